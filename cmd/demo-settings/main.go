@@ -111,7 +111,7 @@ func main() {
 	}
 
 	settingsBtn := widget.NewButton("Open Settings Panel", func() {
-		settingsDialog := shared.NewSettingsDialog(configMgr, window)
+		settingsDialog := shared.NewSettingsDialog(configMgr, nil, window)
 		settingsDialog.Show()
 
 		// Refresh info after dialog closes (simple delay for demo)
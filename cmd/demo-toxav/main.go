@@ -2,14 +2,17 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/opd-ai/whisp/internal/core/audio"
 	"github.com/opd-ai/whisp/internal/core/calls"
 	"github.com/opd-ai/whisp/internal/core/tox"
 )
@@ -109,6 +112,19 @@ func main() {
 		log.Fatalf("Failed to create call manager: %v", err)
 	}
 
+	// Play and capture call audio when the build has an audio backend
+	audioMgr := audio.NewManager()
+	if device, ok := audioMgr.(calls.AudioDevice); ok {
+		if err := audioMgr.Initialize(); err != nil {
+			log.Fatalf("Failed to initialize audio: %v", err)
+		}
+		defer audioMgr.Shutdown()
+		callManager.SetAudioDevice(device)
+		fmt.Println("Call audio uses the default speaker and microphone")
+	} else {
+		fmt.Println("No audio devices (build with -tags portaudio); audio frames are only printed")
+	}
+
 	// Start the call manager
 	fmt.Println("Starting call manager...")
 	if err := callManager.Start(); err != nil {
@@ -136,9 +152,21 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Entering "m" toggles the microphone in active calls
+	muteChan := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if strings.TrimSpace(scanner.Text()) == "m" {
+				muteChan <- struct{}{}
+			}
+		}
+	}()
+
 	// Demo loop
 	fmt.Println("\nDemo running - Press Ctrl+C to exit")
 	fmt.Println("Waiting for calls or use external client to place calls...")
+	fmt.Println("Enter m to mute or unmute the microphone")
 
 	// Simulate some activity
 	ticker := time.NewTicker(10 * time.Second)
@@ -154,6 +182,16 @@ func main() {
 			fmt.Printf("\nReceived signal %v, shutting down gracefully...\n", sig)
 			cancel()
 
+		case <-muteChan:
+			for _, call := range callManager.GetActiveCalls() {
+				muted := !call.IsMuted()
+				if err := callManager.SetMuted(call.FriendID, muted); err != nil {
+					log.Printf("Failed to change mute: %v", err)
+					continue
+				}
+				fmt.Printf("Microphone muted for friend %d: %t\n", call.FriendID, muted)
+			}
+
 		case <-ticker.C:
 			// Print periodic status
 			activeCalls := callManager.GetActiveCalls()
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/opd-ai/whisp/internal/core"
+	"github.com/opd-ai/whisp/internal/core/audio"
 	"github.com/opd-ai/whisp/platform/common"
 )
 
@@ -130,11 +132,19 @@ func main() {
 	// 5. Test waveform generation
 	fmt.Println("\n=== Testing Waveform Generation ===")
 
-	// Mock file path for waveform generation
-	mockFile := filepath.Join(voiceDir, "test_audio.wav")
-	waveform, err := app.GenerateWaveformFromUI(mockFile, 50)
+	// A fading test tone stands in for a recording
+	toneFile := filepath.Join(voiceDir, "test_audio.wav")
+	tone := make([]float32, 16000)
+	for i := range tone {
+		fade := 1 - float64(i)/float64(len(tone))
+		tone[i] = float32(fade * math.Sin(float64(i)*2*math.Pi*440/16000))
+	}
+	if err := audio.WriteWAV(toneFile, tone, 16000); err != nil {
+		log.Fatalf("Failed to write test tone: %v", err)
+	}
+	waveform, err := app.GenerateWaveformFromUI(toneFile, 50)
 	if err != nil {
-		log.Printf("Waveform generation failed (expected for mock): %v", err)
+		log.Printf("Waveform generation failed: %v", err)
 	} else {
 		fmt.Printf("✓ Generated waveform with %d points\n", len(waveform))
 
@@ -156,7 +166,7 @@ func main() {
 	// 6. Test audio playback
 	fmt.Println("\n=== Testing Voice Playback ===")
 
-	player, err := app.PlayVoiceMessageFromUI(mockFile)
+	player, err := app.PlayVoiceMessageFromUI(toneFile)
 	if err != nil {
 		log.Printf("Voice playback failed (expected for mock): %v", err)
 	} else {
@@ -178,7 +188,7 @@ func main() {
 		"voice_max_duration":    "5m",
 		"voice_bitrate":         32,
 		"voice_noise_gate":      -30,
-		"voice_format":          "wav",
+		"voice_format":          "opus",
 		"voice_sample_rate":     48000,
 		"voice_auto_send":       false,
 		"voice_waveform_points": 100,
@@ -199,12 +209,10 @@ func main() {
 	fmt.Println("  ✓ Core app integration with cleanup")
 	fmt.Println("  ✓ Proper error handling and state management")
 
-	fmt.Println("\nTo implement real audio:")
-	fmt.Println("  1. Replace MockRecorder with PortAudio implementation")
-	fmt.Println("  2. Replace MockPlayer with audio playback library")
-	fmt.Println("  3. Add Opus encoding for compression")
-	fmt.Println("  4. Implement real waveform analysis")
-	fmt.Println("  5. Add UI components for recording controls")
+	fmt.Println("\nTo use real audio:")
+	fmt.Println("  1. Build with -tags portaudio to record and play on audio devices")
+	fmt.Println("  2. Build with -tags opus to compress recordings with Opus")
+	fmt.Println("  3. Add UI components for recording controls")
 
 	// Stop the application
 	app.Stop()
@@ -11,6 +11,8 @@ import (
 	"runtime"
 	"syscall"
 
+	"fyne.io/fyne/v2/app"
+
 	"github.com/opd-ai/whisp/internal/core"
 	"github.com/opd-ai/whisp/platform/common"
 	"github.com/opd-ai/whisp/ui/adaptive"
@@ -30,6 +32,7 @@ func main() {
 		configPath  = flag.String("config", "", "Custom config file path")
 		showVersion = flag.Bool("version", false, "Show version information")
 		headless    = flag.Bool("headless", false, "Run in headless mode (no GUI)")
+		migrate     = flag.Bool("migrate-cipher", false, "Re-encrypt the database with the configured cipher settings")
 	)
 	flag.Parse()
 
@@ -57,13 +60,59 @@ func main() {
 		log.Fatal("Failed to create data directory:", err)
 	}
 
-	// Initialize application core
-	coreApp, err := core.NewApp(&core.Config{
+	config := &core.Config{
 		DataDir:    *dataDir,
 		ConfigPath: *configPath,
 		Debug:      *debug,
 		Platform:   platform,
-	})
+
+		PasswordPrompt: promptPassword,
+		MigrateCipher:  *migrate,
+	}
+	if *headless {
+		run(config, true, nil)
+		return
+	}
+
+	// Fyne runs its event loop on the main goroutine, so the core starts
+	// beside it. Without a terminal the password is asked for in a window.
+	fyneApp := app.NewWithID("com.opd-ai.whisp")
+	config.GUIApp = fyneApp
+	var passwordWindow *adaptive.PasswordWindow
+	if !common.StdinIsTerminal() {
+		passwordWindow = adaptive.NewPasswordWindow(fyneApp)
+		config.PasswordPrompt = passwordWindow.Prompt
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		run(config, false, passwordWindow)
+		fyneApp.Quit()
+	}()
+	fyneApp.Run()
+
+	// Quitting from the GUI stops the core too
+	stop()
+	<-stopped
+}
+
+// stopSignals asks run to shut down, as SIGINT and SIGTERM do
+var stopSignals = make(chan os.Signal, 1)
+
+// stop asks run to shut down
+func stop() {
+	select {
+	case stopSignals <- os.Interrupt:
+	default:
+	}
+}
+
+// run starts the core, and the GUI unless headless, and blocks until
+// shutdown. The password window is closed once the main window replaced it.
+func run(config *core.Config, headless bool, passwordWindow *adaptive.PasswordWindow) {
+	// Initialize application core
+	coreApp, err := core.NewApp(config)
 	if err != nil {
 		log.Fatal("Failed to initialize application core:", err)
 	}
@@ -73,23 +122,22 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(stopSignals, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		<-sigChan
+		<-stopSignals
 		log.Println("Shutting down gracefully...")
 		cancel()
 	}()
 
 	// Start application
-	log.Printf("Starting Whisp %s on %s", version, platform)
+	log.Printf("Starting Whisp %s on %s", version, config.Platform)
 
 	if err := coreApp.Start(ctx); err != nil {
 		log.Fatal("Failed to start application:", err)
 	}
 
-	if *headless {
+	if headless {
 		// Headless mode - just run the core
 		log.Println("Running in headless mode...")
 		<-ctx.Done()
@@ -99,11 +147,37 @@ func main() {
 		if err := coreApp.StartGUI(ctx); err != nil {
 			log.Printf("Failed to start GUI: %v", err)
 			log.Println("Falling back to headless mode...")
-			<-ctx.Done()
-		} else {
-			<-ctx.Done()
 		}
+		if passwordWindow != nil {
+			passwordWindow.Close()
+		}
+		<-ctx.Done()
 	}
 
 	log.Println("Application stopped")
 }
+
+// promptPassword asks for the password on the terminal. A new password is
+// asked for twice.
+func promptPassword(setup bool, attempt int) (string, error) {
+	if !setup {
+		if attempt > 1 {
+			fmt.Fprintln(os.Stderr, "Wrong password, try again.")
+		}
+		return common.ReadPassword("Password: ")
+	}
+
+	fmt.Fprintln(os.Stderr, "Choose a password to protect your Whisp data.")
+	password, err := common.ReadPassword("New password: ")
+	if err != nil {
+		return "", err
+	}
+	confirm, err := common.ReadPassword("Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+	if password != confirm {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	return password, nil
+}
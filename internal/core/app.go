@@ -2,22 +2,31 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
 	"time"
 
+	"fyne.io/fyne/v2"
 	"github.com/opd-ai/toxcore"
 	"github.com/opd-ai/whisp/internal/core/audio"
+	"github.com/opd-ai/whisp/internal/core/calls"
 	configpkg "github.com/opd-ai/whisp/internal/core/config"
 	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/group"
+	"github.com/opd-ai/whisp/internal/core/idle"
+	"github.com/opd-ai/whisp/internal/core/linkpreview"
 	"github.com/opd-ai/whisp/internal/core/media"
 	"github.com/opd-ai/whisp/internal/core/message"
 	"github.com/opd-ai/whisp/internal/core/security"
 	"github.com/opd-ai/whisp/internal/core/tox"
 	"github.com/opd-ai/whisp/internal/core/transfer"
+	"github.com/opd-ai/whisp/internal/logging"
 	"github.com/opd-ai/whisp/internal/storage"
+	"github.com/opd-ai/whisp/platform/notifications"
 	"github.com/opd-ai/whisp/ui/adaptive"
 )
 
@@ -27,6 +36,21 @@ type Config struct {
 	ConfigPath string
 	Debug      bool
 	Platform   adaptive.Platform
+
+	// PasswordPrompt gates startup behind the user's password and encrypts
+	// the database with a key it unlocks. Without it the database is not
+	// encrypted.
+	PasswordPrompt PasswordPrompt
+
+	// MigrateCipher re-encrypts an encrypted database with the configured
+	// cipher settings when they differ from the ones it was created with.
+	// Without it changed settings only apply to new databases.
+	MigrateCipher bool
+
+	// GUIApp is the Fyne app the GUI opens its window in. The caller runs its
+	// event loop on the main goroutine. StartGUI creates and runs its own
+	// when nil.
+	GUIApp fyne.App
 }
 
 // App represents the core application logic
@@ -37,15 +61,33 @@ type App struct {
 	storage       *storage.Database
 	contacts      *contact.Manager
 	messages      *message.Manager
+	groups        *group.Manager
 	security      *security.Manager
 	transfers     *transfer.Manager
 	audio         audio.Manager
 	media         media.ManagerInterface
 	notifications *NotificationService
+	activity      *idle.Tracker
+	autoAway      *tox.AutoAway
+	reconnector   *tox.Reconnector
+	calls         *calls.Manager
+	backups       *BackupScheduler
+	linkPreviews  *linkpreview.Manager
 
 	mu       sync.RWMutex
 	running  bool
 	shutdown chan struct{}
+
+	// Auto-lock
+	lockTimeout   time.Duration
+	locked        bool
+	onLockChanged func(locked bool)
+
+	// Calls
+	onCallEvent func(event *calls.CallEvent)
+
+	// Our avatar, sent to friends as they come online
+	selfAvatar []byte
 }
 
 // NewApp creates a new application instance
@@ -56,24 +98,39 @@ func NewApp(config *Config) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize configuration: %w", err)
 	}
 
-	// Initialize database
-	dbPath := filepath.Join(config.DataDir, "whisp.db")
-	db, err := storage.NewDatabase(dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	// Apply log settings first so everything after is logged as configured
+	logDir := filepath.Join(config.DataDir, "logs")
+	configureLogging := func(cfg configpkg.Config) {
+		level := cfg.Advanced.LogLevel
+		if config.Debug {
+			level = "debug"
+		}
+		if err := logging.Configure(level, cfg.Advanced.LogToFile, logDir, cfg.Advanced.MaxLogSize); err != nil {
+			logging.Warnf("%v", err)
+		}
 	}
+	configureLogging(configMgr.GetConfig())
+	configMgr.OnChange(configureLogging)
 
 	// Initialize security manager
 	securityMgr, err := security.NewManager(config.DataDir)
 	if err != nil {
-		db.Close()
 		return nil, fmt.Errorf("failed to initialize security: %w", err)
 	}
 
+	// Initialize database
+	dbPath := filepath.Join(config.DataDir, "whisp.db")
+	db, err := openDatabase(dbPath, configMgr.GetConfig(), securityMgr, config.PasswordPrompt, config.MigrateCipher)
+	if err != nil {
+		securityMgr.Cleanup()
+		return nil, err
+	}
+
 	// Initialize Tox manager
 	toxMgr, err := tox.NewManager(&tox.Config{
-		DataDir: config.DataDir,
-		Debug:   config.Debug,
+		DataDir:        config.DataDir,
+		Debug:          config.Debug,
+		BootstrapNodes: bootstrapNodes(configMgr.GetConfig(), config.DataDir),
 	})
 	if err != nil {
 		db.Close()
@@ -81,11 +138,58 @@ func NewApp(config *Config) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize Tox: %w", err)
 	}
 
+	toxMgr.SetAppearOffline(configMgr.GetConfig().Privacy.AppearOffline)
+	configMgr.OnChange(func(cfg configpkg.Config) {
+		toxMgr.SetBootstrapNodes(bootstrapNodes(cfg, config.DataDir))
+	})
+	reconnector := tox.NewReconnector(toxMgr)
+
+	// Auto-away watches UI activity; it never overrides a status the user picked
+	activity := idle.NewTracker()
+	autoAway := tox.NewAutoAway(toxMgr, activity, configMgr.GetConfig().Privacy.AutoAwayAfter)
+	autoAway.SetEnabled(configMgr.GetConfig().Privacy.AutoAway)
+
 	// Initialize contact manager
 	contactMgr := contact.NewManager(db, toxMgr)
 
 	// Initialize message manager
 	messageMgr := message.NewManager(db, toxMgr, contactMgr)
+	senderPolicy, err := message.ParseSenderPolicy(configMgr.GetConfig().Privacy.UnknownSenderPolicy)
+	if err != nil {
+		logging.Warnf("%v, accepting messages from unknown senders", err)
+	}
+	messageMgr.SetSenderPolicy(senderPolicy)
+	messageMgr.SetHonorRemoteDeletions(configMgr.GetConfig().Privacy.HonorRemoteDeletions)
+	messageMgr.SetDisappearingEnabled(configMgr.GetConfig().Privacy.EnableDisappearingMessages)
+	messageMgr.SetTypingIndicators(configMgr.GetConfig().Privacy.SendTypingIndicators, configMgr.GetConfig().Privacy.ShowTypingIndicators)
+	messageMgr.SetReadReceipts(configMgr.GetConfig().Privacy.SendReadReceipts, configMgr.GetConfig().Privacy.ShowReadReceipts)
+	messageMgr.SetCacheSize(configMgr.GetConfig().Advanced.MessageCacheSize)
+	messageMgr.SetRetention(retentionPeriod(configMgr.GetConfig()), configMgr.GetConfig().Privacy.RetentionHardDelete)
+	configMgr.OnChange(func(cfg configpkg.Config) {
+		if policy, err := message.ParseSenderPolicy(cfg.Privacy.UnknownSenderPolicy); err != nil {
+			logging.Warnf("%v, keeping the current sender policy", err)
+		} else {
+			messageMgr.SetSenderPolicy(policy)
+		}
+		messageMgr.SetCacheSize(cfg.Advanced.MessageCacheSize)
+		messageMgr.SetRetention(retentionPeriod(cfg), cfg.Privacy.RetentionHardDelete)
+	})
+	retryCfg := configMgr.GetConfig().Advanced.SendRetry
+	messageMgr.SetRetryPolicy(message.RetryPolicy{
+		MaxAttempts: retryCfg.MaxAttempts,
+		Expiry:      retryCfg.Expiry,
+		Interval:    message.DefaultRetryPolicy.Interval,
+	})
+	if err := messageMgr.RestoreOutbox(); err != nil {
+		logging.Warnf("Failed to restore unsent messages: %v", err)
+	}
+	if _, err := messageMgr.CheckSearchIndex(message.DefaultSearchIndexDriftThreshold); err != nil {
+		logging.Warnf("Failed to check message search index: %v", err)
+	}
+
+	// Group chats travel as control messages between friends
+	groupMgr := group.NewManager(db, toxMgr, messageMgr)
+	messageMgr.SetOnGroupControl(groupMgr.HandleControl)
 
 	// Initialize file transfer manager
 	transferMgr, err := transfer.NewManager(config.DataDir)
@@ -97,9 +201,38 @@ func NewApp(config *Config) (*App, error) {
 
 	// Connect transfer manager to Tox
 	transferMgr.SetToxManager(toxMgr)
+	transferMgr.SetChunkVerification(configMgr.GetConfig().Advanced.VerifyTransferChunks)
+	transferMgr.SetTransferRateLimit(configMgr.GetConfig().Advanced.TransferRateLimit)
+	transferMgr.SetConcurrencyLimits(configMgr.GetConfig().Advanced.MaxConcurrentDownloads, configMgr.GetConfig().Advanced.MaxConcurrentUploads)
+	configMgr.OnChange(func(cfg configpkg.Config) {
+		transferMgr.SetConcurrencyLimits(cfg.Advanced.MaxConcurrentDownloads, cfg.Advanced.MaxConcurrentUploads)
+	})
+	transferMgr.SetAutoAcceptPolicy(func(friendID uint32) transfer.AutoAcceptPolicy {
+		return fileAutoAcceptPolicy(configMgr.GetConfig(), contactMgr, friendID)
+	})
+	transferMgr.SetBlockedFilter(contactMgr.IsBlocked)
+
+	// Keep one copy of files received more than once
+	transferMgr.SetFileStore(db)
+	if _, err := db.DedupFiles(transferMgr.FileStoreDir()); err != nil {
+		logging.Warnf("Failed to deduplicate stored files: %v", err)
+	}
+
+	// Exchange file checksums so received files can be verified
+	transferMgr.SetOnSendStarted(func(t *transfer.Transfer) {
+		if err := messageMgr.SendFileChecksum(t.FriendID, t.FileName, t.FileSize, t.FileChecksum); err != nil {
+			logging.Warnf("Failed to send checksum for %s: %v", t.FileName, err)
+		}
+	})
+	messageMgr.SetOnFileChecksum(transferMgr.SetExpectedChecksum)
+	transferMgr.SetOnAvatarReceived(func(friendID uint32, avatar []byte) {
+		if err := contactMgr.SetAvatar(friendID, avatar); err != nil {
+			logging.Warnf("Failed to store avatar of friend %d: %v", friendID, err)
+		}
+	})
 
 	// Initialize audio manager
-	audioMgr := audio.NewMockManager()
+	audioMgr := audio.NewManager()
 	if err := audioMgr.Initialize(); err != nil {
 		db.Close()
 		securityMgr.Cleanup()
@@ -109,23 +242,62 @@ func NewApp(config *Config) (*App, error) {
 	// Initialize media manager for thumbnails and previews
 	mediaCacheDir := filepath.Join(config.DataDir, "media_cache")
 	mediaMgr := media.NewManager(mediaCacheDir)
+	mediaMgr.SetThumbnailConcurrency(configMgr.GetConfig().Storage.ThumbnailCache.Workers)
+	if cacheCfg := configMgr.GetConfig().Storage.ThumbnailCache; cacheCfg.CleanupOnStartup {
+		if result, err := mediaMgr.PruneCache(cacheCfg.MaxSize); err != nil {
+			logging.Warnf("Failed to prune thumbnail cache: %v", err)
+		} else if result.BytesReclaimed > 0 {
+			logging.Infof("Thumbnail cache pruned: %d orphaned, %d evicted, %d bytes reclaimed",
+				result.OrphansRemoved, result.EvictedRemoved, result.BytesReclaimed)
+		}
+	}
+
+	// Received files go to the folder set for their type, if any
+	transferMgr.SetDownloadDirs(mediaMgr.GetMediaTypeString, func(mediaType string) string {
+		return resolveDownloadDir(configMgr.GetConfig().Storage.DownloadDirs[mediaType])
+	})
 
 	app := &App{
-		config:    config,
-		configMgr: configMgr,
-		tox:       toxMgr,
-		storage:   db,
-		contacts:  contactMgr,
-		messages:  messageMgr,
-		security:  securityMgr,
-		transfers: transferMgr,
-		audio:     audioMgr,
-		media:     mediaMgr,
-		shutdown:  make(chan struct{}),
+		config:      config,
+		configMgr:   configMgr,
+		tox:         toxMgr,
+		storage:     db,
+		contacts:    contactMgr,
+		messages:    messageMgr,
+		groups:      groupMgr,
+		security:    securityMgr,
+		transfers:   transferMgr,
+		audio:       audioMgr,
+		media:       mediaMgr,
+		activity:    activity,
+		autoAway:    autoAway,
+		reconnector: reconnector,
+		shutdown:    make(chan struct{}),
+
+		lockTimeout: configMgr.GetConfig().Privacy.LockTimeout,
+		selfAvatar:  loadSelfAvatar(db),
 	}
 
+	app.linkPreviews = linkpreview.NewManager(linkpreview.NewHTTPFetcher(), configMgr.GetConfig().Privacy.LinkPreviewTimeout)
+	configMgr.OnChange(func(cfg configpkg.Config) {
+		app.linkPreviews.SetTimeout(cfg.Privacy.LinkPreviewTimeout)
+	})
+
+	// Forwarded files are sent from their local copy like any other upload
+	messageMgr.SetFileSender(func(friendID uint32, filePath string) error {
+		_, err := app.SendFileFromUI(friendID, filePath)
+		return err
+	})
+
+	app.backups = NewBackupScheduler(db.BackupDatabase, func() BackupSettings {
+		return backupSettingsFrom(configMgr.GetConfig(), config.DataDir)
+	}, app.backupSkipReason)
+
 	// Initialize notification service
 	app.notifications = NewNotificationService(app)
+	transferMgr.SetOnIncomingFile(func(t *transfer.Transfer, accepted bool) {
+		app.notifications.handleFileOffer(t.FriendID, t.FileName, accepted)
+	})
 
 	// Set up Tox callbacks
 	if err := app.setupToxCallbacks(); err != nil {
@@ -133,9 +305,158 @@ func NewApp(config *Config) (*App, error) {
 		return nil, fmt.Errorf("failed to setup Tox callbacks: %w", err)
 	}
 
+	// Calls are optional; the rest of the app works without them
+	if err := app.setupCalls(); err != nil {
+		logging.Warnf("%v", err)
+	}
+
 	return app, nil
 }
 
+// openDatabase opens the database, first unlocking the master key when a
+// password prompt is given and encryption is enabled or a password was set up
+// earlier. An unencrypted database is encrypted once the key is unlocked. An
+// encrypted one keeps the cipher settings it was created with unless
+// migrateCipher asks to re-encrypt it with the configured ones.
+func openDatabase(dbPath string, cfg configpkg.Config, securityMgr *security.Manager, prompt PasswordPrompt, migrateCipher bool) (*storage.Database, error) {
+	// A staged backup replaces the database before anything is changed in it
+	if err := storage.ApplyPendingRestore(dbPath); err != nil {
+		return nil, err
+	}
+
+	if prompt == nil || (!cfg.Storage.EnableEncryption && !securityMgr.HasPassword()) {
+		db, err := storage.NewDatabase(dbPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize database: %w", err)
+		}
+		return db, nil
+	}
+
+	if err := unlockSecurity(securityMgr, prompt); err != nil {
+		return nil, err
+	}
+	params := cipherParams(cfg)
+	if err := storage.EncryptDatabase(dbPath, securityMgr, params); err != nil {
+		return nil, fmt.Errorf("failed to encrypt database: %w", err)
+	}
+	if migrateCipher {
+		if err := migrateCipherParams(dbPath, securityMgr, params); err != nil {
+			return nil, err
+		}
+	}
+	db, err := storage.NewDatabaseWithCipher(dbPath, securityMgr, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	return db, nil
+}
+
+// cipherParams returns the configured cipher settings for the database
+func cipherParams(cfg configpkg.Config) storage.CipherParams {
+	params := storage.CipherParams{
+		Compatibility: cfg.Storage.Cipher.Compatibility,
+		KDFIterations: cfg.Storage.Cipher.KDFIterations,
+		PageSize:      cfg.Storage.Cipher.PageSize,
+		HMACAlgorithm: cfg.Storage.Cipher.HMACAlgorithm,
+		KDFAlgorithm:  cfg.Storage.Cipher.KDFAlgorithm,
+	}
+	// Configs from before the settings existed use the defaults
+	if params.Compatibility == 0 {
+		params.Compatibility = storage.DefaultCipherParams().Compatibility
+	}
+	return params
+}
+
+// migrateCipherParams re-encrypts an existing database whose recorded cipher
+// settings differ from params. New databases are created with params.
+func migrateCipherParams(dbPath string, securityMgr *security.Manager, params storage.CipherParams) error {
+	if info, err := os.Stat(dbPath); err != nil || info.Size() == 0 {
+		return nil
+	}
+	recorded, err := storage.LoadCipherParams(dbPath)
+	if err != nil {
+		return err
+	}
+	if recorded == params {
+		return nil
+	}
+
+	logging.Infof("Re-encrypting database with new cipher settings")
+	if err := storage.MigrateCipherParams(dbPath, securityMgr, params); err != nil {
+		return fmt.Errorf("failed to migrate cipher settings: %w", err)
+	}
+	return nil
+}
+
+// fileAutoAcceptPolicy builds the auto-accept policy for files offered by a friend
+func fileAutoAcceptPolicy(cfg configpkg.Config, contacts *contact.Manager, friendID uint32) transfer.AutoAcceptPolicy {
+	policy := transfer.AutoAcceptPolicy{
+		Enabled:     cfg.Privacy.AutoAcceptFiles,
+		DownloadDir: resolveDownloadDir(cfg.Storage.DownloadDir),
+	}
+	if cfg.Privacy.AutoDownloadLimit > 0 {
+		policy.MaxSize = uint64(cfg.Privacy.AutoDownloadLimit)
+	}
+	if c, ok := contacts.GetContact(friendID); ok {
+		policy.Trusted = c.(*contact.Contact).AutoAcceptFiles
+	}
+	return policy
+}
+
+// resolveDownloadDir makes a relative download directory relative to the
+// user's home directory
+func resolveDownloadDir(dir string) string {
+	if dir == "" || filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		logging.Warnf("Failed to find home directory for downloads: %v", err)
+		return ""
+	}
+	return filepath.Join(home, dir)
+}
+
+// bootstrapNodesPath returns where the bootstrap node list is kept
+func bootstrapNodesPath(cfg configpkg.Config, dataDir string) string {
+	path := cfg.Network.BootstrapNodesFile
+	if path == "" {
+		path = tox.DefaultBootstrapNodesFile
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dataDir, path)
+	}
+	return path
+}
+
+// bootstrapNodes returns the configured bootstrap nodes: those in the nodes
+// file if it exists, else those in the config. Nil means the defaults.
+func bootstrapNodes(cfg configpkg.Config, dataDir string) []tox.BootstrapNode {
+	nodes, err := tox.LoadBootstrapNodes(bootstrapNodesPath(cfg, dataDir))
+	if err == nil {
+		return nodes
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		logging.Warnf("%v, using the configured nodes", err)
+	}
+
+	nodes = nil
+	for _, configured := range cfg.Network.BootstrapNodes {
+		node := tox.BootstrapNode{Address: configured.Address, Port: uint16(configured.Port), PublicKey: configured.PublicKey}
+		if err := node.Validate(); err != nil {
+			logging.Warnf("Skipping %v", err)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// retentionPeriod returns how long messages are kept by default; 0 is forever
+func retentionPeriod(cfg configpkg.Config) time.Duration {
+	return time.Duration(cfg.Privacy.RetentionDays) * 24 * time.Hour
+}
+
 // Start starts the application
 func (a *App) Start(ctx context.Context) error {
 	a.mu.Lock()
@@ -152,16 +473,31 @@ func (a *App) Start(ctx context.Context) error {
 
 	// Start notification service
 	if err := a.notifications.Start(ctx); err != nil {
-		log.Printf("Warning: Failed to start notification service: %v", err)
+		logging.Warnf("Failed to start notification service: %v", err)
 		// Don't fail startup for notification issues
 	}
 
+	if a.calls != nil {
+		if err := a.calls.Start(); err != nil {
+			logging.Warnf("Failed to start calls: %v", err)
+		}
+	}
+
 	a.running = true
 
+	// Delete disappearing messages, including any that expired while closed
+	a.messages.StartExpirySweeper(ctx, message.DefaultSweepInterval)
+	// Prune messages past their retention, on startup and then periodically
+	a.messages.StartRetentionPruner(ctx, message.DefaultPruneInterval)
+	a.backups.Start(ctx)
+	a.startCompaction(ctx)
+	// Rejoin the network whenever the connection drops
+	go a.reconnector.Run(ctx, tox.ReconnectCheckInterval)
+
 	// Start main loop
 	go a.mainLoop(ctx)
 
-	log.Println("Application started successfully")
+	logging.Infof("Application started successfully")
 	return nil
 }
 
@@ -177,18 +513,30 @@ func (a *App) Stop() error {
 	close(a.shutdown)
 	a.running = false
 
+	// End calls while Tox can still tell friends they are over
+	if a.calls != nil && a.calls.IsRunning() {
+		if err := a.calls.Stop(); err != nil {
+			logging.Errorf("Error stopping calls: %v", err)
+		}
+	}
+
 	if err := a.tox.Stop(); err != nil {
-		log.Printf("Error stopping Tox: %v", err)
+		logging.Errorf("Error stopping Tox: %v", err)
+	}
+
+	// Let delivery/read status updates land before the process can exit
+	if err := a.storage.WaitAsync(storage.DefaultAsyncTimeout); err != nil {
+		logging.Warnf("%v", err)
 	}
 
 	// Stop notification service
 	if a.notifications != nil {
 		if err := a.notifications.Stop(); err != nil {
-			log.Printf("Error stopping notification service: %v", err)
+			logging.Errorf("Error stopping notification service: %v", err)
 		}
 	}
 
-	log.Println("Application stopped")
+	logging.Infof("Application stopped")
 	return nil
 }
 
@@ -200,6 +548,11 @@ func (a *App) Cleanup() {
 	if a.audio != nil {
 		a.audio.Shutdown()
 	}
+	if a.media != nil {
+		if err := a.media.Flush(); err != nil {
+			logging.Warnf("Failed to save thumbnail index: %v", err)
+		}
+	}
 	if a.tox != nil {
 		a.tox.Cleanup()
 	}
@@ -209,6 +562,7 @@ func (a *App) Cleanup() {
 	if a.security != nil {
 		a.security.Cleanup()
 	}
+	logging.Close()
 }
 
 // IsRunning returns whether the application is running
@@ -233,11 +587,103 @@ func (a *App) GetMessages() *message.Manager {
 	return a.messages
 }
 
+// GetGroups returns the group chat manager
+func (a *App) GetGroups() *group.Manager {
+	return a.groups
+}
+
 // GetNotifications returns the notification service
 func (a *App) GetNotifications() *NotificationService {
 	return a.notifications
 }
 
+// SetActiveConversation marks the conversation the user is viewing so its
+// messages don't raise notifications
+func (a *App) SetActiveConversation(friendID uint32) {
+	a.notifications.SetActiveConversation(friendID)
+}
+
+// ClearActiveConversation re-enables notifications for every conversation
+func (a *App) ClearActiveConversation() {
+	a.notifications.ClearActiveConversation()
+}
+
+// SetPresentationMode pauses notifications while the UI is in presentation mode
+func (a *App) SetPresentationMode(enabled bool) {
+	a.notifications.SetPaused(enabled)
+}
+
+// SetNotificationAppearance matches notification icons to the app theme ("light" or "dark")
+func (a *App) SetNotificationAppearance(variant string) {
+	a.notifications.SetAppearance(notifications.ParseAppearance(variant))
+}
+
+// GetNotificationHistory returns up to limit recent notifications, newest
+// first, including those that were held back
+func (a *App) GetNotificationHistory(limit int) []adaptive.NotificationRecord {
+	history := a.notifications.GetHistory(limit)
+	records := make([]adaptive.NotificationRecord, len(history))
+	for i, entry := range history {
+		records[i] = adaptive.NotificationRecord{
+			Time:      entry.Time,
+			Kind:      entry.Type.String(),
+			Sender:    entry.Sender,
+			Displayed: entry.Displayed,
+			Reason:    string(entry.Reason),
+		}
+	}
+	return records
+}
+
+// GetDiagnostics gathers the app's health from each manager
+func (a *App) GetDiagnostics() adaptive.Diagnostics {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	d := adaptive.Diagnostics{
+		Platform:          fmt.Sprintf("%s/%s, %s", runtime.GOOS, runtime.GOARCH, runtime.Version()),
+		ToxID:             logging.Redact(a.tox.GetToxID()),
+		ToxConnection:     connectionName(a.tox.GetConnectionStatus()),
+		Friends:           len(a.contacts.GetAllContacts()),
+		FriendsOnline:     a.contacts.CountOnline(),
+		ActiveTransfers:   len(a.transfers.GetActiveTransfers()),
+		QueuedTransfers:   len(a.transfers.GetQueuedTransfers()),
+		DatabaseSize:      a.storage.Size(),
+		DatabaseEncrypted: a.storage.IsEncrypted(),
+		MemoryInUse:       mem.HeapInuse,
+		MemoryFromOS:      mem.Sys,
+		Goroutines:        runtime.NumGoroutine(),
+	}
+	d.CacheHits, d.CacheMisses = a.messages.CacheStats()
+
+	// The database is closed while the app is locked
+	if !a.storage.IsLocked() {
+		count, err := a.messages.MessageCount()
+		if err != nil {
+			logging.Warnf("%v", err)
+		}
+		d.Messages = count
+	}
+	return d
+}
+
+// connectionName names a Tox connection status for diagnostics
+func connectionName(status toxcore.ConnectionStatus) string {
+	switch status {
+	case toxcore.ConnectionTCP:
+		return "tcp"
+	case toxcore.ConnectionUDP:
+		return "udp"
+	default:
+		return "none"
+	}
+}
+
+// ClearNotificationHistory empties the notification history
+func (a *App) ClearNotificationHistory() {
+	a.notifications.ClearHistory()
+}
+
 // GetSecurity returns the security manager
 func (a *App) GetSecurity() *security.Manager {
 	return a.security
@@ -253,6 +699,94 @@ func (a *App) GetConfigManager() *configpkg.Manager {
 	return a.configMgr
 }
 
+// GetPeerCapabilities returns the extended features a friend's client announced.
+// The second result is false if the friend has not completed the handshake.
+func (a *App) GetPeerCapabilities(friendID uint32) (message.Capabilities, bool) {
+	return a.messages.GetPeerCapabilities(friendID)
+}
+
+// GetSelfStatus returns the status we present to contacts
+func (a *App) GetSelfStatus() contact.Status {
+	return contact.StatusFromTox(a.tox.GetSelfStatus())
+}
+
+// SetSelfStatus sets the status we present to contacts
+func (a *App) SetSelfStatus(status contact.Status) {
+	a.tox.SetSelfStatus(status.ToxStatus())
+}
+
+// SetAppearOffline toggles presenting as offline while staying connected
+func (a *App) SetAppearOffline(enabled bool) {
+	a.tox.SetAppearOffline(enabled)
+}
+
+// RecordActivity notes user interaction, returning from auto-away straight away
+func (a *App) RecordActivity() {
+	a.activity.Touch()
+	a.autoAway.Check(time.Now())
+}
+
+// SetAutoAway turns inactivity-based away status on or off
+func (a *App) SetAutoAway(enabled bool) {
+	a.autoAway.SetEnabled(enabled)
+}
+
+// IsAutoAway reports whether the away status was set by auto-away rather
+// than chosen by the user
+func (a *App) IsAutoAway() bool {
+	return a.autoAway.IsAutoAway()
+}
+
+// SetOnAutoAwayChanged sets the callback invoked when auto-away sets or
+// clears the away status
+func (a *App) SetOnAutoAwayChanged(callback func(away bool)) {
+	a.autoAway.SetOnChange(callback)
+}
+
+// ListBootstrapNodes returns the nodes used to join the Tox network
+func (a *App) ListBootstrapNodes() []tox.BootstrapNode {
+	return a.tox.ListBootstrapNodes()
+}
+
+// SaveBootstrapNodes saves the nodes used to join the Tox network and uses
+// them from the next bootstrap on. No nodes goes back to the configured ones.
+func (a *App) SaveBootstrapNodes(nodes []tox.BootstrapNode) error {
+	cfg := a.configMgr.GetConfig()
+	path := bootstrapNodesPath(cfg, a.config.DataDir)
+	if len(nodes) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove bootstrap nodes: %w", err)
+		}
+	} else if err := tox.SaveBootstrapNodes(path, nodes); err != nil {
+		return err
+	}
+
+	a.tox.SetBootstrapNodes(bootstrapNodes(cfg, a.config.DataDir))
+	return nil
+}
+
+// GetConnectionState reports whether we are on the Tox network
+func (a *App) GetConnectionState() tox.ConnectionState {
+	return a.reconnector.State()
+}
+
+// SetOnConnectionChanged sets the callback invoked when we go offline,
+// start reconnecting or come back online
+func (a *App) SetOnConnectionChanged(callback func(state tox.ConnectionState)) {
+	a.reconnector.SetOnChange(callback)
+}
+
+// IsAppearOffline reports whether we currently present as offline
+func (a *App) IsAppearOffline() bool {
+	return a.tox.IsAppearOffline()
+}
+
+// GetQuickReactions returns the emoji shown in the reaction quick bar
+func (a *App) GetQuickReactions() []string {
+	cfg := a.configMgr.GetConfig()
+	return cfg.GetQuickReactions()
+}
+
 // SendMessageFromUI sends a message from the UI
 func (a *App) SendMessageFromUI(friendID uint32, content string) error {
 	if content == "" {
@@ -263,9 +797,14 @@ func (a *App) SendMessageFromUI(friendID uint32, content string) error {
 	return err
 }
 
+// MarkAsRead marks a conversation's received messages as read
+func (a *App) MarkAsRead(friendID uint32) error {
+	return a.messages.MarkAsRead(friendID)
+}
+
 // AddContactFromUI adds a contact from the UI
 func (a *App) AddContactFromUI(toxID, message string) error {
-	log.Printf("Adding contact from UI: %s", toxID)
+	logging.Debugf("Adding contact from UI: %s", toxID)
 
 	// Validate Tox ID format (basic validation)
 	if len(toxID) != 76 {
@@ -283,7 +822,7 @@ func (a *App) AddContactFromUI(toxID, message string) error {
 
 // SendFileFromUI initiates a file transfer from the UI
 func (a *App) SendFileFromUI(friendID uint32, filePath string) (string, error) {
-	log.Printf("Sending file from UI: friend=%d, file=%s", friendID, filePath)
+	logging.Debugf("Sending file from UI: friend=%d, file=%s", friendID, filePath)
 
 	// Create file transfer through transfer manager
 	transfer, err := a.transfers.SendFile(friendID, filePath)
@@ -301,14 +840,14 @@ func (a *App) SendFileFromUI(friendID uint32, filePath string) (string, error) {
 
 // AcceptFileFromUI accepts an incoming file transfer from the UI
 func (a *App) AcceptFileFromUI(transferID, saveDir string) error {
-	log.Printf("Accepting file transfer from UI: transfer=%s, saveDir=%s", transferID, saveDir)
+	logging.Debugf("Accepting file transfer from UI: transfer=%s, saveDir=%s", transferID, saveDir)
 
 	return a.transfers.AcceptIncomingFile(transferID, saveDir)
 }
 
 // CancelFileFromUI cancels a file transfer from the UI
 func (a *App) CancelFileFromUI(transferID string) error {
-	log.Printf("Cancelling file transfer from UI: transfer=%s", transferID)
+	logging.Debugf("Cancelling file transfer from UI: transfer=%s", transferID)
 
 	return a.transfers.CancelTransfer(transferID, a.tox)
 }
@@ -324,7 +863,7 @@ func (a *App) GetAudioManager() audio.Manager {
 
 // StartVoiceRecordingFromUI starts voice recording from the UI
 func (a *App) StartVoiceRecordingFromUI(friendID uint32, outputDir string) (audio.Recorder, error) {
-	log.Printf("Starting voice recording from UI: friend=%d, outputDir=%s", friendID, outputDir)
+	logging.Debugf("Starting voice recording from UI: friend=%d, outputDir=%s", friendID, outputDir)
 
 	recorder, err := a.audio.GetRecorder()
 	if err != nil {
@@ -335,13 +874,21 @@ func (a *App) StartVoiceRecordingFromUI(friendID uint32, outputDir string) (audi
 		return nil, fmt.Errorf("voice recording not supported on this system")
 	}
 
-	// Create output path
-	outputPath := filepath.Join(outputDir, fmt.Sprintf("voice_%d_%d.wav", friendID, time.Now().Unix()))
-
 	// Configure recording options
 	options := audio.DefaultRecordingOptions()
-	options.OutputPath = outputPath
 	options.MaxDuration = 5 * time.Minute // 5 minute max for voice messages
+	cfg := a.configMgr.GetConfig()
+	if cfg.Storage.VoiceFormat != "" {
+		options.Format.Codec = cfg.Storage.VoiceFormat
+	}
+	if cfg.Storage.VoiceBitrate > 0 {
+		options.BitrateKbps = cfg.Storage.VoiceBitrate
+	}
+
+	// Create output path; the recorder changes the extension if it has to
+	// fall back to another format
+	options.OutputPath = filepath.Join(outputDir,
+		fmt.Sprintf("voice_%d_%d.%s", friendID, time.Now().Unix(), options.Format.Codec))
 
 	// Start recording
 	ctx := context.Background()
@@ -354,7 +901,7 @@ func (a *App) StartVoiceRecordingFromUI(friendID uint32, outputDir string) (audi
 
 // SendVoiceMessageFromUI sends a completed voice recording as a message
 func (a *App) SendVoiceMessageFromUI(friendID uint32, voiceMsg *audio.VoiceMessage) error {
-	log.Printf("Sending voice message from UI: friend=%d, file=%s, duration=%v",
+	logging.Debugf("Sending voice message from UI: friend=%d, file=%s, duration=%v",
 		friendID, voiceMsg.FilePath, voiceMsg.Duration)
 
 	// Create voice message in database
@@ -366,7 +913,7 @@ func (a *App) SendVoiceMessageFromUI(friendID uint32, voiceMsg *audio.VoiceMessa
 
 	// Update message with file metadata
 	// Note: In a full implementation, we'd need a method to update message file info
-	log.Printf("Voice message created with ID: %d", msg.ID)
+	logging.Debugf("Voice message created with ID: %d", msg.ID)
 
 	// Send file through transfer system
 	transferID, err := a.SendFileFromUI(friendID, voiceMsg.FilePath)
@@ -374,13 +921,13 @@ func (a *App) SendVoiceMessageFromUI(friendID uint32, voiceMsg *audio.VoiceMessa
 		return fmt.Errorf("failed to send voice file: %w", err)
 	}
 
-	log.Printf("Voice message sent with transfer ID: %s", transferID)
+	logging.Debugf("Voice message sent with transfer ID: %s", transferID)
 	return nil
 }
 
 // PlayVoiceMessageFromUI plays a voice message from the UI
 func (a *App) PlayVoiceMessageFromUI(filePath string) (audio.Player, error) {
-	log.Printf("Playing voice message from UI: file=%s", filePath)
+	logging.Debugf("Playing voice message from UI: file=%s", filePath)
 
 	player, err := a.audio.GetPlayer()
 	if err != nil {
@@ -407,7 +954,7 @@ func (a *App) PlayVoiceMessageFromUI(filePath string) (audio.Player, error) {
 
 // GenerateWaveformFromUI generates waveform data for UI visualization
 func (a *App) GenerateWaveformFromUI(filePath string, points int) ([]float32, error) {
-	log.Printf("Generating waveform from UI: file=%s, points=%d", filePath, points)
+	logging.Debugf("Generating waveform from UI: file=%s, points=%d", filePath, points)
 
 	generator := a.audio.GetWaveformGenerator()
 	return generator.GenerateWaveformFromFile(filePath, points)
@@ -415,14 +962,14 @@ func (a *App) GenerateWaveformFromUI(filePath string, points int) ([]float32, er
 
 // GetMediaInfoFromUI returns media information for a file
 func (a *App) GetMediaInfoFromUI(filePath string) (*media.MediaInfo, error) {
-	log.Printf("Getting media info from UI: file=%s", filePath)
+	logging.Debugf("Getting media info from UI: file=%s", filePath)
 
 	return a.media.GetMediaInfo(filePath)
 }
 
 // GenerateThumbnailFromUI creates a thumbnail for a media file
 func (a *App) GenerateThumbnailFromUI(filePath string, maxWidth, maxHeight int) (string, error) {
-	log.Printf("Generating thumbnail from UI: file=%s, size=%dx%d", filePath, maxWidth, maxHeight)
+	logging.Debugf("Generating thumbnail from UI: file=%s, size=%dx%d", filePath, maxWidth, maxHeight)
 
 	return a.media.GenerateThumbnail(filePath, maxWidth, maxHeight)
 }
@@ -439,7 +986,7 @@ func (a *App) GetThumbnailPathFromUI(filePath string, maxWidth, maxHeight int) (
 
 // CleanupMediaCacheFromUI removes cached thumbnails
 func (a *App) CleanupMediaCacheFromUI() error {
-	log.Printf("Cleaning up media cache from UI")
+	logging.Debugf("Cleaning up media cache from UI")
 	return a.media.Cleanup()
 }
 
@@ -455,8 +1002,14 @@ func (a *App) mainLoop(ctx context.Context) {
 		case <-a.shutdown:
 			return
 		case <-ticker.C:
-			// Update Tox
-			a.tox.Iterate()
+			now := time.Now()
+			a.autoAway.Check(now)
+			a.checkAutoLock(now)
+
+			// The database is closed while locked, so nothing can be stored
+			if a.IsLocked() {
+				continue
+			}
 
 			// Process pending messages
 			a.messages.ProcessPending()
@@ -468,27 +1021,45 @@ func (a *App) mainLoop(ctx context.Context) {
 func (a *App) setupToxCallbacks() error {
 	// Friend request callback
 	a.tox.OnFriendRequest(func(publicKey [32]byte, message string) {
-		log.Printf("Friend request received: %s", message)
-		// Add to pending friend requests
-		a.contacts.HandleFriendRequest(publicKey, message)
+		logging.Debugf("Friend request received: %s", message)
+		// Add to pending friend requests; repeats are not notified again
+		if a.contacts.HandleFriendRequest(publicKey, message) {
+			a.notifications.handleFriendRequest(publicKey, message)
+		}
 	})
 
 	// Friend message callback
 	a.tox.OnFriendMessage(func(friendID uint32, msg string) {
-		log.Printf("Message from friend %d: %s", friendID, msg)
-		// Handle incoming message
-		a.messages.HandleIncomingMessage(friendID, msg, message.MessageTypeNormal)
+		logging.Debugf("Message from friend %d: %s", friendID, msg)
+		// Handle incoming message; control messages and held messages return nil
+		if stored := a.messages.HandleIncomingMessage(friendID, msg, message.MessageTypeNormal); stored != nil {
+			a.notifications.handleFriendMessage(friendID, stored.Content)
+		}
+	})
+
+	// Messages that exhausted their retry budget
+	a.messages.SetOnSendFailed(func(msg *message.Message) {
+		a.notifications.handleSendFailed(msg.FriendID)
 	})
 
 	// Friend status callback
 	a.tox.OnFriendStatus(func(friendID uint32, status toxcore.FriendStatus) {
-		log.Printf("Friend %d status: %v", friendID, status)
+		logging.Debugf("Friend %d status: %v", friendID, status)
+		wasOffline := true
+		if c, ok := a.contacts.GetContact(friendID); ok {
+			wasOffline = c.(*contact.Contact).Status == contact.StatusOffline
+		}
 		a.contacts.UpdateStatus(friendID, status)
+		if wasOffline && status != toxcore.FriendStatusNone {
+			a.sendSelfAvatar(friendID)
+		}
+		a.messages.HandlePeerConnection(friendID, status != toxcore.FriendStatusNone)
+		a.notifications.handleFriendStatus(friendID, status)
 	})
 
 	// Friend name callback
 	a.tox.OnFriendName(func(friendID uint32, name string) {
-		log.Printf("Friend %d name: %s", friendID, name)
+		logging.Debugf("Friend %d name: %s", friendID, name)
 		a.contacts.UpdateName(friendID, name)
 	})
 
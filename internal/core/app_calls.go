@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/opd-ai/whisp/internal/core/calls"
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// setupCalls creates the call manager when voice calls are enabled and
+// connects it to the audio devices
+func (a *App) setupCalls() error {
+	if !a.configMgr.GetConfig().Advanced.Experimental.EnableVoiceCalls {
+		return nil
+	}
+
+	callMgr, err := calls.NewManager(a.tox.GetInstance(), calls.DefaultConfig(), a)
+	if err != nil {
+		return fmt.Errorf("failed to initialize calls: %w", err)
+	}
+	callMgr.SetBlockedFilter(a.contacts.IsBlocked)
+	if device, ok := a.audio.(calls.AudioDevice); ok {
+		callMgr.SetAudioDevice(device)
+	} else {
+		logging.Warnf("No audio device for calls, call audio will not be played")
+	}
+	a.calls = callMgr
+	return nil
+}
+
+// GetCalls returns the call manager, or nil when voice calls are disabled
+func (a *App) GetCalls() *calls.Manager {
+	return a.calls
+}
+
+// OnCallEvent forwards call events to the UI
+func (a *App) OnCallEvent(event *calls.CallEvent) {
+	a.mu.RLock()
+	callback := a.onCallEvent
+	a.mu.RUnlock()
+
+	if callback != nil {
+		callback(event)
+	}
+}
+
+// SetOnCallEvent sets the callback invoked for every call event
+func (a *App) SetOnCallEvent(callback func(event *calls.CallEvent)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onCallEvent = callback
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/whisp/ui/adaptive"
+)
+
+func TestCleanupSavesThumbnailIndex(t *testing.T) {
+	tempDir := t.TempDir()
+	app, err := NewApp(&Config{
+		DataDir:    tempDir,
+		ConfigPath: filepath.Join(tempDir, "config.yaml"),
+		Platform:   adaptive.PlatformLinux,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+
+	source := filepath.Join(tempDir, "photo.png")
+	file, err := os.Create(source)
+	if err != nil {
+		t.Fatalf("Failed to create image: %v", err)
+	}
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 100, 100))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	file.Close()
+	if _, err := app.media.GenerateThumbnail(source, 50, 50); err != nil {
+		t.Fatalf("Failed to generate thumbnail: %v", err)
+	}
+
+	// The index is written on shutdown, not only after the flush delay
+	app.Cleanup()
+	if _, err := os.Stat(filepath.Join(tempDir, "media_cache", "sources.json")); err != nil {
+		t.Errorf("Expected the thumbnail index to be saved on cleanup: %v", err)
+	}
+}
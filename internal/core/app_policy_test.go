@@ -0,0 +1,33 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/ui/adaptive"
+)
+
+func TestSenderPolicyFollowsConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	app, err := NewApp(&Config{
+		DataDir:        tempDir,
+		ConfigPath:     filepath.Join(tempDir, "config.yaml"),
+		Platform:       adaptive.PlatformLinux,
+		PasswordPrompt: func(bool, int) (string, error) { return "secret", nil },
+	})
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	defer app.Cleanup()
+
+	cfg := app.GetConfigManager().GetConfig()
+	cfg.Privacy.UnknownSenderPolicy = "reject"
+	if err := app.GetConfigManager().UpdateConfig(cfg); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+
+	if policy := app.messages.GetSenderPolicy(); policy != message.SenderPolicyReject {
+		t.Errorf("Expected the changed sender policy to apply, got %v", policy)
+	}
+}
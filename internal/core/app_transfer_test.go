@@ -146,3 +146,34 @@ func TestFileTransferUIIntegration(t *testing.T) {
 		t.Error("Expected error for invalid transfer ID")
 	}
 }
+
+func TestFileAutoAcceptPolicy(t *testing.T) {
+	tempDir := t.TempDir()
+	app, err := NewApp(&Config{
+		DataDir:    tempDir,
+		ConfigPath: filepath.Join(tempDir, "config.yaml"),
+		Platform:   adaptive.PlatformLinux,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	defer app.Cleanup()
+
+	cfg := app.configMgr.GetConfig()
+	cfg.Privacy.AutoAcceptFiles = true
+	cfg.Privacy.AutoDownloadLimit = 2048
+	cfg.Storage.DownloadDir = filepath.Join(tempDir, "Downloads")
+
+	policy := fileAutoAcceptPolicy(cfg, app.contacts, 42)
+	if !policy.Enabled || policy.MaxSize != 2048 || policy.Trusted || policy.DownloadDir != cfg.Storage.DownloadDir {
+		t.Errorf("Unexpected policy for an unknown friend: %+v", policy)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("No home directory: %v", err)
+	}
+	if got := resolveDownloadDir("Downloads"); got != filepath.Join(home, "Downloads") {
+		t.Errorf("Expected a relative download directory under the home directory, got %q", got)
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"math"
 	"os"
 	"path/filepath"
 	"testing"
@@ -254,9 +255,16 @@ func TestVoiceMessageIntegration(t *testing.T) {
 	})
 
 	t.Run("WaveformGeneration", func(t *testing.T) {
-		mockFile := filepath.Join(tempDir, "test_waveform.wav")
+		voiceFile := filepath.Join(tempDir, "test_waveform.wav")
+		samples := make([]float32, 8000)
+		for i := range samples {
+			samples[i] = 0.5 * float32(math.Sin(float64(i)/10))
+		}
+		if err := audio.WriteWAV(voiceFile, samples, 8000); err != nil {
+			t.Fatalf("Failed to write voice file: %v", err)
+		}
 
-		waveform, err := app.GenerateWaveformFromUI(mockFile, 50)
+		waveform, err := app.GenerateWaveformFromUI(voiceFile, 50)
 		if err != nil {
 			t.Fatalf("Failed to generate waveform: %v", err)
 		}
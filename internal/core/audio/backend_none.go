@@ -0,0 +1,12 @@
+//go:build !portaudio
+
+package audio
+
+import "fmt"
+
+// newDeviceBackend reports that this build has no audio backend. Build with
+// the portaudio tag to record and play voice messages on real devices; that
+// build needs the PortAudio C library (pkg-config portaudio-2.0).
+func newDeviceBackend() (deviceBackend, error) {
+	return nil, fmt.Errorf("built without an audio backend")
+}
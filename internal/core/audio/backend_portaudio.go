@@ -0,0 +1,85 @@
+//go:build portaudio
+
+package audio
+
+import (
+	"fmt"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// framesPerBuffer is how many samples PortAudio hands over per callback
+// at a given sample rate, 20ms like an Opus frame
+func framesPerBuffer(sampleRate int) int {
+	return sampleRate / 50
+}
+
+// portaudioBackend records and plays on the default PortAudio devices
+type portaudioBackend struct{}
+
+// newDeviceBackend initializes PortAudio and checks that default input and
+// output devices exist
+func newDeviceBackend() (deviceBackend, error) {
+	if err := portaudio.Initialize(); err != nil {
+		return nil, fmt.Errorf("failed to initialize PortAudio: %w", err)
+	}
+	if _, err := portaudio.DefaultInputDevice(); err != nil {
+		portaudio.Terminate()
+		return nil, fmt.Errorf("no audio input device: %w", err)
+	}
+	if _, err := portaudio.DefaultOutputDevice(); err != nil {
+		portaudio.Terminate()
+		return nil, fmt.Errorf("no audio output device: %w", err)
+	}
+	return portaudioBackend{}, nil
+}
+
+// OpenInput opens and starts a mono stream on the default input device
+func (portaudioBackend) OpenInput(sampleRate int, onSamples func(samples []float32)) (audioStream, error) {
+	stream, err := portaudio.OpenDefaultStream(1, 0, float64(sampleRate), framesPerBuffer(sampleRate), func(in []float32) {
+		onSamples(in)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input stream: %w", err)
+	}
+	return startStream(stream)
+}
+
+// OpenOutput opens and starts a mono stream on the default output device
+func (portaudioBackend) OpenOutput(sampleRate int, fill func(out []float32)) (audioStream, error) {
+	stream, err := portaudio.OpenDefaultStream(0, 1, float64(sampleRate), framesPerBuffer(sampleRate), func(out []float32) {
+		fill(out)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output stream: %w", err)
+	}
+	return startStream(stream)
+}
+
+// Terminate releases PortAudio
+func (portaudioBackend) Terminate() error {
+	return portaudio.Terminate()
+}
+
+// startStream starts an opened stream, closing it on failure
+func startStream(stream *portaudio.Stream) (audioStream, error) {
+	if err := stream.Start(); err != nil {
+		stream.Close()
+		return nil, fmt.Errorf("failed to start stream: %w", err)
+	}
+	return portaudioStream{stream}, nil
+}
+
+// portaudioStream stops a PortAudio stream before closing it
+type portaudioStream struct {
+	stream *portaudio.Stream
+}
+
+// Close stops and closes the stream
+func (s portaudioStream) Close() error {
+	if err := s.stream.Stop(); err != nil {
+		s.stream.Close()
+		return fmt.Errorf("failed to stop stream: %w", err)
+	}
+	return s.stream.Close()
+}
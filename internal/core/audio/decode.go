@@ -0,0 +1,92 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pion/opus"
+	"github.com/pion/opus/pkg/oggreader"
+)
+
+// opusSampleRate is the rate pion/opus decodes to
+const opusSampleRate = 48000
+
+// DecodeFile reads a WAV or Ogg Opus file into mono samples in [-1, 1]
+func DecodeFile(filePath string) (*AudioData, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio file: %w", err)
+	}
+
+	switch {
+	case bytes.HasPrefix(data, []byte("RIFF")):
+		return DecodeWAV(data)
+	case bytes.HasPrefix(data, []byte("OggS")):
+		return DecodeOggOpus(data)
+	default:
+		return nil, fmt.Errorf("unsupported audio format in %s", filePath)
+	}
+}
+
+// DecodeOggOpus decodes an Ogg Opus file. pion/opus only handles SILK frames,
+// which is what voice encoders produce at voice bitrates. The encoder delay
+// and the padding of the last frame are trimmed as the granule positions say.
+func DecodeOggOpus(data []byte) (*AudioData, error) {
+	ogg, header, err := oggreader.NewWith(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Ogg stream: %w", err)
+	}
+
+	decoder := opus.NewDecoder()
+	frame := make([]float32, opusSampleRate/50) // 20 ms
+	var samples []float32
+	var packet []byte
+	var granule uint64
+	for {
+		segments, page, err := ogg.ParseNextPage()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Ogg page: %w", err)
+		}
+		granule = page.GranulePosition
+		// Packets longer than 254 bytes span several segments; a segment
+		// shorter than 255 bytes ends the packet
+		for _, segment := range segments {
+			packet = append(packet, segment...)
+			if len(segment) == 255 {
+				continue
+			}
+			if !bytes.HasPrefix(packet, []byte("OpusTags")) && !bytes.HasPrefix(packet, []byte("OpusHead")) {
+				if _, _, err := decoder.DecodeFloat32(packet, frame); err != nil {
+					return nil, fmt.Errorf("failed to decode Opus frame: %w", err)
+				}
+				samples = append(samples, frame...)
+			}
+			packet = packet[:0]
+		}
+	}
+
+	if granule > 0 && granule < uint64(len(samples)) {
+		samples = samples[:granule]
+	}
+	samples = samples[min(int(header.PreSkip), len(samples)):]
+
+	return &AudioData{
+		Samples: samples,
+		Format: AudioFormat{
+			SampleRate: opusSampleRate,
+			Channels:   int(header.Channels),
+			BitDepth:   16,
+			Codec:      "opus",
+		},
+		Duration:   time.Duration(len(samples)) * time.Second / opusSampleRate,
+		SampleRate: opusSampleRate,
+		Channels:   1,
+	}, nil
+}
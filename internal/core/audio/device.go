@@ -0,0 +1,597 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// voiceWaveformPoints is how many waveform points a finished recording carries
+const voiceWaveformPoints = 50
+
+// liveSampleRate is the rate live streams such as calls run the devices at
+const liveSampleRate = 48000
+
+// playbackTick is how often playback progress is reported
+const playbackTick = 100 * time.Millisecond
+
+// deviceBackend opens streams on audio hardware. The PortAudio backend is
+// only built with the portaudio tag; other builds fall back to the mocks.
+type deviceBackend interface {
+	// OpenInput captures mono samples, passing each buffer to onSamples.
+	// The buffer is reused after onSamples returns.
+	OpenInput(sampleRate int, onSamples func(samples []float32)) (audioStream, error)
+
+	// OpenOutput plays mono samples, asking fill for each buffer
+	OpenOutput(sampleRate int, fill func(out []float32)) (audioStream, error)
+
+	// Terminate releases the audio system
+	Terminate() error
+}
+
+// audioStream is an open input or output stream
+type audioStream interface {
+	Close() error
+}
+
+// NewManager returns a manager for the audio hardware. It falls back to the
+// mock manager when the build has no audio backend or no device is present.
+func NewManager() Manager {
+	backend, err := newDeviceBackend()
+	if err != nil {
+		logging.Warnf("Audio devices unavailable, voice messages are simulated: %v", err)
+		return NewMockManager()
+	}
+	return newDeviceManager(backend)
+}
+
+// DeviceManager implements Manager on audio hardware
+type DeviceManager struct {
+	*MockManager // Initialization state and supported formats
+	backend      deviceBackend
+}
+
+// newDeviceManager creates a manager for an opened backend
+func newDeviceManager(backend deviceBackend) *DeviceManager {
+	return &DeviceManager{MockManager: NewMockManager(), backend: backend}
+}
+
+// Shutdown shuts down the audio system and releases the devices
+func (m *DeviceManager) Shutdown() error {
+	if err := m.MockManager.Shutdown(); err != nil {
+		return err
+	}
+	return m.backend.Terminate()
+}
+
+// GetRecorder returns a new recorder instance
+func (m *DeviceManager) GetRecorder() (Recorder, error) {
+	if !m.IsInitialized() {
+		return nil, fmt.Errorf("audio system not initialized")
+	}
+	return &DeviceRecorder{backend: m.backend, state: RecordingStateIdle}, nil
+}
+
+// GetPlayer returns a new player instance
+func (m *DeviceManager) GetPlayer() (Player, error) {
+	if !m.IsInitialized() {
+		return nil, fmt.Errorf("audio system not initialized")
+	}
+	return &DevicePlayer{backend: m.backend, state: PlaybackStateIdle, volume: 1.0}, nil
+}
+
+// SampleRate returns the sample rate of live capture and playback streams
+func (m *DeviceManager) SampleRate() int {
+	return liveSampleRate
+}
+
+// OpenCapture streams mono microphone audio at SampleRate to onSamples,
+// for live audio such as calls. The buffer is reused after onSamples returns.
+func (m *DeviceManager) OpenCapture(onSamples func(samples []float32)) (io.Closer, error) {
+	if !m.IsInitialized() {
+		return nil, fmt.Errorf("audio system not initialized")
+	}
+	stream, err := m.backend.OpenInput(liveSampleRate, onSamples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio input: %w", err)
+	}
+	return stream, nil
+}
+
+// OpenPlayback plays mono audio at SampleRate that fill provides, for live
+// audio such as calls
+func (m *DeviceManager) OpenPlayback(fill func(out []float32)) (io.Closer, error) {
+	if !m.IsInitialized() {
+		return nil, fmt.Errorf("audio system not initialized")
+	}
+	stream, err := m.backend.OpenOutput(liveSampleRate, fill)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio output: %w", err)
+	}
+	return stream, nil
+}
+
+// DeviceRecorder records from the default input device into an Opus or WAV
+// file, as the recording format asks
+type DeviceRecorder struct {
+	mu      sync.RWMutex
+	backend deviceBackend
+	stream  audioStream
+	state   RecordingState
+	options RecordingOptions
+	samples []float32
+	level   float32
+	started time.Time
+	done    chan struct{} // Closed when the input stream is closed
+}
+
+// Start begins recording. The callback receives each captured buffer and the
+// current input level.
+func (r *DeviceRecorder) Start(ctx context.Context, options RecordingOptions, callback RecordingCallback) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state != RecordingStateIdle {
+		return fmt.Errorf("recorder is not idle")
+	}
+	if options.Format.SampleRate <= 0 {
+		options.Format = DefaultVoiceFormat()
+	}
+
+	r.options = options
+	r.samples = nil
+	r.level = 0
+	r.started = time.Now()
+
+	stream, err := r.backend.OpenInput(options.Format.SampleRate, func(samples []float32) {
+		r.capture(samples, callback)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open audio input: %w", err)
+	}
+	r.stream = stream
+	r.state = RecordingStateRecording
+	r.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		select {
+		case <-ctx.Done():
+			r.Cancel()
+		case <-done:
+		}
+	}(r.done)
+	return nil
+}
+
+// capture appends a captured buffer while recording
+func (r *DeviceRecorder) capture(samples []float32, callback RecordingCallback) {
+	r.mu.Lock()
+	if r.state != RecordingStateRecording {
+		r.mu.Unlock()
+		return
+	}
+	r.samples = append(r.samples, samples...)
+	r.level = inputLevel(samples)
+	level := r.level
+	full := r.options.MaxDuration > 0 && r.durationLocked() >= r.options.MaxDuration
+	if full {
+		r.state = RecordingStateStopped
+	}
+	r.mu.Unlock()
+
+	if callback != nil {
+		callback(append([]float32(nil), samples...), level)
+	}
+}
+
+// Stop stops recording and writes the recording file
+func (r *DeviceRecorder) Stop() (*VoiceMessage, error) {
+	r.mu.Lock()
+	if r.state != RecordingStateRecording && r.state != RecordingStatePaused &&
+		!(r.state == RecordingStateStopped && r.stream != nil) {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("not currently recording")
+	}
+	r.state = RecordingStateStopped
+	stream := r.detachStreamLocked()
+	r.mu.Unlock()
+
+	// Closing waits for the capture callback, which takes r.mu
+	closeStream(stream)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	duration := r.durationLocked()
+	if duration < r.options.MinDuration {
+		return nil, fmt.Errorf("recording too short: %v", duration.Round(time.Millisecond))
+	}
+
+	path, format, err := saveVoiceFile(r.options.OutputPath, r.samples, r.options.Format.SampleRate,
+		r.options.Format.Codec, r.options.BitrateKbps)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording file: %w", err)
+	}
+
+	// The waveform comes from the captured PCM, not the encoded file
+	waveform := make([]float32, voiceWaveformPoints)
+	if len(r.samples) > 0 {
+		waveform = computeWaveform(r.samples, voiceWaveformPoints)
+	}
+	return &VoiceMessage{
+		ID:        uuid.New().String(),
+		FilePath:  path,
+		Duration:  duration,
+		Format:    format,
+		FileSize:  info.Size(),
+		CreatedAt: r.started,
+		Waveform:  waveform,
+	}, nil
+}
+
+// Pause temporarily stops recording; captured audio is dropped until Resume
+func (r *DeviceRecorder) Pause() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state != RecordingStateRecording {
+		return fmt.Errorf("not currently recording")
+	}
+	r.state = RecordingStatePaused
+	r.level = 0
+	return nil
+}
+
+// Resume continues recording after pause
+func (r *DeviceRecorder) Resume() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state != RecordingStatePaused {
+		return fmt.Errorf("not currently paused")
+	}
+	r.state = RecordingStateRecording
+	return nil
+}
+
+// GetState returns current recording state
+func (r *DeviceRecorder) GetState() RecordingState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.state
+}
+
+// GetLevel returns the input level of the last captured buffer
+func (r *DeviceRecorder) GetLevel() float32 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.level
+}
+
+// GetDuration returns the length of the audio recorded so far
+func (r *DeviceRecorder) GetDuration() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.durationLocked()
+}
+
+// durationLocked returns the recorded length. The caller must hold r.mu.
+func (r *DeviceRecorder) durationLocked() time.Duration {
+	if r.options.Format.SampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(len(r.samples)) * time.Second / time.Duration(r.options.Format.SampleRate)
+}
+
+// Cancel cancels recording without saving
+func (r *DeviceRecorder) Cancel() error {
+	r.mu.Lock()
+	stream := r.detachStreamLocked()
+	r.state = RecordingStateIdle
+	r.samples = nil
+	r.level = 0
+	r.mu.Unlock()
+
+	closeStream(stream)
+	return nil
+}
+
+// detachStreamLocked takes the input stream so it can be closed once r.mu is
+// released. The caller must hold r.mu.
+func (r *DeviceRecorder) detachStreamLocked() audioStream {
+	if r.done != nil {
+		close(r.done)
+		r.done = nil
+	}
+	stream := r.stream
+	r.stream = nil
+	return stream
+}
+
+// IsSupported returns true; the manager only hands out device recorders
+// when an input device is present
+func (r *DeviceRecorder) IsSupported() bool {
+	return true
+}
+
+// inputLevel maps the RMS of a buffer to 0-1 for a level meter, on a
+// logarithmic scale from -60 dBFS to full scale
+func inputLevel(samples []float32) float32 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, sample := range samples {
+		sum += float64(sample) * float64(sample)
+	}
+	rms := math.Sqrt(sum / float64(len(samples)))
+	if rms <= 0 {
+		return 0
+	}
+	db := 20 * math.Log10(rms)
+	return float32(math.Max(0, math.Min(1, (db+60)/60)))
+}
+
+// closeStream closes a detached stream. The stream's callback must not be
+// blocked on a lock held by the caller, or closing deadlocks.
+func closeStream(stream audioStream) {
+	if stream == nil {
+		return
+	}
+	if err := stream.Close(); err != nil {
+		logging.Warnf("Failed to close audio stream: %v", err)
+	}
+}
+
+// DevicePlayer plays WAV and Ogg Opus files on the default output device
+type DevicePlayer struct {
+	mu         sync.RWMutex
+	backend    deviceBackend
+	stream     audioStream
+	state      PlaybackState
+	samples    []float32
+	sampleRate int
+	cursor     float64 // Position in samples; fractional when speed is not 1
+	options    PlaybackOptions
+	volume     float32
+	done       chan struct{} // Closed when the progress reporter should stop
+}
+
+// Load loads an audio file for playback
+func (p *DevicePlayer) Load(filePath string) error {
+	audioData, err := DecodeFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	p.stop()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.samples = audioData.Samples
+	p.sampleRate = audioData.SampleRate
+	p.cursor = 0
+	p.state = PlaybackStateIdle
+	return nil
+}
+
+// Play starts playback with options and callback
+func (p *DevicePlayer) Play(options PlaybackOptions, callback PlaybackCallback) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sampleRate == 0 {
+		return fmt.Errorf("no file loaded")
+	}
+	if p.state == PlaybackStatePlaying {
+		return fmt.Errorf("already playing")
+	}
+	if options.Speed <= 0 {
+		options.Speed = 1
+	}
+
+	p.options = options
+	p.volume = options.Volume
+	p.cursor = float64(options.StartOffset) * float64(p.sampleRate) / float64(time.Second)
+
+	stream, err := p.backend.OpenOutput(p.sampleRate, p.fill)
+	if err != nil {
+		return fmt.Errorf("failed to open audio output: %w", err)
+	}
+	p.stream = stream
+	p.state = PlaybackStatePlaying
+	p.done = make(chan struct{})
+	go p.reportProgress(p.done, callback)
+	return nil
+}
+
+// fill copies the next samples into an output buffer, or silence while paused
+func (p *DevicePlayer) fill(out []float32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := range out {
+		index := int(p.cursor)
+		if p.state != PlaybackStatePlaying || index >= len(p.samples) {
+			out[i] = 0
+			continue
+		}
+		out[i] = p.samples[index] * p.volume
+		p.cursor += float64(p.options.Speed)
+	}
+}
+
+// reportProgress calls the playback callback until playback ends or stops
+func (p *DevicePlayer) reportProgress(done chan struct{}, callback PlaybackCallback) {
+	ticker := time.NewTicker(playbackTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		var stream audioStream
+		p.mu.Lock()
+		position, duration := p.positionLocked(), p.durationLocked()
+		finished := p.state == PlaybackStatePlaying && int(p.cursor) >= len(p.samples)
+		if finished && p.options.AutoStop {
+			stream = p.detachStreamLocked()
+			p.state = PlaybackStateStopped
+		}
+		p.mu.Unlock()
+		closeStream(stream)
+
+		if callback != nil {
+			callback(position, duration)
+		}
+		if finished && p.options.AutoStop {
+			return
+		}
+	}
+}
+
+// Pause pauses playback
+func (p *DevicePlayer) Pause() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.state != PlaybackStatePlaying {
+		return fmt.Errorf("not currently playing")
+	}
+	p.state = PlaybackStatePaused
+	return nil
+}
+
+// Resume resumes playback
+func (p *DevicePlayer) Resume() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.state != PlaybackStatePaused {
+		return fmt.Errorf("not currently paused")
+	}
+	p.state = PlaybackStatePlaying
+	return nil
+}
+
+// Stop stops playback
+func (p *DevicePlayer) Stop() error {
+	p.stop()
+	return nil
+}
+
+// stop closes the output and rewinds
+func (p *DevicePlayer) stop() {
+	p.mu.Lock()
+	stream := p.detachStreamLocked()
+	if p.state != PlaybackStateIdle {
+		p.state = PlaybackStateStopped
+	}
+	p.cursor = 0
+	p.mu.Unlock()
+
+	// Closing waits for the fill callback, which takes p.mu
+	closeStream(stream)
+}
+
+// detachStreamLocked takes the output stream so it can be closed once p.mu is
+// released, and stops progress reports. The caller must hold p.mu.
+func (p *DevicePlayer) detachStreamLocked() audioStream {
+	if p.done != nil {
+		close(p.done)
+		p.done = nil
+	}
+	stream := p.stream
+	p.stream = nil
+	return stream
+}
+
+// Seek seeks to a specific position
+func (p *DevicePlayer) Seek(position time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if position < 0 {
+		position = 0
+	}
+	if duration := p.durationLocked(); position > duration {
+		position = duration
+	}
+	p.cursor = float64(position) * float64(p.sampleRate) / float64(time.Second)
+	return nil
+}
+
+// GetState returns current playback state
+func (p *DevicePlayer) GetState() PlaybackState {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.state
+}
+
+// GetPosition returns current playback position
+func (p *DevicePlayer) GetPosition() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.positionLocked()
+}
+
+// positionLocked returns the playback position. The caller must hold p.mu.
+func (p *DevicePlayer) positionLocked() time.Duration {
+	if p.sampleRate == 0 {
+		return 0
+	}
+	cursor := math.Min(p.cursor, float64(len(p.samples)))
+	return time.Duration(cursor * float64(time.Second) / float64(p.sampleRate))
+}
+
+// GetDuration returns total audio duration
+func (p *DevicePlayer) GetDuration() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.durationLocked()
+}
+
+// durationLocked returns the loaded audio's length. The caller must hold p.mu.
+func (p *DevicePlayer) durationLocked() time.Duration {
+	if p.sampleRate == 0 {
+		return 0
+	}
+	return time.Duration(len(p.samples)) * time.Second / time.Duration(p.sampleRate)
+}
+
+// SetVolume sets playback volume
+func (p *DevicePlayer) SetVolume(volume float32) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.volume = float32(math.Max(0, math.Min(1, float64(volume))))
+	return nil
+}
+
+// GetVolume returns current volume
+func (p *DevicePlayer) GetVolume() float32 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.volume
+}
+
+// IsSupported returns true; the manager only hands out device players
+// when an output device is present
+func (p *DevicePlayer) IsSupported() bool {
+	return true
+}
@@ -0,0 +1,192 @@
+package audio
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeBackend lets tests drive device callbacks by hand
+type fakeBackend struct {
+	onSamples func([]float32)
+	fill      func([]float32)
+	closed    atomic.Int32 // Streams are closed off the test goroutine
+}
+
+func (b *fakeBackend) OpenInput(sampleRate int, onSamples func([]float32)) (audioStream, error) {
+	b.onSamples = onSamples
+	return b, nil
+}
+
+func (b *fakeBackend) OpenOutput(sampleRate int, fill func([]float32)) (audioStream, error) {
+	b.fill = fill
+	return b, nil
+}
+
+func (b *fakeBackend) Terminate() error { return nil }
+
+func (b *fakeBackend) Close() error {
+	b.closed.Add(1)
+	return nil
+}
+
+func newTestDeviceManager(t *testing.T) (*DeviceManager, *fakeBackend) {
+	backend := &fakeBackend{}
+	mgr := newDeviceManager(backend)
+	if err := mgr.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return mgr, backend
+}
+
+func TestDeviceRecorderWritesWAV(t *testing.T) {
+	mgr, backend := newTestDeviceManager(t)
+	recorder, err := mgr.GetRecorder()
+	if err != nil {
+		t.Fatalf("GetRecorder failed: %v", err)
+	}
+
+	options := DefaultRecordingOptions()
+	options.Format = AudioFormat{SampleRate: 8000, Channels: 1, BitDepth: 16, Codec: "wav"}
+	options.MinDuration = 0
+	options.OutputPath = filepath.Join(t.TempDir(), "voice.wav")
+
+	var levels []float32
+	if err := recorder.Start(context.Background(), options, func(_ []float32, level float32) {
+		levels = append(levels, level)
+	}); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	loud := make([]float32, 4000)
+	for i := range loud {
+		loud[i] = 0.5
+	}
+	backend.onSamples(make([]float32, 4000))
+	backend.onSamples(loud)
+
+	if err := recorder.Pause(); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	backend.onSamples(loud) // Dropped while paused
+	if err := recorder.Resume(); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+
+	if len(levels) != 2 || levels[0] != 0 || levels[1] <= levels[0] {
+		t.Errorf("Expected a silent then a loud level, got %v", levels)
+	}
+
+	voiceMsg, err := recorder.Stop()
+	if err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if backend.closed.Load() != 1 {
+		t.Errorf("Expected the input stream to be closed once, got %d", backend.closed.Load())
+	}
+	if voiceMsg.Format.Codec != "wav" {
+		t.Errorf("Format codec = %q, want wav", voiceMsg.Format.Codec)
+	}
+	if voiceMsg.Duration != time.Second {
+		t.Errorf("Duration = %v, want 1s", voiceMsg.Duration)
+	}
+	if voiceMsg.FileSize <= 0 || len(voiceMsg.Waveform) != voiceWaveformPoints {
+		t.Errorf("Unexpected voice message: size %d, %d waveform points", voiceMsg.FileSize, len(voiceMsg.Waveform))
+	}
+	if voiceMsg.Waveform[0] != 0 || voiceMsg.Waveform[voiceWaveformPoints-1] != 1 {
+		t.Errorf("Expected waveform to rise from silence, got %v", voiceMsg.Waveform)
+	}
+
+	audioData, err := DecodeFile(voiceMsg.FilePath)
+	if err != nil {
+		t.Fatalf("Recording is not a valid WAV: %v", err)
+	}
+	if audioData.SampleRate != 8000 || len(audioData.Samples) != 8000 {
+		t.Errorf("Decoded %d samples at %d Hz, want 8000 at 8000 Hz", len(audioData.Samples), audioData.SampleRate)
+	}
+}
+
+func TestDeviceRecorderCancel(t *testing.T) {
+	mgr, backend := newTestDeviceManager(t)
+	recorder, _ := mgr.GetRecorder()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := recorder.Start(ctx, DefaultRecordingOptions(), nil); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for backend.closed.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if recorder.GetState() != RecordingStateIdle {
+		t.Fatal("Expected cancelling the context to cancel the recording")
+	}
+	if backend.closed.Load() != 1 {
+		t.Errorf("Expected the input stream to be closed once, got %d", backend.closed.Load())
+	}
+	if _, err := recorder.Stop(); err == nil {
+		t.Error("Expected Stop after cancel to fail")
+	}
+}
+
+func TestDevicePlayer(t *testing.T) {
+	mgr, backend := newTestDeviceManager(t)
+	player, _ := mgr.GetPlayer()
+
+	path := filepath.Join(t.TempDir(), "tone.wav")
+	samples := make([]float32, 800)
+	for i := range samples {
+		samples[i] = 0.5
+	}
+	if err := WriteWAV(path, samples, 8000); err != nil {
+		t.Fatalf("WriteWAV failed: %v", err)
+	}
+	if err := player.Load(path); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if player.GetDuration() != 100*time.Millisecond {
+		t.Errorf("Duration = %v, want 100ms", player.GetDuration())
+	}
+
+	options := DefaultPlaybackOptions()
+	options.Volume = 0.5
+	if err := player.Play(options, nil); err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+
+	out := make([]float32, 400)
+	backend.fill(out)
+	if out[0] < 0.24 || out[0] > 0.26 {
+		t.Errorf("Expected samples scaled by volume to 0.25, got %v", out[0])
+	}
+	if player.GetPosition() != 50*time.Millisecond {
+		t.Errorf("Position = %v, want 50ms", player.GetPosition())
+	}
+
+	if err := player.Pause(); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	backend.fill(out)
+	if out[0] != 0 || player.GetPosition() != 50*time.Millisecond {
+		t.Error("Expected silence and no progress while paused")
+	}
+	if err := player.Resume(); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+
+	backend.fill(make([]float32, 800)) // Past the end
+	deadline := time.Now().Add(time.Second)
+	for backend.closed.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if player.GetState() != PlaybackStateStopped {
+		t.Fatal("Expected playback to stop at the end of the file")
+	}
+	if backend.closed.Load() != 1 {
+		t.Errorf("Expected the output stream to be closed once, got %d", backend.closed.Load())
+	}
+}
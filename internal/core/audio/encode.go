@@ -0,0 +1,116 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// opusPreSkip is the libopus encoder lookahead at 48 kHz, which decoders
+// drop from the start of the stream
+const opusPreSkip = 312
+
+// opusMaxPacket is the largest packet libopus is asked to produce
+const opusMaxPacket = 4000
+
+// opusEncoder encodes 20 ms frames of mono audio. The libopus encoder is
+// only built with the opus tag; other builds save voice messages as WAV.
+type opusEncoder interface {
+	// Encode encodes one frame into packet and returns the packet length
+	Encode(pcm []float32, packet []byte) (int, error)
+}
+
+// openOpusEncoder creates the Opus encoder, replaced in tests
+var openOpusEncoder = newOpusEncoder
+
+// validOpusRate reports whether Opus can encode at a sample rate
+func validOpusRate(sampleRate int) bool {
+	switch sampleRate {
+	case 8000, 12000, 16000, 24000, opusSampleRate:
+		return true
+	}
+	return false
+}
+
+// EncodeOggOpus encodes mono samples to an Ogg Opus file at the given
+// bitrate. The last frame is padded with silence.
+func EncodeOggOpus(samples []float32, sampleRate, bitrateKbps int) ([]byte, error) {
+	if !validOpusRate(sampleRate) {
+		return nil, fmt.Errorf("unsupported Opus sample rate: %d Hz", sampleRate)
+	}
+	encoder, err := openOpusEncoder(sampleRate, bitrateKbps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Opus encoder: %w", err)
+	}
+
+	frameSize := sampleRate / 50 // 20 ms
+	frame := make([]float32, frameSize)
+	packet := make([]byte, opusMaxPacket)
+	var packets [][]byte
+	for start := 0; start < len(samples) || len(packets) == 0; start += frameSize {
+		end := min(start+frameSize, len(samples))
+		clear(frame[copy(frame, samples[start:end]):])
+
+		n, err := encoder.Encode(frame, packet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode Opus frame: %w", err)
+		}
+		packets = append(packets, append([]byte(nil), packet[:n]...))
+	}
+
+	scale := opusSampleRate / sampleRate // Granule positions count at 48 kHz
+	return writeOggOpus(packets, frameSize*scale, len(samples)*scale, opusPreSkip, sampleRate)
+}
+
+// VoiceCodec returns the codec voice messages are saved in when codec is
+// configured: WAV where this build cannot encode Opus. Empty means Opus.
+func VoiceCodec(codec string) string {
+	if codec == "" {
+		codec = DefaultVoiceFormat().Codec
+	}
+	if codec == "opus" && !opusSupported {
+		return "wav"
+	}
+	return codec
+}
+
+// saveVoiceFile writes recorded samples in the requested codec, "opus" or
+// "wav". When Opus is unavailable it falls back to WAV. The file extension
+// is changed to match, and a temporary file is used if path is empty. It
+// returns the path written and the format actually used.
+func saveVoiceFile(path string, samples []float32, sampleRate int, codec string, bitrateKbps int) (string, AudioFormat, error) {
+	format := AudioFormat{SampleRate: sampleRate, Channels: 1, BitDepth: 16, Codec: "wav"}
+	var data []byte
+	if codec == "opus" {
+		encoded, err := EncodeOggOpus(samples, sampleRate, bitrateKbps)
+		if err == nil {
+			data = encoded
+			format.Codec = "opus"
+		} else {
+			logging.Warnf("Saving voice message as WAV: %v", err)
+		}
+	}
+	if data == nil {
+		data = EncodeWAV(samples, sampleRate)
+	}
+
+	ext := "." + format.Codec
+	if path == "" {
+		file, err := os.CreateTemp("", "whisp-voice-*"+ext)
+		if err != nil {
+			return "", format, fmt.Errorf("failed to create recording file: %w", err)
+		}
+		file.Close()
+		path = file.Name()
+	} else {
+		path = strings.TrimSuffix(path, filepath.Ext(path)) + ext
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", format, fmt.Errorf("failed to write recording: %w", err)
+	}
+	return path, format, nil
+}
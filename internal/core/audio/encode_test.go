@@ -0,0 +1,177 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/pion/opus/pkg/oggreader"
+)
+
+// fakeOpusEncoder emits packets of a fixed size holding the frame index
+type fakeOpusEncoder struct {
+	size   int
+	frames int
+}
+
+func (e *fakeOpusEncoder) Encode(pcm []float32, packet []byte) (int, error) {
+	for i := 0; i < e.size; i++ {
+		packet[i] = byte(e.frames)
+	}
+	e.frames++
+	return e.size, nil
+}
+
+// withOpusEncoder replaces the Opus encoder for one test
+func withOpusEncoder(t *testing.T, open func(sampleRate, bitrateKbps int) (opusEncoder, error)) {
+	previous := openOpusEncoder
+	openOpusEncoder = open
+	t.Cleanup(func() { openOpusEncoder = previous })
+}
+
+func TestEncodeOggOpusContainer(t *testing.T) {
+	tests := []struct {
+		name        string
+		sampleRate  int
+		samples     int
+		packetSize  int
+		wantPackets int
+	}{
+		{name: "48 kHz with partial last frame", sampleRate: 48000, samples: 2500, packetSize: 80, wantPackets: 3},
+		{name: "16 kHz", sampleRate: 16000, samples: 640, packetSize: 40, wantPackets: 2},
+		{name: "packet spanning segments", sampleRate: 48000, samples: 960, packetSize: 600, wantPackets: 1},
+		{name: "empty recording", sampleRate: 48000, samples: 0, packetSize: 3, wantPackets: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withOpusEncoder(t, func(int, int) (opusEncoder, error) {
+				return &fakeOpusEncoder{size: tt.packetSize}, nil
+			})
+
+			data, err := EncodeOggOpus(make([]float32, tt.samples), tt.sampleRate, 32)
+			if err != nil {
+				t.Fatalf("EncodeOggOpus failed: %v", err)
+			}
+
+			ogg, header, err := oggreader.NewWith(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Not a valid Ogg Opus stream: %v", err)
+			}
+			if header.Channels != 1 || header.PreSkip != opusPreSkip || header.SampleRate != uint32(tt.sampleRate) {
+				t.Errorf("Unexpected OpusHead: %+v", header)
+			}
+
+			var packets [][]byte
+			var packet []byte
+			var granule uint64
+			for {
+				segments, page, err := ogg.ParseNextPage()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Failed to read page: %v", err)
+				}
+				granule = page.GranulePosition
+				for _, segment := range segments {
+					packet = append(packet, segment...)
+					if len(segment) < 255 {
+						packets = append(packets, packet)
+						packet = nil
+					}
+				}
+			}
+
+			packets = packets[1:] // OpusTags
+			if len(packets) != tt.wantPackets {
+				t.Fatalf("Got %d audio packets, want %d", len(packets), tt.wantPackets)
+			}
+			for i, p := range packets {
+				if len(p) != tt.packetSize || p[0] != byte(i) {
+					t.Errorf("Packet %d has %d bytes starting %d", i, len(p), p[0])
+				}
+			}
+			wantGranule := uint64(tt.samples*48000/tt.sampleRate + opusPreSkip)
+			if granule != wantGranule {
+				t.Errorf("Final granule position = %d, want %d", granule, wantGranule)
+			}
+		})
+	}
+}
+
+func TestEncodeOggOpusRejectsSampleRate(t *testing.T) {
+	withOpusEncoder(t, func(int, int) (opusEncoder, error) {
+		return &fakeOpusEncoder{size: 1}, nil
+	})
+	if _, err := EncodeOggOpus(make([]float32, 100), 44100, 32); err == nil {
+		t.Error("Expected 44.1 kHz to be rejected")
+	}
+}
+
+func TestSaveVoiceFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		codec     string
+		encoder   error // Error from opening the encoder, nil for a working one
+		wantCodec string
+		wantExt   string
+	}{
+		{name: "opus", codec: "opus", wantCodec: "opus", wantExt: ".opus"},
+		{name: "wav requested", codec: "wav", wantCodec: "wav", wantExt: ".wav"},
+		{name: "opus unavailable", codec: "opus", encoder: fmt.Errorf("no encoder"), wantCodec: "wav", wantExt: ".wav"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withOpusEncoder(t, func(int, int) (opusEncoder, error) {
+				if tt.encoder != nil {
+					return nil, tt.encoder
+				}
+				return &fakeOpusEncoder{size: 10}, nil
+			})
+
+			requested := filepath.Join(t.TempDir(), "voice.wav")
+			path, format, err := saveVoiceFile(requested, make([]float32, 4800), 48000, tt.codec, 32)
+			if err != nil {
+				t.Fatalf("saveVoiceFile failed: %v", err)
+			}
+			if format.Codec != tt.wantCodec || filepath.Ext(path) != tt.wantExt {
+				t.Errorf("Saved %s as %s, want %s as %s", path, format.Codec, tt.wantExt, tt.wantCodec)
+			}
+			if tt.wantCodec == "wav" {
+				audioData, err := DecodeFile(path)
+				if err != nil {
+					t.Fatalf("Fallback is not a valid WAV: %v", err)
+				}
+				if len(audioData.Samples) != 4800 {
+					t.Errorf("Decoded %d samples, want 4800", len(audioData.Samples))
+				}
+			}
+		})
+	}
+}
+
+func TestVoiceCodec(t *testing.T) {
+	opus := "wav"
+	if opusSupported {
+		opus = "opus"
+	}
+	tests := []struct {
+		codec string
+		want  string
+	}{
+		{"wav", "wav"},
+		{"opus", opus},
+		{"", opus}, // Configs from before the setting
+	}
+
+	for _, tt := range tests {
+		if got := VoiceCodec(tt.codec); got != tt.want {
+			t.Errorf("VoiceCodec(%q) = %q, want %q", tt.codec, got, tt.want)
+		}
+	}
+}
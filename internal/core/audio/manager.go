@@ -95,7 +95,7 @@ func (m *MockManager) GetPlayer() (Player, error) {
 
 // GetWaveformGenerator returns waveform generator
 func (m *MockManager) GetWaveformGenerator() WaveformGenerator {
-	return NewMockWaveformGenerator()
+	return NewWaveformAnalyzer()
 }
 
 // GetSupportedFormats returns list of supported audio formats
@@ -0,0 +1,126 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Ogg page header types and layout (RFC 3533)
+const (
+	oggHeaderBOS      = 0x02
+	oggHeaderEOS      = 0x04
+	oggPageHeaderSize = 27
+	oggMaxSegments    = 255
+)
+
+// oggStreamSerial identifies the single logical stream in files Whisp writes
+const oggStreamSerial = 0x57485350 // "WHSP"
+
+// oggCRCTable is the lookup table for the Ogg page checksum, a CRC-32 with
+// polynomial 0x04c11db7 and no bit reflection
+var oggCRCTable = func() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+// oggWriter writes packets of one logical stream as Ogg pages, one packet
+// per page. Voice packets are far smaller than the 65025 bytes a page holds.
+type oggWriter struct {
+	buf      bytes.Buffer
+	sequence uint32
+}
+
+// writePacket writes a packet as its own page
+func (w *oggWriter) writePacket(packet []byte, granule uint64, headerType byte) error {
+	segments := len(packet)/255 + 1
+	if segments > oggMaxSegments {
+		return fmt.Errorf("Ogg packet too large: %d bytes", len(packet))
+	}
+
+	page := make([]byte, oggPageHeaderSize, oggPageHeaderSize+segments+len(packet))
+	copy(page, "OggS")
+	page[5] = headerType
+	binary.LittleEndian.PutUint64(page[6:], granule)
+	binary.LittleEndian.PutUint32(page[14:], oggStreamSerial)
+	binary.LittleEndian.PutUint32(page[18:], w.sequence)
+	page[26] = byte(segments)
+	for i := 0; i < segments-1; i++ {
+		page = append(page, 255)
+	}
+	page = append(page, byte(len(packet)%255))
+	page = append(page, packet...)
+
+	var crc uint32
+	for _, b := range page {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	binary.LittleEndian.PutUint32(page[22:], crc)
+
+	w.buf.Write(page)
+	w.sequence++
+	return nil
+}
+
+// opusHead builds the Opus identification header (RFC 7845 section 5.1) for
+// a mono stream
+func opusHead(preSkip uint16, inputSampleRate int) []byte {
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1 // Version
+	head[9] = 1 // Channels
+	binary.LittleEndian.PutUint16(head[10:], preSkip)
+	binary.LittleEndian.PutUint32(head[12:], uint32(inputSampleRate))
+	// Output gain 0 and channel mapping family 0 are the zero bytes left
+	return head
+}
+
+// opusTags builds the Opus comment header (RFC 7845 section 5.2) with no
+// user comments
+func opusTags() []byte {
+	const vendor = "whisp"
+	tags := make([]byte, 0, 8+4+len(vendor)+4)
+	tags = append(tags, "OpusTags"...)
+	tags = binary.LittleEndian.AppendUint32(tags, uint32(len(vendor)))
+	tags = append(tags, vendor...)
+	return binary.LittleEndian.AppendUint32(tags, 0)
+}
+
+// writeOggOpus wraps Opus packets of frameSize samples in an Ogg Opus file.
+// Both frameSize and samples, the length of the input, count at 48 kHz as
+// granule positions do; the final granule position trims the padding of the
+// last frame.
+func writeOggOpus(packets [][]byte, frameSize int, samples int, preSkip uint16, inputSampleRate int) ([]byte, error) {
+	w := &oggWriter{}
+	if err := w.writePacket(opusHead(preSkip, inputSampleRate), 0, oggHeaderBOS); err != nil {
+		return nil, err
+	}
+	if err := w.writePacket(opusTags(), 0, 0); err != nil {
+		return nil, err
+	}
+
+	var granule uint64
+	for i, packet := range packets {
+		granule += uint64(frameSize)
+		var headerType byte
+		if i == len(packets)-1 {
+			headerType = oggHeaderEOS
+			granule = uint64(samples) + uint64(preSkip)
+		}
+		if err := w.writePacket(packet, granule, headerType); err != nil {
+			return nil, err
+		}
+	}
+	return w.buf.Bytes(), nil
+}
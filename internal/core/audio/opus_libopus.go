@@ -0,0 +1,46 @@
+//go:build opus
+
+package audio
+
+import (
+	"fmt"
+
+	"gopkg.in/hraban/opus.v2"
+)
+
+// opusSupported reports whether this build can encode Opus
+const opusSupported = true
+
+// Bitrates the encoder is clamped to. Above the maximum libopus may switch
+// wideband speech to CELT, which pion/opus cannot decode.
+const (
+	opusMinBitrateKbps = 6
+	opusMaxBitrateKbps = 40
+)
+
+// libopusEncoder encodes with libopus
+type libopusEncoder struct {
+	encoder *opus.Encoder
+}
+
+// newOpusEncoder creates a libopus voice encoder limited to wideband, so
+// every frame is SILK and plays back through DecodeOggOpus
+func newOpusEncoder(sampleRate, bitrateKbps int) (opusEncoder, error) {
+	encoder, err := opus.NewEncoder(sampleRate, 1, opus.AppVoIP)
+	if err != nil {
+		return nil, err
+	}
+	if err := encoder.SetMaxBandwidth(opus.Wideband); err != nil {
+		return nil, fmt.Errorf("failed to limit Opus bandwidth: %w", err)
+	}
+	bitrate := max(opusMinBitrateKbps, min(opusMaxBitrateKbps, bitrateKbps))
+	if err := encoder.SetBitrate(bitrate * 1000); err != nil {
+		return nil, fmt.Errorf("failed to set Opus bitrate: %w", err)
+	}
+	return libopusEncoder{encoder: encoder}, nil
+}
+
+// Encode encodes one frame
+func (e libopusEncoder) Encode(pcm []float32, packet []byte) (int, error) {
+	return e.encoder.EncodeFloat32(pcm, packet)
+}
@@ -0,0 +1,54 @@
+//go:build opus
+
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// TestOpusRoundTrip encodes a tone with libopus and decodes it with pion/opus
+func TestOpusRoundTrip(t *testing.T) {
+	const frequency = 440.0
+	samples := make([]float32, opusSampleRate) // One second
+	for i := range samples {
+		samples[i] = float32(0.5 * math.Sin(2*math.Pi*frequency*float64(i)/opusSampleRate))
+	}
+
+	for _, bitrate := range []int{16, 32} {
+		data, err := EncodeOggOpus(samples, opusSampleRate, bitrate)
+		if err != nil {
+			t.Fatalf("EncodeOggOpus(%d kbps) failed: %v", bitrate, err)
+		}
+		if len(data) >= len(EncodeWAV(samples, opusSampleRate))/4 {
+			t.Errorf("%d kbps: Opus file of %d bytes is not much smaller than WAV", bitrate, len(data))
+		}
+
+		audioData, err := DecodeOggOpus(data)
+		if err != nil {
+			t.Fatalf("DecodeOggOpus(%d kbps) failed: %v", bitrate, err)
+		}
+		if len(audioData.Samples) != len(samples) {
+			t.Errorf("%d kbps: decoded %d samples, want %d", bitrate, len(audioData.Samples), len(samples))
+		}
+
+		// Skip the encoder's start-up and compare level and pitch
+		decoded := audioData.Samples[opusSampleRate/10:]
+		var sum float64
+		var crossings int
+		for i, sample := range decoded {
+			sum += float64(sample) * float64(sample)
+			if i > 0 && (decoded[i-1] < 0) != (sample < 0) {
+				crossings++
+			}
+		}
+		rms := math.Sqrt(sum / float64(len(decoded)))
+		if want := 0.5 / math.Sqrt2; math.Abs(rms-want) > want/3 {
+			t.Errorf("%d kbps: decoded RMS %.3f, want about %.3f", bitrate, rms, want)
+		}
+		pitch := float64(crossings) / 2 / (float64(len(decoded)) / opusSampleRate)
+		if math.Abs(pitch-frequency) > frequency*0.05 {
+			t.Errorf("%d kbps: decoded pitch %.0f Hz, want %.0f Hz", bitrate, pitch, frequency)
+		}
+	}
+}
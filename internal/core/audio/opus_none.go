@@ -0,0 +1,14 @@
+//go:build !opus
+
+package audio
+
+import "fmt"
+
+// opusSupported reports whether this build can encode Opus
+const opusSupported = false
+
+// newOpusEncoder reports that this build has no Opus encoder. Build with the
+// opus tag to save voice messages as Opus.
+func newOpusEncoder(sampleRate, bitrateKbps int) (opusEncoder, error) {
+	return nil, fmt.Errorf("built without an Opus encoder")
+}
@@ -0,0 +1,141 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// WAV sample encodings
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// DecodeWAV decodes PCM (8, 16, 24 or 32 bit) or 32-bit float WAV data,
+// mixing all channels down to mono
+func DecodeWAV(data []byte) (*AudioData, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a WAV file")
+	}
+
+	var format, channels, bitDepth int
+	var sampleRate int
+	var pcm []byte
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := data[offset+8:]
+		if size > len(body) {
+			size = len(body) // Truncated file; use what is there
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("invalid WAV format chunk")
+			}
+			format = int(binary.LittleEndian.Uint16(body[0:2]))
+			channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			sampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bitDepth = int(binary.LittleEndian.Uint16(body[14:16]))
+			if format == wavFormatExtensible && size >= 26 {
+				format = int(binary.LittleEndian.Uint16(body[24:26]))
+			}
+		case "data":
+			pcm = body
+		}
+		offset += 8 + size + size%2 // Chunks are padded to even sizes
+	}
+
+	if channels <= 0 || sampleRate <= 0 {
+		return nil, fmt.Errorf("WAV file has no format chunk")
+	}
+	sampleSize := bitDepth / 8
+	switch {
+	case format == wavFormatPCM && sampleSize >= 1 && sampleSize <= 4:
+	case format == wavFormatFloat && sampleSize == 4:
+	default:
+		return nil, fmt.Errorf("unsupported WAV encoding %d with %d bits", format, bitDepth)
+	}
+
+	frameSize := sampleSize * channels
+	frames := len(pcm) / frameSize
+	samples := make([]float32, frames)
+	for i := 0; i < frames; i++ {
+		var sum float32
+		for c := 0; c < channels; c++ {
+			start := i*frameSize + c*sampleSize
+			sum += decodeSample(pcm[start:start+sampleSize], format)
+		}
+		samples[i] = sum / float32(channels)
+	}
+
+	return &AudioData{
+		Samples: samples,
+		Format: AudioFormat{
+			SampleRate: sampleRate,
+			Channels:   channels,
+			BitDepth:   bitDepth,
+			Codec:      "wav",
+		},
+		Duration:   time.Duration(frames) * time.Second / time.Duration(sampleRate),
+		SampleRate: sampleRate,
+		Channels:   1,
+	}, nil
+}
+
+// decodeSample converts one little-endian WAV sample to [-1, 1]
+func decodeSample(b []byte, format int) float32 {
+	if format == wavFormatFloat {
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	}
+	switch len(b) {
+	case 1:
+		return (float32(b[0]) - 128) / 128 // 8-bit WAV is unsigned
+	case 2:
+		return float32(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case 3:
+		v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+		return float32(v) / (1 << 23)
+	default:
+		return float32(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	}
+}
+
+// EncodeWAV encodes mono samples in [-1, 1] as 16-bit PCM WAV data
+func EncodeWAV(samples []float32, sampleRate int) []byte {
+	var buf bytes.Buffer
+	dataSize := len(samples) * 2
+
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(wavFormatPCM))
+	binary.Write(&buf, binary.LittleEndian, uint16(1)) // Mono
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*2)) // Byte rate
+	binary.Write(&buf, binary.LittleEndian, uint16(2))            // Block align
+	binary.Write(&buf, binary.LittleEndian, uint16(16))           // Bits per sample
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+	for _, sample := range samples {
+		sample = float32(math.Max(-1, math.Min(1, float64(sample))))
+		binary.Write(&buf, binary.LittleEndian, int16(sample*math.MaxInt16))
+	}
+	return buf.Bytes()
+}
+
+// WriteWAV writes mono samples to a 16-bit PCM WAV file
+func WriteWAV(filePath string, samples []float32, sampleRate int) error {
+	if err := os.WriteFile(filePath, EncodeWAV(samples, sampleRate), 0o600); err != nil {
+		return fmt.Errorf("failed to write WAV file: %w", err)
+	}
+	return nil
+}
@@ -1,84 +1,129 @@
 package audio
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"os"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
 )
 
-// MockWaveformGenerator implements WaveformGenerator for demonstration
-type MockWaveformGenerator struct{}
+// waveformCacheSuffix names the file next to a voice message that caches its waveform
+const waveformCacheSuffix = ".waveform"
+
+// WaveformAnalyzer computes waveforms from the audio in voice messages and
+// caches them next to the file so they are not recomputed on every render
+type WaveformAnalyzer struct{}
 
-// NewMockWaveformGenerator creates a new mock waveform generator
-func NewMockWaveformGenerator() *MockWaveformGenerator {
-	return &MockWaveformGenerator{}
+// NewWaveformAnalyzer creates a new waveform analyzer
+func NewWaveformAnalyzer() *WaveformAnalyzer {
+	return &WaveformAnalyzer{}
 }
 
-// GenerateWaveform generates simplified waveform data from audio
-func (w *MockWaveformGenerator) GenerateWaveform(audioData *AudioData, points int) ([]float32, error) {
+// GenerateWaveform returns the RMS level of points equal-width buckets of the
+// audio, normalized so the loudest bucket is 1. Silent audio yields all zeros.
+func (w *WaveformAnalyzer) GenerateWaveform(audioData *AudioData, points int) ([]float32, error) {
 	if audioData == nil || len(audioData.Samples) == 0 || points <= 0 {
 		return nil, fmt.Errorf("invalid audio data or points")
 	}
+	return computeWaveform(audioData.Samples, points), nil
+}
 
-	waveform := make([]float32, points)
-	samplesPerPoint := len(audioData.Samples) / points
-
-	if samplesPerPoint == 0 {
-		samplesPerPoint = 1
+// GenerateWaveformFromFile decodes a WAV or Ogg Opus file and returns its
+// waveform, from the cache when the file has not changed
+func (w *WaveformAnalyzer) GenerateWaveformFromFile(filePath string, points int) ([]float32, error) {
+	if points <= 0 {
+		return nil, fmt.Errorf("invalid points count")
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio file: %w", err)
 	}
 
-	for i := 0; i < points; i++ {
-		start := i * samplesPerPoint
-		end := start + samplesPerPoint
-		if end > len(audioData.Samples) {
-			end = len(audioData.Samples)
-		}
+	if waveform, ok := loadCachedWaveform(filePath, info, points); ok {
+		return waveform, nil
+	}
 
-		// Calculate RMS for this segment
-		var sum float64
-		for j := start; j < end; j++ {
-			sample := float64(audioData.Samples[j])
-			sum += sample * sample
-		}
-		rms := math.Sqrt(sum / float64(end-start))
-		waveform[i] = float32(rms)
+	audioData, err := DecodeFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	waveform := make([]float32, points) // An empty recording is silence
+	if len(audioData.Samples) > 0 {
+		waveform = computeWaveform(audioData.Samples, points)
 	}
 
+	if err := saveCachedWaveform(filePath, info, waveform); err != nil {
+		logging.Warnf("Failed to cache waveform: %v", err)
+	}
 	return waveform, nil
 }
 
-// GenerateWaveformFromFile generates waveform from audio file
-func (w *MockWaveformGenerator) GenerateWaveformFromFile(filePath string, points int) ([]float32, error) {
-	// For demo purposes, generate a synthetic waveform
-	// In a real implementation, this would read and parse the audio file
+// computeWaveform splits samples into points buckets and normalizes their RMS
+// levels. With fewer samples than buckets, neighbouring buckets share a sample.
+func computeWaveform(samples []float32, points int) []float32 {
+	levels := make([]float64, points)
+	var peak float64
+	for i := range levels {
+		start := i * len(samples) / points
+		end := (i + 1) * len(samples) / points
+		if end <= start {
+			end = start + 1
+		}
 
-	if points <= 0 {
-		return nil, fmt.Errorf("invalid points count")
+		var sum float64
+		for _, sample := range samples[start:end] {
+			sum += float64(sample) * float64(sample)
+		}
+		levels[i] = math.Sqrt(sum / float64(end-start))
+		peak = math.Max(peak, levels[i])
 	}
 
 	waveform := make([]float32, points)
+	if peak == 0 {
+		return waveform
+	}
+	for i, level := range levels {
+		waveform[i] = float32(level / peak)
+	}
+	return waveform
+}
 
-	// Generate a synthetic waveform that looks realistic
-	for i := 0; i < points; i++ {
-		t := float64(i) / float64(points)
-
-		// Create a waveform with peaks and valleys
-		amplitude := 0.3 + 0.7*math.Sin(t*math.Pi*4) // Base wave
-		amplitude *= (1 - t*0.5)                     // Fade out over time
-		amplitude += 0.1 * math.Sin(t*math.Pi*20)    // Add some variation
-
-		// Add some randomness
-		amplitude += (math.Sin(t*123.456) * 0.1)
-
-		// Ensure positive and reasonable bounds
-		if amplitude < 0 {
-			amplitude = 0
-		}
-		if amplitude > 1 {
-			amplitude = 1
-		}
+// cachedWaveform is the cache file format; the file's size and modification
+// time tell whether the audio changed since
+type cachedWaveform struct {
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	Waveform []float32 `json:"waveform"`
+}
 
-		waveform[i] = float32(amplitude)
+// loadCachedWaveform returns the cached waveform of a file if it is current
+func loadCachedWaveform(filePath string, info os.FileInfo, points int) ([]float32, bool) {
+	data, err := os.ReadFile(filePath + waveformCacheSuffix)
+	if err != nil {
+		return nil, false
+	}
+	var cached cachedWaveform
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, false
 	}
+	if cached.Size != info.Size() || !cached.ModTime.Equal(info.ModTime()) || len(cached.Waveform) != points {
+		return nil, false
+	}
+	return cached.Waveform, true
+}
 
-	return waveform, nil
+// saveCachedWaveform writes the waveform cache next to the audio file
+func saveCachedWaveform(filePath string, info os.FileInfo, waveform []float32) error {
+	data, err := json.Marshal(cachedWaveform{
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+		Waveform: waveform,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath+waveformCacheSuffix, data, 0o600)
 }
@@ -0,0 +1,108 @@
+package audio
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestComputeWaveform(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float32
+		points  int
+		want    []float32
+	}{
+		{
+			name:    "quiet then loud",
+			samples: []float32{0.25, -0.25, 0.25, -0.25, 0.5, -0.5, 0.5, -0.5},
+			points:  2,
+			want:    []float32{0.5, 1},
+		},
+		{
+			name:    "silence",
+			samples: []float32{0, 0, 0, 0},
+			points:  3,
+			want:    []float32{0, 0, 0},
+		},
+		{
+			name:    "fewer samples than buckets",
+			samples: []float32{0.5, 1},
+			points:  4,
+			want:    []float32{0.5, 0.5, 1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeWaveform(tt.samples, tt.points)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Got %d points, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if math.Abs(float64(got[i]-tt.want[i])) > 1e-6 {
+					t.Errorf("Point %d = %f, want %f", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateWaveformFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "voice.wav")
+	samples := make([]float32, 800)
+	for i := range samples {
+		amplitude := float32(0.1)
+		if i >= 400 {
+			amplitude = 0.8
+		}
+		samples[i] = amplitude * float32(math.Sin(float64(i)))
+	}
+	if err := WriteWAV(path, samples, 8000); err != nil {
+		t.Fatalf("WriteWAV failed: %v", err)
+	}
+
+	analyzer := NewWaveformAnalyzer()
+	waveform, err := analyzer.GenerateWaveformFromFile(path, 10)
+	if err != nil {
+		t.Fatalf("GenerateWaveformFromFile failed: %v", err)
+	}
+	if len(waveform) != 10 {
+		t.Fatalf("Got %d points, want 10", len(waveform))
+	}
+	if waveform[0] >= waveform[9] || waveform[0] > 0.3 {
+		t.Errorf("Expected a quiet start and loud end, got %v", waveform)
+	}
+
+	// The second call is served from the cache next to the file
+	if _, err := os.Stat(path + waveformCacheSuffix); err != nil {
+		t.Fatalf("Expected a waveform cache file: %v", err)
+	}
+	cached, err := analyzer.GenerateWaveformFromFile(path, 10)
+	if err != nil {
+		t.Fatalf("Cached GenerateWaveformFromFile failed: %v", err)
+	}
+	for i := range cached {
+		if cached[i] != waveform[i] {
+			t.Fatalf("Cached waveform differs at %d", i)
+		}
+	}
+}
+
+func TestGenerateWaveformFromFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	analyzer := NewWaveformAnalyzer()
+
+	if _, err := analyzer.GenerateWaveformFromFile(filepath.Join(dir, "missing.wav"), 10); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+
+	garbage := filepath.Join(dir, "garbage.wav")
+	if err := os.WriteFile(garbage, []byte("not audio"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := analyzer.GenerateWaveformFromFile(garbage, 10); err == nil {
+		t.Error("Expected an error for an unsupported file")
+	}
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// CanLock reports whether the app can be locked, which needs a password and
+// an encrypted database
+func (a *App) CanLock() bool {
+	return a.security.HasPassword() && a.storage.IsEncrypted()
+}
+
+// Lock wipes the master key from memory and closes the encrypted database
+// until Unlock is called with the password
+func (a *App) Lock() error {
+	if !a.CanLock() {
+		return fmt.Errorf("locking needs a password and an encrypted database")
+	}
+
+	a.mu.Lock()
+	if a.locked {
+		a.mu.Unlock()
+		return nil
+	}
+	a.locked = true
+	callback := a.onLockChanged
+	a.mu.Unlock()
+
+	// Tox events would be stored in the database about to close
+	a.tox.SetPaused(true)
+	if err := a.storage.Lock(); err != nil {
+		logging.Warnf("Failed to lock database: %v", err)
+	}
+	a.security.Cleanup()
+	logging.Infof("App locked")
+
+	if callback != nil {
+		callback(true)
+	}
+	return nil
+}
+
+// Unlock re-derives the database key from the password and reopens the
+// database. It returns security.ErrWrongPassword for a wrong password.
+func (a *App) Unlock(password string) error {
+	if !a.IsLocked() {
+		return nil
+	}
+
+	if err := a.security.Unlock(password); err != nil {
+		return err
+	}
+	if err := a.storage.Unlock(a.security); err != nil {
+		a.security.Cleanup()
+		return fmt.Errorf("failed to reopen database: %w", err)
+	}
+	a.activity.Touch()
+	a.tox.SetPaused(false)
+
+	a.mu.Lock()
+	a.locked = false
+	callback := a.onLockChanged
+	a.mu.Unlock()
+	logging.Infof("App unlocked")
+
+	if callback != nil {
+		callback(false)
+	}
+	return nil
+}
+
+// IsLocked reports whether the app is locked
+func (a *App) IsLocked() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.locked
+}
+
+// SetOnLockChanged sets the callback invoked when the app locks or unlocks
+func (a *App) SetOnLockChanged(callback func(locked bool)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onLockChanged = callback
+}
+
+// SetLockTimeout sets how long the app may be idle before it locks; zero
+// never locks
+func (a *App) SetLockTimeout(timeout time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lockTimeout = timeout
+}
+
+// checkAutoLock locks the app once it has been idle for the lock timeout
+func (a *App) checkAutoLock(now time.Time) {
+	a.mu.RLock()
+	timeout, locked := a.lockTimeout, a.locked
+	a.mu.RUnlock()
+
+	if timeout <= 0 || locked || a.activity.IdleFor(now) < timeout || !a.CanLock() {
+		return
+	}
+	logging.Infof("Locking after %v idle", timeout)
+	if err := a.Lock(); err != nil {
+		logging.Warnf("Failed to auto-lock: %v", err)
+	}
+}
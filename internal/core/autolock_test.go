@@ -0,0 +1,91 @@
+package core
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/core/security"
+	"github.com/opd-ai/whisp/ui/adaptive"
+)
+
+func TestAutoLock(t *testing.T) {
+	tempDir := t.TempDir()
+	app, err := NewApp(&Config{
+		DataDir:        tempDir,
+		ConfigPath:     filepath.Join(tempDir, "config.yaml"),
+		Platform:       adaptive.PlatformLinux,
+		PasswordPrompt: func(bool, int) (string, error) { return "secret", nil },
+	})
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	defer app.Cleanup()
+
+	var changes []bool
+	app.SetOnLockChanged(func(locked bool) { changes = append(changes, locked) })
+	app.SetLockTimeout(5 * time.Minute)
+
+	now := time.Now()
+	app.activity.TouchAt(now)
+	app.checkAutoLock(now.Add(4 * time.Minute))
+	if app.IsLocked() || app.GetSecurity().GetMasterKey() == nil {
+		t.Fatal("Expected the app to stay unlocked before the timeout")
+	}
+
+	app.checkAutoLock(now.Add(6 * time.Minute))
+	if !app.IsLocked() {
+		t.Fatal("Expected the app to lock after the timeout")
+	}
+	if key := app.GetSecurity().GetMasterKey(); key != nil {
+		t.Fatalf("Expected the master key to be wiped, got %d bytes", len(key))
+	}
+	if _, err := app.storage.Exec("SELECT 1"); err == nil {
+		t.Error("Expected the database to be closed while locked")
+	}
+
+	if err := app.Unlock("wrong"); !errors.Is(err, security.ErrWrongPassword) || !app.IsLocked() {
+		t.Fatalf("Expected a wrong password to keep the app locked, got %v", err)
+	}
+	if err := app.Unlock("secret"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if app.IsLocked() || app.GetSecurity().GetMasterKey() == nil {
+		t.Fatal("Expected the app to be unlocked with the key restored")
+	}
+	if _, err := app.storage.Exec("SELECT 1"); err != nil {
+		t.Errorf("Expected the database to be readable again: %v", err)
+	}
+
+	// Unlocking counts as activity, so the timer starts over
+	app.checkAutoLock(time.Now().Add(time.Minute))
+	if app.IsLocked() {
+		t.Error("Expected unlocking to reset the idle timer")
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("Expected lock then unlock notifications, got %v", changes)
+	}
+}
+
+func TestAutoLockNeedsPassword(t *testing.T) {
+	tempDir := t.TempDir()
+	app, err := NewApp(&Config{
+		DataDir:    tempDir,
+		ConfigPath: filepath.Join(tempDir, "config.yaml"),
+		Platform:   adaptive.PlatformLinux,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	defer app.Cleanup()
+
+	app.SetLockTimeout(time.Minute)
+	app.checkAutoLock(time.Now().Add(time.Hour))
+	if app.IsLocked() {
+		t.Error("Expected an app without a password never to lock")
+	}
+	if err := app.Lock(); err == nil {
+		t.Error("Expected Lock to fail without a password")
+	}
+}
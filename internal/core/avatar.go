@@ -0,0 +1,94 @@
+package core
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/logging"
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+// selfAvatarKey is the settings key our own avatar is stored under
+const selfAvatarKey = "self_avatar"
+
+// SetSelfAvatar makes an image our avatar and sends it to the friends online;
+// an empty path removes it. Friends who are offline get it when they connect.
+func (a *App) SetSelfAvatar(imagePath string) error {
+	var avatar []byte
+	if imagePath != "" {
+		var err error
+		if avatar, err = a.media.MakeAvatar(imagePath); err != nil {
+			return err
+		}
+	}
+	if err := saveSelfAvatar(a.storage, avatar); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.selfAvatar = avatar
+	a.mu.Unlock()
+
+	for _, c := range a.contacts.GetAllContacts() {
+		if c.Status != contact.StatusOffline {
+			if err := a.transfers.SendAvatar(c.FriendID, avatar); err != nil {
+				logging.Warnf("Failed to send avatar to friend %d: %v", c.FriendID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// GetSelfAvatar returns our avatar, or nil if we have none
+func (a *App) GetSelfAvatar() []byte {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.selfAvatar
+}
+
+// sendSelfAvatar sends our avatar to a friend who just came online
+func (a *App) sendSelfAvatar(friendID uint32) {
+	avatar := a.GetSelfAvatar()
+	if len(avatar) == 0 {
+		return
+	}
+	if err := a.transfers.SendAvatar(friendID, avatar); err != nil {
+		logging.Warnf("Failed to send avatar to friend %d: %v", friendID, err)
+	}
+}
+
+// saveSelfAvatar stores our avatar; nil deletes it
+func saveSelfAvatar(db *storage.Database, avatar []byte) error {
+	var err error
+	if len(avatar) == 0 {
+		_, err = db.Exec(`DELETE FROM settings WHERE key = ?`, selfAvatarKey)
+	} else {
+		_, err = db.Exec(`INSERT OR REPLACE INTO settings (key, value, updated_at) VALUES (?, ?, ?)`,
+			selfAvatarKey, base64.StdEncoding.EncodeToString(avatar), time.Now())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save avatar: %w", err)
+	}
+	return nil
+}
+
+// loadSelfAvatar returns our stored avatar, or nil if we have none
+func loadSelfAvatar(db *storage.Database) []byte {
+	var value string
+	err := db.QueryRow(`SELECT value FROM settings WHERE key = ?`, selfAvatarKey).Scan(&value)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logging.Warnf("Failed to load avatar: %v", err)
+		}
+		return nil
+	}
+	avatar, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		logging.Warnf("Failed to decode avatar: %v", err)
+		return nil
+	}
+	return avatar
+}
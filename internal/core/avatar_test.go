@@ -0,0 +1,36 @@
+package core
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+func TestSelfAvatarStorage(t *testing.T) {
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "whisp.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if got := loadSelfAvatar(db); got != nil {
+		t.Errorf("Expected no avatar at first, got %q", got)
+	}
+
+	avatar := []byte("\x89PNG\x00binary avatar")
+	if err := saveSelfAvatar(db, avatar); err != nil {
+		t.Fatalf("saveSelfAvatar failed: %v", err)
+	}
+	if got := loadSelfAvatar(db); !bytes.Equal(got, avatar) {
+		t.Errorf("Expected the saved avatar, got %q", got)
+	}
+
+	if err := saveSelfAvatar(db, nil); err != nil {
+		t.Fatalf("saveSelfAvatar failed: %v", err)
+	}
+	if got := loadSelfAvatar(db); got != nil {
+		t.Errorf("Expected the avatar removed, got %q", got)
+	}
+}
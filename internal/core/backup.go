@@ -0,0 +1,59 @@
+package core
+
+import (
+	"path/filepath"
+	"time"
+
+	configpkg "github.com/opd-ai/whisp/internal/core/config"
+)
+
+// BackupDatabase saves a consistent copy of the contacts and messages
+// database to path while Whisp keeps running
+func (a *App) BackupDatabase(path string) error {
+	return a.storage.BackupDatabase(path)
+}
+
+// RestoreDatabase checks a backup and replaces the database with it when
+// Whisp next starts
+func (a *App) RestoreDatabase(path string) error {
+	return a.storage.RestoreDatabase(path)
+}
+
+// LastBackupTime returns when the last scheduled backup was made, or the
+// zero time if none was
+func (a *App) LastBackupTime() time.Time {
+	return a.backups.LastBackupTime()
+}
+
+// backupSettingsFrom reads the scheduled backup settings from the
+// configuration, filling in defaults for configs from before they existed
+func backupSettingsFrom(cfg configpkg.Config, dataDir string) BackupSettings {
+	settings := BackupSettings{
+		Enabled:   cfg.Storage.BackupEnabled,
+		Interval:  24 * time.Hour,
+		Retention: cfg.Storage.BackupRetention,
+		Dir:       cfg.Storage.BackupDir,
+	}
+	if cfg.Storage.BackupInterval == "weekly" {
+		settings.Interval = 7 * 24 * time.Hour
+	}
+	if settings.Retention == 0 {
+		settings.Retention = configpkg.DefaultBackupRetention
+	}
+	if settings.Dir == "" {
+		settings.Dir = filepath.Join(dataDir, "backups")
+	}
+	return settings
+}
+
+// backupSkipReason says why a due backup should wait: the database is
+// closed while locked, and nothing was done since the last backup while idle
+func (a *App) backupSkipReason(lastBackup time.Time) string {
+	if a.IsLocked() {
+		return "app is locked"
+	}
+	if !a.activity.LastActivity().After(lastBackup) {
+		return "no activity since the last backup"
+	}
+	return ""
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+const (
+	// backupCheckInterval is how often the scheduler looks for a due backup
+	backupCheckInterval = time.Minute
+
+	// backupRetryDelay is how long a skipped or failed backup waits
+	backupRetryDelay = 15 * time.Minute
+
+	// backupTimeFormat names scheduled backups so they sort oldest first
+	backupTimeFormat = "20060102-150405"
+	backupPrefix     = "whisp-backup-"
+	backupSuffix     = ".db"
+)
+
+// BackupSettings say when and where scheduled backups are made
+type BackupSettings struct {
+	Enabled   bool
+	Interval  time.Duration
+	Retention int    // Number of backups kept
+	Dir       string // Directory the backups are written to
+}
+
+// BackupScheduler backs up the database every interval into a directory and
+// deletes all but the most recent backups
+type BackupScheduler struct {
+	backup   func(path string) error
+	settings func() BackupSettings
+	skip     func(lastBackup time.Time) string // Why a due backup should wait, empty to run it
+	now      func() time.Time
+
+	mu        sync.RWMutex
+	last      time.Time // Last successful backup
+	nextRetry time.Time // Earliest retry after a skipped or failed run
+}
+
+// NewBackupScheduler creates a scheduler that writes backups with backup.
+// Settings are read on every check, so changes apply without a restart.
+func NewBackupScheduler(backup func(path string) error, settings func() BackupSettings, skip func(lastBackup time.Time) string) *BackupScheduler {
+	s := &BackupScheduler{
+		backup:   backup,
+		settings: settings,
+		skip:     skip,
+		now:      time.Now,
+	}
+
+	// Backups from earlier runs count, so restarting does not force a new one
+	if dir := settings().Dir; dir != "" {
+		if backups, err := listBackups(dir); err == nil && len(backups) > 0 {
+			s.last, _ = backupTime(backups[len(backups)-1])
+		}
+	}
+	return s
+}
+
+// Start checks for a due backup every minute until the context is done
+func (s *BackupScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(backupCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Check()
+			}
+		}
+	}()
+}
+
+// LastBackupTime returns when the last scheduled backup was made, or the
+// zero time if none was
+func (s *BackupScheduler) LastBackupTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.last
+}
+
+// Check makes a backup if one is due and rotates the old ones out
+func (s *BackupScheduler) Check() {
+	settings := s.settings()
+	if !settings.Enabled || settings.Dir == "" || settings.Interval <= 0 {
+		return
+	}
+
+	now := s.now()
+	s.mu.RLock()
+	last, nextRetry := s.last, s.nextRetry
+	s.mu.RUnlock()
+	if now.Sub(last) < settings.Interval || now.Before(nextRetry) {
+		return
+	}
+
+	if reason := s.skip(last); reason != "" {
+		logging.Infof("Skipping scheduled backup: %s", reason)
+		s.retryAt(now.Add(backupRetryDelay))
+		return
+	}
+
+	path, err := s.run(settings, now)
+	if err != nil {
+		logging.Warnf("Scheduled backup failed: %v", err)
+		s.retryAt(now.Add(backupRetryDelay))
+		return
+	}
+	logging.Infof("Scheduled backup saved to %s", path)
+
+	s.mu.Lock()
+	s.last = now
+	s.nextRetry = time.Time{}
+	s.mu.Unlock()
+
+	if removed, err := rotateBackups(settings.Dir, settings.Retention); err != nil {
+		logging.Warnf("Failed to remove old backups: %v", err)
+	} else if removed > 0 {
+		logging.Infof("Removed %d old backups", removed)
+	}
+}
+
+// run writes one backup named after its time
+func (s *BackupScheduler) run(settings BackupSettings, now time.Time) (string, error) {
+	if err := os.MkdirAll(settings.Dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	path := filepath.Join(settings.Dir, backupPrefix+now.Format(backupTimeFormat)+backupSuffix)
+	if err := s.backup(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// retryAt delays the next attempt after a skipped or failed run
+func (s *BackupScheduler) retryAt(at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextRetry = at
+}
+
+// listBackups returns the paths of the scheduled backups in dir, oldest
+// first. Other files, such as manual backups, are left out.
+func listBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if _, ok := backupTime(path); ok && !entry.IsDir() {
+			backups = append(backups, path)
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// backupTime returns the time a scheduled backup was made from its name
+func backupTime(path string) (time.Time, bool) {
+	name := filepath.Base(path)
+	if !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix)
+	t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+	return t, err == nil
+}
+
+// rotateBackups deletes all but the keep most recent scheduled backups in
+// dir, with their cipher settings, and reports how many were deleted
+func rotateBackups(dir string, keep int) (int, error) {
+	if keep < 1 {
+		keep = 1 // Never delete the backup just made
+	}
+	backups, err := listBackups(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, path := range backups[:max(len(backups)-keep, 0)] {
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		os.Remove(path + ".cipher")
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeBackups is a scheduler writing empty backups on a clock the test moves
+type fakeBackups struct {
+	*BackupScheduler
+	clock  time.Time
+	skip   string
+	fail   error
+	made   int
+	dir    string
+	keep   int
+	active bool
+}
+
+func newFakeBackups(t *testing.T, keep int) *fakeBackups {
+	t.Helper()
+
+	f := &fakeBackups{
+		clock:  time.Date(2026, 1, 1, 3, 0, 0, 0, time.Local),
+		dir:    filepath.Join(t.TempDir(), "backups"),
+		keep:   keep,
+		active: true,
+	}
+	backup := func(path string) error {
+		if f.fail != nil {
+			return f.fail
+		}
+		f.made++
+		if err := os.WriteFile(path, []byte("backup"), 0o600); err != nil {
+			return err
+		}
+		return os.WriteFile(path+".cipher", []byte("{}"), 0o600)
+	}
+	settings := func() BackupSettings {
+		return BackupSettings{Enabled: f.active, Interval: 24 * time.Hour, Retention: f.keep, Dir: f.dir}
+	}
+	f.BackupScheduler = NewBackupScheduler(backup, settings, func(time.Time) string { return f.skip })
+	f.now = func() time.Time { return f.clock }
+	return f
+}
+
+// checkDaily runs the scheduler once a day for days days
+func (f *fakeBackups) checkDaily(days int) {
+	for i := 0; i < days; i++ {
+		f.Check()
+		f.clock = f.clock.Add(24 * time.Hour)
+	}
+}
+
+func TestBackupRotationKeepsNewest(t *testing.T) {
+	tests := []struct {
+		name string
+		keep int
+		days int
+		want int
+	}{
+		{"fewer backups than kept", 5, 3, 3},
+		{"exactly as many as kept", 3, 3, 3},
+		{"more backups than kept", 3, 10, 3},
+		{"keep one", 1, 4, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeBackups(t, tt.keep)
+			f.checkDaily(tt.days)
+
+			if f.made != tt.days {
+				t.Errorf("Expected %d backups to be made, got %d", tt.days, f.made)
+			}
+			backups, err := listBackups(f.dir)
+			if err != nil {
+				t.Fatalf("Failed to list backups: %v", err)
+			}
+			if len(backups) != tt.want {
+				t.Fatalf("Expected %d backups to remain, got %d", tt.want, len(backups))
+			}
+
+			// The survivors are the most recent ones
+			newest, _ := backupTime(backups[len(backups)-1])
+			oldest, _ := backupTime(backups[0])
+			if !newest.Equal(f.LastBackupTime()) {
+				t.Errorf("Expected the newest backup at %v, got %v", f.LastBackupTime(), newest)
+			}
+			if got := newest.Sub(oldest); got != time.Duration(tt.want-1)*24*time.Hour {
+				t.Errorf("Expected the remaining backups to be consecutive days, span %v", got)
+			}
+
+			ciphers, _ := filepath.Glob(filepath.Join(f.dir, "*.cipher"))
+			if len(ciphers) != tt.want {
+				t.Errorf("Expected cipher settings to be removed with their backups, %d remain", len(ciphers))
+			}
+		})
+	}
+}
+
+func TestBackupRotationLeavesOtherFiles(t *testing.T) {
+	f := newFakeBackups(t, 1)
+	if err := os.MkdirAll(f.dir, 0o700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	manual := filepath.Join(f.dir, "whisp-backup-2025-12-24.db")
+	if err := os.WriteFile(manual, []byte("manual"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	f.checkDaily(3)
+	if _, err := os.Stat(manual); err != nil {
+		t.Errorf("Expected a manual backup to be kept: %v", err)
+	}
+}
+
+func TestBackupSchedule(t *testing.T) {
+	f := newFakeBackups(t, 3)
+
+	f.Check()
+	f.clock = f.clock.Add(23 * time.Hour)
+	f.Check()
+	if f.made != 1 {
+		t.Fatalf("Expected one backup within the interval, got %d", f.made)
+	}
+
+	// A skipped run waits before trying again
+	f.clock = f.clock.Add(2 * time.Hour)
+	f.skip = "app is locked"
+	f.Check()
+	f.skip = ""
+	f.Check()
+	if f.made != 1 {
+		t.Fatalf("Expected the skipped run to wait, got %d backups", f.made)
+	}
+	f.clock = f.clock.Add(backupRetryDelay)
+	f.Check()
+	if f.made != 2 {
+		t.Fatalf("Expected the backup once the retry delay passed, got %d", f.made)
+	}
+
+	// Failures are retried the same way and keep the last backup time
+	last := f.LastBackupTime()
+	f.clock = f.clock.Add(24 * time.Hour)
+	f.fail = errors.New("disk full")
+	f.Check()
+	if !f.LastBackupTime().Equal(last) {
+		t.Error("Expected a failed backup not to count")
+	}
+
+	// Nothing happens while disabled
+	f.fail = nil
+	f.active = false
+	f.clock = f.clock.Add(7 * 24 * time.Hour)
+	f.Check()
+	if f.made != 2 {
+		t.Errorf("Expected no backups while disabled, got %d", f.made)
+	}
+}
+
+func TestBackupSchedulerResumesFromExistingBackups(t *testing.T) {
+	f := newFakeBackups(t, 3)
+	f.Check()
+	made := f.LastBackupTime()
+
+	restarted := NewBackupScheduler(nil, f.settings, nil)
+	if !restarted.LastBackupTime().Equal(made) {
+		t.Errorf("Expected the last backup time %v after a restart, got %v", made, restarted.LastBackupTime())
+	}
+}
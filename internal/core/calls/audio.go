@@ -0,0 +1,225 @@
+// Package calls implements the audio path of ToxAV calls.
+// This file connects active calls to the speaker and microphone.
+package calls
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// AudioDevice carries call audio to and from the speaker and microphone at
+// a fixed sample rate. audio.DeviceManager implements it when the build has
+// an audio backend.
+type AudioDevice interface {
+	SampleRate() int
+	OpenCapture(onSamples func(samples []float32)) (io.Closer, error)
+	OpenPlayback(fill func(out []float32)) (io.Closer, error)
+}
+
+// maxPlaybackDelay bounds the received audio queued for the speaker. Older
+// audio is dropped so a stall does not leave the call lagging behind.
+const maxPlaybackDelay = 200 * time.Millisecond
+
+// sendQueueFrames is how many captured frames may wait to be sent before
+// new ones are dropped
+const sendQueueFrames = 10
+
+// frameSender sends one interleaved PCM frame to the friend
+type frameSender func(pcm []int16, sampleCount int, channels uint8, samplingRate uint32) error
+
+// callAudio plays the audio received in one call and sends captured audio
+// in frames of the configured format
+type callAudio struct {
+	call         *Call
+	deviceRate   int
+	sendRate     int
+	sendChannels uint8
+	send         frameSender
+
+	mu           sync.Mutex
+	playback     []float32 // Received audio at the device rate, oldest first
+	playbackRate int       // Sample rate of the last received frame
+	fromPeer     *resampler
+	toPeer       *resampler
+	pending      []float32 // Captured audio at the send rate, not yet framed
+
+	frames  chan []int16
+	done    chan struct{}
+	streams []io.Closer
+}
+
+// newCallAudio creates the audio path of a call
+func newCallAudio(call *Call, deviceRate, sendRate int, sendChannels uint8, send frameSender) *callAudio {
+	if sendRate <= 0 {
+		sendRate = deviceRate
+	}
+	if sendChannels == 0 {
+		sendChannels = 1
+	}
+	return &callAudio{
+		call:         call,
+		deviceRate:   deviceRate,
+		sendRate:     sendRate,
+		sendChannels: sendChannels,
+		send:         send,
+		toPeer:       newResampler(deviceRate, sendRate),
+		frames:       make(chan []int16, sendQueueFrames),
+		done:         make(chan struct{}),
+	}
+}
+
+// start opens the speaker and microphone and starts sending frames
+func (ca *callAudio) start(device AudioDevice) error {
+	output, err := device.OpenPlayback(ca.fill)
+	if err != nil {
+		return fmt.Errorf("failed to open speaker: %w", err)
+	}
+	input, err := device.OpenCapture(ca.capture)
+	if err != nil {
+		output.Close()
+		return fmt.Errorf("failed to open microphone: %w", err)
+	}
+	ca.streams = []io.Closer{input, output}
+
+	go ca.sendLoop()
+	return nil
+}
+
+// stop closes the devices and stops sending
+func (ca *callAudio) stop() {
+	close(ca.done)
+	for _, stream := range ca.streams {
+		if err := stream.Close(); err != nil {
+			logging.Warnf("Failed to close call audio stream: %v", err)
+		}
+	}
+}
+
+// sendLoop sends queued frames until the call audio stops. Only the first
+// failure is logged, as frames keep coming every 20 ms.
+func (ca *callAudio) sendLoop() {
+	var failed bool
+	for {
+		select {
+		case <-ca.done:
+			return
+		case pcm := <-ca.frames:
+			sampleCount := len(pcm) / int(ca.sendChannels)
+			err := ca.send(pcm, sampleCount, ca.sendChannels, uint32(ca.sendRate))
+			if err != nil && !failed {
+				logging.Warnf("Failed to send audio frame to friend %d: %v", ca.call.FriendID, err)
+			}
+			failed = err != nil
+		}
+	}
+}
+
+// capture frames microphone audio for sending, dropping it while muted
+func (ca *callAudio) capture(samples []float32) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	if ca.call.IsMuted() {
+		ca.pending = ca.pending[:0] // Don't send stale audio on unmute
+		return
+	}
+
+	ca.pending = append(ca.pending, ca.toPeer.process(samples)...)
+	frameSize := ca.sendRate / 50 // 20 ms, a frame size ToxAV accepts
+	for len(ca.pending) >= frameSize {
+		pcm := monoToPCM(ca.pending[:frameSize], ca.sendChannels)
+		ca.pending = ca.pending[frameSize:]
+		select {
+		case ca.frames <- pcm:
+		default:
+			// The network is behind; dropping beats adding delay
+		}
+	}
+}
+
+// play queues a received frame for the speaker
+func (ca *callAudio) play(pcm []int16, channels uint8, samplingRate uint32) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	if int(samplingRate) != ca.playbackRate {
+		ca.playbackRate = int(samplingRate)
+		ca.fromPeer = newResampler(ca.playbackRate, ca.deviceRate)
+	}
+	ca.playback = append(ca.playback, ca.fromPeer.process(pcmToMono(pcm, channels))...)
+
+	limit := int(time.Duration(ca.deviceRate) * maxPlaybackDelay / time.Second)
+	if excess := len(ca.playback) - limit; excess > 0 {
+		ca.playback = ca.playback[excess:]
+	}
+}
+
+// fill hands queued audio to the speaker, with silence when none arrived
+func (ca *callAudio) fill(out []float32) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	n := copy(out, ca.playback)
+	ca.playback = ca.playback[n:]
+	clear(out[n:])
+}
+
+// SetAudioDevice connects calls to the speaker and microphone. Without a
+// device, received audio only reaches the event handler.
+func (m *Manager) SetAudioDevice(device AudioDevice) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.audioDevice = device
+}
+
+// SetMuted mutes or unmutes the microphone in a call. A muted call stays up
+// but sends no audio frames.
+func (m *Manager) SetMuted(friendID uint32, muted bool) error {
+	call, exists := m.GetActiveCall(friendID)
+	if !exists {
+		return fmt.Errorf("no active call with friend %d", friendID)
+	}
+	call.SetMuted(muted)
+	return nil
+}
+
+// startCallAudio connects an active call to the audio device (requires lock)
+func (m *Manager) startCallAudio(call *Call) {
+	if m.audioDevice == nil || m.callAudio[call.FriendID] != nil {
+		return
+	}
+
+	friendID := call.FriendID
+	ca := newCallAudio(call, m.audioDevice.SampleRate(), int(m.config.AudioSampleRate), m.config.AudioChannels,
+		func(pcm []int16, sampleCount int, channels uint8, samplingRate uint32) error {
+			return m.toxAV.AudioSendFrame(friendID, pcm, sampleCount, channels, samplingRate)
+		})
+	if err := ca.start(m.audioDevice); err != nil {
+		logging.Warnf("Call with friend %d has no audio: %v", friendID, err)
+		return
+	}
+	m.callAudio[friendID] = ca
+}
+
+// stopCallAudio disconnects a call from the audio device (requires lock)
+func (m *Manager) stopCallAudio(friendID uint32) {
+	if ca := m.callAudio[friendID]; ca != nil {
+		ca.stop()
+		delete(m.callAudio, friendID)
+	}
+}
+
+// playCallAudio plays a received frame if the call is connected to a device
+func (m *Manager) playCallAudio(friendID uint32, pcm []int16, channels uint8, samplingRate uint32) {
+	m.mu.RLock()
+	ca := m.callAudio[friendID]
+	m.mu.RUnlock()
+
+	if ca != nil {
+		ca.play(pcm, channels, samplingRate)
+	}
+}
@@ -0,0 +1,233 @@
+package calls
+
+import (
+	"io"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPCMToMono(t *testing.T) {
+	tests := []struct {
+		name     string
+		pcm      []int16
+		channels uint8
+		want     []float32
+	}{
+		{name: "mono", pcm: []int16{0, 16384, -32768}, channels: 1, want: []float32{0, 0.5, -1}},
+		{name: "stereo averaged", pcm: []int16{16384, 0, -16384, -16384}, channels: 2, want: []float32{0.25, -0.5}},
+		{name: "zero channels as mono", pcm: []int16{16384}, channels: 0, want: []float32{0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pcmToMono(tt.pcm, tt.channels)
+			if len(got) != len(tt.want) {
+				t.Fatalf("pcmToMono() returned %d samples, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("sample %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMonoToPCM(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []float32
+		channels uint8
+		want     []int16
+	}{
+		{name: "mono", samples: []float32{0, 0.5, -1}, channels: 1, want: []int16{0, 16384, -32767}},
+		{name: "stereo duplicated", samples: []float32{1, -0.5}, channels: 2, want: []int16{32767, 32767, -16384, -16384}},
+		{name: "clipped", samples: []float32{1.5, -2}, channels: 1, want: []int16{32767, -32767}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := monoToPCM(tt.samples, tt.channels)
+			if len(got) != len(tt.want) {
+				t.Fatalf("monoToPCM() returned %d samples, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("sample %d = %d, want %d", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// tone returns n samples of a sine at frequency Hz
+func tone(n, rate int, frequency float64) []float32 {
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = float32(0.5 * math.Sin(2*math.Pi*frequency*float64(i)/float64(rate)))
+	}
+	return samples
+}
+
+func TestResampler(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to int
+	}{
+		{name: "same rate", from: 48000, to: 48000},
+		{name: "down to 16 kHz", from: 48000, to: 16000},
+		{name: "up from 8 kHz", from: 8000, to: 48000},
+		{name: "up from 44.1 kHz", from: 44100, to: 48000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const frequency = 440.0
+			input := tone(tt.from, tt.from, frequency) // One second
+			r := newResampler(tt.from, tt.to)
+
+			// Feed 20 ms frames, as calls do
+			var output []float32
+			frame := tt.from / 50
+			for start := 0; start < len(input); start += frame {
+				output = append(output, r.process(input[start:min(start+frame, len(input))])...)
+			}
+
+			// The last input sample waits for the next frame to interpolate
+			slack := tt.to/tt.from + 1
+			if diff := len(output) - tt.to; diff < -slack || diff > 1 {
+				t.Errorf("Got %d samples for one second, want %d", len(output), tt.to)
+			}
+
+			// The tone must come through at the same pitch without clicks
+			// at frame boundaries
+			want := tone(len(output), tt.to, frequency)
+			for i := range output {
+				if math.Abs(float64(output[i]-want[i])) > 0.02 {
+					t.Fatalf("Sample %d = %v, want %v", i, output[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+// fakeDevice drives call audio callbacks by hand
+type fakeDevice struct {
+	rate    int
+	capture func([]float32)
+	fill    func([]float32)
+}
+
+func (d *fakeDevice) SampleRate() int { return d.rate }
+
+func (d *fakeDevice) OpenCapture(onSamples func([]float32)) (io.Closer, error) {
+	d.capture = onSamples
+	return io.NopCloser(nil), nil
+}
+
+func (d *fakeDevice) OpenPlayback(fill func([]float32)) (io.Closer, error) {
+	d.fill = fill
+	return io.NopCloser(nil), nil
+}
+
+// sentFrame is a frame passed to the frame sender
+type sentFrame struct {
+	pcm          []int16
+	sampleCount  int
+	channels     uint8
+	samplingRate uint32
+}
+
+func TestCallAudioSendsFramesInCallFormat(t *testing.T) {
+	device := &fakeDevice{rate: 48000}
+	call := NewCall(1, CallTypeAudio, true)
+
+	var mu sync.Mutex
+	var sent []sentFrame
+	ca := newCallAudio(call, device.rate, 16000, 2, func(pcm []int16, sampleCount int, channels uint8, samplingRate uint32) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, sentFrame{pcm, sampleCount, channels, samplingRate})
+		return nil
+	})
+	if err := ca.start(device); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	defer ca.stop()
+
+	// 50 ms at the device rate makes two 20 ms frames at 16 kHz
+	device.capture(make([]float32, 2400))
+
+	sentCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(sent)
+	}
+	deadline := time.Now().Add(time.Second)
+	for sentCount() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	if len(sent) != 2 {
+		t.Fatalf("Sent %d frames, want 2", len(sent))
+	}
+	for _, frame := range sent {
+		if frame.sampleCount != 320 || frame.channels != 2 || frame.samplingRate != 16000 || len(frame.pcm) != 640 {
+			t.Errorf("Unexpected frame: %d samples, %d channels, %d Hz, %d values",
+				frame.sampleCount, frame.channels, frame.samplingRate, len(frame.pcm))
+		}
+	}
+	mu.Unlock()
+
+	// Muting stops sending but keeps the call
+	call.SetMuted(true)
+	device.capture(make([]float32, 4800))
+	time.Sleep(20 * time.Millisecond)
+	if count := sentCount(); count != 2 {
+		t.Errorf("Sent %d frames while muted, want none after the first 2", count-2)
+	}
+	if call.GetState() != CallStateOutgoing {
+		t.Errorf("Muting changed the call state to %s", call.GetState())
+	}
+}
+
+func TestCallAudioPlaysReceivedFrames(t *testing.T) {
+	device := &fakeDevice{rate: 48000}
+	ca := newCallAudio(NewCall(1, CallTypeAudio, false), device.rate, 48000, 1, func([]int16, int, uint8, uint32) error {
+		return nil
+	})
+	if err := ca.start(device); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	defer ca.stop()
+
+	// A stereo 24 kHz frame of 10 ms plays as 480 mono samples
+	pcm := make([]int16, 480)
+	for i := range pcm {
+		pcm[i] = 16384
+	}
+	ca.play(pcm, 2, 24000)
+
+	out := make([]float32, 600)
+	device.fill(out)
+	if out[100] != 0.5 {
+		t.Errorf("Played %v, want 0.5", out[100])
+	}
+	if out[599] != 0 {
+		t.Errorf("Expected silence after the received audio, got %v", out[599])
+	}
+
+	// A long stall only keeps the most recent audio
+	for i := 0; i < 100; i++ {
+		ca.play(pcm, 2, 24000)
+	}
+	ca.mu.Lock()
+	queued := len(ca.playback)
+	ca.mu.Unlock()
+	if limit := 48000 * int(maxPlaybackDelay/time.Millisecond) / 1000; queued > limit {
+		t.Errorf("Queued %d samples, want at most %d", queued, limit)
+	}
+}
@@ -0,0 +1,85 @@
+// Package calls implements conversion between ToxAV audio frames and the
+// float samples audio devices use.
+// This file contains the sample format, channel and rate conversions.
+package calls
+
+import "math"
+
+// pcmToMono converts interleaved 16-bit PCM to mono samples in [-1, 1],
+// averaging the channels
+func pcmToMono(pcm []int16, channels uint8) []float32 {
+	if channels == 0 {
+		channels = 1
+	}
+	step := int(channels)
+	mono := make([]float32, len(pcm)/step)
+	for i := range mono {
+		var sum float32
+		for _, sample := range pcm[i*step : (i+1)*step] {
+			sum += float32(sample)
+		}
+		mono[i] = sum / float32(step) / 32768
+	}
+	return mono
+}
+
+// monoToPCM converts mono samples to interleaved 16-bit PCM with the sample
+// repeated on every channel, clipping values outside [-1, 1]
+func monoToPCM(samples []float32, channels uint8) []int16 {
+	if channels == 0 {
+		channels = 1
+	}
+	pcm := make([]int16, 0, len(samples)*int(channels))
+	for _, sample := range samples {
+		value := int16(math.Round(math.Max(-1, math.Min(1, float64(sample))) * 32767))
+		for c := uint8(0); c < channels; c++ {
+			pcm = append(pcm, value)
+		}
+	}
+	return pcm
+}
+
+// resampler converts a stream of mono samples between rates by linear
+// interpolation. It keeps its position across calls so consecutive frames
+// join without clicks.
+type resampler struct {
+	from, to int
+	pos      float64 // Input position of the next output sample; -1 is prev
+	prev     float32 // Last input sample of the previous call
+}
+
+// newResampler creates a resampler from one rate to another
+func newResampler(from, to int) *resampler {
+	return &resampler{from: from, to: to}
+}
+
+// process converts the next input samples. Over many calls the output
+// length approaches len(input)*to/from.
+func (r *resampler) process(in []float32) []float32 {
+	if r.from == r.to || r.from <= 0 || r.to <= 0 {
+		return append([]float32(nil), in...)
+	}
+	if len(in) == 0 {
+		return nil
+	}
+
+	at := func(i int) float32 {
+		if i < 0 {
+			return r.prev
+		}
+		return in[i]
+	}
+
+	step := float64(r.from) / float64(r.to)
+	out := make([]float32, 0, int(float64(len(in))/step)+1)
+	for r.pos < float64(len(in)-1) {
+		i := int(math.Floor(r.pos))
+		frac := float32(r.pos - float64(i))
+		a, b := at(i), at(i+1)
+		out = append(out, a+(b-a)*frac)
+		r.pos += step
+	}
+	r.pos -= float64(len(in))
+	r.prev = in[len(in)-1]
+	return out
+}
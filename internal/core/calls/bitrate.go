@@ -0,0 +1,166 @@
+package calls
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// Lowest bitrates adaptation goes down to, in kbps. Opus stays intelligible
+// at 8 kbps; video below 100 kbps is not worth sending.
+const (
+	minAudioBitRate = 8
+	minVideoBitRate = 100
+)
+
+// Hysteresis of bitrate adaptation. Feedback within bitrateDeadband of the
+// current bitrate is ignored. Congestion lowers the bitrate at once, but it
+// only rises after the network has allowed more for bitrateRecoverAfter, and
+// then by at most bitrateStepUp at a time.
+const (
+	bitrateDeadband     = 0.1
+	bitrateRecoverAfter = 5 * time.Second
+	bitrateStepUp       = 0.25
+)
+
+// bitrateController decides the bitrate of one stream of a call from the
+// bitrates the network suggests. Bitrates are in kbps.
+type bitrateController struct {
+	min, max     uint32
+	current      uint32
+	lastChange   time.Time
+	recoverSince time.Time // Start of the feedback allowing more, zero if none
+}
+
+// newBitrateController starts a stream at its configured maximum
+func newBitrateController(min, max uint32, now time.Time) *bitrateController {
+	if min > max {
+		min = max
+	}
+	return &bitrateController{min: min, max: max, current: max, lastChange: now}
+}
+
+// next returns the bitrate to use after the network suggested one, and
+// whether it differs from the bitrate used so far
+func (bc *bitrateController) next(suggested uint32, now time.Time) (uint32, bool) {
+	target := min(max(suggested, bc.min), bc.max)
+
+	switch {
+	case float64(target) < float64(bc.current)*(1-bitrateDeadband):
+		// Congestion: back off right away
+		bc.current = target
+		bc.lastChange = now
+		bc.recoverSince = time.Time{}
+		return bc.current, true
+
+	case target <= bc.current:
+		bc.recoverSince = time.Time{}
+		return bc.current, false
+
+	case float64(target) <= float64(bc.current)*(1+bitrateDeadband):
+		return bc.current, false
+	}
+
+	// The network allows more; wait until it has for a while
+	if bc.recoverSince.IsZero() {
+		bc.recoverSince = now
+	}
+	if now.Sub(bc.recoverSince) < bitrateRecoverAfter || now.Sub(bc.lastChange) < bitrateRecoverAfter {
+		return bc.current, false
+	}
+
+	step := max(uint32(float64(bc.current)*bitrateStepUp), 1)
+	bc.current = min(target, bc.current+step)
+	bc.lastChange = now
+	bc.recoverSince = now // The next step needs its own stretch of good feedback
+	return bc.current, true
+}
+
+// callBitrates holds the bitrate controllers of one call
+type callBitrates struct {
+	audio *bitrateController
+	video *bitrateController // nil for audio calls
+}
+
+// startBitrateControl starts adapting the bitrates of a call (requires lock)
+func (m *Manager) startBitrateControl(call *Call) {
+	now := time.Now()
+	bitrates := &callBitrates{audio: newBitrateController(minAudioBitRate, m.config.AudioBitRate, now)}
+	call.SetAudioBitrate(bitrates.audio.current)
+	if call.Type == CallTypeVideo {
+		bitrates.video = newBitrateController(minVideoBitRate, m.config.VideoBitRate, now)
+		call.SetVideoBitrate(bitrates.video.current)
+	}
+	m.bitrates[call.FriendID] = bitrates
+}
+
+// stopBitrateControl stops adapting the bitrates of a call (requires lock)
+func (m *Manager) stopBitrateControl(friendID uint32) {
+	delete(m.bitrates, friendID)
+}
+
+// adaptBitrate applies network feedback, a suggested bitrate in bits per
+// second, to the audio or video bitrate of a call
+func (m *Manager) adaptBitrate(friendID uint32, suggestedBps uint32, video bool) {
+	kind := "Audio"
+	if video {
+		kind = "Video"
+	}
+
+	m.mu.Lock()
+	call, exists := m.activeCalls[friendID]
+	bitrates := m.bitrates[friendID]
+	if !exists || bitrates == nil {
+		m.mu.Unlock()
+		logging.Warnf("%s bitrate feedback for unknown call with friend %d", kind, friendID)
+		return
+	}
+	controller := bitrates.audio
+	if video {
+		controller = bitrates.video
+	}
+	if controller == nil {
+		m.mu.Unlock()
+		return
+	}
+	previous := controller.current
+	bitrate, changed := controller.next(suggestedBps/1000, time.Now())
+	m.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	var err error
+	if video {
+		err = m.toxAV.VideoSetBitRate(friendID, bitrate*1000)
+		call.SetVideoBitrate(bitrate)
+	} else {
+		err = m.toxAV.AudioSetBitRate(friendID, bitrate*1000)
+		call.SetAudioBitrate(bitrate)
+	}
+	if err != nil {
+		logging.Warnf("Failed to set %s bitrate for friend %d: %v", kind, friendID, err)
+	}
+
+	direction := "lowered"
+	if bitrate > previous {
+		direction = "raised"
+	}
+	event := NewCallEvent(CallEventBitrateChanged, call,
+		fmt.Sprintf("%s bitrate %s to %d kbps", kind, direction, bitrate))
+	m.sendEvent(event)
+
+	logging.Debugf("%s bitrate %s for friend %d: %d kbps", kind, direction, friendID, bitrate)
+}
+
+// GetEffectiveBitrate returns the audio and video bitrates in kbps a call is
+// sending at, after adaptation to the network. Video is 0 for audio calls.
+func (m *Manager) GetEffectiveBitrate(friendID uint32) (audio, video uint32, err error) {
+	call, exists := m.GetActiveCall(friendID)
+	if !exists {
+		return 0, 0, fmt.Errorf("no active call with friend %d", friendID)
+	}
+	return call.GetAudioBitrate(), call.GetVideoBitrate(), nil
+}
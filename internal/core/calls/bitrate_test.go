@@ -0,0 +1,103 @@
+package calls
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBitrateControllerFeedback(t *testing.T) {
+	// feedback is a suggestion from the network at some time into the call
+	type feedback struct {
+		at        time.Duration
+		suggested uint32
+		want      uint32
+	}
+
+	tests := []struct {
+		name     string
+		min, max uint32
+		steps    []feedback
+	}{
+		{
+			name: "congestion lowers at once",
+			min:  8, max: 64,
+			steps: []feedback{
+				{at: time.Second, suggested: 40, want: 40},
+				{at: 1100 * time.Millisecond, suggested: 20, want: 20},
+			},
+		},
+		{
+			name: "bounded by the minimum and the configured maximum",
+			min:  8, max: 64,
+			steps: []feedback{
+				{at: time.Second, suggested: 1, want: 8},
+				{at: 10 * time.Second, suggested: 500, want: 8},
+				{at: 15 * time.Second, suggested: 500, want: 10},
+				{at: 21 * time.Second, suggested: 500, want: 12},
+			},
+		},
+		{
+			name: "small changes are ignored",
+			min:  8, max: 64,
+			steps: []feedback{
+				{at: time.Second, suggested: 60, want: 64},
+				{at: 2 * time.Second, suggested: 58, want: 64},
+				{at: 3 * time.Second, suggested: 57, want: 57},
+				{at: 20 * time.Second, suggested: 62, want: 57},
+			},
+		},
+		{
+			name: "recovers in steps after good feedback",
+			min:  8, max: 64,
+			steps: []feedback{
+				{at: 0, suggested: 32, want: 32},
+				{at: time.Second, suggested: 64, want: 32},
+				{at: 4 * time.Second, suggested: 64, want: 32},
+				{at: 6 * time.Second, suggested: 64, want: 40},
+				{at: 8 * time.Second, suggested: 64, want: 40},
+				{at: 11 * time.Second, suggested: 64, want: 50},
+				{at: 16 * time.Second, suggested: 64, want: 62},
+				{at: 21 * time.Second, suggested: 64, want: 62},
+			},
+		},
+		{
+			name: "does not oscillate on flapping feedback",
+			min:  8, max: 64,
+			steps: []feedback{
+				{at: 0, suggested: 32, want: 32},
+				{at: 2 * time.Second, suggested: 64, want: 32},
+				{at: 4 * time.Second, suggested: 30, want: 32},
+				{at: 6 * time.Second, suggested: 64, want: 32},
+				{at: 8 * time.Second, suggested: 31, want: 32},
+				{at: 10 * time.Second, suggested: 64, want: 32},
+				{at: 14 * time.Second, suggested: 64, want: 32},
+				{at: 15 * time.Second, suggested: 64, want: 40},
+			},
+		},
+		{
+			name: "minimum above the configured maximum",
+			min:  100, max: 50,
+			steps: []feedback{
+				{at: time.Second, suggested: 10, want: 50},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			bc := newBitrateController(tt.min, tt.max, start)
+			previous := bc.current
+			for i, step := range tt.steps {
+				got, changed := bc.next(step.suggested, start.Add(step.at))
+				if got != step.want {
+					t.Fatalf("Step %d: suggested %d at %v, got %d kbps, want %d", i, step.suggested, step.at, got, step.want)
+				}
+				if changed != (got != previous) {
+					t.Errorf("Step %d: changed = %v going from %d to %d", i, changed, previous, got)
+				}
+				previous = got
+			}
+		})
+	}
+}
@@ -129,6 +129,7 @@ type Call struct {
 
 	// Media settings
 	audioEnabled bool   // Whether audio is enabled
+	muted        bool   // Whether our microphone is kept from the call
 	videoEnabled bool   // Whether video is enabled
 	audioBitrate uint32 // Current audio bitrate
 	videoBitrate uint32 // Current video bitrate
@@ -209,6 +210,20 @@ func (c *Call) SetAudioEnabled(enabled bool) {
 	c.audioEnabled = enabled
 }
 
+// IsMuted returns whether the microphone is muted
+func (c *Call) IsMuted() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.muted
+}
+
+// SetMuted sets whether the microphone is muted
+func (c *Call) SetMuted(muted bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.muted = muted
+}
+
 // IsVideoEnabled returns whether video is enabled
 func (c *Call) IsVideoEnabled() bool {
 	c.mu.RLock()
@@ -3,10 +3,9 @@
 package calls
 
 import (
-	"fmt"
-	"log"
-
 	"github.com/opd-ai/toxcore/av"
+
+	"github.com/opd-ai/whisp/internal/logging"
 )
 
 // setupCallbacks configures the ToxAV callbacks for handling call events
@@ -29,17 +28,46 @@ func (m *Manager) setupCallbacks() {
 	// Set video bitrate callback - handles video bitrate changes
 	m.toxAV.CallbackVideoBitRate(m.onVideoBitrateChanged)
 
-	log.Println("ToxAV callbacks configured successfully")
+	logging.Infof("ToxAV callbacks configured successfully")
+}
+
+// SetBlockedFilter sets the function reporting blocked friends. Their calls
+// are rejected without ringing or an event.
+func (m *Manager) SetBlockedFilter(isBlocked func(friendID uint32) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.isBlocked = isBlocked
+}
+
+// rejectBlocked hangs up a call from a blocked friend and reports whether it did
+func (m *Manager) rejectBlocked(friendNumber uint32) bool {
+	m.mu.RLock()
+	isBlocked := m.isBlocked
+	m.mu.RUnlock()
+
+	if isBlocked == nil || !isBlocked(friendNumber) {
+		return false
+	}
+
+	if err := m.toxAV.CallControl(friendNumber, 0); err != nil {
+		logging.Warnf("Failed to reject call from blocked friend %d: %v", friendNumber, err)
+	}
+	logging.Infof("Rejected call from blocked friend %d", friendNumber)
+	return true
 }
 
 // onCallReceived handles incoming call events from ToxAV
 func (m *Manager) onCallReceived(friendNumber uint32, audioEnabled, videoEnabled bool) {
+	if m.rejectBlocked(friendNumber) {
+		return
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Check if there's already an active call with this friend
 	if existingCall, exists := m.activeCalls[friendNumber]; exists {
-		log.Printf("Warning: Received call from friend %d but call already exists: %s",
+		logging.Warnf("Received call from friend %d but call already exists: %s",
 			friendNumber, existingCall.State)
 		return
 	}
@@ -59,7 +87,7 @@ func (m *Manager) onCallReceived(friendNumber uint32, audioEnabled, videoEnabled
 	event := NewCallEvent(CallEventIncoming, call, "Incoming call received")
 	m.sendEvent(event)
 
-	log.Printf("Received incoming %s call from friend %d", callType, friendNumber)
+	logging.Infof("Received incoming %s call from friend %d", callType, friendNumber)
 }
 
 // onCallStateChanged handles call state change events from ToxAV
@@ -69,7 +97,7 @@ func (m *Manager) onCallStateChanged(friendNumber uint32, state av.CallState) {
 
 	call, exists := m.activeCalls[friendNumber]
 	if !exists {
-		log.Printf("Warning: Received call state change for unknown friend %d", friendNumber)
+		logging.Warnf("Received call state change for unknown friend %d", friendNumber)
 		return
 	}
 
@@ -88,6 +116,8 @@ func (m *Manager) onCallStateChanged(friendNumber uint32, state av.CallState) {
 		message = "Call ended by peer"
 
 		// Move to history and remove from active calls
+		m.stopCallAudio(friendNumber)
+		m.stopBitrateControl(friendNumber)
 		m.callHistory = append(m.callHistory, call)
 		delete(m.activeCalls, friendNumber)
 
@@ -114,18 +144,21 @@ func (m *Manager) onCallStateChanged(friendNumber uint32, state av.CallState) {
 		message = "Video reception started"
 
 	default:
-		log.Printf("Unknown ToxAV call state %d for friend %d", stateValue, friendNumber)
+		logging.Warnf("Unknown ToxAV call state %d for friend %d", stateValue, friendNumber)
 		return
 	}
 
 	// Update call state
 	call.SetState(newState)
+	if newState == CallStateActive {
+		m.startCallAudio(call)
+	}
 
 	// Send call event
 	event := NewCallEvent(eventType, call, message)
 	m.sendEvent(event)
 
-	log.Printf("Call state changed for friend %d: %s (%s)", friendNumber, newState, message)
+	logging.Infof("Call state changed for friend %d: %s (%s)", friendNumber, newState, message)
 }
 
 // onAudioFrameReceived handles incoming audio frames from ToxAV
@@ -134,12 +167,12 @@ func (m *Manager) onAudioFrameReceived(friendNumber uint32, pcm []int16, sampleC
 ) {
 	call, exists := m.GetActiveCall(friendNumber)
 	if !exists {
-		log.Printf("Warning: Received audio frame for unknown call from friend %d", friendNumber)
+		logging.Warnf("Received audio frame for unknown call from friend %d", friendNumber)
 		return
 	}
 
 	if call.State != CallStateActive {
-		log.Printf("Warning: Received audio frame for inactive call from friend %d: %s",
+		logging.Warnf("Received audio frame for inactive call from friend %d: %s",
 			friendNumber, call.State)
 		return
 	}
@@ -154,7 +187,8 @@ func (m *Manager) onAudioFrameReceived(friendNumber uint32, pcm []int16, sampleC
 		Timestamp:    call.StartTime,
 	}
 
-	// Send audio frame event
+	// Play it and send the audio frame event
+	m.playCallAudio(friendNumber, pcm, channels, samplingRate)
 	event := NewAudioFrameEvent(call, audioFrame)
 	m.sendEvent(event)
 
@@ -166,7 +200,7 @@ func (m *Manager) onAudioFrameReceived(friendNumber uint32, pcm []int16, sampleC
 
 	// Log periodic audio frame reception (every 1000 frames to avoid spam)
 	if frameCount%1000 == 0 {
-		log.Printf("Received audio frame %d from friend %d (samples=%d, rate=%d)",
+		logging.Debugf("Received audio frame %d from friend %d (samples=%d, rate=%d)",
 			frameCount, friendNumber, sampleCount, samplingRate)
 	}
 }
@@ -177,18 +211,18 @@ func (m *Manager) onVideoFrameReceived(friendNumber uint32, width, height uint16
 ) {
 	call, exists := m.GetActiveCall(friendNumber)
 	if !exists {
-		log.Printf("Warning: Received video frame for unknown call from friend %d", friendNumber)
+		logging.Warnf("Received video frame for unknown call from friend %d", friendNumber)
 		return
 	}
 
 	if call.State != CallStateActive {
-		log.Printf("Warning: Received video frame for inactive call from friend %d: %s",
+		logging.Warnf("Received video frame for inactive call from friend %d: %s",
 			friendNumber, call.State)
 		return
 	}
 
 	if !call.IsVideoEnabled() {
-		log.Printf("Warning: Received video frame but video is disabled for friend %d", friendNumber)
+		logging.Warnf("Received video frame but video is disabled for friend %d", friendNumber)
 		return
 	}
 
@@ -218,45 +252,19 @@ func (m *Manager) onVideoFrameReceived(friendNumber uint32, width, height uint16
 
 	// Log periodic video frame reception (every 30 frames to avoid spam)
 	if frameCount%30 == 0 {
-		log.Printf("Received video frame %d from friend %d (%dx%d)",
+		logging.Debugf("Received video frame %d from friend %d (%dx%d)",
 			frameCount, friendNumber, width, height)
 	}
 }
 
-// onAudioBitrateChanged handles audio bitrate change events from ToxAV
+// onAudioBitrateChanged adapts the audio bitrate to the bitrate ToxAV
+// suggests for the network
 func (m *Manager) onAudioBitrateChanged(friendNumber, bitrate uint32) {
-	call, exists := m.GetActiveCall(friendNumber)
-	if !exists {
-		log.Printf("Warning: Audio bitrate changed for unknown call from friend %d", friendNumber)
-		return
-	}
-
-	// Update call audio bitrate
-	call.SetAudioBitrate(bitrate)
-
-	// Send bitrate change event
-	event := NewCallEvent(CallEventBitrateChanged, call,
-		fmt.Sprintf("Audio bitrate changed to %d kbps", bitrate))
-	m.sendEvent(event)
-
-	log.Printf("Audio bitrate changed for friend %d: %d kbps", friendNumber, bitrate)
+	m.adaptBitrate(friendNumber, bitrate, false)
 }
 
-// onVideoBitrateChanged handles video bitrate change events from ToxAV
+// onVideoBitrateChanged adapts the video bitrate to the bitrate ToxAV
+// suggests for the network
 func (m *Manager) onVideoBitrateChanged(friendNumber, bitrate uint32) {
-	call, exists := m.GetActiveCall(friendNumber)
-	if !exists {
-		log.Printf("Warning: Video bitrate changed for unknown call from friend %d", friendNumber)
-		return
-	}
-
-	// Update call video bitrate
-	call.SetVideoBitrate(bitrate)
-
-	// Send bitrate change event
-	event := NewCallEvent(CallEventBitrateChanged, call,
-		fmt.Sprintf("Video bitrate changed to %d kbps", bitrate))
-	m.sendEvent(event)
-
-	log.Printf("Video bitrate changed for friend %d: %d kbps", friendNumber, bitrate)
+	m.adaptBitrate(friendNumber, bitrate, true)
 }
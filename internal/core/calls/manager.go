@@ -5,11 +5,12 @@ package calls
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
 	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
 )
 
 // Config holds configuration for the call manager
@@ -61,6 +62,16 @@ type Manager struct {
 	callHistory []*Call          // Historical calls
 	running     bool
 
+	// Audio devices for active calls
+	audioDevice AudioDevice
+	callAudio   map[uint32]*callAudio // friendID -> audio path
+
+	// Bitrate adaptation for active calls
+	bitrates map[uint32]*callBitrates // friendID -> controllers
+
+	// Reports friends whose calls are rejected without ringing
+	isBlocked func(friendID uint32) bool
+
 	// Event handling
 	eventHandler CallEventHandler
 	eventChan    chan *CallEvent
@@ -89,6 +100,8 @@ func NewManager(tox *toxcore.Tox, config *Config, eventHandler CallEventHandler)
 		config:       config,
 		activeCalls:  make(map[uint32]*Call),
 		callHistory:  make([]*Call, 0),
+		callAudio:    make(map[uint32]*callAudio),
+		bitrates:     make(map[uint32]*callBitrates),
 		eventHandler: eventHandler,
 		eventChan:    make(chan *CallEvent, 100), // Buffered channel
 		ctx:          ctx,
@@ -118,7 +131,7 @@ func (m *Manager) Start() error {
 	// Start the ToxAV iteration loop
 	go m.iterationLoop()
 
-	log.Println("Call manager started successfully")
+	logging.Infof("Call manager started successfully")
 	return nil
 }
 
@@ -150,7 +163,7 @@ func (m *Manager) Stop() error {
 		m.toxAV.Kill()
 	}
 
-	log.Println("Call manager stopped")
+	logging.Infof("Call manager stopped")
 	return nil
 }
 
@@ -175,8 +188,8 @@ func (m *Manager) PlaceCall(friendID uint32, callType CallType) error {
 		videoBitRate = m.config.VideoBitRate
 	}
 
-	// Place the call using ToxAV
-	err := m.toxAV.Call(friendID, audioBitRate, videoBitRate)
+	// Place the call using ToxAV, which counts bitrates in bits per second
+	err := m.toxAV.Call(friendID, audioBitRate*1000, videoBitRate*1000)
 	if err != nil {
 		// Clean up on failure
 		delete(m.activeCalls, friendID)
@@ -188,14 +201,16 @@ func (m *Manager) PlaceCall(friendID uint32, callType CallType) error {
 		return fmt.Errorf("failed to place call to friend %d: %w", friendID, err)
 	}
 
+	m.startBitrateControl(call)
+
 	// Send call event
-	event := NewCallEvent(CallEventIncoming, call, "Outgoing call initiated")
+	event := NewCallEvent(CallEventOutgoing, call, "Outgoing call initiated")
 	m.sendEvent(event)
 
 	// Set timeout for the call
 	go m.handleCallTimeout(call)
 
-	log.Printf("Placed %s call to friend %d", callType, friendID)
+	logging.Infof("Placed %s call to friend %d", callType, friendID)
 	return nil
 }
 
@@ -220,8 +235,8 @@ func (m *Manager) AnswerCall(friendID uint32) error {
 		videoBitRate = m.config.VideoBitRate
 	}
 
-	// Answer the call using ToxAV
-	err := m.toxAV.Answer(friendID, audioBitRate, videoBitRate)
+	// Answer the call using ToxAV, which counts bitrates in bits per second
+	err := m.toxAV.Answer(friendID, audioBitRate*1000, videoBitRate*1000)
 	if err != nil {
 		call.SetState(CallStateEnded)
 		delete(m.activeCalls, friendID)
@@ -233,12 +248,14 @@ func (m *Manager) AnswerCall(friendID uint32) error {
 	}
 
 	call.SetState(CallStateActive)
+	m.startCallAudio(call)
+	m.startBitrateControl(call)
 
 	// Send call event
 	event := NewCallEvent(CallEventStateChanged, call, "Call answered and active")
 	m.sendEvent(event)
 
-	log.Printf("Answered %s call from friend %d", call.Type, friendID)
+	logging.Infof("Answered %s call from friend %d", call.Type, friendID)
 	return nil
 }
 
@@ -260,11 +277,13 @@ func (m *Manager) endCall(friendID uint32, reason string) error {
 	// Use ToxAV call control to hang up
 	err := m.toxAV.CallControl(friendID, 0) // 0 = TOXAV_CALL_CONTROL_CANCEL/FINISH
 	if err != nil {
-		log.Printf("Warning: ToxAV call control failed for friend %d: %v", friendID, err)
+		logging.Warnf("ToxAV call control failed for friend %d: %v", friendID, err)
 	}
 
 	// Update call state
 	call.SetState(CallStateEnded)
+	m.stopCallAudio(friendID)
+	m.stopBitrateControl(friendID)
 
 	// Move to history and remove from active calls
 	m.callHistory = append(m.callHistory, call)
@@ -274,7 +293,7 @@ func (m *Manager) endCall(friendID uint32, reason string) error {
 	event := NewCallEvent(CallEventEnded, call, reason)
 	m.sendEvent(event)
 
-	log.Printf("Ended call with friend %d: %s", friendID, reason)
+	logging.Infof("Ended call with friend %d: %s", friendID, reason)
 	return nil
 }
 
@@ -326,7 +345,7 @@ func (m *Manager) sendEvent(event *CallEvent) {
 		// Event sent successfully
 	default:
 		// Channel is full, log warning
-		log.Printf("Warning: Call event channel is full, dropping event: %+v", event)
+		logging.Warnf("Call event channel is full, dropping event: %+v", event)
 	}
 }
 
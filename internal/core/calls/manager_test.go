@@ -282,3 +282,33 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected 30s call timeout, got %v", config.CallTimeout)
 	}
 }
+
+func TestCallManager_BlockedCaller(t *testing.T) {
+	tox, err := toxcore.New(toxcore.NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	manager, err := NewManager(tox, nil, &MockEventHandler{})
+	if err != nil {
+		t.Fatalf("Failed to create call manager: %v", err)
+	}
+	manager.SetBlockedFilter(func(friendID uint32) bool { return friendID == 3 })
+
+	manager.onCallReceived(3, true, false)
+	if _, exists := manager.GetActiveCall(3); exists {
+		t.Error("Expected no call from a blocked friend")
+	}
+	if len(manager.eventChan) != 0 {
+		t.Errorf("Expected no event for a blocked call, got %d", len(manager.eventChan))
+	}
+
+	manager.onCallReceived(4, true, false)
+	if _, exists := manager.GetActiveCall(4); !exists {
+		t.Error("Expected an incoming call from an unblocked friend")
+	}
+	if len(manager.eventChan) != 1 {
+		t.Errorf("Expected one incoming call event, got %d", len(manager.eventChan))
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	configpkg "github.com/opd-ai/whisp/internal/core/config"
+	"github.com/opd-ai/whisp/internal/logging"
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+// compactionCheckInterval is how often the app looks for a due compaction
+const compactionCheckInterval = time.Hour
+
+// CompactDatabase removes deleted messages older than the configured
+// retention and shrinks the database file
+func (a *App) CompactDatabase() (*storage.CompactResult, error) {
+	return a.storage.CompactDatabase(purgeCutoff(a.configMgr.GetConfig(), time.Now()))
+}
+
+// startCompaction compacts the database whenever the configured interval has
+// passed since the last compaction, until the context is done
+func (a *App) startCompaction(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(compactionCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.compactIfDue()
+			}
+		}
+	}()
+}
+
+// compactIfDue compacts the database if it is unlocked and due
+func (a *App) compactIfDue() {
+	if a.IsLocked() {
+		return
+	}
+	last, err := a.storage.LastCompacted()
+	if err != nil {
+		logging.Warnf("%v", err)
+		return
+	}
+	if !compactionDue(a.configMgr.GetConfig(), last, time.Now()) {
+		return
+	}
+	if _, err := a.CompactDatabase(); err != nil {
+		logging.Warnf("Scheduled compaction failed: %v", err)
+	}
+}
+
+// compactionDue reports whether automatic compaction is on and the interval
+// has passed since the last one
+func compactionDue(cfg configpkg.Config, last, now time.Time) bool {
+	if !cfg.Storage.CompactEnabled {
+		return false
+	}
+	interval := 7 * 24 * time.Hour
+	if cfg.Storage.CompactInterval == "monthly" {
+		interval = 30 * 24 * time.Hour
+	}
+	return now.Sub(last) >= interval
+}
+
+// purgeCutoff returns the time before which deleted messages are removed for
+// good, or the zero time to keep them all
+func purgeCutoff(cfg configpkg.Config, now time.Time) time.Time {
+	days := cfg.Storage.DeletedRetentionDays
+	if days <= 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -days)
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	configpkg "github.com/opd-ai/whisp/internal/core/config"
+)
+
+func TestCompactionDue(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		interval string
+		last     time.Time
+		want     bool
+	}{
+		{"never compacted", true, "weekly", time.Time{}, true},
+		{"disabled", false, "weekly", time.Time{}, false},
+		{"weekly not due", true, "weekly", now.Add(-6 * day), false},
+		{"weekly due", true, "weekly", now.Add(-7 * day), true},
+		{"monthly not due", true, "monthly", now.Add(-20 * day), false},
+		{"monthly due", true, "monthly", now.Add(-30 * day), true},
+		{"old config compacts weekly", true, "", now.Add(-8 * day), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg configpkg.Config
+			cfg.Storage.CompactEnabled = tt.enabled
+			cfg.Storage.CompactInterval = tt.interval
+			if got := compactionDue(cfg, tt.last, now); got != tt.want {
+				t.Errorf("compactionDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPurgeCutoff(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	var cfg configpkg.Config
+	if got := purgeCutoff(cfg, now); !got.IsZero() {
+		t.Errorf("Expected no purge without a retention, got %v", got)
+	}
+
+	cfg.Storage.DeletedRetentionDays = 30
+	if got, want := purgeCutoff(cfg, now), time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("purgeCutoff() = %v, want %v", got, want)
+	}
+}
@@ -4,15 +4,29 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultStaleContactDays is how long a contact must be inactive before it is
+// offered for cleanup
+const DefaultStaleContactDays = 365
+
+// DefaultBackupRetention is how many scheduled backups are kept
+const DefaultBackupRetention = 7
+
+// DefaultDeletedRetentionDays is how long deleted messages are kept before
+// compaction removes them for good
+const DefaultDeletedRetentionDays = 30
+
 // Manager handles application configuration
 // Uses established libraries: yaml.v3 for parsing, standard library for file I/O
 type Manager struct {
 	configPath string
 	config     *Config
+	onChange   []func(Config)
 }
 
 // Config represents the complete application configuration
@@ -36,6 +50,7 @@ type Config struct {
 			Username string `yaml:"username"`
 			Password string `yaml:"password"`
 		} `yaml:"proxy"`
+		BootstrapNodesFile string `yaml:"bootstrap_nodes_file"` // JSON node list used instead of bootstrap_nodes
 	} `yaml:"network"`
 
 	Storage struct {
@@ -45,46 +60,93 @@ type Config struct {
 		DownloadDir           string `yaml:"download_dir"`
 		MaxMessageHistoryDays int    `yaml:"max_message_history_days"`
 		AutoDeleteMediaDays   int    `yaml:"auto_delete_media_days"`
+		StaleContactDays      int    `yaml:"stale_contact_days"`
+		VoiceFormat           string `yaml:"voice_format"`  // "opus" or "wav"
+		VoiceBitrate          int    `yaml:"voice_bitrate"` // Opus bitrate in kbps
+		BackupEnabled         bool   `yaml:"backup_enabled"`
+		BackupInterval        string `yaml:"backup_interval"`  // "daily" or "weekly"
+		BackupRetention       int    `yaml:"backup_retention"` // Scheduled backups kept
+		BackupDir             string `yaml:"backup_dir"`       // Empty uses "backups" in the data directory
+		CompactEnabled        bool   `yaml:"compact_enabled"`
+		CompactInterval       string `yaml:"compact_interval"`       // "weekly" or "monthly"
+		DeletedRetentionDays  int    `yaml:"deleted_retention_days"` // 0 = never remove deleted messages
+		Cipher                struct {
+			Compatibility int    `yaml:"compatibility"`  // SQLCipher major version whose defaults apply; 0 = 4
+			KDFIterations int    `yaml:"kdf_iterations"` // 0 = the version's default
+			PageSize      int    `yaml:"page_size"`      // 0 = the version's default
+			HMACAlgorithm string `yaml:"hmac_algorithm"` // HMAC_SHA1, HMAC_SHA256 or HMAC_SHA512; empty = the version's default
+			KDFAlgorithm  string `yaml:"kdf_algorithm"`  // PBKDF2_HMAC_SHA1, PBKDF2_HMAC_SHA256 or PBKDF2_HMAC_SHA512; empty = the version's default
+		} `yaml:"cipher"`
+		ThumbnailCache struct {
+			CleanupOnStartup bool  `yaml:"cleanup_on_startup"`
+			MaxSize          int64 `yaml:"max_size"`
+			Workers          int   `yaml:"workers"`
+		} `yaml:"thumbnail_cache"`
+		DownloadDirs map[string]string `yaml:"download_dirs"` // Per media type: image, video, audio, document; empty uses DownloadDir
 	} `yaml:"storage"`
 
 	UI struct {
-		Theme              string `yaml:"theme"`
-		Language           string `yaml:"language"`
-		FontFamily         string `yaml:"font_family"`
-		FontSize           string `yaml:"font_size"`
-		EnableAnimations   bool   `yaml:"enable_animations"`
-		EnableSoundEffects bool   `yaml:"enable_sound_effects"`
+		Theme              string   `yaml:"theme"`
+		Language           string   `yaml:"language"`
+		FontFamily         string   `yaml:"font_family"`
+		FontSize           string   `yaml:"font_size"`
+		EnableAnimations   bool     `yaml:"enable_animations"`
+		EnableSoundEffects bool     `yaml:"enable_sound_effects"`
+		QuickReactions     []string `yaml:"quick_reactions"`
+		MessageWindowSize  int      `yaml:"message_window_size"`
+		ContactSort        string   `yaml:"contact_sort"` // favorites, recent or alphabetical
 		Window             struct {
 			RememberSize     bool `yaml:"remember_size"`
 			RememberPosition bool `yaml:"remember_position"`
 			MinimizeToTray   bool `yaml:"minimize_to_tray"`
 			StartMinimized   bool `yaml:"start_minimized"`
+			Width            int  `yaml:"width"`  // Last window width, 0 if never saved
+			Height           int  `yaml:"height"` // Last window height, 0 if never saved
 		} `yaml:"window"`
 		Mobile struct {
 			VibrateOnMessage   bool `yaml:"vibrate_on_message"`
 			ShowMessagePreview bool `yaml:"show_message_preview"`
 		} `yaml:"mobile"`
+		PasteImageFile string `yaml:"paste_image_file"` // Screenshot file pasted when the clipboard has no text
 	} `yaml:"ui"`
 
 	Privacy struct {
-		SaveMessageHistory           bool   `yaml:"save_message_history"`
-		EnableDisappearingMessages   bool   `yaml:"enable_disappearing_messages"`
-		DefaultDisappearingTimer     string `yaml:"default_disappearing_timer"`
-		ShowTypingIndicators         bool   `yaml:"show_typing_indicators"`
-		SendTypingIndicators         bool   `yaml:"send_typing_indicators"`
-		ShowReadReceipts             bool   `yaml:"show_read_receipts"`
-		SendReadReceipts             bool   `yaml:"send_read_receipts"`
-		ShowLastSeen                 bool   `yaml:"show_last_seen"`
-		AutoAcceptFiles              bool   `yaml:"auto_accept_files"`
-		AutoDownloadLimit            int64  `yaml:"auto_download_limit"`
-		PreventScreenshots           bool   `yaml:"prevent_screenshots"`
-		AutoAcceptFriendRequests     bool   `yaml:"auto_accept_friend_requests"`
-		RequireFriendRequestsMessage bool   `yaml:"require_friend_requests_message"`
+		SaveMessageHistory           bool          `yaml:"save_message_history"`
+		EnableDisappearingMessages   bool          `yaml:"enable_disappearing_messages"`
+		DefaultDisappearingTimer     string        `yaml:"default_disappearing_timer"`
+		ShowTypingIndicators         bool          `yaml:"show_typing_indicators"`
+		SendTypingIndicators         bool          `yaml:"send_typing_indicators"`
+		ShowReadReceipts             bool          `yaml:"show_read_receipts"`
+		SendReadReceipts             bool          `yaml:"send_read_receipts"`
+		ShowLastSeen                 bool          `yaml:"show_last_seen"`
+		AppearOffline                bool          `yaml:"appear_offline"`
+		AutoAway                     bool          `yaml:"auto_away"`
+		AutoAwayAfter                time.Duration `yaml:"auto_away_after"`
+		LockTimeout                  time.Duration `yaml:"lock_timeout"` // 0 = never
+		EnableTranslation            bool          `yaml:"enable_translation"`
+		ClipboardClearSeconds        int           `yaml:"clipboard_clear_seconds"`
+		AutoAcceptFiles              bool          `yaml:"auto_accept_files"`
+		AutoDownloadLimit            int64         `yaml:"auto_download_limit"`
+		PreventScreenshots           bool          `yaml:"prevent_screenshots"`
+		AutoAcceptFriendRequests     bool          `yaml:"auto_accept_friend_requests"`
+		RequireFriendRequestsMessage bool          `yaml:"require_friend_requests_message"`
+		ConfirmUnverifiedContacts    bool          `yaml:"confirm_unverified_contacts"`
+		UnknownSenderPolicy          string        `yaml:"unknown_sender_policy"`
+		HonorRemoteDeletions         bool          `yaml:"honor_remote_deletions"`
+		RetentionDays                int           `yaml:"retention_days"` // 0 = keep messages forever
+		RetentionHardDelete          bool          `yaml:"retention_hard_delete"`
+		OpenLinks                    bool          `yaml:"open_links"`
+		LinkPreviews                 bool          `yaml:"link_previews"`
+		LinkPreviewTimeout           time.Duration `yaml:"link_preview_timeout"`
 	} `yaml:"privacy"`
 
 	Notifications struct {
-		Enabled bool `yaml:"enabled"`
-		Desktop struct {
+		Enabled            bool     `yaml:"enabled"`
+		SuppressActiveChat bool     `yaml:"suppress_active_chat"`
+		Actions            bool     `yaml:"actions"`
+		Mode               string   `yaml:"mode"`
+		Keywords           []string `yaml:"keywords"`
+		Desktop            struct {
 			ShowPreview bool `yaml:"show_preview"`
 			PlaySound   bool `yaml:"play_sound"`
 			ShowSender  bool `yaml:"show_sender"`
@@ -96,9 +158,10 @@ type Config struct {
 			LEDColor         string `yaml:"led_color"`
 		} `yaml:"mobile"`
 		QuietHours struct {
-			Enabled   bool   `yaml:"enabled"`
-			StartTime string `yaml:"start_time"`
-			EndTime   string `yaml:"end_time"`
+			Enabled   bool     `yaml:"enabled"`
+			StartTime string   `yaml:"start_time"`
+			EndTime   string   `yaml:"end_time"`
+			Days      []string `yaml:"days"` // Days the window starts on; empty means every day
 		} `yaml:"quiet_hours"`
 	} `yaml:"notifications"`
 
@@ -111,7 +174,13 @@ type Config struct {
 		MessageCacheSize       int    `yaml:"message_cache_size"`
 		EnableDebugMode        bool   `yaml:"enable_debug_mode"`
 		ShowInternalIDs        bool   `yaml:"show_internal_ids"`
-		Experimental           struct {
+		VerifyTransferChunks   bool   `yaml:"verify_transfer_chunks"`
+		TransferRateLimit      int    `yaml:"transfer_rate_limit"` // Bytes per second for all uploads; 0 is unlimited
+		SendRetry              struct {
+			MaxAttempts int           `yaml:"max_attempts"`
+			Expiry      time.Duration `yaml:"expiry"`
+		} `yaml:"send_retry"`
+		Experimental struct {
 			EnableVoiceCalls bool `yaml:"enable_voice_calls"`
 			EnableVideoCalls bool `yaml:"enable_video_calls"`
 			EnableGroupChats bool `yaml:"enable_group_chats"`
@@ -186,12 +255,34 @@ func (m *Manager) UpdateConfig(config Config) error {
 	}
 
 	m.config = &config
-	return m.Save()
+	if err := m.Save(); err != nil {
+		return err
+	}
+	for _, callback := range m.onChange {
+		callback(config)
+	}
+	return nil
+}
+
+// OnChange registers a callback run with the new configuration after each
+// successful UpdateConfig, for settings that apply without a restart
+func (m *Manager) OnChange(callback func(Config)) {
+	m.onChange = append(m.onChange, callback)
 }
 
 // validateConfig performs basic validation on configuration values
 // Ensures values are within reasonable ranges to prevent runtime errors
 func (m *Manager) validateConfig(config *Config) error {
+	// Validate bootstrap nodes; their keys are checked when they are used
+	for _, node := range config.Network.BootstrapNodes {
+		if node.Address == "" {
+			return fmt.Errorf("bootstrap node needs an address")
+		}
+		if node.Port < 1 || node.Port > 65535 {
+			return fmt.Errorf("invalid bootstrap node port: %d", node.Port)
+		}
+	}
+
 	// Validate theme values
 	validThemes := map[string]bool{
 		"system": true, "light": true, "dark": true, "amoled": true, "custom": true,
@@ -208,15 +299,206 @@ func (m *Manager) validateConfig(config *Config) error {
 		return fmt.Errorf("invalid font size: %s", config.UI.FontSize)
 	}
 
+	// Empty contact sort lists favorites first
+	validContactSorts := map[string]bool{
+		"": true, "favorites": true, "recent": true, "alphabetical": true,
+	}
+	if !validContactSorts[config.UI.ContactSort] {
+		return fmt.Errorf("invalid contact sort: %s", config.UI.ContactSort)
+	}
+
+	// Zero message window size uses the built-in default
+	if config.UI.MessageWindowSize < 0 {
+		return fmt.Errorf("message window size cannot be negative")
+	}
+
+	// Zero window dimensions use the default size
+	if config.UI.Window.Width < 0 || config.UI.Window.Height < 0 {
+		return fmt.Errorf("window size cannot be negative")
+	}
+
+	// Empty quick reactions fall back to the built-in set
+	if len(config.UI.QuickReactions) > 0 {
+		if err := ValidateQuickReactions(config.UI.QuickReactions); err != nil {
+			return err
+		}
+	}
+
 	// Validate file size limits (must be positive)
 	if config.Storage.MaxFileSize <= 0 {
 		return fmt.Errorf("max file size must be positive")
 	}
 
+	if config.Storage.StaleContactDays < 0 {
+		return fmt.Errorf("stale contact period cannot be negative")
+	}
+
+	// Files of types without their own folder go to the download directory
+	validMediaTypes := map[string]bool{
+		"image": true, "video": true, "audio": true, "document": true,
+	}
+	for mediaType := range config.Storage.DownloadDirs {
+		if !validMediaTypes[mediaType] {
+			return fmt.Errorf("invalid download directory media type: %s", mediaType)
+		}
+	}
+
+	// Empty backup interval backs up daily, for configs from before it existed
+	validBackupIntervals := map[string]bool{
+		"": true, "daily": true, "weekly": true,
+	}
+	if !validBackupIntervals[config.Storage.BackupInterval] {
+		return fmt.Errorf("invalid backup interval: %s", config.Storage.BackupInterval)
+	}
+	if config.Storage.BackupRetention < 0 {
+		return fmt.Errorf("backup retention cannot be negative")
+	}
+
+	// Empty compaction interval compacts weekly, for configs from before it existed
+	validCompactIntervals := map[string]bool{
+		"": true, "weekly": true, "monthly": true,
+	}
+	if !validCompactIntervals[config.Storage.CompactInterval] {
+		return fmt.Errorf("invalid compaction interval: %s", config.Storage.CompactInterval)
+	}
+	if config.Storage.DeletedRetentionDays < 0 {
+		return fmt.Errorf("deleted message retention cannot be negative")
+	}
+
+	// Empty voice format records Opus, for configs from before it existed
+	validVoiceFormats := map[string]bool{
+		"": true, "opus": true, "wav": true,
+	}
+	if !validVoiceFormats[config.Storage.VoiceFormat] {
+		return fmt.Errorf("invalid voice format: %s", config.Storage.VoiceFormat)
+	}
+
+	if config.Storage.VoiceBitrate < 0 {
+		return fmt.Errorf("voice bitrate cannot be negative")
+	}
+
+	if err := validateCipher(config); err != nil {
+		return err
+	}
+
+	if config.Storage.ThumbnailCache.MaxSize < 0 {
+		return fmt.Errorf("thumbnail cache size cannot be negative")
+	}
+
+	if config.Storage.ThumbnailCache.Workers < 0 {
+		return fmt.Errorf("thumbnail workers cannot be negative")
+	}
+
 	if config.Privacy.AutoDownloadLimit <= 0 {
 		return fmt.Errorf("auto download limit must be positive")
 	}
 
+	// Validate unknown sender policy (empty means accept for older configs)
+	validSenderPolicies := map[string]bool{
+		"": true, "accept": true, "hold": true, "reject": true,
+	}
+	if !validSenderPolicies[config.Privacy.UnknownSenderPolicy] {
+		return fmt.Errorf("invalid unknown sender policy: %s", config.Privacy.UnknownSenderPolicy)
+	}
+
+	validNotificationModes := map[string]bool{
+		"": true, "all": true, "mentions": true,
+	}
+	if !validNotificationModes[config.Notifications.Mode] {
+		return fmt.Errorf("invalid notification mode: %s", config.Notifications.Mode)
+	}
+
+	// Validate quiet hours ("HH:MM" times, required once enabled)
+	quietHours := config.Notifications.QuietHours
+	for _, clock := range []string{quietHours.StartTime, quietHours.EndTime} {
+		if clock == "" && !quietHours.Enabled {
+			continue
+		}
+		if _, err := time.Parse("15:04", clock); err != nil {
+			return fmt.Errorf("invalid quiet hours time: %q", clock)
+		}
+	}
+	validQuietDays := map[string]bool{
+		"sun": true, "mon": true, "tue": true, "wed": true, "thu": true, "fri": true, "sat": true,
+	}
+	for _, day := range quietHours.Days {
+		if !validQuietDays[strings.ToLower(day)] {
+			return fmt.Errorf("invalid quiet hours day: %s", day)
+		}
+	}
+
+	if config.Advanced.SendRetry.MaxAttempts < 0 || config.Advanced.SendRetry.Expiry < 0 {
+		return fmt.Errorf("send retry budget cannot be negative")
+	}
+
+	validLogLevels := map[string]bool{
+		"debug": true, "info": true, "warn": true, "error": true,
+	}
+	if !validLogLevels[config.Advanced.LogLevel] {
+		return fmt.Errorf("invalid log level: %s", config.Advanced.LogLevel)
+	}
+
+	if config.Advanced.MaxLogSize < 0 {
+		return fmt.Errorf("max log size cannot be negative")
+	}
+
+	if config.Advanced.TransferRateLimit < 0 {
+		return fmt.Errorf("transfer rate limit cannot be negative")
+	}
+
+	if config.Advanced.MessageCacheSize < 0 {
+		return fmt.Errorf("message cache size cannot be negative")
+	}
+
+	if config.Privacy.AutoAway && config.Privacy.AutoAwayAfter <= 0 {
+		return fmt.Errorf("auto-away idle period must be positive")
+	}
+
+	if config.Privacy.RetentionDays < 0 {
+		return fmt.Errorf("message retention cannot be negative")
+	}
+
+	if config.Privacy.LockTimeout < 0 {
+		return fmt.Errorf("lock timeout cannot be negative")
+	}
+
+	if config.Privacy.ClipboardClearSeconds < 0 {
+		return fmt.Errorf("clipboard clear delay cannot be negative")
+	}
+
+	if config.Privacy.LinkPreviewTimeout < 0 {
+		return fmt.Errorf("link preview timeout cannot be negative")
+	}
+
+	return nil
+}
+
+// validateCipher checks the database cipher settings are ones SQLCipher
+// supports; they are checked again when the database is opened
+func validateCipher(config *Config) error {
+	cipher := config.Storage.Cipher
+	if cipher.Compatibility < 0 || cipher.Compatibility > 4 {
+		return fmt.Errorf("cipher compatibility must be between 1 and 4, got %d", cipher.Compatibility)
+	}
+	if cipher.KDFIterations < 0 {
+		return fmt.Errorf("cipher KDF iterations cannot be negative")
+	}
+	if cipher.PageSize != 0 && (cipher.PageSize < 512 || cipher.PageSize > 65536 || cipher.PageSize&(cipher.PageSize-1) != 0) {
+		return fmt.Errorf("cipher page size must be a power of two between 512 and 65536, got %d", cipher.PageSize)
+	}
+
+	validHMACAlgorithms := map[string]bool{
+		"": true, "HMAC_SHA1": true, "HMAC_SHA256": true, "HMAC_SHA512": true,
+	}
+	if !validHMACAlgorithms[cipher.HMACAlgorithm] {
+		return fmt.Errorf("invalid cipher HMAC algorithm: %s", cipher.HMACAlgorithm)
+	}
+	validKDFAlgorithms := map[string]bool{
+		"": true, "PBKDF2_HMAC_SHA1": true, "PBKDF2_HMAC_SHA256": true, "PBKDF2_HMAC_SHA512": true,
+	}
+	if !validKDFAlgorithms[cipher.KDFAlgorithm] {
+		return fmt.Errorf("invalid cipher KDF algorithm: %s", cipher.KDFAlgorithm)
+	}
 	return nil
 }
 
@@ -230,13 +512,26 @@ func (m *Manager) setDefaults() {
 	m.config.Network.EnableLocalDiscovery = true
 	m.config.Network.EnableHolePunching = true
 	m.config.Network.Proxy.Type = "none"
+	m.config.Network.BootstrapNodesFile = "bootstrap_nodes.json"
 
 	// Storage defaults
 	m.config.Storage.EnableEncryption = true
+	m.config.Storage.Cipher.Compatibility = 4
 	m.config.Storage.MaxFileSize = 2147483648 // 2GB
 	m.config.Storage.DownloadDir = "Downloads"
 	m.config.Storage.MaxMessageHistoryDays = 365
+	m.config.Storage.StaleContactDays = DefaultStaleContactDays
+	m.config.Storage.BackupInterval = "daily"
+	m.config.Storage.BackupRetention = DefaultBackupRetention
+	m.config.Storage.CompactEnabled = true
+	m.config.Storage.CompactInterval = "weekly"
+	m.config.Storage.DeletedRetentionDays = DefaultDeletedRetentionDays
 	m.config.Storage.AutoDeleteMediaDays = 30
+	m.config.Storage.VoiceFormat = "wav"
+	m.config.Storage.VoiceBitrate = 32
+	m.config.Storage.ThumbnailCache.CleanupOnStartup = true
+	m.config.Storage.ThumbnailCache.MaxSize = 104857600 // 100MB
+	m.config.Storage.ThumbnailCache.Workers = 4
 
 	// UI defaults
 	m.config.UI.Theme = "system"
@@ -244,6 +539,9 @@ func (m *Manager) setDefaults() {
 	m.config.UI.FontSize = "medium"
 	m.config.UI.EnableAnimations = true
 	m.config.UI.EnableSoundEffects = true
+	m.config.UI.QuickReactions = append([]string(nil), DefaultQuickReactions...)
+	m.config.UI.MessageWindowSize = 200
+	m.config.UI.ContactSort = "favorites"
 	m.config.UI.Window.RememberSize = true
 	m.config.UI.Window.RememberPosition = true
 	m.config.UI.Window.MinimizeToTray = true
@@ -258,9 +556,21 @@ func (m *Manager) setDefaults() {
 	m.config.Privacy.SendReadReceipts = true
 	m.config.Privacy.ShowLastSeen = true
 	m.config.Privacy.AutoDownloadLimit = 10485760 // 10MB
+	m.config.Privacy.UnknownSenderPolicy = "hold"
+	m.config.Privacy.HonorRemoteDeletions = true
+	m.config.Privacy.RetentionDays = 0
+	m.config.Privacy.RetentionHardDelete = false
+	m.config.Privacy.OpenLinks = true
+	m.config.Privacy.LinkPreviews = false
+	m.config.Privacy.LinkPreviewTimeout = 5 * time.Second
+	m.config.Privacy.ClipboardClearSeconds = 30
+	m.config.Privacy.AutoAwayAfter = 10 * time.Minute
 
 	// Notification defaults
 	m.config.Notifications.Enabled = true
+	m.config.Notifications.SuppressActiveChat = true
+	m.config.Notifications.Actions = true
+	m.config.Notifications.Mode = "all"
 	m.config.Notifications.Desktop.ShowPreview = true
 	m.config.Notifications.Desktop.PlaySound = true
 	m.config.Notifications.Desktop.ShowSender = true
@@ -268,6 +578,8 @@ func (m *Manager) setDefaults() {
 	m.config.Notifications.Mobile.ShowPreview = true
 	m.config.Notifications.Mobile.Vibrate = true
 	m.config.Notifications.Mobile.LEDColor = "#0066CC"
+	m.config.Notifications.QuietHours.StartTime = "22:00"
+	m.config.Notifications.QuietHours.EndTime = "08:00"
 
 	// Advanced defaults
 	m.config.Advanced.LogLevel = "info"
@@ -275,4 +587,6 @@ func (m *Manager) setDefaults() {
 	m.config.Advanced.MaxConcurrentDownloads = 3
 	m.config.Advanced.MaxConcurrentUploads = 3
 	m.config.Advanced.MessageCacheSize = 1000
+	m.config.Advanced.SendRetry.MaxAttempts = 5
+	m.config.Advanced.SendRetry.Expiry = 24 * time.Hour
 }
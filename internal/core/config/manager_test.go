@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestNewManager(t *testing.T) {
@@ -124,6 +125,48 @@ func TestValidateConfig(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "invalid contact sort",
+			modify: func(cfg *Config) {
+				cfg.UI.ContactSort = "newest"
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid backup interval",
+			modify: func(cfg *Config) {
+				cfg.Storage.BackupInterval = "hourly"
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative backup retention",
+			modify: func(cfg *Config) {
+				cfg.Storage.BackupRetention = -1
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid download directory media type",
+			modify: func(cfg *Config) {
+				cfg.Storage.DownloadDirs = map[string]string{"image": "Pictures", "memes": "Memes"}
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid compaction interval",
+			modify: func(cfg *Config) {
+				cfg.Storage.CompactInterval = "daily"
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative deleted message retention",
+			modify: func(cfg *Config) {
+				cfg.Storage.DeletedRetentionDays = -1
+			},
+			expectErr: true,
+		},
 		{
 			name: "invalid file size",
 			modify: func(cfg *Config) {
@@ -138,6 +181,150 @@ func TestValidateConfig(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "overnight quiet hours on weekdays",
+			modify: func(cfg *Config) {
+				cfg.Notifications.QuietHours.Enabled = true
+				cfg.Notifications.QuietHours.StartTime = "22:00"
+				cfg.Notifications.QuietHours.EndTime = "07:00"
+				cfg.Notifications.QuietHours.Days = []string{"mon", "Fri"}
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid quiet hours time",
+			modify: func(cfg *Config) {
+				cfg.Notifications.QuietHours.Enabled = true
+				cfg.Notifications.QuietHours.StartTime = "10pm"
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid quiet hours day",
+			modify: func(cfg *Config) {
+				cfg.Notifications.QuietHours.Days = []string{"someday"}
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative clipboard clear delay",
+			modify: func(cfg *Config) {
+				cfg.Privacy.ClipboardClearSeconds = -1
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative window width",
+			modify: func(cfg *Config) {
+				cfg.UI.Window.Width = -1
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative lock timeout",
+			modify: func(cfg *Config) {
+				cfg.Privacy.LockTimeout = -time.Minute
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative message retention",
+			modify: func(cfg *Config) {
+				cfg.Privacy.RetentionDays = -1
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid log level",
+			modify: func(cfg *Config) {
+				cfg.Advanced.LogLevel = "verbose"
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid bootstrap node port",
+			modify: func(cfg *Config) {
+				cfg.Network.BootstrapNodes = append(cfg.Network.BootstrapNodes, struct {
+					Address   string `yaml:"address"`
+					Port      int    `yaml:"port"`
+					PublicKey string `yaml:"public_key"`
+				}{Address: "node.example.org", Port: 70000})
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative transfer rate limit",
+			modify: func(cfg *Config) {
+				cfg.Advanced.TransferRateLimit = -1
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative message cache size",
+			modify: func(cfg *Config) {
+				cfg.Advanced.MessageCacheSize = -1
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid notification mode",
+			modify: func(cfg *Config) {
+				cfg.Notifications.Mode = "keywords"
+			},
+			expectErr: true,
+		},
+		{
+			name: "custom cipher settings",
+			modify: func(cfg *Config) {
+				cfg.Storage.Cipher.KDFIterations = 512000
+				cfg.Storage.Cipher.PageSize = 8192
+				cfg.Storage.Cipher.HMACAlgorithm = "HMAC_SHA256"
+				cfg.Storage.Cipher.KDFAlgorithm = "PBKDF2_HMAC_SHA256"
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid cipher compatibility",
+			modify: func(cfg *Config) {
+				cfg.Storage.Cipher.Compatibility = 5
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid cipher page size",
+			modify: func(cfg *Config) {
+				cfg.Storage.Cipher.PageSize = 1000
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative cipher KDF iterations",
+			modify: func(cfg *Config) {
+				cfg.Storage.Cipher.KDFIterations = -1
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid cipher HMAC algorithm",
+			modify: func(cfg *Config) {
+				cfg.Storage.Cipher.HMACAlgorithm = "MD5"
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid cipher KDF algorithm",
+			modify: func(cfg *Config) {
+				cfg.Storage.Cipher.KDFAlgorithm = "SCRYPT"
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid quick reactions",
+			modify: func(cfg *Config) {
+				cfg.UI.QuickReactions = []string{"👍", "👍"}
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -153,6 +340,30 @@ func TestValidateConfig(t *testing.T) {
 	}
 }
 
+func TestOnChange(t *testing.T) {
+	mgr, err := NewManager(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	var sizes []int
+	mgr.OnChange(func(cfg Config) { sizes = append(sizes, cfg.Advanced.MessageCacheSize) })
+
+	cfg := mgr.GetConfig()
+	cfg.Advanced.MessageCacheSize = 50
+	if err := mgr.UpdateConfig(cfg); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	cfg.Advanced.MessageCacheSize = -1
+	if err := mgr.UpdateConfig(cfg); err == nil {
+		t.Fatal("Expected an invalid config to be rejected")
+	}
+
+	if len(sizes) != 1 || sizes[0] != 50 {
+		t.Errorf("Expected one change with size 50, got %v", sizes)
+	}
+}
+
 func TestConfigDefaults(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "whisp-config-test")
 	if err != nil {
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxQuickReactions is the largest number of emoji allowed in the reaction quick bar
+const MaxQuickReactions = 12
+
+// DefaultQuickReactions is the built-in reaction quick bar
+var DefaultQuickReactions = []string{"👍", "❤️", "😂", "😮", "😢", "🙏"}
+
+// ValidateQuickReactions checks that a reaction quick bar is non-empty, within
+// MaxQuickReactions, and free of blank or duplicate entries
+func ValidateQuickReactions(reactions []string) error {
+	if len(reactions) == 0 {
+		return fmt.Errorf("quick reactions cannot be empty")
+	}
+	if len(reactions) > MaxQuickReactions {
+		return fmt.Errorf("too many quick reactions: %d (max %d)", len(reactions), MaxQuickReactions)
+	}
+
+	seen := make(map[string]bool, len(reactions))
+	for _, reaction := range reactions {
+		if strings.TrimSpace(reaction) == "" {
+			return fmt.Errorf("quick reactions cannot contain blank entries")
+		}
+		if seen[reaction] {
+			return fmt.Errorf("duplicate quick reaction: %s", reaction)
+		}
+		seen[reaction] = true
+	}
+
+	return nil
+}
+
+// GetQuickReactions returns the configured reaction quick bar, falling back to
+// DefaultQuickReactions when the configured set is missing or invalid
+func (c *Config) GetQuickReactions() []string {
+	if err := ValidateQuickReactions(c.UI.QuickReactions); err != nil {
+		return append([]string(nil), DefaultQuickReactions...)
+	}
+	return append([]string(nil), c.UI.QuickReactions...)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateQuickReactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		reactions []string
+		expectErr bool
+	}{
+		{"defaults", DefaultQuickReactions, false},
+		{"custom", []string{"🔥", "🎉"}, false},
+		{"empty", []string{}, true},
+		{"nil", nil, true},
+		{"too many", strings.Split("abcdefghijklmnopqrstuvwxyz"[:MaxQuickReactions+1], ""), true},
+		{"blank entry", []string{"👍", " "}, true},
+		{"duplicate", []string{"👍", "👍"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateQuickReactions(tt.reactions)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("ValidateQuickReactions(%v) error = %v, expectErr %v", tt.reactions, err, tt.expectErr)
+			}
+		})
+	}
+
+	maxSet := strings.Split("abcdefghijklmnopqrstuvwxyz"[:MaxQuickReactions], "")
+	if err := ValidateQuickReactions(maxSet); err != nil {
+		t.Errorf("Expected %d distinct reactions to be allowed, got %v", MaxQuickReactions, err)
+	}
+}
+
+func TestGetQuickReactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		reactions []string
+		expected  []string
+	}{
+		{"unset falls back", nil, DefaultQuickReactions},
+		{"invalid falls back", []string{"👍", ""}, DefaultQuickReactions},
+		{"customized", []string{"🔥", "🎉", "👀"}, []string{"🔥", "🎉", "👀"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			cfg.UI.QuickReactions = tt.reactions
+
+			got := cfg.GetQuickReactions()
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+
+			// Callers must not be able to modify the configured or default set
+			got[0] = "changed"
+			if cfg.GetQuickReactions()[0] == "changed" {
+				t.Error("Expected GetQuickReactions to return a copy")
+			}
+		})
+	}
+}
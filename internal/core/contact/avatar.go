@@ -0,0 +1,40 @@
+package contact
+
+// SetAvatar stores the avatar a friend sent; nil removes it
+func (m *Manager) SetAvatar(friendID uint32, avatar []byte) error {
+	var value interface{} // Stored as NULL, not an empty blob
+	if len(avatar) == 0 {
+		avatar = nil
+	} else {
+		value = avatar
+	}
+	if err := m.updateLocalDetail(friendID, "avatar", value, func(c *Contact) { c.Avatar = avatar }); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	callback := m.onAvatarChanged
+	m.mu.RUnlock()
+	if callback != nil {
+		callback(friendID)
+	}
+	return nil
+}
+
+// GetAvatar returns a friend's avatar, or nil if they have none
+func (m *Manager) GetAvatar(friendID uint32) []byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if c, ok := m.contacts[friendID]; ok {
+		return c.Avatar
+	}
+	return nil
+}
+
+// SetOnAvatarChanged sets the callback invoked when a friend's avatar changes
+func (m *Manager) SetOnAvatarChanged(callback func(friendID uint32)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onAvatarChanged = callback
+}
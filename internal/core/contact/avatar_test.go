@@ -0,0 +1,48 @@
+package contact
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetAvatar(t *testing.T) {
+	mgr, toxMgr := setupTestManager(t)
+	c, err := mgr.AddContact(testToxID(0x05), "hi")
+	if err != nil {
+		t.Fatalf("AddContact failed: %v", err)
+	}
+
+	var changed []uint32
+	mgr.SetOnAvatarChanged(func(friendID uint32) {
+		changed = append(changed, friendID)
+	})
+
+	avatar := []byte("\x89PNG avatar bytes")
+	if err := mgr.SetAvatar(c.FriendID, avatar); err != nil {
+		t.Fatalf("SetAvatar failed: %v", err)
+	}
+	if !bytes.Equal(mgr.GetAvatar(c.FriendID), avatar) {
+		t.Errorf("Expected the avatar in memory, got %q", mgr.GetAvatar(c.FriendID))
+	}
+
+	// The avatar survives a restart
+	reloaded := NewManager(mgr.db, toxMgr)
+	if got := reloaded.GetAvatar(c.FriendID); !bytes.Equal(got, avatar) {
+		t.Errorf("Expected the stored avatar, got %q", got)
+	}
+
+	if err := mgr.SetAvatar(c.FriendID, nil); err != nil {
+		t.Fatalf("SetAvatar failed: %v", err)
+	}
+	reloaded = NewManager(mgr.db, toxMgr)
+	if got := reloaded.GetAvatar(c.FriendID); got != nil {
+		t.Errorf("Expected the avatar removed, got %q", got)
+	}
+
+	if len(changed) != 2 || changed[0] != c.FriendID {
+		t.Errorf("Expected two avatar callbacks, got %v", changed)
+	}
+	if err := mgr.SetAvatar(999, avatar); err == nil {
+		t.Error("Expected an error for an unknown contact")
+	}
+}
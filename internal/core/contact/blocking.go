@@ -0,0 +1,21 @@
+package contact
+
+// Block stops messages, calls and file transfers from a contact reaching the
+// user. The friendship is kept so the contact can be unblocked later.
+func (m *Manager) Block(friendID uint32) error {
+	return m.updateLocalDetail(friendID, "is_blocked", true, func(c *Contact) { c.IsBlocked = true })
+}
+
+// Unblock lets a blocked contact reach the user again
+func (m *Manager) Unblock(friendID uint32) error {
+	return m.updateLocalDetail(friendID, "is_blocked", false, func(c *Contact) { c.IsBlocked = false })
+}
+
+// IsBlocked reports whether a contact is blocked. Unknown friends are not.
+func (m *Manager) IsBlocked(friendID uint32) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, exists := m.contacts[friendID]
+	return exists && c.IsBlocked
+}
@@ -0,0 +1,89 @@
+package contact
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+func TestBlockPersists(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "contacts.db")
+	db, err := storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	mgr := NewManager(db, newMockToxManager())
+	blocked, err := mgr.AddContact(testToxID(0x51), "hi")
+	if err != nil {
+		t.Fatalf("AddContact failed: %v", err)
+	}
+	unblocked, err := mgr.AddContact(testToxID(0x52), "hi")
+	if err != nil {
+		t.Fatalf("AddContact failed: %v", err)
+	}
+
+	if err := mgr.Block(blocked.FriendID); err != nil {
+		t.Fatalf("Block failed: %v", err)
+	}
+	if err := mgr.Block(unblocked.FriendID); err != nil {
+		t.Fatalf("Block failed: %v", err)
+	}
+	if err := mgr.Unblock(unblocked.FriendID); err != nil {
+		t.Fatalf("Unblock failed: %v", err)
+	}
+	if err := mgr.Block(99); err == nil {
+		t.Error("Expected error for unknown contact")
+	}
+	if mgr.IsBlocked(99) {
+		t.Error("Unknown friends should not be blocked")
+	}
+	db.Close()
+
+	db, err = storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	// Blocked contacts are still loaded, unlike deleted ones
+	mgr = NewManager(db, newMockToxManager())
+	if len(mgr.GetAllContacts()) != 2 {
+		t.Fatalf("Expected both contacts to be loaded, got %d", len(mgr.GetAllContacts()))
+	}
+	if !mgr.IsBlocked(blocked.FriendID) {
+		t.Error("Block not persisted")
+	}
+	if mgr.IsBlocked(unblocked.FriendID) {
+		t.Error("Unblock not persisted")
+	}
+}
+
+func TestDeleteContactIsNotBlock(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "contacts.db")
+	db, err := storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	mgr := NewManager(db, newMockToxManager())
+	c, err := mgr.AddContact(testToxID(0x53), "hi")
+	if err != nil {
+		t.Fatalf("AddContact failed: %v", err)
+	}
+	if err := mgr.DeleteContact(c.FriendID); err != nil {
+		t.Fatalf("DeleteContact failed: %v", err)
+	}
+	db.Close()
+
+	db, err = storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	if len(NewManager(db, newMockToxManager()).GetAllContacts()) != 0 {
+		t.Error("Deleted contact should not be loaded")
+	}
+}
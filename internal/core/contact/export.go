@@ -0,0 +1,357 @@
+package contact
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// Supported contact export formats
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+)
+
+// contactExportVersion is bumped when the exported layout changes
+const contactExportVersion = 1
+
+// importRequestMessage is sent with friend requests created by an import
+const importRequestMessage = "Hi, it's me. I moved my contacts to a new Whisp install."
+
+// csvHeader lists the exported CSV columns; notes is appended only when included
+var csvHeader = []string{"tox_id", "public_key", "name", "nickname", "favorite", "verified"}
+
+// ExportOptions controls what ExportContactsWithOptions writes
+type ExportOptions struct {
+	// IncludeNotes adds private notes to the export. Off by default because
+	// notes often hold personal details the user would not want in a file.
+	IncludeNotes bool
+}
+
+// ExportedContact is a single contact in an export file
+type ExportedContact struct {
+	ToxID     string `json:"tox_id"`
+	PublicKey string `json:"public_key"`
+	Name      string `json:"name,omitempty"`
+	Nickname  string `json:"nickname,omitempty"`
+	Notes     string `json:"notes,omitempty"`
+	Favorite  bool   `json:"favorite"`
+	Verified  bool   `json:"verified"`
+}
+
+// contactExport is the top-level JSON export document
+type contactExport struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Contacts   []ExportedContact `json:"contacts"`
+}
+
+// ImportResult summarizes a contact import
+type ImportResult struct {
+	Added   int // New contacts (friend requests sent)
+	Updated int // Existing contacts whose local details were updated
+	Skipped int // Entries without a usable Tox ID
+}
+
+// ExportContacts writes all contacts to w in the given format, without notes
+func (m *Manager) ExportContacts(w io.Writer, format string) error {
+	return m.ExportContactsWithOptions(w, format, ExportOptions{})
+}
+
+// ExportContactsWithOptions writes all contacts to w in the given format
+func (m *Manager) ExportContactsWithOptions(w io.Writer, format string, opts ExportOptions) error {
+	contacts := m.GetAllContacts()
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].FriendID < contacts[j].FriendID
+	})
+
+	m.mu.RLock()
+	records := make([]ExportedContact, 0, len(contacts))
+	for _, c := range contacts {
+		record := ExportedContact{
+			ToxID:     c.ToxID,
+			PublicKey: hex.EncodeToString(c.PublicKey),
+			Name:      c.Name,
+			Nickname:  c.Alias,
+			Favorite:  c.IsFavorite,
+			Verified:  c.IsVerified,
+		}
+		if opts.IncludeNotes {
+			record.Notes = c.Notes
+		}
+		records = append(records, record)
+	}
+	m.mu.RUnlock()
+
+	switch format {
+	case ExportFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(contactExport{
+			Version:    contactExportVersion,
+			ExportedAt: time.Now(),
+			Contacts:   records,
+		}); err != nil {
+			return fmt.Errorf("failed to write contacts: %w", err)
+		}
+		return nil
+	case ExportFormatCSV:
+		return writeContactsCSV(w, records, opts.IncludeNotes)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// writeContactsCSV writes records as CSV with a header row
+func writeContactsCSV(w io.Writer, records []ExportedContact, includeNotes bool) error {
+	writer := csv.NewWriter(w)
+
+	header := csvHeader
+	if includeNotes {
+		header = append(append([]string(nil), csvHeader...), "notes")
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write contacts: %w", err)
+	}
+
+	for _, record := range records {
+		row := []string{
+			record.ToxID, record.PublicKey, record.Name, record.Nickname,
+			strconv.FormatBool(record.Favorite), strconv.FormatBool(record.Verified),
+		}
+		if includeNotes {
+			row = append(row, record.Notes)
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write contacts: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write contacts: %w", err)
+	}
+	return nil
+}
+
+// ImportContacts reads contacts exported by ExportContacts. Unknown Tox IDs are
+// added with a friend request; contacts we already have get their local
+// nickname, notes and flags updated.
+func (m *Manager) ImportContacts(r io.Reader, format string) (*ImportResult, error) {
+	var records []ExportedContact
+
+	switch format {
+	case ExportFormatJSON:
+		var doc contactExport
+		if err := json.NewDecoder(r).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to parse contacts: %w", err)
+		}
+		if doc.Version > contactExportVersion {
+			return nil, fmt.Errorf("unsupported contact export version: %d", doc.Version)
+		}
+		records = doc.Contacts
+	case ExportFormatCSV:
+		parsed, err := readContactsCSV(r)
+		if err != nil {
+			return nil, err
+		}
+		records = parsed
+	default:
+		return nil, fmt.Errorf("unsupported import format: %s", format)
+	}
+
+	result := &ImportResult{}
+	for _, record := range records {
+		existing := m.findContact(record)
+		if existing == nil {
+			if record.ToxID == "" {
+				// Contacts accepted from requests only have a public key, which is not enough to add them
+				result.Skipped++
+				continue
+			}
+
+			added, err := m.AddContact(record.ToxID, importRequestMessage)
+			if err != nil {
+				logging.Warnf("Failed to import contact %s: %v", record.ToxID, err)
+				result.Skipped++
+				continue
+			}
+			existing = added
+			result.Added++
+		} else {
+			result.Updated++
+		}
+
+		if err := m.applyImportedDetails(existing, record); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// readContactsCSV parses a CSV export, locating columns by header name
+func readContactsCSV(r io.Reader) ([]ExportedContact, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse contacts: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["tox_id"]; !ok {
+		return nil, fmt.Errorf("failed to parse contacts: missing tox_id column")
+	}
+
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	records := make([]ExportedContact, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		favorite, _ := strconv.ParseBool(field(row, "favorite"))
+		verified, _ := strconv.ParseBool(field(row, "verified"))
+		records = append(records, ExportedContact{
+			ToxID:     field(row, "tox_id"),
+			PublicKey: field(row, "public_key"),
+			Name:      field(row, "name"),
+			Nickname:  field(row, "nickname"),
+			Notes:     field(row, "notes"),
+			Favorite:  favorite,
+			Verified:  verified,
+		})
+	}
+
+	return records, nil
+}
+
+// findContact returns the existing contact matching an imported record
+func (m *Manager) findContact(record ExportedContact) *Contact {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, c := range m.contacts {
+		if record.ToxID != "" && strings.EqualFold(c.ToxID, record.ToxID) {
+			return c
+		}
+		if record.PublicKey != "" && strings.EqualFold(hex.EncodeToString(c.PublicKey), record.PublicKey) {
+			return c
+		}
+	}
+	return nil
+}
+
+// applyImportedDetails copies local-only fields from an imported record.
+// Notes are only overwritten when the export included them.
+func (m *Manager) applyImportedDetails(c *Contact, record ExportedContact) error {
+	m.mu.Lock()
+	c.Alias = record.Nickname
+	if record.Notes != "" {
+		c.Notes = record.Notes
+	}
+	c.IsFavorite = record.Favorite
+	c.IsVerified = record.Verified
+	c.UpdatedAt = time.Now()
+	alias, notes, favorite, verified, updatedAt := c.Alias, c.Notes, c.IsFavorite, c.IsVerified, c.UpdatedAt
+	m.mu.Unlock()
+
+	query := `UPDATE contacts SET local_alias = ?, notes = ?, is_favorite = ?, is_verified = ?, updated_at = ? WHERE friend_id = ?`
+	if _, err := m.db.Exec(query, alias, notes, favorite, verified, updatedAt, c.FriendID); err != nil {
+		return fmt.Errorf("failed to update imported contact: %w", err)
+	}
+	return nil
+}
+
+// SetAlias sets the local nickname shown instead of the contact's own name.
+// An empty alias goes back to the contact's own name.
+func (m *Manager) SetAlias(friendID uint32, alias string) error {
+	alias = strings.TrimSpace(alias)
+	return m.updateLocalDetail(friendID, "local_alias", alias, func(c *Contact) { c.Alias = alias })
+}
+
+// SetNotes sets the private notes kept about a contact
+func (m *Manager) SetNotes(friendID uint32, notes string) error {
+	return m.updateLocalDetail(friendID, "notes", notes, func(c *Contact) { c.Notes = notes })
+}
+
+// SetVerified records whether the contact's identity was confirmed out-of-band
+func (m *Manager) SetVerified(friendID uint32, verified bool) error {
+	return m.updateLocalDetail(friendID, "is_verified", verified, func(c *Contact) { c.IsVerified = verified })
+}
+
+// SetAutoAcceptFiles sets whether files from the contact are accepted without asking
+func (m *Manager) SetAutoAcceptFiles(friendID uint32, autoAccept bool) error {
+	return m.updateLocalDetail(friendID, "auto_accept_files", autoAccept, func(c *Contact) { c.AutoAcceptFiles = autoAccept })
+}
+
+// SetNotificationOverride sets how notifications from the contact are shown
+func (m *Manager) SetNotificationOverride(friendID uint32, override NotificationOverride) error {
+	override.Sound = strings.TrimSpace(override.Sound)
+
+	m.mu.Lock()
+	c, exists := m.contacts[friendID]
+	if !exists {
+		m.mu.Unlock()
+		return fmt.Errorf("contact %d not found", friendID)
+	}
+	c.Notifications = override
+	c.UpdatedAt = time.Now()
+	updatedAt := c.UpdatedAt
+	m.mu.Unlock()
+
+	query := `UPDATE contacts SET notify_muted = ?, notify_sound = ?, notify_always_preview = ?,
+		notify_override_quiet = ?, updated_at = ? WHERE friend_id = ?`
+	if _, err := m.db.Exec(query, override.Muted, override.Sound, override.AlwaysShowPreview,
+		override.OverrideQuietHours, updatedAt, friendID); err != nil {
+		return fmt.Errorf("failed to update contact: %w", err)
+	}
+	return nil
+}
+
+// GetNotificationOverride returns how notifications from a contact are shown
+func (m *Manager) GetNotificationOverride(friendID uint32) (NotificationOverride, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, exists := m.contacts[friendID]
+	if !exists {
+		return NotificationOverride{}, false
+	}
+	return c.Notifications, true
+}
+
+// updateLocalDetail updates one local-only contact column in memory and in the database
+func (m *Manager) updateLocalDetail(friendID uint32, column string, value interface{}, apply func(*Contact)) error {
+	m.mu.Lock()
+	c, exists := m.contacts[friendID]
+	if !exists {
+		m.mu.Unlock()
+		return fmt.Errorf("contact %d not found", friendID)
+	}
+	apply(c)
+	c.UpdatedAt = time.Now()
+	updatedAt := c.UpdatedAt
+	m.mu.Unlock()
+
+	query := fmt.Sprintf(`UPDATE contacts SET %s = ?, updated_at = ? WHERE friend_id = ?`, column)
+	if _, err := m.db.Exec(query, value, updatedAt, friendID); err != nil {
+		return fmt.Errorf("failed to update contact: %w", err)
+	}
+	return nil
+}
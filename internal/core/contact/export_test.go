@@ -0,0 +1,334 @@
+package contact
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+// mockToxManager hands out sequential friend IDs and derives public keys from Tox IDs
+type mockToxManager struct {
+	nextID   uint32
+	keys     map[uint32][32]byte
+	requests []string
+}
+
+func newMockToxManager() *mockToxManager {
+	return &mockToxManager{keys: make(map[uint32][32]byte)}
+}
+
+func (m *mockToxManager) GetFriends() []uint32 { return nil }
+
+func (m *mockToxManager) GetFriendPublicKey(friendID uint32) ([32]byte, error) {
+	key, ok := m.keys[friendID]
+	if !ok {
+		return [32]byte{}, fmt.Errorf("friend %d not found", friendID)
+	}
+	return key, nil
+}
+
+func (m *mockToxManager) AddFriend(toxID, message string) (uint32, error) {
+	raw, err := hex.DecodeString(toxID)
+	if err != nil || len(raw) < 32 {
+		return 0, fmt.Errorf("invalid Tox ID")
+	}
+
+	var key [32]byte
+	copy(key[:], raw[:32])
+
+	m.nextID++
+	m.keys[m.nextID] = key
+	m.requests = append(m.requests, toxID)
+	return m.nextID, nil
+}
+
+func (m *mockToxManager) AcceptFriendRequest(publicKey [32]byte) (uint32, error) {
+	m.nextID++
+	m.keys[m.nextID] = publicKey
+	return m.nextID, nil
+}
+
+func (m *mockToxManager) DeleteFriend(friendID uint32) error { return nil }
+
+// testToxID builds a syntactically valid 76-character Tox ID
+func testToxID(seed byte) string {
+	return strings.ToUpper(hex.EncodeToString(bytes.Repeat([]byte{seed}, 38)))
+}
+
+func setupTestManager(t *testing.T) (*Manager, *mockToxManager) {
+	t.Helper()
+
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "contacts.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	toxMgr := newMockToxManager()
+	return NewManager(db, toxMgr), toxMgr
+}
+
+// seedContacts adds three contacts with a mix of local details
+func seedContacts(t *testing.T, mgr *Manager) {
+	t.Helper()
+
+	details := []ExportedContact{
+		{ToxID: testToxID(0x11), Nickname: "Mom", Notes: "birthday in May", Favorite: true, Verified: true},
+		{ToxID: testToxID(0x22), Nickname: "Work Alex", Notes: "call before 6, \"urgent\" only"},
+		{ToxID: testToxID(0x33)},
+	}
+
+	for _, d := range details {
+		c, err := mgr.AddContact(d.ToxID, "hi")
+		if err != nil {
+			t.Fatalf("AddContact failed: %v", err)
+		}
+		if err := mgr.applyImportedDetails(c, d); err != nil {
+			t.Fatalf("Failed to set contact details: %v", err)
+		}
+	}
+}
+
+func TestExportContactsJSON(t *testing.T) {
+	mgr, _ := setupTestManager(t)
+	seedContacts(t, mgr)
+
+	for _, includeNotes := range []bool{false, true} {
+		t.Run(fmt.Sprintf("notes=%v", includeNotes), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := mgr.ExportContactsWithOptions(&buf, ExportFormatJSON, ExportOptions{IncludeNotes: includeNotes}); err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+
+			var doc contactExport
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatalf("Export is not valid JSON: %v", err)
+			}
+			if len(doc.Contacts) != 3 {
+				t.Fatalf("Expected 3 contacts, got %d", len(doc.Contacts))
+			}
+
+			first := doc.Contacts[0]
+			if first.ToxID != testToxID(0x11) || first.Nickname != "Mom" || !first.Favorite || !first.Verified {
+				t.Errorf("Unexpected first contact: %+v", first)
+			}
+			if hasNotes := first.Notes != ""; hasNotes != includeNotes {
+				t.Errorf("Expected notes present=%v, got %q", includeNotes, first.Notes)
+			}
+			if !includeNotes && strings.Contains(buf.String(), "birthday") {
+				t.Error("Notes leaked into export without the flag")
+			}
+		})
+	}
+
+	// The plain export never includes notes
+	var buf bytes.Buffer
+	if err := mgr.ExportContacts(&buf, ExportFormatJSON); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "birthday") {
+		t.Error("ExportContacts included notes")
+	}
+}
+
+func TestExportContactsCSV(t *testing.T) {
+	mgr, _ := setupTestManager(t)
+	seedContacts(t, mgr)
+
+	tests := []struct {
+		name         string
+		includeNotes bool
+		columns      int
+	}{
+		{"without notes", false, len(csvHeader)},
+		{"with notes", true, len(csvHeader) + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := mgr.ExportContactsWithOptions(&buf, ExportFormatCSV, ExportOptions{IncludeNotes: tt.includeNotes}); err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+
+			rows, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatalf("Export is not valid CSV: %v", err)
+			}
+			if len(rows) != 4 {
+				t.Fatalf("Expected header and 3 rows, got %d rows", len(rows))
+			}
+			if len(rows[0]) != tt.columns {
+				t.Errorf("Expected %d columns, got %v", tt.columns, rows[0])
+			}
+			if rows[2][3] != "Work Alex" {
+				t.Errorf("Expected nickname column, got %v", rows[2])
+			}
+			if tt.includeNotes && rows[2][6] != `call before 6, "urgent" only` {
+				t.Errorf("Expected notes to survive CSV quoting, got %q", rows[2][6])
+			}
+		})
+	}
+}
+
+func TestExportContactsUnknownFormat(t *testing.T) {
+	mgr, _ := setupTestManager(t)
+
+	if err := mgr.ExportContacts(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+func TestImportContactsRoundTrip(t *testing.T) {
+	source, _ := setupTestManager(t)
+	seedContacts(t, source)
+
+	for _, format := range []string{ExportFormatJSON, ExportFormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := source.ExportContactsWithOptions(&buf, format, ExportOptions{IncludeNotes: true}); err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+
+			target, toxMgr := setupTestManager(t)
+			result, err := target.ImportContacts(&buf, format)
+			if err != nil {
+				t.Fatalf("Import failed: %v", err)
+			}
+			if result.Added != 3 || result.Updated != 0 || result.Skipped != 0 {
+				t.Errorf("Unexpected import result: %+v", result)
+			}
+			if len(toxMgr.requests) != 3 {
+				t.Errorf("Expected 3 friend requests, got %d", len(toxMgr.requests))
+			}
+
+			var mom *Contact
+			for _, c := range target.GetAllContacts() {
+				if c.ToxID == testToxID(0x11) {
+					mom = c
+				}
+			}
+			if mom == nil {
+				t.Fatal("Imported contact not found")
+			}
+			if mom.Alias != "Mom" || mom.Notes != "birthday in May" || !mom.IsFavorite || !mom.IsVerified {
+				t.Errorf("Local details not imported: %+v", mom)
+			}
+
+			// Importing again only refreshes the existing contacts
+			var again bytes.Buffer
+			if err := source.ExportContacts(&again, format); err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			result, err = target.ImportContacts(&again, format)
+			if err != nil {
+				t.Fatalf("Re-import failed: %v", err)
+			}
+			if result.Added != 0 || result.Updated != 3 {
+				t.Errorf("Expected re-import to update 3 contacts, got %+v", result)
+			}
+			if mom.Notes != "birthday in May" {
+				t.Errorf("Import without notes should keep existing notes, got %q", mom.Notes)
+			}
+		})
+	}
+}
+
+func TestLocalDetailsPersist(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "contacts.db")
+	db, err := storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	mgr := NewManager(db, newMockToxManager())
+	c, err := mgr.AddContact(testToxID(0x44), "hi")
+	if err != nil {
+		t.Fatalf("AddContact failed: %v", err)
+	}
+	if err := mgr.SetAlias(c.FriendID, "  Sam from work "); err != nil {
+		t.Fatalf("SetAlias failed: %v", err)
+	}
+	if err := mgr.SetNotes(c.FriendID, "met at the conference"); err != nil {
+		t.Fatalf("SetNotes failed: %v", err)
+	}
+	if err := mgr.SetVerified(c.FriendID, true); err != nil {
+		t.Fatalf("SetVerified failed: %v", err)
+	}
+	if err := mgr.SetNotes(99, "nobody"); err == nil {
+		t.Error("Expected error for unknown contact")
+	}
+	db.Close()
+
+	db, err = storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	reloaded, ok := NewManager(db, newMockToxManager()).GetContact(c.FriendID)
+	if !ok {
+		t.Fatal("Contact not reloaded")
+	}
+	got := reloaded.(*Contact)
+	if got.Notes != "met at the conference" || !got.IsVerified || got.Alias != "Sam from work" {
+		t.Errorf("Local details not persisted: %+v", got)
+	}
+
+	// The alias wins over the peer's name and clearing it restores the name
+	mgr = NewManager(db, newMockToxManager())
+	mgr.UpdateName(c.FriendID, "Samantha")
+	if got := mgr.contacts[c.FriendID]; got.DisplayName() != "Sam from work" || got.Name != "Samantha" {
+		t.Errorf("Expected alias to be shown over the name, got %q (name %q)", got.DisplayName(), got.Name)
+	}
+	if err := mgr.SetAlias(c.FriendID, ""); err != nil {
+		t.Fatalf("SetAlias failed: %v", err)
+	}
+	if got := mgr.contacts[c.FriendID].DisplayName(); got != "Samantha" {
+		t.Errorf("Expected the name once the alias is cleared, got %q", got)
+	}
+}
+
+func TestNotificationOverridePersists(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "contacts.db")
+	db, err := storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	mgr := NewManager(db, newMockToxManager())
+	c, err := mgr.AddContact(testToxID(0x44), "hi")
+	if err != nil {
+		t.Fatalf("AddContact failed: %v", err)
+	}
+	if override, ok := mgr.GetNotificationOverride(c.FriendID); !ok || override != (NotificationOverride{}) {
+		t.Errorf("Expected new contacts to follow the global settings, got %+v", override)
+	}
+
+	want := NotificationOverride{Muted: true, Sound: "chime.wav", OverrideQuietHours: true}
+	if err := mgr.SetNotificationOverride(c.FriendID, NotificationOverride{Muted: true, Sound: " chime.wav ", OverrideQuietHours: true}); err != nil {
+		t.Fatalf("SetNotificationOverride failed: %v", err)
+	}
+	if err := mgr.SetNotificationOverride(99, want); err == nil {
+		t.Error("Expected error for unknown contact")
+	}
+	db.Close()
+
+	db, err = storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	got, ok := NewManager(db, newMockToxManager()).GetNotificationOverride(c.FriendID)
+	if !ok || got != want {
+		t.Errorf("Notification override not persisted: got %+v, want %+v", got, want)
+	}
+}
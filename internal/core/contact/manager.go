@@ -3,11 +3,11 @@ package contact
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
 	"github.com/opd-ai/toxcore"
+	"github.com/opd-ai/whisp/internal/logging"
 	"github.com/opd-ai/whisp/internal/storage"
 )
 
@@ -23,19 +23,44 @@ const (
 
 // Contact represents a contact/friend
 type Contact struct {
-	ID            int64     `json:"id"`
-	ToxID         string    `json:"tox_id"`
-	PublicKey     []byte    `json:"public_key"`
-	FriendID      uint32    `json:"friend_id"`
-	Name          string    `json:"name"`
-	StatusMessage string    `json:"status_message"`
-	Avatar        []byte    `json:"avatar,omitempty"`
-	Status        Status    `json:"status"`
-	IsBlocked     bool      `json:"is_blocked"`
-	IsFavorite    bool      `json:"is_favorite"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	LastSeenAt    time.Time `json:"last_seen_at"`
+	ID              int64     `json:"id"`
+	ToxID           string    `json:"tox_id"`
+	PublicKey       []byte    `json:"public_key"`
+	FriendID        uint32    `json:"friend_id"`
+	Name            string    `json:"name"`
+	StatusMessage   string    `json:"status_message"`
+	Avatar          []byte    `json:"avatar,omitempty"`
+	Status          Status    `json:"status"`
+	IsBlocked       bool      `json:"is_blocked"`
+	IsFavorite      bool      `json:"is_favorite"`
+	Alias           string    `json:"alias,omitempty"`   // Local nickname, never sent to the peer
+	Notes           string    `json:"notes,omitempty"`   // Private notes about the contact
+	IsVerified      bool      `json:"is_verified"`       // Identity confirmed out-of-band
+	AutoAcceptFiles bool      `json:"auto_accept_files"` // Files from this contact are accepted without asking
+	FromRequest     bool      `json:"from_request"`      // Added by accepting their friend request
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	LastSeenAt      time.Time `json:"last_seen_at"`
+
+	// Notifications overrides the global notification settings for this contact
+	Notifications NotificationOverride `json:"notifications"`
+}
+
+// NotificationOverride changes how notifications from one contact are shown.
+// The zero value follows the global settings.
+type NotificationOverride struct {
+	Muted              bool   `json:"muted"`                // Never notify
+	Sound              string `json:"sound,omitempty"`      // Sound file to play instead of the default
+	AlwaysShowPreview  bool   `json:"always_show_preview"`  // Show the message even with previews off
+	OverrideQuietHours bool   `json:"override_quiet_hours"` // Notify during quiet hours
+}
+
+// DisplayName returns the local alias if set, otherwise the contact's own name
+func (c *Contact) DisplayName() string {
+	if c.Alias != "" {
+		return c.Alias
+	}
+	return c.Name
 }
 
 // Manager manages contacts and friend relationships
@@ -46,6 +71,10 @@ type Manager struct {
 	mu       sync.RWMutex
 	contacts map[uint32]*Contact // friendID -> Contact
 	pending  []PendingRequest
+
+	onRequestsChanged func()                               // Called when a request arrives or is resolved
+	onPresenceChanged func(friendID uint32, status Status) // Called when a status or status message changes
+	onAvatarChanged   func(friendID uint32)                // Called when a friend's avatar changes
 }
 
 // ToxManager interface for Tox operations
@@ -75,7 +104,10 @@ func NewManager(db *storage.Database, toxMgr ToxManager) *Manager {
 
 	// Load existing contacts
 	if err := m.loadContacts(); err != nil {
-		log.Printf("Warning: Failed to load contacts: %v", err)
+		logging.Warnf("Failed to load contacts: %v", err)
+	}
+	if err := m.loadPendingRequests(); err != nil {
+		logging.Warnf("Failed to load friend requests: %v", err)
 	}
 
 	return m
@@ -85,8 +117,10 @@ func NewManager(db *storage.Database, toxMgr ToxManager) *Manager {
 func (m *Manager) loadContacts() error {
 	query := `
 		SELECT id, tox_id, public_key, friend_id, name, status_message, 
-		       avatar, status, is_blocked, is_favorite, created_at, updated_at, last_seen_at
-		FROM contacts WHERE is_blocked = 0
+		       avatar, status, is_blocked, is_favorite, local_alias, notes, is_verified,
+		       auto_accept_files, from_request, notify_muted, notify_sound, notify_always_preview,
+		       notify_override_quiet, created_at, updated_at, last_seen_at
+		FROM contacts WHERE is_deleted = 0
 	`
 
 	rows, err := m.db.Query(query)
@@ -102,8 +136,11 @@ func (m *Manager) loadContacts() error {
 		err := rows.Scan(
 			&contact.ID, &contact.ToxID, &contact.PublicKey, &contact.FriendID,
 			&contact.Name, &contact.StatusMessage, &avatar, &contact.Status,
-			&contact.IsBlocked, &contact.IsFavorite, &contact.CreatedAt,
-			&contact.UpdatedAt, &contact.LastSeenAt,
+			&contact.IsBlocked, &contact.IsFavorite, &contact.Alias,
+			&contact.Notes, &contact.IsVerified, &contact.AutoAcceptFiles, &contact.FromRequest,
+			&contact.Notifications.Muted, &contact.Notifications.Sound,
+			&contact.Notifications.AlwaysShowPreview, &contact.Notifications.OverrideQuietHours,
+			&contact.CreatedAt, &contact.UpdatedAt, &contact.LastSeenAt,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to scan contact: %w", err)
@@ -112,6 +149,8 @@ func (m *Manager) loadContacts() error {
 		if avatar.Valid {
 			contact.Avatar = []byte(avatar.String)
 		}
+		// The stored status is from the last run; friends report in once connected
+		contact.Status = StatusOffline
 
 		m.contacts[contact.FriendID] = contact
 	}
@@ -131,6 +170,20 @@ func (m *Manager) GetAllContacts() []*Contact {
 	return contacts
 }
 
+// CountOnline returns how many contacts are online
+func (m *Manager) CountOnline() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	online := 0
+	for _, contact := range m.contacts {
+		if contact.Status != StatusOffline {
+			online++
+		}
+	}
+	return online
+}
+
 // GetContact returns a contact by friend ID
 func (m *Manager) GetContact(friendID uint32) (interface{}, bool) {
 	m.mu.RLock()
@@ -140,6 +193,16 @@ func (m *Manager) GetContact(friendID uint32) (interface{}, bool) {
 	return contact, exists
 }
 
+// IsEstablished reports whether a contact is trusted without review: one we
+// added by Tox ID, or one whose friend request we accepted and then verified
+func (m *Manager) IsEstablished(friendID uint32) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, exists := m.contacts[friendID]
+	return exists && (!c.FromRequest || c.IsVerified)
+}
+
 // AddContact adds a new contact
 func (m *Manager) AddContact(toxID, message string) (*Contact, error) {
 	// Add friend via Tox
@@ -188,12 +251,13 @@ func (m *Manager) AcceptFriendRequest(publicKey [32]byte) (*Contact, error) {
 
 	// Create contact
 	contact := &Contact{
-		PublicKey: publicKey[:],
-		FriendID:  friendID,
-		Name:      "Unknown",
-		Status:    StatusOffline,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		PublicKey:   publicKey[:],
+		FriendID:    friendID,
+		Name:        "Unknown",
+		Status:      StatusOffline,
+		FromRequest: true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 
 	// Save to database
@@ -204,10 +268,13 @@ func (m *Manager) AcceptFriendRequest(publicKey [32]byte) (*Contact, error) {
 	// Add to memory
 	m.mu.Lock()
 	m.contacts[friendID] = contact
-	// Remove from pending
-	m.removePendingRequest(publicKey)
 	m.mu.Unlock()
 
+	// Remove from pending
+	if err := m.removePendingRequest(publicKey); err != nil {
+		logging.Warnf("Failed to remove accepted friend request: %v", err)
+	}
+
 	return contact, nil
 }
 
@@ -219,7 +286,7 @@ func (m *Manager) DeleteContact(friendID uint32) error {
 	}
 
 	// Mark as deleted in database (soft delete)
-	query := `UPDATE contacts SET is_blocked = 1, updated_at = ? WHERE friend_id = ?`
+	query := `UPDATE contacts SET is_deleted = 1, updated_at = ? WHERE friend_id = ?`
 	if _, err := m.db.Exec(query, time.Now(), friendID); err != nil {
 		return fmt.Errorf("failed to delete contact from database: %w", err)
 	}
@@ -246,104 +313,74 @@ func (m *Manager) UpdateName(friendID uint32, name string) {
 	contact.UpdatedAt = time.Now()
 
 	// Update database
-	go func() {
+	m.db.Async(func() {
 		query := `UPDATE contacts SET name = ?, updated_at = ? WHERE friend_id = ?`
 		if _, err := m.db.Exec(query, name, contact.UpdatedAt, friendID); err != nil {
-			log.Printf("Failed to update contact name: %v", err)
+			logging.Errorf("Failed to update contact name: %v", err)
 		}
-	}()
+	})
 }
 
 // UpdateStatusMessage updates a contact's status message
 func (m *Manager) UpdateStatusMessage(friendID uint32, statusMessage string) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	contact, exists := m.contacts[friendID]
 	if !exists {
+		m.mu.Unlock()
 		return
 	}
 
 	contact.StatusMessage = statusMessage
 	contact.UpdatedAt = time.Now()
+	updatedAt := contact.UpdatedAt
+	status := contact.Status
+	callback := m.onPresenceChanged
+	m.mu.Unlock()
 
 	// Update database
-	go func() {
+	m.db.Async(func() {
 		query := `UPDATE contacts SET status_message = ?, updated_at = ? WHERE friend_id = ?`
-		if _, err := m.db.Exec(query, statusMessage, contact.UpdatedAt, friendID); err != nil {
-			log.Printf("Failed to update contact status message: %v", err)
+		if _, err := m.db.Exec(query, statusMessage, updatedAt, friendID); err != nil {
+			logging.Errorf("Failed to update contact status message: %v", err)
 		}
-	}()
+	})
+
+	if callback != nil {
+		callback(friendID, status)
+	}
 }
 
-// UpdateStatus updates a contact's status
+// UpdateStatus updates a contact's status. Going online or offline records
+// when the friend was last seen.
 func (m *Manager) UpdateStatus(friendID uint32, status toxcore.FriendStatus) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	contact, exists := m.contacts[friendID]
 	if !exists {
+		m.mu.Unlock()
 		return
 	}
 
-	// Convert Tox status to our status
-	var newStatus Status
-	switch status {
-	case toxcore.FriendStatusNone:
-		newStatus = StatusOnline
-	case toxcore.FriendStatusAway:
-		newStatus = StatusAway
-	case toxcore.FriendStatusBusy:
-		newStatus = StatusBusy
-	default:
-		newStatus = StatusOffline
+	newStatus := StatusFromTox(status)
+	now := time.Now()
+	if newStatus != StatusOffline || contact.Status != StatusOffline {
+		contact.LastSeenAt = now
 	}
-
 	contact.Status = newStatus
-	contact.UpdatedAt = time.Now()
-	if newStatus != StatusOffline {
-		contact.LastSeenAt = time.Now()
-	}
+	contact.UpdatedAt = now
+	lastSeen := contact.LastSeenAt
+	callback := m.onPresenceChanged
+	m.mu.Unlock()
 
 	// Update database
-	go func() {
+	m.db.Async(func() {
 		query := `UPDATE contacts SET status = ?, updated_at = ?, last_seen_at = ? WHERE friend_id = ?`
-		if _, err := m.db.Exec(query, newStatus, contact.UpdatedAt, contact.LastSeenAt, friendID); err != nil {
-			log.Printf("Failed to update contact status: %v", err)
+		if _, err := m.db.Exec(query, newStatus, now, lastSeen, friendID); err != nil {
+			logging.Errorf("Failed to update contact status: %v", err)
 		}
-	}()
-}
+	})
 
-// HandleFriendRequest handles an incoming friend request
-func (m *Manager) HandleFriendRequest(publicKey [32]byte, message string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	// Add to pending requests
-	request := PendingRequest{
-		PublicKey: publicKey,
-		Message:   message,
-		Timestamp: time.Now(),
-	}
-
-	m.pending = append(m.pending, request)
-}
-
-// GetPendingRequests returns pending friend requests
-func (m *Manager) GetPendingRequests() []PendingRequest {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	return append([]PendingRequest(nil), m.pending...)
-}
-
-// removePendingRequest removes a pending request
-func (m *Manager) removePendingRequest(publicKey [32]byte) {
-	for i, req := range m.pending {
-		if req.PublicKey == publicKey {
-			m.pending = append(m.pending[:i], m.pending[i+1:]...)
-			break
-		}
+	if callback != nil {
+		callback(friendID, newStatus)
 	}
 }
 
@@ -351,14 +388,19 @@ func (m *Manager) removePendingRequest(publicKey [32]byte) {
 func (m *Manager) saveContact(contact *Contact) error {
 	query := `
 		INSERT INTO contacts (tox_id, public_key, friend_id, name, status_message, 
-		                     avatar, status, is_blocked, is_favorite, created_at, updated_at, last_seen_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		                     avatar, status, is_blocked, is_favorite, local_alias, notes, is_verified,
+		                     auto_accept_files, from_request, notify_muted, notify_sound, notify_always_preview,
+		                     notify_override_quiet, created_at, updated_at, last_seen_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := m.db.Exec(query,
 		contact.ToxID, contact.PublicKey, contact.FriendID, contact.Name,
 		contact.StatusMessage, contact.Avatar, contact.Status, contact.IsBlocked,
-		contact.IsFavorite, contact.CreatedAt, contact.UpdatedAt, contact.LastSeenAt,
+		contact.IsFavorite, contact.Alias, contact.Notes, contact.IsVerified,
+		contact.AutoAcceptFiles, contact.FromRequest, contact.Notifications.Muted, contact.Notifications.Sound,
+		contact.Notifications.AlwaysShowPreview, contact.Notifications.OverrideQuietHours,
+		contact.CreatedAt, contact.UpdatedAt, contact.LastSeenAt,
 	)
 	if err != nil {
 		return err
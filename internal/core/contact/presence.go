@@ -0,0 +1,55 @@
+package contact
+
+import (
+	"github.com/opd-ai/toxcore"
+)
+
+// StatusFromTox converts a Tox user status to a contact status
+func StatusFromTox(status toxcore.FriendStatus) Status {
+	switch status {
+	case toxcore.FriendStatusOnline:
+		return StatusOnline
+	case toxcore.FriendStatusAway:
+		return StatusAway
+	case toxcore.FriendStatusBusy:
+		return StatusBusy
+	default:
+		return StatusOffline
+	}
+}
+
+// ToxStatus converts a contact status to the Tox user status
+func (s Status) ToxStatus() toxcore.FriendStatus {
+	switch s {
+	case StatusOnline:
+		return toxcore.FriendStatusOnline
+	case StatusAway:
+		return toxcore.FriendStatusAway
+	case StatusBusy:
+		return toxcore.FriendStatusBusy
+	default:
+		return toxcore.FriendStatusNone
+	}
+}
+
+// String returns the status as shown to the user
+func (s Status) String() string {
+	switch s {
+	case StatusOnline:
+		return "Online"
+	case StatusAway:
+		return "Away"
+	case StatusBusy:
+		return "Busy"
+	default:
+		return "Offline"
+	}
+}
+
+// SetOnPresenceChanged sets the callback invoked when a contact's status or
+// status message changes
+func (m *Manager) SetOnPresenceChanged(callback func(friendID uint32, status Status)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onPresenceChanged = callback
+}
@@ -0,0 +1,80 @@
+package contact
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+)
+
+func TestUpdateStatus(t *testing.T) {
+	mgr, _ := setupTestManager(t)
+	c, err := mgr.AddContact(testToxID(0x01), "hi")
+	if err != nil {
+		t.Fatalf("AddContact failed: %v", err)
+	}
+
+	var changes []Status
+	mgr.SetOnPresenceChanged(func(friendID uint32, status Status) {
+		if friendID != c.FriendID {
+			t.Errorf("Callback for friend %d, want %d", friendID, c.FriendID)
+		}
+		changes = append(changes, status)
+	})
+
+	tests := []struct {
+		status toxcore.FriendStatus
+		want   Status
+	}{
+		{toxcore.FriendStatusOnline, StatusOnline},
+		{toxcore.FriendStatusAway, StatusAway},
+		{toxcore.FriendStatusBusy, StatusBusy},
+		{toxcore.FriendStatusNone, StatusOffline},
+	}
+	for _, tt := range tests {
+		mgr.UpdateStatus(c.FriendID, tt.status)
+		if c.Status != tt.want {
+			t.Errorf("UpdateStatus(%v) set %v, want %v", tt.status, c.Status, tt.want)
+		}
+	}
+	if len(changes) != len(tests) {
+		t.Errorf("Got %d presence callbacks, want %d", len(changes), len(tests))
+	}
+}
+
+func TestUpdateStatusLastSeen(t *testing.T) {
+	mgr, _ := setupTestManager(t)
+	c, err := mgr.AddContact(testToxID(0x02), "hi")
+	if err != nil {
+		t.Fatalf("AddContact failed: %v", err)
+	}
+
+	mgr.UpdateStatus(c.FriendID, toxcore.FriendStatusOnline)
+	online := c.LastSeenAt
+	if online.IsZero() {
+		t.Fatal("Expected last seen to be set when the friend comes online")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	mgr.UpdateStatus(c.FriendID, toxcore.FriendStatusNone)
+	offline := c.LastSeenAt
+	if !offline.After(online) {
+		t.Error("Expected last seen to move forward when the friend goes offline")
+	}
+
+	mgr.UpdateStatus(c.FriendID, toxcore.FriendStatusNone)
+	if !c.LastSeenAt.Equal(offline) {
+		t.Error("Expected last seen to stay put while the friend is offline")
+	}
+
+	if err := mgr.db.WaitAsync(time.Second); err != nil {
+		t.Fatalf("WaitAsync failed: %v", err)
+	}
+	var stored time.Time
+	if err := mgr.db.QueryRow(`SELECT last_seen_at FROM contacts WHERE friend_id = ?`, c.FriendID).Scan(&stored); err != nil {
+		t.Fatalf("Failed to read last seen: %v", err)
+	}
+	if !stored.Equal(offline) {
+		t.Errorf("Stored last seen %v, want %v", stored, offline)
+	}
+}
@@ -0,0 +1,128 @@
+package contact
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// loadPendingRequests loads the friend requests waiting for an answer
+func (m *Manager) loadPendingRequests() error {
+	rows, err := m.db.Query(`SELECT public_key, message, received_at FROM friend_requests ORDER BY received_at`)
+	if err != nil {
+		return fmt.Errorf("failed to query friend requests: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []PendingRequest
+	for rows.Next() {
+		var keyHex string
+		var request PendingRequest
+		if err := rows.Scan(&keyHex, &request.Message, &request.Timestamp); err != nil {
+			return fmt.Errorf("failed to scan friend request: %w", err)
+		}
+
+		key, err := hex.DecodeString(keyHex)
+		if err != nil || len(key) != len(request.PublicKey) {
+			logging.Warnf("Skipping friend request with invalid public key %q", keyHex)
+			continue
+		}
+		copy(request.PublicKey[:], key)
+		pending = append(pending, request)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read friend requests: %w", err)
+	}
+
+	m.mu.Lock()
+	m.pending = append(m.pending, pending...)
+	m.mu.Unlock()
+	return nil
+}
+
+// HandleFriendRequest stores an incoming friend request until the user
+// accepts or rejects it. A repeat request from the same key replaces the
+// greeting of the earlier one; it reports whether the request is new.
+func (m *Manager) HandleFriendRequest(publicKey [32]byte, message string) bool {
+	request := PendingRequest{
+		PublicKey: publicKey,
+		Message:   message,
+		Timestamp: time.Now(),
+	}
+
+	m.mu.Lock()
+	isNew := true
+	for i, existing := range m.pending {
+		if existing.PublicKey == publicKey {
+			m.pending[i].Message = message
+			request.Timestamp = existing.Timestamp
+			isNew = false
+			break
+		}
+	}
+	if isNew {
+		m.pending = append(m.pending, request)
+	}
+	m.mu.Unlock()
+
+	query := `INSERT OR REPLACE INTO friend_requests (public_key, message, received_at) VALUES (?, ?, ?)`
+	if _, err := m.db.Exec(query, hex.EncodeToString(publicKey[:]), message, request.Timestamp); err != nil {
+		logging.Errorf("Failed to save friend request: %v", err)
+	}
+
+	m.requestsChanged()
+	return isNew
+}
+
+// GetPendingRequests returns pending friend requests, oldest first
+func (m *Manager) GetPendingRequests() []PendingRequest {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]PendingRequest(nil), m.pending...)
+}
+
+// RejectFriendRequest drops a pending friend request without telling the sender
+func (m *Manager) RejectFriendRequest(publicKey [32]byte) error {
+	return m.removePendingRequest(publicKey)
+}
+
+// SetOnRequestsChanged sets the callback invoked when a friend request
+// arrives, is accepted or is rejected
+func (m *Manager) SetOnRequestsChanged(callback func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onRequestsChanged = callback
+}
+
+// removePendingRequest removes a pending request from memory and the database
+func (m *Manager) removePendingRequest(publicKey [32]byte) error {
+	m.mu.Lock()
+	for i, req := range m.pending {
+		if req.PublicKey == publicKey {
+			m.pending = append(m.pending[:i], m.pending[i+1:]...)
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	if _, err := m.db.Exec(`DELETE FROM friend_requests WHERE public_key = ?`, hex.EncodeToString(publicKey[:])); err != nil {
+		return fmt.Errorf("failed to delete friend request: %w", err)
+	}
+
+	m.requestsChanged()
+	return nil
+}
+
+// requestsChanged runs the requests-changed callback, if any
+func (m *Manager) requestsChanged() {
+	m.mu.RLock()
+	callback := m.onRequestsChanged
+	m.mu.RUnlock()
+
+	if callback != nil {
+		callback()
+	}
+}
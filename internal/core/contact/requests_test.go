@@ -0,0 +1,109 @@
+package contact
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+func TestFriendRequestsPersist(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "contacts.db")
+	db, err := storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	mgr := NewManager(db, newMockToxManager())
+	changes := 0
+	mgr.SetOnRequestsChanged(func() { changes++ })
+
+	alice, bob := [32]byte{1}, [32]byte{2}
+	if !mgr.HandleFriendRequest(alice, "hi, it's Alice") {
+		t.Error("Expected the first request to be new")
+	}
+	if !mgr.HandleFriendRequest(bob, "hello") {
+		t.Error("Expected a request from another key to be new")
+	}
+
+	// A repeat request replaces the greeting but keeps its place
+	if mgr.HandleFriendRequest(alice, "Alice again") {
+		t.Error("Expected a repeat request not to be new")
+	}
+	pending := mgr.GetPendingRequests()
+	if len(pending) != 2 || pending[0].PublicKey != alice || pending[0].Message != "Alice again" {
+		t.Fatalf("Unexpected pending requests %+v", pending)
+	}
+	if changes != 3 {
+		t.Errorf("Expected 3 change callbacks, got %d", changes)
+	}
+	db.Close()
+
+	// Requests survive a restart
+	db, err = storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	mgr = NewManager(db, newMockToxManager())
+	pending = mgr.GetPendingRequests()
+	if len(pending) != 2 || pending[0].PublicKey != alice || pending[1].PublicKey != bob || pending[0].Message != "Alice again" {
+		t.Fatalf("Requests not reloaded: %+v", pending)
+	}
+
+	// Accepting adds a contact; rejecting just drops the request
+	added, err := mgr.AcceptFriendRequest(alice)
+	if err != nil {
+		t.Fatalf("AcceptFriendRequest failed: %v", err)
+	}
+	if _, ok := mgr.GetContact(added.FriendID); !ok {
+		t.Error("Expected the accepted request to become a contact")
+	}
+	if err := mgr.RejectFriendRequest(bob); err != nil {
+		t.Fatalf("RejectFriendRequest failed: %v", err)
+	}
+	if pending := mgr.GetPendingRequests(); len(pending) != 0 {
+		t.Errorf("Expected no pending requests, got %+v", pending)
+	}
+
+	if pending := NewManager(db, newMockToxManager()).GetPendingRequests(); len(pending) != 0 {
+		t.Errorf("Expected answered requests to be removed from the database, got %+v", pending)
+	}
+}
+
+func TestAcceptedRequestNeedsVerification(t *testing.T) {
+	mgr, _ := setupTestManager(t)
+
+	added, err := mgr.AddContact(testToxID(0x44), "hi")
+	if err != nil {
+		t.Fatalf("AddContact failed: %v", err)
+	}
+	if !mgr.IsEstablished(added.FriendID) {
+		t.Error("Expected a friend we added to be established")
+	}
+
+	accepted, err := mgr.AcceptFriendRequest([32]byte{9})
+	if err != nil {
+		t.Fatalf("AcceptFriendRequest failed: %v", err)
+	}
+	if mgr.IsEstablished(accepted.FriendID) {
+		t.Error("Expected an accepted request to need verification")
+	}
+
+	// The origin is kept across restarts
+	reloaded := NewManager(mgr.db, newMockToxManager())
+	if reloaded.IsEstablished(accepted.FriendID) {
+		t.Error("Expected an accepted request to need verification after reload")
+	}
+
+	if err := mgr.SetVerified(accepted.FriendID, true); err != nil {
+		t.Fatalf("SetVerified failed: %v", err)
+	}
+	if !mgr.IsEstablished(accepted.FriendID) {
+		t.Error("Expected a verified friend to be established")
+	}
+	if mgr.IsEstablished(12345) {
+		t.Error("Expected unknown friends not to be established")
+	}
+}
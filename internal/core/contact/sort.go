@@ -0,0 +1,54 @@
+package contact
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortMode is the order of the contact list
+type SortMode string
+
+const (
+	SortFavoritesFirst SortMode = "favorites"    // Favorites, then most recent activity, then by name
+	SortRecent         SortMode = "recent"       // Most recent activity, then by name
+	SortAlphabetical   SortMode = "alphabetical" // By name
+)
+
+// SetFavorite pins a contact to the top of the favorites-first list
+func (m *Manager) SetFavorite(friendID uint32, favorite bool) error {
+	return m.updateLocalDetail(friendID, "is_favorite", favorite, func(c *Contact) { c.IsFavorite = favorite })
+}
+
+// GetContactsSorted returns all contacts in the given order. Unknown modes,
+// including the empty one, sort favorites first.
+func (m *Manager) GetContactsSorted(mode SortMode) ([]*Contact, error) {
+	contacts := m.GetAllContacts()
+
+	activity := make(map[uint32]time.Time, len(contacts))
+	if mode != SortAlphabetical {
+		for _, c := range contacts {
+			lastActivity, err := m.LastActivity(c)
+			if err != nil {
+				return nil, err
+			}
+			activity[c.FriendID] = lastActivity
+		}
+	}
+
+	sort.SliceStable(contacts, func(i, j int) bool {
+		a, b := contacts[i], contacts[j]
+		if mode != SortRecent && mode != SortAlphabetical && a.IsFavorite != b.IsFavorite {
+			return a.IsFavorite
+		}
+		if !activity[a.FriendID].Equal(activity[b.FriendID]) {
+			return activity[a.FriendID].After(activity[b.FriendID])
+		}
+		nameA, nameB := strings.ToLower(a.DisplayName()), strings.ToLower(b.DisplayName())
+		if nameA != nameB {
+			return nameA < nameB
+		}
+		return a.FriendID < b.FriendID
+	})
+	return contacts, nil
+}
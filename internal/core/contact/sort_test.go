@@ -0,0 +1,113 @@
+package contact
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+func TestGetContactsSorted(t *testing.T) {
+	mgr, _ := setupTestManager(t)
+	now := time.Now()
+	day := 24 * time.Hour
+
+	seeds := []struct {
+		seed        byte
+		name        string
+		favorite    bool
+		added       time.Duration
+		lastMessage time.Duration // Zero if no messages
+	}{
+		{seed: 0x61, name: "bob", favorite: true, added: 10 * day},
+		{seed: 0x62, name: "Alice", added: 5 * day, lastMessage: time.Hour},
+		{seed: 0x63, name: "carol", favorite: true, added: 30 * day, lastMessage: 2 * time.Hour},
+		{seed: 0x64, name: "dave", added: 20 * day},
+	}
+
+	ids := make(map[string]uint32)
+	for _, s := range seeds {
+		c, err := mgr.AddContact(testToxID(s.seed), "hi")
+		if err != nil {
+			t.Fatalf("AddContact failed: %v", err)
+		}
+		c.Name = s.name
+		c.CreatedAt = now.Add(-s.added)
+		if err := mgr.SetFavorite(c.FriendID, s.favorite); err != nil {
+			t.Fatalf("SetFavorite failed: %v", err)
+		}
+		if s.lastMessage > 0 {
+			_, err := mgr.db.Exec(`INSERT INTO messages (uuid, friend_id, content, is_outgoing, timestamp) VALUES (?, ?, 'hello', 0, ?)`,
+				fmt.Sprintf("msg-%d", s.seed), c.FriendID, now.Add(-s.lastMessage))
+			if err != nil {
+				t.Fatalf("Failed to insert message: %v", err)
+			}
+		}
+		ids[s.name] = c.FriendID
+	}
+
+	tests := []struct {
+		mode SortMode
+		want []string
+	}{
+		{SortFavoritesFirst, []string{"carol", "bob", "Alice", "dave"}},
+		{SortRecent, []string{"Alice", "carol", "bob", "dave"}},
+		{SortAlphabetical, []string{"Alice", "bob", "carol", "dave"}},
+		{"", []string{"carol", "bob", "Alice", "dave"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			sorted, err := mgr.GetContactsSorted(tt.mode)
+			if err != nil {
+				t.Fatalf("GetContactsSorted failed: %v", err)
+			}
+			if len(sorted) != len(tt.want) {
+				t.Fatalf("Expected %d contacts, got %d", len(tt.want), len(sorted))
+			}
+			for i, name := range tt.want {
+				if sorted[i].FriendID != ids[name] {
+					got := make([]string, len(sorted))
+					for j, c := range sorted {
+						got[j] = c.Name
+					}
+					t.Fatalf("Expected order %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSetFavoritePersists(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "contacts.db")
+	db, err := storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	mgr := NewManager(db, newMockToxManager())
+	c, err := mgr.AddContact(testToxID(0x65), "hi")
+	if err != nil {
+		t.Fatalf("AddContact failed: %v", err)
+	}
+	if err := mgr.SetFavorite(c.FriendID, true); err != nil {
+		t.Fatalf("SetFavorite failed: %v", err)
+	}
+	if err := mgr.SetFavorite(99, true); err == nil {
+		t.Error("Expected error for unknown contact")
+	}
+	db.Close()
+
+	db, err = storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	found, ok := NewManager(db, newMockToxManager()).GetContact(c.FriendID)
+	if !ok || !found.(*Contact).IsFavorite {
+		t.Error("Favorite not persisted")
+	}
+}
@@ -0,0 +1,82 @@
+package contact
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// LastActivity returns when a contact was last active: the latest of when it
+// was added, last seen online, and its newest message
+func (m *Manager) LastActivity(c *Contact) (time.Time, error) {
+	latest := c.CreatedAt
+	if c.LastSeenAt.After(latest) {
+		latest = c.LastSeenAt
+	}
+
+	var lastMessage time.Time
+	err := m.db.QueryRow(`
+		SELECT timestamp FROM messages
+		WHERE friend_id = ? AND is_deleted = 0
+		ORDER BY timestamp DESC LIMIT 1`, c.FriendID).Scan(&lastMessage)
+	if err != nil && err != sql.ErrNoRows {
+		return time.Time{}, fmt.Errorf("failed to get last message: %w", err)
+	}
+	if lastMessage.After(latest) {
+		latest = lastMessage
+	}
+	return latest, nil
+}
+
+// FindStaleContacts returns the contacts with no messages and no online
+// activity for at least inactiveFor, least recently active first. Contacts
+// that are online or marked favorite are never stale. Nothing is removed;
+// see RemoveContacts.
+func (m *Manager) FindStaleContacts(inactiveFor time.Duration) ([]*Contact, error) {
+	if inactiveFor <= 0 {
+		return nil, fmt.Errorf("inactivity period must be positive")
+	}
+	cutoff := time.Now().Add(-inactiveFor)
+
+	type candidate struct {
+		contact      *Contact
+		lastActivity time.Time
+	}
+	var candidates []candidate
+
+	for _, c := range m.GetAllContacts() {
+		if c.Status != StatusOffline || c.IsFavorite {
+			continue
+		}
+
+		lastActivity, err := m.LastActivity(c)
+		if err != nil {
+			return nil, err
+		}
+		if lastActivity.Before(cutoff) {
+			candidates = append(candidates, candidate{c, lastActivity})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].lastActivity.Before(candidates[j].lastActivity)
+	})
+
+	stale := make([]*Contact, len(candidates))
+	for i, cand := range candidates {
+		stale[i] = cand.contact
+	}
+	return stale, nil
+}
+
+// RemoveContacts deletes several contacts, such as confirmed stale ones. It
+// stops at the first failure and reports how many were removed.
+func (m *Manager) RemoveContacts(friendIDs []uint32) (int, error) {
+	for i, friendID := range friendIDs {
+		if err := m.DeleteContact(friendID); err != nil {
+			return i, err
+		}
+	}
+	return len(friendIDs), nil
+}
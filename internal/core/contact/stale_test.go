@@ -0,0 +1,104 @@
+package contact
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFindStaleContacts(t *testing.T) {
+	mgr, _ := setupTestManager(t)
+	now := time.Now()
+	year := 365 * 24 * time.Hour
+
+	tests := []struct {
+		seed        byte
+		added       time.Duration // How long ago the contact was added
+		lastSeen    time.Duration // Zero if never seen online
+		lastMessage time.Duration // Zero if no messages
+		status      Status
+		favorite    bool
+		stale       bool
+	}{
+		{seed: 0x01, added: 3 * year, stale: true},                                        // Never seen, no messages
+		{seed: 0x02, added: 3 * year, lastSeen: 2 * year, stale: true},                    // Seen long ago
+		{seed: 0x03, added: 3 * year, lastSeen: 2 * year, lastMessage: 24 * time.Hour},    // Recent message
+		{seed: 0x04, added: 3 * year, lastSeen: 48 * time.Hour},                           // Recently online
+		{seed: 0x05, added: 24 * time.Hour},                                               // Just added
+		{seed: 0x06, added: 3 * year, lastMessage: 18 * 30 * 24 * time.Hour, stale: true}, // Old message
+		{seed: 0x07, added: 3 * year, status: StatusOnline},                               // Online now
+		{seed: 0x08, added: 3 * year, favorite: true},                                     // Favorite
+	}
+
+	want := map[uint32]bool{}
+	for _, tt := range tests {
+		c, err := mgr.AddContact(testToxID(tt.seed), "hi")
+		if err != nil {
+			t.Fatalf("AddContact failed: %v", err)
+		}
+		c.CreatedAt = now.Add(-tt.added)
+		if tt.lastSeen > 0 {
+			c.LastSeenAt = now.Add(-tt.lastSeen)
+		}
+		c.Status = tt.status
+		c.IsFavorite = tt.favorite
+
+		if tt.lastMessage > 0 {
+			_, err := mgr.db.Exec(`INSERT INTO messages (uuid, friend_id, content, is_outgoing, timestamp) VALUES (?, ?, 'hello', 0, ?)`,
+				fmt.Sprintf("msg-%d", tt.seed), c.FriendID, now.Add(-tt.lastMessage))
+			if err != nil {
+				t.Fatalf("Failed to insert message: %v", err)
+			}
+		}
+		if tt.stale {
+			want[c.FriendID] = true
+		}
+	}
+
+	stale, err := mgr.FindStaleContacts(year)
+	if err != nil {
+		t.Fatalf("FindStaleContacts failed: %v", err)
+	}
+	if len(stale) != len(want) {
+		t.Fatalf("Expected %d stale contacts, got %d", len(want), len(stale))
+	}
+	for _, c := range stale {
+		if !want[c.FriendID] {
+			t.Errorf("Contact %s should not be stale", c.ToxID[:8])
+		}
+	}
+
+	// Least recently active first: the old message keeps 0x06 ahead of the others
+	if stale[len(stale)-1].ToxID != testToxID(0x06) {
+		t.Errorf("Expected the contact with the old message last, got %s", stale[len(stale)-1].ToxID[:8])
+	}
+
+	// Finding candidates removes nothing
+	if got := len(mgr.GetAllContacts()); got != len(tests) {
+		t.Errorf("Expected %d contacts to remain, got %d", len(tests), got)
+	}
+
+	if _, err := mgr.FindStaleContacts(0); err == nil {
+		t.Error("Expected error for a non-positive period")
+	}
+}
+
+func TestRemoveContacts(t *testing.T) {
+	mgr, _ := setupTestManager(t)
+	var ids []uint32
+	for _, seed := range []byte{0x21, 0x22, 0x23} {
+		c, err := mgr.AddContact(testToxID(seed), "hi")
+		if err != nil {
+			t.Fatalf("AddContact failed: %v", err)
+		}
+		ids = append(ids, c.FriendID)
+	}
+
+	removed, err := mgr.RemoveContacts(ids[:2])
+	if err != nil || removed != 2 {
+		t.Fatalf("Expected 2 contacts removed, got %d (%v)", removed, err)
+	}
+	if contacts := mgr.GetAllContacts(); len(contacts) != 1 || contacts[0].FriendID != ids[2] {
+		t.Errorf("Expected only the third contact to remain, got %d contacts", len(contacts))
+	}
+}
@@ -0,0 +1,48 @@
+package contact
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// toxIDLength is the length of a hex-encoded Tox ID (public key, nospam, checksum)
+const toxIDLength = 76
+
+// Fingerprint formats the public key part of a Tox ID in groups of four so
+// it can be compared with the contact over another channel
+func Fingerprint(toxID string) (string, error) {
+	toxID = strings.TrimSpace(toxID)
+	if len(toxID) != toxIDLength {
+		return "", fmt.Errorf("invalid Tox ID length: expected %d characters, got %d", toxIDLength, len(toxID))
+	}
+	if _, err := hex.DecodeString(toxID); err != nil {
+		return "", fmt.Errorf("invalid Tox ID: %w", err)
+	}
+
+	key := strings.ToUpper(toxID[:64])
+	groups := make([]string, 0, len(key)/4)
+	for i := 0; i < len(key); i += 4 {
+		groups = append(groups, key[i:i+4])
+	}
+	return strings.Join(groups, " "), nil
+}
+
+// NeedsAddConfirmation reports whether adding a contact must be confirmed
+// first: only when confirmation is required and the fingerprint was not verified
+func NeedsAddConfirmation(requireConfirmation, fingerprintVerified bool) bool {
+	return requireConfirmation && !fingerprintVerified
+}
+
+// FindByToxID returns the contact added with a Tox ID
+func (m *Manager) FindByToxID(toxID string) (*Contact, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, c := range m.contacts {
+		if strings.EqualFold(c.ToxID, strings.TrimSpace(toxID)) {
+			return c, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,69 @@
+package contact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNeedsAddConfirmation(t *testing.T) {
+	tests := []struct {
+		name     string
+		require  bool
+		verified bool
+		want     bool
+	}{
+		{"required and unverified", true, false, true},
+		{"required and verified", true, true, false},
+		{"not required and unverified", false, false, false},
+		{"not required and verified", false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsAddConfirmation(tt.require, tt.verified); got != tt.want {
+				t.Errorf("NeedsAddConfirmation(%v, %v) = %v, want %v", tt.require, tt.verified, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	toxID := strings.ToLower(testToxID(0xAB))
+	fingerprint, err := Fingerprint(toxID)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+
+	groups := strings.Split(fingerprint, " ")
+	if len(groups) != 16 {
+		t.Errorf("Expected 16 groups of four, got %d: %s", len(groups), fingerprint)
+	}
+	if groups[0] != "ABAB" {
+		t.Errorf("Expected upper-case key, got %s", groups[0])
+	}
+	if strings.ReplaceAll(fingerprint, " ", "") != strings.ToUpper(toxID[:64]) {
+		t.Errorf("Fingerprint does not match the public key: %s", fingerprint)
+	}
+
+	for _, invalid := range []string{"", "ABCD", strings.Repeat("Z", 76)} {
+		if _, err := Fingerprint(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestFindByToxID(t *testing.T) {
+	mgr, _ := setupTestManager(t)
+	added, err := mgr.AddContact(testToxID(0x55), "hi")
+	if err != nil {
+		t.Fatalf("AddContact failed: %v", err)
+	}
+
+	found, ok := mgr.FindByToxID(strings.ToLower(testToxID(0x55)))
+	if !ok || found != added {
+		t.Errorf("Expected to find the added contact, got %v", found)
+	}
+	if _, ok := mgr.FindByToxID(testToxID(0x66)); ok {
+		t.Error("Expected no contact for an unknown Tox ID")
+	}
+}
@@ -0,0 +1,459 @@
+package group
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opd-ai/whisp/internal/logging"
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+// Group is a group chat we created or joined
+type Group struct {
+	ID        string    `json:"id"` // Shared by all members
+	Name      string    `json:"name"`
+	IsJoined  bool      `json:"is_joined"` // False once we left
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Member is a peer in a group. Members need not be our friends; events from
+// members we cannot reach directly are relayed by those we can.
+type Member struct {
+	GroupID   string    `json:"group_id"`
+	PublicKey string    `json:"public_key"` // Hex encoded
+	Name      string    `json:"name"`
+	HasLeft   bool      `json:"has_left"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+// Message is a group chat message
+type Message struct {
+	ID         int64     `json:"id"`
+	UUID       string    `json:"uuid"`
+	GroupID    string    `json:"group_id"`
+	SenderKey  string    `json:"sender_key"` // Hex public key of the author
+	Content    string    `json:"content"`
+	IsOutgoing bool      `json:"is_outgoing"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// Invite is an invitation to a group that we have not answered yet
+type Invite struct {
+	GroupID  string    `json:"group_id"`
+	Name     string    `json:"name"`
+	FriendID uint32    `json:"friend_id"` // Friend who invited us
+	Members  []Member  `json:"members"`
+	Received time.Time `json:"received"`
+}
+
+// ToxManager interface for the Tox identity and friend list
+type ToxManager interface {
+	GetFriends() []uint32
+	GetFriendPublicKey(friendID uint32) ([32]byte, error)
+	GetPublicKey() [32]byte
+	GetName() string
+}
+
+// Transport carries group events to friends
+type Transport interface {
+	SendGroupControl(friendID uint32, payload string) error
+}
+
+// Manager manages group chats. toxcore's conference API does not deliver
+// messages, so group events travel as control messages between friends and
+// every member relays the events it has not seen to the members it can reach.
+type Manager struct {
+	db        *storage.Database
+	toxMgr    ToxManager
+	transport Transport
+
+	mu      sync.RWMutex
+	invites map[string]*Invite   // Group ID -> invitation
+	seen    map[string]time.Time // Event UUIDs already handled
+
+	onMessage   func(*Message)
+	onPeerJoin  func(groupID string, member Member)
+	onPeerLeave func(groupID string, member Member)
+	onInvite    func(*Invite)
+}
+
+// NewManager creates a new group manager
+func NewManager(db *storage.Database, toxMgr ToxManager, transport Transport) *Manager {
+	return &Manager{
+		db:        db,
+		toxMgr:    toxMgr,
+		transport: transport,
+		invites:   make(map[string]*Invite),
+		seen:      make(map[string]time.Time),
+	}
+}
+
+// CreateGroup creates a group with ourselves as the only member
+func (m *Manager) CreateGroup(name string) (*Group, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("group name cannot be empty")
+	}
+
+	group := &Group{
+		ID:        uuid.New().String(),
+		Name:      name,
+		IsJoined:  true,
+		CreatedAt: time.Now(),
+	}
+	if err := m.saveGroup(group); err != nil {
+		return nil, err
+	}
+	if err := m.saveMember(m.self(group.ID)); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// InviteFriend invites a friend to a group we are in
+func (m *Manager) InviteFriend(groupID string, friendID uint32) error {
+	group, err := m.GetGroup(groupID)
+	if err != nil {
+		return err
+	}
+	if !group.IsJoined {
+		return fmt.Errorf("not a member of group %s", groupID)
+	}
+
+	members, err := m.GetMembers(groupID)
+	if err != nil {
+		return err
+	}
+	ev := m.newEvent(groupID, eventInvite)
+	ev.GroupName = group.Name
+	for _, member := range members {
+		if !member.HasLeft {
+			ev.Members = append(ev.Members, wireMember{Key: member.PublicKey, Name: member.Name})
+		}
+	}
+
+	payload, err := encodeEvent(ev)
+	if err != nil {
+		return err
+	}
+	if err := m.transport.SendGroupControl(friendID, payload); err != nil {
+		return fmt.Errorf("failed to invite friend: %w", err)
+	}
+	return nil
+}
+
+// JoinGroupByID accepts the invitation to a group and tells its members
+func (m *Manager) JoinGroupByID(groupID string) (*Group, error) {
+	m.mu.Lock()
+	invite, exists := m.invites[groupID]
+	delete(m.invites, groupID)
+	m.mu.Unlock()
+
+	if !exists {
+		return nil, fmt.Errorf("no invitation to group %s", groupID)
+	}
+
+	group := &Group{
+		ID:        groupID,
+		Name:      invite.Name,
+		IsJoined:  true,
+		CreatedAt: time.Now(),
+	}
+	if err := m.saveGroup(group); err != nil {
+		return nil, err
+	}
+	for _, member := range invite.Members {
+		if err := m.saveMember(member); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.saveMember(m.self(groupID)); err != nil {
+		return nil, err
+	}
+
+	m.broadcast(m.newEvent(groupID, eventJoin), "")
+	return m.GetGroup(groupID)
+}
+
+// DeclineInvite drops the invitation to a group
+func (m *Manager) DeclineInvite(groupID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.invites, groupID)
+}
+
+// GetInvites returns the invitations we have not answered, oldest first
+func (m *Manager) GetInvites() []*Invite {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	invites := make([]*Invite, 0, len(m.invites))
+	for _, invite := range m.invites {
+		invites = append(invites, invite)
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].Received.Before(invites[j].Received)
+	})
+	return invites
+}
+
+// SendGroupMessage stores a message and sends it to the group. Members that
+// cannot be reached right now miss it; group messages are not queued.
+func (m *Manager) SendGroupMessage(groupID, content string) (*Message, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("message cannot be empty")
+	}
+	group, err := m.GetGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !group.IsJoined {
+		return nil, fmt.Errorf("not a member of group %s", groupID)
+	}
+
+	ev := m.newEvent(groupID, eventMessage)
+	ev.Content = content
+	msg := &Message{
+		UUID:       ev.ID,
+		GroupID:    groupID,
+		SenderKey:  ev.Origin,
+		Content:    content,
+		IsOutgoing: true,
+		Timestamp:  time.Now(),
+	}
+	if err := m.saveMessage(msg); err != nil {
+		return nil, err
+	}
+
+	m.broadcast(ev, "")
+	return msg, nil
+}
+
+// LeaveGroup tells the members we left. The group and its history are kept.
+func (m *Manager) LeaveGroup(groupID string) error {
+	group, err := m.GetGroup(groupID)
+	if err != nil {
+		return err
+	}
+	if !group.IsJoined {
+		return nil
+	}
+
+	m.broadcast(m.newEvent(groupID, eventLeave), "")
+
+	if _, err := m.db.Exec(`UPDATE groups SET is_joined = 0 WHERE id = ?`, groupID); err != nil {
+		return fmt.Errorf("failed to leave group: %w", err)
+	}
+	if _, err := m.db.Exec(`UPDATE group_members SET has_left = 1 WHERE group_id = ? AND public_key = ?`, groupID, m.selfKey()); err != nil {
+		return fmt.Errorf("failed to leave group: %w", err)
+	}
+	return nil
+}
+
+// GetGroups returns all groups, including those we left
+func (m *Manager) GetGroups() ([]*Group, error) {
+	rows, err := m.db.Query(`SELECT id, name, is_joined, created_at FROM groups ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*Group
+	for rows.Next() {
+		group := &Group{}
+		if err := rows.Scan(&group.ID, &group.Name, &group.IsJoined, &group.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+// GetGroup returns a group by ID
+func (m *Manager) GetGroup(groupID string) (*Group, error) {
+	group := &Group{}
+	err := m.db.QueryRow(`SELECT id, name, is_joined, created_at FROM groups WHERE id = ?`, groupID).
+		Scan(&group.ID, &group.Name, &group.IsJoined, &group.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("group %s not found", groupID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	return group, nil
+}
+
+// GetMembers returns the members of a group, including those who left
+func (m *Manager) GetMembers(groupID string) ([]Member, error) {
+	rows, err := m.db.Query(`
+		SELECT group_id, public_key, name, has_left, joined_at
+		FROM group_members WHERE group_id = ?
+		ORDER BY joined_at
+	`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []Member
+	for rows.Next() {
+		var member Member
+		if err := rows.Scan(&member.GroupID, &member.PublicKey, &member.Name, &member.HasLeft, &member.JoinedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan group member: %w", err)
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// GetMessages returns messages of a group, newest first
+func (m *Manager) GetMessages(groupID string, limit, offset int) ([]*Message, error) {
+	rows, err := m.db.Query(`
+		SELECT id, uuid, group_id, sender_key, content, is_outgoing, timestamp
+		FROM group_messages WHERE group_id = ?
+		ORDER BY timestamp DESC
+		LIMIT ? OFFSET ?
+	`, groupID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		msg := &Message{}
+		if err := rows.Scan(&msg.ID, &msg.UUID, &msg.GroupID, &msg.SenderKey, &msg.Content, &msg.IsOutgoing, &msg.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan group message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// SetOnMessage sets the callback invoked when a member sends a message
+func (m *Manager) SetOnMessage(callback func(*Message)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onMessage = callback
+}
+
+// SetOnPeerJoin sets the callback invoked when a peer joins or rejoins a group
+func (m *Manager) SetOnPeerJoin(callback func(groupID string, member Member)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onPeerJoin = callback
+}
+
+// SetOnPeerLeave sets the callback invoked when a peer leaves a group
+func (m *Manager) SetOnPeerLeave(callback func(groupID string, member Member)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onPeerLeave = callback
+}
+
+// SetOnInvite sets the callback invoked when a friend invites us to a group
+func (m *Manager) SetOnInvite(callback func(*Invite)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onInvite = callback
+}
+
+// self returns ourselves as a member of a group
+func (m *Manager) self(groupID string) Member {
+	return Member{
+		GroupID:   groupID,
+		PublicKey: m.selfKey(),
+		Name:      m.toxMgr.GetName(),
+		JoinedAt:  time.Now(),
+	}
+}
+
+// selfKey returns our hex encoded public key
+func (m *Manager) selfKey() string {
+	publicKey := m.toxMgr.GetPublicKey()
+	return hex.EncodeToString(publicKey[:])
+}
+
+// friendsByKey maps the hex public keys of our friends to their friend IDs
+func (m *Manager) friendsByKey() map[string]uint32 {
+	friends := make(map[string]uint32)
+	for _, friendID := range m.toxMgr.GetFriends() {
+		publicKey, err := m.toxMgr.GetFriendPublicKey(friendID)
+		if err != nil {
+			continue
+		}
+		friends[hex.EncodeToString(publicKey[:])] = friendID
+	}
+	return friends
+}
+
+// saveGroup creates a group or rejoins one we left
+func (m *Manager) saveGroup(group *Group) error {
+	query := `
+		INSERT INTO groups (id, name, is_joined, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name, is_joined = excluded.is_joined
+	`
+	if _, err := m.db.Exec(query, group.ID, group.Name, group.IsJoined, group.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save group: %w", err)
+	}
+	return nil
+}
+
+// saveMember adds a member to a group, or marks a member who left as back
+func (m *Manager) saveMember(member Member) error {
+	if member.JoinedAt.IsZero() {
+		member.JoinedAt = time.Now()
+	}
+	query := `
+		INSERT INTO group_members (group_id, public_key, name, has_left, joined_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(group_id, public_key) DO UPDATE SET
+			name = CASE WHEN excluded.name != '' THEN excluded.name ELSE group_members.name END,
+			has_left = excluded.has_left
+	`
+	_, err := m.db.Exec(query, member.GroupID, member.PublicKey, member.Name, member.HasLeft, member.JoinedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save group member: %w", err)
+	}
+	return nil
+}
+
+// saveMessage stores a group message and sets its ID
+func (m *Manager) saveMessage(msg *Message) error {
+	result, err := m.db.Exec(`
+		INSERT INTO group_messages (uuid, group_id, sender_key, content, is_outgoing, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, msg.UUID, msg.GroupID, msg.SenderKey, msg.Content, msg.IsOutgoing, msg.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to save group message: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to save group message: %w", err)
+	}
+	msg.ID = id
+	return nil
+}
+
+// lookupMember returns a member of a group by public key
+func (m *Manager) lookupMember(groupID, publicKey string) (Member, bool) {
+	member := Member{GroupID: groupID, PublicKey: publicKey}
+	err := m.db.QueryRow(`
+		SELECT name, has_left, joined_at FROM group_members
+		WHERE group_id = ? AND public_key = ?
+	`, groupID, publicKey).Scan(&member.Name, &member.HasLeft, &member.JoinedAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logging.Warnf("Failed to look up group member: %v", err)
+		}
+		return Member{}, false
+	}
+	return member, true
+}
@@ -0,0 +1,260 @@
+package group
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+// testNetwork delivers group events between test peers synchronously
+type testNetwork struct {
+	peers map[[32]byte]*testPeer
+}
+
+// testPeer is one Whisp instance with its own database and friend list
+type testPeer struct {
+	network *testNetwork
+	key     [32]byte
+	name    string
+	friends map[uint32][32]byte
+	mgr     *Manager
+}
+
+func (p *testPeer) GetFriends() []uint32 {
+	friends := make([]uint32, 0, len(p.friends))
+	for friendID := range p.friends {
+		friends = append(friends, friendID)
+	}
+	return friends
+}
+
+func (p *testPeer) GetFriendPublicKey(friendID uint32) ([32]byte, error) {
+	key, exists := p.friends[friendID]
+	if !exists {
+		return [32]byte{}, fmt.Errorf("friend %d not found", friendID)
+	}
+	return key, nil
+}
+
+func (p *testPeer) GetPublicKey() [32]byte { return p.key }
+
+func (p *testPeer) GetName() string { return p.name }
+
+func (p *testPeer) SendGroupControl(friendID uint32, payload string) error {
+	key, exists := p.friends[friendID]
+	if !exists {
+		return fmt.Errorf("friend %d not found", friendID)
+	}
+	target := p.network.peers[key]
+	for id, friendKey := range target.friends {
+		if friendKey == p.key {
+			target.mgr.HandleControl(id, payload)
+			return nil
+		}
+	}
+	return fmt.Errorf("not friends")
+}
+
+func (n *testNetwork) addPeer(t *testing.T, id byte, name string) *testPeer {
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	peer := &testPeer{network: n, key: [32]byte{id}, name: name, friends: make(map[uint32][32]byte)}
+	peer.mgr = NewManager(db, peer, peer)
+	n.peers[peer.key] = peer
+	return peer
+}
+
+func befriend(a, b *testPeer) {
+	a.friends[uint32(len(a.friends))] = b.key
+	b.friends[uint32(len(b.friends))] = a.key
+}
+
+func friendIDOf(p, friend *testPeer) uint32 {
+	for id, key := range p.friends {
+		if key == friend.key {
+			return id
+		}
+	}
+	return 0
+}
+
+// setupGroup creates a group owned by alice with bob and carol joined. Bob and
+// carol are only friends with alice, so their events must be relayed.
+func setupGroup(t *testing.T) (string, *testPeer, *testPeer, *testPeer) {
+	network := &testNetwork{peers: make(map[[32]byte]*testPeer)}
+	alice := network.addPeer(t, 1, "Alice")
+	bob := network.addPeer(t, 2, "Sam")
+	carol := network.addPeer(t, 3, "Sam")
+	befriend(alice, bob)
+	befriend(alice, carol)
+
+	group, err := alice.mgr.CreateGroup("Friends")
+	if err != nil {
+		t.Fatalf("CreateGroup failed: %v", err)
+	}
+	for _, peer := range []*testPeer{bob, carol} {
+		if err := alice.mgr.InviteFriend(group.ID, friendIDOf(alice, peer)); err != nil {
+			t.Fatalf("InviteFriend failed: %v", err)
+		}
+		if _, err := peer.mgr.JoinGroupByID(group.ID); err != nil {
+			t.Fatalf("JoinGroupByID failed: %v", err)
+		}
+	}
+	return group.ID, alice, bob, carol
+}
+
+func activeMembers(t *testing.T, p *testPeer, groupID string) int {
+	members, err := p.mgr.GetMembers(groupID)
+	if err != nil {
+		t.Fatalf("GetMembers failed: %v", err)
+	}
+	var count int
+	for _, member := range members {
+		if !member.HasLeft {
+			count++
+		}
+	}
+	return count
+}
+
+func TestJoinGroupRequiresInvite(t *testing.T) {
+	network := &testNetwork{peers: make(map[[32]byte]*testPeer)}
+	alice := network.addPeer(t, 1, "Alice")
+
+	if _, err := alice.mgr.JoinGroupByID("unknown"); err == nil {
+		t.Error("Expected joining without an invite to fail")
+	}
+}
+
+func TestGroupMembershipAndRelay(t *testing.T) {
+	groupID, alice, bob, carol := setupGroup(t)
+
+	for _, peer := range []*testPeer{alice, bob, carol} {
+		if count := activeMembers(t, peer, groupID); count != 3 {
+			t.Errorf("%s sees %d members, want 3", peer.name, count)
+		}
+	}
+
+	var received *Message
+	bob.mgr.SetOnMessage(func(msg *Message) { received = msg })
+
+	sent, err := carol.mgr.SendGroupMessage(groupID, "hello")
+	if err != nil {
+		t.Fatalf("SendGroupMessage failed: %v", err)
+	}
+	if received == nil || received.UUID != sent.UUID || received.Content != "hello" {
+		t.Fatalf("Bob did not receive the relayed message: %+v", received)
+	}
+
+	for _, peer := range []*testPeer{alice, bob, carol} {
+		messages, err := peer.mgr.GetMessages(groupID, 10, 0)
+		if err != nil {
+			t.Fatalf("GetMessages failed: %v", err)
+		}
+		if len(messages) != 1 {
+			t.Errorf("%s has %d messages, want 1", peer.name, len(messages))
+		}
+	}
+}
+
+func TestGroupLeaveAndRejoin(t *testing.T) {
+	groupID, alice, bob, carol := setupGroup(t)
+
+	var left, joined string
+	carol.mgr.SetOnPeerLeave(func(_ string, member Member) { left = member.PublicKey })
+	carol.mgr.SetOnPeerJoin(func(_ string, member Member) { joined = member.PublicKey })
+	bobKey := bob.mgr.selfKey()
+
+	if err := bob.mgr.LeaveGroup(groupID); err != nil {
+		t.Fatalf("LeaveGroup failed: %v", err)
+	}
+	if left != bobKey {
+		t.Error("Carol was not told that Bob left")
+	}
+	if count := activeMembers(t, alice, groupID); count != 2 {
+		t.Errorf("Alice sees %d members after leave, want 2", count)
+	}
+	if _, err := bob.mgr.SendGroupMessage(groupID, "still here?"); err == nil {
+		t.Error("Expected sending to a left group to fail")
+	}
+
+	if err := alice.mgr.InviteFriend(groupID, friendIDOf(alice, bob)); err != nil {
+		t.Fatalf("InviteFriend failed: %v", err)
+	}
+	if _, err := bob.mgr.JoinGroupByID(groupID); err != nil {
+		t.Fatalf("JoinGroupByID failed: %v", err)
+	}
+	if joined != bobKey {
+		t.Error("Carol was not told that Bob rejoined")
+	}
+	if count := activeMembers(t, carol, groupID); count != 3 {
+		t.Errorf("Carol sees %d members after rejoin, want 3", count)
+	}
+}
+
+func TestGroupIgnoresNonMembers(t *testing.T) {
+	groupID, alice, _, _ := setupGroup(t)
+	mallory := alice.network.addPeer(t, 4, "Mallory")
+	befriend(alice, mallory)
+
+	ev := &event{Group: groupID, ID: "forged", Kind: eventMessage, Origin: mallory.mgr.selfKey(), Content: "spam"}
+	payload, err := encodeEvent(ev)
+	if err != nil {
+		t.Fatalf("encodeEvent failed: %v", err)
+	}
+	alice.mgr.HandleControl(friendIDOf(alice, mallory), payload)
+
+	messages, err := alice.mgr.GetMessages(groupID, 10, 0)
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(messages) != 0 {
+		t.Errorf("Expected message from non-member to be dropped, got %d", len(messages))
+	}
+}
+
+func TestDisplayNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []Member
+		want    map[string]string
+	}{
+		{
+			name:    "unique names",
+			members: []Member{{PublicKey: "aaaaaaaa11", Name: "Ann"}, {PublicKey: "bbbbbbbb22", Name: "Ben"}},
+			want:    map[string]string{"aaaaaaaa11": "Ann", "bbbbbbbb22": "Ben"},
+		},
+		{
+			name:    "colliding names",
+			members: []Member{{PublicKey: "aaaaaaaa11", Name: "Sam"}, {PublicKey: "bbbbbbbb22", Name: "sam"}},
+			want:    map[string]string{"aaaaaaaa11": "Sam (AAAAAAAA)", "bbbbbbbb22": "sam (BBBBBBBB)"},
+		},
+		{
+			name:    "collision with member who left",
+			members: []Member{{PublicKey: "aaaaaaaa11", Name: "Sam"}, {PublicKey: "bbbbbbbb22", Name: "Sam", HasLeft: true}},
+			want:    map[string]string{"aaaaaaaa11": "Sam", "bbbbbbbb22": "Sam"},
+		},
+		{
+			name:    "empty name",
+			members: []Member{{PublicKey: "cccccccc33"}},
+			want:    map[string]string{"cccccccc33": "CCCCCCCC"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := displayNames(tt.members)
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("displayNames()[%s] = %q, want %q", key, got[key], want)
+				}
+			}
+		})
+	}
+}
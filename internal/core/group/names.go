@@ -0,0 +1,65 @@
+package group
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// keySuffixLength is how many hex digits of a public key tell apart members
+// sharing a name
+const keySuffixLength = 8
+
+// DisplayNames maps the public keys of a group's members to the names shown
+// for them. Active members sharing a name get a short key suffix.
+func (m *Manager) DisplayNames(groupID string) (map[string]string, error) {
+	members, err := m.GetMembers(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return displayNames(members), nil
+}
+
+// DisplayName returns the name shown for a member of a group
+func (m *Manager) DisplayName(groupID, publicKey string) string {
+	names, err := m.DisplayNames(groupID)
+	if err != nil {
+		logging.Warnf("%v", err)
+	}
+	if name, exists := names[publicKey]; exists {
+		return name
+	}
+	return shortKey(publicKey)
+}
+
+// displayNames disambiguates the names of group members
+func displayNames(members []Member) map[string]string {
+	counts := make(map[string]int)
+	for _, member := range members {
+		if !member.HasLeft {
+			counts[strings.ToLower(member.Name)]++
+		}
+	}
+
+	names := make(map[string]string, len(members))
+	for _, member := range members {
+		switch {
+		case member.Name == "":
+			names[member.PublicKey] = shortKey(member.PublicKey)
+		case counts[strings.ToLower(member.Name)] > 1:
+			names[member.PublicKey] = fmt.Sprintf("%s (%s)", member.Name, shortKey(member.PublicKey))
+		default:
+			names[member.PublicKey] = member.Name
+		}
+	}
+	return names
+}
+
+// shortKey returns the first digits of a hex public key
+func shortKey(publicKey string) string {
+	if len(publicKey) > keySuffixLength {
+		publicKey = publicKey[:keySuffixLength]
+	}
+	return strings.ToUpper(publicKey)
+}
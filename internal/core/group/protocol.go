@@ -0,0 +1,316 @@
+package group
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// Group event kinds
+const (
+	eventInvite  = "invite"
+	eventJoin    = "join"
+	eventLeave   = "leave"
+	eventMessage = "msg"
+)
+
+// seenTTL is how long event IDs are remembered for relay dedupe
+const seenTTL = time.Hour
+
+// event is a group event as sent between friends. Origin is the member the
+// event is about, which differs from the sending friend when it was relayed.
+type event struct {
+	Group     string       `json:"g"`
+	ID        string       `json:"u"`
+	Kind      string       `json:"t"`
+	Origin    string       `json:"o"`
+	Name      string       `json:"n,omitempty"`  // Origin's name
+	GroupName string       `json:"gn,omitempty"` // Invites only
+	Content   string       `json:"c,omitempty"`  // Messages only
+	Members   []wireMember `json:"m,omitempty"`  // Invites only
+}
+
+// wireMember is a group member listed in an invite
+type wireMember struct {
+	Key  string `json:"k"`
+	Name string `json:"n"`
+}
+
+// encodeEvent serializes a group event for SendGroupControl
+func encodeEvent(ev *event) (string, error) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode group event: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodeEvent parses and validates a group event
+func decodeEvent(payload string) (*event, error) {
+	var ev event
+	if err := json.Unmarshal([]byte(payload), &ev); err != nil {
+		return nil, fmt.Errorf("failed to decode group event: %w", err)
+	}
+	if ev.Group == "" || ev.ID == "" || !validKey(ev.Origin) {
+		return nil, fmt.Errorf("incomplete group event")
+	}
+	switch ev.Kind {
+	case eventInvite, eventJoin, eventLeave, eventMessage:
+	default:
+		return nil, fmt.Errorf("unknown group event %q", ev.Kind)
+	}
+	return &ev, nil
+}
+
+// validKey reports whether s is a hex encoded public key
+func validKey(s string) bool {
+	key, err := hex.DecodeString(s)
+	return err == nil && len(key) == 32
+}
+
+// newEvent creates an event originating from us. It is marked as seen so
+// relayed copies coming back are dropped.
+func (m *Manager) newEvent(groupID, kind string) *event {
+	ev := &event{
+		Group:  groupID,
+		ID:     uuid.New().String(),
+		Kind:   kind,
+		Origin: m.selfKey(),
+		Name:   m.toxMgr.GetName(),
+	}
+	m.markSeen(ev.ID)
+	return ev
+}
+
+// markSeen records an event ID and reports whether it was new
+func (m *Manager) markSeen(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.seen[id]; exists {
+		return false
+	}
+	now := time.Now()
+	for seenID, at := range m.seen {
+		if now.Sub(at) > seenTTL {
+			delete(m.seen, seenID)
+		}
+	}
+	m.seen[id] = now
+	return true
+}
+
+// broadcast sends an event to every active member that is our friend, except
+// the event's origin, ourselves and the member we received it from
+func (m *Manager) broadcast(ev *event, skipKey string) {
+	members, err := m.GetMembers(ev.Group)
+	if err != nil {
+		logging.Warnf("Failed to send group event: %v", err)
+		return
+	}
+	payload, err := encodeEvent(ev)
+	if err != nil {
+		logging.Warnf("%v", err)
+		return
+	}
+
+	selfKey := m.selfKey()
+	friends := m.friendsByKey()
+	for _, member := range members {
+		key := member.PublicKey
+		if member.HasLeft || key == selfKey || key == ev.Origin || key == skipKey {
+			continue
+		}
+		friendID, isFriend := friends[key]
+		if !isFriend {
+			continue // Reached through another member's relay
+		}
+		if err := m.transport.SendGroupControl(friendID, payload); err != nil {
+			logging.Warnf("Failed to send group event to friend %d: %v", friendID, err)
+		}
+	}
+}
+
+// HandleControl processes a group event received from a friend and relays it
+// to the members that friend may not reach
+func (m *Manager) HandleControl(friendID uint32, payload string) {
+	ev, err := decodeEvent(payload)
+	if err != nil {
+		logging.Warnf("Ignoring group event from friend %d: %v", friendID, err)
+		return
+	}
+	publicKey, err := m.toxMgr.GetFriendPublicKey(friendID)
+	if err != nil {
+		logging.Warnf("Ignoring group event from unknown friend %d: %v", friendID, err)
+		return
+	}
+	senderKey := hex.EncodeToString(publicKey[:])
+
+	if ev.Kind == eventInvite {
+		m.handleInvite(friendID, senderKey, ev)
+		return
+	}
+	if !m.markSeen(ev.ID) {
+		return
+	}
+
+	group, err := m.GetGroup(ev.Group)
+	if err != nil || !group.IsJoined {
+		return
+	}
+	// Only members may speak for the group; a friend holding the group ID
+	// was invited and may announce its own join
+	sender, isMember := m.lookupMember(ev.Group, senderKey)
+	if (!isMember || sender.HasLeft) && !(ev.Kind == eventJoin && ev.Origin == senderKey) {
+		logging.Warnf("Ignoring group event from non-member friend %d", friendID)
+		return
+	}
+	if ev.Origin == m.selfKey() {
+		return
+	}
+
+	var relay bool
+	switch ev.Kind {
+	case eventJoin:
+		relay = m.handleJoin(ev)
+	case eventLeave:
+		relay = m.handleLeave(ev)
+	case eventMessage:
+		relay = m.handleMessage(ev)
+	}
+	if relay {
+		m.broadcast(ev, senderKey)
+	}
+}
+
+// handleInvite records an invitation from a friend who is a member of the group
+func (m *Manager) handleInvite(friendID uint32, senderKey string, ev *event) {
+	if group, err := m.GetGroup(ev.Group); err == nil && group.IsJoined {
+		return // Already in it
+	}
+
+	invite := &Invite{
+		GroupID:  ev.Group,
+		Name:     ev.GroupName,
+		FriendID: friendID,
+		Received: time.Now(),
+	}
+	var hasSender bool
+	for _, member := range ev.Members {
+		if !validKey(member.Key) {
+			continue
+		}
+		hasSender = hasSender || member.Key == senderKey
+		invite.Members = append(invite.Members, Member{
+			GroupID:   ev.Group,
+			PublicKey: member.Key,
+			Name:      member.Name,
+		})
+	}
+	if !hasSender || invite.Name == "" {
+		logging.Warnf("Ignoring invalid group invite from friend %d", friendID)
+		return
+	}
+
+	m.mu.Lock()
+	m.invites[ev.Group] = invite
+	callback := m.onInvite
+	m.mu.Unlock()
+
+	if callback != nil {
+		callback(invite)
+	}
+}
+
+// handleJoin adds a member, or marks a member who left as back
+func (m *Manager) handleJoin(ev *event) bool {
+	if existing, exists := m.lookupMember(ev.Group, ev.Origin); exists && !existing.HasLeft {
+		return true // Known already; still relay for members that are not
+	}
+
+	member := Member{
+		GroupID:   ev.Group,
+		PublicKey: ev.Origin,
+		Name:      ev.Name,
+		JoinedAt:  time.Now(),
+	}
+	if err := m.saveMember(member); err != nil {
+		logging.Warnf("%v", err)
+		return false
+	}
+
+	m.mu.RLock()
+	callback := m.onPeerJoin
+	m.mu.RUnlock()
+	if callback != nil {
+		callback(ev.Group, member)
+	}
+	return true
+}
+
+// handleLeave marks a member as gone
+func (m *Manager) handleLeave(ev *event) bool {
+	member, exists := m.lookupMember(ev.Group, ev.Origin)
+	if !exists || member.HasLeft {
+		return exists
+	}
+
+	member.HasLeft = true
+	if err := m.saveMember(member); err != nil {
+		logging.Warnf("%v", err)
+		return false
+	}
+
+	m.mu.RLock()
+	callback := m.onPeerLeave
+	m.mu.RUnlock()
+	if callback != nil {
+		callback(ev.Group, member)
+	}
+	return true
+}
+
+// handleMessage stores a message from an active member
+func (m *Manager) handleMessage(ev *event) bool {
+	member, exists := m.lookupMember(ev.Group, ev.Origin)
+	if !exists || member.HasLeft {
+		logging.Warnf("Ignoring group message from non-member %.8s", ev.Origin)
+		return false
+	}
+	if ev.Name != "" && ev.Name != member.Name {
+		member.Name = ev.Name
+		if err := m.saveMember(member); err != nil {
+			logging.Warnf("%v", err)
+		}
+	}
+
+	var count int
+	if err := m.db.QueryRow(`SELECT COUNT(*) FROM group_messages WHERE uuid = ?`, ev.ID).Scan(&count); err != nil || count > 0 {
+		return false // Seen before a restart cleared the dedupe set
+	}
+
+	msg := &Message{
+		UUID:      ev.ID,
+		GroupID:   ev.Group,
+		SenderKey: ev.Origin,
+		Content:   ev.Content,
+		Timestamp: time.Now(),
+	}
+	if err := m.saveMessage(msg); err != nil {
+		logging.Warnf("%v", err)
+		return false
+	}
+
+	m.mu.RLock()
+	callback := m.onMessage
+	m.mu.RUnlock()
+	if callback != nil {
+		callback(msg)
+	}
+	return true
+}
@@ -3,9 +3,9 @@ package core
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"fyne.io/fyne/v2/app"
+	"github.com/opd-ai/whisp/internal/logging"
 	"github.com/opd-ai/whisp/ui/adaptive"
 )
 
@@ -15,8 +15,11 @@ func (a *App) StartGUI(ctx context.Context) error {
 		return fmt.Errorf("GUI not available in headless mode")
 	}
 
-	// Create Fyne application
-	fyneApp := app.NewWithID("com.opd-ai.whisp")
+	// Use the caller's Fyne application if it runs the event loop already
+	fyneApp := a.config.GUIApp
+	if fyneApp == nil {
+		fyneApp = app.NewWithID("com.opd-ai.whisp")
+	}
 
 	// Create adaptive UI
 	ui, err := adaptive.NewUI(fyneApp, a, adaptive.DetectPlatform())
@@ -29,9 +32,15 @@ func (a *App) StartGUI(ctx context.Context) error {
 		return fmt.Errorf("failed to initialize UI: %w", err)
 	}
 
+	if a.config.GUIApp != nil {
+		logging.Infof("Starting GUI...")
+		ui.OpenMainWindow()
+		return nil
+	}
+
 	// Start UI in separate goroutine to avoid blocking
 	go func() {
-		log.Println("Starting GUI...")
+		logging.Infof("Starting GUI...")
 		ui.ShowMainWindow()
 	}()
 
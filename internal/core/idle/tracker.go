@@ -0,0 +1,49 @@
+// Package idle tracks user activity so features such as auto-away can react
+// to inactivity
+package idle
+
+import (
+	"sync"
+	"time"
+)
+
+// Tracker records when the user last interacted with the app
+type Tracker struct {
+	mu   sync.RWMutex
+	last time.Time
+}
+
+// NewTracker creates a tracker that counts the user as active now
+func NewTracker() *Tracker {
+	return &Tracker{last: time.Now()}
+}
+
+// Touch records user activity
+func (t *Tracker) Touch() {
+	t.TouchAt(time.Now())
+}
+
+// TouchAt records user activity at a given time
+func (t *Tracker) TouchAt(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if at.After(t.last) {
+		t.last = at
+	}
+}
+
+// LastActivity returns the time of the most recent activity
+func (t *Tracker) LastActivity() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.last
+}
+
+// IdleFor returns how long the user has been inactive at now
+func (t *Tracker) IdleFor(now time.Time) time.Duration {
+	idle := now.Sub(t.LastActivity())
+	if idle < 0 {
+		return 0
+	}
+	return idle
+}
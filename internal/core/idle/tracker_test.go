@@ -0,0 +1,30 @@
+package idle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrackerIdleFor(t *testing.T) {
+	tracker := NewTracker()
+	start := tracker.LastActivity()
+
+	if idle := tracker.IdleFor(start.Add(5 * time.Minute)); idle != 5*time.Minute {
+		t.Errorf("Expected 5m idle, got %v", idle)
+	}
+
+	tracker.TouchAt(start.Add(4 * time.Minute))
+	if idle := tracker.IdleFor(start.Add(5 * time.Minute)); idle != time.Minute {
+		t.Errorf("Expected 1m idle after activity, got %v", idle)
+	}
+
+	// Late-arriving older activity does not move the clock back
+	tracker.TouchAt(start.Add(time.Minute))
+	if got := tracker.LastActivity(); !got.Equal(start.Add(4 * time.Minute)) {
+		t.Errorf("Expected last activity to stay at 4m, got %v", got.Sub(start))
+	}
+
+	if idle := tracker.IdleFor(start); idle != 0 {
+		t.Errorf("Expected no idle time before the last activity, got %v", idle)
+	}
+}
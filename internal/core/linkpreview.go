@@ -0,0 +1,50 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/linkpreview"
+)
+
+// errLinkPreviewsOff is returned while link previews are turned off
+var errLinkPreviewsOff = errors.New("link previews are turned off")
+
+// LinkPreviewMode returns how previews are fetched for the links in a
+// message with a friend
+func (a *App) LinkPreviewMode(friendID uint32, outgoing bool) linkpreview.Mode {
+	var c *contact.Contact
+	if found, ok := a.contacts.GetContact(friendID); ok {
+		c, _ = found.(*contact.Contact)
+	}
+	return linkPreviewMode(a.configMgr.GetConfig().Privacy.LinkPreviews, c, outgoing)
+}
+
+// linkPreviewMode decides how previews are fetched: never while they are
+// off or for blocked contacts, automatically for our own links and those of
+// verified contacts, and only when asked for anyone else
+func linkPreviewMode(enabled bool, c *contact.Contact, outgoing bool) linkpreview.Mode {
+	switch {
+	case !enabled || c == nil || c.IsBlocked:
+		return linkpreview.ModeOff
+	case outgoing || c.IsVerified:
+		return linkpreview.ModeAuto
+	default:
+		return linkpreview.ModeManual
+	}
+}
+
+// FetchLinkPreview returns the preview of a web link, fetching it unless
+// it is cached
+func (a *App) FetchLinkPreview(rawURL string) (*linkpreview.Preview, error) {
+	if !a.configMgr.GetConfig().Privacy.LinkPreviews {
+		return nil, errLinkPreviewsOff
+	}
+	return a.linkPreviews.Fetch(rawURL)
+}
+
+// CachedLinkPreview returns a preview fetched earlier. done is false while
+// the link was never fetched or is still being fetched.
+func (a *App) CachedLinkPreview(rawURL string) (preview *linkpreview.Preview, done bool) {
+	return a.linkPreviews.Cached(rawURL)
+}
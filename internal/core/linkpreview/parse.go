@@ -0,0 +1,109 @@
+package linkpreview
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxDescriptionRunes is how much of a page description a card shows
+const maxDescriptionRunes = 300
+
+// ParseMetadata reads the title, description and image a page declares for
+// link previews. OpenGraph tags win over Twitter card tags, which win over
+// the page title and description meta tag. A relative image is resolved
+// against pageURL.
+func ParseMetadata(page []byte, pageURL *url.URL) Preview {
+	meta := make(map[string]string)
+	var title string
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(page))
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return buildPreview(meta, title, pageURL)
+		case html.TextToken:
+			if inTitle && title == "" {
+				title = string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				return buildPreview(meta, title, pageURL)
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = true
+			case atom.Body:
+				return buildPreview(meta, title, pageURL)
+			case atom.Meta:
+				if hasAttr {
+					readMeta(tokenizer, meta)
+				}
+			}
+		}
+	}
+}
+
+// readMeta records the content of a meta tag under its property or name.
+// The first tag with a key wins.
+func readMeta(tokenizer *html.Tokenizer, meta map[string]string) {
+	var key, content string
+	for {
+		name, value, more := tokenizer.TagAttr()
+		switch strings.ToLower(string(name)) {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(string(value)))
+			}
+		case "content":
+			content = string(value)
+		}
+		if !more {
+			break
+		}
+	}
+	if _, seen := meta[key]; key != "" && !seen {
+		meta[key] = content
+	}
+}
+
+// buildPreview picks the best of the collected tags
+func buildPreview(meta map[string]string, title string, pageURL *url.URL) Preview {
+	first := func(values ...string) string {
+		for _, value := range values {
+			if value = strings.Join(strings.Fields(value), " "); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	preview := Preview{
+		URL:         pageURL.String(),
+		Title:       first(meta["og:title"], meta["twitter:title"], title),
+		Description: first(meta["og:description"], meta["twitter:description"], meta["description"]),
+		SiteName:    first(meta["og:site_name"], pageURL.Hostname()),
+	}
+	if runes := []rune(preview.Description); len(runes) > maxDescriptionRunes {
+		preview.Description = strings.TrimSpace(string(runes[:maxDescriptionRunes])) + "…"
+	}
+
+	image := first(meta["og:image"], meta["og:image:url"], meta["og:image:secure_url"], meta["twitter:image"], meta["twitter:image:src"])
+	if ref, err := url.Parse(image); image != "" && err == nil {
+		resolved := pageURL.ResolveReference(ref)
+		if resolved.Scheme == "http" || resolved.Scheme == "https" {
+			preview.ImageURL = resolved.String()
+		}
+	}
+	return preview
+}
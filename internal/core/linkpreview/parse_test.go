@@ -0,0 +1,98 @@
+package linkpreview
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMetadata(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/articles/go")
+
+	tests := []struct {
+		name string
+		html string
+		want Preview
+	}{
+		{
+			name: "opengraph",
+			html: `<html><head>
+				<meta property="og:title" content="Go 1.24 released">
+				<meta property="og:description" content="What's new &amp; improved">
+				<meta property="og:image" content="https://cdn.example.com/go.png">
+				<meta property="og:site_name" content="Example News">
+				<title>Ignored</title></head><body></body></html>`,
+			want: Preview{Title: "Go 1.24 released", Description: "What's new & improved", SiteName: "Example News", ImageURL: "https://cdn.example.com/go.png"},
+		},
+		{
+			name: "twitter card fallback",
+			html: `<head><meta name="twitter:title" content="Card title">
+				<meta name="twitter:description" content="Card text">
+				<meta name="twitter:image:src" content="/img/card.jpg"></head>`,
+			want: Preview{Title: "Card title", Description: "Card text", SiteName: "example.com", ImageURL: "https://example.com/img/card.jpg"},
+		},
+		{
+			name: "title and description fallback",
+			html: `<!DOCTYPE html><HTML><HEAD><TITLE>
+				Plain   page
+				</TITLE><META NAME="Description" CONTENT="Old-style description"></HEAD></HTML>`,
+			want: Preview{Title: "Plain page", Description: "Old-style description", SiteName: "example.com"},
+		},
+		{
+			name: "opengraph wins regardless of order",
+			html: `<head><meta name="twitter:title" content="Twitter"><meta property="og:title" content="OpenGraph"></head>`,
+			want: Preview{Title: "OpenGraph", SiteName: "example.com"},
+		},
+		{
+			name: "first duplicate wins",
+			html: `<head><meta property="og:title" content="First"><meta property="og:title" content="Second"></head>`,
+			want: Preview{Title: "First", SiteName: "example.com"},
+		},
+		{
+			name: "relative image",
+			html: `<head><meta property="og:title" content="T"><meta property="og:image" content="../static/a.png"></head>`,
+			want: Preview{Title: "T", SiteName: "example.com", ImageURL: "https://example.com/static/a.png"},
+		},
+		{
+			name: "non-web image ignored",
+			html: `<head><meta property="og:title" content="T"><meta property="og:image" content="javascript:alert(1)"></head>`,
+			want: Preview{Title: "T", SiteName: "example.com"},
+		},
+		{
+			name: "tags in the body ignored",
+			html: `<head><title>Head</title></head><body><meta property="og:title" content="Body"></body>`,
+			want: Preview{Title: "Head", SiteName: "example.com"},
+		},
+		{
+			name: "nothing to show",
+			html: `<p>just text`,
+			want: Preview{SiteName: "example.com"},
+		},
+		{
+			name: "not html",
+			html: "\x89PNG\r\n\x1a\n\x00\x00",
+			want: Preview{SiteName: "example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseMetadata([]byte(tt.html), pageURL)
+			tt.want.URL = pageURL.String()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMetadata() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMetadataLongDescription(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com")
+	page := `<meta name="description" content="` + strings.Repeat("word ", 200) + `">`
+
+	got := ParseMetadata([]byte(page), pageURL)
+	if runes := []rune(got.Description); len(runes) > maxDescriptionRunes+1 || !strings.HasSuffix(got.Description, "…") {
+		t.Errorf("Expected the description cut to %d characters, got %d", maxDescriptionRunes, len(runes))
+	}
+}
@@ -0,0 +1,176 @@
+// Package linkpreview fetches the title, description and image of web
+// pages linked in messages. Fetching tells the site someone opened the
+// link, so previews are off unless the user turns them on.
+package linkpreview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // Decode GIF thumbnails
+	_ "image/jpeg" // Decode JPEG thumbnails
+	_ "image/png"  // Decode PNG thumbnails
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// Limits on what a preview downloads
+const (
+	DefaultTimeout    = 5 * time.Second
+	maxPageBytes      = 512 * 1024 // Metadata lives in the head, near the top
+	maxImageBytes     = 1024 * 1024
+	maxImageDimension = 4096
+	maxCachedPreviews = 256
+)
+
+// ErrNoPreview means the page declares nothing worth showing
+var ErrNoPreview = errors.New("page has no preview")
+
+// Mode is how previews are fetched for a message
+type Mode int
+
+const (
+	// ModeOff never fetches previews
+	ModeOff Mode = iota
+	// ModeManual fetches a preview only when the user asks
+	ModeManual
+	// ModeAuto fetches previews as messages are shown
+	ModeAuto
+)
+
+// Preview is what a link preview card shows
+type Preview struct {
+	URL         string
+	Title       string
+	Description string
+	SiteName    string
+	ImageURL    string
+	Image       []byte // Encoded thumbnail, nil if the page has none
+}
+
+// cacheEntry is a preview being fetched or fetched already
+type cacheEntry struct {
+	done    chan struct{} // Closed once preview and err are set
+	preview *Preview
+	err     error
+}
+
+// Manager fetches previews, remembering each URL's result so a page is
+// fetched at most once
+type Manager struct {
+	fetcher Fetcher
+
+	mu      sync.Mutex
+	timeout time.Duration
+	cache   map[string]*cacheEntry
+	order   []string // Cached URLs, oldest first
+}
+
+// NewManager creates a manager fetching through fetcher
+func NewManager(fetcher Fetcher, timeout time.Duration) *Manager {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Manager{
+		fetcher: fetcher,
+		timeout: timeout,
+		cache:   make(map[string]*cacheEntry),
+	}
+}
+
+// SetTimeout sets how long fetching a preview, image included, may take
+func (m *Manager) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timeout = timeout
+}
+
+// Cached returns the preview of a URL if it was fetched. done is false
+// while the URL was never fetched or is still being fetched.
+func (m *Manager) Cached(rawURL string) (preview *Preview, done bool) {
+	m.mu.Lock()
+	entry, ok := m.cache[rawURL]
+	m.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	select {
+	case <-entry.done:
+		return entry.preview, true
+	default:
+		return nil, false
+	}
+}
+
+// Fetch returns the preview of a web page, fetching it unless it is cached.
+// Callers asking for a URL being fetched wait for that fetch. Failures are
+// cached too, so a broken page is not fetched again on every redraw.
+func (m *Manager) Fetch(rawURL string) (*Preview, error) {
+	m.mu.Lock()
+	if entry, ok := m.cache[rawURL]; ok {
+		m.mu.Unlock()
+		<-entry.done
+		return entry.preview, entry.err
+	}
+	entry := &cacheEntry{done: make(chan struct{})}
+	m.cache[rawURL] = entry
+	m.order = append(m.order, rawURL)
+	if len(m.order) > maxCachedPreviews {
+		delete(m.cache, m.order[0])
+		m.order = m.order[1:]
+	}
+	timeout := m.timeout
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	entry.preview, entry.err = m.fetch(ctx, rawURL)
+	close(entry.done)
+	return entry.preview, entry.err
+}
+
+// fetch downloads a page and its image
+func (m *Manager) fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	pageURL, err := url.Parse(rawURL)
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") || pageURL.Host == "" {
+		return nil, fmt.Errorf("cannot preview %q: not a web link", rawURL)
+	}
+
+	page, err := m.fetcher.Fetch(ctx, rawURL, maxPageBytes)
+	if err != nil {
+		return nil, err
+	}
+	preview := ParseMetadata(page, pageURL)
+	if preview.Title == "" && preview.Description == "" {
+		return nil, ErrNoPreview
+	}
+
+	if preview.ImageURL != "" {
+		data, err := m.fetcher.Fetch(ctx, preview.ImageURL, maxImageBytes)
+		switch {
+		case err != nil:
+			logging.Debugf("Failed to fetch preview image for %s: %v", rawURL, err)
+		case !isThumbnail(data):
+			logging.Debugf("Preview image for %s is not a usable image", rawURL)
+		default:
+			preview.Image = data
+		}
+	}
+	return &preview, nil
+}
+
+// isThumbnail reports whether data is an image small enough to decode
+func isThumbnail(data []byte) bool {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	return err == nil && cfg.Width > 0 && cfg.Height > 0 &&
+		cfg.Width <= maxImageDimension && cfg.Height <= maxImageDimension
+}
@@ -0,0 +1,177 @@
+package linkpreview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFetcher serves canned resources and counts fetches
+type fakeFetcher struct {
+	mu        sync.Mutex
+	resources map[string][]byte
+	fetches   map[string]int
+	block     bool // Wait for the context instead of answering
+}
+
+func (f *fakeFetcher) Fetch(ctx context.Context, rawURL string, limit int64) ([]byte, error) {
+	f.mu.Lock()
+	f.fetches[rawURL]++
+	data, ok := f.resources[rawURL]
+	block := f.block
+	f.mu.Unlock()
+
+	if block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	if int64(len(data)) > limit {
+		data = data[:limit]
+	}
+	return data, nil
+}
+
+func (f *fakeFetcher) count(rawURL string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fetches[rawURL]
+}
+
+func newFakeFetcher(resources map[string][]byte) *fakeFetcher {
+	return &fakeFetcher{resources: resources, fetches: make(map[string]int)}
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestFetch(t *testing.T) {
+	fetcher := newFakeFetcher(map[string][]byte{
+		"https://example.com/post":      []byte(`<head><meta property="og:title" content="Post"><meta property="og:image" content="/thumb.png"></head>`),
+		"https://example.com/thumb.png": testPNG(t),
+		"https://example.com/broken":    []byte(`<head><meta property="og:title" content="Broken"><meta property="og:image" content="/bad.png"></head>`),
+		"https://example.com/bad.png":   []byte("not an image"),
+		"https://example.com/empty":     []byte(`<p>nothing here</p>`),
+	})
+	manager := NewManager(fetcher, time.Second)
+
+	if _, done := manager.Cached("https://example.com/post"); done {
+		t.Error("Expected nothing cached before the first fetch")
+	}
+
+	preview, err := manager.Fetch("https://example.com/post")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if preview.Title != "Post" || len(preview.Image) == 0 {
+		t.Errorf("Expected the title and thumbnail, got %+v", preview)
+	}
+
+	// Cached results are served without fetching again
+	if cached, done := manager.Cached("https://example.com/post"); !done || cached != preview {
+		t.Error("Expected the preview to be cached")
+	}
+	if _, err := manager.Fetch("https://example.com/post"); err != nil || fetcher.count("https://example.com/post") != 1 {
+		t.Errorf("Expected one fetch of a cached page, got %d", fetcher.count("https://example.com/post"))
+	}
+
+	// A bad image leaves the card without one
+	preview, err = manager.Fetch("https://example.com/broken")
+	if err != nil || preview.Image != nil {
+		t.Errorf("Expected a preview without image, got %+v, %v", preview, err)
+	}
+
+	if _, err := manager.Fetch("https://example.com/empty"); !errors.Is(err, ErrNoPreview) {
+		t.Errorf("Expected ErrNoPreview, got %v", err)
+	}
+	if _, err := manager.Fetch("ftp://example.com/file"); err == nil {
+		t.Error("Expected non-web links to be refused")
+	}
+	if fetcher.count("ftp://example.com/file") != 0 {
+		t.Error("Expected non-web links never to be fetched")
+	}
+
+	// Failures are remembered too
+	if _, err := manager.Fetch("https://example.com/missing"); err == nil {
+		t.Error("Expected a missing page to fail")
+	}
+	manager.Fetch("https://example.com/missing")
+	if fetcher.count("https://example.com/missing") != 1 {
+		t.Errorf("Expected a failed page fetched once, got %d", fetcher.count("https://example.com/missing"))
+	}
+}
+
+func TestFetchConcurrent(t *testing.T) {
+	fetcher := newFakeFetcher(map[string][]byte{
+		"https://example.com/": []byte(`<title>Home</title>`),
+	})
+	manager := NewManager(fetcher, time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if preview, err := manager.Fetch("https://example.com/"); err != nil || preview.Title != "Home" {
+				t.Errorf("Fetch = %+v, %v", preview, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := fetcher.count("https://example.com/"); got != 1 {
+		t.Errorf("Expected concurrent callers to share one fetch, got %d", got)
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	fetcher := newFakeFetcher(nil)
+	fetcher.block = true
+	manager := NewManager(fetcher, time.Second)
+	manager.SetTimeout(20 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := manager.Fetch("https://slow.example.com/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the fetch to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the timeout to cut the fetch short, took %v", elapsed)
+	}
+}
+
+func TestIsLocalIP(t *testing.T) {
+	tests := []struct {
+		ip    string
+		local bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"192.168.0.10", true},
+		{"169.254.169.254", true},
+		{"::1", true},
+		{"fd00::1", true},
+		{"0.0.0.0", true},
+		{"93.184.216.34", false},
+		{"2606:2800:220:1::", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLocalIP(net.ParseIP(tt.ip)); got != tt.local {
+			t.Errorf("isLocalIP(%s) = %v, want %v", tt.ip, got, tt.local)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/linkpreview"
+)
+
+func TestLinkPreviewMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		contact  *contact.Contact
+		outgoing bool
+		want     linkpreview.Mode
+	}{
+		{"turned off", false, &contact.Contact{IsVerified: true}, true, linkpreview.ModeOff},
+		{"our own link", true, &contact.Contact{}, true, linkpreview.ModeAuto},
+		{"verified contact", true, &contact.Contact{IsVerified: true}, false, linkpreview.ModeAuto},
+		{"unverified contact", true, &contact.Contact{}, false, linkpreview.ModeManual},
+		{"blocked contact", true, &contact.Contact{IsBlocked: true, IsVerified: true}, false, linkpreview.ModeOff},
+		{"our link to a blocked contact", true, &contact.Contact{IsBlocked: true}, true, linkpreview.ModeOff},
+		{"unknown sender", true, nil, false, linkpreview.ModeOff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linkPreviewMode(tt.enabled, tt.contact, tt.outgoing); got != tt.want {
+				t.Errorf("linkPreviewMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+)
+
+// AvatarSize is the width and height of avatars in pixels
+const AvatarSize = 128
+
+// MaxAvatarBytes is the largest avatar Tox clients accept
+const MaxAvatarBytes = 64 * 1024
+
+// MakeAvatar crops an image file to a centered square and scales it down to
+// an avatar, encoded as PNG or as JPEG if the PNG is too large to send
+func (m *Manager) MakeAvatar(imagePath string) ([]byte, error) {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open avatar image: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := m.processor.DecodeImage(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode avatar image: %w", err)
+	}
+
+	img = cropSquare(img)
+	if img.Bounds().Dx() > AvatarSize {
+		img = m.processor.ResizeImage(img, AvatarSize, AvatarSize)
+	}
+
+	for _, format := range []string{"png", "jpeg"} {
+		var buf bytes.Buffer
+		if err := m.processor.EncodeImage(&buf, img, format); err != nil {
+			return nil, fmt.Errorf("failed to encode avatar: %w", err)
+		}
+		if buf.Len() <= MaxAvatarBytes {
+			return buf.Bytes(), nil
+		}
+	}
+	return nil, fmt.Errorf("avatar is larger than %d bytes", MaxAvatarBytes)
+}
+
+// cropSquare returns the largest centered square of an image
+func cropSquare(img image.Image) image.Image {
+	bounds := img.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	if side == bounds.Dx() && side == bounds.Dy() {
+		return img
+	}
+
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), img, image.Pt(x, y), draw.Src)
+	return square
+}
@@ -0,0 +1,64 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMakeAvatar(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		wantSide      int
+	}{
+		{"large landscape", 300, 200, AvatarSize},
+		{"large portrait", 150, 400, AvatarSize},
+		{"small square", 64, 64, 64},
+		{"small landscape", 90, 60, 60},
+	}
+
+	manager := NewManager(t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+			for x := 0; x < tt.width; x++ {
+				for y := 0; y < tt.height; y++ {
+					src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff})
+				}
+			}
+			path := filepath.Join(t.TempDir(), "photo.png")
+			file, err := os.Create(path)
+			if err != nil {
+				t.Fatalf("Failed to create image: %v", err)
+			}
+			if err := png.Encode(file, src); err != nil {
+				t.Fatalf("Failed to encode image: %v", err)
+			}
+			file.Close()
+
+			avatar, err := manager.MakeAvatar(path)
+			if err != nil {
+				t.Fatalf("MakeAvatar failed: %v", err)
+			}
+			if len(avatar) > MaxAvatarBytes {
+				t.Errorf("Expected at most %d bytes, got %d", MaxAvatarBytes, len(avatar))
+			}
+			img, _, err := image.Decode(bytes.NewReader(avatar))
+			if err != nil {
+				t.Fatalf("Failed to decode avatar: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.wantSide || b.Dy() != tt.wantSide {
+				t.Errorf("Expected a %dx%d avatar, got %dx%d", tt.wantSide, tt.wantSide, b.Dx(), b.Dy())
+			}
+		})
+	}
+
+	if _, err := manager.MakeAvatar(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("Expected an error for a missing image")
+	}
+}
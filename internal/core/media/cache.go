@@ -0,0 +1,196 @@
+package media
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// sourceIndexFile is the name of the file mapping cached thumbnails to their source files
+const sourceIndexFile = "sources.json"
+
+// sourceIndexFlushDelay is how long new index entries are batched before the
+// index is written
+const sourceIndexFlushDelay = 5 * time.Second
+
+// PruneResult summarizes a thumbnail cache maintenance pass
+type PruneResult struct {
+	OrphansRemoved int   `json:"orphans_removed"` // Thumbnails whose source file is gone
+	EvictedRemoved int   `json:"evicted_removed"` // Thumbnails removed to respect the size limit
+	BytesReclaimed int64 `json:"bytes_reclaimed"`
+	BytesRemaining int64 `json:"bytes_remaining"`
+}
+
+// cacheEntry describes a thumbnail file found in the cache directory
+type cacheEntry struct {
+	name string
+	size int64
+	info os.FileInfo
+}
+
+// PruneCache removes orphaned thumbnails and, if maxBytes is positive, evicts the
+// least recently used thumbnails until the cache fits within maxBytes.
+// Thumbnails missing from the source index, such as those cached before it
+// existed, cannot be checked and are only removed by eviction.
+func (g *DefaultThumbnailGenerator) PruneCache(maxBytes int64) (*PruneResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	result := &PruneResult{}
+
+	dirEntries, err := os.ReadDir(g.cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil // Nothing cached yet
+		}
+		return nil, fmt.Errorf("failed to read thumbnail cache: %w", err)
+	}
+
+	sources := g.sourceIndex()
+
+	var remaining []cacheEntry
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || dirEntry.Name() == sourceIndexFile {
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+
+		sourcePath, known := sources[dirEntry.Name()]
+		if known && !fileExists(sourcePath) {
+			if err := os.Remove(filepath.Join(g.cacheDir, dirEntry.Name())); err != nil {
+				return nil, fmt.Errorf("failed to remove orphaned thumbnail: %w", err)
+			}
+			delete(sources, dirEntry.Name())
+			result.OrphansRemoved++
+			result.BytesReclaimed += info.Size()
+			continue
+		}
+
+		remaining = append(remaining, cacheEntry{name: dirEntry.Name(), size: info.Size(), info: info})
+		result.BytesRemaining += info.Size()
+	}
+
+	if maxBytes > 0 && result.BytesRemaining > maxBytes {
+		// Evict least recently used first
+		sort.Slice(remaining, func(i, j int) bool {
+			return remaining[i].info.ModTime().Before(remaining[j].info.ModTime())
+		})
+
+		for _, entry := range remaining {
+			if result.BytesRemaining <= maxBytes {
+				break
+			}
+			if err := os.Remove(filepath.Join(g.cacheDir, entry.name)); err != nil {
+				return nil, fmt.Errorf("failed to evict thumbnail: %w", err)
+			}
+			delete(sources, entry.name)
+			result.EvictedRemoved++
+			result.BytesReclaimed += entry.size
+			result.BytesRemaining -= entry.size
+		}
+	}
+
+	if err := g.saveSourceIndex(sources); err != nil {
+		return nil, err
+	}
+	g.flushPending = false
+
+	return result, nil
+}
+
+// recordSource remembers which source file a thumbnail was generated from.
+// The index is written shortly after, together with other new entries.
+// Must be called with g.mu held.
+func (g *DefaultThumbnailGenerator) recordSource(thumbnailPath, sourcePath string) {
+	absSource, err := filepath.Abs(sourcePath)
+	if err != nil {
+		absSource = sourcePath
+	}
+
+	g.sourceIndex()[filepath.Base(thumbnailPath)] = absSource
+
+	if !g.flushPending {
+		g.flushPending = true
+		time.AfterFunc(sourceIndexFlushDelay, g.flushSourceIndex)
+	}
+}
+
+// flushSourceIndex writes the source index once new entries have gathered.
+// A failed write only means the thumbnails are kept unchecked on the next prune.
+func (g *DefaultThumbnailGenerator) flushSourceIndex() {
+	_ = g.Flush()
+}
+
+// Flush writes the source index if it has unsaved entries, so thumbnails
+// made just before shutdown are still indexed
+func (g *DefaultThumbnailGenerator) Flush() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.flushPending {
+		return nil
+	}
+	g.flushPending = false
+
+	// Nothing to index once the cache directory was removed
+	if _, err := os.Stat(g.cacheDir); err != nil {
+		return nil
+	}
+	return g.saveSourceIndex(g.sources)
+}
+
+// sourceIndex returns the in-memory source index, loading it on first use.
+// Must be called with g.mu held.
+func (g *DefaultThumbnailGenerator) sourceIndex() map[string]string {
+	if g.sources == nil {
+		g.sources = g.loadSourceIndex()
+	}
+	return g.sources
+}
+
+// loadSourceIndex reads the thumbnail source index, returning an empty index if it is missing or corrupt
+func (g *DefaultThumbnailGenerator) loadSourceIndex() map[string]string {
+	sources := make(map[string]string)
+
+	data, err := os.ReadFile(filepath.Join(g.cacheDir, sourceIndexFile))
+	if err != nil {
+		return sources
+	}
+
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return make(map[string]string)
+	}
+
+	return sources
+}
+
+// saveSourceIndex writes the thumbnail source index to the cache directory
+func (g *DefaultThumbnailGenerator) saveSourceIndex(sources map[string]string) error {
+	if err := os.MkdirAll(g.cacheDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(sources)
+	if err != nil {
+		return fmt.Errorf("failed to encode thumbnail index: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(g.cacheDir, sourceIndexFile), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write thumbnail index: %w", err)
+	}
+
+	return nil
+}
+
+// fileExists reports whether a regular file exists at path
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
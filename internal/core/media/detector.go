@@ -84,6 +84,16 @@ func (d *DefaultMediaDetector) GetMediaInfo(filePath string) (*MediaInfo, error)
 		}
 	}
 
+	// Duration and dimensions for audio and video
+	if mediaType == MediaTypeAudio || mediaType == MediaTypeVideo {
+		meta := readMediaMetadata(filePath)
+		mediaInfo.Duration = durationSeconds(meta.Duration)
+		if mediaType == MediaTypeVideo {
+			mediaInfo.Width = meta.Width
+			mediaInfo.Height = meta.Height
+		}
+	}
+
 	return mediaInfo, nil
 }
 
@@ -112,7 +122,7 @@ func (d *DefaultMediaDetector) getMediaTypeFromExtension(ext string) MediaType {
 
 	audioExts := map[string]bool{
 		".mp3": true, ".wav": true, ".flac": true, ".aac": true,
-		".ogg": true, ".wma": true, ".m4a": true,
+		".ogg": true, ".opus": true, ".wma": true, ".m4a": true,
 	}
 
 	if imageExts[ext] {
@@ -24,12 +24,15 @@ func (m *Manager) GetMediaInfo(filePath string) (*MediaInfo, error) {
 	return m.detector.GetMediaInfo(filePath)
 }
 
-// GenerateThumbnail creates a thumbnail for a media file
+// GenerateThumbnail creates a thumbnail for a media file, waiting for a worker
+// slot if the concurrency limit is reached
 func (m *Manager) GenerateThumbnail(filePath string, maxWidth, maxHeight int) (string, error) {
 	if !m.IsMediaFile(filePath) {
 		return "", fmt.Errorf("file is not a supported media type: %s", filePath)
 	}
 
+	release := m.pool.acquire()
+	defer release()
 	return m.thumbnailGen.GenerateThumbnail(filePath, maxWidth, maxHeight)
 }
 
@@ -48,6 +51,16 @@ func (m *Manager) Cleanup() error {
 	return m.thumbnailGen.ClearCache()
 }
 
+// PruneCache removes orphaned thumbnails and enforces the cache size limit
+func (m *Manager) PruneCache(maxBytes int64) (*PruneResult, error) {
+	return m.thumbnailGen.PruneCache(maxBytes)
+}
+
+// Flush writes cache bookkeeping that is still held in memory, e.g. on shutdown
+func (m *Manager) Flush() error {
+	return m.thumbnailGen.Flush()
+}
+
 // GetCacheDir returns the cache directory path
 func (m *Manager) GetCacheDir() string {
 	return m.cacheDir
@@ -58,7 +71,11 @@ func (m *Manager) SetCacheDir(cacheDir string) {
 	m.cacheDir = cacheDir
 	// Update thumbnail generator cache dir if it supports it
 	if gen, ok := m.thumbnailGen.(*DefaultThumbnailGenerator); ok {
+		gen.mu.Lock()
 		gen.cacheDir = cacheDir
+		gen.sources = nil // Loaded again from the new directory
+		gen.flushPending = false
+		gen.mu.Unlock()
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // TestMediaTypes tests the MediaType enum and String method
@@ -316,3 +317,166 @@ func TestMediaManager(t *testing.T) {
 		}
 	})
 }
+
+// TestThumbnailCachePrune tests that cache maintenance removes only orphaned and over-limit thumbnails
+func TestThumbnailCachePrune(t *testing.T) {
+	createImage := func(t *testing.T, path string) {
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("Failed to create test image: %v", err)
+		}
+		defer file.Close()
+
+		if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 100, 100))); err != nil {
+			t.Fatalf("Failed to encode test image: %v", err)
+		}
+	}
+
+	t.Run("Removes orphans", func(t *testing.T) {
+		tempDir := t.TempDir()
+		cacheDir := filepath.Join(tempDir, "cache")
+		generator := NewDefaultThumbnailGenerator(cacheDir, NewDefaultImageProcessor())
+
+		keptSource := filepath.Join(tempDir, "kept.png")
+		goneSource := filepath.Join(tempDir, "gone.png")
+		createImage(t, keptSource)
+		createImage(t, goneSource)
+
+		keptThumb, err := generator.GenerateThumbnail(keptSource, 50, 50)
+		if err != nil {
+			t.Fatalf("Failed to generate thumbnail: %v", err)
+		}
+		goneThumb, err := generator.GenerateThumbnail(goneSource, 50, 50)
+		if err != nil {
+			t.Fatalf("Failed to generate thumbnail: %v", err)
+		}
+
+		// Remove one source and drop in a thumbnail with no recorded source
+		if err := os.Remove(goneSource); err != nil {
+			t.Fatalf("Failed to remove source: %v", err)
+		}
+		strayThumb := filepath.Join(cacheDir, "stray.jpg")
+		if err := os.WriteFile(strayThumb, []byte("stray"), 0o644); err != nil {
+			t.Fatalf("Failed to create stray thumbnail: %v", err)
+		}
+
+		result, err := generator.PruneCache(0)
+		if err != nil {
+			t.Fatalf("PruneCache failed: %v", err)
+		}
+
+		if result.OrphansRemoved != 1 {
+			t.Errorf("Expected 1 orphan removed, got %d", result.OrphansRemoved)
+		}
+		if result.EvictedRemoved != 0 {
+			t.Errorf("Expected no evictions without a limit, got %d", result.EvictedRemoved)
+		}
+		if result.BytesReclaimed == 0 {
+			t.Error("Expected reclaimed bytes to be reported")
+		}
+
+		if _, err := os.Stat(keptThumb); err != nil {
+			t.Error("Expected thumbnail with existing source to be kept")
+		}
+		if _, err := os.Stat(goneThumb); !os.IsNotExist(err) {
+			t.Error("Expected orphaned thumbnail to be removed")
+		}
+
+		// Thumbnails cached before the source index cannot be checked
+		if _, err := os.Stat(strayThumb); err != nil {
+			t.Error("Expected thumbnail without a recorded source to be kept")
+		}
+	})
+
+	t.Run("Enforces size limit", func(t *testing.T) {
+		tempDir := t.TempDir()
+		cacheDir := filepath.Join(tempDir, "cache")
+		generator := NewDefaultThumbnailGenerator(cacheDir, NewDefaultImageProcessor())
+
+		var thumbs []string
+		for i, name := range []string{"old.png", "mid.png", "new.png"} {
+			source := filepath.Join(tempDir, name)
+			createImage(t, source)
+
+			thumb, err := generator.GenerateThumbnail(source, 50, 50)
+			if err != nil {
+				t.Fatalf("Failed to generate thumbnail: %v", err)
+			}
+
+			// Spread modification times so eviction order is deterministic
+			modTime := time.Now().Add(time.Duration(i-3) * time.Hour)
+			if err := os.Chtimes(thumb, modTime, modTime); err != nil {
+				t.Fatalf("Failed to set thumbnail time: %v", err)
+			}
+			thumbs = append(thumbs, thumb)
+		}
+
+		info, err := os.Stat(thumbs[2])
+		if err != nil {
+			t.Fatalf("Failed to stat thumbnail: %v", err)
+		}
+
+		// Room for only the newest thumbnail
+		result, err := generator.PruneCache(info.Size())
+		if err != nil {
+			t.Fatalf("PruneCache failed: %v", err)
+		}
+
+		if result.OrphansRemoved != 0 {
+			t.Errorf("Expected no orphans, got %d", result.OrphansRemoved)
+		}
+		if result.EvictedRemoved != 2 {
+			t.Errorf("Expected 2 evictions, got %d", result.EvictedRemoved)
+		}
+		if result.BytesRemaining > info.Size() {
+			t.Errorf("Expected cache within %d bytes, got %d", info.Size(), result.BytesRemaining)
+		}
+
+		if _, err := os.Stat(thumbs[2]); err != nil {
+			t.Error("Expected most recent thumbnail to be kept")
+		}
+		for _, path := range thumbs[:2] {
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("Expected older thumbnail %s to be evicted", path)
+			}
+		}
+	})
+
+	t.Run("Batches index writes", func(t *testing.T) {
+		tempDir := t.TempDir()
+		cacheDir := filepath.Join(tempDir, "cache")
+		generator := NewDefaultThumbnailGenerator(cacheDir, NewDefaultImageProcessor())
+
+		var sources []string
+		for _, name := range []string{"a.png", "b.png"} {
+			source := filepath.Join(tempDir, name)
+			createImage(t, source)
+			if _, err := generator.GenerateThumbnail(source, 50, 50); err != nil {
+				t.Fatalf("Failed to generate thumbnail: %v", err)
+			}
+			sources = append(sources, source)
+		}
+
+		indexPath := filepath.Join(cacheDir, sourceIndexFile)
+		if _, err := os.Stat(indexPath); !os.IsNotExist(err) {
+			t.Fatal("Expected the index not to be written for each thumbnail")
+		}
+
+		if err := generator.Flush(); err != nil {
+			t.Fatalf("Flush failed: %v", err)
+		}
+
+		// A fresh generator reads the flushed entries and can detect orphans again
+		reloaded := NewDefaultThumbnailGenerator(cacheDir, NewDefaultImageProcessor())
+		if err := os.Remove(sources[0]); err != nil {
+			t.Fatalf("Failed to remove source: %v", err)
+		}
+		result, err := reloaded.PruneCache(0)
+		if err != nil {
+			t.Fatalf("PruneCache failed: %v", err)
+		}
+		if result.OrphansRemoved != 1 {
+			t.Errorf("Expected the flushed index to find 1 orphan, got %d", result.OrphansRemoved)
+		}
+	})
+}
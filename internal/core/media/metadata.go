@@ -0,0 +1,313 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// mediaMetadata holds the playback properties read from an audio or video file
+type mediaMetadata struct {
+	Duration time.Duration
+	Width    int
+	Height   int
+}
+
+// opusSampleRate is the fixed granule rate of Ogg Opus streams
+const opusSampleRate = 48000
+
+// maxAtomScan limits how much of an MP4 file is searched for metadata
+const maxAtomScan = 64 << 20
+
+// maxHeaderAtom caps how much of an mvhd or tkhd atom is read; the fields
+// used are well within it
+const maxHeaderAtom = 256
+
+// maxFmtChunk caps how much of a WAV fmt chunk is read; extensible format
+// chunks are 40 bytes
+const maxFmtChunk = 40
+
+// readMediaMetadata extracts duration and dimensions from audio and video files.
+// Formats that cannot be parsed yield zero values rather than an error, so a
+// preview can still be shown without the details.
+func readMediaMetadata(filePath string) mediaMetadata {
+	if meta, ok := probeExternal(filePath); ok {
+		return meta
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return mediaMetadata{}
+	}
+	defer file.Close()
+
+	var meta mediaMetadata
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".wav":
+		meta.Duration, err = wavDuration(file)
+	case ".opus", ".ogg":
+		meta.Duration, err = oggOpusDuration(file)
+	case ".mp4", ".m4v", ".m4a", ".mov":
+		meta, err = mp4Metadata(file)
+	default:
+		return mediaMetadata{}
+	}
+	if err != nil {
+		return mediaMetadata{}
+	}
+
+	return meta
+}
+
+// durationSeconds rounds a duration to whole seconds for MediaInfo
+func durationSeconds(d time.Duration) int {
+	return int(math.Round(d.Seconds()))
+}
+
+// wavDuration reads the fmt and data chunks of a RIFF/WAVE file
+func wavDuration(r io.ReadSeeker) (time.Duration, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, err
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return 0, fmt.Errorf("not a WAV file")
+	}
+
+	var byteRate uint32
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return 0, err
+		}
+		id := string(chunk[0:4])
+		size := binary.LittleEndian.Uint32(chunk[4:8])
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return 0, fmt.Errorf("invalid fmt chunk")
+			}
+			fmtChunk := make([]byte, min(size, maxFmtChunk))
+			if _, err := io.ReadFull(r, fmtChunk); err != nil {
+				return 0, err
+			}
+			if _, err := r.Seek(int64(size)-int64(len(fmtChunk)), io.SeekCurrent); err != nil {
+				return 0, err
+			}
+			byteRate = binary.LittleEndian.Uint32(fmtChunk[8:12])
+		case "data":
+			if byteRate == 0 {
+				return 0, fmt.Errorf("data chunk before fmt chunk")
+			}
+			return time.Duration(float64(size) / float64(byteRate) * float64(time.Second)), nil
+		default:
+			if _, err := r.Seek(int64(size), io.SeekCurrent); err != nil {
+				return 0, err
+			}
+		}
+
+		// Chunks are padded to an even length
+		if size%2 == 1 && id != "data" {
+			if _, err := r.Seek(1, io.SeekCurrent); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// oggOpusDuration uses the granule position of the last Ogg page, minus the
+// encoder pre-skip from the OpusHead packet
+func oggOpusDuration(r io.ReadSeeker) (time.Duration, error) {
+	var page [27]byte
+	if _, err := io.ReadFull(r, page[:]); err != nil {
+		return 0, fmt.Errorf("not an Ogg Opus file")
+	}
+	if string(page[0:4]) != "OggS" {
+		return 0, fmt.Errorf("not an Ogg file")
+	}
+
+	// The first page carries a single OpusHead packet after the segment table;
+	// its length is the sum of the lacing values up to the first short one
+	segments := make([]byte, page[26])
+	if _, err := io.ReadFull(r, segments); err != nil {
+		return 0, fmt.Errorf("not an Ogg Opus file")
+	}
+	packetLen := 0
+	for _, lacing := range segments {
+		packetLen += int(lacing)
+		if lacing < 255 {
+			break
+		}
+	}
+
+	var head [12]byte
+	if packetLen < len(head) {
+		return 0, fmt.Errorf("not an Opus stream")
+	}
+	if _, err := io.ReadFull(r, head[:]); err != nil || string(head[0:8]) != "OpusHead" {
+		return 0, fmt.Errorf("not an Opus stream")
+	}
+	preSkip := int64(binary.LittleEndian.Uint16(head[10:12]))
+
+	// Find the last page header near the end of the file
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	tail := int64(65307) // Maximum Ogg page size
+	if tail > size {
+		tail = size
+	}
+	if _, err := r.Seek(size-tail, io.SeekStart); err != nil {
+		return 0, err
+	}
+	buf := make([]byte, tail)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return 0, err
+	}
+
+	last := bytes.LastIndex(buf, []byte("OggS"))
+	if last < 0 || last+14 > len(buf) {
+		return 0, fmt.Errorf("no Ogg page found")
+	}
+	granule := int64(binary.LittleEndian.Uint64(buf[last+6 : last+14]))
+	if granule <= preSkip {
+		return 0, nil
+	}
+
+	samples := granule - preSkip
+	return time.Duration(samples) * time.Second / opusSampleRate, nil
+}
+
+// mp4Metadata reads the movie header (duration) and the first track header
+// with a picture size (dimensions) from an ISO base media file
+func mp4Metadata(r io.ReadSeeker) (mediaMetadata, error) {
+	var meta mediaMetadata
+	found := false
+
+	err := walkAtoms(r, 0, maxAtomScan, func(kind string, body []byte) {
+		switch kind {
+		case "mvhd":
+			if d, ok := parseMvhd(body); ok {
+				meta.Duration = d
+				found = true
+			}
+		case "tkhd":
+			if meta.Width == 0 && meta.Height == 0 {
+				meta.Width, meta.Height = parseTkhd(body)
+			}
+		}
+	})
+	if err != nil && !found {
+		return mediaMetadata{}, err
+	}
+	if !found {
+		return mediaMetadata{}, fmt.Errorf("no movie header found")
+	}
+
+	return meta, nil
+}
+
+// containerAtoms are descended into while looking for headers
+var containerAtoms = map[string]bool{"moov": true, "trak": true}
+
+// walkAtoms visits atoms in [offset, end), reading header atoms and recursing into containers
+func walkAtoms(r io.ReadSeeker, offset, end int64, visit func(kind string, body []byte)) error {
+	for offset+8 <= end {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[0:4]))
+		kind := string(header[4:8])
+		headerLen := int64(8)
+
+		switch size {
+		case 0: // Atom extends to the end of the file
+			size = end - offset
+		case 1: // 64-bit size follows
+			var large [8]byte
+			if _, err := io.ReadFull(r, large[:]); err != nil {
+				return err
+			}
+			size = int64(binary.BigEndian.Uint64(large[:]))
+			headerLen = 16
+		}
+		if size < headerLen || size > end-offset {
+			return fmt.Errorf("invalid atom size")
+		}
+
+		switch {
+		case containerAtoms[kind]:
+			if err := walkAtoms(r, offset+headerLen, offset+size, visit); err != nil {
+				return err
+			}
+		case kind == "mvhd" || kind == "tkhd":
+			body := make([]byte, min(size-headerLen, maxHeaderAtom))
+			if _, err := io.ReadFull(r, body); err != nil {
+				return err
+			}
+			visit(kind, body)
+		}
+
+		// Other atoms are skipped by seeking to the next one
+		offset += size
+	}
+
+	return nil
+}
+
+// parseMvhd returns the movie duration from an mvhd atom body
+func parseMvhd(body []byte) (time.Duration, bool) {
+	if len(body) < 20 {
+		return 0, false
+	}
+
+	var timescale uint32
+	var duration uint64
+	if body[0] == 1 { // Version 1 uses 64-bit times
+		if len(body) < 32 {
+			return 0, false
+		}
+		timescale = binary.BigEndian.Uint32(body[20:24])
+		duration = binary.BigEndian.Uint64(body[24:32])
+	} else {
+		timescale = binary.BigEndian.Uint32(body[12:16])
+		duration = uint64(binary.BigEndian.Uint32(body[16:20]))
+	}
+	if timescale == 0 {
+		return 0, false
+	}
+
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), true
+}
+
+// parseTkhd returns the track's presentation size from a tkhd atom body
+func parseTkhd(body []byte) (int, int) {
+	// Width and height are the last 8 bytes, as 16.16 fixed point
+	minLen := 84
+	if len(body) > 0 && body[0] == 1 {
+		minLen = 96
+	}
+	if len(body) < minLen {
+		return 0, 0
+	}
+
+	width := binary.BigEndian.Uint32(body[minLen-8 : minLen-4])
+	height := binary.BigEndian.Uint32(body[minLen-4 : minLen])
+	return int(width >> 16), int(height >> 16)
+}
@@ -0,0 +1,294 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestWAV writes a silent 16-bit mono WAV of the given length, with an
+// extra LIST chunk before the data to exercise chunk skipping
+func writeTestWAV(t *testing.T, path string, sampleRate int, duration time.Duration) {
+	t.Helper()
+
+	const bytesPerSample = 2
+	dataSize := int(duration.Seconds() * float64(sampleRate) * bytesPerSample)
+	list := []byte("INFOISFT\x05\x00\x00\x00whisp\x00") // Odd-sized payload, padded
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+8+16+8+len(list)+8+dataSize))
+	buf.WriteString("WAVE")
+
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(1)) // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(1)) // Mono
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*bytesPerSample))
+	binary.Write(&buf, binary.LittleEndian, uint16(bytesPerSample))
+	binary.Write(&buf, binary.LittleEndian, uint16(16))
+
+	buf.WriteString("LIST")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(list)-1))
+	buf.Write(list)
+
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+	buf.Write(make([]byte, dataSize))
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write WAV: %v", err)
+	}
+}
+
+// oggPage builds an Ogg page carrying a single packet (CRC is not checked by the parser)
+func oggPage(granule uint64, packet []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("OggS")
+	buf.WriteByte(0) // Version
+	buf.WriteByte(0) // Header type
+	binary.Write(&buf, binary.LittleEndian, granule)
+	binary.Write(&buf, binary.LittleEndian, uint32(1)) // Serial
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // Sequence
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // CRC
+	buf.WriteByte(1)
+	buf.WriteByte(byte(len(packet)))
+	buf.Write(packet)
+	return buf.Bytes()
+}
+
+// writeTestOpus writes an Ogg Opus file whose last page ends after duration
+func writeTestOpus(t *testing.T, path string, duration time.Duration) {
+	t.Helper()
+
+	const preSkip = 312
+	head := []byte("OpusHead")
+	head = append(head, 1, 1) // Version, channels
+	head = binary.LittleEndian.AppendUint16(head, preSkip)
+	head = binary.LittleEndian.AppendUint32(head, 48000)
+	head = append(head, 0, 0, 0)
+
+	samples := uint64(duration.Seconds() * opusSampleRate)
+
+	var buf bytes.Buffer
+	buf.Write(oggPage(0, head))
+	buf.Write(oggPage(0, []byte("OpusTags")))
+	buf.Write(oggPage(preSkip+samples/2, make([]byte, 40)))
+	buf.Write(oggPage(preSkip+samples, make([]byte, 40)))
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write Opus: %v", err)
+	}
+}
+
+// atom builds an MP4 atom
+func atom(kind string, body ...[]byte) []byte {
+	payload := bytes.Join(body, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(payload)))
+	out = append(out, kind...)
+	return append(out, payload...)
+}
+
+// writeTestMP4 writes a minimal MP4 with a movie header and one video track header
+func writeTestMP4(t *testing.T, path string, duration time.Duration, width, height int) {
+	t.Helper()
+
+	const timescale = 1000
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:16], timescale)
+	binary.BigEndian.PutUint32(mvhd[16:20], uint32(duration.Milliseconds()))
+
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:80], uint32(width)<<16)
+	binary.BigEndian.PutUint32(tkhd[80:84], uint32(height)<<16)
+
+	// An audio track without a picture size comes first
+	audioTkhd := make([]byte, 84)
+
+	data := bytes.Join([][]byte{
+		atom("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41")),
+		atom("mdat", make([]byte, 256)),
+		atom("moov",
+			atom("mvhd", mvhd),
+			atom("trak", atom("tkhd", audioTkhd)),
+			atom("trak", atom("tkhd", tkhd)),
+		),
+	}, nil)
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("Failed to write MP4: %v", err)
+	}
+}
+
+func TestWAVDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "voice.wav")
+	writeTestWAV(t, path, 8000, 3*time.Second)
+
+	meta := readMediaMetadata(path)
+	if meta.Duration != 3*time.Second {
+		t.Errorf("Expected 3s, got %v", meta.Duration)
+	}
+
+	info, err := NewDefaultMediaDetector().GetMediaInfo(path)
+	if err != nil {
+		t.Fatalf("GetMediaInfo failed: %v", err)
+	}
+	if info.Type != MediaTypeAudio || info.Duration != 3 {
+		t.Errorf("Expected 3 second audio, got %+v", info)
+	}
+}
+
+func TestOpusDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "voice.opus")
+	writeTestOpus(t, path, 2*time.Second)
+
+	meta := readMediaMetadata(path)
+	if meta.Duration != 2*time.Second {
+		t.Errorf("Expected 2s, got %v", meta.Duration)
+	}
+}
+
+func TestMP4Metadata(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clip.mp4")
+	writeTestMP4(t, path, 4*time.Second, 640, 480)
+
+	info, err := NewDefaultMediaDetector().GetMediaInfo(path)
+	if err != nil {
+		t.Fatalf("GetMediaInfo failed: %v", err)
+	}
+	if info.Duration != 4 || info.Width != 640 || info.Height != 480 {
+		t.Errorf("Expected 4s 640x480, got %+v", info)
+	}
+}
+
+func TestUnparseableMediaReturnsZeroes(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"broken.wav", "broken.opus", "broken.mp4", "empty.mov"} {
+		path := filepath.Join(dir, name)
+		content := []byte("definitely not media")
+		if name == "empty.mov" {
+			content = nil
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+
+		info, err := NewDefaultMediaDetector().GetMediaInfo(path)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", name, err)
+			continue
+		}
+		if info.Duration != 0 || info.Width != 0 || info.Height != 0 {
+			t.Errorf("%s: expected zeroed metadata, got %+v", name, info)
+		}
+	}
+}
+
+// oggHeadPage builds a first Ogg page whose OpusHead packet is followed by
+// extra segments, as muxers that pack several packets into a page produce
+func oggHeadPage(segments int, tail []byte) []byte {
+	head := []byte("OpusHead")
+	head = append(head, 1, 1)
+	head = binary.LittleEndian.AppendUint16(head, 312)
+	head = binary.LittleEndian.AppendUint32(head, 48000)
+	head = append(head, 0, 0, 0)
+
+	var buf bytes.Buffer
+	buf.WriteString("OggS")
+	buf.Write(make([]byte, 22)) // Version through CRC
+	buf.WriteByte(byte(segments))
+	buf.WriteByte(byte(len(head)))
+	for i := 1; i < segments; i++ {
+		buf.WriteByte(1)
+	}
+	buf.Write(head)
+	buf.Write(tail)
+	return buf.Bytes()
+}
+
+func TestOpusDurationManySegments(t *testing.T) {
+	data := append(oggHeadPage(30, make([]byte, 29)), oggPage(312+48000, make([]byte, 40))...)
+
+	d, err := oggOpusDuration(bytes.NewReader(data))
+	if err != nil || d != time.Second {
+		t.Errorf("Expected 1s from a page with 30 segments, got %v (%v)", d, err)
+	}
+}
+
+func TestMalformedMediaMetadata(t *testing.T) {
+	// A WAV whose fmt chunk claims almost 4 GiB
+	hugeFmt := []byte("RIFF\x00\x00\x00\x00WAVEfmt \xf0\xff\xff\xff")
+	hugeFmt = append(hugeFmt, make([]byte, 16)...)
+
+	// Ogg pages whose segment table runs past the end of the file
+	shortOgg := append([]byte("OggS"), make([]byte, 22)...)
+	shortOgg = append(shortOgg, 255)
+	shortOgg = append(shortOgg, bytes.Repeat([]byte{255}, 30)...)
+
+	// An MP4 atom with a 64-bit size far beyond the file
+	largeAtom := append(binary.BigEndian.AppendUint32(nil, 1), "mvhd"...)
+	largeAtom = binary.BigEndian.AppendUint64(largeAtom, 1<<62)
+	largeAtom = append(largeAtom, make([]byte, 32)...)
+
+	// An mvhd that claims more bytes than its container holds
+	overrun := atom("moov", append(binary.BigEndian.AppendUint32(nil, 0xfffffff0), "mvhd"...))
+
+	tests := []struct {
+		name  string
+		parse func(io.ReadSeeker) error
+		data  []byte
+	}{
+		{"wav huge fmt chunk", wavParser, hugeFmt},
+		{"wav truncated", wavParser, []byte("RIFF\x00\x00\x00\x00WAVEfmt ")},
+		{"ogg short segment table", oggParser, shortOgg},
+		{"ogg head page cut short", oggParser, oggHeadPage(255, nil)[:200]},
+		{"ogg empty", oggParser, nil},
+		{"mp4 huge 64-bit atom", mp4Parser, largeAtom},
+		{"mp4 atom overruns container", mp4Parser, overrun},
+		{"mp4 truncated 64-bit size", mp4Parser, append(binary.BigEndian.AppendUint32(nil, 1), "moov"...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.parse(bytes.NewReader(tt.data)); err == nil {
+				t.Error("Expected malformed input to be rejected")
+			}
+		})
+	}
+}
+
+func wavParser(r io.ReadSeeker) error {
+	_, err := wavDuration(r)
+	return err
+}
+
+func oggParser(r io.ReadSeeker) error {
+	_, err := oggOpusDuration(r)
+	return err
+}
+
+func mp4Parser(r io.ReadSeeker) error {
+	_, err := mp4Metadata(r)
+	return err
+}
+
+// FuzzMediaMetadata checks that no input makes the parsers panic or allocate
+// based on sizes read from the file
+func FuzzMediaMetadata(f *testing.F) {
+	f.Add(oggHeadPage(30, make([]byte, 29)))
+	f.Add(append(oggHeadPage(1, nil), oggPage(48312, nil)...))
+	f.Add([]byte("RIFF\x00\x00\x00\x00WAVEfmt \x10\x00\x00\x00"))
+	f.Add(atom("moov", atom("mvhd", make([]byte, 100)), atom("trak", atom("tkhd", make([]byte, 84)))))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		wavParser(bytes.NewReader(data))
+		oggParser(bytes.NewReader(data))
+		mp4Parser(bytes.NewReader(data))
+	})
+}
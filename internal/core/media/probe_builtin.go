@@ -0,0 +1,9 @@
+//go:build !ffprobe
+
+package media
+
+// probeExternal is a no-op unless built with the ffprobe tag; the built-in
+// parsers are used instead
+func probeExternal(filePath string) (mediaMetadata, bool) {
+	return mediaMetadata{}, false
+}
@@ -0,0 +1,63 @@
+//go:build ffprobe
+
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// ffprobeTimeout bounds how long a single ffprobe run may take
+const ffprobeTimeout = 5 * time.Second
+
+// ffprobeOutput is the subset of `ffprobe -print_format json` that we use
+type ffprobeOutput struct {
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"streams"`
+}
+
+// probeExternal reads metadata with ffprobe, which handles far more containers
+// than the built-in parsers. It reports false if ffprobe is missing or fails.
+func probeExternal(filePath string) (mediaMetadata, bool) {
+	path, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return mediaMetadata{}, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ffprobeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path,
+		"-v", "error", "-print_format", "json", "-show_format", "-show_streams", filePath,
+	).Output()
+	if err != nil {
+		return mediaMetadata{}, false
+	}
+
+	var probe ffprobeOutput
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return mediaMetadata{}, false
+	}
+
+	var meta mediaMetadata
+	if seconds, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		meta.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	for _, stream := range probe.Streams {
+		if stream.CodecType == "video" {
+			meta.Width, meta.Height = stream.Width, stream.Height
+			break
+		}
+	}
+
+	return meta, true
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 )
 
 // DefaultThumbnailGenerator implements ThumbnailGenerator with file-based caching
@@ -13,6 +14,18 @@ type DefaultThumbnailGenerator struct {
 	cacheDir  string
 	processor ImageProcessor
 	mu        sync.RWMutex
+	inflight  map[string]*thumbnailCall // Thumbnail path -> generation in progress
+
+	sources      map[string]string // Thumbnail name -> source path, loaded on first use
+	flushPending bool              // A write of the source index is scheduled
+}
+
+// thumbnailCall is a thumbnail being generated; callers asking for the same
+// thumbnail meanwhile wait for it instead of writing the file twice
+type thumbnailCall struct {
+	done chan struct{}
+	path string
+	err  error
 }
 
 // NewDefaultThumbnailGenerator creates a new thumbnail generator
@@ -20,19 +33,52 @@ func NewDefaultThumbnailGenerator(cacheDir string, processor ImageProcessor) *De
 	return &DefaultThumbnailGenerator{
 		cacheDir:  cacheDir,
 		processor: processor,
+		inflight:  make(map[string]*thumbnailCall),
 	}
 }
 
-// GenerateThumbnail creates a thumbnail for the given file and returns the thumbnail path
+// GenerateThumbnail creates a thumbnail for the given file and returns the
+// thumbnail path. Different thumbnails can be generated concurrently.
 func (g *DefaultThumbnailGenerator) GenerateThumbnail(filePath string, maxWidth, maxHeight int) (string, error) {
+	key := g.getThumbnailPath(filePath, maxWidth, maxHeight)
+
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	// Wait for a generation already in progress; its file may be incomplete
+	if call, ok := g.inflight[key]; ok {
+		g.mu.Unlock()
+		<-call.done
+		return call.path, call.err
+	}
 
 	// Check if cached thumbnail exists
 	if thumbnailPath, exists := g.getCachedThumbnailPath(filePath, maxWidth, maxHeight); exists {
+		g.mu.Unlock()
 		return thumbnailPath, nil
 	}
 
+	if g.inflight == nil {
+		g.inflight = make(map[string]*thumbnailCall)
+	}
+	call := &thumbnailCall{done: make(chan struct{})}
+	g.inflight[key] = call
+	g.mu.Unlock()
+
+	call.path, call.err = g.generateThumbnail(filePath, maxWidth, maxHeight)
+
+	g.mu.Lock()
+	delete(g.inflight, key)
+	if call.err == nil {
+		// Remember the source so cache maintenance can detect orphans
+		g.recordSource(call.path, filePath)
+	}
+	g.mu.Unlock()
+	close(call.done)
+
+	return call.path, call.err
+}
+
+// generateThumbnail writes a new thumbnail for the given file
+func (g *DefaultThumbnailGenerator) generateThumbnail(filePath string, maxWidth, maxHeight int) (string, error) {
 	// Determine media type
 	detector := NewDefaultMediaDetector()
 	mediaType, err := detector.DetectMediaType(filePath)
@@ -41,14 +87,20 @@ func (g *DefaultThumbnailGenerator) GenerateThumbnail(filePath string, maxWidth,
 	}
 
 	// Generate thumbnail based on media type
+	var thumbnailPath string
 	switch mediaType {
 	case MediaTypeImage:
-		return g.generateImageThumbnail(filePath, maxWidth, maxHeight)
+		thumbnailPath, err = g.generateImageThumbnail(filePath, maxWidth, maxHeight)
 	case MediaTypeVideo:
-		return g.GenerateVideoThumbnail(filePath, maxWidth, maxHeight)
+		thumbnailPath, err = g.GenerateVideoThumbnail(filePath, maxWidth, maxHeight)
 	default:
 		return "", fmt.Errorf("unsupported media type for thumbnail: %s", mediaType)
 	}
+	if err != nil {
+		return "", err
+	}
+
+	return thumbnailPath, nil
 }
 
 // GenerateVideoThumbnail creates a thumbnail for video files
@@ -74,6 +126,10 @@ func (g *DefaultThumbnailGenerator) ClearCache() error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	// The index goes with the thumbnails it describes
+	g.sources = nil
+	g.flushPending = false
+
 	if _, err := os.Stat(g.cacheDir); os.IsNotExist(err) {
 		return nil // Cache directory doesn't exist, nothing to clear
 	}
@@ -135,6 +191,9 @@ func (g *DefaultThumbnailGenerator) getCachedThumbnailPath(filePath string, maxW
 
 	// Check if thumbnail file exists
 	if _, err := os.Stat(thumbnailPath); err == nil {
+		// Refresh the modification time so cache pruning evicts least recently used thumbnails first
+		now := time.Now()
+		os.Chtimes(thumbnailPath, now, now)
 		return thumbnailPath, true
 	}
 
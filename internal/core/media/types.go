@@ -56,6 +56,12 @@ type ThumbnailGenerator interface {
 
 	// ClearCache removes all cached thumbnails
 	ClearCache() error
+
+	// PruneCache removes orphaned thumbnails and evicts old ones beyond maxBytes
+	PruneCache(maxBytes int64) (*PruneResult, error)
+
+	// Flush writes cache bookkeeping that is still held in memory
+	Flush() error
 }
 
 // MediaDetector detects media file types and properties
@@ -91,6 +97,7 @@ type Manager struct {
 	detector     MediaDetector
 	processor    ImageProcessor
 	cacheDir     string
+	pool         thumbnailPool // Bounds concurrent thumbnail generation
 }
 
 // ManagerInterface defines the public interface for the media manager
@@ -109,4 +116,13 @@ type ManagerInterface interface {
 
 	// Cleanup removes cached thumbnails
 	Cleanup() error
+
+	// PruneCache removes orphaned thumbnails and enforces the cache size limit
+	PruneCache(maxBytes int64) (*PruneResult, error)
+
+	// Flush writes cache bookkeeping that is still held in memory
+	Flush() error
+
+	// MakeAvatar scales an image file down to an avatar
+	MakeAvatar(imagePath string) ([]byte, error)
 }
@@ -0,0 +1,77 @@
+package media
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultThumbnailConcurrency is how many thumbnails are generated at once
+// unless configured otherwise
+const DefaultThumbnailConcurrency = 4
+
+// ThumbnailResult is the outcome of generating one thumbnail in a batch
+type ThumbnailResult struct {
+	FilePath      string // Source file
+	ThumbnailPath string // Empty if generation failed
+	Err           error
+}
+
+// thumbnailPool bounds how many thumbnails are generated concurrently
+type thumbnailPool struct {
+	mu    sync.Mutex
+	slots chan struct{}
+}
+
+// acquire blocks until a slot is free and returns the function releasing it
+func (p *thumbnailPool) acquire() func() {
+	p.mu.Lock()
+	if p.slots == nil {
+		p.slots = make(chan struct{}, DefaultThumbnailConcurrency)
+	}
+	slots := p.slots
+	p.mu.Unlock()
+
+	slots <- struct{}{}
+	return func() { <-slots }
+}
+
+// setLimit changes the concurrency limit. Generations already running keep
+// their slots in the old pool until they finish.
+func (p *thumbnailPool) setLimit(limit int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.slots = make(chan struct{}, limit)
+}
+
+// SetThumbnailConcurrency limits how many thumbnails are generated at once.
+// Non-positive values restore the default.
+func (m *Manager) SetThumbnailConcurrency(limit int) {
+	if limit <= 0 {
+		limit = DefaultThumbnailConcurrency
+	}
+	m.pool.setLimit(limit)
+}
+
+// GenerateThumbnails creates thumbnails for several files in parallel, within
+// the concurrency limit. Results are in the order of paths; a file that fails
+// only sets the Err of its own result.
+func (m *Manager) GenerateThumbnails(paths []string, maxWidth, maxHeight int) ([]ThumbnailResult, error) {
+	if maxWidth <= 0 || maxHeight <= 0 {
+		return nil, fmt.Errorf("invalid thumbnail size: %dx%d", maxWidth, maxHeight)
+	}
+
+	results := make([]ThumbnailResult, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		results[i].FilePath = path
+
+		wg.Add(1)
+		go func(result *ThumbnailResult) {
+			defer wg.Done()
+			result.ThumbnailPath, result.Err = m.GenerateThumbnail(result.FilePath, maxWidth, maxHeight)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results, nil
+}
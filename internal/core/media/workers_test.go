@@ -0,0 +1,134 @@
+package media
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingProcessor records how many thumbnails are being created at once
+type countingProcessor struct {
+	*DefaultImageProcessor
+
+	mu      sync.Mutex
+	active  int
+	peak    int
+	created int
+}
+
+func (p *countingProcessor) CreateThumbnail(sourcePath, outputPath string, maxWidth, maxHeight int) error {
+	p.mu.Lock()
+	p.active++
+	p.created++
+	if p.active > p.peak {
+		p.peak = p.active
+	}
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.active--
+		p.mu.Unlock()
+	}()
+
+	time.Sleep(10 * time.Millisecond) // Hold the slot long enough to overlap
+	return p.DefaultImageProcessor.CreateThumbnail(sourcePath, outputPath, maxWidth, maxHeight)
+}
+
+// writeTestPNG writes a small PNG image
+func writeTestPNG(t *testing.T, path string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 80, 60))); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+}
+
+func TestGenerateThumbnailsRespectsConcurrencyLimit(t *testing.T) {
+	tempDir := t.TempDir()
+	cacheDir := filepath.Join(tempDir, "cache")
+	processor := &countingProcessor{DefaultImageProcessor: NewDefaultImageProcessor()}
+
+	mgr := NewManager(cacheDir)
+	mgr.thumbnailGen = NewDefaultThumbnailGenerator(cacheDir, processor)
+	const limit = 3
+	mgr.SetThumbnailConcurrency(limit)
+
+	var paths []string
+	for i := 0; i < 20; i++ {
+		path := filepath.Join(tempDir, fmt.Sprintf("image%02d.png", i))
+		writeTestPNG(t, path)
+		paths = append(paths, path)
+	}
+	paths = append(paths, paths[0]) // A duplicate is only generated once
+
+	results, err := mgr.GenerateThumbnails(paths, 32, 32)
+	if err != nil {
+		t.Fatalf("GenerateThumbnails failed: %v", err)
+	}
+
+	if len(results) != len(paths) {
+		t.Fatalf("Expected %d results, got %d", len(paths), len(results))
+	}
+	for i, result := range results {
+		if result.FilePath != paths[i] {
+			t.Errorf("Result %d is for %s, expected %s", i, result.FilePath, paths[i])
+		}
+		if result.Err != nil {
+			t.Errorf("Thumbnail for %s failed: %v", result.FilePath, result.Err)
+			continue
+		}
+		if _, err := os.Stat(result.ThumbnailPath); err != nil {
+			t.Errorf("Thumbnail for %s missing: %v", result.FilePath, err)
+		}
+	}
+	if results[0].ThumbnailPath != results[len(results)-1].ThumbnailPath {
+		t.Error("Expected duplicate paths to share a thumbnail")
+	}
+
+	if processor.peak > limit {
+		t.Errorf("Expected at most %d concurrent thumbnails, saw %d", limit, processor.peak)
+	}
+	if processor.peak < 2 {
+		t.Errorf("Expected thumbnails to be generated in parallel, peak was %d", processor.peak)
+	}
+	if processor.created != 20 {
+		t.Errorf("Expected 20 thumbnails created, got %d", processor.created)
+	}
+}
+
+func TestGenerateThumbnailsReportsFailures(t *testing.T) {
+	tempDir := t.TempDir()
+	mgr := NewManager(filepath.Join(tempDir, "cache"))
+
+	good := filepath.Join(tempDir, "good.png")
+	writeTestPNG(t, good)
+	notMedia := filepath.Join(tempDir, "notes.txt")
+	if err := os.WriteFile(notMedia, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	results, err := mgr.GenerateThumbnails([]string{notMedia, good}, 32, 32)
+	if err != nil {
+		t.Fatalf("GenerateThumbnails failed: %v", err)
+	}
+	if results[0].Err == nil || results[0].ThumbnailPath != "" {
+		t.Errorf("Expected the text file to fail, got %+v", results[0])
+	}
+	if results[1].Err != nil {
+		t.Errorf("Expected the image to succeed despite the other failure: %v", results[1].Err)
+	}
+
+	if _, err := mgr.GenerateThumbnails([]string{good}, 0, 32); err == nil {
+		t.Error("Expected error for an invalid size")
+	}
+}
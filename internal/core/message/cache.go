@@ -0,0 +1,228 @@
+package message
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultMessageCacheSize is how many messages are cached until SetCacheSize
+// is called
+const DefaultMessageCacheSize = 1000
+
+// cachePage identifies one GetMessages result within a conversation
+type cachePage struct {
+	limit  int
+	offset int
+}
+
+// cachedConversation holds the loaded pages of one conversation
+type cachedConversation struct {
+	friendID uint32
+	pages    map[cachePage][]*Message
+	count    int // Messages across all pages
+}
+
+// messageCache keeps recently loaded conversation pages in memory, evicting
+// the least recently used conversations to stay within its size in messages
+type messageCache struct {
+	mu            sync.Mutex
+	size          int // 0 disables the cache
+	count         int
+	order         *list.List // Most recently used conversation first
+	conversations map[uint32]*list.Element
+	version       uint64 // Bumped by every invalidation
+	hits          uint64
+	misses        uint64
+}
+
+// newMessageCache creates a cache holding up to size messages
+func newMessageCache(size int) *messageCache {
+	return &messageCache{
+		size:          size,
+		order:         list.New(),
+		conversations: make(map[uint32]*list.Element),
+	}
+}
+
+// snapshot returns the version a load must still see for its result to be
+// cached; a write in between makes the result stale
+func (c *messageCache) snapshot() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// get returns a cached page, or false if it is not cached or holds a
+// message that has since expired
+func (c *messageCache) get(friendID uint32, limit, offset int, now time.Time) ([]*Message, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	page, ok := c.lookupLocked(friendID, cachePage{limit, offset}, now)
+	if c.size > 0 {
+		if ok {
+			c.hits++
+		} else {
+			c.misses++
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	return copyMessages(page), true
+}
+
+// lookupLocked finds a cached page and marks its conversation recently
+// used. The caller must hold c.mu.
+func (c *messageCache) lookupLocked(friendID uint32, key cachePage, now time.Time) ([]*Message, bool) {
+	elem, ok := c.conversations[friendID]
+	if !ok {
+		return nil, false
+	}
+	conv := elem.Value.(*cachedConversation)
+	page, ok := conv.pages[key]
+	if !ok {
+		return nil, false
+	}
+	for _, msg := range page {
+		if msg.ExpiresAt != nil && !msg.ExpiresAt.After(now) {
+			// The page shifts once a message disappears, so load it afresh
+			c.removeLocked(elem)
+			return nil, false
+		}
+	}
+
+	c.order.MoveToFront(elem)
+	return page, true
+}
+
+// stats returns how many loads the cache served and how many it missed
+func (c *messageCache) stats() (hits, misses uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// put caches a page loaded at version, unless the conversation changed since
+func (c *messageCache) put(friendID uint32, limit, offset int, messages []*Message, version uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size <= 0 || len(messages) > c.size || version != c.version {
+		return
+	}
+
+	elem, ok := c.conversations[friendID]
+	if !ok {
+		elem = c.order.PushFront(&cachedConversation{friendID: friendID, pages: make(map[cachePage][]*Message)})
+		c.conversations[friendID] = elem
+	}
+	c.order.MoveToFront(elem)
+
+	conv := elem.Value.(*cachedConversation)
+	key := cachePage{limit, offset}
+	if old, ok := conv.pages[key]; ok {
+		conv.count -= len(old)
+		c.count -= len(old)
+	}
+	conv.pages[key] = copyMessages(messages)
+	conv.count += len(messages)
+	c.count += len(messages)
+
+	c.evictLocked(elem, key)
+}
+
+// invalidate drops a conversation after its messages changed
+func (c *messageCache) invalidate(friendID uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	if elem, ok := c.conversations[friendID]; ok {
+		c.removeLocked(elem)
+	}
+}
+
+// clear drops every conversation
+func (c *messageCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	c.count = 0
+	c.order.Init()
+	c.conversations = make(map[uint32]*list.Element)
+}
+
+// setSize changes how many messages are kept, evicting to fit
+func (c *messageCache) setSize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.size = size
+	c.evictLocked(nil, cachePage{})
+}
+
+// len returns the number of cached messages
+func (c *messageCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+// evictLocked drops the least recently used conversations until the cache
+// fits. The page just stored in keep survives, though the rest of its
+// conversation may go. The caller must hold c.mu.
+func (c *messageCache) evictLocked(keep *list.Element, keepPage cachePage) {
+	for c.count > c.size && c.order.Len() > 0 {
+		back := c.order.Back()
+		if back != keep {
+			c.removeLocked(back)
+			continue
+		}
+
+		conv := keep.Value.(*cachedConversation)
+		for key, page := range conv.pages {
+			if key != keepPage {
+				delete(conv.pages, key)
+				conv.count -= len(page)
+				c.count -= len(page)
+			}
+		}
+		if c.count > c.size {
+			c.removeLocked(keep)
+		}
+		return
+	}
+}
+
+// removeLocked drops one conversation. The caller must hold c.mu.
+func (c *messageCache) removeLocked(elem *list.Element) {
+	conv := elem.Value.(*cachedConversation)
+	c.count -= conv.count
+	c.order.Remove(elem)
+	delete(c.conversations, conv.friendID)
+}
+
+// copyMessages copies a page so callers cannot change the cached messages
+func copyMessages(messages []*Message) []*Message {
+	copied := make([]*Message, len(messages))
+	for i, msg := range messages {
+		msgCopy := *msg
+		copied[i] = &msgCopy
+	}
+	return copied
+}
+
+// SetCacheSize sets how many recently loaded messages are kept in memory;
+// 0 turns the cache off
+func (m *Manager) SetCacheSize(size int) {
+	m.cache.setSize(size)
+}
+
+// CacheStats returns how many conversation loads were served from memory
+// and how many went to the database while the cache was on
+func (m *Manager) CacheStats() (hits, misses uint64) {
+	return m.cache.stats()
+}
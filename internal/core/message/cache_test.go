@@ -0,0 +1,183 @@
+package message
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+// saveIncoming stores count incoming messages from a friend
+func saveIncoming(t testing.TB, mgr *Manager, friendID uint32, count int) {
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		msg := &Message{
+			UUID: fmt.Sprintf("msg-%d-%d", friendID, i), FriendID: friendID, Content: "original",
+			MessageType: MessageTypeNormal, Timestamp: time.Now().Add(time.Duration(i) * time.Second),
+		}
+		if err := mgr.saveMessage(msg); err != nil {
+			t.Fatalf("Failed to save message: %v", err)
+		}
+	}
+}
+
+// changeBehindManager edits a friend's messages without the manager, so only
+// a fresh query sees the change
+func changeBehindManager(t *testing.T, db *storage.Database, friendID uint32, content string) {
+	t.Helper()
+
+	if _, err := db.Exec(`UPDATE messages SET content = ? WHERE friend_id = ?`, content, friendID); err != nil {
+		t.Fatalf("Failed to update messages: %v", err)
+	}
+}
+
+// firstContent loads a friend's latest message
+func firstContent(t *testing.T, mgr *Manager, friendID uint32) string {
+	t.Helper()
+
+	messages, err := mgr.GetMessages(friendID, 10, 0)
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(messages) == 0 {
+		return ""
+	}
+	return messages[0].Content
+}
+
+func TestMessageCacheServesRepeatLoads(t *testing.T) {
+	mgr, db, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	saveIncoming(t, mgr, 1, 3)
+	firstContent(t, mgr, 1)
+
+	changeBehindManager(t, db, 1, "changed")
+	if got := firstContent(t, mgr, 1); got != "original" {
+		t.Errorf("Expected a cache hit to skip the database, got %q", got)
+	}
+	if hits, misses := mgr.CacheStats(); hits != 1 || misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %d and %d", hits, misses)
+	}
+
+	// Changes through the manager are seen straight away
+	messages, _ := mgr.GetMessages(1, 10, 0)
+	if err := mgr.EditMessage(messages[0].ID, "edited"); err != nil {
+		t.Fatalf("EditMessage failed: %v", err)
+	}
+	if got := firstContent(t, mgr, 1); got != "edited" {
+		t.Errorf("Expected the edit to invalidate the cache, got %q", got)
+	}
+
+	msg := &Message{UUID: "newest", FriendID: 1, Content: "newest", MessageType: MessageTypeNormal, Timestamp: time.Now().Add(time.Hour)}
+	if err := mgr.saveMessage(msg); err != nil {
+		t.Fatalf("Failed to save message: %v", err)
+	}
+	if got := firstContent(t, mgr, 1); got != "newest" {
+		t.Errorf("Expected a new message to invalidate the cache, got %q", got)
+	}
+
+	if err := mgr.DeleteMessage(msg.ID); err != nil {
+		t.Fatalf("DeleteMessage failed: %v", err)
+	}
+	if got := firstContent(t, mgr, 1); got == "newest" {
+		t.Error("Expected a deletion to invalidate the cache")
+	}
+
+	// Cached pages are copies
+	messages, _ = mgr.GetMessages(1, 10, 0)
+	messages[0].Content = "scribbled"
+	if got := firstContent(t, mgr, 1); got == "scribbled" {
+		t.Error("Expected callers not to change cached messages")
+	}
+}
+
+func TestMessageCacheEviction(t *testing.T) {
+	tests := []struct {
+		name        string
+		size        int
+		wantCached  int
+		wantEvicted []uint32 // Conversations reloaded from the database
+	}{
+		{"everything fits", 20, 12, nil},
+		{"least recent go first", 7, 6, []uint32{1, 2}},
+		{"disabled", 0, 0, []uint32{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, db, _, _, cleanup := setupTestManager(t)
+			defer cleanup()
+			mgr.SetCacheSize(tt.size)
+
+			friends := []uint32{1, 2, 3, 4}
+			for _, friendID := range friends {
+				saveIncoming(t, mgr, friendID, 3)
+				firstContent(t, mgr, friendID)
+				if got := mgr.cache.len(); got > tt.size {
+					t.Fatalf("Expected at most %d cached messages, got %d", tt.size, got)
+				}
+			}
+			if got := mgr.cache.len(); got != tt.wantCached {
+				t.Errorf("Expected %d cached messages, got %d", tt.wantCached, got)
+			}
+
+			evicted := make(map[uint32]bool)
+			for _, friendID := range tt.wantEvicted {
+				evicted[friendID] = true
+			}
+			for _, friendID := range friends {
+				changeBehindManager(t, db, friendID, "changed")
+			}
+			// Most recent first, so reloading one does not evict the next
+			for i := len(friends) - 1; i >= 0; i-- {
+				friendID := friends[i]
+				reloaded := firstContent(t, mgr, friendID) == "changed"
+				if reloaded != evicted[friendID] {
+					t.Errorf("Friend %d: expected reloaded=%v, got %v", friendID, evicted[friendID], reloaded)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageCacheResize(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	for _, friendID := range []uint32{1, 2, 3} {
+		saveIncoming(t, mgr, friendID, 4)
+		firstContent(t, mgr, friendID)
+	}
+	if got := mgr.cache.len(); got != 12 {
+		t.Fatalf("Expected 12 cached messages, got %d", got)
+	}
+
+	mgr.SetCacheSize(5)
+	if got := mgr.cache.len(); got != 4 {
+		t.Errorf("Expected shrinking to keep the latest conversation only, got %d messages", got)
+	}
+}
+
+func BenchmarkGetMessages(b *testing.B) {
+	for _, size := range []int{0, DefaultMessageCacheSize} {
+		b.Run(fmt.Sprintf("cache=%d", size), func(b *testing.B) {
+			mgr, _, _, _, cleanup := setupTestManager(b)
+			defer cleanup()
+			mgr.SetCacheSize(size)
+			for _, friendID := range []uint32{1, 2} {
+				saveIncoming(b, mgr, friendID, 50)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Switching back and forth between two conversations
+				if _, err := mgr.GetMessages(uint32(i%2)+1, 50, 0); err != nil {
+					b.Fatalf("GetMessages failed: %v", err)
+				}
+			}
+		})
+	}
+}
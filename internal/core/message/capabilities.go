@@ -0,0 +1,242 @@
+package message
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// CapabilitiesVersion is the version of the capability descriptor this client sends
+const CapabilitiesVersion = 1
+
+// controlCapabilities marks a wire message carrying a capability descriptor
+const controlCapabilities = "caps"
+
+// Feature is a bit flag for an extended messaging feature
+type Feature uint32
+
+const (
+	// FeatureMessageIDs means the peer shares message UUIDs and reply references
+	FeatureMessageIDs Feature = 1 << iota
+	// FeatureEdits means the peer applies remote message edits
+	FeatureEdits
+	// FeatureReactions means the peer understands emoji reactions
+	FeatureReactions
+	// FeatureRemoteDelete means the peer applies delete-for-everyone requests
+	FeatureRemoteDelete
+	// FeatureReadReceipts means the peer shows read receipts, so it wants them sent
+	FeatureReadReceipts
+	// FeatureCompression means the peer accepts compressed message bodies
+	FeatureCompression
+	// FeatureFileChecksums means the peer announces and verifies file checksums
+	FeatureFileChecksums
+	// FeatureTyping means the peer sends and shows typing indicators
+	FeatureTyping
+	// FeatureDisappearing means the peer syncs disappearing message timers
+	FeatureDisappearing
+	// FeatureGroups means the peer takes part in group chats
+	FeatureGroups
+)
+
+// Capabilities describes which extended features a Whisp client supports
+type Capabilities struct {
+	Version  int     `json:"v"`
+	Features Feature `json:"f"`
+}
+
+// LocalCapabilities returns the capabilities of this client
+func LocalCapabilities() Capabilities {
+	features := FeatureMessageIDs | FeatureEdits | FeatureReactions | FeatureRemoteDelete |
+		FeatureFileChecksums | FeatureTyping | FeatureDisappearing | FeatureGroups
+	return Capabilities{
+		Version:  CapabilitiesVersion,
+		Features: features,
+	}
+}
+
+// localCapabilities returns the capabilities this client announces, leaving
+// out remote deletion while it is not honored and read receipts while they
+// are not shown
+func (m *Manager) localCapabilities() Capabilities {
+	caps := LocalCapabilities()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.ignoreRemoteDeletions {
+		caps.Features &^= FeatureRemoteDelete
+	}
+	if m.showReceipts {
+		caps.Features |= FeatureReadReceipts
+	}
+	return caps
+}
+
+// Supports reports whether all of the given features are available
+func (c Capabilities) Supports(features Feature) bool {
+	return c.Features&features == features
+}
+
+// EncodeCapabilities serializes a capability descriptor for transmission
+func EncodeCapabilities(caps Capabilities) (string, error) {
+	data, err := json.Marshal(caps)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode capabilities: %w", err)
+	}
+	return string(data), nil
+}
+
+// DecodeCapabilities parses a capability descriptor received from a peer.
+// Unknown feature bits from newer clients are kept so they round-trip unchanged.
+func DecodeCapabilities(data string) (Capabilities, error) {
+	var caps Capabilities
+	if err := json.Unmarshal([]byte(data), &caps); err != nil {
+		return Capabilities{}, fmt.Errorf("failed to decode capabilities: %w", err)
+	}
+	if caps.Version < 1 {
+		return Capabilities{}, fmt.Errorf("invalid capabilities version: %d", caps.Version)
+	}
+	return caps, nil
+}
+
+// AnnounceCapabilities sends this client's capability descriptor to a friend
+func (m *Manager) AnnounceCapabilities(friendID uint32) error {
+	descriptor, err := EncodeCapabilities(m.localCapabilities())
+	if err != nil {
+		return err
+	}
+
+	wireContent := encodeWire(wireHeader{Control: controlCapabilities}, descriptor)
+	if err := m.toxMgr.SendMessage(friendID, wireContent, toxcore.MessageTypeNormal); err != nil {
+		return fmt.Errorf("failed to send capabilities: %w", err)
+	}
+
+	m.mu.Lock()
+	m.announced[friendID] = true
+	m.mu.Unlock()
+
+	return nil
+}
+
+// HandlePeerConnection starts the capability handshake when a friend known to
+// run Whisp comes online and forgets their capabilities when they go offline,
+// since they may reconnect with a different client. Other friends are never
+// sent control messages they would show as text.
+func (m *Manager) HandlePeerConnection(friendID uint32, online bool) {
+	if !online {
+		m.mu.Lock()
+		delete(m.peerCapabilities, friendID)
+		delete(m.announced, friendID)
+		delete(m.greeted, friendID)
+		m.mu.Unlock()
+		m.setFriendTyping(friendID, false)
+		return
+	}
+
+	if m.isWhispPeer(friendID) {
+		m.announceOnce(friendID)
+	}
+}
+
+// announceOnce sends our capabilities to a friend unless they already got
+// them this session
+func (m *Manager) announceOnce(friendID uint32) {
+	m.mu.RLock()
+	announced := m.announced[friendID]
+	m.mu.RUnlock()
+
+	if !announced {
+		if err := m.AnnounceCapabilities(friendID); err != nil {
+			logging.Warnf("Failed to announce capabilities to friend %d: %v", friendID, err)
+		}
+	}
+}
+
+// isWhispPeer reports whether a friend has ever sent a Whisp header or hello marker
+func (m *Manager) isWhispPeer(friendID uint32) bool {
+	m.mu.RLock()
+	known := m.whispPeers[friendID]
+	m.mu.RUnlock()
+	if known {
+		return true
+	}
+
+	var seen int
+	err := m.db.QueryRow("SELECT 1 FROM whisp_peers WHERE friend_id = ?", friendID).Scan(&seen)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logging.Warnf("Failed to look up Whisp peer %d: %v", friendID, err)
+		}
+		return false
+	}
+
+	m.mu.Lock()
+	m.whispPeers[friendID] = true
+	m.mu.Unlock()
+	return true
+}
+
+// noteWhispPeer remembers that a friend runs Whisp and starts the handshake
+// with them if we have not announced yet
+func (m *Manager) noteWhispPeer(friendID uint32) {
+	if !m.isWhispPeer(friendID) {
+		m.mu.Lock()
+		m.whispPeers[friendID] = true
+		m.mu.Unlock()
+
+		if _, err := m.db.Exec(`
+			INSERT OR REPLACE INTO whisp_peers (friend_id, seen_at) VALUES (?, ?)
+		`, friendID, time.Now()); err != nil {
+			logging.Warnf("Failed to remember Whisp peer %d: %v", friendID, err)
+		}
+	}
+
+	m.announceOnce(friendID)
+}
+
+// shouldGreet reports whether a plain message to a friend should carry the
+// hello marker, which is sent once per session until the handshake completes
+func (m *Manager) shouldGreet(friendID uint32) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.peerCapabilities[friendID]; ok || m.greeted[friendID] {
+		return false
+	}
+	m.greeted[friendID] = true
+	return true
+}
+
+// GetPeerCapabilities returns the capabilities a friend announced, if any
+func (m *Manager) GetPeerCapabilities(friendID uint32) (Capabilities, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	caps, ok := m.peerCapabilities[friendID]
+	return caps, ok
+}
+
+// peerSupports reports whether a friend announced support for the given features.
+// Peers that have not completed the handshake are treated as plain Tox clients.
+func (m *Manager) peerSupports(friendID uint32, features Feature) bool {
+	caps, ok := m.GetPeerCapabilities(friendID)
+	return ok && caps.Supports(features)
+}
+
+// handleCapabilities records a peer's capability descriptor. The answer with
+// our own is sent by noteWhispPeer when the header arrives.
+func (m *Manager) handleCapabilities(friendID uint32, descriptor string) {
+	caps, err := DecodeCapabilities(descriptor)
+	if err != nil {
+		logging.Warnf("Ignoring capabilities from friend %d: %v", friendID, err)
+		return
+	}
+
+	m.mu.Lock()
+	m.peerCapabilities[friendID] = caps
+	m.mu.Unlock()
+}
@@ -0,0 +1,172 @@
+package message
+
+import "testing"
+
+// completeHandshake simulates a Whisp peer announcing its capabilities
+func completeHandshake(t *testing.T, mgr *Manager, friendID uint32) {
+	t.Helper()
+
+	descriptor, err := EncodeCapabilities(LocalCapabilities())
+	if err != nil {
+		t.Fatalf("EncodeCapabilities failed: %v", err)
+	}
+	if msg := mgr.HandleIncomingMessage(friendID, encodeWire(wireHeader{Control: controlCapabilities}, descriptor), MessageTypeNormal); msg != nil {
+		t.Fatal("Expected capability descriptor not to be stored as a message")
+	}
+}
+
+func TestCapabilitiesEncoding(t *testing.T) {
+	tests := []struct {
+		name      string
+		caps      Capabilities
+		expectErr bool
+	}{
+		{"local", LocalCapabilities(), false},
+		{"no features", Capabilities{Version: 1}, false},
+		{"unknown future bits", Capabilities{Version: 3, Features: FeatureMessageIDs | 1<<20}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodeCapabilities(tt.caps)
+			if err != nil {
+				t.Fatalf("EncodeCapabilities failed: %v", err)
+			}
+
+			decoded, err := DecodeCapabilities(data)
+			if err != nil {
+				t.Fatalf("DecodeCapabilities failed: %v", err)
+			}
+			if decoded != tt.caps {
+				t.Errorf("Expected %+v, got %+v", tt.caps, decoded)
+			}
+		})
+	}
+
+	for _, data := range []string{"", "not json", `{"v":0,"f":1}`} {
+		if _, err := DecodeCapabilities(data); err == nil {
+			t.Errorf("Expected error decoding %q", data)
+		}
+	}
+}
+
+func TestCapabilitiesSupports(t *testing.T) {
+	caps := Capabilities{Version: 1, Features: FeatureMessageIDs | FeatureEdits}
+
+	if !caps.Supports(FeatureEdits) {
+		t.Error("Expected edits to be supported")
+	}
+	if caps.Supports(FeatureReactions) {
+		t.Error("Expected reactions to be unsupported")
+	}
+	if caps.Supports(FeatureEdits | FeatureReactions) {
+		t.Error("Expected combined check to require every feature")
+	}
+}
+
+func TestCapabilityHandshake(t *testing.T) {
+	mgr, db, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	// Friends that may run any Tox client get no control messages
+	mgr.HandlePeerConnection(1, true)
+	if msg := mgr.HandleIncomingMessage(1, "hi", MessageTypeNormal); msg == nil || msg.Content != "hi" {
+		t.Fatalf("Expected plain message to be stored, got %+v", msg)
+	}
+	if toxMgr.lastMessage != "" {
+		t.Fatalf("Expected nothing sent to a plain Tox client, got %q", toxMgr.lastMessage)
+	}
+
+	// The hello marker shows a Whisp peer and triggers our announcement
+	if msg := mgr.HandleIncomingMessage(1, "hello"+helloMarker, MessageTypeNormal); msg == nil || msg.Content != "hello" {
+		t.Fatalf("Expected hello marker to be stripped, got %+v", msg)
+	}
+	header, body := decodeWire(toxMgr.lastMessage)
+	if header.Control != controlCapabilities {
+		t.Fatalf("Expected capability announcement, got %q", toxMgr.lastMessage)
+	}
+	if caps, err := DecodeCapabilities(body); err != nil || caps != LocalCapabilities() {
+		t.Errorf("Expected local capabilities to be announced, got %+v (%v)", caps, err)
+	}
+
+	if _, ok := mgr.GetPeerCapabilities(1); ok {
+		t.Error("Expected no peer capabilities before the peer answers")
+	}
+
+	// The peer's answer is recorded without re-announcing
+	toxMgr.lastMessage = ""
+	completeHandshake(t, mgr, 1)
+	if toxMgr.lastMessage != "" {
+		t.Errorf("Expected no second announcement, got %q", toxMgr.lastMessage)
+	}
+	if caps, ok := mgr.GetPeerCapabilities(1); !ok || caps != LocalCapabilities() {
+		t.Errorf("Expected peer capabilities to be recorded, got %+v", caps)
+	}
+
+	// Going offline forgets the peer's capabilities
+	mgr.HandlePeerConnection(1, false)
+	if _, ok := mgr.GetPeerCapabilities(1); ok {
+		t.Error("Expected peer capabilities to be cleared when offline")
+	}
+
+	// A known Whisp peer is announced to when they come online, also after a restart
+	toxMgr.lastMessage = ""
+	restarted := NewManager(db, toxMgr, nil)
+	restarted.HandlePeerConnection(1, true)
+	if header, _ := decodeWire(toxMgr.lastMessage); header.Control != controlCapabilities {
+		t.Errorf("Expected announcement to known Whisp peer, got %q", toxMgr.lastMessage)
+	}
+
+	// A peer that announces first gets an answer
+	toxMgr.lastMessage = ""
+	completeHandshake(t, mgr, 2)
+	if header, _ := decodeWire(toxMgr.lastMessage); header.Control != controlCapabilities {
+		t.Errorf("Expected capabilities to be answered, got %q", toxMgr.lastMessage)
+	}
+}
+
+func TestCapabilityDegradation(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	original, err := mgr.SendMessage(1, "plain", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	// Without a handshake the peer may be any Tox client, so no metadata is
+	// sent, only the invisible hello marker on the first message
+	if toxMgr.lastMessage != "plain"+helloMarker {
+		t.Errorf("Expected plain text with hello marker to non-Whisp peer, got %q", toxMgr.lastMessage)
+	}
+
+	reply, err := mgr.SendReply(1, "reply", MessageTypeNormal, original.ID)
+	if err != nil {
+		t.Fatalf("SendReply failed: %v", err)
+	}
+	if toxMgr.lastMessage != "reply" {
+		t.Errorf("Expected reply reference to be dropped for non-Whisp peer, got %q", toxMgr.lastMessage)
+	}
+	if reply.ReplyToID == nil || *reply.ReplyToID != original.ID {
+		t.Error("Expected reply to stay linked locally")
+	}
+
+	// A peer that lacks message IDs still gets plain text
+	descriptor, _ := EncodeCapabilities(Capabilities{Version: 1})
+	mgr.HandleIncomingMessage(1, encodeWire(wireHeader{Control: controlCapabilities}, descriptor), MessageTypeNormal)
+	if _, err := mgr.SendMessage(1, "still plain", MessageTypeNormal); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if toxMgr.lastMessage != "still plain" {
+		t.Errorf("Expected plain text to peer without message IDs, got %q", toxMgr.lastMessage)
+	}
+
+	completeHandshake(t, mgr, 1)
+	msg, err := mgr.SendMessage(1, "with metadata", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if header, body := decodeWire(toxMgr.lastMessage); header.ID != msg.UUID || body != "with metadata" {
+		t.Errorf("Expected metadata header for capable peer, got %q", toxMgr.lastMessage)
+	}
+}
@@ -0,0 +1,270 @@
+package message
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// DefaultSweepInterval is how often expired messages are deleted
+const DefaultSweepInterval = 30 * time.Second
+
+// controlDisappearingTimer marks a wire message carrying the disappearing
+// message timer of a conversation, in seconds
+const controlDisappearingTimer = "timer"
+
+// ErrDisappearingDisabled is returned when setting a timer while disappearing
+// messages are turned off in the privacy settings
+var ErrDisappearingDisabled = errors.New("disappearing messages are disabled")
+
+// SetDisappearingEnabled sets whether the user may start disappearing message
+// timers. Timers set by friends are honored either way.
+func (m *Manager) SetDisappearingEnabled(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.disappearingEnabled = enabled
+}
+
+// SetDisappearingTimer sets how long new messages in a conversation live
+// before they are deleted; zero turns the timer off. The friend is told so
+// both sides expire messages alike.
+func (m *Manager) SetDisappearingTimer(friendID uint32, d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("disappearing timer cannot be negative")
+	}
+
+	m.mu.RLock()
+	enabled := m.disappearingEnabled
+	m.mu.RUnlock()
+	if d > 0 && !enabled {
+		return ErrDisappearingDisabled
+	}
+
+	if err := m.storeDisappearingTimer(friendID, d); err != nil {
+		return err
+	}
+
+	if m.peerSupports(friendID, FeatureDisappearing) {
+		seconds := strconv.FormatInt(int64(d/time.Second), 10)
+		wireContent := encodeWire(wireHeader{Control: controlDisappearingTimer}, seconds)
+		if err := m.toxMgr.SendMessage(friendID, wireContent, toxcore.MessageTypeNormal); err != nil {
+			logging.Warnf("Failed to send disappearing timer to friend %d: %v", friendID, err)
+		}
+	}
+	return nil
+}
+
+// GetDisappearingTimer returns the disappearing message timer of a
+// conversation, or zero if messages are kept
+func (m *Manager) GetDisappearingTimer(friendID uint32) time.Duration {
+	var seconds int64
+	err := m.db.QueryRow("SELECT seconds FROM disappearing_timers WHERE friend_id = ?", friendID).Scan(&seconds)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logging.Warnf("Failed to load disappearing timer: %v", err)
+		}
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// SetOnMessagesExpired sets the callback invoked with the conversations that
+// lost messages to their disappearing timer or retention
+func (m *Manager) SetOnMessagesExpired(callback func(friendIDs []uint32)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onMessagesExpired = callback
+}
+
+// StartExpirySweeper deletes expired messages now and then every interval
+// until the context is done
+func (m *Manager) StartExpirySweeper(ctx context.Context, interval time.Duration) {
+	m.sweepAndNotify(time.Now())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				m.sweepAndNotify(now)
+			}
+		}
+	}()
+}
+
+// sweepAndNotify deletes expired messages and runs the expiry callback
+func (m *Manager) sweepAndNotify(now time.Time) {
+	// The database is closed while the app is locked
+	if m.db.IsLocked() {
+		return
+	}
+
+	friendIDs, err := m.SweepExpired(now)
+	if err != nil {
+		logging.Warnf("%v", err)
+		return
+	}
+
+	m.mu.RLock()
+	callback := m.onMessagesExpired
+	m.mu.RUnlock()
+
+	if callback != nil && len(friendIDs) > 0 {
+		callback(friendIDs)
+	}
+}
+
+// SweepExpired deletes messages whose disappearing timer ran out by now,
+// along with their reactions and media files. Returns the conversations
+// that lost messages.
+func (m *Manager) SweepExpired(now time.Time) ([]uint32, error) {
+	rows, err := m.db.Query(`SELECT id, friend_id, uuid, file_path FROM messages WHERE expires_at IS NOT NULL AND expires_at <= ?`, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired messages: %w", err)
+	}
+
+	var ids []interface{}
+	var uuids []interface{}
+	var filePaths []string
+	seen := make(map[uint32]bool)
+	var friendIDs []uint32
+	for rows.Next() {
+		var id int64
+		var friendID uint32
+		var messageUUID string
+		var filePath sql.NullString
+		if err := rows.Scan(&id, &friendID, &messageUUID, &filePath); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan expired message: %w", err)
+		}
+		ids = append(ids, id)
+		uuids = append(uuids, messageUUID)
+		if filePath.Valid && filePath.String != "" {
+			filePaths = append(filePaths, filePath.String)
+		}
+		if !seen[friendID] {
+			seen[friendID] = true
+			friendIDs = append(friendIDs, friendID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read expired messages: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	if err := m.purgeMessages(ids, uuids, filePaths); err != nil {
+		return nil, fmt.Errorf("failed to delete expired messages: %w", err)
+	}
+	for _, friendID := range friendIDs {
+		m.cache.invalidate(friendID)
+	}
+	return friendIDs, nil
+}
+
+// purgeMessages deletes messages for good, along with their reactions and
+// media files, and detaches replies and transfers that refer to them
+func (m *Manager) purgeMessages(ids, uuids []interface{}, filePaths []string) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	idList := placeholders(len(ids))
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`UPDATE messages SET reply_to_id = NULL WHERE reply_to_id IN (` + idList + `)`, ids},
+		{`UPDATE file_transfers SET message_id = NULL WHERE message_id IN (` + idList + `)`, ids},
+		{`DELETE FROM message_reactions WHERE message_uuid IN (` + placeholders(len(uuids)) + `)`, uuids},
+		{`DELETE FROM messages WHERE id IN (` + idList + `)`, ids},
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement.query, statement.args...); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit message deletion: %w", err)
+	}
+
+	for _, filePath := range filePaths {
+		if err := m.db.ReleaseFile(filePath); err != nil {
+			logging.Warnf("Failed to remove media file %s: %v", filePath, err)
+		}
+	}
+	return nil
+}
+
+// handleDisappearingTimer adopts the disappearing timer a friend set for
+// our conversation
+func (m *Manager) handleDisappearingTimer(friendID uint32, body string) {
+	seconds, err := strconv.ParseInt(body, 10, 64)
+	if err != nil || seconds < 0 {
+		logging.Warnf("Ignoring malformed disappearing timer from friend %d", friendID)
+		return
+	}
+	if err := m.storeDisappearingTimer(friendID, time.Duration(seconds)*time.Second); err != nil {
+		logging.Warnf("%v", err)
+	}
+}
+
+// storeDisappearingTimer saves the disappearing timer of a conversation
+func (m *Manager) storeDisappearingTimer(friendID uint32, d time.Duration) error {
+	var err error
+	if d == 0 {
+		_, err = m.db.Exec("DELETE FROM disappearing_timers WHERE friend_id = ?", friendID)
+	} else {
+		query := `
+			INSERT INTO disappearing_timers (friend_id, seconds, updated_at) VALUES (?, ?, ?)
+			ON CONFLICT(friend_id) DO UPDATE SET seconds = excluded.seconds, updated_at = excluded.updated_at
+		`
+		_, err = m.db.Exec(query, friendID, int64(d/time.Second), time.Now())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save disappearing timer: %w", err)
+	}
+	return nil
+}
+
+// applyExpiry sets when a message expires under a disappearing timer
+func (m *Manager) applyExpiry(msg *Message, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	expiresAt := msg.Timestamp.Add(ttl).UTC()
+	msg.ExpiresAt = &expiresAt
+}
+
+// wireTTL returns the lifetime of a message in seconds, for the wire header
+func wireTTL(msg *Message) int64 {
+	if msg.ExpiresAt == nil {
+		return 0
+	}
+	seconds := int64(msg.ExpiresAt.Sub(msg.Timestamp) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// placeholders returns n comma separated SQL parameter placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
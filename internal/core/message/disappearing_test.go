@@ -0,0 +1,163 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+func TestDisappearingTimer(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		ttl     time.Duration
+		wantErr error
+		expires bool
+	}{
+		{"timer off", true, 0, nil, false},
+		{"one hour", true, time.Hour, nil, true},
+		{"disabled in settings", false, time.Hour, ErrDisappearingDisabled, false},
+		{"turning off while disabled", false, 0, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, _, _, _, cleanup := setupTestManager(t)
+			defer cleanup()
+
+			mgr.SetDisappearingEnabled(tt.enabled)
+			if err := mgr.SetDisappearingTimer(1, tt.ttl); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			sent, err := mgr.SendMessage(1, "hello", MessageTypeNormal)
+			if err != nil {
+				t.Fatalf("SendMessage failed: %v", err)
+			}
+			received := mgr.HandleIncomingMessage(1, "hi", MessageTypeNormal)
+
+			for _, msg := range []*Message{sent, received} {
+				if (msg.ExpiresAt != nil) != tt.expires {
+					t.Fatalf("Expected expires=%v, got %v", tt.expires, msg.ExpiresAt)
+				}
+				if tt.expires && !msg.ExpiresAt.Equal(msg.Timestamp.Add(tt.ttl)) {
+					t.Errorf("Expected expiry one TTL after %v, got %v", msg.Timestamp, msg.ExpiresAt)
+				}
+			}
+		})
+	}
+
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+	if err := mgr.SetDisappearingTimer(1, -time.Second); err == nil {
+		t.Error("Expected a negative timer to be rejected")
+	}
+}
+
+func TestDisappearingTimerSync(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	mgr.SetDisappearingEnabled(true)
+	completeHandshake(t, mgr, 1)
+
+	// Setting the timer tells the friend
+	if err := mgr.SetDisappearingTimer(1, 5*time.Minute); err != nil {
+		t.Fatalf("SetDisappearingTimer failed: %v", err)
+	}
+	header, body := decodeWire(toxMgr.lastMessage)
+	if header.Control != controlDisappearingTimer || body != "300" {
+		t.Fatalf("Expected a timer control message, got %q", toxMgr.lastMessage)
+	}
+
+	// Outgoing messages carry their lifetime
+	if _, err := mgr.SendMessage(1, "hello", MessageTypeNormal); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if header, _ := decodeWire(toxMgr.lastMessage); header.TTL != 300 {
+		t.Errorf("Expected TTL 300 in the header, got %d", header.TTL)
+	}
+
+	// The friend turning the timer off is adopted, even with the setting disabled
+	mgr.SetDisappearingEnabled(false)
+	if msg := mgr.HandleIncomingMessage(1, encodeWire(wireHeader{Control: controlDisappearingTimer}, "0"), MessageTypeNormal); msg != nil {
+		t.Fatal("Expected the timer not to be stored as a message")
+	}
+	if ttl := mgr.GetDisappearingTimer(1); ttl != 0 {
+		t.Errorf("Expected the friend's timer to be adopted, got %v", ttl)
+	}
+
+	// Messages from the friend follow the lifetime they carry
+	msg := mgr.HandleIncomingMessage(1, encodeWire(wireHeader{ID: "remote-1", TTL: 60}, "bye"), MessageTypeNormal)
+	if msg == nil || msg.ExpiresAt == nil || !msg.ExpiresAt.Equal(msg.Timestamp.Add(time.Minute)) {
+		t.Errorf("Expected the message to expire after a minute, got %+v", msg)
+	}
+}
+
+func TestExpiredOnLoad(t *testing.T) {
+	mgr, db, toxMgr, contactMgr, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	past := time.Now().Add(-time.Hour)
+	expired := &Message{UUID: "expired", FriendID: 1, Content: "gone", Timestamp: past.Add(-time.Minute)}
+	mgr.applyExpiry(expired, time.Minute)
+	if err := mgr.saveMessage(expired); err != nil {
+		t.Fatalf("saveMessage failed: %v", err)
+	}
+	kept := &Message{UUID: "kept", FriendID: 1, Content: "still here", Timestamp: time.Now()}
+	if err := mgr.saveMessage(kept); err != nil {
+		t.Fatalf("saveMessage failed: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO message_reactions (message_uuid, friend_id, emoji, is_outgoing, timestamp) VALUES (?, 1, '👍', 1, ?)`, "expired", past); err != nil {
+		t.Fatalf("Failed to add reaction: %v", err)
+	}
+
+	// Restart on the same database before the sweeper ran
+	dbPath := db.GetPath()
+	db.Close()
+	reopened, err := storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer reopened.Close()
+	restarted := NewManager(reopened, toxMgr, contactMgr)
+
+	messages, err := restarted.GetMessages(1, 10, 0)
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(messages) != 1 || messages[0].UUID != "kept" {
+		t.Fatalf("Expected only the unexpired message, got %d", len(messages))
+	}
+
+	expiredFriends := make(chan []uint32, 1)
+	restarted.SetOnMessagesExpired(func(friendIDs []uint32) { expiredFriends <- friendIDs })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	restarted.StartExpirySweeper(ctx, time.Hour)
+
+	select {
+	case friendIDs := <-expiredFriends:
+		if len(friendIDs) != 1 || friendIDs[0] != 1 {
+			t.Errorf("Expected friend 1 to lose messages, got %v", friendIDs)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the sweeper to delete the expired message on start")
+	}
+
+	var count int
+	reopened.QueryRow(`SELECT COUNT(*) FROM messages WHERE uuid = 'expired'`).Scan(&count)
+	if count != 0 {
+		t.Error("Expected the expired message to be deleted")
+	}
+	reopened.QueryRow(`SELECT COUNT(*) FROM message_reactions WHERE message_uuid = 'expired'`).Scan(&count)
+	if count != 0 {
+		t.Error("Expected the expired message's reactions to be deleted")
+	}
+	if friendIDs, err := restarted.SweepExpired(time.Now()); err != nil || len(friendIDs) != 0 {
+		t.Errorf("Expected nothing left to sweep, got %v (%v)", friendIDs, err)
+	}
+}
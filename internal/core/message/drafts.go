@@ -0,0 +1,144 @@
+package message
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// DefaultAutosaveDelay is how long typing must pause before a draft is written
+const DefaultAutosaveDelay = 2 * time.Second
+
+// SaveDraft stores the unsent text for a conversation. Empty text removes the draft.
+func (m *Manager) SaveDraft(friendID uint32, content string) error {
+	if content == "" {
+		return m.DeleteDraft(friendID)
+	}
+
+	query := `
+		INSERT INTO drafts (friend_id, content, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(friend_id) DO UPDATE SET content = excluded.content, updated_at = excluded.updated_at
+	`
+	if _, err := m.db.Exec(query, friendID, content, time.Now()); err != nil {
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+	return nil
+}
+
+// LoadDraft returns the saved draft for a conversation, or "" if there is none
+func (m *Manager) LoadDraft(friendID uint32) (string, error) {
+	var content string
+	err := m.db.QueryRow("SELECT content FROM drafts WHERE friend_id = ?", friendID).Scan(&content)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load draft: %w", err)
+	}
+	return content, nil
+}
+
+// DeleteDraft removes the saved draft for a conversation
+func (m *Manager) DeleteDraft(friendID uint32) error {
+	if _, err := m.db.Exec("DELETE FROM drafts WHERE friend_id = ?", friendID); err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	return nil
+}
+
+// DraftAutosaver periodically persists in-progress messages so they survive a
+// crash. Writes are debounced per conversation.
+type DraftAutosaver struct {
+	mgr   *Manager
+	delay time.Duration
+
+	mu      sync.Mutex
+	pending map[uint32]string
+	timers  map[uint32]*time.Timer
+
+	// writeMu orders database writes so a discard cannot be overtaken by a stale save
+	writeMu sync.Mutex
+}
+
+// NewDraftAutosaver creates an autosaver that writes drafts after delay without changes
+func NewDraftAutosaver(mgr *Manager, delay time.Duration) *DraftAutosaver {
+	return &DraftAutosaver{
+		mgr:     mgr,
+		delay:   delay,
+		pending: make(map[uint32]string),
+		timers:  make(map[uint32]*time.Timer),
+	}
+}
+
+// Update records the current input for a conversation and schedules a save
+func (a *DraftAutosaver) Update(friendID uint32, content string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.pending[friendID] = content
+
+	if timer, exists := a.timers[friendID]; exists {
+		timer.Stop()
+	}
+	a.timers[friendID] = time.AfterFunc(a.delay, func() {
+		a.save(friendID)
+	})
+}
+
+// Discard drops any pending save and deletes the stored draft, e.g. after sending
+func (a *DraftAutosaver) Discard(friendID uint32) {
+	a.mu.Lock()
+	if timer, exists := a.timers[friendID]; exists {
+		timer.Stop()
+		delete(a.timers, friendID)
+	}
+	delete(a.pending, friendID)
+	a.mu.Unlock()
+
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+
+	if err := a.mgr.DeleteDraft(friendID); err != nil {
+		logging.Warnf("%v", err)
+	}
+}
+
+// Flush immediately writes all pending drafts
+func (a *DraftAutosaver) Flush() {
+	a.mu.Lock()
+	friendIDs := make([]uint32, 0, len(a.pending))
+	for friendID := range a.pending {
+		friendIDs = append(friendIDs, friendID)
+	}
+	a.mu.Unlock()
+
+	for _, friendID := range friendIDs {
+		a.save(friendID)
+	}
+}
+
+// save writes the pending draft for a conversation if one is still pending
+func (a *DraftAutosaver) save(friendID uint32) {
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+
+	a.mu.Lock()
+	content, exists := a.pending[friendID]
+	if timer, ok := a.timers[friendID]; ok {
+		timer.Stop()
+		delete(a.timers, friendID)
+	}
+	delete(a.pending, friendID)
+	a.mu.Unlock()
+
+	if !exists {
+		return
+	}
+
+	if err := a.mgr.SaveDraft(friendID, content); err != nil {
+		logging.Warnf("Failed to autosave draft for friend %d: %v", friendID, err)
+	}
+}
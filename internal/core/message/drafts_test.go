@@ -0,0 +1,92 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+func TestDraftAutosaveSurvivesRestart(t *testing.T) {
+	mgr, db, toxMgr, contactMgr, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	autosaver := NewDraftAutosaver(mgr, 20*time.Millisecond)
+	autosaver.Update(1, "a long")
+	autosaver.Update(1, "a long composition")
+	autosaver.Update(2, "note for someone else")
+
+	// Debounced: nothing is written while typing continues
+	if draft, _ := mgr.LoadDraft(1); draft != "" {
+		t.Errorf("Expected no draft before the autosave delay, got %q", draft)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if draft, _ := mgr.LoadDraft(1); draft != "" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Simulate a crash and restart with a fresh manager on the same database
+	dbPath := db.GetPath()
+	db.Close()
+
+	restartedDB, err := storage.NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer restartedDB.Close()
+	restarted := NewManager(restartedDB, toxMgr, contactMgr)
+
+	tests := []struct {
+		friendID uint32
+		expected string
+	}{
+		{1, "a long composition"},
+		{2, "note for someone else"},
+		{3, ""},
+	}
+	for _, tt := range tests {
+		draft, err := restarted.LoadDraft(tt.friendID)
+		if err != nil {
+			t.Fatalf("LoadDraft failed: %v", err)
+		}
+		if draft != tt.expected {
+			t.Errorf("Friend %d: expected draft %q, got %q", tt.friendID, tt.expected, draft)
+		}
+	}
+}
+
+func TestDraftAutosaverFlushAndDiscard(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	autosaver := NewDraftAutosaver(mgr, time.Hour)
+	autosaver.Update(1, "unsent")
+	autosaver.Flush()
+
+	if draft, _ := mgr.LoadDraft(1); draft != "unsent" {
+		t.Errorf("Expected flushed draft %q, got %q", "unsent", draft)
+	}
+
+	// Sending discards both the stored draft and any pending save
+	autosaver.Update(1, "about to send")
+	autosaver.Discard(1)
+	autosaver.Flush()
+
+	if draft, _ := mgr.LoadDraft(1); draft != "" {
+		t.Errorf("Expected draft to be discarded, got %q", draft)
+	}
+
+	// Clearing the input removes the draft
+	if err := mgr.SaveDraft(2, "x"); err != nil {
+		t.Fatalf("SaveDraft failed: %v", err)
+	}
+	autosaver.Update(2, "")
+	autosaver.Flush()
+	if draft, _ := mgr.LoadDraft(2); draft != "" {
+		t.Errorf("Expected empty input to remove draft, got %q", draft)
+	}
+}
@@ -0,0 +1,122 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// Control kinds for changes to a message already sent to the friend
+const (
+	controlEdit   = "edit"
+	controlDelete = "delete"
+)
+
+// messageChangePayload is the body of an edit or delete control message. The
+// message is named by the UUID both sides share.
+type messageChangePayload struct {
+	MessageID string `json:"m"`
+	Content   string `json:"c,omitempty"` // New text of an edit
+}
+
+// SetHonorRemoteDeletions sets whether friends may delete their messages from
+// our history. Friends are told of the change the next time they connect.
+func (m *Manager) SetHonorRemoteDeletions(honor bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ignoreRemoteDeletions = !honor
+}
+
+// SetOnMessageChanged sets the callback invoked when a friend edits or
+// deletes one of their messages
+func (m *Manager) SetOnMessageChanged(callback func(friendID uint32, messageUUID string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onMessageChanged = callback
+}
+
+// sendMessageChange sends an edit or delete to a friend whose client supports it
+func (m *Manager) sendMessageChange(friendID uint32, control string, feature Feature, payload messageChangePayload) error {
+	if !m.peerSupports(friendID, feature) {
+		return nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode message %s: %w", control, err)
+	}
+	wireContent := encodeWire(wireHeader{Control: control}, string(data))
+	if err := m.toxMgr.SendMessage(friendID, wireContent, toxcore.MessageTypeNormal); err != nil {
+		return fmt.Errorf("failed to send message %s: %w", control, err)
+	}
+	return nil
+}
+
+// handleEdit applies a friend's edit to our copy of their message. Edits of
+// unknown messages, or of messages the friend did not send, are ignored.
+func (m *Manager) handleEdit(friendID uint32, body string) {
+	var payload messageChangePayload
+	if err := json.Unmarshal([]byte(body), &payload); err != nil || payload.MessageID == "" {
+		logging.Warnf("Ignoring malformed edit from friend %d", friendID)
+		return
+	}
+
+	// Later edits keep the text as first received
+	query := `
+		UPDATE messages
+		SET content = ?, original_content = COALESCE(NULLIF(original_content, ''), content), edited_at = ?
+		WHERE uuid = ? AND friend_id = ? AND is_outgoing = 0 AND is_deleted = 0
+	`
+	m.applyMessageChange(friendID, payload.MessageID, "edit", query, payload.Content, time.Now(), payload.MessageID, friendID)
+}
+
+// handleDelete soft-deletes our copy of a friend's message, unless remote
+// deletions are not honored
+func (m *Manager) handleDelete(friendID uint32, body string) {
+	var payload messageChangePayload
+	if err := json.Unmarshal([]byte(body), &payload); err != nil || payload.MessageID == "" {
+		logging.Warnf("Ignoring malformed delete from friend %d", friendID)
+		return
+	}
+
+	m.mu.RLock()
+	ignore := m.ignoreRemoteDeletions
+	m.mu.RUnlock()
+	if ignore {
+		logging.Infof("Ignoring delete from friend %d: remote deletions are disabled", friendID)
+		return
+	}
+
+	query := `
+		UPDATE messages SET is_deleted = 1
+		WHERE uuid = ? AND friend_id = ? AND is_outgoing = 0 AND is_deleted = 0
+	`
+	m.applyMessageChange(friendID, payload.MessageID, "delete", query, payload.MessageID, friendID)
+}
+
+// applyMessageChange runs the update for a friend's edit or delete and
+// reports the change if it matched a message
+func (m *Manager) applyMessageChange(friendID uint32, messageUUID, kind, query string, args ...interface{}) {
+	result, err := m.db.Exec(query, args...)
+	if err != nil {
+		logging.Errorf("Failed to apply %s from friend %d: %v", kind, friendID, err)
+		return
+	}
+	m.cache.invalidate(friendID)
+	if changed, err := result.RowsAffected(); err != nil || changed == 0 {
+		logging.Infof("Ignoring %s from friend %d of unknown message %s", kind, friendID, messageUUID)
+		return
+	}
+
+	m.mu.RLock()
+	callback := m.onMessageChanged
+	m.mu.RUnlock()
+
+	if callback != nil {
+		callback(friendID, messageUUID)
+	}
+}
@@ -0,0 +1,185 @@
+package message
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// findMessage returns the visible message with a UUID, or nil
+func findMessage(t *testing.T, mgr *Manager, friendID uint32, messageUUID string) *Message {
+	t.Helper()
+
+	messages, err := mgr.GetMessages(friendID, 100, 0)
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	for _, msg := range messages {
+		if msg.UUID == messageUUID {
+			return msg
+		}
+	}
+	return nil
+}
+
+func TestSendMessageChanges(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	completeHandshake(t, mgr, 1)
+	msg, err := mgr.SendMessage(1, "helo", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		change  func() error
+		control string
+		content string
+	}{
+		{"edit", func() error { return mgr.EditMessage(msg.ID, "hello") }, controlEdit, "hello"},
+		{"delete", func() error { return mgr.DeleteMessage(msg.ID) }, controlDelete, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toxMgr.lastMessage = ""
+			if err := tt.change(); err != nil {
+				t.Fatalf("Change failed: %v", err)
+			}
+
+			header, body := decodeWire(toxMgr.lastMessage)
+			var payload messageChangePayload
+			if header.Control != tt.control || json.Unmarshal([]byte(body), &payload) != nil {
+				t.Fatalf("Expected a %s control message, got %q", tt.control, toxMgr.lastMessage)
+			}
+			if payload.MessageID != msg.UUID || payload.Content != tt.content {
+				t.Errorf("Unexpected payload %+v", payload)
+			}
+		})
+	}
+
+	if findMessage(t, mgr, 1, msg.UUID) != nil {
+		t.Error("Expected the message to be deleted locally too")
+	}
+}
+
+func TestReceiveMessageChanges(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	var changed []string
+	mgr.SetOnMessageChanged(func(friendID uint32, messageUUID string) {
+		changed = append(changed, messageUUID)
+	})
+
+	if mgr.HandleIncomingMessage(1, encodeWire(wireHeader{ID: "remote-1"}, "helo"), MessageTypeNormal) == nil {
+		t.Fatal("Expected the friend's message to be stored")
+	}
+	own, err := mgr.SendMessage(1, "mine", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	change := func(control, messageUUID, content string) {
+		data, _ := json.Marshal(messageChangePayload{MessageID: messageUUID, Content: content})
+		if msg := mgr.HandleIncomingMessage(1, encodeWire(wireHeader{Control: control}, string(data)), MessageTypeNormal); msg != nil {
+			t.Fatalf("Expected the %s not to be stored as a message", control)
+		}
+	}
+
+	// Edits of unknown messages and of our own messages are ignored
+	change(controlEdit, "unknown", "nope")
+	change(controlEdit, own.UUID, "hijacked")
+	if msg := findMessage(t, mgr, 1, own.UUID); msg == nil || msg.Content != "mine" {
+		t.Error("Expected the friend not to be able to edit our message")
+	}
+
+	change(controlEdit, "remote-1", "hello")
+	msg := findMessage(t, mgr, 1, "remote-1")
+	if msg == nil || msg.Content != "hello" || msg.OriginalContent != "helo" || msg.EditedAt == nil {
+		t.Fatalf("Expected the edit to be applied, got %+v", msg)
+	}
+
+	// Deletions are ignored while disabled and applied once enabled
+	mgr.SetHonorRemoteDeletions(false)
+	change(controlDelete, "remote-1", "")
+	if findMessage(t, mgr, 1, "remote-1") == nil {
+		t.Fatal("Expected the delete to be ignored while disabled")
+	}
+	mgr.SetHonorRemoteDeletions(true)
+	change(controlDelete, own.UUID, "")
+	change(controlDelete, "remote-1", "")
+	if findMessage(t, mgr, 1, "remote-1") != nil {
+		t.Error("Expected the delete to be applied")
+	}
+	if findMessage(t, mgr, 1, own.UUID) == nil {
+		t.Error("Expected the friend not to be able to delete our message")
+	}
+
+	if len(changed) != 2 || changed[0] != "remote-1" || changed[1] != "remote-1" {
+		t.Errorf("Expected two change notifications, got %v", changed)
+	}
+}
+
+func TestRemoteDeleteCapability(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	mgr.SetHonorRemoteDeletions(false)
+	if err := mgr.AnnounceCapabilities(1); err != nil {
+		t.Fatalf("AnnounceCapabilities failed: %v", err)
+	}
+	_, body := decodeWire(toxMgr.lastMessage)
+	caps, err := DecodeCapabilities(body)
+	if err != nil {
+		t.Fatalf("DecodeCapabilities failed: %v", err)
+	}
+	if caps.Supports(FeatureRemoteDelete) || !caps.Supports(FeatureEdits) {
+		t.Errorf("Expected edits without remote deletion, got %b", caps.Features)
+	}
+}
+
+func TestDoubleEditKeepsOriginal(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	if mgr.HandleIncomingMessage(1, encodeWire(wireHeader{ID: "remote-1"}, "helo"), MessageTypeNormal) == nil {
+		t.Fatal("Expected the friend's message to be stored")
+	}
+	own, err := mgr.SendMessage(1, "mine", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	remoteEdit := func(content string) error {
+		data, _ := json.Marshal(messageChangePayload{MessageID: "remote-1", Content: content})
+		mgr.HandleIncomingMessage(1, encodeWire(wireHeader{Control: controlEdit}, string(data)), MessageTypeNormal)
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		uuid     string
+		edit     func(content string) error
+		original string
+	}{
+		{"remote", "remote-1", remoteEdit, "helo"},
+		{"local", own.UUID, func(content string) error { return mgr.EditMessage(own.ID, content) }, "mine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, content := range []string{"first edit", "second edit"} {
+				if err := tt.edit(content); err != nil {
+					t.Fatalf("Edit failed: %v", err)
+				}
+			}
+
+			msg := findMessage(t, mgr, 1, tt.uuid)
+			if msg == nil || msg.Content != "second edit" || msg.OriginalContent != tt.original {
+				t.Errorf("Expected the original text to survive a second edit, got %+v", msg)
+			}
+		})
+	}
+}
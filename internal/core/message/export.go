@@ -0,0 +1,200 @@
+package message
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExportFormat selects the file format of a conversation export
+type ExportFormat string
+
+// Supported conversation export formats
+const (
+	ExportFormatJSON ExportFormat = "json"
+	ExportFormatHTML ExportFormat = "html"
+)
+
+// exportedFile describes a file attached to an exported message
+type exportedFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+// exportedMessage is one message in a JSON export
+type exportedMessage struct {
+	ID              string        `json:"id"`
+	Timestamp       time.Time     `json:"timestamp"`
+	Direction       string        `json:"direction"` // "sent" or "received"
+	Sender          string        `json:"sender"`
+	Type            string        `json:"type"`
+	Content         string        `json:"content"`
+	EditedAt        *time.Time    `json:"edited_at,omitempty"`
+	OriginalContent string        `json:"original_content,omitempty"`
+	ReplyTo         string        `json:"reply_to,omitempty"` // ID of the message replied to
+	File            *exportedFile `json:"file,omitempty"`
+}
+
+// exportHTMLHeader starts a self-contained HTML export; %s is the escaped title
+const exportHTMLHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+</head>
+<body style="font-family: sans-serif; max-width: 48em; margin: 2em auto; color: #222;">
+<h1 style="font-size: 1.4em;">%s</h1>
+`
+
+// exportHTMLFooter ends an HTML export
+const exportHTMLFooter = `</body>
+</html>
+`
+
+// ExportConversation writes every non-deleted message exchanged with a friend
+// to w, oldest first. Messages are streamed from the database one at a time,
+// so large histories are never held in memory.
+func (m *Manager) ExportConversation(friendID uint32, format ExportFormat, w io.Writer) error {
+	if format != ExportFormatJSON && format != ExportFormatHTML {
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	rows, err := m.db.Query(`
+		SELECT id, uuid, friend_id, content, message_type, is_outgoing,
+		       timestamp, delivered_at, read_at, edited_at, original_content,
+		       file_path, file_size, file_type, is_deleted, reply_to_id, reply_to_uuid,
+		       failed_at
+		FROM messages
+		WHERE friend_id = ? AND is_deleted = 0
+		ORDER BY timestamp ASC, id ASC
+	`, friendID)
+	if err != nil {
+		return fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	friendName := m.friendName(friendID)
+	out := bufio.NewWriter(w)
+
+	if format == ExportFormatJSON {
+		fmt.Fprintf(out, "{\n  \"friend_id\": %d,\n  \"contact\": %s,\n  \"exported_at\": %s,\n  \"messages\": [",
+			friendID, jsonString(friendName), jsonString(time.Now().UTC().Format(time.RFC3339)))
+	} else {
+		title := html.EscapeString("Conversation with " + friendName)
+		fmt.Fprintf(out, exportHTMLHeader, title, title)
+	}
+
+	count := 0
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return err
+		}
+		entry := exportEntry(msg, friendName)
+
+		if format == ExportFormatJSON {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return fmt.Errorf("failed to encode message: %w", err)
+			}
+			if count > 0 {
+				out.WriteString(",")
+			}
+			out.WriteString("\n    ")
+			out.Write(data)
+		} else {
+			writeHTMLMessage(out, entry)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read messages: %w", err)
+	}
+
+	if format == ExportFormatJSON {
+		out.WriteString("\n  ]\n}\n")
+	} else {
+		out.WriteString(exportHTMLFooter)
+	}
+
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+// exportEntry converts a stored message for export
+func exportEntry(msg *Message, friendName string) exportedMessage {
+	entry := exportedMessage{
+		ID:              msg.UUID,
+		Timestamp:       msg.Timestamp,
+		Direction:       "received",
+		Sender:          friendName,
+		Type:            messageTypeName(msg.MessageType),
+		Content:         msg.Content,
+		EditedAt:        msg.EditedAt,
+		OriginalContent: msg.OriginalContent,
+		ReplyTo:         msg.ReplyToUUID,
+	}
+	if msg.IsOutgoing {
+		entry.Direction = "sent"
+		entry.Sender = "You"
+	}
+	if msg.FilePath != "" {
+		entry.File = &exportedFile{Path: msg.FilePath, Size: msg.FileSize, Type: msg.FileType}
+	}
+	return entry
+}
+
+// writeHTMLMessage writes one message of an HTML export
+func writeHTMLMessage(out *bufio.Writer, entry exportedMessage) {
+	align, background := "left", "#eee"
+	if entry.Direction == "sent" {
+		align, background = "right", "#d7ebff"
+	}
+
+	fmt.Fprintf(out, `<div style="text-align: %s; margin: 0.5em 0;">`+"\n", align)
+	fmt.Fprintf(out, `<div style="display: inline-block; text-align: left; background: %s; border-radius: 0.5em; padding: 0.4em 0.7em; max-width: 80%%;">`+"\n", background)
+	fmt.Fprintf(out, `<div style="font-size: 0.8em; color: #666;"><b>%s</b> %s`,
+		html.EscapeString(entry.Sender), entry.Timestamp.Local().Format(transcriptTimeFormat))
+	if entry.EditedAt != nil {
+		out.WriteString(" (edited)")
+	}
+	out.WriteString("</div>\n")
+
+	content := strings.ReplaceAll(html.EscapeString(entry.Content), "\n", "<br>\n")
+	fmt.Fprintf(out, "<div>%s</div>\n", content)
+	if entry.File != nil {
+		fmt.Fprintf(out, `<div style="font-size: 0.8em; color: #666;">Attachment: %s</div>`+"\n", html.EscapeString(entry.File.Path))
+	}
+	out.WriteString("</div>\n</div>\n")
+}
+
+// messageTypeName returns the name of a message type used in exports
+func messageTypeName(messageType MessageType) string {
+	switch messageType {
+	case MessageTypeAction:
+		return "action"
+	case MessageTypeFile:
+		return "file"
+	case MessageTypeVoice:
+		return "voice"
+	case MessageTypeImage:
+		return "image"
+	case MessageTypeVideo:
+		return "video"
+	default:
+		return "text"
+	}
+}
+
+// jsonString encodes a string as a JSON literal
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,95 @@
+package message
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportConversation(t *testing.T) {
+	mgr, _, _, contactMgr, cleanup := setupTestManager(t)
+	defer cleanup()
+	contactMgr.AddContact(1, namedContact("Alice <3"))
+
+	first := mgr.HandleIncomingMessage(1, "hi\nthere", MessageTypeNormal)
+	time.Sleep(2 * time.Millisecond) // Keep timestamps distinct
+	sent, err := mgr.SendMessage(1, "<b>hello</b>", MessageTypeNormal)
+	if err != nil || first == nil {
+		t.Fatalf("Failed to store messages: %v", err)
+	}
+	if err := mgr.EditMessage(sent.ID, "hello"); err != nil {
+		t.Fatalf("EditMessage failed: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	deleted := mgr.HandleIncomingMessage(1, "deleted", MessageTypeNormal)
+	if err := mgr.DeleteMessage(deleted.ID); err != nil {
+		t.Fatalf("DeleteMessage failed: %v", err)
+	}
+	if _, err := mgr.db.Exec(`UPDATE messages SET file_path = '/tmp/photo.png', file_size = 42 WHERE id = ?`, first.ID); err != nil {
+		t.Fatalf("Failed to attach file: %v", err)
+	}
+
+	var jsonOut bytes.Buffer
+	if err := mgr.ExportConversation(1, ExportFormatJSON, &jsonOut); err != nil {
+		t.Fatalf("JSON export failed: %v", err)
+	}
+
+	var export struct {
+		FriendID uint32            `json:"friend_id"`
+		Contact  string            `json:"contact"`
+		Messages []exportedMessage `json:"messages"`
+	}
+	if err := json.Unmarshal(jsonOut.Bytes(), &export); err != nil {
+		t.Fatalf("Export is not valid JSON: %v\n%s", err, jsonOut.String())
+	}
+	if export.FriendID != 1 || export.Contact != "Alice <3" || len(export.Messages) != 2 {
+		t.Fatalf("Unexpected export %+v", export)
+	}
+
+	received, mine := export.Messages[0], export.Messages[1]
+	if received.Direction != "received" || received.Sender != "Alice <3" || received.Content != "hi\nthere" {
+		t.Errorf("Unexpected first message %+v", received)
+	}
+	if received.File == nil || received.File.Path != "/tmp/photo.png" || received.File.Size != 42 {
+		t.Errorf("Expected file reference, got %+v", received.File)
+	}
+	if mine.Direction != "sent" || mine.Content != "hello" || mine.EditedAt == nil || mine.OriginalContent != "<b>hello</b>" {
+		t.Errorf("Expected the edited message with its original, got %+v", mine)
+	}
+
+	var htmlOut bytes.Buffer
+	if err := mgr.ExportConversation(1, ExportFormatHTML, &htmlOut); err != nil {
+		t.Fatalf("HTML export failed: %v", err)
+	}
+	page := htmlOut.String()
+	for _, want := range []string{"<title>Conversation with Alice &lt;3</title>", "hi<br>\nthere", "(edited)", "Attachment: /tmp/photo.png", "</html>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected HTML to contain %q", want)
+		}
+	}
+	if strings.Contains(page, "<b>hello</b>") || strings.Contains(page, "deleted") {
+		t.Errorf("HTML export is not escaped or includes deleted messages:\n%s", page)
+	}
+
+	if err := mgr.ExportConversation(1, "pdf", &htmlOut); err == nil {
+		t.Error("Expected error for an unsupported format")
+	}
+}
+
+func TestExportConversationEmpty(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	var out bytes.Buffer
+	if err := mgr.ExportConversation(7, ExportFormatJSON, &out); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	var export struct {
+		Messages []exportedMessage `json:"messages"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &export); err != nil || len(export.Messages) != 0 {
+		t.Errorf("Expected an empty message list, got %q (%v)", out.String(), err)
+	}
+}
@@ -0,0 +1,73 @@
+package message
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// controlFileChecksum marks a wire message announcing the checksum of a file
+// we are sending
+const controlFileChecksum = "filesum"
+
+// fileChecksumPayload is the body of a file checksum control message. Tox
+// file numbers differ on each side, so the file is named by name and size.
+type fileChecksumPayload struct {
+	Name     string `json:"n"`
+	Size     uint64 `json:"s"`
+	Checksum string `json:"h"` // Hex SHA-256
+}
+
+// SendFileChecksum tells a friend the SHA-256 checksum of a file we offered
+// them. Friends whose clients do not verify checksums are not sent anything.
+func (m *Manager) SendFileChecksum(friendID uint32, fileName string, fileSize uint64, checksum string) error {
+	if !m.peerSupports(friendID, FeatureFileChecksums) {
+		return nil
+	}
+
+	data, err := json.Marshal(fileChecksumPayload{Name: fileName, Size: fileSize, Checksum: checksum})
+	if err != nil {
+		return fmt.Errorf("failed to encode file checksum: %w", err)
+	}
+	wireContent := encodeWire(wireHeader{Control: controlFileChecksum}, string(data))
+	if err := m.toxMgr.SendMessage(friendID, wireContent, toxcore.MessageTypeNormal); err != nil {
+		return fmt.Errorf("failed to send file checksum: %w", err)
+	}
+	return nil
+}
+
+// SetOnFileChecksum sets the callback invoked when a friend announces the
+// checksum of a file they send
+func (m *Manager) SetOnFileChecksum(callback func(friendID uint32, fileName string, fileSize uint64, checksum string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onFileChecksum = callback
+}
+
+// handleFileChecksum passes a checksum announced by a friend on to the
+// file checksum callback
+func (m *Manager) handleFileChecksum(friendID uint32, body string) {
+	var payload fileChecksumPayload
+	if err := json.Unmarshal([]byte(body), &payload); err != nil || !validChecksum(payload.Checksum) {
+		logging.Warnf("Ignoring malformed file checksum from friend %d", friendID)
+		return
+	}
+
+	m.mu.RLock()
+	callback := m.onFileChecksum
+	m.mu.RUnlock()
+
+	if callback != nil {
+		callback(friendID, payload.Name, payload.Size, payload.Checksum)
+	}
+}
+
+// validChecksum reports whether s is a hex encoded SHA-256 checksum
+func validChecksum(s string) bool {
+	decoded, err := hex.DecodeString(s)
+	return err == nil && len(decoded) == 32
+}
@@ -0,0 +1,55 @@
+package message
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFileChecksumRoundTrip(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	checksum := strings.Repeat("ab", 32)
+
+	// Nothing is sent before the friend announced checksum support
+	toxMgr.lastMessage = ""
+	if err := mgr.SendFileChecksum(1, "photo.png", 42, checksum); err != nil || toxMgr.lastMessage != "" {
+		t.Fatalf("Expected nothing to be sent, got %q (%v)", toxMgr.lastMessage, err)
+	}
+
+	completeHandshake(t, mgr, 1)
+	if err := mgr.SendFileChecksum(1, "photo.png", 42, checksum); err != nil {
+		t.Fatalf("SendFileChecksum failed: %v", err)
+	}
+	header, body := decodeWire(toxMgr.lastMessage)
+	var payload fileChecksumPayload
+	if header.Control != controlFileChecksum || json.Unmarshal([]byte(body), &payload) != nil ||
+		payload.Name != "photo.png" || payload.Size != 42 || payload.Checksum != checksum {
+		t.Fatalf("Expected a file checksum control message, got %q", toxMgr.lastMessage)
+	}
+
+	var gotFriend uint32
+	var gotName, gotChecksum string
+	var gotSize uint64
+	calls := 0
+	mgr.SetOnFileChecksum(func(friendID uint32, name string, size uint64, sum string) {
+		calls++
+		gotFriend, gotName, gotSize, gotChecksum = friendID, name, size, sum
+	})
+
+	// The friend's announcement reaches the callback and is not stored
+	if stored := mgr.HandleIncomingMessage(2, toxMgr.lastMessage, MessageTypeNormal); stored != nil {
+		t.Fatal("Expected the checksum not to be stored as a message")
+	}
+	if calls != 1 || gotFriend != 2 || gotName != "photo.png" || gotSize != 42 || gotChecksum != checksum {
+		t.Errorf("Unexpected callback %d: %d %q %d %q", calls, gotFriend, gotName, gotSize, gotChecksum)
+	}
+
+	// Malformed checksums are dropped
+	data, _ := json.Marshal(fileChecksumPayload{Name: "photo.png", Size: 42, Checksum: "nope"})
+	mgr.HandleIncomingMessage(2, encodeWire(wireHeader{Control: controlFileChecksum}, string(data)), MessageTypeNormal)
+	if calls != 1 {
+		t.Errorf("Expected a malformed checksum to be ignored, got %d calls", calls)
+	}
+}
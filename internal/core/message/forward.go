@@ -0,0 +1,121 @@
+package message
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// SetFileSender sets how forwarded files are sent to a friend, e.g. by
+// starting a file transfer
+func (m *Manager) SetFileSender(send func(friendID uint32, filePath string) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fileSender = send
+}
+
+// ForwardMessage sends a copy of a stored message to another friend, marked
+// as forwarded. File messages are sent again from the local copy of the file,
+// which the new message shares with the original.
+func (m *Manager) ForwardMessage(messageID int64, toFriendID uint32) (*Message, error) {
+	original, err := m.GetMessage(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if original.IsDeleted {
+		return nil, fmt.Errorf("message %d was deleted", messageID)
+	}
+	if m.isDisappearing(messageID) {
+		return nil, fmt.Errorf("disappearing messages cannot be forwarded")
+	}
+	if _, exists := m.contacts.GetContact(toFriendID); !exists {
+		return nil, fmt.Errorf("friend %d not found", toFriendID)
+	}
+	if m.contacts.IsBlocked(toFriendID) {
+		return nil, fmt.Errorf("friend %d is blocked", toFriendID)
+	}
+
+	msg := &Message{
+		UUID:        uuid.New().String(),
+		FriendID:    toFriendID,
+		Content:     original.Content,
+		MessageType: original.MessageType,
+		IsOutgoing:  true,
+		Timestamp:   time.Now(),
+		IsForwarded: true,
+	}
+
+	if !isFileMessage(original) {
+		if err := m.deliver(msg); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	if err := m.forwardFile(msg, original); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// isFileMessage reports whether a message carries a local file
+func isFileMessage(msg *Message) bool {
+	switch msg.MessageType {
+	case MessageTypeFile, MessageTypeImage, MessageTypeVideo, MessageTypeVoice:
+		return msg.FilePath != ""
+	}
+	return false
+}
+
+// forwardFile stores the forwarded record of a file message and sends the
+// original's file to the new friend
+func (m *Manager) forwardFile(msg, original *Message) error {
+	m.mu.RLock()
+	send := m.fileSender
+	m.mu.RUnlock()
+
+	if send == nil {
+		return fmt.Errorf("files cannot be forwarded")
+	}
+	if _, err := os.Stat(original.FilePath); err != nil {
+		return fmt.Errorf("failed to read file to forward: %w", err)
+	}
+
+	msg.FileSize = original.FileSize
+	msg.FileType = original.FileType
+
+	// Share the stored file rather than copying it; files outside the store
+	// are not reference counted, so deleting the copy must not remove them
+	if err := m.db.RetainFile(original.FilePath); err == nil {
+		msg.FilePath = original.FilePath
+	}
+
+	m.applyExpiry(msg, m.GetDisappearingTimer(msg.FriendID))
+	if err := m.saveMessage(msg); err != nil {
+		if msg.FilePath != "" {
+			if releaseErr := m.db.ReleaseFile(msg.FilePath); releaseErr != nil {
+				logging.Warnf("Failed to release forwarded file: %v", releaseErr)
+			}
+		}
+		return fmt.Errorf("failed to save message: %w", err)
+	}
+
+	if err := send(msg.FriendID, original.FilePath); err != nil {
+		return fmt.Errorf("failed to send file: %w", err)
+	}
+	return nil
+}
+
+// isDisappearing reports whether a message is set to expire
+func (m *Manager) isDisappearing(messageID int64) bool {
+	var expiresAt sql.NullTime
+	if err := m.db.QueryRow("SELECT expires_at FROM messages WHERE id = ?", messageID).Scan(&expiresAt); err != nil {
+		return false
+	}
+	return expiresAt.Valid
+}
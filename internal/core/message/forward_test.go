@@ -0,0 +1,142 @@
+package message
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestForwardTextMessage(t *testing.T) {
+	mgr, _, toxMgr, contactMgr, cleanup := setupTestManager(t)
+	defer cleanup()
+	contactMgr.AddContact(2, map[string]string{"name": "Other Friend"})
+	completeHandshake(t, mgr, 2)
+
+	original := mgr.HandleIncomingMessage(1, "worth sharing", MessageTypeNormal)
+	if original == nil {
+		t.Fatal("Expected the incoming message to be stored")
+	}
+
+	forwarded, err := mgr.ForwardMessage(original.ID, 2)
+	if err != nil {
+		t.Fatalf("ForwardMessage failed: %v", err)
+	}
+	if forwarded.FriendID != 2 || !forwarded.IsOutgoing || !forwarded.IsForwarded || forwarded.UUID == original.UUID {
+		t.Errorf("Expected a new outgoing forwarded message to friend 2, got %+v", forwarded)
+	}
+
+	header, body := decodeWire(toxMgr.lastMessage)
+	if toxMgr.lastFriendID != 2 || body != "worth sharing" || !header.Forwarded {
+		t.Errorf("Expected the content sent to friend 2 as forwarded, got %q to %d", toxMgr.lastMessage, toxMgr.lastFriendID)
+	}
+
+	messages, err := mgr.GetMessages(2, 10, 0)
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(messages) != 1 || !messages[0].IsForwarded || messages[0].Content != "worth sharing" {
+		t.Errorf("Expected the stored message to be marked forwarded, got %v", messages)
+	}
+
+	// The friend sees the origin too
+	received := mgr.HandleIncomingMessage(1, encodeWire(wireHeader{ID: "forwarded-in", Forwarded: true}, "passed on"), MessageTypeNormal)
+	if received == nil || !received.IsForwarded {
+		t.Error("Expected a received forwarded message to be marked")
+	}
+}
+
+func TestForwardFileMessage(t *testing.T) {
+	mgr, db, _, contactMgr, cleanup := setupTestManager(t)
+	defer cleanup()
+	contactMgr.AddContact(2, map[string]string{"name": "Other Friend"})
+
+	dir := t.TempDir()
+	content := []byte("holiday photo")
+	src := filepath.Join(dir, "photo.jpg")
+	if err := os.WriteFile(src, content, 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+	stored, err := db.StoreFile(src, checksum, filepath.Join(dir, "files"))
+	if err != nil {
+		t.Fatalf("StoreFile failed: %v", err)
+	}
+
+	original := &Message{
+		UUID: "photo", FriendID: 1, Content: "photo.jpg", MessageType: MessageTypeImage,
+		Timestamp: time.Now(), FilePath: stored, FileSize: int64(len(content)), FileType: "image/jpeg",
+	}
+	if err := mgr.saveMessage(original); err != nil {
+		t.Fatalf("Failed to save message: %v", err)
+	}
+
+	if _, err := mgr.ForwardMessage(original.ID, 2); err == nil {
+		t.Error("Expected an error forwarding a file without a file sender")
+	}
+
+	var sentTo uint32
+	var sentPath string
+	mgr.SetFileSender(func(friendID uint32, filePath string) error {
+		sentTo, sentPath = friendID, filePath
+		return nil
+	})
+
+	forwarded, err := mgr.ForwardMessage(original.ID, 2)
+	if err != nil {
+		t.Fatalf("ForwardMessage failed: %v", err)
+	}
+	if sentTo != 2 || sentPath != stored {
+		t.Errorf("Expected the stored file sent to friend 2, got %q to %d", sentPath, sentTo)
+	}
+	if forwarded.FilePath != stored || forwarded.FileSize != original.FileSize || !forwarded.IsForwarded {
+		t.Errorf("Expected the forwarded message to share the file, got %+v", forwarded)
+	}
+
+	// Deleting the original leaves the file to the forwarded copy
+	var refs int
+	if err := db.QueryRow(`SELECT ref_count FROM file_blobs WHERE checksum = ?`, checksum).Scan(&refs); err != nil || refs != 2 {
+		t.Errorf("Expected 2 references to the file, got %d (%v)", refs, err)
+	}
+	if err := db.ReleaseFile(stored); err != nil {
+		t.Fatalf("ReleaseFile failed: %v", err)
+	}
+	if _, err := os.Stat(stored); err != nil {
+		t.Errorf("Expected the file to be kept for the forwarded message: %v", err)
+	}
+}
+
+func TestForwardMessageRejected(t *testing.T) {
+	mgr, _, _, contactMgr, cleanup := setupTestManager(t)
+	defer cleanup()
+	contactMgr.AddContact(2, map[string]string{"name": "Other Friend"})
+	contactMgr.blocked[3] = true
+	contactMgr.AddContact(3, map[string]string{"name": "Blocked Friend"})
+
+	msg := mgr.HandleIncomingMessage(1, "hello", MessageTypeNormal)
+	deleted := mgr.HandleIncomingMessage(1, "gone", MessageTypeNormal)
+	if err := mgr.DeleteMessage(deleted.ID); err != nil {
+		t.Fatalf("DeleteMessage failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		messageID int64
+		friendID  uint32
+	}{
+		{"missing message", 9999, 2},
+		{"deleted message", deleted.ID, 2},
+		{"unknown friend", msg.ID, 42},
+		{"blocked friend", msg.ID, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := mgr.ForwardMessage(tt.messageID, tt.friendID); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
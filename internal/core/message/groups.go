@@ -0,0 +1,43 @@
+package message
+
+import (
+	"fmt"
+
+	"github.com/opd-ai/toxcore"
+)
+
+// controlGroup marks a wire message carrying a group chat event. The payload
+// belongs to the group package; this package only carries it between friends.
+const controlGroup = "group"
+
+// SendGroupControl sends a group chat event to a friend
+func (m *Manager) SendGroupControl(friendID uint32, payload string) error {
+	if !m.peerSupports(friendID, FeatureGroups) {
+		return fmt.Errorf("friend %d does not support group chats", friendID)
+	}
+
+	wireContent := encodeWire(wireHeader{Control: controlGroup}, payload)
+	if err := m.toxMgr.SendMessage(friendID, wireContent, toxcore.MessageTypeNormal); err != nil {
+		return fmt.Errorf("failed to send group event: %w", err)
+	}
+	return nil
+}
+
+// SetOnGroupControl sets the callback invoked with group chat events received
+// from friends
+func (m *Manager) SetOnGroupControl(callback func(friendID uint32, payload string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onGroupControl = callback
+}
+
+// handleGroupControl passes a group chat event on to the group callback
+func (m *Manager) handleGroupControl(friendID uint32, body string) {
+	m.mu.RLock()
+	callback := m.onGroupControl
+	m.mu.RUnlock()
+
+	if callback != nil {
+		callback(friendID, body)
+	}
+}
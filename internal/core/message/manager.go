@@ -3,12 +3,13 @@ package message
 import (
 	"database/sql"
 	"fmt"
-	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opd-ai/toxcore"
+	"github.com/opd-ai/whisp/internal/logging"
 	"github.com/opd-ai/whisp/internal/storage"
 )
 
@@ -42,6 +43,10 @@ type Message struct {
 	FileType        string      `json:"file_type,omitempty"`
 	IsDeleted       bool        `json:"is_deleted"`
 	ReplyToID       *int64      `json:"reply_to_id,omitempty"`
+	ReplyToUUID     string      `json:"reply_to_uuid,omitempty"`
+	FailedAt        *time.Time  `json:"failed_at,omitempty"`  // Set when retries were exhausted
+	ExpiresAt       *time.Time  `json:"expires_at,omitempty"` // Set in conversations with disappearing messages
+	IsForwarded     bool        `json:"is_forwarded"`         // Copied from another conversation
 }
 
 // Manager manages messages and conversations
@@ -52,6 +57,43 @@ type Manager struct {
 
 	mu              sync.RWMutex
 	pendingMessages map[string]*Message // UUID -> Message
+	senderPolicy    SenderPolicy
+
+	peerCapabilities map[uint32]Capabilities // Capabilities announced by online friends
+	announced        map[uint32]bool         // Friends we sent our capabilities to this session
+	greeted          map[uint32]bool         // Friends we sent the hello marker to this session
+	whispPeers       map[uint32]bool         // Friends known to run Whisp, cached from the database
+
+	retryPolicy  RetryPolicy
+	outbox       map[string]*queuedSend // UUID -> failed send awaiting retry
+	onSendFailed func(*Message)
+	onStatus     func(friendID uint32, messageUUID string) // Outgoing message delivered or read
+
+	sendReceipts    bool
+	showReceipts    bool
+	pendingReceipts map[uint32][]string // Read receipts waiting to be sent, by friend
+	receiptTimers   map[uint32]*time.Timer
+
+	onFileChecksum func(friendID uint32, fileName string, fileSize uint64, checksum string)
+	fileSender     func(friendID uint32, filePath string) error // Sends forwarded files
+
+	sendTyping     bool
+	showTyping     bool
+	friendTyping   map[uint32]bool // Friends currently typing to us
+	onFriendTyping func(friendID uint32, typing bool)
+
+	disappearingEnabled bool
+	onMessagesExpired   func(friendIDs []uint32)
+
+	retention           time.Duration // Kept this long without a per-friend retention; 0 is forever
+	retentionHardDelete bool
+
+	ignoreRemoteDeletions bool
+	onMessageChanged      func(friendID uint32, messageUUID string)
+
+	onGroupControl func(friendID uint32, payload string)
+
+	cache *messageCache // Recently loaded conversation pages
 }
 
 // ToxManager interface for Tox operations
@@ -62,15 +104,27 @@ type ToxManager interface {
 // ContactManager interface for contact operations
 type ContactManager interface {
 	GetContact(friendID uint32) (interface{}, bool)
+	IsBlocked(friendID uint32) bool
+	IsEstablished(friendID uint32) bool
 }
 
 // NewManager creates a new message manager
 func NewManager(db *storage.Database, toxMgr ToxManager, contacts ContactManager) *Manager {
 	return &Manager{
-		db:              db,
-		toxMgr:          toxMgr,
-		contacts:        contacts,
-		pendingMessages: make(map[string]*Message),
+		db:               db,
+		toxMgr:           toxMgr,
+		contacts:         contacts,
+		pendingMessages:  make(map[string]*Message),
+		peerCapabilities: make(map[uint32]Capabilities),
+		announced:        make(map[uint32]bool),
+		greeted:          make(map[uint32]bool),
+		whispPeers:       make(map[uint32]bool),
+		retryPolicy:      DefaultRetryPolicy,
+		outbox:           make(map[string]*queuedSend),
+		friendTyping:     make(map[uint32]bool),
+		pendingReceipts:  make(map[uint32][]string),
+		receiptTimers:    make(map[uint32]*time.Timer),
+		cache:            newMessageCache(DefaultMessageCacheSize),
 	}
 }
 
@@ -86,11 +140,32 @@ func (m *Manager) SendMessage(friendID uint32, content string, messageType Messa
 		Timestamp:   time.Now(),
 	}
 
+	if err := m.deliver(msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// deliver stores an outgoing message and sends it via Tox with its metadata header
+func (m *Manager) deliver(msg *Message) error {
+	m.applyExpiry(msg, m.GetDisappearingTimer(msg.FriendID))
+
 	// Save to database first
 	if err := m.saveMessage(msg); err != nil {
-		return nil, fmt.Errorf("failed to save message: %w", err)
+		return fmt.Errorf("failed to save message: %w", err)
 	}
 
+	if err := m.transmit(msg); err != nil {
+		m.queueRetry(msg)
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+// transmit sends a stored message via Tox and records its delivery
+func (m *Manager) transmit(msg *Message) error {
 	// Add to pending
 	m.mu.Lock()
 	m.pendingMessages[msg.UUID] = msg
@@ -98,20 +173,29 @@ func (m *Manager) SendMessage(friendID uint32, content string, messageType Messa
 
 	// Convert message type for Tox
 	var toxMsgType toxcore.MessageType
-	switch messageType {
+	switch msg.MessageType {
 	case MessageTypeAction:
 		toxMsgType = toxcore.MessageTypeAction
 	default:
 		toxMsgType = toxcore.MessageTypeNormal
 	}
 
+	// Only Whisp peers that announced support get the metadata header; friends
+	// whose client is unknown get the invisible hello marker once
+	wireContent := msg.Content
+	if m.peerSupports(msg.FriendID, FeatureMessageIDs) {
+		wireContent = encodeWire(wireHeader{ID: msg.UUID, ReplyTo: msg.ReplyToUUID, TTL: wireTTL(msg), Forwarded: msg.IsForwarded}, msg.Content)
+	} else if m.shouldGreet(msg.FriendID) {
+		wireContent += helloMarker
+	}
+
 	// Send via Tox
-	if err := m.toxMgr.SendMessage(friendID, content, toxMsgType); err != nil {
+	if err := m.toxMgr.SendMessage(msg.FriendID, wireContent, toxMsgType); err != nil {
 		// Mark as failed
 		m.mu.Lock()
 		delete(m.pendingMessages, msg.UUID)
 		m.mu.Unlock()
-		return nil, fmt.Errorf("failed to send message: %w", err)
+		return err
 	}
 
 	// Mark as delivered (for now, in real implementation this would be done by callback)
@@ -119,60 +203,147 @@ func (m *Manager) SendMessage(friendID uint32, content string, messageType Messa
 	msg.DeliveredAt = &now
 
 	// Update database
-	go func() {
+	m.db.Async(func() {
 		query := `UPDATE messages SET delivered_at = ? WHERE id = ?`
 		if _, err := m.db.Exec(query, now, msg.ID); err != nil {
-			log.Printf("Failed to update message delivery status: %v", err)
+			logging.Errorf("Failed to update message delivery status: %v", err)
+			return
 		}
-	}()
+		m.cache.invalidate(msg.FriendID)
+		m.notifyStatus(msg.FriendID, msg.UUID)
+	})
 
-	return msg, nil
+	return nil
 }
 
-// HandleIncomingMessage handles an incoming message
+// HandleIncomingMessage handles an incoming message.
+// Returns nil if the message was held or rejected by the sender policy, or
+// dropped because the friend is blocked.
 func (m *Manager) HandleIncomingMessage(friendID uint32, content string, messageType MessageType) *Message {
+	// Nothing from a blocked friend is stored or acted on, including typing
+	// and other control messages
+	if m.contacts != nil && m.contacts.IsBlocked(friendID) {
+		logging.Infof("Dropping message from blocked friend %d", friendID)
+		return nil
+	}
+
+	header, body := decodeWire(content)
+
+	// A Whisp header or hello marker shows the friend runs Whisp
+	if body != content {
+		m.noteWhispPeer(friendID)
+	} else if trimmed, ok := strings.CutSuffix(body, helloMarker); ok {
+		body = trimmed
+		m.noteWhispPeer(friendID)
+	}
+
+	// Control messages update protocol state and are never stored
+	switch header.Control {
+	case "":
+	case controlCapabilities:
+		m.handleCapabilities(friendID, body)
+		return nil
+	case controlReaction:
+		m.handleReaction(friendID, body)
+		return nil
+	case controlFileChecksum:
+		m.handleFileChecksum(friendID, body)
+		return nil
+	case controlTyping:
+		m.handleTyping(friendID, body)
+		return nil
+	case controlReadReceipt:
+		m.handleReadReceipt(friendID, body)
+		return nil
+	case controlDisappearingTimer:
+		m.handleDisappearingTimer(friendID, body)
+		return nil
+	case controlEdit:
+		m.handleEdit(friendID, body)
+		return nil
+	case controlDelete:
+		m.handleDelete(friendID, body)
+		return nil
+	case controlGroup:
+		m.handleGroupControl(friendID, body)
+		return nil
+	default:
+		logging.Infof("Ignoring unknown control message %q from friend %d", header.Control, friendID)
+		return nil
+	}
+
+	// A friend stops typing once their message arrives
+	m.setFriendTyping(friendID, false)
+
+	// Whisp peers with disappearing messages say how long each message lives;
+	// other clients get the timer of the conversation
+	ttl := m.GetDisappearingTimer(friendID)
+	if m.peerSupports(friendID, FeatureDisappearing) {
+		ttl = time.Duration(header.TTL) * time.Second
+	}
+
 	msg := &Message{
-		UUID:        uuid.New().String(),
+		UUID:        header.ID,
 		FriendID:    friendID,
-		Content:     content,
+		Content:     body,
 		MessageType: messageType,
 		IsOutgoing:  false,
 		Timestamp:   time.Now(),
+		ReplyToUUID: header.ReplyTo,
+		IsForwarded: header.Forwarded,
 	}
 
-	// Save to database
-	if err := m.saveMessage(msg); err != nil {
-		log.Printf("Failed to save incoming message: %v", err)
+	if msg.UUID == "" {
+		// Plain message from a client that does not share message identity
+		msg.UUID = uuid.New().String()
+	} else if m.lookupMessageID(friendID, msg.UUID) != nil {
+		logging.Infof("Ignoring duplicate message %s from friend %d", msg.UUID, friendID)
 		return nil
 	}
 
-	// Mark as read immediately (for demo, in real app user would mark as read)
-	now := time.Now()
-	msg.ReadAt = &now
+	m.applyExpiry(msg, ttl)
 
-	go func() {
-		query := `UPDATE messages SET read_at = ? WHERE id = ?`
-		if _, err := m.db.Exec(query, now, msg.ID); err != nil {
-			log.Printf("Failed to update message read status: %v", err)
-		}
-	}()
+	// Link replies to our stored copy of the referenced message
+	if msg.ReplyToUUID != "" {
+		msg.ReplyToID = m.lookupMessageID(friendID, msg.ReplyToUUID)
+	}
+
+	// Apply the unknown-sender policy before storing
+	if !m.admitSender(msg) {
+		return nil
+	}
+
+	// Save to database
+	if err := m.saveMessage(msg); err != nil {
+		logging.Errorf("Failed to save incoming message: %v", err)
+		return nil
+	}
 
+	// Stays unread until the conversation is opened and MarkAsRead runs
 	return msg
 }
 
 // GetMessages returns messages for a conversation
 func (m *Manager) GetMessages(friendID uint32, limit, offset int) ([]*Message, error) {
+	now := time.Now()
+	if messages, ok := m.cache.get(friendID, limit, offset, now); ok {
+		return messages, nil
+	}
+	version := m.cache.snapshot()
+
 	query := `
 		SELECT id, uuid, friend_id, content, message_type, is_outgoing,
 		       timestamp, delivered_at, read_at, edited_at, original_content,
-		       file_path, file_size, file_type, is_deleted, reply_to_id
+		       file_path, file_size, file_type, is_deleted, reply_to_id, reply_to_uuid,
+		       failed_at, expires_at, is_forwarded
 		FROM messages 
-		WHERE friend_id = ? AND is_deleted = 0
+		WHERE friend_id = ? AND is_deleted = 0 AND (expires_at IS NULL OR expires_at > ?)
 		ORDER BY timestamp DESC
 		LIMIT ? OFFSET ?
 	`
 
-	rows, err := m.db.Query(query, friendID, limit, offset)
+	// Expired messages are hidden even before the sweeper deletes them
+	rows, err := m.db.Query(query, friendID, now.UTC(), limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query messages: %w", err)
 	}
@@ -185,12 +356,14 @@ func (m *Manager) GetMessages(friendID uint32, limit, offset int) ([]*Message, e
 		var originalContent, filePath, fileType sql.NullString
 		var fileSize sql.NullInt64
 		var replyToID sql.NullInt64
+		var replyToUUID sql.NullString
+		var failedAt, expiresAt sql.NullTime
 
 		err := rows.Scan(
 			&msg.ID, &msg.UUID, &msg.FriendID, &msg.Content, &msg.MessageType,
 			&msg.IsOutgoing, &msg.Timestamp, &deliveredAt, &readAt, &editedAt,
 			&originalContent, &filePath, &fileSize, &fileType, &msg.IsDeleted,
-			&replyToID,
+			&replyToID, &replyToUUID, &failedAt, &expiresAt, &msg.IsForwarded,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan message: %w", err)
@@ -220,50 +393,170 @@ func (m *Manager) GetMessages(friendID uint32, limit, offset int) ([]*Message, e
 		if replyToID.Valid {
 			msg.ReplyToID = &replyToID.Int64
 		}
+		if replyToUUID.Valid {
+			msg.ReplyToUUID = replyToUUID.String
+		}
+		if failedAt.Valid {
+			msg.FailedAt = &failedAt.Time
+		}
+		if expiresAt.Valid {
+			msg.ExpiresAt = &expiresAt.Time
+		}
 
 		messages = append(messages, msg)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	return messages, rows.Err()
+	m.cache.put(friendID, limit, offset, messages, version)
+	return messages, nil
 }
 
-// EditMessage edits an existing message
+// EditMessage edits an existing message. Edits of our own messages are sent
+// to friends whose clients apply them.
 func (m *Manager) EditMessage(messageID int64, newContent string) error {
 	// Get original message
-	query := `SELECT content FROM messages WHERE id = ?`
-	var originalContent string
-	if err := m.db.QueryRow(query, messageID).Scan(&originalContent); err != nil {
+	query := `SELECT friend_id, uuid, is_outgoing FROM messages WHERE id = ?`
+	var friendID uint32
+	var messageUUID string
+	var outgoing bool
+	if err := m.db.QueryRow(query, messageID).Scan(&friendID, &messageUUID, &outgoing); err != nil {
 		return fmt.Errorf("failed to get original message: %w", err)
 	}
 
-	// Update message
+	if outgoing {
+		payload := messageChangePayload{MessageID: messageUUID, Content: newContent}
+		if err := m.sendMessageChange(friendID, controlEdit, FeatureEdits, payload); err != nil {
+			return err
+		}
+	}
+
+	// Update message, keeping the text from before the first edit
 	now := time.Now()
 	updateQuery := `
 		UPDATE messages 
-		SET content = ?, original_content = ?, edited_at = ? 
+		SET content = ?, original_content = COALESCE(NULLIF(original_content, ''), content), edited_at = ? 
 		WHERE id = ?
 	`
 
-	_, err := m.db.Exec(updateQuery, newContent, originalContent, now, messageID)
+	_, err := m.db.Exec(updateQuery, newContent, now, messageID)
 	if err != nil {
 		return fmt.Errorf("failed to update message: %w", err)
 	}
+	m.cache.invalidate(friendID)
 
 	return nil
 }
 
-// DeleteMessage deletes a message (soft delete)
+// DeleteMessage deletes a message (soft delete). Our own messages are also
+// deleted for friends whose clients honor remote deletions.
 func (m *Manager) DeleteMessage(messageID int64) error {
+	query := `SELECT friend_id, uuid, is_outgoing FROM messages WHERE id = ?`
+	var friendID uint32
+	var messageUUID string
+	var outgoing bool
+	if err := m.db.QueryRow(query, messageID).Scan(&friendID, &messageUUID, &outgoing); err != nil {
+		return fmt.Errorf("failed to get message: %w", err)
+	}
+
+	if outgoing {
+		payload := messageChangePayload{MessageID: messageUUID}
+		if err := m.sendMessageChange(friendID, controlDelete, FeatureRemoteDelete, payload); err != nil {
+			return err
+		}
+	}
+	return m.deleteLocal(friendID, messageID)
+}
+
+// deleteLocal soft-deletes a message without telling the friend
+func (m *Manager) deleteLocal(friendID uint32, messageID int64) error {
 	query := `UPDATE messages SET is_deleted = 1 WHERE id = ?`
 	_, err := m.db.Exec(query, messageID)
 	if err != nil {
 		return fmt.Errorf("failed to delete message: %w", err)
 	}
+	m.cache.invalidate(friendID)
 	return nil
 }
 
-// MarkAsRead marks messages as read
+// ClearConversation removes all messages exchanged with a friend while keeping
+// the contact. A soft clear hides the messages; a hard clear deletes the rows
+// and any media files they reference. Returns the number of messages cleared.
+func (m *Manager) ClearConversation(friendID uint32, hardDelete bool) (int, error) {
+	var filePaths []string
+	if hardDelete {
+		rows, err := m.db.Query(`SELECT file_path FROM messages WHERE friend_id = ? AND file_path IS NOT NULL AND file_path != ''`, friendID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to query message files: %w", err)
+		}
+		for rows.Next() {
+			var filePath string
+			if err := rows.Scan(&filePath); err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("failed to scan message file: %w", err)
+			}
+			filePaths = append(filePaths, filePath)
+		}
+		rows.Close()
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Keep the search index in step; hidden messages must not show up in results
+	if m.isFTSAvailable() {
+		if _, err := tx.Exec(`DELETE FROM messages_fts WHERE rowid IN (SELECT id FROM messages WHERE friend_id = ?)`, friendID); err != nil {
+			return 0, fmt.Errorf("failed to clear search index: %w", err)
+		}
+	}
+
+	var result sql.Result
+	if hardDelete {
+		if _, err := tx.Exec(`UPDATE file_transfers SET message_id = NULL WHERE message_id IN (SELECT id FROM messages WHERE friend_id = ?)`, friendID); err != nil {
+			return 0, fmt.Errorf("failed to detach file transfers: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM message_reactions WHERE friend_id = ?`, friendID); err != nil {
+			return 0, fmt.Errorf("failed to clear reactions: %w", err)
+		}
+		result, err = tx.Exec(`DELETE FROM messages WHERE friend_id = ?`, friendID)
+	} else {
+		result, err = tx.Exec(`UPDATE messages SET is_deleted = 1 WHERE friend_id = ? AND is_deleted = 0`, friendID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear conversation: %w", err)
+	}
+
+	cleared, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count cleared messages: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit conversation clear: %w", err)
+	}
+	m.cache.invalidate(friendID)
+
+	for _, filePath := range filePaths {
+		if err := m.db.ReleaseFile(filePath); err != nil {
+			logging.Warnf("Failed to remove media file %s: %v", filePath, err)
+		}
+	}
+
+	return int(cleared), nil
+}
+
+// MarkAsRead marks messages as read and tells the friend if read receipts
+// are sent
 func (m *Manager) MarkAsRead(friendID uint32) error {
+	uuids, err := m.unreadUUIDs(friendID)
+	if err != nil {
+		logging.Warnf("%v", err)
+	}
+
 	now := time.Now()
 	query := `
 		UPDATE messages 
@@ -271,14 +564,112 @@ func (m *Manager) MarkAsRead(friendID uint32) error {
 		WHERE friend_id = ? AND is_outgoing = 0 AND read_at IS NULL
 	`
 
-	_, err := m.db.Exec(query, now, friendID)
-	if err != nil {
+	if _, err := m.db.Exec(query, now, friendID); err != nil {
 		return fmt.Errorf("failed to mark messages as read: %w", err)
 	}
+	m.cache.invalidate(friendID)
 
+	m.queueReadReceipts(friendID, uuids)
 	return nil
 }
 
+// MarkAllAsRead marks every received message read across all conversations
+// and tells friends if read receipts are sent. Returns the number of messages
+// marked.
+func (m *Manager) MarkAllAsRead() (int, error) {
+	counts, err := m.GetUnreadCounts()
+	if err != nil {
+		return 0, err
+	}
+	uuids := make(map[uint32][]string)
+	for friendID := range counts {
+		friendUUIDs, err := m.unreadUUIDs(friendID)
+		if err != nil {
+			logging.Warnf("%v", err)
+		}
+		uuids[friendID] = friendUUIDs
+	}
+
+	result, err := m.db.Exec(`
+		UPDATE messages
+		SET read_at = ?
+		WHERE is_outgoing = 0 AND read_at IS NULL
+	`, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark messages as read: %w", err)
+	}
+	m.cache.clear()
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count read messages: %w", err)
+	}
+
+	for friendID, friendUUIDs := range uuids {
+		m.queueReadReceipts(friendID, friendUUIDs)
+	}
+	return int(marked), nil
+}
+
+// UnreadCount returns the number of received messages not yet read, across all conversations
+func (m *Manager) UnreadCount() (int, error) {
+	var count int
+	err := m.db.QueryRow(`
+		SELECT COUNT(*) FROM messages
+		WHERE is_outgoing = 0 AND read_at IS NULL AND is_deleted = 0
+	`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return count, nil
+}
+
+// MessageCount returns the number of stored messages not deleted
+func (m *Manager) MessageCount() (int, error) {
+	var count int
+	if err := m.db.QueryRow(`SELECT COUNT(*) FROM messages WHERE is_deleted = 0`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count messages: %w", err)
+	}
+	return count, nil
+}
+
+// GetUnreadCount returns the number of unread messages received from a friend
+func (m *Manager) GetUnreadCount(friendID uint32) (int, error) {
+	var count int
+	err := m.db.QueryRow(`
+		SELECT COUNT(*) FROM messages
+		WHERE friend_id = ? AND is_outgoing = 0 AND read_at IS NULL AND is_deleted = 0
+	`, friendID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return count, nil
+}
+
+// GetUnreadCounts returns the unread message count of every friend with
+// unread messages, in a single query
+func (m *Manager) GetUnreadCounts() (map[uint32]int, error) {
+	rows, err := m.db.Query(`
+		SELECT friend_id, COUNT(*) FROM messages
+		WHERE is_outgoing = 0 AND read_at IS NULL AND is_deleted = 0
+		GROUP BY friend_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[uint32]int)
+	for rows.Next() {
+		var friendID uint32
+		var count int
+		if err := rows.Scan(&friendID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan unread count: %w", err)
+		}
+		counts[friendID] = count
+	}
+	return counts, rows.Err()
+}
+
 // SearchMessages searches for messages containing text using FTS for optimal performance
 func (m *Manager) SearchMessages(query string, limit int) ([]*Message, error) {
 	if query == "" {
@@ -292,13 +683,130 @@ func (m *Manager) SearchMessages(query string, limit int) ([]*Message, error) {
 			return messages, nil
 		}
 		// If FTS fails, fall back to LIKE search
-		log.Printf("FTS search failed, falling back to LIKE: %v", err)
+		logging.Warnf("FTS search failed, falling back to LIKE: %v", err)
 	}
 
 	// Fallback to LIKE query
 	return m.searchWithLike(query, limit)
 }
 
+// SearchDirection restricts a filtered search to incoming or outgoing messages
+type SearchDirection int
+
+const (
+	// SearchDirectionAny matches messages in both directions
+	SearchDirectionAny SearchDirection = iota
+	// SearchDirectionIncoming matches only received messages
+	SearchDirectionIncoming
+	// SearchDirectionOutgoing matches only sent messages
+	SearchDirectionOutgoing
+)
+
+// SearchOptions holds the optional constraints for a filtered message search.
+// Zero values mean "no constraint" for every field.
+type SearchOptions struct {
+	Query        string          // Text to match; empty matches all messages
+	FriendID     *uint32         // Restrict to a single conversation
+	Since        time.Time       // Inclusive lower bound on timestamp
+	Until        time.Time       // Inclusive upper bound on timestamp
+	Direction    SearchDirection // Incoming, outgoing, or both
+	MessageTypes []MessageType   // Restrict to any of these types
+	Limit        int             // Maximum results; defaults to 100
+}
+
+// SearchMessagesFiltered searches messages combining a text query with
+// date-range, conversation, direction, and message-type filters
+func (m *Manager) SearchMessagesFiltered(opts SearchOptions) ([]*Message, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 100
+	}
+
+	// Without a text query there is nothing for FTS to match, so go straight to the plain filter
+	if opts.Query == "" {
+		return m.searchFiltered(opts, false)
+	}
+
+	if m.isFTSAvailable() {
+		messages, err := m.searchFiltered(opts, true)
+		if err == nil {
+			return messages, nil
+		}
+		logging.Warnf("FTS filtered search failed, falling back to LIKE: %v", err)
+	}
+
+	return m.searchFiltered(opts, false)
+}
+
+// searchFiltered builds and runs the filtered search query, using the FTS
+// table for the text match when useFTS is set and LIKE otherwise
+func (m *Manager) searchFiltered(opts SearchOptions, useFTS bool) ([]*Message, error) {
+	var (
+		from       = "messages m"
+		conditions = []string{"m.is_deleted = 0"}
+		args       []interface{}
+	)
+
+	if opts.Query != "" {
+		if useFTS {
+			from += " INNER JOIN messages_fts fts ON m.id = fts.rowid"
+			conditions = append(conditions, "messages_fts MATCH ?")
+			args = append(args, ftsPhrase(opts.Query))
+		} else {
+			conditions = append(conditions, `m.content LIKE ? ESCAPE '\'`)
+			args = append(args, likePattern(opts.Query))
+		}
+	}
+
+	if opts.FriendID != nil {
+		conditions = append(conditions, "m.friend_id = ?")
+		args = append(args, *opts.FriendID)
+	}
+	if !opts.Since.IsZero() {
+		conditions = append(conditions, "m.timestamp >= ?")
+		args = append(args, opts.Since)
+	}
+	if !opts.Until.IsZero() {
+		conditions = append(conditions, "m.timestamp <= ?")
+		args = append(args, opts.Until)
+	}
+
+	switch opts.Direction {
+	case SearchDirectionIncoming:
+		conditions = append(conditions, "m.is_outgoing = 0")
+	case SearchDirectionOutgoing:
+		conditions = append(conditions, "m.is_outgoing = 1")
+	}
+
+	if len(opts.MessageTypes) > 0 {
+		placeholders := make([]string, len(opts.MessageTypes))
+		for i, messageType := range opts.MessageTypes {
+			placeholders[i] = "?"
+			args = append(args, messageType)
+		}
+		conditions = append(conditions, "m.message_type IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	searchQuery := `
+		SELECT m.id, m.uuid, m.friend_id, m.content, m.message_type, m.is_outgoing,
+		       m.timestamp, m.delivered_at, m.read_at, m.edited_at, m.original_content,
+		       m.file_path, m.file_size, m.file_type, m.is_deleted, m.reply_to_id, m.reply_to_uuid,
+		       m.failed_at
+		FROM ` + from + `
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY m.timestamp DESC
+		LIMIT ?
+	`
+	args = append(args, opts.Limit)
+
+	rows, err := m.db.Query(searchQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("filtered search failed: %w", err)
+	}
+	defer rows.Close()
+
+	return m.scanMessageRows(rows)
+}
+
 // isFTSAvailable checks if the FTS virtual table exists and is usable
 func (m *Manager) isFTSAvailable() bool {
 	var count int
@@ -311,7 +819,8 @@ func (m *Manager) searchWithFTS(query string, limit int) ([]*Message, error) {
 	searchQuery := `
 		SELECT m.id, m.uuid, m.friend_id, m.content, m.message_type, m.is_outgoing,
 		       m.timestamp, m.delivered_at, m.read_at, m.edited_at, m.original_content,
-		       m.file_path, m.file_size, m.file_type, m.is_deleted, m.reply_to_id
+		       m.file_path, m.file_size, m.file_type, m.is_deleted, m.reply_to_id, m.reply_to_uuid,
+		       m.failed_at
 		FROM messages m
 		INNER JOIN messages_fts fts ON m.id = fts.rowid
 		WHERE messages_fts MATCH ? AND m.is_deleted = 0
@@ -319,10 +828,7 @@ func (m *Manager) searchWithFTS(query string, limit int) ([]*Message, error) {
 		LIMIT ?
 	`
 
-	// Escape query for FTS5 MATCH syntax - wrap in double quotes for phrase search
-	ftsQuery := fmt.Sprintf(`"%s"`, query)
-
-	rows, err := m.db.Query(searchQuery, ftsQuery, limit)
+	rows, err := m.db.Query(searchQuery, ftsPhrase(query), limit)
 	if err != nil {
 		return nil, fmt.Errorf("FTS search failed: %w", err)
 	}
@@ -331,19 +837,33 @@ func (m *Manager) searchWithFTS(query string, limit int) ([]*Message, error) {
 	return m.scanMessageRows(rows)
 }
 
+// ftsPhrase quotes user input as a single FTS5 phrase, so operators and
+// special characters such as * or " are matched literally
+func ftsPhrase(query string) string {
+	return `"` + strings.ReplaceAll(query, `"`, `""`) + `"`
+}
+
+// likePattern builds a LIKE pattern matching query anywhere in the text, with
+// the LIKE wildcards % and _ escaped by backslashes
+func likePattern(query string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + escaper.Replace(query) + "%"
+}
+
 // searchWithLike performs search using LIKE operator (fallback)
 func (m *Manager) searchWithLike(query string, limit int) ([]*Message, error) {
 	searchQuery := `
 		SELECT id, uuid, friend_id, content, message_type, is_outgoing,
 		       timestamp, delivered_at, read_at, edited_at, original_content,
-		       file_path, file_size, file_type, is_deleted, reply_to_id
+		       file_path, file_size, file_type, is_deleted, reply_to_id, reply_to_uuid,
+		       failed_at
 		FROM messages 
-		WHERE content LIKE ? AND is_deleted = 0
+		WHERE content LIKE ? ESCAPE '\' AND is_deleted = 0
 		ORDER BY timestamp DESC
 		LIMIT ?
 	`
 
-	rows, err := m.db.Query(searchQuery, "%"+query+"%", limit)
+	rows, err := m.db.Query(searchQuery, likePattern(query), limit)
 	if err != nil {
 		return nil, fmt.Errorf("LIKE search failed: %w", err)
 	}
@@ -356,59 +876,74 @@ func (m *Manager) searchWithLike(query string, limit int) ([]*Message, error) {
 func (m *Manager) scanMessageRows(rows *sql.Rows) ([]*Message, error) {
 	var messages []*Message
 	for rows.Next() {
-		msg := &Message{}
-		var deliveredAt, readAt, editedAt sql.NullTime
-		var originalContent, filePath, fileType sql.NullString
-		var fileSize sql.NullInt64
-		var replyToID sql.NullInt64
-
-		err := rows.Scan(
-			&msg.ID, &msg.UUID, &msg.FriendID, &msg.Content, &msg.MessageType,
-			&msg.IsOutgoing, &msg.Timestamp, &deliveredAt, &readAt, &editedAt,
-			&originalContent, &filePath, &fileSize, &fileType, &msg.IsDeleted,
-			&replyToID,
-		)
+		msg, err := scanMessage(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan message: %w", err)
-		}
-
-		// Set nullable fields
-		if deliveredAt.Valid {
-			msg.DeliveredAt = &deliveredAt.Time
-		}
-		if readAt.Valid {
-			msg.ReadAt = &readAt.Time
-		}
-		if editedAt.Valid {
-			msg.EditedAt = &editedAt.Time
-		}
-		if originalContent.Valid {
-			msg.OriginalContent = originalContent.String
-		}
-		if filePath.Valid {
-			msg.FilePath = filePath.String
+			return nil, err
 		}
-		if fileType.Valid {
-			msg.FileType = fileType.String
-		}
-		if fileSize.Valid {
-			msg.FileSize = fileSize.Int64
-		}
-		if replyToID.Valid {
-			msg.ReplyToID = &replyToID.Int64
-		}
-
 		messages = append(messages, msg)
 	}
 
 	return messages, rows.Err()
 }
 
-// ProcessPending processes pending messages
+// scanMessage scans the current row into a Message
+func scanMessage(rows *sql.Rows) (*Message, error) {
+	msg := &Message{}
+	var deliveredAt, readAt, editedAt sql.NullTime
+	var originalContent, filePath, fileType sql.NullString
+	var fileSize sql.NullInt64
+	var replyToID sql.NullInt64
+	var replyToUUID sql.NullString
+	var failedAt sql.NullTime
+
+	err := rows.Scan(
+		&msg.ID, &msg.UUID, &msg.FriendID, &msg.Content, &msg.MessageType,
+		&msg.IsOutgoing, &msg.Timestamp, &deliveredAt, &readAt, &editedAt,
+		&originalContent, &filePath, &fileSize, &fileType, &msg.IsDeleted,
+		&replyToID, &replyToUUID, &failedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan message: %w", err)
+	}
+
+	// Set nullable fields
+	if deliveredAt.Valid {
+		msg.DeliveredAt = &deliveredAt.Time
+	}
+	if readAt.Valid {
+		msg.ReadAt = &readAt.Time
+	}
+	if editedAt.Valid {
+		msg.EditedAt = &editedAt.Time
+	}
+	if originalContent.Valid {
+		msg.OriginalContent = originalContent.String
+	}
+	if filePath.Valid {
+		msg.FilePath = filePath.String
+	}
+	if fileType.Valid {
+		msg.FileType = fileType.String
+	}
+	if fileSize.Valid {
+		msg.FileSize = fileSize.Int64
+	}
+	if replyToID.Valid {
+		msg.ReplyToID = &replyToID.Int64
+	}
+	if replyToUUID.Valid {
+		msg.ReplyToUUID = replyToUUID.String
+	}
+	if failedAt.Valid {
+		msg.FailedAt = &failedAt.Time
+	}
+
+	return msg, nil
+}
+
+// ProcessPending processes pending messages and retries failed sends
 func (m *Manager) ProcessPending() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	// In a real implementation, this would handle message delivery confirmations
 	// For now, we'll just clear old pending messages
 	for uuid, msg := range m.pendingMessages {
@@ -416,6 +951,9 @@ func (m *Manager) ProcessPending() {
 			delete(m.pendingMessages, uuid)
 		}
 	}
+	m.mu.Unlock()
+
+	m.retryFailedSends()
 }
 
 // saveMessage saves a message to the database
@@ -423,19 +961,23 @@ func (m *Manager) saveMessage(msg *Message) error {
 	query := `
 		INSERT INTO messages (uuid, friend_id, content, message_type, is_outgoing,
 		                     timestamp, delivered_at, read_at, edited_at, original_content,
-		                     file_path, file_size, file_type, is_deleted, reply_to_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		                     file_path, file_size, file_type, is_deleted, reply_to_id, reply_to_uuid,
+		                     failed_at, expires_at, is_forwarded)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := m.db.Exec(query,
 		msg.UUID, msg.FriendID, msg.Content, msg.MessageType, msg.IsOutgoing,
 		msg.Timestamp, msg.DeliveredAt, msg.ReadAt, msg.EditedAt, msg.OriginalContent,
-		msg.FilePath, msg.FileSize, msg.FileType, msg.IsDeleted, msg.ReplyToID,
+		msg.FilePath, msg.FileSize, msg.FileType, msg.IsDeleted, msg.ReplyToID, msg.ReplyToUUID,
+		msg.FailedAt, msg.ExpiresAt, msg.IsForwarded,
 	)
 	if err != nil {
 		return err
 	}
 
+	m.cache.invalidate(msg.FriendID)
+
 	id, err := result.LastInsertId()
 	if err != nil {
 		return err
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/opd-ai/toxcore"
 	"github.com/opd-ai/whisp/internal/storage"
@@ -16,18 +18,24 @@ type MockToxManager struct {
 	lastMessage     string
 	lastFriendID    uint32
 	lastMessageType toxcore.MessageType
+	onSend          func(message string) // Sees every message sent, if set
 }
 
 func (m *MockToxManager) SendMessage(friendID uint32, message string, messageType toxcore.MessageType) error {
 	m.lastFriendID = friendID
 	m.lastMessage = message
 	m.lastMessageType = messageType
+	if m.onSend != nil {
+		m.onSend(message)
+	}
 	return m.sendError
 }
 
 // MockContactManager implements ContactManager for testing
 type MockContactManager struct {
-	contacts map[uint32]interface{}
+	contacts    map[uint32]interface{}
+	blocked     map[uint32]bool
+	fromRequest map[uint32]bool // Accepted from a friend request and not verified
 }
 
 func (m *MockContactManager) GetContact(friendID uint32) (interface{}, bool) {
@@ -35,9 +43,20 @@ func (m *MockContactManager) GetContact(friendID uint32) (interface{}, bool) {
 	return contact, exists
 }
 
+func (m *MockContactManager) IsBlocked(friendID uint32) bool {
+	return m.blocked[friendID]
+}
+
+func (m *MockContactManager) IsEstablished(friendID uint32) bool {
+	_, exists := m.contacts[friendID]
+	return exists && !m.fromRequest[friendID]
+}
+
 func NewMockContactManager() *MockContactManager {
 	return &MockContactManager{
-		contacts: make(map[uint32]interface{}),
+		contacts:    make(map[uint32]interface{}),
+		blocked:     make(map[uint32]bool),
+		fromRequest: make(map[uint32]bool),
 	}
 }
 
@@ -46,7 +65,7 @@ func (m *MockContactManager) AddContact(friendID uint32, contact interface{}) {
 }
 
 // Test setup helper
-func setupTestManager(t *testing.T) (*Manager, *storage.Database, *MockToxManager, *MockContactManager, func()) {
+func setupTestManager(t testing.TB) (*Manager, *storage.Database, *MockToxManager, *MockContactManager, func()) {
 	// Create temporary database
 	tempDir := t.TempDir()
 	dbPath := filepath.Join(tempDir, "test.db")
@@ -170,8 +189,9 @@ func TestSendMessage(t *testing.T) {
 			if toxMgr.lastFriendID != tt.friendID {
 				t.Errorf("Expected Tox friend ID %d, got %d", tt.friendID, toxMgr.lastFriendID)
 			}
-			if toxMgr.lastMessage != tt.content {
-				t.Errorf("Expected Tox message %q, got %q", tt.content, toxMgr.lastMessage)
+			// The first message to a friend of unknown client carries the hello marker
+			if sent := strings.TrimSuffix(toxMgr.lastMessage, helloMarker); sent != tt.content {
+				t.Errorf("Expected Tox message %q, got %q", tt.content, sent)
 			}
 
 			expectedToxType := toxcore.MessageTypeNormal
@@ -219,9 +239,43 @@ func TestHandleIncomingMessage(t *testing.T) {
 		t.Error("Expected non-zero message ID")
 	}
 
-	// Verify read status (should be automatically marked as read)
-	if msg.ReadAt == nil {
-		t.Error("Expected read timestamp")
+	// Received messages stay unread until the conversation is opened
+	if msg.ReadAt != nil {
+		t.Error("Expected no read timestamp")
+	}
+}
+
+func TestHandleIncomingMessageBlocked(t *testing.T) {
+	tests := []struct {
+		name    string
+		blocked bool
+		stored  int
+	}{
+		{"blocked friend is ignored", true, 0},
+		{"unblocked friend is stored", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, _, _, contactMgr, cleanup := setupTestManager(t)
+			defer cleanup()
+
+			friendID := uint32(1)
+			contactMgr.blocked[friendID] = tt.blocked
+
+			msg := mgr.HandleIncomingMessage(friendID, "Hello", MessageTypeNormal)
+			if (msg == nil) != tt.blocked {
+				t.Errorf("Expected nil message only when blocked, got %v", msg)
+			}
+
+			messages, err := mgr.GetMessages(friendID, 10, 0)
+			if err != nil {
+				t.Fatalf("GetMessages failed: %v", err)
+			}
+			if len(messages) != tt.stored {
+				t.Errorf("Expected %d stored messages, got %d", tt.stored, len(messages))
+			}
+		})
 	}
 }
 
@@ -394,6 +448,71 @@ func TestMarkAsRead(t *testing.T) {
 	}
 }
 
+func TestIncomingMessageStaysUnread(t *testing.T) {
+	mgr, db, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	msg := mgr.HandleIncomingMessage(1, "are you there?", MessageTypeNormal)
+	if msg == nil {
+		t.Fatal("Failed to create test message")
+	}
+	if msg.ReadAt != nil {
+		t.Error("Expected a received message to be unread")
+	}
+	if err := db.WaitAsync(time.Second); err != nil {
+		t.Fatalf("WaitAsync failed: %v", err)
+	}
+
+	if count, err := mgr.GetUnreadCount(1); err != nil || count != 1 {
+		t.Errorf("Expected 1 unread message, got %d (%v)", count, err)
+	}
+	if counts, err := mgr.GetUnreadCounts(); err != nil || counts[1] != 1 {
+		t.Errorf("Expected 1 unread message from friend 1, got %v (%v)", counts, err)
+	}
+}
+
+func TestUnreadCount(t *testing.T) {
+	mgr, db, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	for _, friendID := range []uint32{1, 1, 2} {
+		if msg := mgr.HandleIncomingMessage(friendID, "hello", MessageTypeNormal); msg == nil {
+			t.Fatal("Failed to create test message")
+		}
+	}
+	if _, err := mgr.SendMessage(1, "outgoing", MessageTypeNormal); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if err := db.WaitAsync(time.Second); err != nil {
+		t.Fatalf("WaitAsync failed: %v", err)
+	}
+
+	if count, err := mgr.UnreadCount(); err != nil || count != 3 {
+		t.Fatalf("Expected 3 unread messages, got %d (%v)", count, err)
+	}
+
+	counts, err := mgr.GetUnreadCounts()
+	if err != nil || len(counts) != 2 || counts[1] != 2 || counts[2] != 1 {
+		t.Fatalf("Expected 2 unread from friend 1 and 1 from friend 2, got %v (%v)", counts, err)
+	}
+
+	if err := mgr.MarkAsRead(1); err != nil {
+		t.Fatalf("MarkAsRead failed: %v", err)
+	}
+	if count, err := mgr.UnreadCount(); err != nil || count != 1 {
+		t.Errorf("Expected 1 unread message after reading friend 1, got %d (%v)", count, err)
+	}
+	if count, err := mgr.GetUnreadCount(1); err != nil || count != 0 {
+		t.Errorf("Expected no unread messages from friend 1, got %d (%v)", count, err)
+	}
+	if count, err := mgr.GetUnreadCount(2); err != nil || count != 1 {
+		t.Errorf("Expected 1 unread message from friend 2, got %d (%v)", count, err)
+	}
+	if counts, err := mgr.GetUnreadCounts(); err != nil || len(counts) != 1 {
+		t.Errorf("Expected only friend 2 to have unread messages, got %v (%v)", counts, err)
+	}
+}
+
 func TestSearchMessages(t *testing.T) {
 	mgr, _, _, _, cleanup := setupTestManager(t)
 	defer cleanup()
@@ -506,6 +625,141 @@ func TestMessagePersistence(t *testing.T) {
 	}
 }
 
+func TestClearConversation(t *testing.T) {
+	for _, hardDelete := range []bool{false, true} {
+		t.Run(fmt.Sprintf("hardDelete=%v", hardDelete), func(t *testing.T) {
+			mgr, db, _, _, cleanup := setupTestManager(t)
+			defer cleanup()
+
+			now := time.Now()
+			if _, err := db.Exec(`INSERT INTO contacts (public_key, friend_id, name, created_at, updated_at, last_seen_at)
+				VALUES (?, ?, ?, ?, ?, ?)`, []byte("key-1"), 1, "Test Friend", now, now, now); err != nil {
+				t.Fatalf("Failed to insert contact: %v", err)
+			}
+
+			for i := 0; i < 3; i++ {
+				if _, err := mgr.SendMessage(1, fmt.Sprintf("searchable %d", i), MessageTypeNormal); err != nil {
+					t.Fatalf("SendMessage failed: %v", err)
+				}
+			}
+			if _, err := mgr.SendMessage(2, "searchable elsewhere", MessageTypeNormal); err != nil {
+				t.Fatalf("SendMessage failed: %v", err)
+			}
+
+			mediaPath := filepath.Join(t.TempDir(), "photo.png")
+			if err := os.WriteFile(mediaPath, []byte("png"), 0o644); err != nil {
+				t.Fatalf("Failed to create media file: %v", err)
+			}
+			mediaMsg := &Message{
+				UUID: "media-1", FriendID: 1, Content: "photo", MessageType: MessageTypeImage,
+				Timestamp: now, FilePath: mediaPath,
+			}
+			if err := mgr.saveMessage(mediaMsg); err != nil {
+				t.Fatalf("Failed to save media message: %v", err)
+			}
+
+			cleared, err := mgr.ClearConversation(1, hardDelete)
+			if err != nil {
+				t.Fatalf("ClearConversation failed: %v", err)
+			}
+			if cleared != 4 {
+				t.Errorf("Expected 4 cleared messages, got %d", cleared)
+			}
+
+			messages, err := mgr.GetMessages(1, 10, 0)
+			if err != nil {
+				t.Fatalf("GetMessages failed: %v", err)
+			}
+			if len(messages) != 0 {
+				t.Errorf("Expected empty conversation, got %d messages", len(messages))
+			}
+
+			results, err := mgr.SearchMessages("searchable", 10)
+			if err != nil {
+				t.Fatalf("SearchMessages failed: %v", err)
+			}
+			if len(results) != 1 || results[0].FriendID != 2 {
+				t.Errorf("Expected only the other conversation in search results, got %d", len(results))
+			}
+
+			var contactCount int
+			if err := db.QueryRow("SELECT COUNT(*) FROM contacts WHERE friend_id = 1").Scan(&contactCount); err != nil {
+				t.Fatalf("Failed to count contacts: %v", err)
+			}
+			if contactCount != 1 {
+				t.Error("Expected contact to remain after clearing conversation")
+			}
+
+			var rowCount int
+			if err := db.QueryRow("SELECT COUNT(*) FROM messages WHERE friend_id = 1").Scan(&rowCount); err != nil {
+				t.Fatalf("Failed to count message rows: %v", err)
+			}
+			_, statErr := os.Stat(mediaPath)
+			if hardDelete {
+				if rowCount != 0 {
+					t.Errorf("Expected message rows to be deleted, got %d", rowCount)
+				}
+				if !os.IsNotExist(statErr) {
+					t.Error("Expected media file to be removed on hard delete")
+				}
+			} else {
+				if rowCount != 4 {
+					t.Errorf("Expected soft-deleted rows to remain, got %d", rowCount)
+				}
+				if statErr != nil {
+					t.Error("Expected media file to be kept on soft delete")
+				}
+			}
+
+			if others, err := mgr.GetMessages(2, 10, 0); err != nil || len(others) != 1 {
+				t.Errorf("Expected the other conversation to keep its message, got %d (%v)", len(others), err)
+			}
+
+			// Clearing again finds nothing
+			if cleared, err := mgr.ClearConversation(1, hardDelete); err != nil || cleared != 0 {
+				t.Errorf("Expected second clear to remove nothing, got %d (%v)", cleared, err)
+			}
+		})
+	}
+}
+
+func TestMarkAllAsRead(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	now := time.Now()
+	for i, friendID := range []uint32{1, 1, 2, 3} {
+		msg := &Message{
+			UUID: fmt.Sprintf("unread-%d", i), FriendID: friendID, Content: "hello",
+			MessageType: MessageTypeNormal, Timestamp: now,
+		}
+		if err := mgr.saveMessage(msg); err != nil {
+			t.Fatalf("Failed to save message: %v", err)
+		}
+	}
+	if _, err := mgr.SendMessage(2, "outgoing", MessageTypeNormal); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	marked, err := mgr.MarkAllAsRead()
+	if err != nil {
+		t.Fatalf("MarkAllAsRead failed: %v", err)
+	}
+	if marked != 4 {
+		t.Errorf("Expected 4 messages marked read, got %d", marked)
+	}
+	if count, err := mgr.UnreadCount(); err != nil || count != 0 {
+		t.Errorf("Expected no unread messages, got %d (%v)", count, err)
+	}
+	if counts, err := mgr.GetUnreadCounts(); err != nil || len(counts) != 0 {
+		t.Errorf("Expected no unread conversations, got %v (%v)", counts, err)
+	}
+
+	if marked, err := mgr.MarkAllAsRead(); err != nil || marked != 0 {
+		t.Errorf("Expected nothing left to mark, got %d (%v)", marked, err)
+	}
+}
+
 // Helper function to check if string contains substring (case-insensitive)
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr ||
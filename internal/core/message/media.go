@@ -0,0 +1,46 @@
+package message
+
+import (
+	"fmt"
+	"time"
+)
+
+// GetSharedMedia returns a page of the files, images and videos exchanged
+// with a friend, newest first
+func (m *Manager) GetSharedMedia(friendID uint32, limit, offset int) ([]*Message, error) {
+	query := `
+		SELECT id, uuid, friend_id, content, message_type, is_outgoing,
+		       timestamp, delivered_at, read_at, edited_at, original_content,
+		       file_path, file_size, file_type, is_deleted, reply_to_id, reply_to_uuid,
+		       failed_at
+		FROM messages
+		WHERE friend_id = ? AND is_deleted = 0 AND (expires_at IS NULL OR expires_at > ?)
+		  AND message_type IN (?, ?, ?) AND file_path IS NOT NULL AND file_path != ''
+		ORDER BY timestamp DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := m.db.Query(query, friendID, time.Now().UTC(),
+		MessageTypeFile, MessageTypeImage, MessageTypeVideo, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shared media: %w", err)
+	}
+	defer rows.Close()
+
+	return m.scanMessageRows(rows)
+}
+
+// CountSharedMedia returns how many files, images and videos were exchanged
+// with a friend
+func (m *Manager) CountSharedMedia(friendID uint32) (int, error) {
+	var count int
+	err := m.db.QueryRow(`
+		SELECT COUNT(*) FROM messages
+		WHERE friend_id = ? AND is_deleted = 0 AND (expires_at IS NULL OR expires_at > ?)
+		  AND message_type IN (?, ?, ?) AND file_path IS NOT NULL AND file_path != ''`,
+		friendID, time.Now().UTC(), MessageTypeFile, MessageTypeImage, MessageTypeVideo).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count shared media: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,66 @@
+package message
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestGetSharedMedia(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	now := time.Now()
+	media := []struct {
+		friendID    uint32
+		messageType MessageType
+		filePath    string
+	}{
+		{1, MessageTypeImage, "/tmp/a.png"},
+		{1, MessageTypeVideo, "/tmp/b.mp4"},
+		{1, MessageTypeFile, "/tmp/c.pdf"},
+		{1, MessageTypeImage, ""},           // Never arrived
+		{1, MessageTypeVoice, "/tmp/d.ogg"}, // Voice notes stay in the chat
+		{2, MessageTypeImage, "/tmp/e.png"}, // Another conversation
+		{1, MessageTypeImage, "/tmp/f.png"}, // Deleted below
+	}
+	for i, m := range media {
+		msg := &Message{
+			UUID: fmt.Sprintf("media-%d", i), FriendID: m.friendID, Content: "file",
+			MessageType: m.messageType, Timestamp: now.Add(time.Duration(i) * time.Second), FilePath: m.filePath,
+		}
+		if err := mgr.saveMessage(msg); err != nil {
+			t.Fatalf("Failed to save message: %v", err)
+		}
+		if i == len(media)-1 {
+			if err := mgr.DeleteMessage(msg.ID); err != nil {
+				t.Fatalf("DeleteMessage failed: %v", err)
+			}
+		}
+	}
+	mgr.HandleIncomingMessage(1, "text", MessageTypeNormal)
+
+	count, err := mgr.CountSharedMedia(1)
+	if err != nil {
+		t.Fatalf("CountSharedMedia failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 shared files, got %d", count)
+	}
+
+	page, err := mgr.GetSharedMedia(1, 2, 0)
+	if err != nil {
+		t.Fatalf("GetSharedMedia failed: %v", err)
+	}
+	if len(page) != 2 || page[0].FilePath != "/tmp/c.pdf" || page[1].FilePath != "/tmp/b.mp4" {
+		t.Fatalf("Expected the newest two files, got %v", page)
+	}
+
+	page, err = mgr.GetSharedMedia(1, 2, 2)
+	if err != nil {
+		t.Fatalf("GetSharedMedia failed: %v", err)
+	}
+	if len(page) != 1 || page[0].FilePath != "/tmp/a.png" {
+		t.Errorf("Expected the oldest file on the second page, got %v", page)
+	}
+}
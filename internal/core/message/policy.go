@@ -0,0 +1,200 @@
+package message
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// SenderPolicy controls how messages from senders that are not established
+// contacts are handled
+type SenderPolicy int
+
+const (
+	// SenderPolicyAccept stores messages from unknown senders like any other message
+	SenderPolicyAccept SenderPolicy = iota
+	// SenderPolicyHold keeps messages from unknown senders in a review queue
+	SenderPolicyHold
+	// SenderPolicyReject drops messages from unknown senders
+	SenderPolicyReject
+)
+
+// String returns the configuration name of the policy
+func (p SenderPolicy) String() string {
+	switch p {
+	case SenderPolicyHold:
+		return "hold"
+	case SenderPolicyReject:
+		return "reject"
+	default:
+		return "accept"
+	}
+}
+
+// ParseSenderPolicy converts a configuration value into a SenderPolicy.
+// An empty value is treated as "accept" for configs written before the setting existed.
+func ParseSenderPolicy(value string) (SenderPolicy, error) {
+	switch value {
+	case "", "accept":
+		return SenderPolicyAccept, nil
+	case "hold":
+		return SenderPolicyHold, nil
+	case "reject":
+		return SenderPolicyReject, nil
+	default:
+		return SenderPolicyAccept, fmt.Errorf("unknown sender policy: %s", value)
+	}
+}
+
+// SetSenderPolicy sets how messages from unknown senders are handled
+func (m *Manager) SetSenderPolicy(policy SenderPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.senderPolicy = policy
+}
+
+// GetSenderPolicy returns the current unknown-sender policy
+func (m *Manager) GetSenderPolicy() SenderPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.senderPolicy
+}
+
+// GetHeldMessages returns the messages waiting for review, oldest first
+func (m *Manager) GetHeldMessages() []*Message {
+	rows, err := m.db.Query(`
+		SELECT uuid, friend_id, content, message_type, timestamp, reply_to_uuid, expires_at, is_forwarded
+		FROM held_messages ORDER BY timestamp, rowid
+	`)
+	if err != nil {
+		logging.Warnf("Failed to load held messages: %v", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var held []*Message
+	for rows.Next() {
+		msg, err := scanHeldMessage(rows)
+		if err != nil {
+			logging.Warnf("Failed to scan held message: %v", err)
+			return held
+		}
+		held = append(held, msg)
+	}
+	if err := rows.Err(); err != nil {
+		logging.Warnf("Failed to load held messages: %v", err)
+	}
+	return held
+}
+
+// ReleaseHeldMessage accepts a held message and stores it in the conversation
+func (m *Manager) ReleaseHeldMessage(messageUUID string) (*Message, error) {
+	msg, err := m.takeHeldMessage(messageUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The referenced message may have arrived while this one was held
+	if msg.ReplyToUUID != "" {
+		msg.ReplyToID = m.lookupMessageID(msg.FriendID, msg.ReplyToUUID)
+	}
+
+	if err := m.saveMessage(msg); err != nil {
+		return nil, fmt.Errorf("failed to save released message: %w", err)
+	}
+
+	return msg, nil
+}
+
+// DiscardHeldMessage removes a held message without storing it
+func (m *Manager) DiscardHeldMessage(messageUUID string) error {
+	_, err := m.takeHeldMessage(messageUUID)
+	return err
+}
+
+// takeHeldMessage removes a message from the review queue and returns it
+func (m *Manager) takeHeldMessage(messageUUID string) (*Message, error) {
+	row := m.db.QueryRow(`
+		SELECT uuid, friend_id, content, message_type, timestamp, reply_to_uuid, expires_at, is_forwarded
+		FROM held_messages WHERE uuid = ?
+	`, messageUUID)
+	msg, err := scanHeldMessage(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("held message %s not found", messageUUID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load held message: %w", err)
+	}
+
+	if _, err := m.db.Exec("DELETE FROM held_messages WHERE uuid = ?", messageUUID); err != nil {
+		return nil, fmt.Errorf("failed to remove held message: %w", err)
+	}
+	return msg, nil
+}
+
+// holdMessage stores a message in the review queue
+func (m *Manager) holdMessage(msg *Message) error {
+	_, err := m.db.Exec(`
+		INSERT OR IGNORE INTO held_messages (uuid, friend_id, content, message_type, timestamp, reply_to_uuid, expires_at, is_forwarded)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, msg.UUID, msg.FriendID, msg.Content, msg.MessageType, msg.Timestamp, msg.ReplyToUUID, msg.ExpiresAt, msg.IsForwarded)
+	if err != nil {
+		return fmt.Errorf("failed to hold message: %w", err)
+	}
+	return nil
+}
+
+// scanHeldMessage reads a held message from a query row
+func scanHeldMessage(row interface{ Scan(...interface{}) error }) (*Message, error) {
+	msg := &Message{}
+	var replyTo sql.NullString
+	var expiresAt sql.NullTime
+	if err := row.Scan(&msg.UUID, &msg.FriendID, &msg.Content, &msg.MessageType, &msg.Timestamp,
+		&replyTo, &expiresAt, &msg.IsForwarded); err != nil {
+		return nil, err
+	}
+	msg.ReplyToUUID = replyTo.String
+	if expiresAt.Valid {
+		msg.ExpiresAt = &expiresAt.Time
+	}
+	return msg, nil
+}
+
+// admitSender applies the sender policy to an incoming message.
+// It returns true if the message should be stored normally.
+func (m *Manager) admitSender(msg *Message) bool {
+	// Established friends always pass, as do friends we have written to.
+	// Friends accepted from a request are reviewed until verified.
+	if m.contacts != nil && m.contacts.IsEstablished(msg.FriendID) {
+		return true
+	}
+	if m.hasWrittenTo(msg.FriendID) {
+		return true
+	}
+
+	switch m.GetSenderPolicy() {
+	case SenderPolicyHold:
+		if err := m.holdMessage(msg); err != nil {
+			logging.Errorf("%v", err)
+			return false
+		}
+		logging.Infof("Held message from unknown sender %d for review", msg.FriendID)
+		return false
+	case SenderPolicyReject:
+		logging.Infof("Rejected message from unknown sender %d", msg.FriendID)
+		return false
+	default:
+		return true
+	}
+}
+
+// hasWrittenTo reports whether we have ever sent a message to a friend
+func (m *Manager) hasWrittenTo(friendID uint32) bool {
+	var written int
+	err := m.db.QueryRow("SELECT 1 FROM messages WHERE friend_id = ? AND is_outgoing = 1 LIMIT 1", friendID).Scan(&written)
+	if err != nil && err != sql.ErrNoRows {
+		logging.Warnf("Failed to check messages sent to friend %d: %v", friendID, err)
+	}
+	return err == nil
+}
@@ -0,0 +1,186 @@
+package message
+
+import "testing"
+
+func TestParseSenderPolicy(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    SenderPolicy
+		expectError bool
+	}{
+		{"", SenderPolicyAccept, false},
+		{"accept", SenderPolicyAccept, false},
+		{"hold", SenderPolicyHold, false},
+		{"reject", SenderPolicyReject, false},
+		{"ignore", SenderPolicyAccept, true},
+	}
+
+	for _, tt := range tests {
+		policy, err := ParseSenderPolicy(tt.value)
+		if tt.expectError != (err != nil) {
+			t.Errorf("ParseSenderPolicy(%q): expected error %v, got %v", tt.value, tt.expectError, err)
+		}
+		if policy != tt.expected {
+			t.Errorf("ParseSenderPolicy(%q): expected %v, got %v", tt.value, tt.expected, policy)
+		}
+	}
+}
+
+func TestSenderPolicyUnknownSender(t *testing.T) {
+	const strangerID uint32 = 99
+
+	tests := []struct {
+		name          string
+		policy        SenderPolicy
+		expectStored  bool
+		expectHeldLen int
+	}{
+		{"accept", SenderPolicyAccept, true, 0},
+		{"hold", SenderPolicyHold, false, 1},
+		{"reject", SenderPolicyReject, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, _, _, _, cleanup := setupTestManager(t)
+			defer cleanup()
+
+			mgr.SetSenderPolicy(tt.policy)
+
+			msg := mgr.HandleIncomingMessage(strangerID, "hi, we haven't met", MessageTypeNormal)
+			if tt.expectStored != (msg != nil) {
+				t.Errorf("Expected stored=%v, got message %v", tt.expectStored, msg)
+			}
+
+			stored, err := mgr.GetMessages(strangerID, 10, 0)
+			if err != nil {
+				t.Fatalf("GetMessages failed: %v", err)
+			}
+			if tt.expectStored && len(stored) != 1 {
+				t.Errorf("Expected 1 stored message, got %d", len(stored))
+			}
+			if !tt.expectStored && len(stored) != 0 {
+				t.Errorf("Expected no stored messages, got %d", len(stored))
+			}
+
+			if held := mgr.GetHeldMessages(); len(held) != tt.expectHeldLen {
+				t.Errorf("Expected %d held messages, got %d", tt.expectHeldLen, len(held))
+			}
+		})
+	}
+}
+
+func TestSenderPolicyFriendsAlwaysPass(t *testing.T) {
+	for _, policy := range []SenderPolicy{SenderPolicyAccept, SenderPolicyHold, SenderPolicyReject} {
+		t.Run(policy.String(), func(t *testing.T) {
+			mgr, _, _, _, cleanup := setupTestManager(t)
+			defer cleanup()
+
+			mgr.SetSenderPolicy(policy)
+
+			// Friend 1 is registered by setupTestManager
+			if msg := mgr.HandleIncomingMessage(1, "hello from a friend", MessageTypeNormal); msg == nil {
+				t.Fatal("Expected message from friend to be stored")
+			}
+
+			if held := mgr.GetHeldMessages(); len(held) != 0 {
+				t.Errorf("Expected no held messages, got %d", len(held))
+			}
+		})
+	}
+}
+
+func TestReleaseAndDiscardHeldMessages(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	mgr.SetSenderPolicy(SenderPolicyHold)
+	mgr.HandleIncomingMessage(42, "first", MessageTypeNormal)
+	mgr.HandleIncomingMessage(42, "second", MessageTypeNormal)
+
+	held := mgr.GetHeldMessages()
+	if len(held) != 2 {
+		t.Fatalf("Expected 2 held messages, got %d", len(held))
+	}
+
+	released, err := mgr.ReleaseHeldMessage(held[0].UUID)
+	if err != nil {
+		t.Fatalf("ReleaseHeldMessage failed: %v", err)
+	}
+	if released.ID == 0 {
+		t.Error("Expected released message to be saved with an ID")
+	}
+
+	if err := mgr.DiscardHeldMessage(held[1].UUID); err != nil {
+		t.Fatalf("DiscardHeldMessage failed: %v", err)
+	}
+
+	if remaining := mgr.GetHeldMessages(); len(remaining) != 0 {
+		t.Errorf("Expected empty review queue, got %d", len(remaining))
+	}
+
+	stored, err := mgr.GetMessages(42, 10, 0)
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Content != "first" {
+		t.Errorf("Expected only the released message to be stored, got %v", stored)
+	}
+
+	if _, err := mgr.ReleaseHeldMessage("missing"); err == nil {
+		t.Error("Expected error releasing unknown held message")
+	}
+}
+
+func TestSenderPolicyUnverifiedFriend(t *testing.T) {
+	mgr, _, _, contactMgr, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	mgr.SetSenderPolicy(SenderPolicyHold)
+	contactMgr.AddContact(7, map[string]string{"name": "New Friend"})
+	contactMgr.fromRequest[7] = true
+
+	// A friend accepted from a request is reviewed like a stranger
+	if msg := mgr.HandleIncomingMessage(7, "hi", MessageTypeNormal); msg != nil {
+		t.Fatal("Expected message from unverified friend to be held")
+	}
+	if held := mgr.GetHeldMessages(); len(held) != 1 {
+		t.Fatalf("Expected 1 held message, got %d", len(held))
+	}
+
+	// Writing to them shows we know them
+	if _, err := mgr.SendMessage(7, "hello", MessageTypeNormal); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if msg := mgr.HandleIncomingMessage(7, "thanks", MessageTypeNormal); msg == nil {
+		t.Error("Expected message from friend we wrote to to be stored")
+	}
+
+	// Friends we added or verified are established
+	contactMgr.AddContact(8, map[string]string{"name": "Verified Friend"})
+	if msg := mgr.HandleIncomingMessage(8, "verified", MessageTypeNormal); msg == nil {
+		t.Error("Expected message from established friend to be stored")
+	}
+}
+
+func TestHeldMessagesPersist(t *testing.T) {
+	mgr, db, _, contactMgr, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	mgr.SetSenderPolicy(SenderPolicyHold)
+	mgr.HandleIncomingMessage(42, "while you were away", MessageTypeNormal)
+
+	// The review queue survives a restart
+	restarted := NewManager(db, &MockToxManager{}, contactMgr)
+	held := restarted.GetHeldMessages()
+	if len(held) != 1 || held[0].Content != "while you were away" || held[0].FriendID != 42 {
+		t.Fatalf("Expected held message after restart, got %v", held)
+	}
+
+	if _, err := restarted.ReleaseHeldMessage(held[0].UUID); err != nil {
+		t.Fatalf("ReleaseHeldMessage failed: %v", err)
+	}
+	if remaining := mgr.GetHeldMessages(); len(remaining) != 0 {
+		t.Errorf("Expected released message to leave the queue, got %d", len(remaining))
+	}
+}
@@ -0,0 +1,151 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// controlReaction marks a wire message carrying a reaction change
+const controlReaction = "react"
+
+// Reaction is an emoji reaction to a message, by us or by the friend
+type Reaction struct {
+	MessageUUID string    `json:"message_uuid"`
+	FriendID    uint32    `json:"friend_id"`
+	Emoji       string    `json:"emoji"`
+	IsOutgoing  bool      `json:"is_outgoing"` // We reacted
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// reactionPayload is the body of a reaction control message
+type reactionPayload struct {
+	MessageID string `json:"m"`
+	Emoji     string `json:"e"`
+	Remove    bool   `json:"rm,omitempty"`
+}
+
+// AddReaction reacts to a message in a conversation with an emoji. Friends
+// whose clients announced reaction support are told about it; for others the
+// reaction is only kept locally.
+func (m *Manager) AddReaction(friendID uint32, messageUUID, emoji string) error {
+	return m.changeReaction(friendID, reactionPayload{MessageID: messageUUID, Emoji: emoji})
+}
+
+// RemoveReaction withdraws our reaction to a message
+func (m *Manager) RemoveReaction(friendID uint32, messageUUID, emoji string) error {
+	return m.changeReaction(friendID, reactionPayload{MessageID: messageUUID, Emoji: emoji, Remove: true})
+}
+
+// ToggleReaction adds our reaction to a message, or removes it if we already
+// reacted with that emoji, and reports whether the reaction is now present
+func (m *Manager) ToggleReaction(friendID uint32, messageUUID, emoji string) (bool, error) {
+	var count int
+	err := m.db.QueryRow(`
+		SELECT COUNT(*) FROM message_reactions
+		WHERE message_uuid = ? AND is_outgoing = 1 AND emoji = ?
+	`, messageUUID, emoji).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check reaction: %w", err)
+	}
+
+	if count > 0 {
+		return false, m.RemoveReaction(friendID, messageUUID, emoji)
+	}
+	return true, m.AddReaction(friendID, messageUUID, emoji)
+}
+
+// GetReactions returns the reactions to a message, oldest first
+func (m *Manager) GetReactions(messageUUID string) ([]Reaction, error) {
+	rows, err := m.db.Query(`
+		SELECT message_uuid, friend_id, emoji, is_outgoing, timestamp
+		FROM message_reactions
+		WHERE message_uuid = ?
+		ORDER BY timestamp ASC
+	`, messageUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reactions: %w", err)
+	}
+	defer rows.Close()
+
+	var reactions []Reaction
+	for rows.Next() {
+		var r Reaction
+		if err := rows.Scan(&r.MessageUUID, &r.FriendID, &r.Emoji, &r.IsOutgoing, &r.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction: %w", err)
+		}
+		reactions = append(reactions, r)
+	}
+	return reactions, rows.Err()
+}
+
+// changeReaction validates, sends and stores one of our reaction changes
+func (m *Manager) changeReaction(friendID uint32, payload reactionPayload) error {
+	payload.Emoji = strings.TrimSpace(payload.Emoji)
+	if payload.Emoji == "" {
+		return fmt.Errorf("reaction cannot be empty")
+	}
+	if m.lookupMessageID(friendID, payload.MessageID) == nil {
+		return fmt.Errorf("message %s not found", payload.MessageID)
+	}
+
+	if m.peerSupports(friendID, FeatureReactions) {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode reaction: %w", err)
+		}
+		wireContent := encodeWire(wireHeader{Control: controlReaction}, string(data))
+		if err := m.toxMgr.SendMessage(friendID, wireContent, toxcore.MessageTypeNormal); err != nil {
+			return fmt.Errorf("failed to send reaction: %w", err)
+		}
+	}
+
+	return m.storeReaction(friendID, payload, true)
+}
+
+// handleReaction applies a reaction change received from a friend. Reactions
+// to messages we do not have are ignored.
+func (m *Manager) handleReaction(friendID uint32, body string) {
+	var payload reactionPayload
+	if err := json.Unmarshal([]byte(body), &payload); err != nil || strings.TrimSpace(payload.Emoji) == "" {
+		logging.Warnf("Ignoring malformed reaction from friend %d", friendID)
+		return
+	}
+	if m.lookupMessageID(friendID, payload.MessageID) == nil {
+		logging.Infof("Ignoring reaction from friend %d to unknown message %s", friendID, payload.MessageID)
+		return
+	}
+
+	payload.Emoji = strings.TrimSpace(payload.Emoji)
+	if err := m.storeReaction(friendID, payload, false); err != nil {
+		logging.Errorf("Failed to save reaction from friend %d: %v", friendID, err)
+	}
+}
+
+// storeReaction records or deletes a reaction
+func (m *Manager) storeReaction(friendID uint32, payload reactionPayload, outgoing bool) error {
+	if payload.Remove {
+		_, err := m.db.Exec(`
+			DELETE FROM message_reactions
+			WHERE message_uuid = ? AND is_outgoing = ? AND emoji = ?
+		`, payload.MessageID, outgoing, payload.Emoji)
+		if err != nil {
+			return fmt.Errorf("failed to remove reaction: %w", err)
+		}
+		return nil
+	}
+
+	_, err := m.db.Exec(`
+		INSERT OR REPLACE INTO message_reactions (message_uuid, friend_id, emoji, is_outgoing, timestamp)
+		VALUES (?, ?, ?, ?, ?)
+	`, payload.MessageID, friendID, payload.Emoji, outgoing, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save reaction: %w", err)
+	}
+	return nil
+}
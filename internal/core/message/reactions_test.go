@@ -0,0 +1,108 @@
+package message
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestReactionsRoundTrip(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+	completeHandshake(t, mgr, 1)
+
+	msg, err := mgr.SendMessage(1, "lunch?", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	// Our reaction is sent to a peer that supports reactions
+	added, err := mgr.ToggleReaction(1, msg.UUID, "👍")
+	if err != nil || !added {
+		t.Fatalf("Expected reaction to be added, got %v (%v)", added, err)
+	}
+	header, body := decodeWire(toxMgr.lastMessage)
+	var payload reactionPayload
+	if header.Control != controlReaction || json.Unmarshal([]byte(body), &payload) != nil ||
+		payload.MessageID != msg.UUID || payload.Emoji != "👍" || payload.Remove {
+		t.Fatalf("Expected a reaction control message, got %q", toxMgr.lastMessage)
+	}
+
+	// The friend reacts too; the control message is not stored as a message
+	data, _ := json.Marshal(reactionPayload{MessageID: msg.UUID, Emoji: "😂"})
+	if stored := mgr.HandleIncomingMessage(1, encodeWire(wireHeader{Control: controlReaction}, string(data)), MessageTypeNormal); stored != nil {
+		t.Fatal("Expected reaction not to be stored as a message")
+	}
+
+	reactions, err := mgr.GetReactions(msg.UUID)
+	if err != nil || len(reactions) != 2 {
+		t.Fatalf("Expected 2 reactions, got %+v (%v)", reactions, err)
+	}
+	if !reactions[0].IsOutgoing || reactions[0].Emoji != "👍" || reactions[1].IsOutgoing || reactions[1].Emoji != "😂" {
+		t.Errorf("Unexpected reactions %+v", reactions)
+	}
+
+	// Toggling again withdraws ours and tells the peer
+	added, err = mgr.ToggleReaction(1, msg.UUID, "👍")
+	if err != nil || added {
+		t.Fatalf("Expected reaction to be removed, got %v (%v)", added, err)
+	}
+	if _, body := decodeWire(toxMgr.lastMessage); json.Unmarshal([]byte(body), &payload) != nil || !payload.Remove {
+		t.Errorf("Expected a removal to be sent, got %q", toxMgr.lastMessage)
+	}
+
+	// The friend withdraws theirs
+	data, _ = json.Marshal(reactionPayload{MessageID: msg.UUID, Emoji: "😂", Remove: true})
+	mgr.HandleIncomingMessage(1, encodeWire(wireHeader{Control: controlReaction}, string(data)), MessageTypeNormal)
+	if reactions, err := mgr.GetReactions(msg.UUID); err != nil || len(reactions) != 0 {
+		t.Errorf("Expected no reactions left, got %+v (%v)", reactions, err)
+	}
+}
+
+func TestReactionsEdgeCases(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	msg := mgr.HandleIncomingMessage(1, "hello", MessageTypeNormal)
+	if msg == nil {
+		t.Fatal("Failed to store message")
+	}
+
+	// A peer that has not announced reaction support is not sent anything
+	toxMgr.lastMessage = ""
+	if err := mgr.AddReaction(1, msg.UUID, "❤️"); err != nil {
+		t.Fatalf("AddReaction failed: %v", err)
+	}
+	if toxMgr.lastMessage != "" {
+		t.Errorf("Expected no reaction sent to a plain Tox client, got %q", toxMgr.lastMessage)
+	}
+	if reactions, _ := mgr.GetReactions(msg.UUID); len(reactions) != 1 {
+		t.Errorf("Expected the reaction to be kept locally, got %+v", reactions)
+	}
+
+	if err := mgr.AddReaction(1, msg.UUID, "  "); err == nil {
+		t.Error("Expected error for an empty reaction")
+	}
+	if err := mgr.AddReaction(1, "no-such-message", "❤️"); err == nil {
+		t.Error("Expected error for an unknown message")
+	}
+	if err := mgr.AddReaction(2, msg.UUID, "❤️"); err == nil {
+		t.Error("Expected error for a message in another conversation")
+	}
+
+	// Reactions to messages we do not have are ignored
+	data, _ := json.Marshal(reactionPayload{MessageID: "not-yet-received", Emoji: "👍"})
+	if stored := mgr.HandleIncomingMessage(1, encodeWire(wireHeader{Control: controlReaction}, string(data)), MessageTypeNormal); stored != nil {
+		t.Error("Expected reaction not to be stored as a message")
+	}
+	if reactions, _ := mgr.GetReactions("not-yet-received"); len(reactions) != 0 {
+		t.Errorf("Expected reaction to an unknown message to be ignored, got %+v", reactions)
+	}
+
+	// Hard-clearing the conversation removes its reactions
+	if _, err := mgr.ClearConversation(1, true); err != nil {
+		t.Fatalf("ClearConversation failed: %v", err)
+	}
+	if reactions, _ := mgr.GetReactions(msg.UUID); len(reactions) != 0 {
+		t.Errorf("Expected reactions to be cleared with the conversation, got %+v", reactions)
+	}
+}
@@ -0,0 +1,155 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// controlReadReceipt marks a wire message listing the UUIDs of messages the
+// friend has read
+const controlReadReceipt = "read"
+
+// readReceiptDelay is how long receipts for a friend are collected before
+// they are sent together
+const readReceiptDelay = 2 * time.Second
+
+// maxReceiptsPerMessage keeps a batch of receipts within one Tox message
+const maxReceiptsPerMessage = 25
+
+// SetReadReceipts sets whether friends are told when we read their messages
+// and whether their receipts are shown. Friends only send receipts to clients
+// that show them, and learn of a change the next time they connect.
+func (m *Manager) SetReadReceipts(send, show bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sendReceipts = send
+	m.showReceipts = show
+	if !send {
+		for friendID, timer := range m.receiptTimers {
+			timer.Stop()
+			delete(m.receiptTimers, friendID)
+		}
+		m.pendingReceipts = make(map[uint32][]string)
+	}
+}
+
+// unreadUUIDs returns the UUIDs of a friend's unread messages if we would
+// tell them we read those messages
+func (m *Manager) unreadUUIDs(friendID uint32) ([]string, error) {
+	m.mu.RLock()
+	send := m.sendReceipts
+	m.mu.RUnlock()
+	if !send || !m.peerSupports(friendID, FeatureReadReceipts) {
+		return nil, nil
+	}
+
+	rows, err := m.db.Query(`
+		SELECT uuid FROM messages
+		WHERE friend_id = ? AND is_outgoing = 0 AND read_at IS NULL AND uuid IS NOT NULL AND uuid != ''
+	`, friendID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unread messages: %w", err)
+	}
+	defer rows.Close()
+
+	var uuids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan unread message: %w", err)
+		}
+		uuids = append(uuids, id)
+	}
+	return uuids, rows.Err()
+}
+
+// queueReadReceipts schedules receipts for messages of a friend we read
+func (m *Manager) queueReadReceipts(friendID uint32, uuids []string) {
+	if len(uuids) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.sendReceipts {
+		return
+	}
+	m.pendingReceipts[friendID] = append(m.pendingReceipts[friendID], uuids...)
+	if _, scheduled := m.receiptTimers[friendID]; !scheduled {
+		m.receiptTimers[friendID] = time.AfterFunc(readReceiptDelay, func() {
+			m.flushReadReceipts(friendID)
+		})
+	}
+}
+
+// flushReadReceipts sends the receipts collected for a friend
+func (m *Manager) flushReadReceipts(friendID uint32) {
+	m.mu.Lock()
+	uuids := m.pendingReceipts[friendID]
+	delete(m.pendingReceipts, friendID)
+	if timer, exists := m.receiptTimers[friendID]; exists {
+		timer.Stop()
+		delete(m.receiptTimers, friendID)
+	}
+	send := m.sendReceipts
+	m.mu.Unlock()
+
+	if !send || len(uuids) == 0 || !m.peerSupports(friendID, FeatureReadReceipts) {
+		return
+	}
+
+	for start := 0; start < len(uuids); start += maxReceiptsPerMessage {
+		end := start + maxReceiptsPerMessage
+		if end > len(uuids) {
+			end = len(uuids)
+		}
+		data, err := json.Marshal(uuids[start:end])
+		if err != nil {
+			logging.Warnf("Failed to encode read receipts: %v", err)
+			return
+		}
+		wireContent := encodeWire(wireHeader{Control: controlReadReceipt}, string(data))
+		if err := m.toxMgr.SendMessage(friendID, wireContent, toxcore.MessageTypeNormal); err != nil {
+			logging.Warnf("Failed to send read receipts to friend %d: %v", friendID, err)
+			return
+		}
+	}
+}
+
+// handleReadReceipt records that a friend read some of our messages unless
+// showing receipts is disabled
+func (m *Manager) handleReadReceipt(friendID uint32, body string) {
+	m.mu.RLock()
+	show := m.showReceipts
+	m.mu.RUnlock()
+	if !show {
+		return
+	}
+
+	var uuids []string
+	if err := json.Unmarshal([]byte(body), &uuids); err != nil {
+		logging.Warnf("Ignoring malformed read receipt from friend %d: %v", friendID, err)
+		return
+	}
+
+	now := time.Now()
+	for _, id := range uuids {
+		result, err := m.db.Exec(`
+			UPDATE messages SET read_at = ?
+			WHERE friend_id = ? AND uuid = ? AND is_outgoing = 1 AND read_at IS NULL
+		`, now, friendID, id)
+		if err != nil {
+			logging.Errorf("Failed to record read receipt from friend %d: %v", friendID, err)
+			continue
+		}
+		if changed, err := result.RowsAffected(); err == nil && changed > 0 {
+			m.cache.invalidate(friendID)
+			m.notifyStatus(friendID, id)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package message
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReadReceiptsNeedBothSides(t *testing.T) {
+	tests := []struct {
+		name        string
+		readerSends bool
+		senderShows bool
+		wantRead    bool
+	}{
+		{"both opted in", true, true, true},
+		{"reader does not send", false, true, false},
+		{"sender does not show", true, false, false},
+		{"neither opted in", false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Friend 1 of the sender is the reader and the other way around
+			sender, senderDB, senderTox, _, cleanupSender := setupTestManager(t)
+			defer cleanupSender()
+			reader, readerDB, readerTox, _, cleanupReader := setupTestManager(t)
+			defer cleanupReader()
+
+			sender.SetReadReceipts(true, tt.senderShows)
+			reader.SetReadReceipts(tt.readerSends, true)
+
+			if err := sender.AnnounceCapabilities(1); err != nil {
+				t.Fatalf("AnnounceCapabilities failed: %v", err)
+			}
+			reader.HandleIncomingMessage(1, senderTox.lastMessage, MessageTypeNormal)
+			sender.HandleIncomingMessage(1, readerTox.lastMessage, MessageTypeNormal)
+
+			sent, err := sender.SendMessage(1, "did you see this?", MessageTypeNormal)
+			if err != nil {
+				t.Fatalf("SendMessage failed: %v", err)
+			}
+			received := reader.HandleIncomingMessage(1, senderTox.lastMessage, MessageTypeNormal)
+			if received == nil || received.UUID != sent.UUID {
+				t.Fatalf("Expected the reader to store the message with its UUID, got %+v", received)
+			}
+			if err := readerDB.WaitAsync(time.Second); err != nil {
+				t.Fatalf("WaitAsync failed: %v", err)
+			}
+
+			before := readerTox.lastMessage
+			if err := reader.MarkAsRead(1); err != nil {
+				t.Fatalf("MarkAsRead failed: %v", err)
+			}
+			reader.flushReadReceipts(1)
+			if readerTox.lastMessage != before {
+				sender.HandleIncomingMessage(1, readerTox.lastMessage, MessageTypeNormal)
+			}
+			if err := senderDB.WaitAsync(time.Second); err != nil {
+				t.Fatalf("WaitAsync failed: %v", err)
+			}
+
+			stored, err := sender.GetMessages(1, 10, 0)
+			if err != nil || len(stored) != 1 {
+				t.Fatalf("Expected the sent message, got %v: %v", stored, err)
+			}
+			if gotRead := stored[0].ReadAt != nil; gotRead != tt.wantRead {
+				t.Errorf("Expected read=%v, got ReadAt %v", tt.wantRead, stored[0].ReadAt)
+			}
+		})
+	}
+}
+
+func TestReadReceiptsAreBatched(t *testing.T) {
+	mgr, db, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	mgr.SetReadReceipts(true, true)
+	caps, _ := EncodeCapabilities(Capabilities{Version: CapabilitiesVersion, Features: FeatureReadReceipts})
+	mgr.handleCapabilities(1, caps)
+
+	count := maxReceiptsPerMessage + 5
+	for i := 0; i < count; i++ {
+		if _, err := db.Exec(`INSERT INTO messages (uuid, friend_id, content, is_outgoing, timestamp) VALUES (?, 1, 'hi', 0, ?)`,
+			fmt.Sprintf("uuid-%02d", i), time.Now()); err != nil {
+			t.Fatalf("Failed to insert message: %v", err)
+		}
+	}
+
+	var sent []string
+	toxMgr.onSend = func(message string) { sent = append(sent, message) }
+	if err := mgr.MarkAsRead(1); err != nil {
+		t.Fatalf("MarkAsRead failed: %v", err)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("Expected receipts to wait for the batch delay, got %d messages", len(sent))
+	}
+
+	mgr.flushReadReceipts(1)
+	if len(sent) != 2 {
+		t.Fatalf("Expected %d receipts in 2 messages, got %d", count, len(sent))
+	}
+	for _, message := range sent {
+		if header, _ := decodeWire(message); header.Control != controlReadReceipt {
+			t.Errorf("Expected a read receipt, got %q", message)
+		}
+	}
+
+	// Nothing is left to send
+	mgr.flushReadReceipts(1)
+	if len(sent) != 2 {
+		t.Errorf("Expected no more receipts, got %d messages", len(sent))
+	}
+}
@@ -0,0 +1,87 @@
+package message
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SendReply sends a message as a reply to an existing message in the same conversation
+func (m *Manager) SendReply(friendID uint32, content string, messageType MessageType, replyToID int64) (*Message, error) {
+	var replyToUUID string
+	err := m.db.QueryRow(
+		"SELECT uuid FROM messages WHERE id = ? AND friend_id = ? AND is_deleted = 0",
+		replyToID, friendID,
+	).Scan(&replyToUUID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("message %d not found in conversation", replyToID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replied-to message: %w", err)
+	}
+
+	msg := &Message{
+		UUID:        uuid.New().String(),
+		FriendID:    friendID,
+		Content:     content,
+		MessageType: messageType,
+		IsOutgoing:  true,
+		Timestamp:   time.Now(),
+		ReplyToID:   &replyToID,
+		ReplyToUUID: replyToUUID,
+	}
+
+	if err := m.deliver(msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// GetMessage returns a stored message by ID, including deleted ones, so that
+// callers such as reply references can tell a deleted message from a missing one
+func (m *Manager) GetMessage(messageID int64) (*Message, error) {
+	rows, err := m.db.Query(`
+		SELECT id, uuid, friend_id, content, message_type, is_outgoing,
+		       timestamp, delivered_at, read_at, edited_at, original_content,
+		       file_path, file_size, file_type, is_deleted, reply_to_id, reply_to_uuid,
+		       failed_at
+		FROM messages
+		WHERE id = ?
+	`, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query message: %w", err)
+	}
+	defer rows.Close()
+
+	messages, err := m.scanMessageRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("message %d not found", messageID)
+	}
+	return messages[0], nil
+}
+
+// IsReplyUnavailable reports whether the message is a reply to a message
+// that is not in local storage, e.g. one sent before history was cleared
+func (msg *Message) IsReplyUnavailable() bool {
+	return msg.ReplyToUUID != "" && msg.ReplyToID == nil
+}
+
+// lookupMessageID returns the local ID of a message in a conversation by UUID,
+// or nil if it is not stored
+func (m *Manager) lookupMessageID(friendID uint32, messageUUID string) *int64 {
+	var id int64
+	err := m.db.QueryRow(
+		"SELECT id FROM messages WHERE uuid = ? AND friend_id = ?",
+		messageUUID, friendID,
+	).Scan(&id)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,153 @@
+package message
+
+import "testing"
+
+func TestWireEncoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		header  wireHeader
+		body    string
+	}{
+		{"plain text", "hello", wireHeader{}, "hello"},
+		{"header", encodeWire(wireHeader{ID: "a", ReplyTo: "b"}, "hi"), wireHeader{ID: "a", ReplyTo: "b"}, "hi"},
+		{"separator in body", encodeWire(wireHeader{ID: "a"}, "x\x1fy"), wireHeader{ID: "a"}, "x\x1fy"},
+		{"unterminated header", wirePrefix + `{"id":"a"}`, wireHeader{}, wirePrefix + `{"id":"a"}`},
+		{"malformed header", wirePrefix + "{oops" + wireSeparator + "hi", wireHeader{}, wirePrefix + "{oops" + wireSeparator + "hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, body := decodeWire(tt.content)
+			if header != tt.header {
+				t.Errorf("Expected header %+v, got %+v", tt.header, header)
+			}
+			if body != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, body)
+			}
+		})
+	}
+}
+
+func TestSendReply(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+	completeHandshake(t, mgr, 1)
+
+	original, err := mgr.SendMessage(1, "original", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	reply, err := mgr.SendReply(1, "replying", MessageTypeNormal, original.ID)
+	if err != nil {
+		t.Fatalf("SendReply failed: %v", err)
+	}
+
+	if reply.ReplyToID == nil || *reply.ReplyToID != original.ID {
+		t.Errorf("Expected reply to reference message %d, got %v", original.ID, reply.ReplyToID)
+	}
+
+	header, body := decodeWire(toxMgr.lastMessage)
+	if header.ReplyTo != original.UUID {
+		t.Errorf("Expected reply reference %q to be sent, got %q", original.UUID, header.ReplyTo)
+	}
+	if body != "replying" {
+		t.Errorf("Expected body %q, got %q", "replying", body)
+	}
+
+	if _, err := mgr.SendReply(1, "orphan", MessageTypeNormal, 9999); err == nil {
+		t.Error("Expected error replying to unknown message")
+	}
+}
+
+func TestHandleIncomingReply(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	// The peer shares our UUID for a message we sent them
+	original, err := mgr.SendMessage(1, "question?", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	t.Run("links to stored message", func(t *testing.T) {
+		wire := encodeWire(wireHeader{ID: "peer-reply-1", ReplyTo: original.UUID}, "answer")
+		msg := mgr.HandleIncomingMessage(1, wire, MessageTypeNormal)
+		if msg == nil {
+			t.Fatal("Expected incoming reply to be stored")
+		}
+
+		if msg.UUID != "peer-reply-1" {
+			t.Errorf("Expected sender's UUID to be kept, got %q", msg.UUID)
+		}
+		if msg.Content != "answer" {
+			t.Errorf("Expected content %q, got %q", "answer", msg.Content)
+		}
+		if msg.ReplyToID == nil || *msg.ReplyToID != original.ID {
+			t.Errorf("Expected reply linked to message %d, got %v", original.ID, msg.ReplyToID)
+		}
+		if msg.IsReplyUnavailable() {
+			t.Error("Expected referenced message to be available")
+		}
+	})
+
+	t.Run("degrades when reference is missing", func(t *testing.T) {
+		wire := encodeWire(wireHeader{ID: "peer-reply-2", ReplyTo: "never-seen"}, "about that")
+		msg := mgr.HandleIncomingMessage(1, wire, MessageTypeNormal)
+		if msg == nil {
+			t.Fatal("Expected reply with missing reference to still be stored")
+		}
+
+		if msg.ReplyToID != nil {
+			t.Errorf("Expected no local link, got %v", *msg.ReplyToID)
+		}
+		if !msg.IsReplyUnavailable() {
+			t.Error("Expected referenced message to be reported unavailable")
+		}
+
+		stored, err := mgr.GetMessages(1, 10, 0)
+		if err != nil {
+			t.Fatalf("GetMessages failed: %v", err)
+		}
+		for _, s := range stored {
+			if s.UUID == "peer-reply-2" && s.ReplyToUUID != "never-seen" {
+				t.Errorf("Expected reply reference to persist, got %q", s.ReplyToUUID)
+			}
+		}
+	})
+
+	t.Run("ignores duplicates", func(t *testing.T) {
+		wire := encodeWire(wireHeader{ID: "peer-reply-1"}, "answer")
+		if msg := mgr.HandleIncomingMessage(1, wire, MessageTypeNormal); msg != nil {
+			t.Error("Expected duplicate delivery to be ignored")
+		}
+	})
+}
+
+func TestGetMessage(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	original := mgr.HandleIncomingMessage(1, "original", MessageTypeNormal)
+	if original == nil {
+		t.Fatal("Failed to store message")
+	}
+
+	got, err := mgr.GetMessage(original.ID)
+	if err != nil || got.UUID != original.UUID || got.Content != "original" || got.IsDeleted {
+		t.Fatalf("Expected the stored message, got %+v (%v)", got, err)
+	}
+
+	// Deleted messages are still returned so callers can say so
+	if err := mgr.DeleteMessage(original.ID); err != nil {
+		t.Fatalf("DeleteMessage failed: %v", err)
+	}
+	if got, err := mgr.GetMessage(original.ID); err != nil || !got.IsDeleted {
+		t.Errorf("Expected the deleted message, got %+v (%v)", got, err)
+	}
+
+	if _, err := mgr.GetMessage(9999); err == nil {
+		t.Error("Expected error for an unknown message")
+	}
+}
@@ -0,0 +1,244 @@
+package message
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// DefaultPruneInterval is how often messages past their retention are deleted
+const DefaultPruneInterval = time.Hour
+
+// RetentionDefault is the retention of a conversation that follows the
+// global policy
+const RetentionDefault time.Duration = -1
+
+// SetRetention sets how long messages are kept in conversations without a
+// retention of their own; zero keeps them forever. Pruned messages are
+// hidden like deleted ones, or removed for good with their files if
+// hardDelete is set. Unlike disappearing messages, retention is never
+// shared with friends.
+func (m *Manager) SetRetention(keep time.Duration, hardDelete bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retention = keep
+	m.retentionHardDelete = hardDelete
+}
+
+// SetFriendRetention sets how long messages with a friend are kept: zero
+// keeps them forever and RetentionDefault follows the global policy
+func (m *Manager) SetFriendRetention(friendID uint32, keep time.Duration) error {
+	if keep < 0 && keep != RetentionDefault {
+		return fmt.Errorf("retention cannot be negative")
+	}
+
+	var err error
+	if keep == RetentionDefault {
+		_, err = m.db.Exec("DELETE FROM retention_policies WHERE friend_id = ?", friendID)
+	} else {
+		_, err = m.db.Exec(`INSERT INTO retention_policies (friend_id, seconds, updated_at) VALUES (?, ?, ?)
+			ON CONFLICT(friend_id) DO UPDATE SET seconds = excluded.seconds, updated_at = excluded.updated_at`,
+			friendID, int64(keep/time.Second), time.Now())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save retention: %w", err)
+	}
+	return nil
+}
+
+// GetFriendRetention returns how long messages with a friend are kept, or
+// RetentionDefault if the conversation follows the global policy
+func (m *Manager) GetFriendRetention(friendID uint32) time.Duration {
+	var seconds int64
+	err := m.db.QueryRow("SELECT seconds FROM retention_policies WHERE friend_id = ?", friendID).Scan(&seconds)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logging.Warnf("Failed to load retention: %v", err)
+		}
+		return RetentionDefault
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// StartRetentionPruner deletes messages past their retention now and then
+// every interval until the context is done
+func (m *Manager) StartRetentionPruner(ctx context.Context, interval time.Duration) {
+	m.pruneAndNotify(time.Now())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				m.pruneAndNotify(now)
+			}
+		}
+	}()
+}
+
+// pruneAndNotify prunes old messages and runs the expiry callback
+func (m *Manager) pruneAndNotify(now time.Time) {
+	// The database is closed while the app is locked
+	if m.db.IsLocked() {
+		return
+	}
+
+	friendIDs, err := m.PruneOld(now)
+	if err != nil {
+		logging.Warnf("%v", err)
+		return
+	}
+
+	m.mu.RLock()
+	callback := m.onMessagesExpired
+	m.mu.RUnlock()
+
+	if callback != nil && len(friendIDs) > 0 {
+		callback(friendIDs)
+	}
+}
+
+// PruneOld deletes the messages each conversation's retention no longer
+// keeps at now. Returns the conversations that lost messages.
+func (m *Manager) PruneOld(now time.Time) ([]uint32, error) {
+	m.mu.RLock()
+	global, hardDelete := m.retention, m.retentionHardDelete
+	m.mu.RUnlock()
+
+	overrides, err := m.friendRetentions()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.Query(`SELECT DISTINCT friend_id FROM messages WHERE is_deleted = 0`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+	var friendIDs []uint32
+	for rows.Next() {
+		var friendID uint32
+		if err := rows.Scan(&friendID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		friendIDs = append(friendIDs, friendID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read conversations: %w", err)
+	}
+
+	var pruned []uint32
+	for _, friendID := range friendIDs {
+		keep, ok := overrides[friendID]
+		if !ok {
+			keep = global
+		}
+		if keep <= 0 {
+			continue
+		}
+
+		count, err := m.pruneConversation(friendID, now.Add(-keep), hardDelete)
+		if err != nil {
+			return pruned, err
+		}
+		if count > 0 {
+			m.cache.invalidate(friendID)
+			pruned = append(pruned, friendID)
+		}
+	}
+	return pruned, nil
+}
+
+// friendRetentions returns the retention of every conversation that has
+// its own
+func (m *Manager) friendRetentions() (map[uint32]time.Duration, error) {
+	rows, err := m.db.Query("SELECT friend_id, seconds FROM retention_policies")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query retention: %w", err)
+	}
+	defer rows.Close()
+
+	retentions := make(map[uint32]time.Duration)
+	for rows.Next() {
+		var friendID uint32
+		var seconds int64
+		if err := rows.Scan(&friendID, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan retention: %w", err)
+		}
+		retentions[friendID] = time.Duration(seconds) * time.Second
+	}
+	return retentions, rows.Err()
+}
+
+// pruneConversation deletes a friend's messages sent before cutoff and
+// returns how many went. There is no way to pin a message yet, so none are
+// exempt.
+func (m *Manager) pruneConversation(friendID uint32, cutoff time.Time, hardDelete bool) (int, error) {
+	if !hardDelete {
+		tx, err := m.db.Begin()
+		if err != nil {
+			return 0, fmt.Errorf("failed to start transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		// Keep the search index in step; hidden messages must not show up in results
+		if m.isFTSAvailable() {
+			if _, err := tx.Exec(`DELETE FROM messages_fts WHERE rowid IN (SELECT id FROM messages WHERE friend_id = ? AND timestamp < ?)`, friendID, cutoff); err != nil {
+				return 0, fmt.Errorf("failed to prune search index: %w", err)
+			}
+		}
+		result, err := tx.Exec(`UPDATE messages SET is_deleted = 1 WHERE friend_id = ? AND timestamp < ? AND is_deleted = 0`, friendID, cutoff)
+		if err != nil {
+			return 0, fmt.Errorf("failed to prune messages: %w", err)
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to count pruned messages: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("failed to commit pruned messages: %w", err)
+		}
+		return int(count), nil
+	}
+
+	rows, err := m.db.Query(`SELECT id, uuid, file_path FROM messages WHERE friend_id = ? AND timestamp < ?`, friendID, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query old messages: %w", err)
+	}
+	var ids, uuids []interface{}
+	var filePaths []string
+	for rows.Next() {
+		var id int64
+		var messageUUID string
+		var filePath sql.NullString
+		if err := rows.Scan(&id, &messageUUID, &filePath); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan old message: %w", err)
+		}
+		ids = append(ids, id)
+		uuids = append(uuids, messageUUID)
+		if filePath.Valid && filePath.String != "" {
+			filePaths = append(filePaths, filePath.String)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read old messages: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := m.purgeMessages(ids, uuids, filePaths); err != nil {
+		return 0, fmt.Errorf("failed to prune messages: %w", err)
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,149 @@
+package message
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPruneOld(t *testing.T) {
+	const day = 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		global     time.Duration
+		hardDelete bool
+		overrides  map[uint32]time.Duration
+		wantPruned map[uint32]bool // Friends whose old message goes
+	}{
+		{
+			name:       "keep forever",
+			wantPruned: map[uint32]bool{},
+		},
+		{
+			name:       "global retention",
+			global:     30 * day,
+			wantPruned: map[uint32]bool{1: true, 2: true},
+		},
+		{
+			name:       "friend kept forever",
+			global:     30 * day,
+			overrides:  map[uint32]time.Duration{1: 0},
+			wantPruned: map[uint32]bool{2: true},
+		},
+		{
+			name:       "friend retention without a global one",
+			overrides:  map[uint32]time.Duration{2: 7 * day},
+			wantPruned: map[uint32]bool{2: true},
+		},
+		{
+			name:       "hard delete",
+			global:     30 * day,
+			hardDelete: true,
+			wantPruned: map[uint32]bool{1: true, 2: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, db, _, _, cleanup := setupTestManager(t)
+			defer cleanup()
+
+			now := time.Now()
+			for _, friendID := range []uint32{1, 2} {
+				old := &Message{UUID: fmt.Sprintf("old-%d", friendID), FriendID: friendID, Content: "ancient history", Timestamp: now.Add(-60 * day)}
+				recent := &Message{UUID: fmt.Sprintf("recent-%d", friendID), FriendID: friendID, Content: "fresh news", Timestamp: now.Add(-time.Hour)}
+				for _, msg := range []*Message{old, recent} {
+					if err := mgr.saveMessage(msg); err != nil {
+						t.Fatalf("saveMessage failed: %v", err)
+					}
+				}
+			}
+			for friendID, keep := range tt.overrides {
+				if err := mgr.SetFriendRetention(friendID, keep); err != nil {
+					t.Fatalf("SetFriendRetention failed: %v", err)
+				}
+			}
+			mgr.SetRetention(tt.global, tt.hardDelete)
+
+			// Load once so a stale cached page would show up below
+			if _, err := mgr.GetMessages(1, 10, 0); err != nil {
+				t.Fatalf("GetMessages failed: %v", err)
+			}
+
+			pruned, err := mgr.PruneOld(now)
+			if err != nil {
+				t.Fatalf("PruneOld failed: %v", err)
+			}
+			if len(pruned) != len(tt.wantPruned) {
+				t.Errorf("Expected %d pruned conversations, got %v", len(tt.wantPruned), pruned)
+			}
+
+			for _, friendID := range []uint32{1, 2} {
+				messages, err := mgr.GetMessages(friendID, 10, 0)
+				if err != nil {
+					t.Fatalf("GetMessages failed: %v", err)
+				}
+				want := 2
+				if tt.wantPruned[friendID] {
+					want = 1
+				}
+				if len(messages) != want {
+					t.Errorf("Friend %d: expected %d messages, got %d", friendID, want, len(messages))
+				}
+				for _, msg := range messages {
+					if tt.wantPruned[friendID] && msg.UUID == fmt.Sprintf("old-%d", friendID) {
+						t.Errorf("Friend %d: expected the old message to be pruned", friendID)
+					}
+				}
+			}
+
+			results, err := mgr.SearchMessages("ancient", 10)
+			if err != nil {
+				t.Fatalf("SearchMessages failed: %v", err)
+			}
+			if want := 2 - len(tt.wantPruned); len(results) != want {
+				t.Errorf("Expected %d search results for old messages, got %d", want, len(results))
+			}
+
+			var rows int
+			db.QueryRow(`SELECT COUNT(*) FROM messages`).Scan(&rows)
+			wantRows := 4
+			if tt.hardDelete {
+				wantRows -= len(tt.wantPruned)
+			}
+			if rows != wantRows {
+				t.Errorf("Expected %d stored messages, got %d", wantRows, rows)
+			}
+
+			// Pruning again finds nothing left to do
+			if pruned, err := mgr.PruneOld(now); err != nil || len(pruned) != 0 {
+				t.Errorf("Expected a second prune to do nothing, got %v, %v", pruned, err)
+			}
+		})
+	}
+}
+
+func TestFriendRetention(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	if keep := mgr.GetFriendRetention(1); keep != RetentionDefault {
+		t.Errorf("Expected the default retention, got %v", keep)
+	}
+	if err := mgr.SetFriendRetention(1, 7*24*time.Hour); err != nil {
+		t.Fatalf("SetFriendRetention failed: %v", err)
+	}
+	if keep := mgr.GetFriendRetention(1); keep != 7*24*time.Hour {
+		t.Errorf("Expected a week, got %v", keep)
+	}
+	if err := mgr.SetFriendRetention(1, RetentionDefault); err != nil {
+		t.Fatalf("SetFriendRetention failed: %v", err)
+	}
+	if keep := mgr.GetFriendRetention(1); keep != RetentionDefault {
+		t.Errorf("Expected the default retention again, got %v", keep)
+	}
+	if err := mgr.SetFriendRetention(1, -time.Hour); err == nil {
+		t.Error("Expected a negative retention to be rejected")
+	}
+}
@@ -0,0 +1,295 @@
+package message
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// RetryPolicy bounds how failed sends are retried from the outbox
+type RetryPolicy struct {
+	MaxAttempts int           // Send attempts before giving up, including the first
+	Expiry      time.Duration // Give up on messages older than this
+	Interval    time.Duration // Minimum wait between attempts
+}
+
+// DefaultRetryPolicy is used until SetRetryPolicy is called
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	Expiry:      24 * time.Hour,
+	Interval:    30 * time.Second,
+}
+
+// queuedSend is a stored outgoing message whose send failed
+type queuedSend struct {
+	msg         *Message
+	attempts    int
+	lastAttempt time.Time
+}
+
+// SetRetryPolicy sets the retry budget and expiry for failed sends.
+// Non-positive values fall back to the defaults.
+func (m *Manager) SetRetryPolicy(policy RetryPolicy) {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if policy.Expiry <= 0 {
+		policy.Expiry = DefaultRetryPolicy.Expiry
+	}
+	if policy.Interval < 0 {
+		policy.Interval = DefaultRetryPolicy.Interval
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retryPolicy = policy
+}
+
+// SetOnSendFailed sets the callback invoked when a message is permanently failed
+func (m *Manager) SetOnSendFailed(callback func(*Message)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onSendFailed = callback
+}
+
+// SetOnMessageStatus sets the callback invoked once an outgoing message is
+// recorded as delivered or read
+func (m *Manager) SetOnMessageStatus(callback func(friendID uint32, messageUUID string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onStatus = callback
+}
+
+// notifyStatus reports that the delivery status of a message changed
+func (m *Manager) notifyStatus(friendID uint32, messageUUID string) {
+	m.mu.RLock()
+	callback := m.onStatus
+	m.mu.RUnlock()
+
+	if callback != nil {
+		callback(friendID, messageUUID)
+	}
+}
+
+// GetQueuedMessages returns the outgoing messages waiting to be retried
+func (m *Manager) GetQueuedMessages() []*Message {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	messages := make([]*Message, 0, len(m.outbox))
+	for _, queued := range m.outbox {
+		messages = append(messages, queued.msg)
+	}
+	return messages
+}
+
+// queueRetry adds a message whose first send attempt failed to the outbox
+func (m *Manager) queueRetry(msg *Message) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.outbox[msg.UUID] = &queuedSend{msg: msg, attempts: 1, lastAttempt: time.Now()}
+}
+
+// RestoreOutbox queues the outgoing messages that were not delivered when the
+// app last stopped, so they are retried like any failed send. Messages that
+// failed permanently but are still within the retry expiry get another round.
+func (m *Manager) RestoreOutbox() error {
+	m.mu.RLock()
+	policy := m.retryPolicy
+	m.mu.RUnlock()
+
+	now := time.Now()
+	rows, err := m.db.Query(`
+		SELECT id, uuid, friend_id, content, message_type, timestamp,
+		       reply_to_id, reply_to_uuid, expires_at, is_forwarded, failed_at
+		FROM messages
+		WHERE is_outgoing = 1 AND is_deleted = 0 AND delivered_at IS NULL
+		  AND COALESCE(file_type, '') = ''
+		  AND (failed_at IS NULL OR timestamp > ?)
+		  AND (expires_at IS NULL OR expires_at > ?)
+		ORDER BY timestamp
+	`, now.Add(-policy.Expiry), now)
+	if err != nil {
+		return fmt.Errorf("failed to query unsent messages: %w", err)
+	}
+
+	var unsent []*Message
+	for rows.Next() {
+		msg := &Message{IsOutgoing: true}
+		var replyToID sql.NullInt64
+		var replyToUUID sql.NullString
+		var expiresAt, failedAt sql.NullTime
+		if err := rows.Scan(&msg.ID, &msg.UUID, &msg.FriendID, &msg.Content, &msg.MessageType, &msg.Timestamp,
+			&replyToID, &replyToUUID, &expiresAt, &msg.IsForwarded, &failedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan unsent message: %w", err)
+		}
+		if replyToID.Valid {
+			msg.ReplyToID = &replyToID.Int64
+		}
+		msg.ReplyToUUID = replyToUUID.String
+		if expiresAt.Valid {
+			msg.ExpiresAt = &expiresAt.Time
+		}
+		if failedAt.Valid {
+			msg.FailedAt = &failedAt.Time
+		}
+		unsent = append(unsent, msg)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query unsent messages: %w", err)
+	}
+
+	for _, msg := range unsent {
+		// Failed messages go back to pending while they are retried
+		if msg.FailedAt != nil {
+			if _, err := m.db.Exec("UPDATE messages SET failed_at = NULL WHERE id = ?", msg.ID); err != nil {
+				return fmt.Errorf("failed to requeue failed message: %w", err)
+			}
+			msg.FailedAt = nil
+			m.cache.invalidate(msg.FriendID)
+		}
+
+		// The send before the restart counts as the first attempt; the next is due now
+		m.mu.Lock()
+		if _, queued := m.outbox[msg.UUID]; !queued {
+			m.outbox[msg.UUID] = &queuedSend{msg: msg, attempts: 1}
+		}
+		m.mu.Unlock()
+	}
+
+	if len(unsent) > 0 {
+		logging.Infof("Restored %d unsent messages to the outbox", len(unsent))
+	}
+	return nil
+}
+
+// retryFailedSends resends queued messages that are due and gives up on
+// those that ran out of attempts or expired
+func (m *Manager) retryFailedSends() {
+	now := time.Now()
+
+	m.mu.Lock()
+	policy := m.retryPolicy
+	var due, expired []*queuedSend
+	for _, queued := range m.outbox {
+		switch {
+		case now.Sub(queued.msg.Timestamp) > policy.Expiry || queued.attempts >= policy.MaxAttempts:
+			expired = append(expired, queued)
+		case now.Sub(queued.lastAttempt) >= policy.Interval:
+			// Claim the attempt now so a concurrent pass does not resend it too
+			queued.attempts++
+			queued.lastAttempt = now
+			due = append(due, queued)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, queued := range expired {
+		m.failSend(queued.msg.UUID)
+	}
+
+	for _, queued := range due {
+		if err := m.transmit(queued.msg); err != nil {
+			m.mu.RLock()
+			exhausted := queued.attempts >= policy.MaxAttempts
+			m.mu.RUnlock()
+			if exhausted {
+				m.failSend(queued.msg.UUID)
+			}
+			continue
+		}
+
+		m.mu.Lock()
+		delete(m.outbox, queued.msg.UUID)
+		m.mu.Unlock()
+	}
+}
+
+// failSend removes a message from the outbox and marks it permanently failed
+func (m *Manager) failSend(messageUUID string) {
+	m.mu.Lock()
+	queued, exists := m.outbox[messageUUID]
+	if !exists {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.outbox, messageUUID)
+	now := time.Now()
+	queued.msg.FailedAt = &now
+	callback := m.onSendFailed
+	m.mu.Unlock()
+
+	query := `UPDATE messages SET failed_at = ? WHERE id = ?`
+	if _, err := m.db.Exec(query, now, queued.msg.ID); err != nil {
+		logging.Errorf("Failed to mark message as failed: %v", err)
+	}
+	m.cache.invalidate(queued.msg.FriendID)
+
+	logging.Infof("Giving up on message %s after %d attempts", messageUUID, queued.attempts)
+
+	if callback != nil {
+		callback(queued.msg)
+	}
+}
+
+// ResendAsNew sends the content of a permanently failed message as a fresh
+// message with a new UUID and timestamp, and removes the failed original.
+// If the new send fails too, the new message is queued for retry and returned
+// along with the error.
+func (m *Manager) ResendAsNew(failedMessageID int64) (*Message, error) {
+	query := `
+		SELECT friend_id, content, message_type, is_outgoing, failed_at, reply_to_id, reply_to_uuid
+		FROM messages
+		WHERE id = ? AND is_deleted = 0
+	`
+
+	original := &Message{ID: failedMessageID}
+	var failedAt sql.NullTime
+	var replyToID sql.NullInt64
+	var replyToUUID sql.NullString
+	err := m.db.QueryRow(query, failedMessageID).Scan(
+		&original.FriendID, &original.Content, &original.MessageType, &original.IsOutgoing,
+		&failedAt, &replyToID, &replyToUUID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	if !original.IsOutgoing || !failedAt.Valid {
+		return nil, fmt.Errorf("message %d has not failed", failedMessageID)
+	}
+
+	msg := &Message{
+		UUID:        uuid.New().String(),
+		FriendID:    original.FriendID,
+		Content:     original.Content,
+		MessageType: original.MessageType,
+		IsOutgoing:  true,
+		Timestamp:   time.Now(),
+	}
+	if replyToID.Valid {
+		msg.ReplyToID = &replyToID.Int64
+	}
+	if replyToUUID.Valid {
+		msg.ReplyToUUID = replyToUUID.String
+	}
+
+	sendErr := m.deliver(msg)
+	if msg.ID == 0 {
+		// Nothing was stored, so keep the original for another try
+		return nil, sendErr
+	}
+
+	// The friend never got the failed copy, so there is nothing to delete on their side
+	if err := m.deleteLocal(msg.FriendID, failedMessageID); err != nil {
+		logging.Warnf("Failed to remove resent message %d: %v", failedMessageID, err)
+	}
+
+	return msg, sendErr
+}
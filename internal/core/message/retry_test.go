@@ -0,0 +1,280 @@
+package message
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetryBudgetExhausted(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	mgr.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, Expiry: time.Hour, Interval: 0})
+
+	var failed []*Message
+	mgr.SetOnSendFailed(func(msg *Message) { failed = append(failed, msg) })
+
+	toxMgr.sendError = fmt.Errorf("friend offline")
+
+	// First attempt fails and queues the message
+	if _, err := mgr.SendMessage(1, "first", MessageTypeNormal); err == nil {
+		t.Fatal("Expected send error while offline")
+	}
+	mgr.ProcessPending() // "first": attempt 2
+
+	if _, err := mgr.SendMessage(1, "second", MessageTypeNormal); err == nil {
+		t.Fatal("Expected send error while offline")
+	}
+	mgr.ProcessPending() // "first": attempt 3, budget exhausted; "second": attempt 2
+
+	if len(failed) != 1 || failed[0].Content != "first" {
+		t.Fatalf("Expected only the first message to fail permanently, got %v", failed)
+	}
+	if failed[0].FailedAt == nil {
+		t.Error("Expected FailedAt to be set")
+	}
+
+	queued := mgr.GetQueuedMessages()
+	if len(queued) != 1 || queued[0].Content != "second" {
+		t.Fatalf("Expected only the second message to remain queued, got %v", queued)
+	}
+
+	// The failure is persisted so it survives a reload
+	stored, err := mgr.GetMessages(1, 10, 0)
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	for _, msg := range stored {
+		if isFailed := msg.FailedAt != nil; isFailed != (msg.Content == "first") {
+			t.Errorf("Message %q: expected failed=%v, got FailedAt %v", msg.Content, msg.Content == "first", msg.FailedAt)
+		}
+	}
+
+	// The message within budget keeps retrying and is delivered once the friend is back
+	toxMgr.sendError = nil
+	mgr.ProcessPending()
+
+	if queued := mgr.GetQueuedMessages(); len(queued) != 0 {
+		t.Errorf("Expected empty queue after delivery, got %d", len(queued))
+	}
+	if toxMgr.lastMessage != "second" {
+		t.Errorf("Expected second message to be resent, got %q", toxMgr.lastMessage)
+	}
+	if len(failed) != 1 {
+		t.Errorf("Expected no further failures, got %d", len(failed))
+	}
+}
+
+func TestRetryExpiry(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	mgr.SetRetryPolicy(RetryPolicy{MaxAttempts: 100, Expiry: 10 * time.Millisecond, Interval: 0})
+
+	var failed int
+	mgr.SetOnSendFailed(func(msg *Message) { failed++ })
+
+	toxMgr.sendError = fmt.Errorf("friend offline")
+	if _, err := mgr.SendMessage(1, "stale", MessageTypeNormal); err == nil {
+		t.Fatal("Expected send error while offline")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	mgr.ProcessPending()
+
+	if failed != 1 {
+		t.Errorf("Expected expired message to fail, got %d failures", failed)
+	}
+	if queued := mgr.GetQueuedMessages(); len(queued) != 0 {
+		t.Errorf("Expected expired message to leave the queue, got %d", len(queued))
+	}
+}
+
+func TestRetryInterval(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	mgr.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, Expiry: time.Hour, Interval: time.Hour})
+
+	toxMgr.sendError = fmt.Errorf("friend offline")
+	if _, err := mgr.SendMessage(1, "waiting", MessageTypeNormal); err == nil {
+		t.Fatal("Expected send error while offline")
+	}
+
+	// Not due yet, so repeated passes must not burn the budget
+	for i := 0; i < 5; i++ {
+		mgr.ProcessPending()
+	}
+
+	if queued := mgr.GetQueuedMessages(); len(queued) != 1 {
+		t.Errorf("Expected message to stay queued until its retry is due, got %d", len(queued))
+	}
+}
+
+func TestResendAsNew(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	mgr.SetRetryPolicy(RetryPolicy{MaxAttempts: 1, Expiry: time.Hour, Interval: 0})
+
+	var failed *Message
+	mgr.SetOnSendFailed(func(msg *Message) { failed = msg })
+
+	toxMgr.sendError = fmt.Errorf("friend offline")
+	if _, err := mgr.SendMessage(1, "did you get this?", MessageTypeNormal); err == nil {
+		t.Fatal("Expected send error while offline")
+	}
+	mgr.ProcessPending()
+	if failed == nil {
+		t.Fatal("Expected the message to fail permanently")
+	}
+
+	toxMgr.sendError = nil
+	resent, err := mgr.ResendAsNew(failed.ID)
+	if err != nil {
+		t.Fatalf("ResendAsNew failed: %v", err)
+	}
+
+	if resent.ID == failed.ID || resent.UUID == failed.UUID {
+		t.Errorf("Expected a new row and UUID, got id %d uuid %s", resent.ID, resent.UUID)
+	}
+	if resent.Content != failed.Content || !resent.IsOutgoing || resent.FailedAt != nil {
+		t.Errorf("Unexpected resent message: %+v", resent)
+	}
+	if !resent.Timestamp.After(failed.Timestamp) {
+		t.Error("Expected a fresh timestamp")
+	}
+	if toxMgr.lastMessage != "did you get this?" {
+		t.Errorf("Expected the content to be sent again, got %q", toxMgr.lastMessage)
+	}
+
+	// Only the new message remains visible
+	stored, err := mgr.GetMessages(1, 10, 0)
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(stored) != 1 || stored[0].UUID != resent.UUID {
+		t.Fatalf("Expected only the resent message, got %d messages", len(stored))
+	}
+
+	// The original cannot be resent twice
+	if _, err := mgr.ResendAsNew(failed.ID); err == nil {
+		t.Error("Expected error resending a removed message")
+	}
+}
+
+func TestResendAsNewRequiresFailedMessage(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	sent, err := mgr.SendMessage(1, "delivered fine", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	incoming := mgr.HandleIncomingMessage(1, "hello", MessageTypeNormal)
+
+	for _, id := range []int64{sent.ID, incoming.ID, 9999} {
+		if _, err := mgr.ResendAsNew(id); err == nil {
+			t.Errorf("Expected error resending message %d", id)
+		}
+	}
+
+	stored, _ := mgr.GetMessages(1, 10, 0)
+	if len(stored) != 2 {
+		t.Errorf("Expected messages to be left alone, got %d", len(stored))
+	}
+}
+
+func TestMessageStatusReportedOnDelivery(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	delivered := make(chan string, 1)
+	mgr.SetOnMessageStatus(func(friendID uint32, messageUUID string) {
+		delivered <- messageUUID
+	})
+
+	toxMgr.sendError = fmt.Errorf("friend offline")
+	mgr.SendMessage(1, "later", MessageTypeNormal)
+	msg := mgr.GetQueuedMessages()[0]
+	select {
+	case uuid := <-delivered:
+		t.Fatalf("Expected no status for an unsent message, got %s", uuid)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The retry delivers it
+	toxMgr.sendError = nil
+	mgr.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, Expiry: time.Hour, Interval: 0})
+	mgr.ProcessPending()
+	select {
+	case uuid := <-delivered:
+		if uuid != msg.UUID {
+			t.Errorf("Expected status for %s, got %s", msg.UUID, uuid)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the delivery to be reported")
+	}
+
+	stored, err := mgr.GetMessages(1, 10, 0)
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(stored) != 1 || stored[0].DeliveredAt == nil {
+		t.Errorf("Expected the delivery to be stored before it is reported, got %+v", stored)
+	}
+}
+
+func TestRestoreOutbox(t *testing.T) {
+	mgr, db, toxMgr, contactMgr, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	policy := RetryPolicy{MaxAttempts: 2, Expiry: time.Hour, Interval: 0}
+	mgr.SetRetryPolicy(policy)
+
+	if _, err := mgr.SendMessage(1, "delivered", MessageTypeNormal); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	toxMgr.sendError = fmt.Errorf("friend offline")
+	mgr.SendMessage(1, "gave up", MessageTypeNormal)
+	mgr.ProcessPending() // Budget exhausted
+	mgr.SendMessage(1, "pending", MessageTypeNormal)
+	if err := db.WaitAsync(time.Second); err != nil {
+		t.Fatalf("WaitAsync failed: %v", err)
+	}
+
+	// After a restart the undelivered messages are queued again
+	restarted := NewManager(db, toxMgr, contactMgr)
+	restarted.SetRetryPolicy(policy)
+	if err := restarted.RestoreOutbox(); err != nil {
+		t.Fatalf("RestoreOutbox failed: %v", err)
+	}
+
+	queued := restarted.GetQueuedMessages()
+	contents := map[string]bool{}
+	for _, msg := range queued {
+		contents[msg.Content] = true
+	}
+	if len(queued) != 2 || !contents["gave up"] || !contents["pending"] {
+		t.Fatalf("Expected the failed and pending messages to be queued, got %v", queued)
+	}
+
+	stored, err := restarted.GetMessages(1, 10, 0)
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	for _, msg := range stored {
+		if msg.FailedAt != nil {
+			t.Errorf("Expected %q to be pending again, got FailedAt %v", msg.Content, msg.FailedAt)
+		}
+	}
+
+	// Both are sent once the friend is back
+	toxMgr.sendError = nil
+	restarted.ProcessPending()
+	if queued := restarted.GetQueuedMessages(); len(queued) != 0 {
+		t.Errorf("Expected empty queue after delivery, got %v", queued)
+	}
+}
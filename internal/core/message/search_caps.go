@@ -0,0 +1,51 @@
+package message
+
+// SearchCaps describes which search features the database supports
+type SearchCaps struct {
+	FullText bool // Indexed full-text matching (FTS5); otherwise LIKE scans
+	Ranking  bool // Relevance ranking with bm25()
+	Snippets bool // Highlighted excerpts with snippet()
+}
+
+// Degraded reports whether search runs without any of the FTS features
+func (c SearchCaps) Degraded() bool {
+	return !c.FullText || !c.Ranking || !c.Snippets
+}
+
+// Notice returns a short explanation for the UI when search is degraded
+func (c SearchCaps) Notice() string {
+	if !c.FullText {
+		return "Search is limited on this build: results are matched by plain text, not ranked, and have no snippets."
+	}
+	if c.Degraded() {
+		return "Some search features are unavailable on this build."
+	}
+	return ""
+}
+
+// SearchCapabilities probes the search index so the UI can adapt to builds
+// without FTS5, where search falls back to LIKE
+func (m *Manager) SearchCapabilities() SearchCaps {
+	var caps SearchCaps
+	if !m.isFTSAvailable() {
+		return caps
+	}
+
+	// Each probe is a zero-row query that fails if the feature is missing
+	probe := func(query string) bool {
+		rows, err := m.db.Query(query)
+		if err != nil {
+			return false
+		}
+		rows.Close()
+		return true
+	}
+
+	caps.FullText = probe(`SELECT rowid FROM messages_fts WHERE messages_fts MATCH 'probe' LIMIT 0`)
+	if !caps.FullText {
+		return caps
+	}
+	caps.Ranking = probe(`SELECT bm25(messages_fts) FROM messages_fts WHERE messages_fts MATCH 'probe' LIMIT 0`)
+	caps.Snippets = probe(`SELECT snippet(messages_fts, 0, '[', ']', '…', 8) FROM messages_fts WHERE messages_fts MATCH 'probe' LIMIT 0`)
+	return caps
+}
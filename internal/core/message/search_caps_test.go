@@ -0,0 +1,83 @@
+package message
+
+import (
+	"testing"
+)
+
+// fts5Compiled reports whether the SQLite build includes FTS5
+func fts5Compiled(t *testing.T, manager *Manager) bool {
+	t.Helper()
+
+	if _, err := manager.db.Exec(`CREATE VIRTUAL TABLE _caps_probe USING fts5(content)`); err != nil {
+		return false
+	}
+	manager.db.Exec(`DROP TABLE _caps_probe`)
+	return true
+}
+
+func TestSearchCapabilities(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, manager *Manager)
+		want  SearchCaps
+	}{
+		{
+			name: "no index",
+			setup: func(t *testing.T, manager *Manager) {
+				if _, err := manager.db.Exec(`DROP TABLE IF EXISTS messages_fts`); err != nil {
+					t.Fatalf("Failed to drop index: %v", err)
+				}
+			},
+			want: SearchCaps{},
+		},
+		{
+			name: "index table without FTS5",
+			setup: func(t *testing.T, manager *Manager) {
+				// A plain table with the index's name, as left by a broken migration
+				manager.db.Exec(`DROP TABLE IF EXISTS messages_fts`)
+				if _, err := manager.db.Exec(`CREATE TABLE messages_fts (content TEXT)`); err != nil {
+					t.Fatalf("Failed to create table: %v", err)
+				}
+			},
+			want: SearchCaps{},
+		},
+		{
+			name: "FTS5 index",
+			setup: func(t *testing.T, manager *Manager) {
+				if !fts5Compiled(t, manager) {
+					t.Skip("FTS5 not compiled in; build with -tags sqlite_fts5")
+				}
+			},
+			want: SearchCaps{FullText: true, Ranking: true, Snippets: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := createTestManagerWithMessages(t, 15)
+			defer cleanup(manager)
+
+			tt.setup(t, manager)
+
+			caps := manager.SearchCapabilities()
+			if caps != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, caps)
+			}
+			if caps.Degraded() != (tt.want != SearchCaps{FullText: true, Ranking: true, Snippets: true}) {
+				t.Errorf("Unexpected Degraded() = %v for %+v", caps.Degraded(), caps)
+			}
+			if (caps.Notice() == "") != !caps.Degraded() {
+				t.Errorf("Expected a notice only when degraded, got %q", caps.Notice())
+			}
+
+			// Search keeps working either way
+			results, err := manager.SearchMessages("hello world", 10)
+			if err != nil {
+				t.Fatalf("SearchMessages failed: %v", err)
+			}
+			if len(results) == 0 {
+				t.Error("Expected search results")
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package message
+
+import (
+	"fmt"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// DefaultSearchIndexDriftThreshold is how many rows the search index may differ
+// from the searchable messages before the startup check rebuilds it
+const DefaultSearchIndexDriftThreshold = 10
+
+// searchableMessages selects the messages that belong in the search index
+const searchableMessages = `FROM messages WHERE is_deleted = 0 AND content IS NOT NULL AND content != ''`
+
+// RebuildSearchIndex clears the full-text search index and repopulates it from
+// the current non-deleted messages in a single transaction
+func (m *Manager) RebuildSearchIndex() error {
+	if !m.isFTSAvailable() {
+		return nil
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM messages_fts`); err != nil {
+		return fmt.Errorf("failed to clear search index: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO messages_fts(rowid, content) SELECT id, content ` + searchableMessages); err != nil {
+		return fmt.Errorf("failed to repopulate search index: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit search index rebuild: %w", err)
+	}
+	return nil
+}
+
+// CheckSearchIndex compares the search index with the searchable messages and
+// rebuilds it when the row counts differ by more than threshold. It reports
+// whether a rebuild ran.
+func (m *Manager) CheckSearchIndex(threshold int) (bool, error) {
+	if !m.isFTSAvailable() {
+		return false, nil
+	}
+
+	var indexed, searchable int
+	if err := m.db.QueryRow(`SELECT COUNT(*) FROM messages_fts`).Scan(&indexed); err != nil {
+		return false, fmt.Errorf("failed to count search index rows: %w", err)
+	}
+	if err := m.db.QueryRow(`SELECT COUNT(*) ` + searchableMessages).Scan(&searchable); err != nil {
+		return false, fmt.Errorf("failed to count messages: %w", err)
+	}
+
+	drift := indexed - searchable
+	if drift < 0 {
+		drift = -drift
+	}
+	if drift <= threshold {
+		return false, nil
+	}
+
+	logging.Warnf("Search index has %d rows for %d messages, rebuilding", indexed, searchable)
+	if err := m.RebuildSearchIndex(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
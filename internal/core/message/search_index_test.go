@@ -0,0 +1,151 @@
+package message
+
+import (
+	"testing"
+)
+
+// searchIDs returns the IDs of the messages a search finds
+func searchIDs(t *testing.T, manager *Manager, query string) map[int64]bool {
+	t.Helper()
+
+	results, err := manager.searchWithFTS(query, 100)
+	if err != nil {
+		t.Fatalf("FTS search failed: %v", err)
+	}
+	ids := make(map[int64]bool, len(results))
+	for _, msg := range results {
+		ids[msg.ID] = true
+	}
+	return ids
+}
+
+func TestRebuildSearchIndex(t *testing.T) {
+	manager := createTestManagerWithMessages(t, 15)
+	defer cleanup(manager)
+	if !manager.isFTSAvailable() {
+		t.Skip("FTS5 not available")
+	}
+
+	want := searchIDs(t, manager, "hello world")
+	if len(want) == 0 {
+		t.Fatal("Expected search results before desync")
+	}
+
+	// Desync the index: lose some rows and index a message under the wrong text
+	if _, err := manager.db.Exec(`DELETE FROM messages_fts WHERE rowid IN (SELECT rowid FROM messages_fts LIMIT 3)`); err != nil {
+		t.Fatalf("Failed to remove index rows: %v", err)
+	}
+	var strayID int64
+	if err := manager.db.QueryRow(`SELECT id FROM messages WHERE content = 'test data sample'`).Scan(&strayID); err != nil {
+		t.Fatalf("Failed to find message: %v", err)
+	}
+	if _, err := manager.db.Exec(`DELETE FROM messages_fts WHERE rowid = ?`, strayID); err != nil {
+		t.Fatalf("Failed to remove index row: %v", err)
+	}
+	if _, err := manager.db.Exec(`INSERT INTO messages_fts(rowid, content) VALUES (?, 'hello world stale')`, strayID); err != nil {
+		t.Fatalf("Failed to insert stale index row: %v", err)
+	}
+
+	if got := searchIDs(t, manager, "hello world"); len(got) == len(want) && !got[strayID] {
+		t.Fatal("Expected desynced index to return wrong results")
+	}
+
+	if err := manager.RebuildSearchIndex(); err != nil {
+		t.Fatalf("RebuildSearchIndex failed: %v", err)
+	}
+
+	got := searchIDs(t, manager, "hello world")
+	if len(got) != len(want) {
+		t.Errorf("Expected %d results after rebuild, got %d", len(want), len(got))
+	}
+	for id := range want {
+		if !got[id] {
+			t.Errorf("Message %d missing from results after rebuild", id)
+		}
+	}
+	if got[strayID] {
+		t.Error("Stale index entry survived the rebuild")
+	}
+}
+
+func TestRebuildSearchIndexSkipsDeleted(t *testing.T) {
+	manager := createTestManagerWithMessages(t, 15)
+	defer cleanup(manager)
+	if !manager.isFTSAvailable() {
+		t.Skip("FTS5 not available")
+	}
+
+	// Soft-delete bypassing the triggers' view of the index
+	if _, err := manager.db.Exec(`UPDATE messages SET is_deleted = 1 WHERE content = 'hello everyone'`); err != nil {
+		t.Fatalf("Failed to delete message: %v", err)
+	}
+	if _, err := manager.db.Exec(`INSERT INTO messages_fts(rowid, content) SELECT id, content FROM messages WHERE content = 'hello everyone'`); err != nil {
+		t.Fatalf("Failed to index deleted message: %v", err)
+	}
+
+	if err := manager.RebuildSearchIndex(); err != nil {
+		t.Fatalf("RebuildSearchIndex failed: %v", err)
+	}
+
+	var indexed int
+	if err := manager.db.QueryRow(`SELECT COUNT(*) FROM messages_fts WHERE messages_fts MATCH '"hello everyone"'`).Scan(&indexed); err != nil {
+		t.Fatalf("Failed to query index: %v", err)
+	}
+	if indexed != 0 {
+		t.Errorf("Deleted message still indexed %d times", indexed)
+	}
+}
+
+func TestCheckSearchIndex(t *testing.T) {
+	manager := createTestManagerWithMessages(t, 40)
+	defer cleanup(manager)
+	if !manager.isFTSAvailable() {
+		t.Skip("FTS5 not available")
+	}
+
+	rebuilt, err := manager.CheckSearchIndex(DefaultSearchIndexDriftThreshold)
+	if err != nil {
+		t.Fatalf("CheckSearchIndex failed: %v", err)
+	}
+	if rebuilt {
+		t.Error("Expected no rebuild for a consistent index")
+	}
+
+	tests := []struct {
+		name        string
+		dropRows    int
+		wantRebuilt bool
+	}{
+		{"within threshold", 5, false},
+		{"beyond threshold", 20, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := manager.RebuildSearchIndex(); err != nil {
+				t.Fatalf("RebuildSearchIndex failed: %v", err)
+			}
+			if _, err := manager.db.Exec(`DELETE FROM messages_fts WHERE rowid IN (SELECT rowid FROM messages_fts LIMIT ?)`, tt.dropRows); err != nil {
+				t.Fatalf("Failed to remove index rows: %v", err)
+			}
+
+			rebuilt, err := manager.CheckSearchIndex(DefaultSearchIndexDriftThreshold)
+			if err != nil {
+				t.Fatalf("CheckSearchIndex failed: %v", err)
+			}
+			if rebuilt != tt.wantRebuilt {
+				t.Errorf("Expected rebuilt=%v, got %v", tt.wantRebuilt, rebuilt)
+			}
+
+			var indexed int
+			manager.db.QueryRow(`SELECT COUNT(*) FROM messages_fts`).Scan(&indexed)
+			if wantIndexed := 40 - tt.dropRows; tt.wantRebuilt {
+				if indexed != 40 {
+					t.Errorf("Expected 40 indexed rows after rebuild, got %d", indexed)
+				}
+			} else if indexed != wantIndexed {
+				t.Errorf("Expected %d indexed rows, got %d", wantIndexed, indexed)
+			}
+		})
+	}
+}
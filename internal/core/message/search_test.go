@@ -266,3 +266,150 @@ func cleanup(manager *Manager) {
 		manager.db.Close()
 	}
 }
+
+// TestSearchMessagesFiltered tests combining a text query with date range, direction, and type filters
+func TestSearchMessagesFiltered(t *testing.T) {
+	manager, _, _, _, cleanupManager := setupTestManager(t)
+	defer cleanupManager()
+
+	now := time.Now()
+	seed := []*Message{
+		{FriendID: 1, Content: "project update today", IsOutgoing: false, Timestamp: now.Add(-1 * time.Hour)},
+		{FriendID: 1, Content: "project update sent", IsOutgoing: true, Timestamp: now.Add(-2 * time.Hour)},
+		{FriendID: 2, Content: "old project update", IsOutgoing: false, Timestamp: now.Add(-72 * time.Hour)},
+		{FriendID: 2, Content: "unrelated chatter", IsOutgoing: false, Timestamp: now.Add(-30 * time.Minute)},
+		{FriendID: 3, Content: "project update action", IsOutgoing: false, Timestamp: now.Add(-10 * time.Minute), MessageType: MessageTypeAction},
+	}
+	for i, msg := range seed {
+		msg.UUID = fmt.Sprintf("filtered-%d", i)
+		if err := manager.saveMessage(msg); err != nil {
+			t.Fatalf("Failed to seed message: %v", err)
+		}
+	}
+
+	friendOne := uint32(1)
+
+	testCases := []struct {
+		name     string
+		opts     SearchOptions
+		expected []string
+	}{
+		{
+			name: "text with date range and incoming direction",
+			opts: SearchOptions{
+				Query:     "project",
+				Since:     now.Add(-24 * time.Hour),
+				Direction: SearchDirectionIncoming,
+			},
+			expected: []string{"project update action", "project update today"},
+		},
+		{
+			name: "text with outgoing direction",
+			opts: SearchOptions{
+				Query:     "project",
+				Direction: SearchDirectionOutgoing,
+			},
+			expected: []string{"project update sent"},
+		},
+		{
+			name: "text with message type",
+			opts: SearchOptions{
+				Query:        "project",
+				MessageTypes: []MessageType{MessageTypeNormal},
+				Until:        now.Add(-90 * time.Minute),
+			},
+			expected: []string{"project update sent", "old project update"},
+		},
+		{
+			name: "empty text with friend filter",
+			opts: SearchOptions{
+				FriendID: &friendOne,
+			},
+			expected: []string{"project update today", "project update sent"},
+		},
+		{
+			name: "empty text with date range",
+			opts: SearchOptions{
+				Since: now.Add(-45 * time.Minute),
+			},
+			expected: []string{"project update action", "unrelated chatter"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := manager.SearchMessagesFiltered(tc.opts)
+			if err != nil {
+				t.Fatalf("SearchMessagesFiltered failed: %v", err)
+			}
+
+			if len(results) != len(tc.expected) {
+				t.Fatalf("Expected %d results, got %d", len(tc.expected), len(results))
+			}
+
+			for i, msg := range results {
+				if msg.Content != tc.expected[i] {
+					t.Errorf("Result %d: expected %q, got %q", i, tc.expected[i], msg.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchSpecialCharacters(t *testing.T) {
+	manager, _, _, _, cleanupManager := setupTestManager(t)
+	defer cleanupManager()
+
+	for i, content := range []string{`foo*bar`, `she said "hi" twice`, `100% sure`, `snake_case name`} {
+		msg := &Message{UUID: fmt.Sprintf("special-%d", i), FriendID: 1, Content: content, Timestamp: time.Now()}
+		if err := manager.saveMessage(msg); err != nil {
+			t.Fatalf("Failed to seed message: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`foo*`, `foo*bar`},
+		{`"hi"`, `she said "hi" twice`},
+		{`said "hi`, `she said "hi" twice`},
+		{`100%`, `100% sure`},
+		{`snake_case`, `snake_case name`},
+		{`NEAR(`, ``},
+	}
+
+	for _, tt := range tests {
+		results, err := manager.SearchMessages(tt.query, 10)
+		if err != nil {
+			t.Errorf("SearchMessages(%q) failed: %v", tt.query, err)
+			continue
+		}
+		filtered, err := manager.SearchMessagesFiltered(SearchOptions{Query: tt.query})
+		if err != nil {
+			t.Errorf("SearchMessagesFiltered(%q) failed: %v", tt.query, err)
+			continue
+		}
+		if tt.want == "" {
+			continue
+		}
+		for _, set := range [][]*Message{results, filtered} {
+			found := false
+			for _, msg := range set {
+				found = found || msg.Content == tt.want
+			}
+			if !found {
+				t.Errorf("Expected %q to find %q", tt.query, tt.want)
+			}
+		}
+	}
+}
+
+func TestSearchQueryEscaping(t *testing.T) {
+	if got := ftsPhrase(`say "hi"`); got != `"say ""hi"""` {
+		t.Errorf("ftsPhrase = %s", got)
+	}
+	if got := likePattern(`100%_\`); got != `%100\%\_\\%` {
+		t.Errorf("likePattern = %s", got)
+	}
+}
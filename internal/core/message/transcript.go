@@ -0,0 +1,92 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported transcript formats
+const (
+	TranscriptFormatText     = "text"
+	TranscriptFormatMarkdown = "markdown"
+)
+
+// transcriptTimeFormat is how message times appear in transcripts
+const transcriptTimeFormat = "2006-01-02 15:04"
+
+// displayNamer is implemented by contacts that can name themselves
+type displayNamer interface {
+	DisplayName() string
+}
+
+// ExportRange returns a transcript of the messages with a friend whose IDs lie
+// between fromID and toID inclusive, oldest first, with sender and timestamp.
+// The bounds may be given in either order.
+func (m *Manager) ExportRange(friendID uint32, fromID, toID int64, format string) (string, error) {
+	if format != TranscriptFormatText && format != TranscriptFormatMarkdown {
+		return "", fmt.Errorf("unsupported transcript format: %s", format)
+	}
+	if fromID > toID {
+		fromID, toID = toID, fromID
+	}
+
+	rows, err := m.db.Query(`
+		SELECT id, uuid, friend_id, content, message_type, is_outgoing,
+		       timestamp, delivered_at, read_at, edited_at, original_content,
+		       file_path, file_size, file_type, is_deleted, reply_to_id, reply_to_uuid,
+		       failed_at
+		FROM messages
+		WHERE friend_id = ? AND id BETWEEN ? AND ? AND is_deleted = 0
+		ORDER BY timestamp ASC, id ASC
+	`, friendID, fromID, toID)
+	if err != nil {
+		return "", fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages, err := m.scanMessageRows(rows)
+	if err != nil {
+		return "", err
+	}
+	if len(messages) == 0 {
+		return "", fmt.Errorf("no messages between %d and %d", fromID, toID)
+	}
+
+	friendName := m.friendName(friendID)
+	var b strings.Builder
+	for i, msg := range messages {
+		sender := friendName
+		if msg.IsOutgoing {
+			sender = "You"
+		}
+		timestamp := msg.Timestamp.Local().Format(transcriptTimeFormat)
+
+		if format == TranscriptFormatMarkdown {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "**%s** (%s):\n", sender, timestamp)
+			for _, line := range strings.Split(msg.Content, "\n") {
+				b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+			continue
+		}
+
+		// Continuation lines are indented under the first
+		content := strings.ReplaceAll(msg.Content, "\n", "\n    ")
+		fmt.Fprintf(&b, "[%s] %s: %s\n", timestamp, sender, content)
+	}
+	return b.String(), nil
+}
+
+// friendName returns the name to attribute a friend's messages to
+func (m *Manager) friendName(friendID uint32) string {
+	if m.contacts != nil {
+		if c, ok := m.contacts.GetContact(friendID); ok {
+			if named, ok := c.(displayNamer); ok && named.DisplayName() != "" {
+				return named.DisplayName()
+			}
+		}
+	}
+	return fmt.Sprintf("Friend %d", friendID)
+}
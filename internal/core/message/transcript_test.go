@@ -0,0 +1,98 @@
+package message
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// namedContact stands in for a contact that can name itself
+type namedContact string
+
+func (c namedContact) DisplayName() string { return string(c) }
+
+func TestExportRange(t *testing.T) {
+	mgr, _, _, contactMgr, cleanup := setupTestManager(t)
+	defer cleanup()
+	contactMgr.AddContact(1, namedContact("Alice"))
+
+	var ids []int64
+	for _, step := range []struct {
+		outgoing bool
+		content  string
+	}{
+		{false, "before the range"},
+		{false, "lunch?"},
+		{true, "sure\nwhere?"},
+		{false, "the usual place"},
+		{true, "after the range"},
+	} {
+		var msg *Message
+		var err error
+		if step.outgoing {
+			msg, err = mgr.SendMessage(1, step.content, MessageTypeNormal)
+		} else {
+			msg = mgr.HandleIncomingMessage(1, step.content, MessageTypeNormal)
+		}
+		if err != nil || msg == nil {
+			t.Fatalf("Failed to store %q: %v", step.content, err)
+		}
+		ids = append(ids, msg.ID)
+		time.Sleep(2 * time.Millisecond) // Keep timestamps distinct
+	}
+
+	text, err := mgr.ExportRange(1, ids[3], ids[1], TranscriptFormatText) // Reversed bounds
+	if err != nil {
+		t.Fatalf("ExportRange failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	wantSuffixes := []string{"] Alice: lunch?", "] You: sure", "    where?", "] Alice: the usual place"}
+	if len(lines) != len(wantSuffixes) {
+		t.Fatalf("Expected %d lines, got %q", len(wantSuffixes), text)
+	}
+	for i, suffix := range wantSuffixes {
+		if !strings.HasSuffix(lines[i], suffix) {
+			t.Errorf("Line %d: expected suffix %q, got %q", i, suffix, lines[i])
+		}
+	}
+	if strings.Contains(text, "before the range") || strings.Contains(text, "after the range") {
+		t.Errorf("Transcript includes messages outside the range: %q", text)
+	}
+
+	markdown, err := mgr.ExportRange(1, ids[1], ids[2], TranscriptFormatMarkdown)
+	if err != nil {
+		t.Fatalf("ExportRange failed: %v", err)
+	}
+	for _, want := range []string{"**Alice** (", "> lunch?\n", "**You** (", "> sure\n> where?\n"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected markdown to contain %q, got %q", want, markdown)
+		}
+	}
+	if strings.Index(markdown, "**Alice**") > strings.Index(markdown, "**You**") {
+		t.Errorf("Expected messages oldest first, got %q", markdown)
+	}
+}
+
+func TestExportRangeErrors(t *testing.T) {
+	mgr, _, _, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	msg := mgr.HandleIncomingMessage(1, "hello", MessageTypeNormal)
+	if msg == nil {
+		t.Fatal("Failed to store message")
+	}
+
+	if _, err := mgr.ExportRange(1, msg.ID, msg.ID, "html"); err == nil {
+		t.Error("Expected error for an unsupported format")
+	}
+	if _, err := mgr.ExportRange(2, msg.ID, msg.ID, TranscriptFormatText); err == nil {
+		t.Error("Expected error when the range has no messages for the friend")
+	}
+
+	// Contacts without a name are attributed by friend ID
+	text, err := mgr.ExportRange(1, msg.ID, msg.ID, TranscriptFormatText)
+	if err != nil || !strings.Contains(text, "Friend 1: hello") {
+		t.Errorf("Expected fallback attribution, got %q (%v)", text, err)
+	}
+}
@@ -0,0 +1,208 @@
+package message
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// DefaultTypingTimeout is how long typing must pause before friends are told
+// that we stopped
+const DefaultTypingTimeout = 3 * time.Second
+
+// controlTyping marks a wire message carrying our typing state. toxcore has
+// no typing API, so the state travels as a control message to Whisp peers.
+const controlTyping = "typing"
+
+// SetTypingIndicators sets whether our typing state is sent to friends and
+// whether the typing state of friends is shown. The two are independent.
+func (m *Manager) SetTypingIndicators(send, show bool) {
+	m.mu.Lock()
+	m.sendTyping = send
+	m.showTyping = show
+	m.mu.Unlock()
+
+	if !show {
+		for _, friendID := range m.typingFriends() {
+			m.setFriendTyping(friendID, false)
+		}
+	}
+}
+
+// SendTyping tells a friend whether we are typing to them. Nothing is sent
+// while sending typing indicators is disabled or to friends whose clients
+// do not show them.
+func (m *Manager) SendTyping(friendID uint32, typing bool) error {
+	m.mu.RLock()
+	enabled := m.sendTyping
+	m.mu.RUnlock()
+
+	if !enabled || !m.peerSupports(friendID, FeatureTyping) {
+		return nil
+	}
+
+	state := "0"
+	if typing {
+		state = "1"
+	}
+	wireContent := encodeWire(wireHeader{Control: controlTyping}, state)
+	if err := m.toxMgr.SendMessage(friendID, wireContent, toxcore.MessageTypeNormal); err != nil {
+		return fmt.Errorf("failed to send typing state: %w", err)
+	}
+	return nil
+}
+
+// SetOnFriendTyping sets the callback invoked when a friend starts or stops typing
+func (m *Manager) SetOnFriendTyping(callback func(friendID uint32, typing bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onFriendTyping = callback
+}
+
+// IsFriendTyping reports whether a friend is typing to us
+func (m *Manager) IsFriendTyping(friendID uint32) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.friendTyping[friendID]
+}
+
+// handleTyping records a friend's typing state unless showing it is disabled
+func (m *Manager) handleTyping(friendID uint32, body string) {
+	m.mu.RLock()
+	show := m.showTyping
+	m.mu.RUnlock()
+
+	if !show {
+		return
+	}
+	switch body {
+	case "1":
+		m.setFriendTyping(friendID, true)
+	case "0":
+		m.setFriendTyping(friendID, false)
+	default:
+		logging.Warnf("Ignoring malformed typing state from friend %d", friendID)
+	}
+}
+
+// setFriendTyping updates a friend's typing state and runs the callback if it changed
+func (m *Manager) setFriendTyping(friendID uint32, typing bool) {
+	m.mu.Lock()
+	if m.friendTyping[friendID] == typing {
+		m.mu.Unlock()
+		return
+	}
+	if typing {
+		m.friendTyping[friendID] = true
+	} else {
+		delete(m.friendTyping, friendID)
+	}
+	callback := m.onFriendTyping
+	m.mu.Unlock()
+
+	if callback != nil {
+		callback(friendID, typing)
+	}
+}
+
+// typingFriends returns the friends currently typing to us
+func (m *Manager) typingFriends() []uint32 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	friendIDs := make([]uint32, 0, len(m.friendTyping))
+	for friendID := range m.friendTyping {
+		friendIDs = append(friendIDs, friendID)
+	}
+	return friendIDs
+}
+
+// TypingNotifier turns input changes into typing state updates. Friends are
+// told once when typing starts and again when it stops: after the timeout
+// without changes, when the input is cleared, or when Stop is called.
+type TypingNotifier struct {
+	send    func(friendID uint32, typing bool) error
+	timeout time.Duration
+
+	mu     sync.Mutex
+	typing map[uint32]bool
+	text   map[uint32]string
+	timers map[uint32]*time.Timer
+}
+
+// NewTypingNotifier creates a notifier that reports typing state through send
+func NewTypingNotifier(send func(friendID uint32, typing bool) error, timeout time.Duration) *TypingNotifier {
+	return &TypingNotifier{
+		send:    send,
+		timeout: timeout,
+		typing:  make(map[uint32]bool),
+		text:    make(map[uint32]string),
+		timers:  make(map[uint32]*time.Timer),
+	}
+}
+
+// Update records the current input for a conversation
+func (n *TypingNotifier) Update(friendID uint32, text string) {
+	if text == "" {
+		n.Stop(friendID)
+		return
+	}
+
+	n.mu.Lock()
+	if n.text[friendID] == text {
+		n.mu.Unlock()
+		return
+	}
+	n.text[friendID] = text
+
+	if timer, exists := n.timers[friendID]; exists {
+		timer.Stop()
+	}
+	n.timers[friendID] = time.AfterFunc(n.timeout, func() {
+		n.Stop(friendID)
+	})
+
+	started := !n.typing[friendID]
+	n.typing[friendID] = true
+	n.mu.Unlock()
+
+	if started {
+		n.notify(friendID, true)
+	}
+}
+
+// SetText records input that was not typed by the user, such as a restored
+// draft, without reporting typing
+func (n *TypingNotifier) SetText(friendID uint32, text string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.text[friendID] = text
+}
+
+// Stop reports that typing stopped, e.g. because the message was sent
+func (n *TypingNotifier) Stop(friendID uint32) {
+	n.mu.Lock()
+	if timer, exists := n.timers[friendID]; exists {
+		timer.Stop()
+		delete(n.timers, friendID)
+	}
+	delete(n.text, friendID)
+	wasTyping := n.typing[friendID]
+	delete(n.typing, friendID)
+	n.mu.Unlock()
+
+	if wasTyping {
+		n.notify(friendID, false)
+	}
+}
+
+// notify sends a typing state, logging failures
+func (n *TypingNotifier) notify(friendID uint32, typing bool) {
+	if err := n.send(friendID, typing); err != nil {
+		logging.Warnf("%v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package message
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// typingRecorder collects the typing states a notifier sends
+type typingRecorder struct {
+	mu     sync.Mutex
+	states []bool
+}
+
+func (r *typingRecorder) send(friendID uint32, typing bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states = append(r.states, typing)
+	return nil
+}
+
+func (r *typingRecorder) get() []bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]bool(nil), r.states...)
+}
+
+func TestTypingNotifierDebounce(t *testing.T) {
+	recorder := &typingRecorder{}
+	notifier := NewTypingNotifier(recorder.send, 50*time.Millisecond)
+
+	// Keystrokes while typing send a single start
+	notifier.Update(1, "h")
+	notifier.Update(1, "he")
+	notifier.Update(1, "hey")
+	if states := recorder.get(); len(states) != 1 || !states[0] {
+		t.Fatalf("Expected one typing start, got %v", states)
+	}
+
+	// A pause sends a stop
+	deadline := time.Now().Add(2 * time.Second)
+	for len(recorder.get()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if states := recorder.get(); len(states) != 2 || states[1] {
+		t.Fatalf("Expected typing to stop after the timeout, got %v", states)
+	}
+
+	// Sending the message stops typing right away, and only once
+	notifier.Update(1, "hey there")
+	notifier.Stop(1)
+	notifier.Update(1, "")
+	if states := recorder.get(); len(states) != 4 || !states[2] || states[3] {
+		t.Fatalf("Expected start then stop on send, got %v", states)
+	}
+
+	// A restored draft is not typing
+	notifier.SetText(2, "draft")
+	notifier.Update(2, "draft")
+	if states := recorder.get(); len(states) != 4 {
+		t.Errorf("Expected a restored draft not to report typing, got %v", states)
+	}
+}
+
+func TestTypingIndicatorGating(t *testing.T) {
+	tests := []struct {
+		name       string
+		send, show bool
+	}{
+		{"both enabled", true, true},
+		{"send only", true, false},
+		{"show only", false, true},
+		{"both disabled", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+			defer cleanup()
+
+			mgr.SetTypingIndicators(tt.send, tt.show)
+			completeHandshake(t, mgr, 1)
+
+			toxMgr.lastMessage = ""
+			if err := mgr.SendTyping(1, true); err != nil {
+				t.Fatalf("SendTyping failed: %v", err)
+			}
+			header, body := decodeWire(toxMgr.lastMessage)
+			sent := header.Control == controlTyping && body == "1"
+			if sent != tt.send {
+				t.Errorf("Expected sent=%v, got %q", tt.send, toxMgr.lastMessage)
+			}
+
+			var events []bool
+			mgr.SetOnFriendTyping(func(friendID uint32, typing bool) { events = append(events, typing) })
+			if stored := mgr.HandleIncomingMessage(1, encodeWire(wireHeader{Control: controlTyping}, "1"), MessageTypeNormal); stored != nil {
+				t.Fatal("Expected the typing state not to be stored as a message")
+			}
+			if mgr.IsFriendTyping(1) != tt.show {
+				t.Errorf("Expected friend typing=%v", tt.show)
+			}
+
+			// The friend's message ends their typing
+			mgr.HandleIncomingMessage(1, "hello", MessageTypeNormal)
+			if mgr.IsFriendTyping(1) {
+				t.Error("Expected typing to end when the message arrives")
+			}
+			if tt.show && (len(events) != 2 || !events[0] || events[1]) {
+				t.Errorf("Expected start and stop events, got %v", events)
+			}
+			if !tt.show && len(events) != 0 {
+				t.Errorf("Expected no events while hidden, got %v", events)
+			}
+		})
+	}
+}
+
+func TestSendTypingNeedsPeerSupport(t *testing.T) {
+	mgr, _, toxMgr, _, cleanup := setupTestManager(t)
+	defer cleanup()
+
+	mgr.SetTypingIndicators(true, true)
+	toxMgr.lastMessage = ""
+	if err := mgr.SendTyping(1, true); err != nil || toxMgr.lastMessage != "" {
+		t.Errorf("Expected nothing sent before the handshake, got %q (%v)", toxMgr.lastMessage, err)
+	}
+}
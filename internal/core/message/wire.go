@@ -0,0 +1,62 @@
+package message
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Whisp clients prefix message text with a small metadata header so that peers
+// can share message identity. The header is JSON between the prefix and a unit
+// separator; JSON encoding escapes control characters, so the first separator
+// after the prefix always ends the header. Messages without the prefix are
+// treated as plain text from other Tox clients.
+const (
+	wirePrefix    = "\x1fwhisp1"
+	wireSeparator = "\x1f"
+)
+
+// helloMarker is appended to plain text sent to friends whose client is not
+// known yet. It is made of zero-width characters that other clients do not
+// display, and tells a Whisp peer to start the capability handshake.
+const helloMarker = "\u2060\u200b\u2060\u200c"
+
+// wireHeader carries message metadata alongside the text
+type wireHeader struct {
+	ID      string `json:"id,omitempty"`  // Sender's message UUID
+	ReplyTo string `json:"re,omitempty"`  // UUID of the message being replied to
+	Control string `json:"ctl,omitempty"` // Control message kind; the body is its payload
+	TTL     int64  `json:"ttl,omitempty"` // Seconds until a disappearing message expires
+
+	Forwarded bool `json:"fwd,omitempty"` // Copied from another conversation
+}
+
+// encodeWire attaches a metadata header to message text
+func encodeWire(header wireHeader, body string) string {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return body
+	}
+	return wirePrefix + string(data) + wireSeparator + body
+}
+
+// decodeWire splits message text into its metadata header and body.
+// Text without a valid header is returned unchanged with an empty header.
+func decodeWire(content string) (wireHeader, string) {
+	var header wireHeader
+
+	if !strings.HasPrefix(content, wirePrefix) {
+		return header, content
+	}
+
+	rest := content[len(wirePrefix):]
+	end := strings.Index(rest, wireSeparator)
+	if end < 0 {
+		return header, content
+	}
+
+	if err := json.Unmarshal([]byte(rest[:end]), &header); err != nil {
+		return wireHeader{}, content
+	}
+
+	return header, rest[end+len(wireSeparator):]
+}
@@ -2,10 +2,12 @@ package core
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"path/filepath"
+	"sync"
 
 	"github.com/opd-ai/toxcore"
+	"github.com/opd-ai/whisp/internal/logging"
 	"github.com/opd-ai/whisp/platform/notifications"
 )
 
@@ -15,6 +17,28 @@ type NotificationService struct {
 	app     *App
 	config  notifications.NotificationConfig
 	enabled bool
+
+	// Message notifications offer reply and mark-read actions handled here
+	showActions bool
+	actions     notificationActions
+
+	// Messages in the conversation the user is looking at are not notified
+	suppressActive bool
+	activeMu       sync.RWMutex
+	activeFriend   uint32
+	hasActive      bool
+	paused         bool // Presentation mode holds back every notification
+
+	// In mentions mode only messages matching a keyword or our name notify
+	filterMu sync.RWMutex
+	mode     string
+	keywords []string
+}
+
+// notificationActions is the part of the app that notification actions call into
+type notificationActions interface {
+	SendMessageFromUI(friendID uint32, content string) error
+	MarkAsRead(friendID uint32) error
 }
 
 // NewNotificationService creates a new notification service
@@ -33,41 +57,58 @@ func NewNotificationService(app *App) *NotificationService {
 	// Create default config from YAML config
 	config, err := notifications.ConfigFromYAML(nil)
 	if err != nil {
-		log.Printf("Warning: Failed to load notification config: %v", err)
+		logging.Warnf("Failed to load notification config: %v", err)
 	}
 
 	service := &NotificationService{
-		manager: manager,
-		app:     app,
-		config:  config,
-		enabled: true,
+		manager:        manager,
+		app:            app,
+		config:         config,
+		enabled:        true,
+		showActions:    true,
+		actions:        app,
+		suppressActive: true,
+		mode:           notifications.ModeAll,
+	}
+	if app.configMgr != nil {
+		cfg := app.configMgr.GetConfig()
+		service.suppressActive = cfg.Notifications.SuppressActiveChat
+		service.showActions = cfg.Notifications.Actions
+		service.SetMessageFilter(cfg.Notifications.Mode, cfg.Notifications.Keywords)
+
+		quiet := cfg.Notifications.QuietHours
+		if quiet.Enabled {
+			quietHours, err := notifications.ParseQuietHours(quiet.Enabled, quiet.StartTime, quiet.EndTime, quiet.Days)
+			if err != nil {
+				logging.Warnf("Ignoring quiet hours: %v", err)
+			} else {
+				service.config.QuietHours = quietHours
+			}
+		}
 	}
+	manager.SetActionHandler(service.handleAction)
+	manager.SetContactOverrides(service.contactOverride)
 
 	// Apply config to manager
-	if err := manager.SetConfig(config); err != nil {
-		log.Printf("Warning: Failed to set notification config: %v", err)
+	if err := manager.SetConfig(service.config); err != nil {
+		logging.Warnf("Failed to set notification config: %v", err)
 	}
 
 	return service
 }
 
-// Start initializes the notification service and sets up callbacks
+// Start initializes the notification service
 func (ns *NotificationService) Start(ctx context.Context) error {
-	if !ns.enabled {
+	if !ns.active() {
 		return nil
 	}
 
 	// Request permission (mainly for mobile platforms)
 	if err := ns.manager.RequestPermission(ctx); err != nil {
-		log.Printf("Warning: Failed to request notification permission: %v", err)
+		logging.Warnf("Failed to request notification permission: %v", err)
 		// Don't fail startup for permission issues
 	}
 
-	// Set up callbacks if we have a tox manager
-	if ns.app.tox != nil {
-		ns.setupToxCallbacks()
-	}
-
 	return nil
 }
 
@@ -76,92 +117,137 @@ func (ns *NotificationService) Stop() error {
 	return ns.manager.Close()
 }
 
-// setupToxCallbacks sets up callbacks to show notifications for Tox events
+// setupToxCallbacks registers the notification handlers directly with Tox.
+// The app normally forwards events from its own callbacks instead, since
+// Tox only keeps one callback per event.
 func (ns *NotificationService) setupToxCallbacks() {
-	// Set up message callback
-	ns.app.tox.OnFriendMessage(func(friendID uint32, message string) {
-		if !ns.enabled {
-			return
-		}
+	ns.app.tox.OnFriendMessage(ns.handleFriendMessage)
+	ns.app.tox.OnFriendRequest(ns.handleFriendRequest)
+	ns.app.tox.OnFriendStatus(ns.handleFriendStatus)
+}
 
-		// Get friend name from contact manager
-		friendName := ns.getFriendName(friendID)
+// handleFriendMessage shows a notification for an incoming message
+func (ns *NotificationService) handleFriendMessage(friendID uint32, message string) {
+	if ns.isActiveConversation(friendID) || !ns.matchesFilter(message) {
+		return
+	}
 
-		// Create and show notification
-		notification := notifications.NewMessageNotification(friendName, message)
-		if err := ns.manager.Show(context.Background(), notification); err != nil {
-			log.Printf("Failed to show message notification: %v", err)
-		}
-	})
+	// Get friend name from contact manager
+	friendName := ns.getFriendName(friendID)
 
-	// Set up friend request callback
-	ns.app.tox.OnFriendRequest(func(publicKey [32]byte, message string) {
-		if !ns.enabled {
-			return
-		}
+	// Create and show notification
+	notification := notifications.NewMessageNotification(friendName, message)
+	if ns.showActions {
+		notification = notifications.NewMessageNotificationWithActions(friendName, message, friendID)
+	}
+	notification.SetFriendID(friendID)
+	if err := ns.show(notification); err != nil {
+		logging.Errorf("Failed to show message notification: %v", err)
+	}
+}
 
-		// Convert public key to display format
-		senderName := "Unknown User" // In a real app, we might decode the key
+// handleAction carries out a notification action without opening the window
+func (ns *NotificationService) handleAction(notification *notifications.Notification, actionID, input string) {
+	friendID, ok := notification.FriendID()
+	if !ok {
+		logging.Warnf("Notification action %s has no conversation", actionID)
+		return
+	}
 
-		// Create and show notification
-		notification := notifications.NewFriendRequestNotification(senderName, message)
-		if err := ns.manager.Show(context.Background(), notification); err != nil {
-			log.Printf("Failed to show friend request notification: %v", err)
+	switch actionID {
+	case notifications.ActionReply:
+		if input == "" {
+			return
 		}
-	})
-
-	// Set up status change callback
-	ns.app.tox.OnFriendStatus(func(friendID uint32, status toxcore.FriendStatus) {
-		if !ns.enabled {
+		if err := ns.actions.SendMessageFromUI(friendID, input); err != nil {
+			logging.Errorf("Failed to send reply from notification: %v", err)
 			return
 		}
-
-		// Get friend name and convert status
-		friendName := ns.getFriendName(friendID)
-		statusStr := "unknown"
-
-		// Convert status to string
-		switch status {
-		case toxcore.FriendStatusNone:
-			statusStr = "offline"
-		case toxcore.FriendStatusAway:
-			statusStr = "away"
-		case toxcore.FriendStatusBusy:
-			statusStr = "busy"
-		default:
-			statusStr = "online"
+		// Replying implies the conversation was read
+		if err := ns.actions.MarkAsRead(friendID); err != nil {
+			logging.Errorf("Failed to mark conversation as read: %v", err)
+		}
+	case notifications.ActionMarkRead:
+		if err := ns.actions.MarkAsRead(friendID); err != nil {
+			logging.Errorf("Failed to mark conversation as read: %v", err)
 		}
+	default:
+		logging.Warnf("Unknown notification action: %s", actionID)
+	}
+}
 
-		// Only notify for online status to avoid spam
-		if statusStr == "online" {
-			notification := notifications.NewStatusNotification(friendName, statusStr)
-			if err := ns.manager.Show(context.Background(), notification); err != nil {
-				log.Printf("Failed to show status notification: %v", err)
-			}
+// handleFriendRequest shows a notification for an incoming friend request
+func (ns *NotificationService) handleFriendRequest(publicKey [32]byte, message string) {
+	// Requests come from strangers, so the start of the key is all we can show
+	senderName := fmt.Sprintf("%X…", publicKey[:4])
+
+	// Create and show notification
+	notification := notifications.NewFriendRequestNotification(senderName, message)
+	if err := ns.show(notification); err != nil {
+		logging.Errorf("Failed to show friend request notification: %v", err)
+	}
+}
+
+// handleFriendStatus shows a notification when a friend comes online
+func (ns *NotificationService) handleFriendStatus(friendID uint32, status toxcore.FriendStatus) {
+	// Get friend name and convert status
+	friendName := ns.getFriendName(friendID)
+	statusStr := "unknown"
+
+	// Convert status to string
+	switch status {
+	case toxcore.FriendStatusNone:
+		statusStr = "offline"
+	case toxcore.FriendStatusAway:
+		statusStr = "away"
+	case toxcore.FriendStatusBusy:
+		statusStr = "busy"
+	default:
+		statusStr = "online"
+	}
+
+	// Only notify for online status to avoid spam
+	if statusStr == "online" {
+		notification := notifications.NewStatusNotification(friendName, statusStr)
+		notification.SetFriendID(friendID)
+		if err := ns.show(notification); err != nil {
+			logging.Errorf("Failed to show status notification: %v", err)
 		}
-	})
+	}
 }
 
-// ShowFileTransferNotification shows a notification for file transfers
-func (ns *NotificationService) ShowFileTransferNotification(friendID uint32, fileName string, isIncoming bool) error {
-	if !ns.enabled {
-		return nil
+// handleSendFailed tells the user a message could not be delivered after all retries
+func (ns *NotificationService) handleSendFailed(friendID uint32) {
+	friendName := ns.getFriendName(friendID)
+	body := fmt.Sprintf("Your message to %s could not be delivered", friendName)
+	if err := ns.ShowCustomNotification(notifications.NotificationMessage, "Message not delivered", body); err != nil {
+		logging.Errorf("Failed to show send failure notification: %v", err)
 	}
+}
 
+// handleFileOffer shows a notification for a file a friend offered, saying
+// whether it is already being saved or waits for the user
+func (ns *NotificationService) handleFileOffer(friendID uint32, fileName string, accepted bool) {
+	notification := notifications.NewFileOfferNotification(ns.getFriendName(friendID), fileName, accepted)
+	notification.SetFriendID(friendID)
+	if err := ns.show(notification); err != nil {
+		logging.Errorf("Failed to show file offer notification: %v", err)
+	}
+}
+
+// ShowFileTransferNotification shows a notification for file transfers
+func (ns *NotificationService) ShowFileTransferNotification(friendID uint32, fileName string, isIncoming bool) error {
 	friendName := ns.getFriendName(friendID)
 	notification := notifications.NewFileTransferNotification(friendName, fileName, isIncoming)
+	notification.SetFriendID(friendID)
 
-	return ns.manager.Show(context.Background(), notification)
+	return ns.show(notification)
 }
 
 // ShowCustomNotification shows a custom notification
 func (ns *NotificationService) ShowCustomNotification(notificationType notifications.NotificationType, title, body string) error {
-	if !ns.enabled {
-		return nil
-	}
-
 	notification := notifications.NewNotification(notificationType, title, body)
-	return ns.manager.Show(context.Background(), notification)
+	return ns.show(notification)
 }
 
 // UpdateConfig updates the notification configuration
@@ -185,6 +271,112 @@ func (ns *NotificationService) SetEnabled(enabled bool) error {
 	return ns.manager.SetConfig(config)
 }
 
+// SetAppearance styles notifications for the app's light or dark theme
+func (ns *NotificationService) SetAppearance(appearance notifications.Appearance) {
+	ns.manager.SetAppearance(appearance)
+}
+
+// SetActiveConversation tells the service which conversation is currently
+// visible and focused so its messages do not raise notifications
+func (ns *NotificationService) SetActiveConversation(friendID uint32) {
+	ns.activeMu.Lock()
+	defer ns.activeMu.Unlock()
+	ns.activeFriend = friendID
+	ns.hasActive = true
+}
+
+// ClearActiveConversation re-enables notifications for all conversations,
+// e.g. when the app loses focus
+func (ns *NotificationService) ClearActiveConversation() {
+	ns.activeMu.Lock()
+	defer ns.activeMu.Unlock()
+	ns.hasActive = false
+}
+
+// SetSuppressActiveConversation enables or disables suppression for the active conversation
+func (ns *NotificationService) SetSuppressActiveConversation(suppress bool) {
+	ns.activeMu.Lock()
+	defer ns.activeMu.Unlock()
+	ns.suppressActive = suppress
+}
+
+// SetPaused holds back all notifications until unpaused, without changing
+// whether notifications are enabled
+func (ns *NotificationService) SetPaused(paused bool) {
+	ns.activeMu.Lock()
+	defer ns.activeMu.Unlock()
+	ns.paused = paused
+}
+
+// show passes a notification to the manager. While paused it is only added
+// to the history, so the user can catch up afterwards.
+func (ns *NotificationService) show(notification *notifications.Notification) error {
+	ns.activeMu.RLock()
+	paused := ns.paused
+	ns.activeMu.RUnlock()
+
+	if paused {
+		ns.manager.RecordSuppressed(notification, notifications.SuppressedPaused)
+		return nil
+	}
+	return ns.manager.Show(context.Background(), notification)
+}
+
+// GetHistory returns up to limit recent notifications, newest first,
+// including those held back while disabled, paused or in quiet hours
+func (ns *NotificationService) GetHistory(limit int) []notifications.HistoryEntry {
+	return ns.manager.GetHistory(limit)
+}
+
+// ClearHistory empties the notification history
+func (ns *NotificationService) ClearHistory() {
+	ns.manager.ClearHistory()
+}
+
+// active reports whether notifications are enabled and not paused
+func (ns *NotificationService) active() bool {
+	ns.activeMu.RLock()
+	defer ns.activeMu.RUnlock()
+	return ns.enabled && !ns.paused
+}
+
+// isActiveConversation reports whether notifications for a friend are suppressed
+func (ns *NotificationService) isActiveConversation(friendID uint32) bool {
+	ns.activeMu.RLock()
+	defer ns.activeMu.RUnlock()
+	return ns.suppressActive && ns.hasActive && ns.activeFriend == friendID
+}
+
+// SetMessageFilter sets the message notification mode and the keywords that
+// notify in mentions mode. An empty mode notifies for every message.
+func (ns *NotificationService) SetMessageFilter(mode string, keywords []string) {
+	if mode == "" {
+		mode = notifications.ModeAll
+	}
+
+	ns.filterMu.Lock()
+	defer ns.filterMu.Unlock()
+	ns.mode = mode
+	ns.keywords = append([]string(nil), keywords...)
+}
+
+// matchesFilter reports whether an incoming message should notify under the
+// current mode. Our own display name always counts as a mention.
+func (ns *NotificationService) matchesFilter(message string) bool {
+	ns.filterMu.RLock()
+	defer ns.filterMu.RUnlock()
+
+	if ns.mode != notifications.ModeMentions {
+		return true
+	}
+
+	keywords := ns.keywords
+	if ns.app.tox != nil {
+		keywords = append([]string{ns.app.tox.GetName()}, keywords...)
+	}
+	return notifications.NewKeywordFilter(keywords).Matches(message)
+}
+
 // IsEnabled returns whether notifications are enabled
 func (ns *NotificationService) IsEnabled() bool {
 	return ns.enabled && ns.config.Enabled
@@ -195,6 +387,23 @@ func (ns *NotificationService) IsSupported() bool {
 	return ns.manager.IsSupported()
 }
 
+// contactOverride returns the notification settings the user keeps for a friend
+func (ns *NotificationService) contactOverride(friendID uint32) (notifications.ContactOverride, bool) {
+	if ns.app.contacts == nil {
+		return notifications.ContactOverride{}, false
+	}
+	override, ok := ns.app.contacts.GetNotificationOverride(friendID)
+	if !ok {
+		return notifications.ContactOverride{}, false
+	}
+	return notifications.ContactOverride{
+		Muted:              override.Muted,
+		Sound:              override.Sound,
+		AlwaysShowPreview:  override.AlwaysShowPreview,
+		OverrideQuietHours: override.OverrideQuietHours,
+	}, true
+}
+
 // getFriendName gets the display name for a friend ID
 func (ns *NotificationService) getFriendName(friendID uint32) string {
 	if ns.app.contacts == nil {
@@ -205,8 +414,8 @@ func (ns *NotificationService) getFriendName(friendID uint32) string {
 	contacts := ns.app.contacts.GetAllContacts()
 	for _, contact := range contacts {
 		if contact.FriendID == friendID {
-			if contact.Name != "" {
-				return contact.Name
+			if name := contact.DisplayName(); name != "" {
+				return name
 			}
 			return "Friend " + contact.ToxID[:8] // Show first 8 chars of ToxID
 		}
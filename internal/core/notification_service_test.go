@@ -194,6 +194,211 @@ func TestNotificationServiceCallbacks(t *testing.T) {
 	})
 }
 
+// recordingManager is a notifications.Manager that records shown notifications
+type recordingManager struct {
+	shown      []*notifications.Notification
+	suppressed []notifications.SuppressReason
+}
+
+func (m *recordingManager) Show(ctx context.Context, notification *notifications.Notification) error {
+	m.shown = append(m.shown, notification)
+	return nil
+}
+
+func (m *recordingManager) Cancel(ctx context.Context, notificationID string) error { return nil }
+
+func (m *recordingManager) SetConfig(config notifications.NotificationConfig) error { return nil }
+
+func (m *recordingManager) GetConfig() notifications.NotificationConfig {
+	return notifications.NotificationConfig{Enabled: true}
+}
+
+func (m *recordingManager) IsSupported() bool { return true }
+
+func (m *recordingManager) RequestPermission(ctx context.Context) error { return nil }
+
+func (m *recordingManager) SetAppearance(appearance notifications.Appearance) {}
+
+func (m *recordingManager) SetActionHandler(handler notifications.ActionHandler) {}
+
+func (m *recordingManager) SetContactOverrides(lookup notifications.OverrideLookup) {}
+
+func (m *recordingManager) RecordSuppressed(notification *notifications.Notification, reason notifications.SuppressReason) {
+	m.suppressed = append(m.suppressed, reason)
+}
+
+func (m *recordingManager) GetHistory(limit int) []notifications.HistoryEntry { return nil }
+
+func (m *recordingManager) ClearHistory() {}
+
+func (m *recordingManager) Close() error { return nil }
+
+func TestNotificationServiceActiveConversation(t *testing.T) {
+	recorder := &recordingManager{}
+	service := &NotificationService{
+		manager:        recorder,
+		app:            &App{},
+		enabled:        true,
+		suppressActive: true,
+	}
+
+	service.SetActiveConversation(1)
+	service.handleFriendMessage(1, "in the open chat")
+	service.handleFriendMessage(2, "from another chat")
+
+	if len(recorder.shown) != 1 || recorder.shown[0].Body != "from another chat" {
+		t.Fatalf("Expected only the other chat to notify, got %d notifications", len(recorder.shown))
+	}
+
+	// Unfocusing the app re-enables notifications for every chat
+	service.ClearActiveConversation()
+	service.handleFriendMessage(1, "after unfocus")
+	if len(recorder.shown) != 2 {
+		t.Errorf("Expected active chat to notify after clearing, got %d notifications", len(recorder.shown))
+	}
+
+	// Suppression can be turned off entirely
+	service.SetActiveConversation(1)
+	service.SetSuppressActiveConversation(false)
+	service.handleFriendMessage(1, "suppression disabled")
+	if len(recorder.shown) != 3 {
+		t.Errorf("Expected notification with suppression disabled, got %d notifications", len(recorder.shown))
+	}
+}
+
+func TestNotificationServicePausedRecordsHistory(t *testing.T) {
+	recorder := &recordingManager{}
+	service := &NotificationService{
+		manager: recorder,
+		app:     &App{},
+		enabled: true,
+	}
+
+	service.SetPaused(true)
+	service.handleFriendMessage(1, "during the presentation")
+	if len(recorder.shown) != 0 {
+		t.Errorf("Expected no notification while paused, got %d", len(recorder.shown))
+	}
+	if len(recorder.suppressed) != 1 || recorder.suppressed[0] != notifications.SuppressedPaused {
+		t.Errorf("Expected the message recorded as paused, got %v", recorder.suppressed)
+	}
+
+	service.SetPaused(false)
+	service.handleFriendMessage(1, "after the presentation")
+	if len(recorder.shown) != 1 {
+		t.Errorf("Expected notification after unpausing, got %d", len(recorder.shown))
+	}
+}
+
+func TestNotificationServiceMentionsOnly(t *testing.T) {
+	recorder := &recordingManager{}
+	service := &NotificationService{
+		manager: recorder,
+		app:     &App{},
+		enabled: true,
+	}
+	service.SetMessageFilter(notifications.ModeMentions, []string{"deploy", "Sam"})
+
+	tests := []struct {
+		message string
+		notify  bool
+	}{
+		{"lunch anyone?", false},
+		{"sam, can you look at this?", true},
+		{"ready to DEPLOY", true},
+		{"the deployment finished", false},
+		{"Samantha joined", false},
+	}
+
+	for _, tt := range tests {
+		before := len(recorder.shown)
+		service.handleFriendMessage(1, tt.message)
+		if notified := len(recorder.shown) > before; notified != tt.notify {
+			t.Errorf("Message %q: notified = %v, want %v", tt.message, notified, tt.notify)
+		}
+	}
+
+	// All mode notifies regardless of keywords
+	service.SetMessageFilter(notifications.ModeAll, []string{"deploy"})
+	before := len(recorder.shown)
+	service.handleFriendMessage(1, "lunch anyone?")
+	if len(recorder.shown) != before+1 {
+		t.Error("Expected every message to notify in all mode")
+	}
+}
+
+// recordingActions records the core calls made by notification actions
+type recordingActions struct {
+	replies  map[uint32][]string
+	markRead []uint32
+}
+
+func (r *recordingActions) SendMessageFromUI(friendID uint32, content string) error {
+	if r.replies == nil {
+		r.replies = make(map[uint32][]string)
+	}
+	r.replies[friendID] = append(r.replies[friendID], content)
+	return nil
+}
+
+func (r *recordingActions) MarkAsRead(friendID uint32) error {
+	r.markRead = append(r.markRead, friendID)
+	return nil
+}
+
+func TestNotificationActions(t *testing.T) {
+	recorder := &recordingManager{}
+	actions := &recordingActions{}
+	service := &NotificationService{
+		manager:     recorder,
+		app:         &App{},
+		enabled:     true,
+		showActions: true,
+		actions:     actions,
+	}
+
+	service.handleFriendMessage(7, "are you there?")
+	if len(recorder.shown) != 1 {
+		t.Fatalf("Expected one notification, got %d", len(recorder.shown))
+	}
+	notification := recorder.shown[0]
+	if len(notification.Actions) != 2 {
+		t.Fatalf("Expected reply and mark-read actions, got %+v", notification.Actions)
+	}
+
+	service.handleAction(notification, notifications.ActionMarkRead, "")
+	if len(actions.markRead) != 1 || actions.markRead[0] != 7 {
+		t.Errorf("Expected MarkAsRead for friend 7, got %v", actions.markRead)
+	}
+	if len(actions.replies) != 0 {
+		t.Errorf("Mark as read should not send anything, got %v", actions.replies)
+	}
+
+	service.handleAction(notification, notifications.ActionReply, "on my way")
+	if got := actions.replies[7]; len(got) != 1 || got[0] != "on my way" {
+		t.Errorf("Expected reply routed to friend 7, got %v", actions.replies)
+	}
+
+	// An empty reply sends nothing
+	service.handleAction(notification, notifications.ActionReply, "")
+	if got := actions.replies[7]; len(got) != 1 {
+		t.Errorf("Expected empty reply to be ignored, got %v", got)
+	}
+
+	// Notifications without a conversation are ignored
+	service.handleAction(notifications.NewNotification(notifications.NotificationStatus, "Status", "online"), notifications.ActionMarkRead, "")
+	if len(actions.markRead) != 2 {
+		t.Errorf("Expected only the reply to mark read again, got %v", actions.markRead)
+	}
+
+	// Actions can be turned off
+	service.showActions = false
+	service.handleFriendMessage(7, "hello?")
+	if len(recorder.shown[1].Actions) != 0 {
+		t.Errorf("Expected no actions when disabled, got %+v", recorder.shown[1].Actions)
+	}
+}
+
 // Benchmark the notification system
 func BenchmarkNotificationService(b *testing.B) {
 	config := &Config{
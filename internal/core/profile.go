@@ -0,0 +1,74 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// profileMagic starts a password-protected profile export; plain exports are
+// raw Tox savedata so other Tox clients can read them
+var profileMagic = []byte("WHISPTOX1\n")
+
+// profileContext is the encryption context of protected profile exports
+const profileContext = "tox_profile"
+
+// ErrProfilePasswordRequired is returned when importing a password-protected
+// profile without a password
+var ErrProfilePasswordRequired = errors.New("profile is password protected")
+
+// GetProfile returns our name and status message
+func (a *App) GetProfile() (name, statusMessage string) {
+	return a.tox.GetName(), a.tox.GetStatusMessage()
+}
+
+// UpdateProfile sets our name and status message and saves them. Leading
+// and trailing spaces are dropped; an empty name is allowed.
+func (a *App) UpdateProfile(name, statusMessage string) error {
+	return a.tox.UpdateProfile(strings.TrimSpace(name), strings.TrimSpace(statusMessage))
+}
+
+// ExportProfile writes the Tox identity to a .tox file. With a password the
+// file is encrypted and can only be imported by Whisp.
+func (a *App) ExportProfile(path, password string) error {
+	if password == "" {
+		return a.tox.ExportProfile(path)
+	}
+
+	savedata, err := a.tox.ProfileData()
+	if err != nil {
+		return err
+	}
+	encrypted, err := a.security.EncryptWithPassword(savedata, password, profileContext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt profile: %w", err)
+	}
+	if err := os.WriteFile(path, append(append([]byte{}, profileMagic...), encrypted...), 0o600); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return nil
+}
+
+// ImportProfile replaces the Tox identity with the one in a .tox file. The
+// password is only used for profiles exported with one. Friends and messages
+// stored for the previous identity are kept.
+func (a *App) ImportProfile(path, password string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	if bytes.HasPrefix(data, profileMagic) {
+		if password == "" {
+			return ErrProfilePasswordRequired
+		}
+		data, err = a.security.DecryptWithPassword(data[len(profileMagic):], password, profileContext)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt profile, is the password correct?: %w", err)
+		}
+	}
+
+	return a.tox.LoadProfile(data)
+}
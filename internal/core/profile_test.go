@@ -0,0 +1,66 @@
+package core
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/whisp/ui/adaptive"
+)
+
+func TestProfileExportImport(t *testing.T) {
+	newApp := func() *App {
+		tempDir := t.TempDir()
+		app, err := NewApp(&Config{
+			DataDir:    tempDir,
+			ConfigPath: filepath.Join(tempDir, "config.yaml"),
+			Platform:   adaptive.PlatformLinux,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create app: %v", err)
+		}
+		t.Cleanup(app.Cleanup)
+		return app
+	}
+
+	tests := []struct {
+		name           string
+		exportPassword string
+		importPassword string
+		wantErr        error
+		wantImported   bool
+	}{
+		{"plain", "", "", nil, true},
+		{"plain ignores password", "", "secret", nil, true},
+		{"protected", "secret", "secret", nil, true},
+		{"protected without password", "secret", "", ErrProfilePasswordRequired, false},
+		{"protected with wrong password", "secret", "wrong", errors.New("any"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, target := newApp(), newApp()
+			path := filepath.Join(t.TempDir(), "me.tox")
+			if err := source.ExportProfile(path, tt.exportPassword); err != nil {
+				t.Fatalf("ExportProfile failed: %v", err)
+			}
+
+			oldID := target.GetToxID()
+			err := target.ImportProfile(path, tt.importPassword)
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if errors.Is(tt.wantErr, ErrProfilePasswordRequired) && !errors.Is(err, ErrProfilePasswordRequired) {
+				t.Errorf("Expected ErrProfilePasswordRequired, got %v", err)
+			}
+
+			wantID := oldID
+			if tt.wantImported {
+				wantID = source.GetToxID()
+			}
+			if got := target.GetToxID(); got != wantID {
+				t.Errorf("Expected Tox ID %s, got %s", wantID, got)
+			}
+		})
+	}
+}
@@ -205,6 +205,54 @@ func (m *Manager) DecryptData(encryptedData []byte, context string) ([]byte, err
 	return plaintext, nil
 }
 
+// EncryptWithPassword encrypts data for use outside this device. The key is
+// derived from the password with scrypt and a random salt, which is
+// prepended to the output of EncryptData.
+func (m *Manager) EncryptWithPassword(data []byte, password, context string) ([]byte, error) {
+	salt, err := m.GenerateSalt()
+	if err != nil {
+		return nil, err
+	}
+	sealer, err := m.passwordManager(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer sealer.Cleanup()
+
+	ciphertext, err := sealer.EncryptData(data, context)
+	if err != nil {
+		return nil, err
+	}
+	return append(salt, ciphertext...), nil
+}
+
+// DecryptWithPassword decrypts data produced by EncryptWithPassword
+func (m *Manager) DecryptWithPassword(encryptedData []byte, password, context string) ([]byte, error) {
+	const saltSize = 32
+	if len(encryptedData) < saltSize {
+		return nil, fmt.Errorf("encrypted data too short")
+	}
+	sealer, err := m.passwordManager(password, encryptedData[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	defer sealer.Cleanup()
+
+	return sealer.DecryptData(encryptedData[saltSize:], context)
+}
+
+// passwordManager returns a manager unlocked with a key derived from password
+func (m *Manager) passwordManager(password string, salt []byte) (*Manager, error) {
+	key, err := m.DeriveKey([]byte(password), salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	sealer := &Manager{}
+	sealer.SetMasterKey(key)
+	clearBytes(key)
+	return sealer, nil
+}
+
 // GetDatabaseKey derives a database encryption key in hex format
 func (m *Manager) GetDatabaseKey() (string, error) {
 	key, err := m.DeriveContextKey("database")
@@ -200,6 +200,39 @@ func TestEncryptDecryptData(t *testing.T) {
 	}
 }
 
+func TestEncryptDecryptWithPassword(t *testing.T) {
+	manager, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create security manager: %v", err)
+	}
+
+	// Works without a master key, so exports can be opened on another device
+	testData := []byte("portable secret")
+	encryptedData, err := manager.EncryptWithPassword(testData, "hunter2", "profile")
+	if err != nil {
+		t.Fatalf("Failed to encrypt data: %v", err)
+	}
+	if bytes.Contains(encryptedData, testData) {
+		t.Error("Encrypted data should not contain the plaintext")
+	}
+
+	other, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create security manager: %v", err)
+	}
+	decryptedData, err := other.DecryptWithPassword(encryptedData, "hunter2", "profile")
+	if err != nil || !bytes.Equal(decryptedData, testData) {
+		t.Fatalf("Expected %q, got %q (%v)", testData, decryptedData, err)
+	}
+
+	if _, err := other.DecryptWithPassword(encryptedData, "wrong", "profile"); err == nil {
+		t.Error("Expected error when decrypting with the wrong password")
+	}
+	if _, err := other.DecryptWithPassword(encryptedData[:10], "hunter2", "profile"); err == nil {
+		t.Error("Expected error when decrypting truncated data")
+	}
+}
+
 func TestGetDatabaseKey(t *testing.T) {
 	tempDir := t.TempDir()
 	manager, err := NewManager(tempDir)
@@ -0,0 +1,128 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// wrappedKeyContext is the encryption context of the password-wrapped master key
+const wrappedKeyContext = "master_key_wrap"
+
+// ErrWrongPassword is returned when a password does not unwrap the master key
+var ErrWrongPassword = errors.New("wrong password")
+
+// wrappedKeyPath returns the file holding the password-wrapped master key
+func (m *Manager) wrappedKeyPath() string {
+	return filepath.Join(m.dataDir, "security", "master.key")
+}
+
+// HasPassword reports whether a password protecting the master key was set up
+func (m *Manager) HasPassword() bool {
+	_, err := os.Stat(m.wrappedKeyPath())
+	return err == nil
+}
+
+// SetupPassword generates a new master key, stores it wrapped under the
+// password and unlocks the manager with it
+func (m *Manager) SetupPassword(password string) error {
+	if password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+	if m.HasPassword() {
+		return fmt.Errorf("a password is already set")
+	}
+
+	masterKey, err := m.GenerateMasterKey()
+	if err != nil {
+		return err
+	}
+	defer clearBytes(masterKey)
+
+	if err := m.storeWrappedKey(masterKey, password); err != nil {
+		return err
+	}
+	m.SetMasterKey(masterKey)
+	return nil
+}
+
+// Unlock unwraps the master key with the password and unlocks the manager.
+// It returns ErrWrongPassword if the password is not the one set up.
+func (m *Manager) Unlock(password string) error {
+	masterKey, err := m.unwrapKey(password)
+	if err != nil {
+		return err
+	}
+	defer clearBytes(masterKey)
+
+	m.SetMasterKey(masterKey)
+	return nil
+}
+
+// ChangePassword re-wraps the master key under a new password. The master
+// key itself is unchanged, so encrypted data stays readable.
+func (m *Manager) ChangePassword(oldPassword, newPassword string) error {
+	if newPassword == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+
+	masterKey, err := m.unwrapKey(oldPassword)
+	if err != nil {
+		return err
+	}
+	defer clearBytes(masterKey)
+
+	return m.storeWrappedKey(masterKey, newPassword)
+}
+
+// unwrapKey reads the wrapped master key and decrypts it with the password
+func (m *Manager) unwrapKey(password string) ([]byte, error) {
+	wrapped, err := os.ReadFile(m.wrappedKeyPath())
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no password has been set up")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wrapped master key: %w", err)
+	}
+
+	masterKey, err := m.DecryptWithPassword(wrapped, password, wrappedKeyContext)
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+	if len(masterKey) != 32 {
+		clearBytes(masterKey)
+		return nil, fmt.Errorf("wrapped master key is corrupt")
+	}
+	return masterKey, nil
+}
+
+// storeWrappedKey writes the master key wrapped under the password, replacing
+// any earlier one atomically
+func (m *Manager) storeWrappedKey(masterKey []byte, password string) error {
+	wrapped, err := m.EncryptWithPassword(masterKey, password, wrappedKeyContext)
+	if err != nil {
+		return fmt.Errorf("failed to wrap master key: %w", err)
+	}
+
+	path := m.wrappedKeyPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create security directory: %w", err)
+	}
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, wrapped, 0o600); err != nil {
+		return fmt.Errorf("failed to write wrapped master key: %w", err)
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to store wrapped master key: %w", err)
+	}
+	return nil
+}
+
+// clearBytes overwrites key material
+func clearBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
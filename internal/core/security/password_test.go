@@ -0,0 +1,67 @@
+package security
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPasswordSetupUnlockChange(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create security manager: %v", err)
+	}
+
+	if manager.HasPassword() {
+		t.Fatal("Expected no password before setup")
+	}
+	if err := manager.SetupPassword(""); err == nil {
+		t.Error("Expected an empty password to be refused")
+	}
+	if err := manager.SetupPassword("first"); err != nil {
+		t.Fatalf("SetupPassword failed: %v", err)
+	}
+	if !manager.HasPassword() {
+		t.Fatal("Expected a password after setup")
+	}
+	if err := manager.SetupPassword("again"); err == nil {
+		t.Error("Expected a second setup to be refused")
+	}
+	masterKey := manager.GetMasterKey()
+	if len(masterKey) != 32 {
+		t.Fatalf("Expected setup to unlock with a 32 byte key, got %d bytes", len(masterKey))
+	}
+
+	// A fresh manager, as on the next start, unlocks with the same key
+	restarted, err := NewManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create security manager: %v", err)
+	}
+	if err := restarted.Unlock("wrong"); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("Expected ErrWrongPassword, got %v", err)
+	}
+	if restarted.GetMasterKey() != nil {
+		t.Fatal("Expected a wrong password to leave the manager locked")
+	}
+	if err := restarted.Unlock("first"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if !bytes.Equal(restarted.GetMasterKey(), masterKey) {
+		t.Fatal("Expected the unlocked master key to match")
+	}
+
+	// Changing the password keeps the master key
+	if err := restarted.ChangePassword("wrong", "second"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("Expected ErrWrongPassword, got %v", err)
+	}
+	if err := restarted.ChangePassword("first", "second"); err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+	if err := restarted.Unlock("first"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("Expected the old password to stop working, got %v", err)
+	}
+	if err := restarted.Unlock("second"); err != nil || !bytes.Equal(restarted.GetMasterKey(), masterKey) {
+		t.Errorf("Expected the new password to unlock the same key (%v)", err)
+	}
+}
@@ -0,0 +1,115 @@
+package tox
+
+import (
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+	"github.com/opd-ai/whisp/internal/core/idle"
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// selfPresence is the status control AutoAway drives; *Manager implements it
+type selfPresence interface {
+	GetSelfStatus() toxcore.FriendStatus
+	SetSelfStatus(status toxcore.FriendStatus)
+}
+
+// AutoAway switches the self status to away after a period of inactivity and
+// back to online on activity. It only changes a status it set itself, so a
+// manually chosen busy or away status is left alone.
+type AutoAway struct {
+	mu        sync.Mutex
+	presence  selfPresence
+	tracker   *idle.Tracker
+	threshold time.Duration
+	enabled   bool
+	setAway   bool // We switched the status to away
+	onChange  func(away bool)
+}
+
+// NewAutoAway creates an auto-away controller that goes away after threshold of inactivity
+func NewAutoAway(presence selfPresence, tracker *idle.Tracker, threshold time.Duration) *AutoAway {
+	return &AutoAway{
+		presence:  presence,
+		tracker:   tracker,
+		threshold: threshold,
+		enabled:   true,
+	}
+}
+
+// SetEnabled turns auto-away on or off. Turning it off restores online if
+// auto-away had set the status to away.
+func (a *AutoAway) SetEnabled(enabled bool) {
+	a.mu.Lock()
+	a.enabled = enabled
+	changed := !enabled && a.restoreLocked()
+	callback := a.onChange
+	a.mu.Unlock()
+
+	if changed && callback != nil {
+		callback(false)
+	}
+}
+
+// SetOnChange sets the callback invoked when auto-away sets or clears the
+// away status
+func (a *AutoAway) SetOnChange(callback func(away bool)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onChange = callback
+}
+
+// Check updates the status for the inactivity at now. It is cheap enough to
+// call from the main loop.
+func (a *AutoAway) Check(now time.Time) {
+	a.mu.Lock()
+	if !a.enabled || a.threshold <= 0 {
+		a.mu.Unlock()
+		return
+	}
+
+	changed := false
+	idleFor := a.tracker.IdleFor(now)
+	switch {
+	case !a.setAway && idleFor >= a.threshold:
+		// Only an online status is replaced; busy, away and appear-offline are user choices
+		if a.presence.GetSelfStatus() == toxcore.FriendStatusOnline {
+			a.presence.SetSelfStatus(toxcore.FriendStatusAway)
+			a.setAway = true
+			changed = true
+			logging.Infof("Auto-away after %v idle", idleFor.Round(time.Second))
+		}
+	case a.setAway && idleFor < a.threshold:
+		changed = a.restoreLocked()
+	}
+	away := a.setAway
+	callback := a.onChange
+	a.mu.Unlock()
+
+	if changed && callback != nil {
+		callback(away)
+	}
+}
+
+// IsAutoAway reports whether the current away status was set by auto-away
+func (a *AutoAway) IsAutoAway() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.setAway
+}
+
+// restoreLocked returns to online if the status is still the away we set,
+// reporting whether auto-away was active. The caller must hold a.mu.
+func (a *AutoAway) restoreLocked() bool {
+	if !a.setAway {
+		return false
+	}
+	a.setAway = false
+
+	// The user may have picked another status while we were away
+	if a.presence.GetSelfStatus() == toxcore.FriendStatusAway {
+		a.presence.SetSelfStatus(toxcore.FriendStatusOnline)
+	}
+	return true
+}
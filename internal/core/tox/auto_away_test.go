@@ -0,0 +1,156 @@
+package tox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+	"github.com/opd-ai/whisp/internal/core/idle"
+)
+
+// fakePresence records the self status without a Tox instance
+type fakePresence struct {
+	status toxcore.FriendStatus
+}
+
+func (p *fakePresence) GetSelfStatus() toxcore.FriendStatus { return p.status }
+
+func (p *fakePresence) SetSelfStatus(status toxcore.FriendStatus) { p.status = status }
+
+// autoAwayStep advances the clock, optionally with activity, and checks the status after
+type autoAwayStep struct {
+	elapsed  time.Duration
+	activity bool
+	want     toxcore.FriendStatus
+}
+
+func TestAutoAway(t *testing.T) {
+	const threshold = 10 * time.Minute
+
+	tests := []struct {
+		name    string
+		initial toxcore.FriendStatus
+		steps   []autoAwayStep
+	}{
+		{
+			name:    "idle goes away and activity returns online",
+			initial: toxcore.FriendStatusOnline,
+			steps: []autoAwayStep{
+				{5 * time.Minute, false, toxcore.FriendStatusOnline},
+				{11 * time.Minute, false, toxcore.FriendStatusAway},
+				{12 * time.Minute, true, toxcore.FriendStatusOnline},
+				{21 * time.Minute, false, toxcore.FriendStatusOnline},
+				{23 * time.Minute, false, toxcore.FriendStatusAway},
+			},
+		},
+		{
+			name:    "manual busy is never overridden",
+			initial: toxcore.FriendStatusBusy,
+			steps: []autoAwayStep{
+				{11 * time.Minute, false, toxcore.FriendStatusBusy},
+				{12 * time.Minute, true, toxcore.FriendStatusBusy},
+			},
+		},
+		{
+			name:    "manual away stays after activity",
+			initial: toxcore.FriendStatusAway,
+			steps: []autoAwayStep{
+				{11 * time.Minute, false, toxcore.FriendStatusAway},
+				{12 * time.Minute, true, toxcore.FriendStatusAway},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			presence := &fakePresence{status: tt.initial}
+			tracker := idle.NewTracker()
+			start := tracker.LastActivity()
+			autoAway := NewAutoAway(presence, tracker, threshold)
+
+			for i, step := range tt.steps {
+				now := start.Add(step.elapsed)
+				if step.activity {
+					tracker.TouchAt(now)
+				}
+				autoAway.Check(now)
+				if presence.status != step.want {
+					t.Fatalf("Step %d (%v): expected status %v, got %v", i, step.elapsed, step.want, presence.status)
+				}
+			}
+		})
+	}
+}
+
+func TestAutoAwayKeepsStatusChangedWhileAway(t *testing.T) {
+	presence := &fakePresence{status: toxcore.FriendStatusOnline}
+	tracker := idle.NewTracker()
+	start := tracker.LastActivity()
+	autoAway := NewAutoAway(presence, tracker, time.Minute)
+
+	autoAway.Check(start.Add(2 * time.Minute))
+	if !autoAway.IsAutoAway() {
+		t.Fatal("Expected auto-away to engage")
+	}
+
+	// The user sets busy while auto-away is active; activity must not reset it to online
+	presence.SetSelfStatus(toxcore.FriendStatusBusy)
+	tracker.TouchAt(start.Add(3 * time.Minute))
+	autoAway.Check(start.Add(3 * time.Minute))
+	if presence.status != toxcore.FriendStatusBusy {
+		t.Errorf("Expected busy to be kept, got %v", presence.status)
+	}
+	if autoAway.IsAutoAway() {
+		t.Error("Expected auto-away to be cleared after activity")
+	}
+}
+
+func TestAutoAwayDisable(t *testing.T) {
+	presence := &fakePresence{status: toxcore.FriendStatusOnline}
+	tracker := idle.NewTracker()
+	start := tracker.LastActivity()
+	autoAway := NewAutoAway(presence, tracker, time.Minute)
+
+	autoAway.Check(start.Add(2 * time.Minute))
+	if presence.status != toxcore.FriendStatusAway {
+		t.Fatalf("Expected away, got %v", presence.status)
+	}
+
+	// Disabling restores online and stops further changes
+	autoAway.SetEnabled(false)
+	if presence.status != toxcore.FriendStatusOnline {
+		t.Errorf("Expected online after disabling, got %v", presence.status)
+	}
+	autoAway.Check(start.Add(10 * time.Minute))
+	if presence.status != toxcore.FriendStatusOnline {
+		t.Errorf("Expected disabled auto-away to leave status alone, got %v", presence.status)
+	}
+}
+
+func TestAutoAwayReportsChanges(t *testing.T) {
+	presence := &fakePresence{status: toxcore.FriendStatusOnline}
+	tracker := idle.NewTracker()
+	start := tracker.LastActivity()
+	autoAway := NewAutoAway(presence, tracker, time.Minute)
+
+	var changes []bool
+	autoAway.SetOnChange(func(away bool) { changes = append(changes, away) })
+
+	autoAway.Check(start.Add(30 * time.Second))
+	autoAway.Check(start.Add(2 * time.Minute))
+	autoAway.Check(start.Add(3 * time.Minute))
+	tracker.TouchAt(start.Add(4 * time.Minute))
+	autoAway.Check(start.Add(4 * time.Minute))
+	autoAway.Check(start.Add(6 * time.Minute))
+	autoAway.SetEnabled(false)
+
+	want := []bool{true, false, true, false}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected changes %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Change %d: expected away=%v, got %v", i, want[i], changes[i])
+		}
+	}
+}
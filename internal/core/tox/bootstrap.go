@@ -0,0 +1,194 @@
+package tox
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// BootstrapNode is a well-known node used to join the Tox network
+type BootstrapNode struct {
+	Address   string
+	Port      uint16
+	PublicKey string
+}
+
+// DefaultBootstrapNodesFile is the node list read from the data directory
+// unless another file is configured
+const DefaultBootstrapNodesFile = "bootstrap_nodes.json"
+
+// DefaultBootstrapNodes are used when no nodes are configured
+var DefaultBootstrapNodes = []BootstrapNode{
+	{"node.tox.biribiri.org", 33445, "F404ABAA1C99A9D37D61AB54898F56793E1DEF8BD46B1038B9D822E8460FAB67"},
+	{"tox.initramfs.io", 33445, "3F0A45A268367C1BEA652F258C85F4A66DA76BCAA667A49E770BCC4917AB6A25"},
+	{"tox2.abilinski.com", 33445, "7A6098B590BDC73F9723FC59F82B3F9085A64D1B213AAF8E610FD351930D052D"},
+}
+
+// Validate checks that a node has an address, a port and a 32-byte public key
+func (n BootstrapNode) Validate() error {
+	if n.Address == "" {
+		return fmt.Errorf("bootstrap node has no address")
+	}
+	if n.Port == 0 {
+		return fmt.Errorf("bootstrap node %s has no port", n.Address)
+	}
+	if key, err := hex.DecodeString(n.PublicKey); err != nil || len(key) != 32 {
+		return fmt.Errorf("bootstrap node %s has an invalid public key", n.Address)
+	}
+	return nil
+}
+
+// nodesFile is the JSON node list published at nodes.tox.chat, where nodes
+// we run ourselves may give an address instead of ipv4/ipv6
+type nodesFile struct {
+	Nodes []nodeEntry `json:"nodes"`
+}
+
+// nodeEntry is one node in a nodesFile
+type nodeEntry struct {
+	Address   string `json:"address,omitempty"`
+	IPv4      string `json:"ipv4,omitempty"`
+	IPv6      string `json:"ipv6,omitempty"`
+	Port      int    `json:"port"`
+	PublicKey string `json:"public_key"`
+}
+
+// LoadBootstrapNodes reads a JSON node list. Invalid nodes are skipped, but
+// a file without any valid node is an error.
+func LoadBootstrapNodes(path string) ([]BootstrapNode, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// Wrapped so a missing file can be told apart with os.ErrNotExist
+		return nil, fmt.Errorf("failed to read bootstrap nodes: %w", err)
+	}
+
+	var file nodesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse bootstrap nodes: %w", err)
+	}
+
+	var nodes []BootstrapNode
+	for _, entry := range file.Nodes {
+		// The published list marks a missing address family with "-"
+		address := entry.Address
+		for _, ip := range []string{entry.IPv4, entry.IPv6} {
+			if address == "" && ip != "-" {
+				address = ip
+			}
+		}
+		if entry.Port < 0 || entry.Port > 65535 {
+			logging.Warnf("Skipping bootstrap node %s with invalid port %d", address, entry.Port)
+			continue
+		}
+
+		node := BootstrapNode{Address: address, Port: uint16(entry.Port), PublicKey: entry.PublicKey}
+		if err := node.Validate(); err != nil {
+			logging.Warnf("Skipping %v", err)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no valid bootstrap nodes in %s", path)
+	}
+	return nodes, nil
+}
+
+// SaveBootstrapNodes writes nodes to a JSON node list LoadBootstrapNodes reads
+func SaveBootstrapNodes(path string, nodes []BootstrapNode) error {
+	file := nodesFile{Nodes: make([]nodeEntry, len(nodes))}
+	for i, node := range nodes {
+		if err := node.Validate(); err != nil {
+			return err
+		}
+		file.Nodes[i] = nodeEntry{Address: node.Address, Port: int(node.Port), PublicKey: node.PublicKey}
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bootstrap nodes: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save bootstrap nodes: %w", err)
+	}
+	return nil
+}
+
+// SetBootstrapNodes sets the nodes later bootstraps use; nil restores the
+// defaults
+func (m *Manager) SetBootstrapNodes(nodes []BootstrapNode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bootstrapNodes = nodes
+}
+
+// AddBootstrapNode adds a node to those later bootstraps use, replacing any
+// node at the same address and port. Added to the defaults if none are set.
+func (m *Manager) AddBootstrapNode(node BootstrapNode) error {
+	if err := node.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nodes := m.listBootstrapNodesLocked()
+	for i, existing := range nodes {
+		if existing.Address == node.Address && existing.Port == node.Port {
+			nodes[i] = node
+			m.bootstrapNodes = nodes
+			return nil
+		}
+	}
+	m.bootstrapNodes = append(nodes, node)
+	return nil
+}
+
+// ListBootstrapNodes returns the nodes bootstraps use
+func (m *Manager) ListBootstrapNodes() []BootstrapNode {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.listBootstrapNodesLocked()
+}
+
+// listBootstrapNodesLocked returns a copy of the nodes in use. The caller
+// must hold m.mu.
+func (m *Manager) listBootstrapNodesLocked() []BootstrapNode {
+	if len(m.bootstrapNodes) == 0 {
+		return append([]BootstrapNode(nil), DefaultBootstrapNodes...)
+	}
+	return append([]BootstrapNode(nil), m.bootstrapNodes...)
+}
+
+// Bootstrap contacts the bootstrap nodes until one accepts, to join or
+// rejoin the network
+func (m *Manager) Bootstrap() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.tox == nil {
+		return fmt.Errorf("Tox not initialized")
+	}
+	return m.bootstrap()
+}
+
+// bootstrap connects to the Tox network. The caller must hold m.mu or own
+// the manager exclusively.
+func (m *Manager) bootstrap() error {
+	var lastErr error
+	for _, node := range m.listBootstrapNodesLocked() {
+		err := m.tox.Bootstrap(node.Address, node.Port, node.PublicKey)
+		if err != nil {
+			lastErr = err
+			logging.Errorf("Failed to bootstrap to %s: %v", node.Address, err)
+		} else {
+			logging.Infof("Successfully bootstrapped to %s", node.Address)
+			return nil
+		}
+	}
+
+	return lastErr
+}
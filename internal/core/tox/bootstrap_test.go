@@ -0,0 +1,147 @@
+package tox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadBootstrapNodes(t *testing.T) {
+	key := "F404ABAA1C99A9D37D61AB54898F56793E1DEF8BD46B1038B9D822E8460FAB67"
+
+	tests := []struct {
+		name    string
+		content string
+		want    []BootstrapNode
+		wantErr bool
+	}{
+		{
+			name:    "published list",
+			content: `{"last_scan": 1, "nodes": [{"ipv4": "1.2.3.4", "ipv6": "-", "port": 33445, "public_key": "` + key + `"}]}`,
+			want:    []BootstrapNode{{"1.2.3.4", 33445, key}},
+		},
+		{
+			name:    "own node by address",
+			content: `{"nodes": [{"address": "tox.example.org", "ipv4": "1.2.3.4", "port": 443, "public_key": "` + key + `"}]}`,
+			want:    []BootstrapNode{{"tox.example.org", 443, key}},
+		},
+		{
+			name:    "ipv6 only",
+			content: `{"nodes": [{"ipv4": "-", "ipv6": "2001:db8::1", "port": 33445, "public_key": "` + key + `"}]}`,
+			want:    []BootstrapNode{{"2001:db8::1", 33445, key}},
+		},
+		{
+			name: "invalid nodes skipped",
+			content: `{"nodes": [
+				{"address": "bad-key.example.org", "port": 33445, "public_key": "ABCD"},
+				{"address": "bad-port.example.org", "port": 70000, "public_key": "` + key + `"},
+				{"address": "good.example.org", "port": 33445, "public_key": "` + key + `"}
+			]}`,
+			want: []BootstrapNode{{"good.example.org", 33445, key}},
+		},
+		{
+			name:    "no valid nodes",
+			content: `{"nodes": [{"address": "bad-key.example.org", "port": 33445, "public_key": "ABCD"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "not JSON",
+			content: `nodes`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nodes.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("Failed to write nodes file: %v", err)
+			}
+
+			nodes, err := LoadBootstrapNodes(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadBootstrapNodes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(nodes) != len(tt.want) {
+				t.Fatalf("Expected %d nodes, got %v", len(tt.want), nodes)
+			}
+			for i := range tt.want {
+				if nodes[i] != tt.want[i] {
+					t.Errorf("Node %d: expected %+v, got %+v", i, tt.want[i], nodes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadBootstrapNodes_Missing(t *testing.T) {
+	_, err := LoadBootstrapNodes(filepath.Join(t.TempDir(), DefaultBootstrapNodesFile))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+}
+
+func TestSaveBootstrapNodes(t *testing.T) {
+	key := "F404ABAA1C99A9D37D61AB54898F56793E1DEF8BD46B1038B9D822E8460FAB67"
+	path := filepath.Join(t.TempDir(), DefaultBootstrapNodesFile)
+
+	nodes := []BootstrapNode{{"tox.example.org", 443, key}, {"1.2.3.4", 33445, key}}
+	if err := SaveBootstrapNodes(path, nodes); err != nil {
+		t.Fatalf("SaveBootstrapNodes failed: %v", err)
+	}
+	loaded, err := LoadBootstrapNodes(path)
+	if err != nil {
+		t.Fatalf("LoadBootstrapNodes failed: %v", err)
+	}
+	if len(loaded) != len(nodes) || loaded[0] != nodes[0] || loaded[1] != nodes[1] {
+		t.Errorf("Expected %v, got %v", nodes, loaded)
+	}
+
+	if err := SaveBootstrapNodes(path, []BootstrapNode{{"tox.example.org", 443, "ABCD"}}); err == nil {
+		t.Error("Expected an invalid node to be refused")
+	}
+}
+
+func TestManager_BootstrapNodes(t *testing.T) {
+	key := "F404ABAA1C99A9D37D61AB54898F56793E1DEF8BD46B1038B9D822E8460FAB67"
+	manager := &Manager{}
+
+	// Without nodes of its own the manager falls back to the defaults
+	if got := manager.ListBootstrapNodes(); len(got) != len(DefaultBootstrapNodes) {
+		t.Fatalf("Expected the default nodes, got %v", got)
+	}
+
+	node := BootstrapNode{"tox.example.org", 443, key}
+	if err := manager.AddBootstrapNode(node); err != nil {
+		t.Fatalf("AddBootstrapNode failed: %v", err)
+	}
+	nodes := manager.ListBootstrapNodes()
+	if len(nodes) != len(DefaultBootstrapNodes)+1 || nodes[len(nodes)-1] != node {
+		t.Errorf("Expected the node added to the defaults, got %v", nodes)
+	}
+
+	// A node at the same address and port is replaced
+	replaced := BootstrapNode{"tox.example.org", 443, DefaultBootstrapNodes[0].PublicKey}
+	if err := manager.AddBootstrapNode(replaced); err != nil {
+		t.Fatalf("AddBootstrapNode failed: %v", err)
+	}
+	nodes = manager.ListBootstrapNodes()
+	if len(nodes) != len(DefaultBootstrapNodes)+1 || nodes[len(nodes)-1] != replaced {
+		t.Errorf("Expected the node replaced, got %v", nodes)
+	}
+
+	if err := manager.AddBootstrapNode(BootstrapNode{"tox.example.org", 0, key}); err == nil {
+		t.Error("Expected a node without a port to be refused")
+	}
+
+	// The defaults themselves are never changed
+	if DefaultBootstrapNodes[0].Address == "tox.example.org" || len(DefaultBootstrapNodes) == len(nodes) {
+		t.Error("Expected the default nodes untouched")
+	}
+
+	manager.SetBootstrapNodes(nil)
+	if got := manager.ListBootstrapNodes(); len(got) != len(DefaultBootstrapNodes) {
+		t.Errorf("Expected the defaults after clearing, got %v", got)
+	}
+}
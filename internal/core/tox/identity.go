@@ -0,0 +1,49 @@
+package tox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Length limits Tox puts on our profile, in bytes
+const (
+	MaxNameLength          = 128
+	MaxStatusMessageLength = 1007
+)
+
+// ValidateProfile checks a name and status message fit the Tox limits. An
+// empty name is allowed; friends then see us by our public key.
+func ValidateProfile(name, statusMessage string) error {
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("name is %d bytes, the limit is %d", len(name), MaxNameLength)
+	}
+	if strings.ContainsAny(name, "\r\n") {
+		return fmt.Errorf("name cannot span several lines")
+	}
+	if len(statusMessage) > MaxStatusMessageLength {
+		return fmt.Errorf("status message is %d bytes, the limit is %d", len(statusMessage), MaxStatusMessageLength)
+	}
+	return nil
+}
+
+// UpdateProfile sets our name and status message, tells friends online and
+// saves the Tox state so the change survives a restart
+func (m *Manager) UpdateProfile(name, statusMessage string) error {
+	if err := ValidateProfile(name, statusMessage); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.tox == nil {
+		return fmt.Errorf("Tox not initialized")
+	}
+	if err := m.tox.SelfSetName(name); err != nil {
+		return fmt.Errorf("failed to set name: %w", err)
+	}
+	if err := m.tox.SelfSetStatusMessage(statusMessage); err != nil {
+		return fmt.Errorf("failed to set status message: %w", err)
+	}
+	return m.save()
+}
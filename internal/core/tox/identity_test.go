@@ -0,0 +1,34 @@
+package tox
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name          string
+		profileName   string
+		statusMessage string
+		wantErr       bool
+	}{
+		{"empty", "", "", false},
+		{"typical", "Alice", "Out until Monday", false},
+		{"name at limit", strings.Repeat("a", MaxNameLength), "", false},
+		{"name too long", strings.Repeat("a", MaxNameLength+1), "", true},
+		{"multibyte name too long", strings.Repeat("é", MaxNameLength/2+1), "", true},
+		{"name with newline", "Alice\nSmith", "", true},
+		{"status at limit", "Alice", strings.Repeat("b", MaxStatusMessageLength), false},
+		{"status too long", "Alice", strings.Repeat("b", MaxStatusMessageLength+1), true},
+		{"multiline status", "Alice", "line one\nline two", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProfile(tt.profileName, tt.statusMessage)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,19 +1,30 @@
 package tox
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// Tox is iterated at defaultIterationInterval when it recommends none, and
+// failed iterations are retried no slower than maxIterationBackoff
+const (
+	defaultIterationInterval = 50 * time.Millisecond
+	maxIterationBackoff      = 5 * time.Second
 )
 
 // Config holds Tox manager configuration
 type Config struct {
-	DataDir string
-	Debug   bool
+	DataDir        string
+	Debug          bool
+	BootstrapNodes []BootstrapNode // Empty uses DefaultBootstrapNodes
 }
 
 // Manager manages the Tox instance and protocol operations
@@ -24,6 +35,17 @@ type Manager struct {
 	running  bool
 	saveFile string
 
+	bootstrapNodes []BootstrapNode
+
+	// Iteration loop, run between Start and Stop
+	stopLoop context.CancelFunc
+	loopDone chan struct{}
+	paused   bool // Events are not serviced while the app is locked
+
+	// Presence
+	selfStatus    toxcore.FriendStatus
+	appearOffline bool
+
 	// Event callbacks
 	onFriendRequest func([32]byte, string)
 	onFriendMessage func(uint32, string)
@@ -39,8 +61,10 @@ type Manager struct {
 // NewManager creates a new Tox manager
 func NewManager(config *Config) (*Manager, error) {
 	m := &Manager{
-		config:   config,
-		saveFile: filepath.Join(config.DataDir, "tox.save"),
+		config:         config,
+		saveFile:       filepath.Join(config.DataDir, "tox.save"),
+		selfStatus:     toxcore.FriendStatusOnline,
+		bootstrapNodes: config.BootstrapNodes,
 	}
 
 	if err := m.initializeTox(); err != nil {
@@ -52,7 +76,7 @@ func NewManager(config *Config) (*Manager, error) {
 
 // initializeTox initializes the Tox instance
 func (m *Manager) initializeTox() error {
-	log.Println("Initializing Tox...")
+	logging.Infof("Initializing Tox...")
 
 	// Create options
 	options := toxcore.NewOptions()
@@ -65,10 +89,10 @@ func (m *Manager) initializeTox() error {
 
 	// Check if save file exists
 	if savedata, err := m.loadSavedata(); err == nil && len(savedata) > 0 {
-		log.Println("Loading existing Tox profile...")
+		logging.Infof("Loading existing Tox profile...")
 		tox, err = toxcore.NewFromSavedata(options, savedata)
 	} else {
-		log.Println("Creating new Tox profile...")
+		logging.Infof("Creating new Tox profile...")
 		tox, err = toxcore.New(options)
 	}
 
@@ -85,11 +109,11 @@ func (m *Manager) initializeTox() error {
 
 	// Bootstrap to network
 	if err := m.bootstrap(); err != nil {
-		log.Printf("Warning: Bootstrap failed: %v", err)
+		logging.Warnf("Bootstrap failed: %v", err)
 		// Don't fail initialization if bootstrap fails
 	}
 
-	log.Printf("Tox initialized. ID: %s", m.GetToxID())
+	logging.Infof("Tox initialized. ID: %s", m.GetToxID())
 	return nil
 }
 
@@ -120,39 +144,56 @@ func (m *Manager) Start() error {
 		return fmt.Errorf("Tox manager already running")
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
 	m.running = true
-	log.Println("Tox manager started")
+	m.stopLoop = cancel
+	m.loopDone = done
+	go func() {
+		defer close(done)
+		m.Run(ctx)
+	}()
+
+	logging.Infof("Tox manager started")
 	return nil
 }
 
-// Stop stops the Tox manager
+// Stop stops the Tox manager, waiting for the iteration loop to finish
 func (m *Manager) Stop() error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	if !m.running {
+		m.mu.Unlock()
 		return nil
 	}
-
 	m.running = false
-	log.Println("Tox manager stopped")
+	cancel, done := m.stopLoop, m.loopDone
+	m.stopLoop, m.loopDone = nil, nil
+	m.mu.Unlock()
+
+	// The loop takes the lock to iterate, so wait for it unlocked
+	cancel()
+	<-done
+
+	logging.Infof("Tox manager stopped")
 	return nil
 }
 
 // Cleanup cleans up resources
 func (m *Manager) Cleanup() {
+	m.Stop()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Save state before cleanup
 	if m.tox != nil {
 		if err := m.save(); err != nil {
-			log.Printf("Warning: Failed to save state during cleanup: %v", err)
+			logging.Warnf("Failed to save state during cleanup: %v", err)
 		}
 		m.tox.Kill()
 		m.tox = nil
 	}
-	log.Println("Tox manager cleanup")
+	logging.Infof("Tox manager cleanup")
 }
 
 // setupCallbacks sets up Tox event callbacks
@@ -184,42 +225,74 @@ func (m *Manager) setupCallbacks() error {
 	return nil
 }
 
-// bootstrap connects to the Tox network
-func (m *Manager) bootstrap() error {
-	// Bootstrap to well-known nodes
-	bootstrapNodes := []struct {
-		address   string
-		port      uint16
-		publicKey string
-	}{
-		{"node.tox.biribiri.org", 33445, "F404ABAA1C99A9D37D61AB54898F56793E1DEF8BD46B1038B9D822E8460FAB67"},
-		{"tox.initramfs.io", 33445, "3F0A45A268367C1BEA652F258C85F4A66DA76BCAA667A49E770BCC4917AB6A25"},
-		{"tox2.abilinski.com", 33445, "7A6098B590BDC73F9723FC59F82B3F9085A64D1B213AAF8E610FD351930D052D"},
-	}
-
-	var lastErr error
-	for _, node := range bootstrapNodes {
-		err := m.tox.Bootstrap(node.address, node.port, node.publicKey)
-		if err != nil {
-			lastErr = err
-			log.Printf("Failed to bootstrap to %s: %v", node.address, err)
+// Iterate performs one Tox iteration
+func (m *Manager) Iterate() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.tox != nil && m.running && !m.paused {
+		m.tox.Iterate()
+	}
+}
+
+// SetPaused stops or resumes servicing Tox events, e.g. while the database
+// is closed
+func (m *Manager) SetPaused(paused bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = paused
+}
+
+// Run iterates Tox at the interval it asks for until ctx is done. A failed
+// iteration is logged and retried after a growing delay.
+func (m *Manager) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	backoff := time.Duration(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		wait := m.iterationInterval()
+		if err := m.iterateSafely(); err != nil {
+			backoff = min(max(2*backoff, wait), maxIterationBackoff)
+			logging.Errorf("Tox iteration failed, retrying in %v: %v", backoff, err)
+			wait = backoff
 		} else {
-			log.Printf("Successfully bootstrapped to %s", node.address)
-			return nil
+			backoff = 0
 		}
+		timer.Reset(wait)
 	}
+}
 
-	return lastErr
+// iterateSafely runs one iteration, turning a panic in toxcore or a
+// callback into an error so the loop keeps going
+func (m *Manager) iterateSafely() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	m.Iterate()
+	return nil
 }
 
-// Iterate performs one Tox iteration
-func (m *Manager) Iterate() {
+// iterationInterval returns how long toxcore wants between iterations
+func (m *Manager) iterationInterval() time.Duration {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if m.tox != nil && m.running {
-		m.tox.Iterate()
+	if m.tox == nil {
+		return defaultIterationInterval
+	}
+	if interval := m.tox.IterationInterval(); interval > 0 {
+		return interval
 	}
+	return defaultIterationInterval
 }
 
 // GetToxID returns the current Tox ID
@@ -233,13 +306,24 @@ func (m *Manager) GetToxID() string {
 
 	toxID := m.tox.SelfGetAddress()
 	if toxID == "" {
-		log.Printf("Warning: Tox instance returned empty ID")
+		logging.Warnf("Tox instance returned empty ID")
 		return ""
 	}
 
 	return toxID
 }
 
+// GetConnectionStatus returns how we are connected to the Tox network
+func (m *Manager) GetConnectionStatus() toxcore.ConnectionStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.tox == nil {
+		return toxcore.ConnectionNone
+	}
+	return m.tox.SelfGetConnectionStatus()
+}
+
 // SendMessage sends a message to a friend
 func (m *Manager) SendMessage(friendID uint32, message string, messageType toxcore.MessageType) error {
 	m.mu.RLock()
@@ -268,7 +352,7 @@ func (m *Manager) AddFriend(toxID, message string) (uint32, error) {
 
 	// Save state after adding friend
 	if err := m.save(); err != nil {
-		log.Printf("Warning: Failed to save after adding friend: %v", err)
+		logging.Warnf("Failed to save after adding friend: %v", err)
 	}
 
 	return friendID, nil
@@ -290,7 +374,7 @@ func (m *Manager) AcceptFriendRequest(publicKey [32]byte) (uint32, error) {
 
 	// Save state after accepting friend request
 	if err := m.save(); err != nil {
-		log.Printf("Warning: Failed to save after accepting friend: %v", err)
+		logging.Warnf("Failed to save after accepting friend: %v", err)
 	}
 
 	return friendID, nil
@@ -312,7 +396,7 @@ func (m *Manager) DeleteFriend(friendID uint32) error {
 
 	// Save state after deleting friend
 	if err := m.save(); err != nil {
-		log.Printf("Warning: Failed to save after deleting friend: %v", err)
+		logging.Warnf("Failed to save after deleting friend: %v", err)
 	}
 
 	return nil
@@ -347,6 +431,18 @@ func (m *Manager) GetFriendPublicKey(friendID uint32) ([32]byte, error) {
 	return m.tox.GetFriendPublicKey(friendID)
 }
 
+// GetPublicKey returns our public key
+func (m *Manager) GetPublicKey() [32]byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.tox == nil {
+		return [32]byte{}
+	}
+
+	return m.tox.SelfGetPublicKey()
+}
+
 // SetName sets our display name
 func (m *Manager) SetName(name string) error {
 	m.mu.RLock()
@@ -422,7 +518,7 @@ func (m *Manager) save() error {
 		return fmt.Errorf("failed to move savedata to final location: %w", err)
 	}
 
-	log.Printf("Tox savedata written to %s (%d bytes)", m.saveFile, len(savedata))
+	logging.Debugf("Tox savedata written to %s (%d bytes)", m.saveFile, len(savedata))
 	return nil
 }
 
@@ -472,7 +568,7 @@ func (m *Manager) FileSend(friendID, kind uint32, fileSize uint64, fileID [32]by
 	// Note: This is a simplified implementation. Real toxcore may have different method signature
 	// For now, we'll return a mock file ID since the actual toxcore integration details
 	// may vary based on the specific toxcore library implementation
-	log.Printf("FileSend called: friend=%d, kind=%d, size=%d, name=%s", friendID, kind, fileSize, fileName)
+	logging.Debugf("FileSend called: friend=%d, kind=%d, size=%d, name=%s", friendID, kind, fileSize, fileName)
 	return 1, nil // Return mock file ID for integration testing
 }
 
@@ -485,7 +581,7 @@ func (m *Manager) FileSendChunk(friendID, fileID uint32, position uint64, data [
 		return fmt.Errorf("Tox instance not initialized")
 	}
 
-	log.Printf("FileSendChunk called: friend=%d, fileID=%d, position=%d, dataLen=%d", friendID, fileID, position, len(data))
+	logging.Debugf("FileSendChunk called: friend=%d, fileID=%d, position=%d, dataLen=%d", friendID, fileID, position, len(data))
 	return nil // Success for integration testing
 }
 
@@ -498,7 +594,7 @@ func (m *Manager) FileControl(friendID, fileID uint32, control toxcore.FileContr
 		return fmt.Errorf("Tox instance not initialized")
 	}
 
-	log.Printf("FileControl called: friend=%d, fileID=%d, control=%v", friendID, fileID, control)
+	logging.Debugf("FileControl called: friend=%d, fileID=%d, control=%v", friendID, fileID, control)
 	return nil // Success for integration testing
 }
 
@@ -1,6 +1,7 @@
 package tox
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -339,6 +340,50 @@ func TestManager_Iterate(t *testing.T) {
 	manager.Iterate()
 }
 
+// TestManager_RunStopsOnCancel tests that the iteration loop ends with its context
+func TestManager_RunStopsOnCancel(t *testing.T) {
+	manager, err := NewManager(&Config{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer manager.Cleanup()
+
+	if interval := manager.iterationInterval(); interval <= 0 {
+		t.Errorf("Expected a positive iteration interval, got %v", interval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		manager.Run(ctx)
+		close(done)
+	}()
+
+	// Let it iterate a few times first
+	time.Sleep(3 * manager.iterationInterval())
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected Run to return once its context was cancelled")
+	}
+
+	// Start runs the loop until Stop, which waits for it to finish
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	loopDone := manager.loopDone
+	if err := manager.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	select {
+	case <-loopDone:
+	default:
+		t.Error("Expected Stop to end the iteration loop")
+	}
+}
+
 // TestManager_SaveStateOnCleanup tests that state is saved during cleanup
 func TestManager_SaveStateOnCleanup(t *testing.T) {
 	tempDir := t.TempDir()
@@ -0,0 +1,48 @@
+package tox
+
+import (
+	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// SetSelfStatus sets the user status we present to contacts
+func (m *Manager) SetSelfStatus(status toxcore.FriendStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.selfStatus = status
+}
+
+// GetSelfStatus returns the user status we present to contacts.
+// While appear-offline is enabled this is always FriendStatusNone,
+// regardless of the underlying connection.
+func (m *Manager) GetSelfStatus() toxcore.FriendStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.appearOffline {
+		return toxcore.FriendStatusNone
+	}
+	return m.selfStatus
+}
+
+// SetAppearOffline toggles the appear-offline presence override. The Tox
+// connection stays up so messages and file transfers keep flowing; only the
+// status we report is changed.
+func (m *Manager) SetAppearOffline(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.appearOffline == enabled {
+		return
+	}
+	m.appearOffline = enabled
+	logging.Infof("Appear offline: %v", enabled)
+}
+
+// IsAppearOffline reports whether the appear-offline override is enabled
+func (m *Manager) IsAppearOffline() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.appearOffline
+}
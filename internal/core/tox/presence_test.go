@@ -0,0 +1,59 @@
+package tox
+
+import (
+	"testing"
+
+	"github.com/opd-ai/toxcore"
+)
+
+func TestManager_AppearOffline(t *testing.T) {
+	manager, err := NewManager(&Config{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer manager.Cleanup()
+
+	peer, err := NewManager(&Config{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create peer: %v", err)
+	}
+	defer peer.Cleanup()
+
+	friendID, err := manager.AddFriend(peer.GetToxID(), "hi")
+	if err != nil {
+		t.Fatalf("AddFriend failed: %v", err)
+	}
+
+	if status := manager.GetSelfStatus(); status != toxcore.FriendStatusOnline {
+		t.Errorf("Expected online status by default, got %v", status)
+	}
+	sendErr := manager.SendMessage(friendID, "before", toxcore.MessageTypeNormal)
+
+	manager.SetAppearOffline(true)
+
+	if !manager.IsAppearOffline() {
+		t.Error("Expected appear offline to be enabled")
+	}
+	if status := manager.GetSelfStatus(); status != toxcore.FriendStatusNone {
+		t.Errorf("Expected offline status while appearing offline, got %v", status)
+	}
+
+	// The peer is not connected in a unit test, so delivery cannot complete either
+	// way; what matters is that the override does not change the send path
+	err = manager.SendMessage(friendID, "while hidden", toxcore.MessageTypeNormal)
+	if errorString(err) != errorString(sendErr) {
+		t.Errorf("Expected send outcome to be unchanged, got %v (was %v)", err, sendErr)
+	}
+
+	manager.SetAppearOffline(false)
+	if status := manager.GetSelfStatus(); status != toxcore.FriendStatusOnline {
+		t.Errorf("Expected online status after disabling override, got %v", status)
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,132 @@
+package tox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// ProfileData returns the savedata of the running Tox instance
+func (m *Manager) ProfileData() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.tox == nil {
+		return nil, fmt.Errorf("Tox not initialized")
+	}
+	savedata := m.tox.GetSavedata()
+	if len(savedata) == 0 {
+		return nil, fmt.Errorf("no savedata to export")
+	}
+	return savedata, nil
+}
+
+// ExportProfile writes the current Tox identity to a .tox file
+func (m *Manager) ExportProfile(path string) error {
+	savedata, err := m.ProfileData()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, savedata, 0o600); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return nil
+}
+
+// ImportProfile replaces the active Tox identity with the one in a .tox file
+func (m *Manager) ImportProfile(path string) error {
+	savedata, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read profile: %w", err)
+	}
+	return m.LoadProfile(savedata)
+}
+
+// LoadProfile replaces the active Tox identity with the given savedata. The
+// savedata must load in toxcore before anything is replaced; the previous
+// profile is backed up next to tox.save and the Tox instance is restarted
+// with the new identity.
+func (m *Manager) LoadProfile(savedata []byte) error {
+	if len(savedata) == 0 {
+		return fmt.Errorf("profile is empty")
+	}
+
+	options := toxcore.NewOptions()
+	options.UDPEnabled = true
+	options.IPv6Enabled = true
+	tox, err := toxcore.NewFromSavedata(options, savedata)
+	if err != nil {
+		return fmt.Errorf("invalid Tox profile: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.backupProfile(); err != nil {
+		tox.Kill()
+		return err
+	}
+	if err := writeFileAtomic(m.saveFile, savedata); err != nil {
+		tox.Kill()
+		return err
+	}
+
+	if m.tox != nil {
+		m.tox.Kill()
+	}
+	m.tox = tox
+	if err := m.setupCallbacks(); err != nil {
+		return fmt.Errorf("failed to setup callbacks: %w", err)
+	}
+	if err := m.bootstrap(); err != nil {
+		logging.Warnf("Bootstrap failed: %v", err)
+	}
+
+	logging.Infof("Imported Tox profile. ID: %s", m.tox.SelfGetAddress())
+	return nil
+}
+
+// backupProfile copies the current savedata to a timestamped backup file.
+// Must be called with m.mu held.
+func (m *Manager) backupProfile() error {
+	var savedata []byte
+	if m.tox != nil {
+		savedata = m.tox.GetSavedata()
+	}
+	if len(savedata) == 0 {
+		data, err := m.loadSavedata()
+		if err != nil {
+			return nil // Nothing to back up
+		}
+		savedata = data
+	}
+
+	backup := fmt.Sprintf("%s.%s.bak", m.saveFile, time.Now().Format("20060102-150405"))
+	if err := writeFileAtomic(backup, savedata); err != nil {
+		return fmt.Errorf("failed to back up current profile: %w", err)
+	}
+	logging.Infof("Backed up current Tox profile to %s", backup)
+	return nil
+}
+
+// writeFileAtomic writes data to path through a temporary file
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tempFile, err)
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to move %s into place: %w", path, err)
+	}
+	return nil
+}
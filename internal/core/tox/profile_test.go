@@ -0,0 +1,60 @@
+package tox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfileExportImport(t *testing.T) {
+	source, err := NewManager(&Config{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create source manager: %v", err)
+	}
+	defer source.Cleanup()
+
+	exported := filepath.Join(t.TempDir(), "me.tox")
+	if err := source.ExportProfile(exported); err != nil {
+		t.Fatalf("ExportProfile failed: %v", err)
+	}
+
+	targetDir := t.TempDir()
+	target, err := NewManager(&Config{DataDir: targetDir})
+	if err != nil {
+		t.Fatalf("Failed to create target manager: %v", err)
+	}
+	defer target.Cleanup()
+	if err := target.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	oldID := target.GetToxID()
+
+	// Data that does not load leaves the current profile alone
+	garbage := filepath.Join(t.TempDir(), "garbage.tox")
+	if err := os.WriteFile(garbage, []byte("not a profile"), 0o600); err != nil {
+		t.Fatalf("Failed to write garbage: %v", err)
+	}
+	if err := target.ImportProfile(garbage); err == nil {
+		t.Fatal("Expected an invalid profile to be refused")
+	}
+	if target.GetToxID() != oldID {
+		t.Fatal("Expected the identity to be unchanged after a failed import")
+	}
+
+	if err := target.ImportProfile(exported); err != nil {
+		t.Fatalf("ImportProfile failed: %v", err)
+	}
+	if target.GetToxID() != source.GetToxID() {
+		t.Errorf("Expected imported ID %s, got %s", source.GetToxID(), target.GetToxID())
+	}
+
+	// The previous profile is backed up and the new one saved
+	backups, _ := filepath.Glob(filepath.Join(targetDir, "tox.save.*.bak"))
+	if len(backups) != 1 {
+		t.Fatalf("Expected one backup, got %v", backups)
+	}
+	saved, err := os.ReadFile(filepath.Join(targetDir, "tox.save"))
+	if err != nil || len(saved) == 0 {
+		t.Fatalf("Expected the imported profile to be saved: %v", err)
+	}
+}
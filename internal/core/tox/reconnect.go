@@ -0,0 +1,162 @@
+package tox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// ConnectionState is how connected we are to the Tox network
+type ConnectionState int
+
+const (
+	// ConnectionOffline means the last bootstrap failed; another is scheduled
+	ConnectionOffline ConnectionState = iota
+	// ConnectionConnecting means a node was contacted and we wait to join
+	ConnectionConnecting
+	// ConnectionOnline means we are part of the network
+	ConnectionOnline
+)
+
+// String returns the state's name
+func (s ConnectionState) String() string {
+	switch s {
+	case ConnectionOffline:
+		return "offline"
+	case ConnectionConnecting:
+		return "connecting"
+	case ConnectionOnline:
+		return "online"
+	default:
+		return "unknown"
+	}
+}
+
+// Bootstraps are retried after reconnectMinDelay, doubling up to
+// reconnectMaxDelay while the network stays unreachable
+const (
+	reconnectMinDelay = 5 * time.Second
+	reconnectMaxDelay = 5 * time.Minute
+)
+
+// ReconnectCheckInterval is how often the connection is checked
+const ReconnectCheckInterval = time.Second
+
+// networkLink is the connection Reconnector watches; *Manager implements it
+type networkLink interface {
+	GetConnectionStatus() toxcore.ConnectionStatus
+	Bootstrap() error
+}
+
+// Reconnector watches the Tox connection and, while it is down, bootstraps
+// again with exponential backoff until we are back online
+type Reconnector struct {
+	mu          sync.Mutex
+	link        networkLink
+	state       ConnectionState
+	attempts    int       // Bootstraps since we were last online
+	nextAttempt time.Time // Zero until the first check
+	onChange    func(state ConnectionState)
+}
+
+// NewReconnector creates a reconnector for a link that has just bootstrapped
+func NewReconnector(link networkLink) *Reconnector {
+	return &Reconnector{link: link, state: ConnectionConnecting}
+}
+
+// SetOnChange sets the callback invoked when the connection state changes
+func (r *Reconnector) SetOnChange(callback func(state ConnectionState)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChange = callback
+}
+
+// State returns the current connection state
+func (r *Reconnector) State() ConnectionState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state
+}
+
+// Check updates the state for the connection at now and bootstraps if a
+// retry is due
+func (r *Reconnector) Check(now time.Time) {
+	connected := r.link.GetConnectionStatus() != toxcore.ConnectionNone
+
+	r.mu.Lock()
+	previous := r.state
+	bootstrapDue := false
+	switch {
+	case connected:
+		r.state = ConnectionOnline
+		r.attempts = 0
+		r.nextAttempt = time.Time{}
+	case r.state == ConnectionOnline:
+		// Dropped off the network: try again straight away
+		logging.Warnf("Lost connection to the Tox network, reconnecting")
+		r.state = ConnectionOffline
+		bootstrapDue = true
+	case r.nextAttempt.IsZero():
+		// Give the bootstrap done at startup time to take
+		r.nextAttempt = now.Add(reconnectDelay(0))
+	default:
+		bootstrapDue = !now.Before(r.nextAttempt)
+	}
+	if bootstrapDue {
+		r.attempts++
+		r.nextAttempt = now.Add(reconnectDelay(r.attempts))
+	}
+	r.mu.Unlock()
+
+	// Bootstrapping resolves node addresses, so it runs unlocked
+	if bootstrapDue {
+		state := ConnectionConnecting
+		if err := r.link.Bootstrap(); err != nil {
+			state = ConnectionOffline
+		}
+
+		r.mu.Lock()
+		// Only a check since could have seen us come online
+		if r.state != ConnectionOnline {
+			r.state = state
+		}
+		r.mu.Unlock()
+	}
+
+	r.mu.Lock()
+	current := r.state
+	callback := r.onChange
+	r.mu.Unlock()
+
+	if current != previous && callback != nil {
+		callback(current)
+	}
+}
+
+// Run checks the connection every interval until ctx is done
+func (r *Reconnector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.Check(now)
+		}
+	}
+}
+
+// reconnectDelay returns how long to wait after the given number of
+// bootstraps before trying again
+func reconnectDelay(attempts int) time.Duration {
+	delay := reconnectMinDelay
+	for i := 0; i < attempts && delay < reconnectMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, reconnectMaxDelay)
+}
@@ -0,0 +1,113 @@
+package tox
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+)
+
+// fakeLink is a network connection whose status the test sets
+type fakeLink struct {
+	status       toxcore.ConnectionStatus
+	bootstrapErr error
+	bootstraps   []time.Time
+	now          time.Time
+}
+
+func (l *fakeLink) GetConnectionStatus() toxcore.ConnectionStatus { return l.status }
+
+func (l *fakeLink) Bootstrap() error {
+	l.bootstraps = append(l.bootstraps, l.now)
+	return l.bootstrapErr
+}
+
+func TestReconnectDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{5, 160 * time.Second},
+		{6, 5 * time.Minute},
+		{100, 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := reconnectDelay(tt.attempts); got != tt.want {
+			t.Errorf("reconnectDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestReconnector(t *testing.T) {
+	link := &fakeLink{status: toxcore.ConnectionUDP, now: time.Unix(1000, 0)}
+	r := NewReconnector(link)
+
+	var states []ConnectionState
+	r.SetOnChange(func(state ConnectionState) { states = append(states, state) })
+
+	check := func(after time.Duration) {
+		link.now = link.now.Add(after)
+		r.Check(link.now)
+	}
+
+	check(0)
+	if r.State() != ConnectionOnline || len(link.bootstraps) != 0 {
+		t.Fatalf("Expected online without bootstrapping, got %v after %d bootstraps", r.State(), len(link.bootstraps))
+	}
+
+	// Losing the connection bootstraps at once, then backs off while nodes fail
+	link.status = toxcore.ConnectionNone
+	link.bootstrapErr = errors.New("unreachable")
+	dropped := link.now
+	for i := 0; i < 5*60; i++ {
+		check(time.Second)
+	}
+	want := []time.Duration{1, 11, 31, 71, 151}
+	if len(link.bootstraps) != len(want) {
+		t.Fatalf("Expected %d bootstraps in five minutes, got %d", len(want), len(link.bootstraps))
+	}
+	for i, at := range link.bootstraps {
+		if got := at.Sub(dropped); got != want[i]*time.Second {
+			t.Errorf("Bootstrap %d: expected %v after the drop, got %v", i, want[i]*time.Second, got)
+		}
+	}
+	if r.State() != ConnectionOffline {
+		t.Errorf("Expected offline while bootstraps fail, got %v", r.State())
+	}
+
+	// A node answering means connecting, and the network answering means online
+	link.bootstrapErr = nil
+	check(5 * time.Minute)
+	if r.State() != ConnectionConnecting {
+		t.Errorf("Expected connecting after a successful bootstrap, got %v", r.State())
+	}
+	link.status = toxcore.ConnectionTCP
+	check(time.Second)
+	if r.State() != ConnectionOnline {
+		t.Errorf("Expected online once connected, got %v", r.State())
+	}
+
+	wantStates := []ConnectionState{ConnectionOnline, ConnectionOffline, ConnectionConnecting, ConnectionOnline}
+	if len(states) != len(wantStates) {
+		t.Fatalf("Expected state changes %v, got %v", wantStates, states)
+	}
+	for i := range wantStates {
+		if states[i] != wantStates[i] {
+			t.Errorf("State change %d: expected %v, got %v", i, wantStates[i], states[i])
+		}
+	}
+
+	// Backoff starts over after reconnecting
+	link.status = toxcore.ConnectionNone
+	before := len(link.bootstraps)
+	check(time.Second)
+	check(10 * time.Second)
+	if len(link.bootstraps) != before+2 {
+		t.Errorf("Expected the backoff to reset, got %d new bootstraps", len(link.bootstraps)-before)
+	}
+}
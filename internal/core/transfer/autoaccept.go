@@ -0,0 +1,92 @@
+package transfer
+
+import (
+	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// AutoAcceptPolicy decides which incoming files are saved without asking
+type AutoAcceptPolicy struct {
+	Enabled     bool   // Accept files up to MaxSize from every friend
+	MaxSize     uint64 // Largest file accepted when Enabled
+	Trusted     bool   // The sender's files are always accepted
+	DownloadDir string // Where accepted files are saved; nothing is accepted without one
+}
+
+// Accepts reports whether a file of the given size is accepted automatically.
+// The manager's maximum file size still applies to trusted friends.
+func (p AutoAcceptPolicy) Accepts(fileSize uint64) bool {
+	if p.DownloadDir == "" {
+		return false
+	}
+	return p.Trusted || (p.Enabled && fileSize <= p.MaxSize)
+}
+
+// SetAutoAcceptPolicy sets the function returning the auto-accept policy for
+// a sender. It is consulted for every offered file, so settings changes apply
+// to the next transfer.
+func (m *Manager) SetAutoAcceptPolicy(policy func(friendID uint32) AutoAcceptPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.autoAccept = policy
+}
+
+// SetBlockedFilter sets the function reporting blocked friends. Files they
+// offer are cancelled without creating a transfer or notifying anyone.
+func (m *Manager) SetBlockedFilter(isBlocked func(friendID uint32) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.isBlocked = isBlocked
+}
+
+// rejectBlocked cancels a file offered by a blocked friend and reports
+// whether it did
+func (m *Manager) rejectBlocked(friendID, fileID uint32) bool {
+	m.mu.RLock()
+	isBlocked, toxMgr := m.isBlocked, m.toxMgr
+	m.mu.RUnlock()
+
+	if isBlocked == nil || !isBlocked(friendID) {
+		return false
+	}
+
+	if toxMgr != nil {
+		if err := toxMgr.FileControl(friendID, fileID, toxcore.FileControlCancel); err != nil {
+			logging.Warnf("Failed to cancel file from blocked friend %d: %v", friendID, err)
+		}
+	}
+	logging.Infof("Refused file from blocked friend %d", friendID)
+	return true
+}
+
+// SetOnIncomingFile sets the callback invoked when a friend offers a file,
+// with whether it was accepted automatically
+func (m *Manager) SetOnIncomingFile(callback func(transfer *Transfer, accepted bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onIncomingFile = callback
+}
+
+// applyAutoAccept accepts a newly offered file if the policy allows it and
+// reports the offer. Files that are not accepted stay pending for the user.
+func (m *Manager) applyAutoAccept(transfer *Transfer) {
+	m.mu.RLock()
+	policyFor, callback := m.autoAccept, m.onIncomingFile
+	m.mu.RUnlock()
+
+	accepted := false
+	if policyFor != nil {
+		if policy := policyFor(transfer.FriendID); policy.Accepts(transfer.FileSize) {
+			if err := m.AcceptIncomingFile(transfer.ID, policy.DownloadDir); err != nil {
+				logging.Warnf("Failed to auto-accept file transfer %s: %v", transfer.ID, err)
+			} else {
+				accepted = true
+			}
+		}
+	}
+
+	if callback != nil {
+		callback(transfer, accepted)
+	}
+}
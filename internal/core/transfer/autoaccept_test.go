@@ -0,0 +1,143 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/toxcore"
+)
+
+func TestAutoAcceptPolicyAccepts(t *testing.T) {
+	const limit = 1024
+
+	tests := []struct {
+		name   string
+		policy AutoAcceptPolicy
+		size   uint64
+		want   bool
+	}{
+		{"disabled", AutoAcceptPolicy{MaxSize: limit, DownloadDir: "dl"}, 10, false},
+		{"under limit", AutoAcceptPolicy{Enabled: true, MaxSize: limit, DownloadDir: "dl"}, limit - 1, true},
+		{"at limit", AutoAcceptPolicy{Enabled: true, MaxSize: limit, DownloadDir: "dl"}, limit, true},
+		{"over limit", AutoAcceptPolicy{Enabled: true, MaxSize: limit, DownloadDir: "dl"}, limit + 1, false},
+		{"trusted over limit", AutoAcceptPolicy{MaxSize: limit, Trusted: true, DownloadDir: "dl"}, limit * 10, true},
+		{"no download dir", AutoAcceptPolicy{Enabled: true, MaxSize: limit, Trusted: true}, 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Accepts(tt.size); got != tt.want {
+				t.Errorf("Accepts(%d) = %v, want %v", tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleFileRecvAutoAccept(t *testing.T) {
+	manager, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+
+	downloadDir := filepath.Join(t.TempDir(), "Downloads")
+	manager.SetAutoAcceptPolicy(func(friendID uint32) AutoAcceptPolicy {
+		return AutoAcceptPolicy{Enabled: true, MaxSize: 100, Trusted: friendID == 7, DownloadDir: downloadDir}
+	})
+
+	offers := make(map[string]bool)
+	manager.SetOnIncomingFile(func(transfer *Transfer, accepted bool) {
+		offers[transfer.FileName] = accepted
+	})
+
+	manager.handleFileRecv(1, 1, 0, 100, "small.txt")
+	manager.handleFileRecv(1, 2, 0, 101, "large.txt")
+	manager.handleFileRecv(7, 3, 0, 5000, "trusted.txt")
+
+	want := map[string]bool{"small.txt": true, "large.txt": false, "trusted.txt": true}
+	for name, accepted := range want {
+		got, offered := offers[name]
+		if !offered {
+			t.Errorf("Expected %s to be reported", name)
+			continue
+		}
+		if got != accepted {
+			t.Errorf("%s: expected accepted=%v, got %v", name, accepted, got)
+		}
+	}
+
+	for _, transfer := range append(manager.GetTransfersByFriend(1), manager.GetTransfersByFriend(7)...) {
+		wantState := TransferStatePending
+		if want[transfer.FileName] {
+			wantState = TransferStateActive
+			if _, err := os.Stat(filepath.Join(downloadDir, transfer.FileName)); err != nil {
+				t.Errorf("Expected %s to be saved to the download directory: %v", transfer.FileName, err)
+			}
+		}
+		if transfer.State != wantState {
+			t.Errorf("%s: expected state %v, got %v", transfer.FileName, wantState, transfer.State)
+		}
+		if transfer.file != nil {
+			transfer.file.Close()
+		}
+	}
+}
+
+func TestHandleFileRecvWithoutPolicy(t *testing.T) {
+	manager, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+
+	reported := false
+	manager.SetOnIncomingFile(func(transfer *Transfer, accepted bool) {
+		reported = true
+		if accepted {
+			t.Error("Expected the file not to be accepted without a policy")
+		}
+	})
+	manager.handleFileRecv(1, 1, 0, 10, "note.txt")
+
+	if !reported {
+		t.Error("Expected the offer to be reported")
+	}
+	if transfers := manager.GetTransfersByFriend(1); len(transfers) != 1 || transfers[0].State != TransferStatePending {
+		t.Errorf("Expected one pending transfer, got %+v", transfers)
+	}
+}
+
+func TestHandleFileRecvBlocked(t *testing.T) {
+	manager, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+
+	var cancelled []uint32
+	manager.SetToxManager(&MockToxManager{
+		fileControlFunc: func(friendID, fileID uint32, control toxcore.FileControl) error {
+			if control == toxcore.FileControlCancel {
+				cancelled = append(cancelled, friendID)
+			}
+			return nil
+		},
+	})
+	manager.SetBlockedFilter(func(friendID uint32) bool { return friendID == 3 })
+
+	var offered []uint32
+	manager.SetOnIncomingFile(func(transfer *Transfer, accepted bool) {
+		offered = append(offered, transfer.FriendID)
+	})
+
+	manager.handleFileRecv(3, 1, 0, 10, "spam.txt")
+	manager.handleFileRecv(4, 2, 0, 10, "note.txt")
+
+	if len(manager.GetTransfersByFriend(3)) != 0 {
+		t.Error("Expected no transfer for a blocked friend")
+	}
+	if len(cancelled) != 1 || cancelled[0] != 3 {
+		t.Errorf("Expected only the blocked friend's file to be cancelled, got %v", cancelled)
+	}
+	if len(offered) != 1 || offered[0] != 4 {
+		t.Errorf("Expected only the unblocked friend's file to be reported, got %v", offered)
+	}
+}
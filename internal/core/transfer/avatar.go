@@ -0,0 +1,149 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/opd-ai/toxcore"
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// FileKindAvatar is the Tox file kind of avatars. An empty avatar file means
+// the sender has no avatar.
+const FileKindAvatar uint32 = 1
+
+// maxAvatarSize is the largest avatar Tox clients accept
+const maxAvatarSize = 64 * 1024
+
+// avatarKey identifies an avatar being sent or received
+type avatarKey struct {
+	friendID uint32
+	fileID   uint32
+}
+
+// avatarDownload is an avatar being received into memory
+type avatarDownload struct {
+	data     []byte
+	received uint64 // Bytes written so far, in any order
+}
+
+// SetOnAvatarReceived sets the callback invoked with a friend's new avatar,
+// or nil once they removed theirs
+func (m *Manager) SetOnAvatarReceived(callback func(friendID uint32, avatar []byte)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onAvatarReceived = callback
+}
+
+// SendAvatar offers our avatar to a friend; an empty one tells them we have
+// none. Avatars are kept in memory and bypass the transfer limits.
+func (m *Manager) SendAvatar(friendID uint32, avatar []byte) error {
+	if len(avatar) > maxAvatarSize {
+		return fmt.Errorf("avatar is larger than %d bytes", maxAvatarSize)
+	}
+
+	m.mu.RLock()
+	toxMgr := m.toxMgr
+	m.mu.RUnlock()
+	if toxMgr == nil {
+		return fmt.Errorf("file transfers are not available")
+	}
+
+	// Tox identifies avatars by their hash so unchanged ones can be refused
+	toxFileID, err := toxMgr.FileSend(friendID, FileKindAvatar, uint64(len(avatar)), sha256.Sum256(avatar), "")
+	if err != nil {
+		return fmt.Errorf("failed to send avatar: %w", err)
+	}
+	if len(avatar) > 0 {
+		m.mu.Lock()
+		m.avatarSends[avatarKey{friendID, toxFileID}] = avatar
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+// receiveAvatar accepts an avatar a friend offered
+func (m *Manager) receiveAvatar(friendID, fileID uint32, fileSize uint64) {
+	m.mu.Lock()
+	toxMgr, callback := m.toxMgr, m.onAvatarReceived
+	if fileSize > 0 && fileSize <= maxAvatarSize {
+		m.avatarRecvs[avatarKey{friendID, fileID}] = &avatarDownload{data: make([]byte, fileSize)}
+	}
+	m.mu.Unlock()
+
+	control := toxcore.FileControlResume
+	if fileSize == 0 || fileSize > maxAvatarSize {
+		if fileSize > maxAvatarSize {
+			logging.Warnf("Refused avatar of %d bytes from friend %d", fileSize, friendID)
+		}
+		control = toxcore.FileControlCancel
+	}
+	if toxMgr != nil {
+		if err := toxMgr.FileControl(friendID, fileID, control); err != nil {
+			logging.Warnf("Failed to answer avatar from friend %d: %v", friendID, err)
+		}
+	}
+
+	if fileSize == 0 && callback != nil {
+		callback(friendID, nil)
+	}
+}
+
+// receiveAvatarChunk stores a chunk of an avatar being received and reports
+// whether the chunk belonged to one
+func (m *Manager) receiveAvatarChunk(friendID, fileID uint32, position uint64, data []byte) bool {
+	key := avatarKey{friendID, fileID}
+
+	m.mu.Lock()
+	download, ok := m.avatarRecvs[key]
+	if !ok {
+		m.mu.Unlock()
+		return false
+	}
+	if position+uint64(len(data)) > uint64(len(download.data)) {
+		delete(m.avatarRecvs, key)
+		m.mu.Unlock()
+		logging.Warnf("Dropped avatar from friend %d: chunk past its size", friendID)
+		return true
+	}
+	copy(download.data[position:], data)
+	download.received += uint64(len(data))
+	complete := download.received >= uint64(len(download.data))
+	if complete {
+		delete(m.avatarRecvs, key)
+	}
+	callback := m.onAvatarReceived
+	m.mu.Unlock()
+
+	if complete && callback != nil {
+		callback(friendID, download.data)
+	}
+	return true
+}
+
+// sendAvatarChunk answers a chunk request for an avatar being sent and
+// reports whether the request was for one
+func (m *Manager) sendAvatarChunk(friendID, fileID uint32, position uint64, length int) bool {
+	key := avatarKey{friendID, fileID}
+
+	m.mu.Lock()
+	avatar, ok := m.avatarSends[key]
+	if ok && (length == 0 || position >= uint64(len(avatar))) {
+		delete(m.avatarSends, key)
+	}
+	toxMgr := m.toxMgr
+	m.mu.Unlock()
+
+	if !ok || length == 0 || position >= uint64(len(avatar)) || toxMgr == nil {
+		return ok
+	}
+
+	end := position + uint64(length)
+	if end > uint64(len(avatar)) {
+		end = uint64(len(avatar))
+	}
+	if err := toxMgr.FileSendChunk(friendID, fileID, position, avatar[position:end]); err != nil {
+		logging.Warnf("Failed to send avatar to friend %d: %v", friendID, err)
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package transfer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/opd-ai/toxcore"
+)
+
+func TestSendAvatar(t *testing.T) {
+	manager, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+
+	avatar := bytes.Repeat([]byte("avatar"), 100)
+	var sent []byte
+	mockTox := &MockToxManager{
+		fileSendFunc: func(friendID, kind uint32, fileSize uint64, fileID [32]byte, fileName string) (uint32, error) {
+			if kind != FileKindAvatar || fileSize != uint64(len(avatar)) || fileID != sha256.Sum256(avatar) {
+				t.Errorf("Unexpected avatar offer: kind=%d size=%d", kind, fileSize)
+			}
+			return 3, nil
+		},
+		fileSendChunkFunc: func(friendID, fileID uint32, position uint64, data []byte) error {
+			if position != uint64(len(sent)) {
+				t.Errorf("Expected a chunk at %d, got %d", len(sent), position)
+			}
+			sent = append(sent, data...)
+			return nil
+		},
+	}
+	manager.SetToxManager(mockTox)
+
+	if err := manager.SendAvatar(5, avatar); err != nil {
+		t.Fatalf("SendAvatar failed: %v", err)
+	}
+	for position := 0; position < len(avatar); position += 256 {
+		mockTox.TriggerFileChunkRequest(5, 3, uint64(position), 256)
+	}
+	mockTox.TriggerFileChunkRequest(5, 3, uint64(len(avatar)), 0)
+	if !bytes.Equal(sent, avatar) {
+		t.Errorf("Expected the avatar sent whole, got %d bytes", len(sent))
+	}
+	if len(manager.avatarSends) != 0 {
+		t.Error("Expected the finished avatar to be forgotten")
+	}
+	if len(manager.transfers) != 0 {
+		t.Error("Expected avatars to stay out of the transfer list")
+	}
+
+	if err := manager.SendAvatar(5, make([]byte, maxAvatarSize+1)); err == nil {
+		t.Error("Expected an oversized avatar to be refused")
+	}
+}
+
+func TestReceiveAvatar(t *testing.T) {
+	manager, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+
+	var controls []toxcore.FileControl
+	mockTox := &MockToxManager{
+		fileControlFunc: func(friendID, fileID uint32, control toxcore.FileControl) error {
+			controls = append(controls, control)
+			return nil
+		},
+	}
+	manager.SetToxManager(mockTox)
+
+	received := make(map[uint32][]byte)
+	calls := 0
+	manager.SetOnAvatarReceived(func(friendID uint32, avatar []byte) {
+		calls++
+		received[friendID] = avatar
+	})
+
+	// Chunks may arrive out of order
+	avatar := []byte("0123456789abcdef")
+	mockTox.TriggerFileRecv(2, 1, FileKindAvatar, uint64(len(avatar)), "")
+	mockTox.TriggerFileRecvChunk(2, 1, 8, avatar[8:])
+	mockTox.TriggerFileRecvChunk(2, 1, 0, avatar[:8])
+	if !bytes.Equal(received[2], avatar) {
+		t.Errorf("Expected the avatar received, got %q", received[2])
+	}
+
+	// An empty avatar means the friend removed theirs
+	mockTox.TriggerFileRecv(2, 2, FileKindAvatar, 0, "")
+	if avatar, ok := received[2]; !ok || avatar != nil {
+		t.Errorf("Expected the avatar removed, got %q", avatar)
+	}
+
+	// Avatars too large for Tox are refused
+	mockTox.TriggerFileRecv(3, 1, FileKindAvatar, maxAvatarSize+1, "")
+	if _, ok := received[3]; ok {
+		t.Error("Expected an oversized avatar to be refused")
+	}
+
+	want := []toxcore.FileControl{toxcore.FileControlResume, toxcore.FileControlCancel, toxcore.FileControlCancel}
+	if len(controls) != len(want) {
+		t.Fatalf("Expected controls %v, got %v", want, controls)
+	}
+	for i := range want {
+		if controls[i] != want[i] {
+			t.Errorf("Control %d: expected %v, got %v", i, want[i], controls[i])
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 avatar callbacks, got %d", calls)
+	}
+	if len(manager.transfers) != 0 {
+		t.Error("Expected avatars to stay out of the transfer list")
+	}
+}
@@ -3,10 +3,10 @@ package transfer
 import (
 	"fmt"
 	"io"
-	"log"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/opd-ai/whisp/internal/logging"
 	"github.com/opd-ai/whisp/platform/common"
 )
 
@@ -15,6 +15,14 @@ func (m *Manager) handleFileRecv(friendID, fileID, kind uint32, fileSize uint64,
 	common.SecurePrintf("Received file transfer request: friend=%d, fileID=%d, size=%d, name=%s",
 		friendID, fileID, fileSize, fileName)
 
+	if m.rejectBlocked(friendID, fileID) {
+		return
+	}
+	if kind == FileKindAvatar {
+		m.receiveAvatar(friendID, fileID, fileSize)
+		return
+	}
+
 	// Validate file size
 	if err := m.validateFileSize(fileSize); err != nil {
 		common.SecurePrintf("Rejecting file transfer: %v", err)
@@ -23,18 +31,20 @@ func (m *Manager) handleFileRecv(friendID, fileID, kind uint32, fileSize uint64,
 
 	// Create incoming transfer record
 	transfer := &Transfer{
-		ID:        uuid.New().String(),
-		FriendID:  friendID,
-		FileID:    fileID,
-		FileName:  fileName,
-		FileSize:  fileSize,
-		Direction: TransferDirectionIncoming,
-		State:     TransferStatePending,
-		StartTime: time.Now(),
+		ID:           uuid.New().String(),
+		FriendID:     friendID,
+		FileID:       fileID,
+		FileName:     fileName,
+		FileSize:     fileSize,
+		Direction:    TransferDirectionIncoming,
+		State:        TransferStatePending,
+		StartTime:    time.Now(),
+		VerifyChunks: kind == FileKindVerifiedData,
 	}
 
 	// Register transfer
 	m.mu.Lock()
+	transfer.ExpectedChecksum = m.takeExpectedChecksum(friendID, fileName, fileSize)
 	m.transfers[transfer.ID] = transfer
 	if m.toxTransfers[friendID] == nil {
 		m.toxTransfers[friendID] = make(map[uint32]*Transfer)
@@ -42,11 +52,17 @@ func (m *Manager) handleFileRecv(friendID, fileID, kind uint32, fileSize uint64,
 	m.toxTransfers[friendID][fileID] = transfer
 	m.mu.Unlock()
 
-	log.Printf("Created incoming transfer record: %s", transfer.ID)
+	logging.Debugf("Created incoming transfer record: %s", transfer.ID)
+
+	m.applyAutoAccept(transfer)
 }
 
 // handleFileRecvChunk handles incoming file data chunks from Tox
 func (m *Manager) handleFileRecvChunk(friendID, fileID uint32, position uint64, data []byte) {
+	if m.receiveAvatarChunk(friendID, fileID, position, data) {
+		return
+	}
+
 	// Find the transfer
 	m.mu.RLock()
 	transfers, exists := m.toxTransfers[friendID]
@@ -66,7 +82,8 @@ func (m *Manager) handleFileRecvChunk(friendID, fileID uint32, position uint64,
 	transfer.mu.Lock()
 	defer transfer.mu.Unlock()
 
-	if transfer.State != TransferStateActive {
+	// Chunks already in flight when we paused are still kept
+	if transfer.State != TransferStateActive && transfer.State != TransferStatePaused {
 		common.SecurePrintf("Transfer %s is not active, ignoring chunk", transfer.ID)
 		return
 	}
@@ -76,6 +93,16 @@ func (m *Manager) handleFileRecvChunk(friendID, fileID uint32, position uint64,
 		return
 	}
 
+	// Abort on the first corrupt chunk instead of discovering it at the end
+	if transfer.VerifyChunks && len(data) > 0 {
+		verified, err := openChunk(position, data)
+		if err != nil {
+			m.abortTransfer(transfer, &ChunkIntegrityError{TransferID: transfer.ID, Position: position})
+			return
+		}
+		data = verified
+	}
+
 	// Seek to the correct position
 	if _, err := transfer.file.Seek(int64(position), 0); err != nil {
 		common.SecurePrintf("Failed to seek to position %d in transfer %s: %v", position, transfer.ID, err)
@@ -88,31 +115,38 @@ func (m *Manager) handleFileRecvChunk(friendID, fileID uint32, position uint64,
 		return
 	}
 
-	// Update progress
-	transfer.BytesTransferred += uint64(len(data))
+	// Update progress; a chunk sent again after a resume is not counted twice
+	if end := position + uint64(len(data)); end > transfer.BytesTransferred {
+		transfer.BytesTransferred = end
+	}
 	if transfer.BytesTransferred >= transfer.FileSize {
-		transfer.State = TransferStateCompleted
-		transfer.file.Close()
-		transfer.file = nil
-		common.SecurePrintf("Transfer %s completed successfully", transfer.ID)
+		m.completeTransfer(transfer)
+	}
+
+	if transfer.onProgress != nil {
+		go transfer.onProgress(transfer)
 	}
 }
 
 // handleFileChunkRequest handles requests for file chunks from Tox (for outgoing transfers)
 func (m *Manager) handleFileChunkRequest(friendID, fileID uint32, position uint64, length int) {
+	if m.sendAvatarChunk(friendID, fileID, position, length) {
+		return
+	}
+
 	// Find the transfer
 	m.mu.RLock()
 	friendTransfers, exists := m.toxTransfers[friendID]
 	if !exists {
 		m.mu.RUnlock()
-		log.Printf("No transfers found for friend %d", friendID)
+		logging.Debugf("No transfers found for friend %d", friendID)
 		return
 	}
 
 	transfer, exists := friendTransfers[fileID]
 	if !exists {
 		m.mu.RUnlock()
-		log.Printf("No transfer found for friend %d, fileID %d", friendID, fileID)
+		logging.Debugf("No transfer found for friend %d, fileID %d", friendID, fileID)
 		return
 	}
 	m.mu.RUnlock()
@@ -122,13 +156,13 @@ func (m *Manager) handleFileChunkRequest(friendID, fileID uint32, position uint6
 
 	// Check if transfer is active
 	if transfer.State != TransferStateActive {
-		log.Printf("Transfer %s is not active, ignoring chunk request", transfer.ID)
+		logging.Debugf("Transfer %s is not active, ignoring chunk request", transfer.ID)
 		return
 	}
 
 	// Check if file is open for reading
 	if transfer.file == nil {
-		log.Printf("Transfer %s has no open file, ignoring chunk request", transfer.ID)
+		logging.Debugf("Transfer %s has no open file, ignoring chunk request", transfer.ID)
 		return
 	}
 
@@ -138,28 +172,64 @@ func (m *Manager) handleFileChunkRequest(friendID, fileID uint32, position uint6
 		return
 	}
 
+	// Over the rate limit, the chunk is sent once the budget allows so the
+	// Tox loop is never blocked
+	if delay := m.limiter.reserve(length, time.Now()); delay > 0 {
+		time.AfterFunc(delay, func() {
+			transfer.mu.Lock()
+			defer transfer.mu.Unlock()
+			if transfer.State == TransferStateActive && transfer.file != nil {
+				m.sendChunk(m.toxMgr, transfer, position, length)
+			}
+		})
+		return
+	}
+
+	m.sendChunk(m.toxMgr, transfer, position, length)
+}
+
+// sendChunk reads up to length bytes at position and sends them to the peer.
+// The caller must hold the transfer's lock.
+func (m *Manager) sendChunk(toxMgr ToxManager, transfer *Transfer, position uint64, length int) {
 	// Seek to position
 	if _, err := transfer.file.Seek(int64(position), io.SeekStart); err != nil {
-		log.Printf("Failed to seek to position %d in transfer %s: %v", position, transfer.ID, err)
+		logging.Errorf("Failed to seek to position %d in transfer %s: %v", position, transfer.ID, err)
 		transfer.State = TransferStateFailed
+		m.transferEnded()
 		return
 	}
 
+	// Leave room for the digest so the sealed chunk still fits the requested length
+	if transfer.VerifyChunks {
+		if length <= chunkDigestSize {
+			logging.Warnf("Chunk request of %d bytes too small for verified transfer %s", length, transfer.ID)
+			transfer.State = TransferStateFailed
+			m.transferEnded()
+			return
+		}
+		length -= chunkDigestSize
+	}
+
 	// Read data
 	data := make([]byte, length)
 	bytesRead, err := transfer.file.Read(data)
 	if err != nil && err != io.EOF {
-		log.Printf("Failed to read data for transfer %s: %v", transfer.ID, err)
+		logging.Errorf("Failed to read data for transfer %s: %v", transfer.ID, err)
 		transfer.State = TransferStateFailed
+		m.transferEnded()
 		return
 	}
 
-	// Send chunk via Tox (this requires access to ToxManager)
-	// We'll need to store the ToxManager reference in the Manager
-	if m.toxMgr != nil {
-		if err := m.toxMgr.FileSendChunk(friendID, fileID, position, data[:bytesRead]); err != nil {
-			log.Printf("Failed to send chunk for transfer %s: %v", transfer.ID, err)
+	// Send chunk via Tox
+	if toxMgr != nil {
+		chunk := data[:bytesRead]
+		if transfer.VerifyChunks {
+			chunk = sealChunk(position, chunk)
+		}
+		if err := toxMgr.FileSendChunk(transfer.FriendID, transfer.FileID, position, chunk); err != nil {
+			logging.Errorf("Failed to send chunk for transfer %s: %v", transfer.ID, err)
 			transfer.State = TransferStateFailed
+			m.transferEnded()
 			return
 		}
 	}
@@ -181,24 +251,29 @@ func (m *Manager) completeTransfer(transfer *Transfer) {
 		transfer.file = nil
 	}
 
+	// Update state
+	transfer.State = TransferStateCompleted
+	now := time.Now()
+	transfer.EndTime = &now
+	m.transferEnded()
+
 	// Verify checksum for incoming files
 	if transfer.Direction == TransferDirectionIncoming && transfer.FilePath != "" {
 		if checksum, err := computeFileChecksum(transfer.FilePath); err == nil {
 			transfer.FileChecksum = checksum
+			if !m.verifyReceivedFile(transfer) {
+				return
+			}
+			m.storeReceivedFile(transfer)
 		}
 	}
 
-	// Update state
-	transfer.State = TransferStateCompleted
-	now := time.Now()
-	transfer.EndTime = &now
-
 	// Call completion callback if set
 	if transfer.onComplete != nil {
 		go transfer.onComplete(transfer, nil)
 	}
 
-	log.Printf("Transfer %s completed successfully", transfer.ID)
+	logging.Infof("Transfer %s completed successfully", transfer.ID)
 }
 
 // SetProgressCallback sets a progress callback for a transfer
@@ -5,6 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore"
 )
 
 func TestPauseTransfer(t *testing.T) {
@@ -389,3 +392,120 @@ func TestTransferControlErrors(t *testing.T) {
 		t.Error("Expected error when cancelling completed transfer")
 	}
 }
+
+func TestPauseResumeOutgoing(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+
+	content := make([]byte, 3000)
+	for i := range content {
+		content[i] = byte(i)
+	}
+	testFile := filepath.Join(tempDir, "large.bin")
+	if err := os.WriteFile(testFile, content, 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	var events []string
+	mockTox := &MockToxManager{
+		fileControlFunc: func(friendID, fileID uint32, control toxcore.FileControl) error {
+			events = append(events, fmt.Sprintf("control:%v", control))
+			return nil
+		},
+		fileSendChunkFunc: func(friendID, fileID uint32, position uint64, data []byte) error {
+			events = append(events, fmt.Sprintf("chunk:%d+%d", position, len(data)))
+			return nil
+		},
+	}
+	manager.SetToxManager(mockTox)
+
+	transfer, err := manager.SendFile(1, testFile)
+	if err != nil {
+		t.Fatalf("Failed to create transfer: %v", err)
+	}
+	if err := manager.StartSend(transfer, mockTox); err != nil {
+		t.Fatalf("Failed to start transfer: %v", err)
+	}
+
+	progress := make(chan struct{}, 10)
+	if err := manager.SetProgressCallback(transfer.ID, func(*Transfer) {
+		progress <- struct{}{}
+	}); err != nil {
+		t.Fatalf("Failed to set progress callback: %v", err)
+	}
+
+	mockTox.TriggerFileChunkRequest(1, transfer.FileID, 0, 1000)
+	if err := manager.PauseTransfer(transfer.ID, mockTox); err != nil {
+		t.Fatalf("Failed to pause transfer: %v", err)
+	}
+
+	// Requests arriving while paused are dropped
+	mockTox.TriggerFileChunkRequest(1, transfer.FileID, 1000, 1000)
+	if transfer.BytesTransferred != 1000 {
+		t.Errorf("Expected 1000 bytes sent before the pause, got %d", transfer.BytesTransferred)
+	}
+
+	// Resuming sends the next chunk from where the transfer stopped
+	if err := manager.ResumeTransfer(transfer.ID, mockTox); err != nil {
+		t.Fatalf("Failed to resume transfer: %v", err)
+	}
+	mockTox.TriggerFileChunkRequest(1, transfer.FileID, 2024, 1000)
+
+	pause, resume := fmt.Sprintf("control:%v", toxcore.FileControlPause), fmt.Sprintf("control:%v", toxcore.FileControlResume)
+	want := []string{"chunk:0+1000", pause, resume, "chunk:1000+1024", "chunk:2024+976"}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("Expected events %v, got %v", want, events)
+	}
+	if transfer.BytesTransferred != transfer.FileSize {
+		t.Errorf("Expected all %d bytes sent, got %d", transfer.FileSize, transfer.BytesTransferred)
+	}
+
+	// Progress is reported for every chunk sent and for the pause
+	for i := 0; i < 4; i++ {
+		select {
+		case <-progress:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected 4 progress callbacks, got %d", i)
+		}
+	}
+}
+
+func TestPauseResumeIncoming(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+	mockTox := &MockToxManager{}
+	manager.SetToxManager(mockTox)
+
+	mockTox.TriggerFileRecv(2, 5, 0, 10, "in.txt")
+	transfer := manager.GetTransfersByFriend(2)[0]
+	if err := manager.AcceptIncomingFile(transfer.ID, filepath.Join(tempDir, "downloads")); err != nil {
+		t.Fatalf("Failed to accept incoming file: %v", err)
+	}
+
+	mockTox.TriggerFileRecvChunk(2, 5, 0, []byte("01234"))
+	if err := manager.PauseTransfer(transfer.ID, mockTox); err != nil {
+		t.Fatalf("Failed to pause transfer: %v", err)
+	}
+
+	// A chunk already in flight is kept, and one sent again is not counted twice
+	mockTox.TriggerFileRecvChunk(2, 5, 5, []byte("567"))
+	if err := manager.ResumeTransfer(transfer.ID, mockTox); err != nil {
+		t.Fatalf("Failed to resume transfer: %v", err)
+	}
+	mockTox.TriggerFileRecvChunk(2, 5, 5, []byte("567"))
+	if transfer.BytesTransferred != 8 || transfer.State != TransferStateActive {
+		t.Errorf("Expected 8 bytes and an active transfer, got %d in state %v", transfer.BytesTransferred, transfer.State)
+	}
+
+	mockTox.TriggerFileRecvChunk(2, 5, 8, []byte("89"))
+	data, err := os.ReadFile(transfer.FilePath)
+	if err != nil || string(data) != "0123456789" || transfer.State != TransferStateCompleted {
+		t.Errorf("Expected the complete file, got %q in state %v (%v)", data, transfer.State, err)
+	}
+}
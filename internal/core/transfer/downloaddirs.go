@@ -0,0 +1,25 @@
+package transfer
+
+// SetDownloadDirs routes accepted files to a folder per media type. mediaType
+// names a file's type, as media.Manager.GetMediaTypeString does, and dirFor
+// returns the folder for a type, or "" to save such files where they were
+// accepted. The folders are read for every file, so settings changes apply
+// to the next transfer.
+func (m *Manager) SetDownloadDirs(mediaType func(path string) string, dirFor func(mediaType string) string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mediaType = mediaType
+	m.downloadDirFor = dirFor
+}
+
+// typedDownloadDir returns the folder for a file of fileName's media type,
+// falling back to saveDir
+func typedDownloadDir(fileName, saveDir string, mediaType func(path string) string, dirFor func(mediaType string) string) string {
+	if mediaType == nil || dirFor == nil {
+		return saveDir
+	}
+	if dir := dirFor(mediaType(fileName)); dir != "" {
+		return dir
+	}
+	return saveDir
+}
@@ -0,0 +1,65 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/whisp/internal/core/media"
+)
+
+func TestAcceptIncomingFileTypedDownloadDirs(t *testing.T) {
+	tempDir := t.TempDir()
+	defaultDir := filepath.Join(tempDir, "Downloads")
+	dirs := map[string]string{
+		"image": filepath.Join(tempDir, "Pictures"),
+		"video": filepath.Join(tempDir, "Videos"),
+	}
+
+	tests := []struct {
+		name     string
+		fileName string
+		wantDir  string
+	}{
+		{"image", "cat.png", dirs["image"]},
+		{"upper-case extension", "CAT.PNG", dirs["image"]},
+		{"video", "clip.mp4", dirs["video"]},
+		{"type without a folder", "song.mp3", defaultDir},
+		{"unknown type", "notes.xyz", defaultDir},
+	}
+
+	manager, err := NewManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	mockTox := &MockToxManager{}
+	manager.SetToxManager(mockTox)
+	manager.SetDownloadDirs(media.NewManager(t.TempDir()).GetMediaTypeString, func(mediaType string) string {
+		return dirs[mediaType]
+	})
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTox.TriggerFileRecv(4, uint32(i), 0, 10, tt.fileName)
+			var incoming *Transfer
+			for _, transfer := range manager.GetTransfersByFriend(4) {
+				if transfer.FileName == tt.fileName {
+					incoming = transfer
+				}
+			}
+			if incoming == nil {
+				t.Fatalf("Expected a transfer for %s", tt.fileName)
+			}
+
+			if err := manager.AcceptIncomingFile(incoming.ID, defaultDir); err != nil {
+				t.Fatalf("AcceptIncomingFile failed: %v", err)
+			}
+			if got := filepath.Dir(incoming.FilePath); got != tt.wantDir {
+				t.Errorf("Expected %s to be saved in %s, got %s", tt.fileName, tt.wantDir, got)
+			}
+			if _, err := os.Stat(incoming.FilePath); err != nil {
+				t.Errorf("Expected the folder to be created on demand: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// FileStore keeps one copy of each received file's content and counts the
+// references to it
+type FileStore interface {
+	// StoreFile moves a file with the given SHA-256 checksum into dir and
+	// returns where it now is
+	StoreFile(srcPath, checksum, dir string) (string, error)
+
+	// ReleaseFile drops a reference, removing the file when none are left
+	ReleaseFile(path string) error
+}
+
+// SetFileStore sets the store verified incoming files are moved into
+func (m *Manager) SetFileStore(store FileStore) {
+	m.fileStoreMu.Lock()
+	defer m.fileStoreMu.Unlock()
+	m.fileStore = store
+}
+
+// FileStoreDir returns the directory received files are stored in
+func (m *Manager) FileStoreDir() string {
+	return filepath.Join(m.transfersDir, "files")
+}
+
+// storeReceivedFile moves a verified incoming file into the file store. If
+// that fails the file stays where it was received. Must be called with
+// transfer.mu held.
+func (m *Manager) storeReceivedFile(transfer *Transfer) {
+	m.fileStoreMu.RLock()
+	store := m.fileStore
+	m.fileStoreMu.RUnlock()
+
+	if store == nil {
+		return
+	}
+	path, err := store.StoreFile(transfer.FilePath, transfer.FileChecksum, m.FileStoreDir())
+	if err != nil {
+		logging.Warnf("Failed to store received file %s: %v", transfer.FilePath, err)
+		return
+	}
+	transfer.FilePath = path
+}
+
+// removeReceivedFile deletes a received file, through the file store so a
+// copy other transfers share is kept
+func (m *Manager) removeReceivedFile(path string) error {
+	m.fileStoreMu.RLock()
+	store := m.fileStore
+	m.fileStoreMu.RUnlock()
+
+	if store != nil {
+		return store.ReleaseFile(path)
+	}
+	return os.Remove(path)
+}
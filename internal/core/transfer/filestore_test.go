@@ -0,0 +1,85 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mockFileStore moves files into its directory and records releases
+type mockFileStore struct {
+	released []string
+}
+
+func (s *mockFileStore) StoreFile(srcPath, checksum, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, checksum)
+	return path, os.Rename(srcPath, path)
+}
+
+func (s *mockFileStore) ReleaseFile(path string) error {
+	s.released = append(s.released, path)
+	return os.Remove(path)
+}
+
+func TestReceivedFileIsStored(t *testing.T) {
+	content := []byte("Hello, stored world!")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name         string
+		lateChecksum string
+		wantStored   bool
+	}{
+		{"stored", "", true},
+		{"released on a late mismatch", hex.EncodeToString(make([]byte, sha256.Size)), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			receiver, err := NewManager(tempDir)
+			if err != nil {
+				t.Fatalf("Failed to create receiver: %v", err)
+			}
+			mockTox := &MockToxManager{}
+			receiver.SetToxManager(mockTox)
+			store := &mockFileStore{}
+			receiver.SetFileStore(store)
+
+			mockTox.TriggerFileRecv(3, 1, 0, uint64(len(content)), "hello.txt")
+			incoming := receiver.GetTransfersByFriend(3)[0]
+			saveDir := filepath.Join(tempDir, "downloads")
+			if err := receiver.AcceptIncomingFile(incoming.ID, saveDir); err != nil {
+				t.Fatalf("AcceptIncomingFile failed: %v", err)
+			}
+			mockTox.TriggerFileRecvChunk(3, 1, 0, content)
+			if tt.lateChecksum != "" {
+				receiver.SetExpectedChecksum(3, "hello.txt", uint64(len(content)), tt.lateChecksum)
+			}
+
+			incoming.mu.RLock()
+			filePath := incoming.FilePath
+			incoming.mu.RUnlock()
+
+			stored := filepath.Join(receiver.FileStoreDir(), checksum)
+			if filePath != stored {
+				t.Errorf("Expected the transfer to point at %s, got %s", stored, filePath)
+			}
+			if _, err := os.Stat(filepath.Join(saveDir, "hello.txt")); !os.IsNotExist(err) {
+				t.Error("Expected the received file to be moved into the store")
+			}
+			if _, err := os.Stat(stored); (err == nil) != tt.wantStored {
+				t.Errorf("Expected stored file kept=%v, stat error %v", tt.wantStored, err)
+			}
+			if wantReleased := !tt.wantStored; (len(store.released) == 1) != wantReleased {
+				t.Errorf("Expected released=%v, got %v", wantReleased, store.released)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// FileKindVerifiedData marks a transfer whose chunks each carry a digest.
+// Tox treats unknown kinds like plain data, so the kind doubles as the signal
+// that the receiver must strip and verify the digests.
+const FileKindVerifiedData uint32 = 0x57500001
+
+// chunkDigestSize is the number of digest bytes appended to each verified chunk
+const chunkDigestSize = 16
+
+// ErrChunkIntegrity is returned when a received chunk does not match its digest
+var ErrChunkIntegrity = errors.New("chunk integrity check failed")
+
+// ChunkIntegrityError reports the chunk at which a verified transfer was aborted
+type ChunkIntegrityError struct {
+	TransferID string
+	Position   uint64
+}
+
+func (e *ChunkIntegrityError) Error() string {
+	return fmt.Sprintf("transfer %s: %v at position %d", e.TransferID, ErrChunkIntegrity, e.Position)
+}
+
+// Unwrap allows errors.Is(err, ErrChunkIntegrity)
+func (e *ChunkIntegrityError) Unwrap() error {
+	return ErrChunkIntegrity
+}
+
+// ErrChecksumMismatch is returned when a received file does not match the
+// checksum its sender announced
+var ErrChecksumMismatch = errors.New("file checksum mismatch")
+
+// ChecksumMismatchError reports a received file whose SHA-256 checksum differs
+// from the sender's
+type ChecksumMismatchError struct {
+	TransferID string
+	Expected   string
+	Actual     string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("transfer %s: %v: expected %s, got %s", e.TransferID, ErrChecksumMismatch, e.Expected, e.Actual)
+}
+
+// Unwrap allows errors.Is(err, ErrChecksumMismatch)
+func (e *ChecksumMismatchError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+// checksumKey identifies an incoming file by what both sides know about it;
+// Tox file numbers are local to each side
+type checksumKey struct {
+	friendID uint32
+	fileName string
+	fileSize uint64
+}
+
+// SetOnSendStarted sets the callback invoked once an outgoing transfer has
+// been offered to the friend, used to send them the file's checksum
+func (m *Manager) SetOnSendStarted(callback func(transfer *Transfer)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onSendStarted = callback
+}
+
+// SetExpectedChecksum records the SHA-256 checksum a friend announced for a
+// file they send. The checksum may arrive before or after the file offer; a
+// file that already finished is verified straight away.
+func (m *Manager) SetExpectedChecksum(friendID uint32, fileName string, fileSize uint64, checksum string) {
+	checksum = strings.ToLower(checksum)
+
+	m.mu.Lock()
+	var match *Transfer
+	for _, transfer := range m.toxTransfers[friendID] {
+		transfer.mu.RLock()
+		waiting := transfer.Direction == TransferDirectionIncoming && transfer.ExpectedChecksum == "" &&
+			transfer.FileName == fileName && transfer.FileSize == fileSize &&
+			transfer.State != TransferStateFailed && transfer.State != TransferStateCancelled
+		transfer.mu.RUnlock()
+		if waiting {
+			match = transfer
+			break
+		}
+	}
+	if match == nil {
+		m.expectedChecksums[checksumKey{friendID, fileName, fileSize}] = checksum
+		m.mu.Unlock()
+		return
+	}
+	m.mu.Unlock()
+
+	match.mu.Lock()
+	defer match.mu.Unlock()
+	match.ExpectedChecksum = checksum
+	if match.State == TransferStateCompleted {
+		m.verifyReceivedFile(match)
+	}
+}
+
+// takeExpectedChecksum returns and forgets the checksum announced for a file.
+// Must be called with m.mu held.
+func (m *Manager) takeExpectedChecksum(friendID uint32, fileName string, fileSize uint64) string {
+	key := checksumKey{friendID, fileName, fileSize}
+	checksum := m.expectedChecksums[key]
+	delete(m.expectedChecksums, key)
+	return checksum
+}
+
+// verifyReceivedFile compares a finished incoming file with the checksum its
+// sender announced and discards it on a mismatch. It reports whether the file
+// is good, which it is when no checksum was announced. Must be called with
+// transfer.mu held.
+func (m *Manager) verifyReceivedFile(transfer *Transfer) bool {
+	if transfer.ExpectedChecksum == "" || transfer.FileChecksum == transfer.ExpectedChecksum {
+		return true
+	}
+
+	reason := &ChecksumMismatchError{
+		TransferID: transfer.ID,
+		Expected:   transfer.ExpectedChecksum,
+		Actual:     transfer.FileChecksum,
+	}
+	if transfer.FilePath != "" {
+		if err := m.removeReceivedFile(transfer.FilePath); err != nil {
+			logging.Warnf("Failed to remove corrupt file %s: %v", transfer.FilePath, err)
+		}
+	}
+
+	transfer.State = TransferStateFailed
+	transfer.Err = reason
+	if transfer.onComplete != nil {
+		go transfer.onComplete(transfer, reason)
+	}
+
+	logging.Warnf("Transfer %s failed verification: %v", transfer.ID, reason)
+	return false
+}
+
+// SetChunkVerification enables per-chunk digests for transfers started afterwards
+func (m *Manager) SetChunkVerification(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.verifyChunks = enabled
+}
+
+// ChunkVerificationEnabled reports whether new outgoing transfers use per-chunk digests
+func (m *Manager) ChunkVerificationEnabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.verifyChunks
+}
+
+// chunkDigest hashes a chunk together with its position so misplaced data is detected too
+func chunkDigest(position uint64, data []byte) []byte {
+	var pos [8]byte
+	binary.BigEndian.PutUint64(pos[:], position)
+
+	hash := sha256.New()
+	hash.Write(pos[:])
+	hash.Write(data)
+	return hash.Sum(nil)[:chunkDigestSize]
+}
+
+// sealChunk appends the digest of a chunk to its data
+func sealChunk(position uint64, data []byte) []byte {
+	sealed := make([]byte, 0, len(data)+chunkDigestSize)
+	sealed = append(sealed, data...)
+	return append(sealed, chunkDigest(position, data)...)
+}
+
+// openChunk verifies a sealed chunk and returns its data
+func openChunk(position uint64, payload []byte) ([]byte, error) {
+	if len(payload) < chunkDigestSize {
+		return nil, ErrChunkIntegrity
+	}
+
+	data := payload[:len(payload)-chunkDigestSize]
+	digest := payload[len(payload)-chunkDigestSize:]
+	if string(digest) != string(chunkDigest(position, data)) {
+		return nil, ErrChunkIntegrity
+	}
+
+	return data, nil
+}
+
+// abortTransfer cancels a transfer on both sides and discards partial data.
+// Must be called with transfer.mu held.
+func (m *Manager) abortTransfer(transfer *Transfer, reason error) {
+	if m.toxMgr != nil {
+		if err := m.toxMgr.FileControl(transfer.FriendID, transfer.FileID, toxcore.FileControlCancel); err != nil {
+			logging.Warnf("Failed to cancel transfer %s via Tox: %v", transfer.ID, err)
+		}
+	}
+
+	if transfer.file != nil {
+		transfer.file.Close()
+		transfer.file = nil
+	}
+
+	if transfer.Direction == TransferDirectionIncoming && transfer.FilePath != "" {
+		os.Remove(transfer.FilePath)
+	}
+
+	transfer.State = TransferStateFailed
+	transfer.Err = reason
+	now := time.Now()
+	transfer.EndTime = &now
+	m.transferEnded()
+
+	if transfer.onComplete != nil {
+		go transfer.onComplete(transfer, reason)
+	}
+
+	logging.Warnf("Transfer %s aborted: %v", transfer.ID, reason)
+}
@@ -0,0 +1,301 @@
+package transfer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+)
+
+func TestChunkSealing(t *testing.T) {
+	data := []byte("chunk data")
+	sealed := sealChunk(42, data)
+
+	if len(sealed) != len(data)+chunkDigestSize {
+		t.Fatalf("Expected sealed length %d, got %d", len(data)+chunkDigestSize, len(sealed))
+	}
+
+	opened, err := openChunk(42, sealed)
+	if err != nil {
+		t.Fatalf("openChunk failed: %v", err)
+	}
+	if !bytes.Equal(opened, data) {
+		t.Errorf("Expected %q, got %q", data, opened)
+	}
+
+	if _, err := openChunk(43, sealed); !errors.Is(err, ErrChunkIntegrity) {
+		t.Error("Expected chunk at the wrong position to fail verification")
+	}
+	if _, err := openChunk(0, []byte("short")); !errors.Is(err, ErrChunkIntegrity) {
+		t.Error("Expected truncated chunk to fail verification")
+	}
+}
+
+func TestVerifiedTransferAbortsOnCorruptChunk(t *testing.T) {
+	const chunkSize = 64
+
+	tempDir := t.TempDir()
+	content := bytes.Repeat([]byte("0123456789abcdef"), 16) // 256 bytes, four chunks
+	sourcePath := filepath.Join(tempDir, "large.bin")
+	if err := os.WriteFile(sourcePath, content, 0o644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	// Sender side: capture the chunks it would put on the wire
+	sender, err := NewManager(filepath.Join(tempDir, "sender"))
+	if err != nil {
+		t.Fatalf("Failed to create sender: %v", err)
+	}
+	sender.SetChunkVerification(true)
+
+	var sentKind uint32
+	var wire [][]byte
+	senderTox := &MockToxManager{
+		fileSendFunc: func(friendID, kind uint32, fileSize uint64, fileID [32]byte, fileName string) (uint32, error) {
+			sentKind = kind
+			return 7, nil
+		},
+		fileSendChunkFunc: func(friendID, fileID uint32, position uint64, data []byte) error {
+			if len(data) > chunkSize {
+				t.Errorf("Sealed chunk of %d bytes exceeds requested length %d", len(data), chunkSize)
+			}
+			wire = append(wire, append([]byte(nil), data...))
+			return nil
+		},
+	}
+	sender.SetToxManager(senderTox)
+
+	outgoing, err := sender.SendFile(1, sourcePath)
+	if err != nil {
+		t.Fatalf("SendFile failed: %v", err)
+	}
+	if err := sender.StartSend(outgoing, senderTox); err != nil {
+		t.Fatalf("StartSend failed: %v", err)
+	}
+	if sentKind != FileKindVerifiedData {
+		t.Fatalf("Expected verified file kind, got %d", sentKind)
+	}
+
+	var positions []uint64
+	for position := uint64(0); position < uint64(len(content)); position += chunkSize - chunkDigestSize {
+		senderTox.TriggerFileChunkRequest(1, 7, position, chunkSize)
+		positions = append(positions, position)
+	}
+
+	// Receiver side: the third chunk is corrupted in transit
+	receiver, err := NewManager(filepath.Join(tempDir, "receiver"))
+	if err != nil {
+		t.Fatalf("Failed to create receiver: %v", err)
+	}
+
+	var cancelled bool
+	receiverTox := &MockToxManager{
+		fileControlFunc: func(friendID, fileID uint32, control toxcore.FileControl) error {
+			cancelled = control == toxcore.FileControlCancel
+			return nil
+		},
+	}
+	receiver.SetToxManager(receiverTox)
+
+	receiverTox.TriggerFileRecv(2, 9, FileKindVerifiedData, uint64(len(content)), "large.bin")
+	incoming := receiver.GetTransfersByFriend(2)[0]
+	if !incoming.VerifyChunks {
+		t.Fatal("Expected incoming transfer to verify chunks")
+	}
+
+	saveDir := filepath.Join(tempDir, "downloads")
+	if err := receiver.AcceptIncomingFile(incoming.ID, saveDir); err != nil {
+		t.Fatalf("AcceptIncomingFile failed: %v", err)
+	}
+
+	const corruptIndex = 2
+	wire[corruptIndex][0] ^= 0xFF
+
+	for i, chunk := range wire {
+		receiverTox.TriggerFileRecvChunk(2, 9, positions[i], chunk)
+	}
+
+	if incoming.State != TransferStateFailed {
+		t.Fatalf("Expected transfer to fail, got state %d", incoming.State)
+	}
+
+	var integrityErr *ChunkIntegrityError
+	if !errors.As(incoming.Err, &integrityErr) || !errors.Is(incoming.Err, ErrChunkIntegrity) {
+		t.Fatalf("Expected chunk integrity error, got %v", incoming.Err)
+	}
+	if integrityErr.Position != positions[corruptIndex] {
+		t.Errorf("Expected abort at position %d, got %d", positions[corruptIndex], integrityErr.Position)
+	}
+
+	// Only the chunks before the corrupt one were accepted
+	expectedBytes := positions[corruptIndex]
+	if incoming.BytesTransferred != expectedBytes {
+		t.Errorf("Expected %d bytes accepted before abort, got %d", expectedBytes, incoming.BytesTransferred)
+	}
+
+	if !cancelled {
+		t.Error("Expected the sender to be told to cancel")
+	}
+	if _, err := os.Stat(filepath.Join(saveDir, "large.bin")); !os.IsNotExist(err) {
+		t.Error("Expected partial file to be removed")
+	}
+}
+
+func TestVerifiedTransferCompletes(t *testing.T) {
+	tempDir := t.TempDir()
+	receiver, err := NewManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create receiver: %v", err)
+	}
+
+	mockTox := &MockToxManager{}
+	receiver.SetToxManager(mockTox)
+
+	content := []byte("Hello, verified world!")
+	mockTox.TriggerFileRecv(3, 1, FileKindVerifiedData, uint64(len(content)), "hello.txt")
+	incoming := receiver.GetTransfersByFriend(3)[0]
+
+	saveDir := filepath.Join(tempDir, "downloads")
+	if err := receiver.AcceptIncomingFile(incoming.ID, saveDir); err != nil {
+		t.Fatalf("AcceptIncomingFile failed: %v", err)
+	}
+
+	mockTox.TriggerFileRecvChunk(3, 1, 0, sealChunk(0, content[:10]))
+	mockTox.TriggerFileRecvChunk(3, 1, 10, sealChunk(10, content[10:]))
+
+	if incoming.State != TransferStateCompleted {
+		t.Fatalf("Expected transfer to complete, got state %d (%v)", incoming.State, incoming.Err)
+	}
+
+	written, err := os.ReadFile(filepath.Join(saveDir, "hello.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read received file: %v", err)
+	}
+	if !bytes.Equal(written, content) {
+		t.Errorf("Expected digests to be stripped, got %q", written)
+	}
+}
+
+func TestReceivedFileChecksum(t *testing.T) {
+	content := []byte("Hello, checksummed world!")
+	sum := sha256.Sum256(content)
+	good := hex.EncodeToString(sum[:])
+	bad := hex.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name          string
+		checksum      string
+		beforeOffer   bool
+		wantMatch     bool
+		wantCompleted bool
+	}{
+		{"no checksum", "", false, true, true},
+		{"match before offer", good, true, true, true},
+		{"match after completion", good, false, true, true},
+		{"upper-case match", strings.ToUpper(good), true, true, true},
+		{"mismatch before offer", bad, true, false, false},
+		{"mismatch after completion", bad, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			receiver, err := NewManager(tempDir)
+			if err != nil {
+				t.Fatalf("Failed to create receiver: %v", err)
+			}
+			mockTox := &MockToxManager{}
+			receiver.SetToxManager(mockTox)
+
+			if tt.beforeOffer && tt.checksum != "" {
+				receiver.SetExpectedChecksum(3, "hello.txt", uint64(len(content)), tt.checksum)
+			}
+
+			mockTox.TriggerFileRecv(3, 1, 0, uint64(len(content)), "hello.txt")
+			incoming := receiver.GetTransfersByFriend(3)[0]
+
+			done := make(chan error, 1)
+			if err := receiver.SetCompletionCallback(incoming.ID, func(_ *Transfer, err error) { done <- err }); err != nil {
+				t.Fatalf("SetCompletionCallback failed: %v", err)
+			}
+			saveDir := filepath.Join(tempDir, "downloads")
+			if err := receiver.AcceptIncomingFile(incoming.ID, saveDir); err != nil {
+				t.Fatalf("AcceptIncomingFile failed: %v", err)
+			}
+			mockTox.TriggerFileRecvChunk(3, 1, 0, content)
+
+			if !tt.beforeOffer && tt.checksum != "" {
+				// The file finished before the checksum arrived
+				if err := waitCompletion(t, done); err != nil {
+					t.Fatalf("Expected completion before the checksum arrived, got %v", err)
+				}
+				receiver.SetExpectedChecksum(3, "hello.txt", uint64(len(content)), tt.checksum)
+			}
+
+			if tt.beforeOffer || tt.checksum == "" || !tt.wantMatch {
+				err = waitCompletion(t, done)
+				if tt.wantMatch && err != nil {
+					t.Fatalf("Expected success, got %v", err)
+				}
+				if !tt.wantMatch && !errors.Is(err, ErrChecksumMismatch) {
+					t.Fatalf("Expected a checksum mismatch, got %v", err)
+				}
+			}
+
+			incoming.mu.RLock()
+			state, filePath := incoming.State, incoming.FilePath
+			incoming.mu.RUnlock()
+			if (state == TransferStateCompleted) != tt.wantCompleted {
+				t.Errorf("Unexpected state %d", state)
+			}
+			if _, err := os.Stat(filePath); (err == nil) != tt.wantMatch {
+				t.Errorf("Expected file kept=%v, stat error %v", tt.wantMatch, err)
+			}
+		})
+	}
+}
+
+func TestSendStartedCallback(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+
+	testFile := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("content"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	transfer, err := manager.SendFile(3, testFile)
+	if err != nil {
+		t.Fatalf("Failed to create transfer: %v", err)
+	}
+
+	var started *Transfer
+	manager.SetOnSendStarted(func(t *Transfer) { started = t })
+	if err := manager.StartSend(transfer, &MockToxManager{}); err != nil {
+		t.Fatalf("StartSend failed: %v", err)
+	}
+	if started != transfer || started.FileChecksum == "" {
+		t.Errorf("Expected the started transfer with its checksum, got %+v", started)
+	}
+}
+
+// waitCompletion waits for a transfer's completion callback
+func waitCompletion(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for completion")
+		return nil
+	}
+}
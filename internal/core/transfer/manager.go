@@ -76,12 +76,36 @@ func (m *Manager) SendFile(friendID uint32, filePath string) (*Transfer, error)
 	return transfer, nil
 }
 
-// StartSend begins the actual file transfer via Tox
+// StartSend begins the actual file transfer via Tox, or queues it while the
+// upload limit is reached
 func (m *Manager) StartSend(transfer *Transfer, toxMgr ToxManager) error {
 	if transfer.Direction != TransferDirectionOutgoing {
 		return fmt.Errorf("transfer %s is not an outgoing transfer", transfer.ID)
 	}
 
+	m.schedMu.Lock()
+	defer m.schedMu.Unlock()
+
+	if !m.hasSlot(TransferDirectionOutgoing) {
+		transfer.mu.Lock()
+		defer transfer.mu.Unlock()
+		if transfer.State != TransferStatePending {
+			return fmt.Errorf("transfer %s is not in pending state", transfer.ID)
+		}
+		m.enqueue(queuedTransfer{transfer: transfer, toxMgr: toxMgr})
+		return nil
+	}
+	return m.offerFile(transfer, toxMgr)
+}
+
+// offerFile offers a pending outgoing file to the friend
+func (m *Manager) offerFile(transfer *Transfer, toxMgr ToxManager) error {
+	verifyChunks := m.ChunkVerificationEnabled()
+	kind := uint32(0)
+	if verifyChunks {
+		kind = FileKindVerifiedData
+	}
+
 	transfer.mu.Lock()
 	if transfer.State != TransferStatePending {
 		transfer.mu.Unlock()
@@ -93,7 +117,7 @@ func (m *Manager) StartSend(transfer *Transfer, toxMgr ToxManager) error {
 	copy(fileID[:], transfer.ID[:32])
 
 	// Initiate Tox file transfer
-	toxFileID, err := toxMgr.FileSend(transfer.FriendID, 0, transfer.FileSize, fileID, transfer.FileName)
+	toxFileID, err := toxMgr.FileSend(transfer.FriendID, kind, transfer.FileSize, fileID, transfer.FileName)
 	if err != nil {
 		transfer.State = TransferStateFailed
 		transfer.mu.Unlock()
@@ -101,7 +125,9 @@ func (m *Manager) StartSend(transfer *Transfer, toxMgr ToxManager) error {
 	}
 
 	transfer.FileID = toxFileID
+	transfer.VerifyChunks = verifyChunks
 	transfer.State = TransferStateActive
+	onProgress := transfer.onProgress
 	transfer.mu.Unlock()
 
 	// Register with Tox transfer tracking
@@ -110,21 +136,37 @@ func (m *Manager) StartSend(transfer *Transfer, toxMgr ToxManager) error {
 		m.toxTransfers[transfer.FriendID] = make(map[uint32]*Transfer)
 	}
 	m.toxTransfers[transfer.FriendID][toxFileID] = transfer
+	onSendStarted := m.onSendStarted
 	m.mu.Unlock()
 
+	if onSendStarted != nil {
+		onSendStarted(transfer)
+	}
+	if onProgress != nil {
+		// Shows a transfer that waited in the queue as started
+		go onProgress(transfer)
+	}
+
 	return nil
 }
 
-// AcceptIncomingFile accepts an incoming file transfer
+// AcceptIncomingFile accepts an incoming file transfer, saving it in saveDir
+// unless a folder is set for its media type. The download is queued while
+// the download limit is reached.
 func (m *Manager) AcceptIncomingFile(transferID, saveDir string) error {
 	m.mu.RLock()
 	transfer, exists := m.transfers[transferID]
+	mediaType, downloadDirFor := m.mediaType, m.downloadDirFor
 	m.mu.RUnlock()
 
 	if !exists {
 		return fmt.Errorf("transfer %s not found", transferID)
 	}
 
+	m.schedMu.Lock()
+	defer m.schedMu.Unlock()
+	queue := !m.hasSlot(TransferDirectionIncoming)
+
 	transfer.mu.Lock()
 	defer transfer.mu.Unlock()
 
@@ -147,9 +189,20 @@ func (m *Manager) AcceptIncomingFile(transferID, saveDir string) error {
 		return fmt.Errorf("invalid filename: contains dangerous characters")
 	}
 
-	// Prepare file path with sanitized filename
+	// Prepare file path with sanitized filename, in the folder for its type if set
+	saveDir = typedDownloadDir(cleanFileName, saveDir, mediaType, downloadDirFor)
 	savePath := filepath.Join(saveDir, cleanFileName)
 
+	if queue {
+		m.enqueue(queuedTransfer{transfer: transfer, savePath: savePath})
+		return nil
+	}
+	return openIncomingFile(transfer, savePath)
+}
+
+// openIncomingFile creates the file an accepted transfer is written to and
+// starts the transfer. The caller must hold the transfer's lock.
+func openIncomingFile(transfer *Transfer, savePath string) error {
 	// Ensure directory exists with restrictive permissions
 	if err := os.MkdirAll(filepath.Dir(savePath), 0o700); err != nil {
 		return fmt.Errorf("failed to create save directory: %w", err)
@@ -164,6 +217,9 @@ func (m *Manager) AcceptIncomingFile(transferID, saveDir string) error {
 	transfer.FilePath = savePath
 	transfer.file = file
 	transfer.State = TransferStateActive
+	if transfer.onProgress != nil {
+		go transfer.onProgress(transfer)
+	}
 
 	return nil
 }
@@ -191,9 +247,16 @@ func (m *Manager) PauseTransfer(transferID string, toxMgr ToxManager) error {
 	}
 
 	transfer.State = TransferStatePaused
+	if transfer.onProgress != nil {
+		go transfer.onProgress(transfer)
+	}
 	return nil
 }
 
+// resumeChunkSize is the size of the chunk sent when an outgoing transfer is
+// resumed, the largest toxcore accepts
+const resumeChunkSize = 1024
+
 // ResumeTransfer resumes a paused transfer
 func (m *Manager) ResumeTransfer(transferID string, toxMgr ToxManager) error {
 	m.mu.RLock()
@@ -217,6 +280,14 @@ func (m *Manager) ResumeTransfer(transferID string, toxMgr ToxManager) error {
 	}
 
 	transfer.State = TransferStateActive
+
+	// Chunk requests that arrived while paused were dropped, so an outgoing
+	// transfer picks up again from the last chunk the peer has
+	if transfer.Direction == TransferDirectionOutgoing && transfer.file != nil && transfer.BytesTransferred < transfer.FileSize {
+		m.sendChunk(toxMgr, transfer, transfer.BytesTransferred, resumeChunkSize)
+	} else if transfer.onProgress != nil {
+		go transfer.onProgress(transfer)
+	}
 	return nil
 }
 
@@ -240,9 +311,12 @@ func (m *Manager) CancelTransfer(transferID string, toxMgr ToxManager) error {
 		return fmt.Errorf("transfer %s is already complete", transferID)
 	}
 
-	// Send cancel control to Tox
-	if err := toxMgr.FileControl(transfer.FriendID, transfer.FileID, toxcore.FileControlCancel); err != nil {
-		return fmt.Errorf("failed to cancel transfer via Tox: %w", err)
+	// A queued upload was never offered, so the friend has nothing to cancel
+	offered := transfer.Direction == TransferDirectionIncoming || transfer.State != TransferStateQueued
+	if offered {
+		if err := toxMgr.FileControl(transfer.FriendID, transfer.FileID, toxcore.FileControlCancel); err != nil {
+			return fmt.Errorf("failed to cancel transfer via Tox: %w", err)
+		}
 	}
 
 	// Close file if open
@@ -259,6 +333,7 @@ func (m *Manager) CancelTransfer(transferID string, toxMgr ToxManager) error {
 	transfer.State = TransferStateCancelled
 	now := time.Now()
 	transfer.EndTime = &now
+	m.transferEnded()
 
 	return nil
 }
@@ -0,0 +1,67 @@
+package transfer
+
+import (
+	"sync"
+	"time"
+)
+
+// minRateLimitBurst is the smallest bucket size, so one full chunk can always
+// be sent without waiting when the bucket is full
+const minRateLimitBurst = resumeChunkSize
+
+// rateLimiter is a token bucket shared by every outgoing transfer. Sends may
+// overdraw it; the debt is paid back by waiting before the next send.
+type rateLimiter struct {
+	mu       sync.Mutex
+	rate     float64 // Bytes per second; 0 means unlimited
+	capacity float64 // Most bytes that may be sent in a burst
+	tokens   float64
+	last     time.Time
+}
+
+// setRate changes the limit and starts again with a full bucket
+func (l *rateLimiter) setRate(bytesPerSec int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if bytesPerSec <= 0 {
+		l.rate, l.capacity, l.tokens = 0, 0, 0
+		return
+	}
+	l.rate = float64(bytesPerSec)
+	l.capacity = l.rate / 10
+	if l.capacity < minRateLimitBurst {
+		l.capacity = minRateLimitBurst
+	}
+	l.tokens = l.capacity
+	l.last = time.Now()
+}
+
+// reserve takes n bytes from the bucket and returns how long to wait before
+// sending them
+func (l *rateLimiter) reserve(n int, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate == 0 {
+		return 0
+	}
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.capacity {
+		l.tokens = l.capacity
+	}
+	l.last = now
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// SetTransferRateLimit limits how fast all outgoing transfers together send
+// data, in bytes per second. Zero or less removes the limit.
+func (m *Manager) SetTransferRateLimit(bytesPerSec int) {
+	m.limiter.setRate(bytesPerSec)
+}
@@ -0,0 +1,97 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	var limiter rateLimiter
+	now := time.Now()
+	if delay := limiter.reserve(1<<20, now); delay != 0 {
+		t.Errorf("Expected no delay without a limit, got %v", delay)
+	}
+
+	limiter.setRate(10000) // 1000-byte burst, raised to one chunk
+	limiter.last = now
+	if delay := limiter.reserve(1024, now); delay != 0 {
+		t.Errorf("Expected a full bucket to cover one chunk, got %v", delay)
+	}
+	if delay := limiter.reserve(1000, now); delay != 100*time.Millisecond {
+		t.Errorf("Expected to wait 100ms for the next 1000 bytes, got %v", delay)
+	}
+
+	// Debt carries over to the next send until it is paid back
+	if delay := limiter.reserve(1000, now.Add(50*time.Millisecond)); delay != 150*time.Millisecond {
+		t.Errorf("Expected to wait 150ms, got %v", delay)
+	}
+
+	limiter.setRate(0)
+	if delay := limiter.reserve(1000, now); delay != 0 {
+		t.Errorf("Expected no delay once the limit is removed, got %v", delay)
+	}
+}
+
+func TestTransferRateLimit(t *testing.T) {
+	const (
+		fileSize  = 10000
+		chunkSize = 1000
+		rateLimit = 20000 // Bytes per second, with a 2000-byte burst
+	)
+
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+	manager.SetTransferRateLimit(rateLimit)
+
+	testFile := filepath.Join(tempDir, "upload.bin")
+	if err := os.WriteFile(testFile, make([]byte, fileSize), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	var mu sync.Mutex
+	var sent int
+	done := make(chan struct{})
+	mockTox := &MockToxManager{
+		fileSendChunkFunc: func(friendID, fileID uint32, position uint64, data []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			sent += len(data)
+			if sent == fileSize {
+				close(done)
+			}
+			return nil
+		},
+	}
+	manager.SetToxManager(mockTox)
+
+	transfer, err := manager.SendFile(1, testFile)
+	if err != nil {
+		t.Fatalf("Failed to create transfer: %v", err)
+	}
+	if err := manager.StartSend(transfer, mockTox); err != nil {
+		t.Fatalf("Failed to start transfer: %v", err)
+	}
+
+	start := time.Now()
+	for position := 0; position < fileSize; position += chunkSize {
+		mockTox.TriggerFileChunkRequest(1, transfer.FileID, uint64(position), chunkSize)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Transfer did not finish")
+	}
+
+	// Everything past the initial burst is sent at the limited rate
+	minimum := time.Duration(float64(fileSize-rateLimit/10) / rateLimit * float64(time.Second))
+	if elapsed := time.Since(start); elapsed < minimum {
+		t.Errorf("Expected the transfer to take at least %v, took %v", minimum, elapsed)
+	}
+}
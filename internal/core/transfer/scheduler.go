@@ -0,0 +1,142 @@
+package transfer
+
+import (
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// queuedTransfer is a transfer waiting for a free slot
+type queuedTransfer struct {
+	transfer *Transfer
+	toxMgr   ToxManager // Outgoing: offers the file once started
+	savePath string     // Incoming: where the accepted file is written
+}
+
+// SetConcurrencyLimits sets how many downloads and uploads run at once; 0
+// is unlimited. Transfers beyond a limit are queued, and raising a limit
+// starts queued transfers straight away.
+func (m *Manager) SetConcurrencyLimits(downloads, uploads int) {
+	m.mu.Lock()
+	m.maxDownloads = downloads
+	m.maxUploads = uploads
+	m.mu.Unlock()
+
+	m.promoteQueued()
+}
+
+// GetQueuedTransfers returns the transfers waiting for a slot, oldest first
+func (m *Manager) GetQueuedTransfers() []*Transfer {
+	m.schedMu.Lock()
+	defer m.schedMu.Unlock()
+
+	var queued []*Transfer
+	for _, entry := range m.queue {
+		entry.transfer.mu.RLock()
+		if entry.transfer.State == TransferStateQueued {
+			queued = append(queued, entry.transfer)
+		}
+		entry.transfer.mu.RUnlock()
+	}
+	return queued
+}
+
+// hasSlot reports whether another transfer in direction may start now. The
+// caller must hold schedMu and no transfer's lock.
+func (m *Manager) hasSlot(direction TransferDirection) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	limit := m.maxUploads
+	if direction == TransferDirectionIncoming {
+		limit = m.maxDownloads
+	}
+	if limit <= 0 {
+		return true
+	}
+
+	// A paused transfer keeps its slot so resuming never exceeds the limit
+	running := 0
+	for _, transfer := range m.transfers {
+		if transfer.Direction != direction {
+			continue
+		}
+		transfer.mu.RLock()
+		if transfer.State == TransferStateActive || transfer.State == TransferStatePaused {
+			running++
+		}
+		transfer.mu.RUnlock()
+	}
+	return running < limit
+}
+
+// enqueue parks a transfer until a slot frees up. The caller must hold
+// schedMu and the transfer's lock.
+func (m *Manager) enqueue(entry queuedTransfer) {
+	entry.transfer.State = TransferStateQueued
+	m.queue = append(m.queue, entry)
+	logging.Debugf("Transfer %s queued", entry.transfer.ID)
+}
+
+// transferEnded starts queued transfers after one freed its slot. It runs
+// on its own goroutine since callers hold the ended transfer's lock.
+func (m *Manager) transferEnded() {
+	go m.promoteQueued()
+}
+
+// promoteQueued starts queued transfers, oldest first, while their
+// direction has a free slot
+func (m *Manager) promoteQueued() {
+	m.schedMu.Lock()
+	defer m.schedMu.Unlock()
+
+	for {
+		entry, ok := m.nextQueued()
+		if !ok {
+			return
+		}
+
+		var err error
+		if entry.transfer.Direction == TransferDirectionOutgoing {
+			entry.transfer.mu.Lock()
+			entry.transfer.State = TransferStatePending
+			entry.transfer.mu.Unlock()
+			err = m.offerFile(entry.transfer, entry.toxMgr)
+		} else {
+			entry.transfer.mu.Lock()
+			if entry.transfer.State == TransferStateQueued {
+				err = openIncomingFile(entry.transfer, entry.savePath)
+			}
+			entry.transfer.mu.Unlock()
+		}
+		if err != nil {
+			logging.Warnf("Failed to start queued transfer %s: %v", entry.transfer.ID, err)
+		}
+	}
+}
+
+// nextQueued removes and returns the oldest queued transfer that may start,
+// dropping transfers cancelled while they waited. The caller must hold
+// schedMu.
+func (m *Manager) nextQueued() (queuedTransfer, bool) {
+	slots := map[TransferDirection]bool{
+		TransferDirectionOutgoing: m.hasSlot(TransferDirectionOutgoing),
+		TransferDirectionIncoming: m.hasSlot(TransferDirectionIncoming),
+	}
+
+	for i := 0; i < len(m.queue); i++ {
+		entry := m.queue[i]
+		entry.transfer.mu.RLock()
+		waiting := entry.transfer.State == TransferStateQueued
+		entry.transfer.mu.RUnlock()
+
+		if !waiting {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			i--
+			continue
+		}
+		if slots[entry.transfer.Direction] {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			return entry, true
+		}
+	}
+	return queuedTransfer{}, false
+}
@@ -0,0 +1,163 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+)
+
+// countStates returns how many transfers in a direction run and wait
+func countStates(m *Manager, direction TransferDirection) (running, queued int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, transfer := range m.transfers {
+		if transfer.Direction != direction {
+			continue
+		}
+		transfer.mu.RLock()
+		switch transfer.State {
+		case TransferStateActive, TransferStatePaused:
+			running++
+		case TransferStateQueued:
+			queued++
+		}
+		transfer.mu.RUnlock()
+	}
+	return running, queued
+}
+
+// waitForStates waits until a direction has the given running and queued
+// transfers, as queued ones are started in the background
+func waitForStates(t *testing.T, m *Manager, direction TransferDirection, wantRunning, wantQueued int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		running, queued := countStates(m, direction)
+		if running == wantRunning && queued == wantQueued {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d running and %d queued, got %d and %d", wantRunning, wantQueued, running, queued)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestUploadLimit(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+
+	var nextFileID atomic.Uint32
+	var mu sync.Mutex
+	var cancelled []uint32
+	mockTox := &MockToxManager{
+		fileSendFunc: func(friendID, kind uint32, fileSize uint64, fileID [32]byte, fileName string) (uint32, error) {
+			return nextFileID.Add(1), nil
+		},
+		fileControlFunc: func(friendID, fileID uint32, control toxcore.FileControl) error {
+			mu.Lock()
+			defer mu.Unlock()
+			cancelled = append(cancelled, fileID)
+			return nil
+		},
+	}
+	manager.SetToxManager(mockTox)
+	manager.SetConcurrencyLimits(0, 2)
+
+	var transfers []*Transfer
+	for i := 0; i < 5; i++ {
+		path := filepath.Join(tempDir, fmt.Sprintf("file%d.txt", i))
+		if err := os.WriteFile(path, []byte("upload"), 0o644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		transfer, err := manager.SendFile(7, path)
+		if err != nil {
+			t.Fatalf("SendFile failed: %v", err)
+		}
+		if err := manager.StartSend(transfer, mockTox); err != nil {
+			t.Fatalf("StartSend failed: %v", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+	waitForStates(t, manager, TransferDirectionOutgoing, 2, 3)
+	if queued := manager.GetQueuedTransfers(); len(queued) != 3 || queued[0] != transfers[2] {
+		t.Errorf("Expected the last three transfers queued in order, got %d", len(queued))
+	}
+
+	// Finishing an upload starts the oldest queued one
+	mockTox.TriggerFileChunkRequest(7, transfers[0].FileID, 0, 0)
+	waitForStates(t, manager, TransferDirectionOutgoing, 2, 2)
+	if transfers[2].IsQueued() {
+		t.Error("Expected the oldest queued transfer to start first")
+	}
+
+	// A queued upload was never offered, so cancelling it tells Tox nothing
+	if err := manager.CancelTransfer(transfers[3].ID, mockTox); err != nil {
+		t.Fatalf("CancelTransfer failed: %v", err)
+	}
+	mu.Lock()
+	if len(cancelled) != 0 {
+		t.Errorf("Expected no Tox cancel for a queued upload, got %v", cancelled)
+	}
+	mu.Unlock()
+	waitForStates(t, manager, TransferDirectionOutgoing, 2, 1)
+
+	// Raising the limit starts the rest at once
+	manager.SetConcurrencyLimits(0, 5)
+	waitForStates(t, manager, TransferDirectionOutgoing, 3, 0)
+	if len(manager.GetQueuedTransfers()) != 0 {
+		t.Error("Expected the queue to be empty")
+	}
+}
+
+func TestDownloadLimit(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+	mockTox := &MockToxManager{}
+	manager.SetToxManager(mockTox)
+	manager.SetConcurrencyLimits(1, 0)
+
+	content := []byte("download")
+	saveDir := filepath.Join(tempDir, "downloads")
+	var transfers []*Transfer
+	for i := uint32(1); i <= 3; i++ {
+		mockTox.TriggerFileRecv(4, i, 0, uint64(len(content)), fmt.Sprintf("file%d.txt", i))
+		incoming := manager.toxTransfers[4][i]
+		if err := manager.AcceptIncomingFile(incoming.ID, saveDir); err != nil {
+			t.Fatalf("AcceptIncomingFile failed: %v", err)
+		}
+		transfers = append(transfers, incoming)
+	}
+	waitForStates(t, manager, TransferDirectionIncoming, 1, 2)
+
+	// Chunks for a queued download are not written
+	mockTox.TriggerFileRecvChunk(4, 3, 0, content)
+	if _, err := os.Stat(filepath.Join(saveDir, "file3.txt")); !os.IsNotExist(err) {
+		t.Error("Expected a queued download to have no file yet")
+	}
+
+	for i, transfer := range transfers {
+		mockTox.TriggerFileRecvChunk(4, transfer.FileID, 0, content)
+		if !transfer.IsComplete() {
+			t.Fatalf("Expected download %d to complete", i)
+		}
+		if i < len(transfers)-1 {
+			waitForStates(t, manager, TransferDirectionIncoming, 1, len(transfers)-2-i)
+		}
+	}
+	waitForStates(t, manager, TransferDirectionIncoming, 0, 0)
+}
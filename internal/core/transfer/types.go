@@ -38,6 +38,8 @@ const (
 	TransferStateFailed
 	// TransferStateCancelled indicates the transfer was cancelled
 	TransferStateCancelled
+	// TransferStateQueued indicates the transfer waits for a free slot
+	TransferStateQueued
 )
 
 // TransferDirection indicates if this is an incoming or outgoing transfer
@@ -65,12 +67,19 @@ type Transfer struct {
 	FileSize     uint64
 	FileChecksum string // SHA256 hash
 
+	// Checksum the sender announced for an incoming file; empty if none was sent
+	ExpectedChecksum string
+
 	// Transfer metadata
 	Direction        TransferDirection
 	State            TransferState
 	BytesTransferred uint64
 	StartTime        time.Time
 	EndTime          *time.Time
+	Err              error // Reason the transfer failed, if known
+
+	// Chunks carry a digest that the receiver verifies
+	VerifyChunks bool
 
 	// File handle for active transfers
 	file *os.File
@@ -99,6 +108,50 @@ type Manager struct {
 	// Tox manager for file operations
 	toxMgr ToxManager
 
+	// Whether new outgoing transfers send per-chunk digests
+	verifyChunks bool
+
+	// Shared send budget of all outgoing transfers
+	limiter rateLimiter
+
+	// Decides which incoming files are accepted without asking
+	autoAccept func(friendID uint32) AutoAcceptPolicy
+
+	// Reports friends whose files are refused without asking
+	isBlocked func(friendID uint32) bool
+
+	// Route accepted files to a folder per media type
+	mediaType      func(path string) string
+	downloadDirFor func(mediaType string) string
+
+	// Keeps one copy of each received file; guarded by fileStoreMu, not mu,
+	// because it is used while a transfer's lock is held
+	fileStore   FileStore
+	fileStoreMu sync.RWMutex
+
+	// Called when a friend offers a file
+	onIncomingFile func(transfer *Transfer, accepted bool)
+
+	// Called once an outgoing transfer was offered to the friend
+	onSendStarted func(transfer *Transfer)
+
+	// Checksums announced for files that have not been offered yet
+	expectedChecksums map[checksumKey]string
+
+	// Avatars being sent and received, which never touch the disk
+	avatarSends      map[avatarKey][]byte
+	avatarRecvs      map[avatarKey]*avatarDownload
+	onAvatarReceived func(friendID uint32, avatar []byte)
+
+	// Transfers running at once in each direction; 0 is unlimited
+	maxDownloads int
+	maxUploads   int
+
+	// Transfers waiting for a slot, oldest first. schedMu guards the queue
+	// and serializes starting transfers; it is taken before mu.
+	queue   []queuedTransfer
+	schedMu sync.Mutex
+
 	mu sync.RWMutex
 }
 
@@ -114,6 +167,10 @@ func NewManager(dataDir string) (*Manager, error) {
 		transfers:    make(map[string]*Transfer),
 		toxTransfers: make(map[uint32]map[uint32]*Transfer),
 		maxFileSize:  2 * 1024 * 1024 * 1024, // 2GB default limit
+
+		expectedChecksums: make(map[checksumKey]string),
+		avatarSends:       make(map[avatarKey][]byte),
+		avatarRecvs:       make(map[avatarKey]*avatarDownload),
 	}, nil
 }
 
@@ -143,6 +200,14 @@ func (t *Transfer) Progress() float64 {
 	return float64(t.BytesTransferred) / float64(t.FileSize)
 }
 
+// IsQueued reports whether the transfer waits for a free slot
+func (t *Transfer) IsQueued() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.State == TransferStateQueued
+}
+
 // IsComplete returns true if the transfer is in a terminal state
 func (t *Transfer) IsComplete() bool {
 	t.mu.RLock()
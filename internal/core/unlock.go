@@ -0,0 +1,73 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/core/security"
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// PasswordPrompt asks the user for the password protecting the master key.
+// setup is true on first run, when a new password is chosen; attempt counts
+// unlock attempts from 1. Returning an error aborts startup.
+type PasswordPrompt func(setup bool, attempt int) (string, error)
+
+// maxUnlockAttempts is how many wrong passwords are allowed before startup fails
+const maxUnlockAttempts = 5
+
+// unlockBackoff is the wait after the first wrong password; it doubles after
+// each further one
+var unlockBackoff = time.Second
+
+// ErrTooManyAttempts is returned when every unlock attempt used a wrong password
+var ErrTooManyAttempts = errors.New("too many wrong passwords")
+
+// unlockSecurity sets up the password on first run, or asks for it and
+// unlocks the master key on later runs
+func unlockSecurity(securityMgr *security.Manager, prompt PasswordPrompt) error {
+	if !securityMgr.HasPassword() {
+		password, err := prompt(true, 1)
+		if err != nil {
+			return fmt.Errorf("password setup cancelled: %w", err)
+		}
+		if err := securityMgr.SetupPassword(password); err != nil {
+			return fmt.Errorf("failed to set up password: %w", err)
+		}
+		logging.Infof("Password set up")
+		return nil
+	}
+
+	delay := unlockBackoff
+	for attempt := 1; attempt <= maxUnlockAttempts; attempt++ {
+		password, err := prompt(false, attempt)
+		if err != nil {
+			return fmt.Errorf("unlock cancelled: %w", err)
+		}
+
+		err = securityMgr.Unlock(password)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, security.ErrWrongPassword) {
+			return fmt.Errorf("failed to unlock: %w", err)
+		}
+
+		logging.Warnf("Wrong password (attempt %d of %d)", attempt, maxUnlockAttempts)
+		if attempt < maxUnlockAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return ErrTooManyAttempts
+}
+
+// ChangePassword replaces the password protecting the master key. The
+// encrypted database does not need to be rewritten.
+func (a *App) ChangePassword(oldPassword, newPassword string) error {
+	if !a.security.HasPassword() {
+		return fmt.Errorf("no password has been set up")
+	}
+	return a.security.ChangePassword(oldPassword, newPassword)
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	configpkg "github.com/opd-ai/whisp/internal/core/config"
+	"github.com/opd-ai/whisp/internal/core/security"
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+// passwords returns a prompt answering with the given passwords in turn
+func passwords(t *testing.T, answers ...string) (PasswordPrompt, *int) {
+	calls := 0
+	return func(setup bool, attempt int) (string, error) {
+		if calls >= len(answers) {
+			t.Fatalf("Unexpected prompt (setup=%v, attempt=%d)", setup, attempt)
+		}
+		calls++
+		return answers[calls-1], nil
+	}, &calls
+}
+
+func TestUnlockSecurity(t *testing.T) {
+	saved := unlockBackoff
+	unlockBackoff = 0
+	defer func() { unlockBackoff = saved }()
+
+	dataDir := t.TempDir()
+	securityMgr, err := security.NewManager(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create security manager: %v", err)
+	}
+
+	// First run sets the password up
+	prompt, calls := passwords(t, "secret")
+	if err := unlockSecurity(securityMgr, prompt); err != nil || *calls != 1 {
+		t.Fatalf("Setup failed after %d prompts: %v", *calls, err)
+	}
+
+	tests := []struct {
+		name      string
+		answers   []string
+		wantErr   error
+		wantCalls int
+	}{
+		{"right password", []string{"secret"}, nil, 1},
+		{"retry after a wrong password", []string{"nope", "secret"}, nil, 2},
+		{"too many wrong passwords", []string{"1", "2", "3", "4", "5"}, ErrTooManyAttempts, maxUnlockAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restarted, err := security.NewManager(dataDir)
+			if err != nil {
+				t.Fatalf("Failed to create security manager: %v", err)
+			}
+			prompt, calls := passwords(t, tt.answers...)
+			err = unlockSecurity(restarted, prompt)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("Expected %d prompts, got %d", tt.wantCalls, *calls)
+			}
+			if unlocked := restarted.GetMasterKey() != nil; unlocked != (tt.wantErr == nil) {
+				t.Errorf("Expected unlocked=%v", tt.wantErr == nil)
+			}
+		})
+	}
+
+	// Cancelling aborts without retrying
+	restarted, _ := security.NewManager(dataDir)
+	cancelled := errors.New("cancelled")
+	if err := unlockSecurity(restarted, func(bool, int) (string, error) { return "", cancelled }); !errors.Is(err, cancelled) {
+		t.Errorf("Expected the prompt error, got %v", err)
+	}
+}
+
+func TestOpenDatabaseEncrypts(t *testing.T) {
+	dataDir := t.TempDir()
+	dbPath := filepath.Join(dataDir, "whisp.db")
+	var cfg configpkg.Config
+	cfg.Storage.EnableEncryption = true
+
+	// Without a prompt the database stays unencrypted
+	securityMgr, _ := security.NewManager(dataDir)
+	db, err := openDatabase(dbPath, cfg, securityMgr, nil, false)
+	if err != nil {
+		t.Fatalf("openDatabase failed: %v", err)
+	}
+	db.Close()
+	if !storage.IsPlaintextDatabase(dbPath) {
+		t.Fatal("Expected an unencrypted database without a prompt")
+	}
+
+	// With one, the existing database is encrypted under the unlocked key
+	prompt, _ := passwords(t, "secret")
+	db, err = openDatabase(dbPath, cfg, securityMgr, prompt, false)
+	if err != nil {
+		t.Fatalf("openDatabase failed: %v", err)
+	}
+	if !db.IsEncrypted() {
+		t.Error("Expected an encrypted database")
+	}
+	db.Close()
+	if storage.IsPlaintextDatabase(dbPath) {
+		t.Fatal("Expected the database file to be encrypted")
+	}
+
+	// Once a password exists it is required even with encryption switched off
+	cfg.Storage.EnableEncryption = false
+	restarted, _ := security.NewManager(dataDir)
+	prompt, calls := passwords(t, "secret")
+	db, err = openDatabase(dbPath, cfg, restarted, prompt, false)
+	if err != nil || *calls != 1 {
+		t.Fatalf("Expected to unlock with one prompt, got %d (%v)", *calls, err)
+	}
+	db.Close()
+}
+
+func TestOpenDatabaseAppliesRestoreFirst(t *testing.T) {
+	dataDir := t.TempDir()
+	dbPath := filepath.Join(dataDir, "whisp.db")
+	backupPath := filepath.Join(dataDir, "backup.db")
+	var cfg configpkg.Config
+
+	securityMgr, _ := security.NewManager(dataDir)
+	db, err := openDatabase(dbPath, cfg, securityMgr, nil, false)
+	if err != nil {
+		t.Fatalf("openDatabase failed: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO settings (key, value, updated_at) VALUES ('marker', 'backed up', datetime('now'))"); err != nil {
+		t.Fatalf("Failed to insert marker: %v", err)
+	}
+	if err := db.BackupDatabase(backupPath); err != nil {
+		t.Fatalf("BackupDatabase failed: %v", err)
+	}
+	if _, err := db.Exec("UPDATE settings SET value = 'changed' WHERE key = 'marker'"); err != nil {
+		t.Fatalf("Failed to update marker: %v", err)
+	}
+	if err := db.RestoreDatabase(backupPath); err != nil {
+		t.Fatalf("RestoreDatabase failed: %v", err)
+	}
+	db.Close()
+
+	// Encryption switched on while the plaintext backup is staged
+	cfg.Storage.EnableEncryption = true
+	prompt, _ := passwords(t, "secret")
+	db, err = openDatabase(dbPath, cfg, securityMgr, prompt, false)
+	if err != nil {
+		t.Fatalf("openDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	if !db.IsEncrypted() {
+		t.Error("Expected the restored database to be encrypted")
+	}
+	var value string
+	if err := db.QueryRow("SELECT value FROM settings WHERE key = 'marker'").Scan(&value); err != nil || value != "backed up" {
+		t.Errorf("Expected the restored data, got %q (%v)", value, err)
+	}
+}
+
+func TestOpenDatabaseMigratesCipherParams(t *testing.T) {
+	dataDir := t.TempDir()
+	dbPath := filepath.Join(dataDir, "whisp.db")
+	var cfg configpkg.Config
+	cfg.Storage.EnableEncryption = true
+
+	securityMgr, _ := security.NewManager(dataDir)
+	prompt, _ := passwords(t, "secret")
+	db, err := openDatabase(dbPath, cfg, securityMgr, prompt, false)
+	if err != nil {
+		t.Fatalf("openDatabase failed: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO settings (key, value, updated_at) VALUES ('marker', 'kept', datetime('now'))"); err != nil {
+		t.Fatalf("Failed to insert marker: %v", err)
+	}
+	db.Close()
+	if recorded, err := storage.LoadCipherParams(dbPath); err != nil || recorded != storage.DefaultCipherParams() {
+		t.Fatalf("Expected the default cipher settings, got %+v (%v)", recorded, err)
+	}
+
+	// Changed settings alone keep the database as it is
+	cfg.Storage.Cipher.KDFIterations = 64000
+	cfg.Storage.Cipher.PageSize = 8192
+	cfg.Storage.Cipher.HMACAlgorithm = "HMAC_SHA256"
+	cfg.Storage.Cipher.KDFAlgorithm = "PBKDF2_HMAC_SHA256"
+	restarted, _ := security.NewManager(dataDir)
+	prompt, _ = passwords(t, "secret")
+	db, err = openDatabase(dbPath, cfg, restarted, prompt, false)
+	if err != nil {
+		t.Fatalf("openDatabase failed: %v", err)
+	}
+	db.Close()
+	if recorded, err := storage.LoadCipherParams(dbPath); err != nil || recorded != storage.DefaultCipherParams() {
+		t.Fatalf("Expected the settings unchanged without a migration, got %+v (%v)", recorded, err)
+	}
+
+	// Asking for the migration re-encrypts it
+	restarted, _ = security.NewManager(dataDir)
+	prompt, _ = passwords(t, "secret")
+	db, err = openDatabase(dbPath, cfg, restarted, prompt, true)
+	if err != nil {
+		t.Fatalf("openDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	want := storage.CipherParams{Compatibility: 4, KDFIterations: 64000, PageSize: 8192, HMACAlgorithm: "HMAC_SHA256", KDFAlgorithm: "PBKDF2_HMAC_SHA256"}
+	if recorded, err := storage.LoadCipherParams(dbPath); err != nil || recorded != want {
+		t.Errorf("Expected the configured cipher settings %+v, got %+v (%v)", want, recorded, err)
+	}
+	var value string
+	if err := db.QueryRow("SELECT value FROM settings WHERE key = 'marker'").Scan(&value); err != nil || value != "kept" {
+		t.Errorf("Expected the data to survive the migration, got %q (%v)", value, err)
+	}
+}
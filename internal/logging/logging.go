@@ -0,0 +1,199 @@
+// Package logging writes leveled log lines to stderr and, optionally, to a
+// rotating file, redacting Tox IDs and keys on the way out.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Level is how important a log line is
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// Log files rotate at DefaultMaxFileSize unless configured otherwise,
+// keeping MaxBackups older files
+const (
+	DefaultMaxFileSize = 10 * 1024 * 1024
+	MaxBackups         = 3
+)
+
+// FileName is the log file written in the logs directory
+const FileName = "whisp.log"
+
+// String returns the level's name as used in config
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int32(l))
+	}
+}
+
+// ParseLevel parses a level name from config
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+var (
+	level atomic.Int32 // Lines below this level are dropped
+
+	mu     sync.Mutex
+	logger = log.New(os.Stderr, "", log.LstdFlags)
+	file   *RotatingFile
+)
+
+func init() {
+	level.Store(int32(LevelInfo))
+}
+
+// SetLevel sets the lowest level written; it takes effect immediately
+func SetLevel(l Level) {
+	level.Store(int32(l))
+}
+
+// GetLevel returns the lowest level written
+func GetLevel() Level {
+	return Level(level.Load())
+}
+
+// Enabled reports whether lines at l are written
+func Enabled(l Level) bool {
+	return l >= GetLevel()
+}
+
+// SetOutput sends log lines to w instead of stderr, closing any log file
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	closeFileLocked()
+	logger.SetOutput(w)
+}
+
+// Configure applies the log settings: the level, and whether lines are also
+// written to a file in logDir that rotates at maxSize bytes
+func Configure(levelName string, toFile bool, logDir string, maxSize int64) error {
+	l, err := ParseLevel(levelName)
+	SetLevel(l)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	path := filepath.Join(logDir, FileName)
+	if !toFile || logDir == "" {
+		closeFileLocked()
+		logger.SetOutput(os.Stderr)
+		return err
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFileSize
+	}
+	if file != nil && file.Path() == path {
+		file.SetMaxSize(maxSize)
+		return err
+	}
+
+	rotating, openErr := OpenRotatingFile(path, maxSize, MaxBackups)
+	if openErr != nil {
+		return fmt.Errorf("failed to open log file: %w", openErr)
+	}
+	closeFileLocked()
+	file = rotating
+	logger.SetOutput(io.MultiWriter(os.Stderr, file))
+	return err
+}
+
+// Close closes the log file, if any, and goes back to stderr
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	err := closeFileLocked()
+	logger.SetOutput(os.Stderr)
+	return err
+}
+
+// closeFileLocked closes the log file. The caller must hold mu.
+func closeFileLocked() error {
+	if file == nil {
+		return nil
+	}
+	err := file.Close()
+	file = nil
+	return err
+}
+
+// Debugf logs detail only useful when diagnosing a problem
+func Debugf(format string, args ...interface{}) {
+	output(LevelDebug, format, args...)
+}
+
+// Infof logs normal operation
+func Infof(format string, args ...interface{}) {
+	output(LevelInfo, format, args...)
+}
+
+// Warnf logs a problem the app recovered from
+func Warnf(format string, args ...interface{}) {
+	output(LevelWarn, format, args...)
+}
+
+// Errorf logs a failure of something the user asked for
+func Errorf(format string, args ...interface{}) {
+	output(LevelError, format, args...)
+}
+
+// output formats, redacts and writes one line at l
+func output(l Level, format string, args ...interface{}) {
+	if !Enabled(l) {
+		return
+	}
+	line := Redact(fmt.Sprintf(format, args...))
+
+	mu.Lock()
+	defer mu.Unlock()
+	logger.Printf("[%s] %s", strings.ToUpper(l.String()), line)
+}
+
+// secretPattern matches the hex of Tox IDs, public keys and secret keys
+var secretPattern = regexp.MustCompile(`\b[0-9A-Fa-f]{32,}\b`)
+
+// Redact shortens Tox IDs and keys in s to their first 8 characters, enough
+// to tell them apart in a log without revealing them
+func Redact(s string) string {
+	return secretPattern.ReplaceAllStringFunc(s, func(secret string) string {
+		return secret[:8] + "…"
+	})
+}
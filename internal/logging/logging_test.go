@@ -0,0 +1,179 @@
+package logging
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureLogs sends log lines to a buffer for the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	previous := GetLevel()
+	t.Cleanup(func() {
+		SetLevel(previous)
+		Close()
+	})
+	return &buf
+}
+
+func TestLevelFiltering(t *testing.T) {
+	tests := []struct {
+		level Level
+		want  []string
+	}{
+		{LevelDebug, []string{"[DEBUG] d", "[INFO] i", "[WARN] w", "[ERROR] e"}},
+		{LevelInfo, []string{"[INFO] i", "[WARN] w", "[ERROR] e"}},
+		{LevelWarn, []string{"[WARN] w", "[ERROR] e"}},
+		{LevelError, []string{"[ERROR] e"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			buf := captureLogs(t)
+			SetLevel(tt.level)
+
+			Debugf("d")
+			Infof("i")
+			Warnf("w")
+			Errorf("e")
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("Expected %d lines, got %d: %q", len(tt.want), len(lines), buf.String())
+			}
+			for i, want := range tt.want {
+				if !strings.HasSuffix(lines[i], want) {
+					t.Errorf("Line %d: expected %q, got %q", i, want, lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Level
+		wantErr bool
+	}{
+		{"debug", LevelDebug, false},
+		{"INFO", LevelInfo, false},
+		{"warning", LevelWarn, false},
+		{"error", LevelError, false},
+		{"", LevelInfo, false},
+		{"verbose", LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLevel(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	toxID := strings.Repeat("ABCDEF0123456789", 4) + "0123456789AB"
+	buf := captureLogs(t)
+	SetLevel(LevelInfo)
+
+	Infof("Tox initialized. ID: %s", toxID)
+
+	if strings.Contains(buf.String(), toxID) {
+		t.Errorf("Expected the Tox ID to be redacted, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "ID: ABCDEF01…") {
+		t.Errorf("Expected the Tox ID's prefix to remain, got %q", buf.String())
+	}
+	if got := Redact("friend 12, transfer 3f2a"); got != "friend 12, transfer 3f2a" {
+		t.Errorf("Expected short values to be left alone, got %q", got)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", FileName)
+	file, err := OpenRotatingFile(path, 100, 2)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile failed: %v", err)
+	}
+	defer file.Close()
+
+	line := []byte(strings.Repeat("x", 39) + "\n")
+	for i := 0; i < 2; i++ {
+		if _, err := file.Write(line); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Fatal("Expected no rotation below the size limit")
+	}
+
+	// The third line would pass 100 bytes, so the file is moved aside first
+	if _, err := file.Write(line); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if info, err := os.Stat(path + ".1"); err != nil || info.Size() != 80 {
+		t.Fatalf("Expected the full file rotated to .1, got %v, %v", info, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 40 {
+		t.Fatalf("Expected a fresh file with one line, got %v, %v", info, err)
+	}
+
+	// Only maxBackups older files are kept
+	for i := 0; i < 9; i++ {
+		if _, err := file.Write(line); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if _, err := os.Stat(path + ".2"); err != nil {
+		t.Errorf("Expected a second older file: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected no more than two older files")
+	}
+}
+
+func TestConfigureFile(t *testing.T) {
+	logDir := t.TempDir()
+	captureLogs(t)
+
+	if err := Configure("warn", true, logDir, 0); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	Infof("dropped")
+	Warnf("kept")
+
+	// Lowering the level applies to the next line
+	if err := Configure("debug", true, logDir, 0); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	Debugf("detail")
+
+	data, err := os.ReadFile(filepath.Join(logDir, FileName))
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if strings.Contains(string(data), "dropped") || !strings.Contains(string(data), "[WARN] kept") || !strings.Contains(string(data), "[DEBUG] detail") {
+		t.Errorf("Unexpected log file contents: %q", data)
+	}
+
+	if err := Configure("info", false, logDir, 0); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	Warnf("stderr only")
+	data, _ = os.ReadFile(filepath.Join(logDir, FileName))
+	if strings.Contains(string(data), "stderr only") {
+		t.Error("Expected nothing written to the file once turned off")
+	}
+}
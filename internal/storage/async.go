@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultAsyncTimeout bounds how long Close waits for background writes
+const DefaultAsyncTimeout = 5 * time.Second
+
+// asyncTracker counts in-flight background writes so shutdown can wait for them
+type asyncTracker struct {
+	mu      sync.Mutex
+	pending int
+	idle    chan struct{} // Closed when pending drops to zero
+}
+
+// Async runs a database update in the background. Unlike a bare goroutine, the
+// update is tracked so WaitAsync and Close do not lose it on shutdown.
+func (d *Database) Async(update func()) {
+	d.async.mu.Lock()
+	if d.async.pending == 0 {
+		d.async.idle = make(chan struct{})
+	}
+	d.async.pending++
+	d.async.mu.Unlock()
+
+	go func() {
+		defer d.asyncDone()
+		update()
+	}()
+}
+
+// WaitAsync blocks until all background updates have finished or the timeout expires
+func (d *Database) WaitAsync(timeout time.Duration) error {
+	d.async.mu.Lock()
+	if d.async.pending == 0 {
+		d.async.mu.Unlock()
+		return nil
+	}
+	idle := d.async.idle
+	pending := d.async.pending
+	d.async.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out waiting for %d background database writes", pending)
+	}
+}
+
+// asyncDone marks a background update as finished
+func (d *Database) asyncDone() {
+	d.async.mu.Lock()
+	defer d.async.mu.Unlock()
+
+	d.async.pending--
+	if d.async.pending == 0 {
+		close(d.async.idle)
+	}
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAsyncWritesCompleteBeforeClose(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "async.db")
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	const writes = 10
+	for i := 0; i < writes; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		db.Async(func() {
+			time.Sleep(20 * time.Millisecond)
+			if _, err := db.Exec("INSERT INTO settings (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)", key, "v"); err != nil {
+				t.Errorf("Async insert failed: %v", err)
+			}
+		})
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Reopen and verify nothing was lost
+	db, err = NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM settings WHERE key LIKE 'key-%'").Scan(&count); err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != writes {
+		t.Errorf("Expected %d rows after shutdown, got %d", writes, count)
+	}
+}
+
+func TestWaitAsync(t *testing.T) {
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "async.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.WaitAsync(time.Millisecond); err != nil {
+		t.Errorf("Expected no error with nothing pending, got %v", err)
+	}
+
+	release := make(chan struct{})
+	db.Async(func() { <-release })
+
+	if err := db.WaitAsync(20 * time.Millisecond); err == nil {
+		t.Error("Expected timeout while an update is blocked")
+	}
+
+	close(release)
+	if err := db.WaitAsync(time.Second); err != nil {
+		t.Errorf("Expected pending update to finish, got %v", err)
+	}
+
+	// The tracker must be reusable after draining
+	done := false
+	db.Async(func() { done = true })
+	if err := db.WaitAsync(time.Second); err != nil || !done {
+		t.Errorf("Expected second round to complete, done=%v err=%v", done, err)
+	}
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// Suffixes of the files a restore works with, next to the database
+const (
+	restoreSuffix    = ".restore"     // Backup staged by RestoreDatabase
+	preRestoreSuffix = ".pre-restore" // Database replaced by the last restore
+)
+
+// BackupDatabase writes a consistent copy of the database to destPath while
+// it stays in use. The copy is taken in one read transaction, so writes made
+// meanwhile are either fully in it or not at all. Encrypted databases are
+// copied encrypted with the same key and their cipher settings are recorded
+// next to the copy.
+func (d *Database) BackupDatabase(destPath string) error {
+	if d.IsLocked() {
+		return fmt.Errorf("database is locked")
+	}
+	if err := d.WaitAsync(DefaultAsyncTimeout); err != nil {
+		logging.Warnf("%v", err)
+	}
+
+	// VACUUM INTO refuses to overwrite, so write a fresh file and move it
+	tmpPath := destPath + ".tmp"
+	os.Remove(tmpPath)
+	if _, err := d.conn().Exec("VACUUM INTO ?", tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save backup: %w", err)
+	}
+
+	if d.encrypted {
+		params, err := LoadCipherParams(d.path)
+		if err != nil {
+			return err
+		}
+		return saveCipherParams(destPath, params)
+	}
+	return nil
+}
+
+// RestoreDatabase checks that the backup at srcPath is intact and readable
+// with the current key, and stages it to replace the database the next time
+// it is opened. The replaced database is kept with a .pre-restore suffix.
+func (d *Database) RestoreDatabase(srcPath string) error {
+	if d.IsLocked() {
+		return fmt.Errorf("database is locked")
+	}
+	if !databaseExists(srcPath) {
+		return fmt.Errorf("backup %s does not exist", srcPath)
+	}
+	if err := d.validateBackup(srcPath); err != nil {
+		return err
+	}
+
+	staged := d.path + restoreSuffix
+	if err := copyFile(srcPath, staged); err != nil {
+		return fmt.Errorf("failed to stage backup: %w", err)
+	}
+	if d.encrypted {
+		params, err := LoadCipherParams(srcPath)
+		if err != nil {
+			os.Remove(staged)
+			return err
+		}
+		if err := saveCipherParams(staged, params); err != nil {
+			os.Remove(staged)
+			return err
+		}
+	}
+	return nil
+}
+
+// validateBackup attaches a backup to the open database, which keys it with
+// the same key, and checks it is an intact Whisp database
+func (d *Database) validateBackup(srcPath string) error {
+	if d.encrypted {
+		current, err := LoadCipherParams(d.path)
+		if err != nil {
+			return err
+		}
+		backup, err := LoadCipherParams(srcPath)
+		if err != nil {
+			return err
+		}
+		if backup != current {
+			return fmt.Errorf("backup uses cipher settings %+v, not %+v", backup, current)
+		}
+	}
+
+	// ATTACH and the checks must share one connection
+	ctx := context.Background()
+	conn, err := d.conn().Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS backup", srcPath); err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "DETACH DATABASE backup"); err != nil {
+			logging.Warnf("Failed to detach backup: %v", err)
+		}
+	}()
+
+	var result string
+	if err := conn.QueryRowContext(ctx, "PRAGMA backup.integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("failed to read backup, was it made with another key?: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("backup is damaged: %s", result)
+	}
+
+	var tables int
+	if err := conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM backup.sqlite_master WHERE type = 'table' AND name IN ('contacts', 'messages')").Scan(&tables); err != nil {
+		return fmt.Errorf("failed to read backup tables: %w", err)
+	}
+	if tables != 2 {
+		return fmt.Errorf("backup is not a Whisp database")
+	}
+	return nil
+}
+
+// ApplyPendingRestore swaps in a backup staged by RestoreDatabase before the
+// database is opened, keeping the replaced files with a .pre-restore suffix.
+// Callers that encrypt or migrate the database first must call it before
+// doing so, or they change the files the backup is about to replace.
+func ApplyPendingRestore(dbPath string) error {
+	staged := dbPath + restoreSuffix
+	if !databaseExists(staged) {
+		return nil
+	}
+
+	// The write-ahead log belongs to the replaced database, so it moves too
+	for _, path := range []string{dbPath, dbPath + "-wal", dbPath + "-shm", cipherParamsPath(dbPath)} {
+		if err := os.Rename(path, path+preRestoreSuffix); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to keep replaced database: %w", err)
+		}
+	}
+
+	if err := os.Rename(staged, dbPath); err != nil {
+		return fmt.Errorf("failed to restore database: %w", err)
+	}
+	if err := os.Rename(cipherParamsPath(staged), cipherParamsPath(dbPath)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to restore cipher settings: %w", err)
+	}
+
+	logging.Infof("Restored database %s from backup", dbPath)
+	return nil
+}
+
+// copyFile copies src to dst through a temporary file so dst is never partial
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
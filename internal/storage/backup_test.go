@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// openTestDatabase opens an encrypted database when securityManager is set
+func openTestDatabase(t *testing.T, dbPath string, securityManager SecurityManager) *Database {
+	t.Helper()
+
+	var db *Database
+	var err error
+	if securityManager != nil {
+		db, err = NewDatabaseWithEncryption(dbPath, securityManager)
+	} else {
+		db, err = NewDatabase(dbPath)
+	}
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	return db
+}
+
+// insertTestMessages adds count messages to friend 1, numbered from first
+func insertTestMessages(t *testing.T, db *Database, first, count int) {
+	t.Helper()
+
+	for i := first; i < first+count; i++ {
+		_, err := db.Exec(`INSERT INTO messages (uuid, friend_id, content, is_outgoing, timestamp) VALUES (?, 1, ?, 0, datetime('now'))`,
+			fmt.Sprintf("msg-%d", i), fmt.Sprintf("message %d", i))
+		if err != nil {
+			t.Fatalf("Failed to insert message: %v", err)
+		}
+	}
+}
+
+// countMessages returns the number of stored messages
+func countMessages(t *testing.T, db *Database) int {
+	t.Helper()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM messages").Scan(&count); err != nil {
+		t.Fatalf("Failed to count messages: %v", err)
+	}
+	return count
+}
+
+func TestBackupDatabase(t *testing.T) {
+	tests := []struct {
+		name            string
+		securityManager SecurityManager
+	}{
+		{"unencrypted", nil},
+		{"encrypted", &MockSecurityManager{dbKey: "backup-key"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			db := openTestDatabase(t, filepath.Join(dir, "whisp.db"), tt.securityManager)
+			defer db.Close()
+			insertTestMessages(t, db, 0, 50)
+
+			// Keep writing while the backup runs
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 50; i < 100; i++ {
+					db.Exec(`INSERT INTO messages (uuid, friend_id, content, is_outgoing, timestamp) VALUES (?, 1, 'late', 0, datetime('now'))`,
+						fmt.Sprintf("msg-%d", i))
+				}
+			}()
+
+			backupPath := filepath.Join(dir, "backup.db")
+			if err := db.BackupDatabase(backupPath); err != nil {
+				t.Fatalf("BackupDatabase failed: %v", err)
+			}
+			wg.Wait()
+
+			if IsPlaintextDatabase(backupPath) != (tt.securityManager == nil) {
+				t.Errorf("Expected the backup to keep the database's encryption")
+			}
+
+			backup := openTestDatabase(t, backupPath, tt.securityManager)
+			defer backup.Close()
+			if got := countMessages(t, backup); got < 50 || got > 100 {
+				t.Errorf("Expected between 50 and 100 messages in the backup, got %d", got)
+			}
+			var content string
+			if err := backup.QueryRow("SELECT content FROM messages WHERE uuid = 'msg-7'").Scan(&content); err != nil || content != "message 7" {
+				t.Errorf("Expected message rows to survive the backup, got %q: %v", content, err)
+			}
+		})
+	}
+}
+
+func TestRestoreDatabase(t *testing.T) {
+	securityManager := &MockSecurityManager{dbKey: "restore-key"}
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "whisp.db")
+
+	db := openTestDatabase(t, dbPath, securityManager)
+	insertTestMessages(t, db, 0, 3)
+	backupPath := filepath.Join(dir, "backup.db")
+	if err := db.BackupDatabase(backupPath); err != nil {
+		t.Fatalf("BackupDatabase failed: %v", err)
+	}
+	insertTestMessages(t, db, 3, 2)
+
+	if err := db.RestoreDatabase(backupPath); err != nil {
+		t.Fatalf("RestoreDatabase failed: %v", err)
+	}
+	if got := countMessages(t, db); got != 5 {
+		t.Errorf("Expected the open database to be untouched until restart, got %d messages", got)
+	}
+	db.Close()
+
+	db = openTestDatabase(t, dbPath, securityManager)
+	defer db.Close()
+	if got := countMessages(t, db); got != 3 {
+		t.Errorf("Expected the restored database to have the backup's 3 messages, got %d", got)
+	}
+	if _, err := os.Stat(dbPath + preRestoreSuffix); err != nil {
+		t.Errorf("Expected the replaced database to be kept: %v", err)
+	}
+}
+
+func TestRestoreDatabaseRejectsBadBackups(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDatabase(t, filepath.Join(dir, "whisp.db"), &MockSecurityManager{dbKey: "right-key"})
+	defer db.Close()
+
+	otherKey := openTestDatabase(t, filepath.Join(dir, "other.db"), &MockSecurityManager{dbKey: "wrong-key"})
+	otherKey.Close()
+	plaintext := openTestDatabase(t, filepath.Join(dir, "plain.db"), nil)
+	plaintext.Close()
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte("not a database at all"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join(dir, "other.db"),
+		filepath.Join(dir, "plain.db"),
+		garbage,
+		filepath.Join(dir, "missing.db"),
+	} {
+		if err := db.RestoreDatabase(path); err == nil {
+			t.Errorf("Expected %s to be rejected", filepath.Base(path))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "whisp.db"+restoreSuffix)); !os.IsNotExist(err) {
+		t.Error("Expected nothing to be staged")
+	}
+}
@@ -0,0 +1,323 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// dedupFilesMigration records that files saved before the blob store existed
+// were moved into it
+const dedupFilesMigration = "dedup_existing_files"
+
+// querier runs statements on the database or inside a transaction
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// StoreFile moves a received file into the content-addressed store in dir and
+// returns the path of the stored copy. The store keeps one file per checksum:
+// if the same content is already stored, srcPath is removed and the stored
+// copy gains a reference instead.
+func (d *Database) StoreFile(srcPath, checksum, dir string) (string, error) {
+	d.blobMu.Lock()
+	defer d.blobMu.Unlock()
+
+	blobPath, moved, err := storeFile(d, srcPath, checksum, dir)
+	if err != nil {
+		return "", err
+	}
+	if !moved && srcPath != blobPath {
+		if err := os.Remove(srcPath); err != nil {
+			logging.Warnf("Failed to remove duplicate file %s: %v", srcPath, err)
+		}
+	}
+	return blobPath, nil
+}
+
+// storeFile records srcPath in the store and moves it there unless the same
+// content is already stored, reporting whether it was moved. Duplicates are
+// left for the caller to remove. Must be called with blobMu held.
+func storeFile(q querier, srcPath, checksum, dir string) (string, bool, error) {
+	checksum = strings.ToLower(checksum)
+	if checksum == "" {
+		return "", false, fmt.Errorf("file %s has no checksum", srcPath)
+	}
+
+	var blobPath string
+	err := q.QueryRow(`SELECT path FROM file_blobs WHERE checksum = ?`, checksum).Scan(&blobPath)
+	if err != nil && err != sql.ErrNoRows {
+		return "", false, fmt.Errorf("failed to look up stored file: %w", err)
+	}
+
+	if err == nil && fileExists(blobPath) {
+		if _, err := q.Exec(`UPDATE file_blobs SET ref_count = ref_count + 1 WHERE checksum = ?`, checksum); err != nil {
+			return "", false, fmt.Errorf("failed to reference stored file: %w", err)
+		}
+		return blobPath, false, nil
+	}
+
+	// First copy of this content, or the stored copy went missing
+	if err := ensureDir(dir); err != nil {
+		return "", false, fmt.Errorf("failed to create file store: %w", err)
+	}
+	blobPath = filepath.Join(dir, checksum+strings.ToLower(filepath.Ext(srcPath)))
+	if err := moveFile(srcPath, blobPath); err != nil {
+		return "", false, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	info, err := os.Stat(blobPath)
+	if err == nil {
+		_, err = q.Exec(`INSERT INTO file_blobs (checksum, path, size, ref_count, created_at) VALUES (?, ?, ?, 1, ?)
+			ON CONFLICT(checksum) DO UPDATE SET path = excluded.path, ref_count = ref_count + 1`,
+			checksum, blobPath, info.Size(), time.Now())
+	}
+	if err != nil {
+		// Put the file back so nothing refers to a path the store does not know
+		restoreFile(blobPath, srcPath)
+		return "", false, fmt.Errorf("failed to record stored file: %w", err)
+	}
+	return blobPath, true, nil
+}
+
+// restoreFile moves a file back out of the store after a failed update,
+// logging it if that fails too
+func restoreFile(blobPath, srcPath string) {
+	if err := moveFile(blobPath, srcPath); err != nil {
+		logging.Errorf("Failed to move %s back to %s: %v", blobPath, srcPath, err)
+	}
+}
+
+// ReleaseFile drops one reference to a file of a deleted message or
+// transfer. Stored files are removed once nothing refers to them; files
+// outside the store are removed right away.
+func (d *Database) ReleaseFile(path string) error {
+	d.blobMu.Lock()
+	defer d.blobMu.Unlock()
+
+	var checksum string
+	var refs int
+	err := d.QueryRow(`SELECT checksum, ref_count FROM file_blobs WHERE path = ?`, path).Scan(&checksum, &refs)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to look up stored file: %w", err)
+	}
+
+	if err == nil && refs > 1 {
+		if _, err := d.Exec(`UPDATE file_blobs SET ref_count = ref_count - 1 WHERE checksum = ?`, checksum); err != nil {
+			return fmt.Errorf("failed to release stored file: %w", err)
+		}
+		return nil
+	}
+	if err == nil {
+		if _, err := d.Exec(`DELETE FROM file_blobs WHERE checksum = ?`, checksum); err != nil {
+			return fmt.Errorf("failed to release stored file: %w", err)
+		}
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove file: %w", err)
+	}
+	return nil
+}
+
+// RetainFile counts one more reference to a stored file, for a message that
+// shares it with another. Files outside the store are not reference counted
+// and cannot be shared this way.
+func (d *Database) RetainFile(path string) error {
+	d.blobMu.Lock()
+	defer d.blobMu.Unlock()
+
+	if !isStoredFile(d, path) {
+		return fmt.Errorf("file %s is not in the file store", path)
+	}
+	return addFileReference(d, path)
+}
+
+// DedupFiles moves the files of messages saved before the store existed into
+// dir, keeping one copy of each content, and points their rows and those of
+// the transfers that saved them at the stored copies. Only messages hold
+// references, as only deleting a message releases one; files no message uses
+// stay where they are. It runs once per database and reports how many
+// duplicate files it removed.
+func (d *Database) DedupFiles(dir string) (int, error) {
+	var applied int
+	if err := d.QueryRow(`SELECT COUNT(*) FROM migrations WHERE version = ?`, dedupFilesMigration).Scan(&applied); err != nil {
+		return 0, fmt.Errorf("failed to check migration status: %w", err)
+	}
+	if applied > 0 {
+		return 0, nil
+	}
+
+	d.blobMu.Lock()
+	defer d.blobMu.Unlock()
+
+	tx, err := d.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Files moved into the store go back if the rows referring to them are
+	// not updated; duplicates are only removed once the update is committed
+	var moved []fileMove
+	var duplicates []string
+	fail := func(err error) (int, error) {
+		tx.Rollback()
+		for i := len(moved) - 1; i >= 0; i-- {
+			restoreFile(moved[i].to, moved[i].from)
+		}
+		return 0, err
+	}
+
+	removed := 0
+	stored := make(map[string]string) // Original path -> stored copy
+	checksums := make(map[string]bool)
+	for _, table := range []string{"messages", "file_transfers"} {
+		counted := table == "messages"
+		refs, err := fileReferences(tx, table)
+		if err != nil {
+			return fail(err)
+		}
+
+		for _, ref := range refs {
+			blobPath, seen := stored[ref.path]
+			if seen {
+				// Another row already moved this file
+				if counted {
+					if err := addFileReference(tx, blobPath); err != nil {
+						return fail(err)
+					}
+				}
+			} else {
+				if !counted || !fileExists(ref.path) || isStoredFile(tx, ref.path) {
+					continue
+				}
+				checksum, err := fileChecksum(ref.path)
+				if err != nil {
+					logging.Warnf("Failed to read %s, leaving it in place: %v", ref.path, err)
+					continue
+				}
+				if checksums[checksum] {
+					removed++
+				}
+				checksums[checksum] = true
+
+				var wasMoved bool
+				if blobPath, wasMoved, err = storeFile(tx, ref.path, checksum, dir); err != nil {
+					return fail(err)
+				}
+				if wasMoved {
+					moved = append(moved, fileMove{from: ref.path, to: blobPath})
+				} else if ref.path != blobPath {
+					duplicates = append(duplicates, ref.path)
+				}
+				stored[ref.path] = blobPath
+			}
+
+			if _, err := tx.Exec(`UPDATE `+table+` SET file_path = ? WHERE id = ?`, blobPath, ref.id); err != nil {
+				return fail(fmt.Errorf("failed to update file path: %w", err))
+			}
+		}
+	}
+
+	if _, err := tx.Exec(`INSERT INTO migrations (version, applied_at) VALUES (?, ?)`, dedupFilesMigration, time.Now()); err != nil {
+		return fail(fmt.Errorf("failed to record migration: %w", err))
+	}
+	if err := tx.Commit(); err != nil {
+		return fail(fmt.Errorf("failed to commit file store migration: %w", err))
+	}
+
+	for _, path := range duplicates {
+		if err := os.Remove(path); err != nil {
+			logging.Warnf("Failed to remove duplicate file %s: %v", path, err)
+		}
+	}
+	logging.Infof("Applied migration: %s, removed %d duplicate files", dedupFilesMigration, removed)
+	return removed, nil
+}
+
+// fileMove is a file moved into the store
+type fileMove struct {
+	from string
+	to   string
+}
+
+// fileReference is a row of a table that refers to a file
+type fileReference struct {
+	id   int64
+	path string
+}
+
+// fileReferences returns the rows of table that refer to a file
+func fileReferences(q querier, table string) ([]fileReference, error) {
+	rows, err := q.Query(`SELECT id, file_path FROM ` + table + ` WHERE file_path IS NOT NULL AND file_path != '' ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s files: %w", table, err)
+	}
+	defer rows.Close()
+
+	var refs []fileReference
+	for rows.Next() {
+		var ref fileReference
+		if err := rows.Scan(&ref.id, &ref.path); err != nil {
+			return nil, fmt.Errorf("failed to scan %s file: %w", table, err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// addFileReference counts one more row referring to a stored file
+func addFileReference(q querier, blobPath string) error {
+	if _, err := q.Exec(`UPDATE file_blobs SET ref_count = ref_count + 1 WHERE path = ?`, blobPath); err != nil {
+		return fmt.Errorf("failed to reference stored file: %w", err)
+	}
+	return nil
+}
+
+// isStoredFile reports whether path is a file in the store
+func isStoredFile(q querier, path string) bool {
+	var count int
+	err := q.QueryRow(`SELECT COUNT(*) FROM file_blobs WHERE path = ?`, path).Scan(&count)
+	return err == nil && count > 0
+}
+
+// fileChecksum returns the hex SHA-256 checksum of a file
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// moveFile renames src to dst, copying when they are on different devices
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// fileExists reports whether a regular file exists at path
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,372 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeReceivedFile writes content to a new file in dir and returns its path
+// and checksum
+func writeReceivedFile(t *testing.T, dir, name, content string) (string, string) {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	sum := sha256.Sum256([]byte(content))
+	return path, hex.EncodeToString(sum[:])
+}
+
+// refCount returns the references to a stored file, or 0 if it is not stored
+func refCount(t *testing.T, db *Database, checksum string) int {
+	t.Helper()
+
+	var refs int
+	db.QueryRow(`SELECT ref_count FROM file_blobs WHERE checksum = ?`, checksum).Scan(&refs)
+	return refs
+}
+
+func TestStoreFileDeduplicates(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDatabase(t, filepath.Join(dir, "whisp.db"), nil)
+	defer db.Close()
+	storeDir := filepath.Join(dir, "files")
+
+	first, checksum := writeReceivedFile(t, dir, "meme.PNG", "same meme")
+	second, _ := writeReceivedFile(t, dir, "meme (1).png", "same meme")
+	other, otherChecksum := writeReceivedFile(t, dir, "other.png", "another meme")
+
+	var stored []string
+	for _, src := range []struct{ path, checksum string }{{first, checksum}, {second, checksum}, {other, otherChecksum}} {
+		path, err := db.StoreFile(src.path, src.checksum, storeDir)
+		if err != nil {
+			t.Fatalf("StoreFile failed: %v", err)
+		}
+		if _, err := os.Stat(src.path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be moved into the store", filepath.Base(src.path))
+		}
+		stored = append(stored, path)
+	}
+
+	if stored[0] != stored[1] {
+		t.Errorf("Expected identical files to share %s, got %s", stored[0], stored[1])
+	}
+	if stored[0] != filepath.Join(storeDir, checksum+".png") {
+		t.Errorf("Expected the stored file to be named by its checksum, got %s", stored[0])
+	}
+	if stored[2] == stored[0] {
+		t.Error("Expected different content to be stored separately")
+	}
+	entries, _ := os.ReadDir(storeDir)
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 files in the store, got %d", len(entries))
+	}
+	if got := refCount(t, db, checksum); got != 2 {
+		t.Errorf("Expected 2 references, got %d", got)
+	}
+	if got := refCount(t, db, otherChecksum); got != 1 {
+		t.Errorf("Expected 1 reference, got %d", got)
+	}
+}
+
+func TestReleaseFile(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDatabase(t, filepath.Join(dir, "whisp.db"), nil)
+	defer db.Close()
+	storeDir := filepath.Join(dir, "files")
+
+	var stored string
+	var checksum string
+	for _, name := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+		var path string
+		path, checksum = writeReceivedFile(t, dir, name, "forwarded a lot")
+		var err error
+		if stored, err = db.StoreFile(path, checksum, storeDir); err != nil {
+			t.Fatalf("StoreFile failed: %v", err)
+		}
+	}
+
+	for want := 2; want > 0; want-- {
+		if err := db.ReleaseFile(stored); err != nil {
+			t.Fatalf("ReleaseFile failed: %v", err)
+		}
+		if got := refCount(t, db, checksum); got != want {
+			t.Errorf("Expected %d references, got %d", want, got)
+		}
+		if _, err := os.Stat(stored); err != nil {
+			t.Fatalf("Expected the file to be kept while referenced: %v", err)
+		}
+	}
+
+	if err := db.ReleaseFile(stored); err != nil {
+		t.Fatalf("ReleaseFile failed: %v", err)
+	}
+	if _, err := os.Stat(stored); !os.IsNotExist(err) {
+		t.Error("Expected the file to be removed with its last reference")
+	}
+	if isStoredFile(db, stored) {
+		t.Error("Expected the store to forget the file")
+	}
+
+	// The same content received again is stored afresh
+	path, _ := writeReceivedFile(t, dir, "d.jpg", "forwarded a lot")
+	if _, err := db.StoreFile(path, checksum, storeDir); err != nil {
+		t.Fatalf("StoreFile failed: %v", err)
+	}
+	if got := refCount(t, db, checksum); got != 1 {
+		t.Errorf("Expected 1 reference after storing again, got %d", got)
+	}
+
+	// Files from before the store are removed right away
+	legacy, _ := writeReceivedFile(t, dir, "legacy.jpg", "old")
+	if err := db.ReleaseFile(legacy); err != nil {
+		t.Fatalf("ReleaseFile failed: %v", err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Error("Expected a file outside the store to be removed")
+	}
+}
+
+func TestRetainFile(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDatabase(t, filepath.Join(dir, "whisp.db"), nil)
+	defer db.Close()
+
+	path, checksum := writeReceivedFile(t, dir, "shared.png", "forward me")
+	stored, err := db.StoreFile(path, checksum, filepath.Join(dir, "files"))
+	if err != nil {
+		t.Fatalf("StoreFile failed: %v", err)
+	}
+	if err := db.RetainFile(stored); err != nil {
+		t.Fatalf("RetainFile failed: %v", err)
+	}
+	if got := refCount(t, db, checksum); got != 2 {
+		t.Errorf("Expected 2 references, got %d", got)
+	}
+
+	// Releasing the original keeps the file for the copy
+	if err := db.ReleaseFile(stored); err != nil {
+		t.Fatalf("ReleaseFile failed: %v", err)
+	}
+	if _, err := os.Stat(stored); err != nil {
+		t.Errorf("Expected the retained file to be kept: %v", err)
+	}
+
+	legacy, _ := writeReceivedFile(t, dir, "legacy.png", "old")
+	if err := db.RetainFile(legacy); err == nil {
+		t.Error("Expected an error retaining a file outside the store")
+	}
+}
+
+func TestDedupFiles(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDatabase(t, filepath.Join(dir, "whisp.db"), nil)
+	defer db.Close()
+	storeDir := filepath.Join(dir, "files")
+
+	dup1, checksum := writeReceivedFile(t, dir, "one.gif", "dancing cat")
+	dup2, _ := writeReceivedFile(t, dir, "two.gif", "dancing cat")
+	unique, _ := writeReceivedFile(t, dir, "three.gif", "sleeping cat")
+
+	now := time.Now()
+	var ids []int64
+	for i, path := range []string{dup1, dup2, dup1, unique, filepath.Join(dir, "missing.gif")} {
+		res, err := db.Exec(`INSERT INTO messages (uuid, friend_id, content, is_outgoing, timestamp, file_path) VALUES (?, 1, '', 0, ?, ?)`,
+			filepath.Base(path)+string(rune('a'+i)), now, path)
+		if err != nil {
+			t.Fatalf("Failed to insert message: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		ids = append(ids, id)
+	}
+	if _, err := db.Exec(`INSERT INTO file_transfers (friend_id, file_name, file_size, file_path, is_outgoing, started_at) VALUES (1, 'two.gif', 11, ?, 0, ?)`, dup2, now); err != nil {
+		t.Fatalf("Failed to insert transfer: %v", err)
+	}
+
+	removed, err := db.DedupFiles(storeDir)
+	if err != nil {
+		t.Fatalf("DedupFiles failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 duplicate removed, got %d", removed)
+	}
+
+	stored := filepath.Join(storeDir, checksum+".gif")
+	for _, id := range ids[:3] {
+		var path string
+		db.QueryRow(`SELECT file_path FROM messages WHERE id = ?`, id).Scan(&path)
+		if path != stored {
+			t.Errorf("Expected message %d to point at %s, got %s", id, stored, path)
+		}
+	}
+	var transferPath string
+	db.QueryRow(`SELECT file_path FROM file_transfers`).Scan(&transferPath)
+	if transferPath != stored {
+		t.Errorf("Expected the transfer to point at %s, got %s", stored, transferPath)
+	}
+	if got := refCount(t, db, checksum); got != 3 {
+		t.Errorf("Expected 3 references to the shared file, got %d", got)
+	}
+	for _, path := range []string{dup1, dup2, unique} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be moved into the store", filepath.Base(path))
+		}
+	}
+
+	// It runs only once
+	again, _ := writeReceivedFile(t, dir, "again.gif", "dancing cat")
+	if _, err := db.Exec(`UPDATE messages SET file_path = ? WHERE id = ?`, again, ids[4]); err != nil {
+		t.Fatalf("Failed to update message: %v", err)
+	}
+	if removed, err := db.DedupFiles(storeDir); err != nil || removed != 0 {
+		t.Errorf("Expected a second run to do nothing, got %d: %v", removed, err)
+	}
+	if _, err := os.Stat(again); err != nil {
+		t.Error("Expected a second run to leave files alone")
+	}
+}
+
+func TestDedupFilesTransferHoldsNoReference(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDatabase(t, filepath.Join(dir, "whisp.db"), nil)
+	defer db.Close()
+	storeDir := filepath.Join(dir, "files")
+
+	shared, checksum := writeReceivedFile(t, dir, "shared.gif", "dancing cat")
+	transferOnly, _ := writeReceivedFile(t, dir, "sent.gif", "sleeping cat")
+
+	now := time.Now()
+	if _, err := db.Exec(`INSERT INTO messages (uuid, friend_id, content, is_outgoing, timestamp, file_path) VALUES ('a', 1, '', 0, ?, ?)`, now, shared); err != nil {
+		t.Fatalf("Failed to insert message: %v", err)
+	}
+	for _, path := range []string{shared, transferOnly} {
+		if _, err := db.Exec(`INSERT INTO file_transfers (friend_id, file_name, file_size, file_path, is_outgoing, started_at) VALUES (1, ?, 11, ?, 0, ?)`,
+			filepath.Base(path), path, now); err != nil {
+			t.Fatalf("Failed to insert transfer: %v", err)
+		}
+	}
+
+	if _, err := db.DedupFiles(storeDir); err != nil {
+		t.Fatalf("DedupFiles failed: %v", err)
+	}
+	stored := filepath.Join(storeDir, checksum+".gif")
+	var transferPath string
+	db.QueryRow(`SELECT file_path FROM file_transfers WHERE file_name = 'shared.gif'`).Scan(&transferPath)
+	if transferPath != stored {
+		t.Errorf("Expected the transfer to point at %s, got %s", stored, transferPath)
+	}
+	if got := refCount(t, db, checksum); got != 1 {
+		t.Errorf("Expected only the message to reference the file, got %d", got)
+	}
+	if _, err := os.Stat(transferOnly); err != nil {
+		t.Errorf("Expected a file no message uses to stay in place: %v", err)
+	}
+
+	// Deleting the message frees the file
+	if err := db.ReleaseFile(stored); err != nil {
+		t.Fatalf("ReleaseFile failed: %v", err)
+	}
+	if _, err := os.Stat(stored); !os.IsNotExist(err) {
+		t.Error("Expected the file to be removed with its last message")
+	}
+	if got := refCount(t, db, checksum); got != 0 {
+		t.Errorf("Expected the file to leave the store, got %d references", got)
+	}
+}
+
+func TestCompactDatabaseReleasesFiles(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDatabase(t, filepath.Join(dir, "whisp.db"), nil)
+	defer db.Close()
+	storeDir := filepath.Join(dir, "files")
+
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	var stored, checksum string
+	for i, deleted := range []bool{true, false} {
+		path, sum := writeReceivedFile(t, dir, "shared.png", "shared")
+		var err error
+		if stored, err = db.StoreFile(path, sum, storeDir); err != nil {
+			t.Fatalf("StoreFile failed: %v", err)
+		}
+		checksum = sum
+		if _, err := db.Exec(`INSERT INTO messages (uuid, friend_id, content, is_outgoing, timestamp, file_path, is_deleted) VALUES (?, 1, '', 0, ?, ?, ?)`,
+			string(rune('a'+i)), old, stored, deleted); err != nil {
+			t.Fatalf("Failed to insert message: %v", err)
+		}
+	}
+
+	if _, err := db.CompactDatabase(time.Now()); err != nil {
+		t.Fatalf("CompactDatabase failed: %v", err)
+	}
+	if got := refCount(t, db, checksum); got != 1 {
+		t.Errorf("Expected the purged message's reference to be released, got %d", got)
+	}
+	if _, err := os.Stat(stored); err != nil {
+		t.Errorf("Expected the file the kept message uses to stay: %v", err)
+	}
+}
+
+func TestDedupFilesRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDatabase(t, filepath.Join(dir, "whisp.db"), nil)
+	defer db.Close()
+	storeDir := filepath.Join(dir, "files")
+
+	dup1, checksum := writeReceivedFile(t, dir, "one.gif", "dancing cat")
+	dup2, _ := writeReceivedFile(t, dir, "two.gif", "dancing cat")
+
+	now := time.Now()
+	for i, path := range []string{dup1, dup2} {
+		if _, err := db.Exec(`INSERT INTO messages (uuid, friend_id, content, is_outgoing, timestamp, file_path) VALUES (?, 1, '', 0, ?, ?)`,
+			string(rune('a'+i)), now, path); err != nil {
+			t.Fatalf("Failed to insert message: %v", err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO file_transfers (friend_id, file_name, file_size, file_path, is_outgoing, started_at) VALUES (1, 'two.gif', 11, ?, 0, ?)`, dup2, now); err != nil {
+		t.Fatalf("Failed to insert transfer: %v", err)
+	}
+
+	// The transfer row cannot be updated after the messages were moved
+	if _, err := db.Exec(`CREATE TRIGGER block_transfers BEFORE UPDATE ON file_transfers BEGIN SELECT RAISE(ABORT, 'blocked'); END`); err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+	if _, err := db.DedupFiles(storeDir); err == nil {
+		t.Fatal("Expected DedupFiles to fail")
+	}
+
+	var paths []string
+	rows, err := db.Query(`SELECT file_path FROM messages ORDER BY id`)
+	if err != nil {
+		t.Fatalf("Failed to query messages: %v", err)
+	}
+	for rows.Next() {
+		var path string
+		rows.Scan(&path)
+		paths = append(paths, path)
+	}
+	rows.Close()
+	if len(paths) != 2 || paths[0] != dup1 || paths[1] != dup2 {
+		t.Errorf("Expected message rows to be rolled back, got %v", paths)
+	}
+	for _, path := range []string{dup1, dup2} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be moved back", filepath.Base(path))
+		}
+	}
+	if got := refCount(t, db, checksum); got != 0 {
+		t.Errorf("Expected no stored file after rollback, got %d references", got)
+	}
+
+	// The migration was not recorded, so it runs again once the rows can be updated
+	if _, err := db.Exec(`DROP TRIGGER block_transfers`); err != nil {
+		t.Fatalf("Failed to drop trigger: %v", err)
+	}
+	if removed, err := db.DedupFiles(storeDir); err != nil || removed != 1 {
+		t.Errorf("Expected the retried migration to remove 1 duplicate, got %d: %v", removed, err)
+	}
+}
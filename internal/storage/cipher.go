@@ -0,0 +1,414 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+
+	sqlite3 "github.com/mutecomm/go-sqlcipher/v4"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// cipherParamsSuffix names the file recording the cipher settings of a database
+const cipherParamsSuffix = ".cipher"
+
+// CipherParams are the SQLCipher settings an encrypted database is keyed with.
+// Zero or empty fields use the defaults of the compatibility version.
+type CipherParams struct {
+	Compatibility int    `json:"compatibility"` // SQLCipher major version whose defaults apply
+	KDFIterations int    `json:"kdf_iterations,omitempty"`
+	PageSize      int    `json:"page_size,omitempty"`
+	HMACAlgorithm string `json:"hmac_algorithm,omitempty"` // e.g. HMAC_SHA512
+	KDFAlgorithm  string `json:"kdf_algorithm,omitempty"`  // e.g. PBKDF2_HMAC_SHA512
+}
+
+// HMACAlgorithms are the page HMAC algorithms SQLCipher supports
+var HMACAlgorithms = []string{"HMAC_SHA1", "HMAC_SHA256", "HMAC_SHA512"}
+
+// KDFAlgorithms are the key derivation algorithms SQLCipher supports
+var KDFAlgorithms = []string{"PBKDF2_HMAC_SHA1", "PBKDF2_HMAC_SHA256", "PBKDF2_HMAC_SHA512"}
+
+// DefaultCipherParams returns the settings encrypted databases were created
+// with before they became configurable
+func DefaultCipherParams() CipherParams {
+	return CipherParams{Compatibility: 4}
+}
+
+// Validate checks the parameters are supported by SQLCipher
+func (p CipherParams) Validate() error {
+	if p.Compatibility < 1 || p.Compatibility > 4 {
+		return fmt.Errorf("cipher compatibility must be between 1 and 4, got %d", p.Compatibility)
+	}
+	if p.KDFIterations < 0 {
+		return fmt.Errorf("KDF iterations cannot be negative, got %d", p.KDFIterations)
+	}
+	if p.PageSize != 0 && (p.PageSize < 512 || p.PageSize > 65536 || p.PageSize&(p.PageSize-1) != 0) {
+		return fmt.Errorf("cipher page size must be a power of two between 512 and 65536, got %d", p.PageSize)
+	}
+	if p.HMACAlgorithm != "" && !slices.Contains(HMACAlgorithms, p.HMACAlgorithm) {
+		return fmt.Errorf("unsupported cipher HMAC algorithm %q", p.HMACAlgorithm)
+	}
+	if p.KDFAlgorithm != "" && !slices.Contains(KDFAlgorithms, p.KDFAlgorithm) {
+		return fmt.Errorf("unsupported cipher KDF algorithm %q", p.KDFAlgorithm)
+	}
+	return nil
+}
+
+// cipherPragmas names the pragmas that apply cipher settings, either as
+// SQLCipher's defaults or to one database
+type cipherPragmas struct {
+	compatibility, kdfIter, pageSize, hmacAlgorithm, kdfAlgorithm string
+}
+
+// defaultCipherPragmas set the settings SQLCipher applies to newly keyed databases
+var defaultCipherPragmas = cipherPragmas{
+	"cipher_default_compatibility", "cipher_default_kdf_iter", "cipher_default_page_size",
+	"cipher_default_hmac_algorithm", "cipher_default_kdf_algorithm",
+}
+
+// attachedCipherPragmas returns the pragmas setting up an attached database
+func attachedCipherPragmas(schema string) cipherPragmas {
+	return cipherPragmas{
+		schema + ".cipher_compatibility", schema + ".kdf_iter", schema + ".cipher_page_size",
+		schema + ".cipher_hmac_algorithm", schema + ".cipher_kdf_algorithm",
+	}
+}
+
+// pragmas returns the statements applying the parameters with the given pragmas
+func (p CipherParams) pragmas(names cipherPragmas) []string {
+	// The compatibility pragma resets the other settings, so it goes first
+	pragmas := []string{fmt.Sprintf("PRAGMA %s = %d", names.compatibility, p.Compatibility)}
+	if p.KDFIterations > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA %s = %d", names.kdfIter, p.KDFIterations))
+	}
+	if p.PageSize > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA %s = %d", names.pageSize, p.PageSize))
+	}
+	// Validate limits the algorithms to known names, so they are safe to inline
+	if p.HMACAlgorithm != "" {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA %s = %s", names.hmacAlgorithm, p.HMACAlgorithm))
+	}
+	if p.KDFAlgorithm != "" {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA %s = %s", names.kdfAlgorithm, p.KDFAlgorithm))
+	}
+	return pragmas
+}
+
+// cipherDefaultsMu serializes opening encrypted connections. The driver reads
+// the database right after keying it, so the settings have to be in place as
+// SQLCipher's process-wide defaults when the connection is opened.
+var cipherDefaultsMu sync.Mutex
+
+// setCipherDefaults sets the settings SQLCipher applies to newly keyed databases
+func setCipherDefaults(params CipherParams) error {
+	conn, err := (&sqlite3.SQLiteDriver{}).Open(":memory:")
+	if err != nil {
+		return fmt.Errorf("failed to open connection for cipher settings: %w", err)
+	}
+	defer conn.Close()
+
+	execer := conn.(*sqlite3.SQLiteConn)
+	for _, pragma := range params.pragmas(defaultCipherPragmas) {
+		if _, err := execer.Exec(pragma, nil); err != nil {
+			return fmt.Errorf("failed to apply %q: %w", pragma, err)
+		}
+	}
+	return nil
+}
+
+// cipherConnector opens SQLCipher connections keyed with fixed cipher settings.
+// Every pooled connection gets the key and settings before it reads the file.
+type cipherConnector struct {
+	dsn    string
+	params CipherParams
+	driver *sqlite3.SQLiteDriver
+}
+
+// newCipherConnector creates a connector for dbPath using the hex-encoded key
+func newCipherConnector(dbPath, hexKey string, params CipherParams) *cipherConnector {
+	query := url.Values{}
+	query.Set("_pragma_key", hexKey)
+	query.Set("_busy_timeout", "5000")
+
+	return &cipherConnector{
+		dsn:    fmt.Sprintf("file:%s?%s", dbPath, query.Encode()),
+		params: params,
+		driver: &sqlite3.SQLiteDriver{},
+	}
+}
+
+// Connect opens a new keyed connection
+func (c *cipherConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	cipherDefaultsMu.Lock()
+	defer cipherDefaultsMu.Unlock()
+
+	if err := setCipherDefaults(c.params); err != nil {
+		return nil, err
+	}
+	// DefaultCipherParams match SQLCipher's own defaults
+	defer func() {
+		if err := setCipherDefaults(DefaultCipherParams()); err != nil {
+			logging.Warnf("Failed to restore cipher defaults: %v", err)
+		}
+	}()
+
+	return c.driver.Open(c.dsn)
+}
+
+// Driver returns the underlying SQLCipher driver
+func (c *cipherConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// openCipherDB opens an encrypted database and checks the key and settings
+// can read it
+func openCipherDB(dbPath, hexKey string, params CipherParams) (*sql.DB, error) {
+	db := sql.OpenDB(newCipherConnector(dbPath, hexKey, params))
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master").Scan(&count); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read database with the given key and cipher settings: %w", err)
+	}
+	return db, nil
+}
+
+// databaseKeyHex returns the database key in the hex form used as the
+// SQLCipher passphrase
+func databaseKeyHex(securityManager SecurityManager) (string, error) {
+	keyBytes, err := securityManager.GetDatabaseKeyBytes()
+	if err != nil {
+		return "", fmt.Errorf("failed to get database key bytes: %w", err)
+	}
+	defer func() {
+		// Clear key bytes from memory
+		for i := range keyBytes {
+			keyBytes[i] = 0
+		}
+	}()
+	return fmt.Sprintf("%x", keyBytes), nil
+}
+
+// cipherParamsPath returns the file recording the cipher settings of dbPath
+func cipherParamsPath(dbPath string) string {
+	return dbPath + cipherParamsSuffix
+}
+
+// LoadCipherParams returns the cipher settings an existing encrypted database
+// was created with. Databases created before the settings were recorded use
+// DefaultCipherParams.
+func LoadCipherParams(dbPath string) (CipherParams, error) {
+	data, err := os.ReadFile(cipherParamsPath(dbPath))
+	if os.IsNotExist(err) {
+		return DefaultCipherParams(), nil
+	}
+	if err != nil {
+		return CipherParams{}, fmt.Errorf("failed to read cipher settings: %w", err)
+	}
+
+	var params CipherParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		return CipherParams{}, fmt.Errorf("failed to parse cipher settings: %w", err)
+	}
+	if err := params.Validate(); err != nil {
+		return CipherParams{}, fmt.Errorf("invalid cipher settings in %s: %w", cipherParamsPath(dbPath), err)
+	}
+	return params, nil
+}
+
+// saveCipherParams records the cipher settings of dbPath
+func saveCipherParams(dbPath string, params CipherParams) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode cipher settings: %w", err)
+	}
+	if err := os.WriteFile(cipherParamsPath(dbPath), data, 0600); err != nil {
+		return fmt.Errorf("failed to write cipher settings: %w", err)
+	}
+	return nil
+}
+
+// databaseExists reports whether dbPath holds an existing, non-empty database
+func databaseExists(dbPath string) bool {
+	info, err := os.Stat(dbPath)
+	return err == nil && info.Size() > 0
+}
+
+// MigrateCipherParams re-encrypts an existing database with new cipher
+// settings. The database must be closed. Opening a database never changes its
+// settings, so this is the only way to apply new ones.
+func MigrateCipherParams(dbPath string, securityManager SecurityManager, params CipherParams) error {
+	if securityManager == nil {
+		return fmt.Errorf("security manager is required to migrate cipher settings")
+	}
+	if err := params.Validate(); err != nil {
+		return fmt.Errorf("invalid cipher settings: %w", err)
+	}
+	if !databaseExists(dbPath) {
+		return fmt.Errorf("database %s does not exist", dbPath)
+	}
+
+	current, err := LoadCipherParams(dbPath)
+	if err != nil {
+		return err
+	}
+	if current == params {
+		return nil
+	}
+
+	hexKey, err := databaseKeyHex(securityManager)
+	if err != nil {
+		return err
+	}
+
+	migratedPath := dbPath + ".migrating"
+	os.Remove(migratedPath)
+
+	if err := exportWithCipherParams(dbPath, migratedPath, hexKey, current, params); err != nil {
+		os.Remove(migratedPath)
+		return err
+	}
+
+	if err := os.Rename(migratedPath, dbPath); err != nil {
+		os.Remove(migratedPath)
+		return fmt.Errorf("failed to replace database: %w", err)
+	}
+	return saveCipherParams(dbPath, params)
+}
+
+// exportWithCipherParams copies the database at srcPath into a new database at
+// dstPath encrypted with the same key and the new settings
+func exportWithCipherParams(srcPath, dstPath, hexKey string, current, params CipherParams) error {
+	db, err := openCipherDB(srcPath, hexKey, current)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// ATTACH and the export must share one connection
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS migrated KEY ?", dstPath, hexKey); err != nil {
+		return fmt.Errorf("failed to create migrated database: %w", err)
+	}
+	for _, pragma := range params.pragmas(attachedCipherPragmas("migrated")) {
+		if _, err := conn.ExecContext(ctx, pragma); err != nil {
+			return fmt.Errorf("failed to apply %q: %w", pragma, err)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT sqlcipher_export('migrated')"); err != nil {
+		return fmt.Errorf("failed to export database: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "DETACH DATABASE migrated"); err != nil {
+		return fmt.Errorf("failed to detach migrated database: %w", err)
+	}
+	return nil
+}
+
+// plaintextHeader starts every unencrypted SQLite database file
+var plaintextHeader = []byte("SQLite format 3\x00")
+
+// IsPlaintextDatabase reports whether dbPath holds an unencrypted database
+func IsPlaintextDatabase(dbPath string) bool {
+	f, err := os.Open(dbPath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	header := make([]byte, len(plaintextHeader))
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false
+	}
+	return bytes.Equal(header, plaintextHeader)
+}
+
+// EncryptDatabase encrypts an existing unencrypted database in place with the
+// given cipher settings. The database must be closed. Missing and already
+// encrypted databases are left alone.
+func EncryptDatabase(dbPath string, securityManager SecurityManager, params CipherParams) error {
+	if securityManager == nil {
+		return fmt.Errorf("security manager is required to encrypt a database")
+	}
+	if err := params.Validate(); err != nil {
+		return fmt.Errorf("invalid cipher settings: %w", err)
+	}
+	if !IsPlaintextDatabase(dbPath) {
+		return nil
+	}
+
+	hexKey, err := databaseKeyHex(securityManager)
+	if err != nil {
+		return err
+	}
+
+	encryptedPath := dbPath + ".encrypting"
+	os.Remove(encryptedPath)
+
+	if err := exportEncrypted(dbPath, encryptedPath, hexKey, params); err != nil {
+		os.Remove(encryptedPath)
+		return err
+	}
+
+	if err := os.Rename(encryptedPath, dbPath); err != nil {
+		os.Remove(encryptedPath)
+		return fmt.Errorf("failed to replace database: %w", err)
+	}
+	// The write-ahead log belonged to the unencrypted file and was checkpointed
+	os.Remove(dbPath + "-wal")
+	os.Remove(dbPath + "-shm")
+
+	logging.Infof("Encrypted database %s", dbPath)
+	return saveCipherParams(dbPath, params)
+}
+
+// exportEncrypted copies the unencrypted database at srcPath into a new
+// database at dstPath encrypted with the key and settings
+func exportEncrypted(srcPath, dstPath, hexKey string, params CipherParams) error {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s", srcPath))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	// ATTACH and the export must share one connection
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("failed to checkpoint database: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS encrypted KEY ?", dstPath, hexKey); err != nil {
+		return fmt.Errorf("failed to create encrypted database: %w", err)
+	}
+	for _, pragma := range params.pragmas(attachedCipherPragmas("encrypted")) {
+		if _, err := conn.ExecContext(ctx, pragma); err != nil {
+			return fmt.Errorf("failed to apply %q: %w", pragma, err)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT sqlcipher_export('encrypted')"); err != nil {
+		return fmt.Errorf("failed to export database: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "DETACH DATABASE encrypted"); err != nil {
+		return fmt.Errorf("failed to detach encrypted database: %w", err)
+	}
+	return nil
+}
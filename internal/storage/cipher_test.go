@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// insertSetting stores a marker row so reopened databases can be checked
+func insertSetting(t *testing.T, db *Database, value string) {
+	t.Helper()
+	if _, err := db.Exec("INSERT INTO settings (key, value, updated_at) VALUES ('marker', ?, datetime('now'))", value); err != nil {
+		t.Fatalf("Failed to insert marker: %v", err)
+	}
+}
+
+// assertSetting checks the marker row written by insertSetting
+func assertSetting(t *testing.T, db *Database, want string) {
+	t.Helper()
+	var value string
+	if err := db.QueryRow("SELECT value FROM settings WHERE key = 'marker'").Scan(&value); err != nil {
+		t.Fatalf("Failed to read marker: %v", err)
+	}
+	if value != want {
+		t.Errorf("Expected marker %q, got %q", want, value)
+	}
+}
+
+func TestCipherParamsOpenWithMatchingSettings(t *testing.T) {
+	securityManager := &MockSecurityManager{dbKey: "key"}
+	key, err := databaseKeyHex(securityManager)
+	if err != nil {
+		t.Fatalf("databaseKeyHex failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		params   CipherParams
+		mismatch CipherParams
+	}{
+		{"sqlcipher 3", CipherParams{Compatibility: 3}, CipherParams{Compatibility: 4}},
+		{"sqlcipher 4", CipherParams{Compatibility: 4}, CipherParams{Compatibility: 3}},
+		{"custom", CipherParams{Compatibility: 4, KDFIterations: 64000, PageSize: 8192}, CipherParams{Compatibility: 4}},
+		{"algorithms", CipherParams{Compatibility: 4, HMACAlgorithm: "HMAC_SHA256", KDFAlgorithm: "PBKDF2_HMAC_SHA256"}, CipherParams{Compatibility: 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbPath := filepath.Join(t.TempDir(), "cipher.db")
+
+			db, err := NewDatabaseWithCipher(dbPath, securityManager, tt.params)
+			if err != nil {
+				t.Fatalf("Failed to create database: %v", err)
+			}
+			insertSetting(t, db, tt.name)
+			db.Close()
+
+			recorded, err := LoadCipherParams(dbPath)
+			if err != nil || recorded != tt.params {
+				t.Fatalf("Expected recorded settings %+v, got %+v (%v)", tt.params, recorded, err)
+			}
+
+			// Reopening with different settings uses the recorded ones
+			db, err = NewDatabaseWithCipher(dbPath, securityManager, tt.mismatch)
+			if err != nil {
+				t.Fatalf("Failed to reopen database: %v", err)
+			}
+			assertSetting(t, db, tt.name)
+			db.Close()
+
+			// The file itself only opens with its own settings
+			if _, err := openCipherDB(dbPath, key, tt.mismatch); err == nil {
+				t.Error("Expected mismatched cipher settings to fail")
+			}
+			raw, err := openCipherDB(dbPath, key, tt.params)
+			if err != nil {
+				t.Fatalf("Expected matching cipher settings to open: %v", err)
+			}
+			raw.Close()
+		})
+	}
+}
+
+func TestCipherParamsLegacyDatabase(t *testing.T) {
+	securityManager := &MockSecurityManager{dbKey: "key"}
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	db, err := NewDatabaseWithEncryption(dbPath, securityManager)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	insertSetting(t, db, "legacy")
+	db.Close()
+
+	// Databases from before the settings were recorded use the defaults
+	if err := os.Remove(cipherParamsPath(dbPath)); err != nil {
+		t.Fatalf("Failed to remove cipher settings: %v", err)
+	}
+	db, err = NewDatabaseWithCipher(dbPath, securityManager, CipherParams{Compatibility: 3})
+	if err != nil {
+		t.Fatalf("Failed to open legacy database: %v", err)
+	}
+	defer db.Close()
+	assertSetting(t, db, "legacy")
+}
+
+func TestMigrateCipherParams(t *testing.T) {
+	securityManager := &MockSecurityManager{dbKey: "key"}
+	dbPath := filepath.Join(t.TempDir(), "migrate.db")
+	from := CipherParams{Compatibility: 3}
+	to := CipherParams{Compatibility: 4, KDFIterations: 100000, PageSize: 8192, HMACAlgorithm: "HMAC_SHA256", KDFAlgorithm: "PBKDF2_HMAC_SHA256"}
+
+	db, err := NewDatabaseWithCipher(dbPath, securityManager, from)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	insertSetting(t, db, "migrated")
+	db.Close()
+
+	if err := MigrateCipherParams(dbPath, securityManager, to); err != nil {
+		t.Fatalf("MigrateCipherParams failed: %v", err)
+	}
+
+	recorded, err := LoadCipherParams(dbPath)
+	if err != nil || recorded != to {
+		t.Fatalf("Expected recorded settings %+v, got %+v (%v)", to, recorded, err)
+	}
+	if _, err := os.Stat(dbPath + ".migrating"); !os.IsNotExist(err) {
+		t.Error("Expected the temporary migration file to be removed")
+	}
+
+	key, _ := databaseKeyHex(securityManager)
+	if _, err := openCipherDB(dbPath, key, from); err == nil {
+		t.Error("Expected the old settings to no longer open the database")
+	}
+
+	db, err = NewDatabaseWithCipher(dbPath, securityManager, to)
+	if err != nil {
+		t.Fatalf("Failed to open migrated database: %v", err)
+	}
+	defer db.Close()
+	assertSetting(t, db, "migrated")
+}
+
+func TestCipherParamsValidate(t *testing.T) {
+	tests := []struct {
+		params  CipherParams
+		wantErr bool
+	}{
+		{DefaultCipherParams(), false},
+		{CipherParams{Compatibility: 3, KDFIterations: 64000, PageSize: 1024}, false},
+		{CipherParams{Compatibility: 0}, true},
+		{CipherParams{Compatibility: 5}, true},
+		{CipherParams{Compatibility: 4, KDFIterations: -1}, true},
+		{CipherParams{Compatibility: 4, PageSize: 1000}, true},
+		{CipherParams{Compatibility: 4, PageSize: 256}, true},
+		{CipherParams{Compatibility: 4, HMACAlgorithm: "HMAC_SHA512", KDFAlgorithm: "PBKDF2_HMAC_SHA1"}, false},
+		{CipherParams{Compatibility: 4, HMACAlgorithm: "MD5"}, true},
+		{CipherParams{Compatibility: 4, KDFAlgorithm: "HMAC_SHA512; DROP TABLE x"}, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.params, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEncryptDatabase(t *testing.T) {
+	securityManager := &MockSecurityManager{dbKey: "key"}
+	dbPath := filepath.Join(t.TempDir(), "plain.db")
+
+	// Missing databases are left alone
+	if err := EncryptDatabase(dbPath, securityManager, DefaultCipherParams()); err != nil {
+		t.Fatalf("EncryptDatabase of a missing database failed: %v", err)
+	}
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	insertSetting(t, db, "kept")
+	db.Close()
+	if !IsPlaintextDatabase(dbPath) {
+		t.Fatal("Expected an unencrypted database")
+	}
+
+	if err := EncryptDatabase(dbPath, securityManager, DefaultCipherParams()); err != nil {
+		t.Fatalf("EncryptDatabase failed: %v", err)
+	}
+	if IsPlaintextDatabase(dbPath) {
+		t.Fatal("Expected the database to be encrypted")
+	}
+	// Encrypting again is a no-op
+	if err := EncryptDatabase(dbPath, securityManager, DefaultCipherParams()); err != nil {
+		t.Fatalf("Second EncryptDatabase failed: %v", err)
+	}
+
+	db, err = NewDatabaseWithEncryption(dbPath, securityManager)
+	if err != nil {
+		t.Fatalf("Failed to open encrypted database: %v", err)
+	}
+	defer db.Close()
+	assertSetting(t, db, "kept")
+}
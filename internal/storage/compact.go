@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// lastCompactedKey is the settings key recording the last compaction
+const lastCompactedKey = "last_compacted_at"
+
+// CompactResult reports what CompactDatabase did
+type CompactResult struct {
+	PurgedMessages int64 // Deleted messages removed for good
+	BytesReclaimed int64 // Shrinkage of the database files on disk
+}
+
+// CompactDatabase shrinks the database file. Messages marked deleted and sent
+// before purgeBefore are removed for good, with their reactions and files; a zero
+// purgeBefore keeps them. The search index is then rebuilt and the database
+// vacuumed, keeping its encryption and journal mode.
+func (d *Database) CompactDatabase(purgeBefore time.Time) (*CompactResult, error) {
+	if d.IsLocked() {
+		return nil, fmt.Errorf("database is locked")
+	}
+	if err := d.WaitAsync(DefaultAsyncTimeout); err != nil {
+		logging.Warnf("%v", err)
+	}
+
+	d.compactMu.Lock()
+	defer d.compactMu.Unlock()
+
+	before := d.Size()
+	result := &CompactResult{}
+
+	tx, err := d.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var filePaths []string
+	if !purgeBefore.IsZero() {
+		if result.PurgedMessages, filePaths, err = purgeDeletedMessages(tx, purgeBefore); err != nil {
+			return nil, err
+		}
+	}
+	if err := rebuildSearchIndex(tx); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO settings (key, value, updated_at) VALUES (?, ?, ?)`,
+		lastCompactedKey, time.Now().UTC().Format(time.RFC3339), time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to record compaction: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit compaction: %w", err)
+	}
+	for _, filePath := range filePaths {
+		if err := d.ReleaseFile(filePath); err != nil {
+			logging.Warnf("Failed to remove media file %s: %v", filePath, err)
+		}
+	}
+
+	// VACUUM cannot run inside a transaction, and the checkpoint that moves
+	// it out of the write-ahead log must follow on the same connection
+	ctx := context.Background()
+	conn, err := d.conn().Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "VACUUM"); err != nil {
+		return nil, fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		logging.Warnf("Failed to checkpoint WAL: %v", err)
+	}
+
+	result.BytesReclaimed = max(before-d.Size(), 0)
+	logging.Infof("Compacted database %s: purged %d deleted messages, reclaimed %d bytes",
+		d.path, result.PurgedMessages, result.BytesReclaimed)
+	return result, nil
+}
+
+// LastCompacted returns when the database was last compacted, or the zero
+// time if it never was
+func (d *Database) LastCompacted() (time.Time, error) {
+	var value string
+	err := d.QueryRow(`SELECT value FROM settings WHERE key = ?`, lastCompactedKey).Scan(&value)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read last compaction: %w", err)
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// purgeDeletedMessages removes messages marked deleted and sent before
+// cutoff, detaching the replies and file transfers that point at them. It
+// returns the files of the removed messages, to release once committed.
+func purgeDeletedMessages(tx *sql.Tx, cutoff time.Time) (int64, []string, error) {
+	const purged = `SELECT id FROM messages WHERE is_deleted = 1 AND timestamp < ?`
+
+	rows, err := tx.Query(`SELECT file_path FROM messages WHERE is_deleted = 1 AND timestamp < ? AND file_path IS NOT NULL AND file_path != ''`, cutoff)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to query message files: %w", err)
+	}
+	var filePaths []string
+	for rows.Next() {
+		var filePath string
+		if err := rows.Scan(&filePath); err != nil {
+			rows.Close()
+			return 0, nil, fmt.Errorf("failed to scan message file: %w", err)
+		}
+		filePaths = append(filePaths, filePath)
+	}
+	rows.Close()
+
+	if _, err := tx.Exec(`UPDATE messages SET reply_to_id = NULL WHERE reply_to_id IN (`+purged+`)`, cutoff); err != nil {
+		return 0, nil, fmt.Errorf("failed to detach replies: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE file_transfers SET message_id = NULL WHERE message_id IN (`+purged+`)`, cutoff); err != nil {
+		return 0, nil, fmt.Errorf("failed to detach file transfers: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM message_reactions WHERE message_uuid IN (
+		SELECT uuid FROM messages WHERE is_deleted = 1 AND timestamp < ?)`, cutoff); err != nil {
+		return 0, nil, fmt.Errorf("failed to delete reactions: %w", err)
+	}
+
+	res, err := tx.Exec(`DELETE FROM messages WHERE is_deleted = 1 AND timestamp < ?`, cutoff)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to purge deleted messages: %w", err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to count purged messages: %w", err)
+	}
+	return count, filePaths, nil
+}
+
+// rebuildSearchIndex refills the message search index from the visible
+// messages and merges it into as few segments as possible. Databases without
+// FTS5 have no index to rebuild.
+func rebuildSearchIndex(tx *sql.Tx) error {
+	var schema string
+	err := tx.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'messages_fts'`).Scan(&schema)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check search index: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM messages_fts`); err != nil {
+		return fmt.Errorf("failed to clear search index: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO messages_fts(rowid, content)
+		SELECT id, content FROM messages WHERE is_deleted = 0 AND content IS NOT NULL AND content != ''`); err != nil {
+		return fmt.Errorf("failed to repopulate search index: %w", err)
+	}
+	if strings.Contains(strings.ToLower(schema), "fts5") {
+		if _, err := tx.Exec(`INSERT INTO messages_fts(messages_fts) VALUES ('optimize')`); err != nil {
+			return fmt.Errorf("failed to optimize search index: %w", err)
+		}
+	}
+	return nil
+}
+
+// Size returns the size of the database and its write-ahead log on disk
+func (d *Database) Size() int64 {
+	var size int64
+	for _, path := range []string{d.path, d.path + "-wal"} {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// insertMessageAt adds a message for friend 1 sent at ts
+func insertMessageAt(t *testing.T, db *Database, uuid, content string, ts time.Time) int64 {
+	t.Helper()
+
+	res, err := db.Exec(`INSERT INTO messages (uuid, friend_id, content, is_outgoing, timestamp) VALUES (?, 1, ?, 0, ?)`, uuid, content, ts)
+	if err != nil {
+		t.Fatalf("Failed to insert message: %v", err)
+	}
+	id, _ := res.LastInsertId()
+	return id
+}
+
+func TestCompactDatabase(t *testing.T) {
+	tests := []struct {
+		name            string
+		securityManager SecurityManager
+	}{
+		{"unencrypted", nil},
+		{"encrypted", &MockSecurityManager{dbKey: "compact-key"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDatabase(t, filepath.Join(t.TempDir(), "whisp.db"), tt.securityManager)
+			defer db.Close()
+
+			old := time.Now().Add(-60 * 24 * time.Hour)
+			padding := strings.Repeat("x", 2000)
+			var deleted []int64
+			for i := 0; i < 200; i++ {
+				id := insertMessageAt(t, db, fmt.Sprintf("old-%d", i), fmt.Sprintf("searchable %d %s", i, padding), old)
+				if i%2 == 0 {
+					deleted = append(deleted, id)
+				}
+			}
+			recent := insertMessageAt(t, db, "recent", "searchable recent", time.Now())
+			reply := insertMessageAt(t, db, "reply", "searchable reply", time.Now())
+			if _, err := db.Exec(`UPDATE messages SET reply_to_id = ? WHERE id = ?`, deleted[0], reply); err != nil {
+				t.Fatalf("Failed to set reply: %v", err)
+			}
+			if _, err := db.Exec(`INSERT INTO message_reactions (message_uuid, friend_id, emoji, is_outgoing, timestamp) VALUES ('old-0', 1, '👍', 1, ?)`, time.Now()); err != nil {
+				t.Fatalf("Failed to insert reaction: %v", err)
+			}
+			for _, id := range append(deleted, recent) {
+				if _, err := db.Exec(`UPDATE messages SET is_deleted = 1 WHERE id = ?`, id); err != nil {
+					t.Fatalf("Failed to delete message: %v", err)
+				}
+			}
+
+			hasFTS := db.isFTS5Available()
+			if hasFTS {
+				// An index entry left behind for a deleted message
+				if _, err := db.Exec(`INSERT INTO messages_fts(rowid, content) VALUES (?, 'searchable stray')`, deleted[1]); err != nil {
+					t.Fatalf("Failed to add stray index entry: %v", err)
+				}
+			}
+
+			result, err := db.CompactDatabase(time.Now().Add(-30 * 24 * time.Hour))
+			if err != nil {
+				t.Fatalf("CompactDatabase failed: %v", err)
+			}
+
+			if result.PurgedMessages != int64(len(deleted)) {
+				t.Errorf("Expected %d purged messages, got %d", len(deleted), result.PurgedMessages)
+			}
+			if result.BytesReclaimed <= 0 {
+				t.Errorf("Expected bytes to be reclaimed, got %d", result.BytesReclaimed)
+			}
+
+			var remaining int
+			db.QueryRow(`SELECT COUNT(*) FROM messages WHERE uuid LIKE 'old-%' AND is_deleted = 1`).Scan(&remaining)
+			if remaining != 0 {
+				t.Errorf("Expected old deleted messages to be gone, %d remain", remaining)
+			}
+			var recentDeleted int
+			db.QueryRow(`SELECT COUNT(*) FROM messages WHERE id = ? AND is_deleted = 1`, recent).Scan(&recentDeleted)
+			if recentDeleted != 1 {
+				t.Error("Expected a deleted message inside the retention window to be kept")
+			}
+			if got := countMessages(t, db); got != 100+2 {
+				t.Errorf("Expected 102 messages left, got %d", got)
+			}
+
+			var replyTo *int64
+			db.QueryRow(`SELECT reply_to_id FROM messages WHERE id = ?`, reply).Scan(&replyTo)
+			if replyTo != nil {
+				t.Errorf("Expected the reply to be detached from the purged message, got %d", *replyTo)
+			}
+			var reactions int
+			db.QueryRow(`SELECT COUNT(*) FROM message_reactions`).Scan(&reactions)
+			if reactions != 0 {
+				t.Errorf("Expected reactions of purged messages to be gone, %d remain", reactions)
+			}
+
+			if hasFTS {
+				var indexed, stale int
+				db.QueryRow(`SELECT COUNT(*) FROM messages_fts WHERE messages_fts MATCH 'searchable'`).Scan(&indexed)
+				if indexed != 100+1 {
+					t.Errorf("Expected 101 visible messages in the search index, got %d", indexed)
+				}
+				db.QueryRow(`SELECT COUNT(*) FROM messages_fts WHERE rowid IN (SELECT id FROM messages WHERE is_deleted = 1) OR rowid NOT IN (SELECT id FROM messages)`).Scan(&stale)
+				if stale != 0 {
+					t.Errorf("Expected no search index entries for deleted messages, got %d", stale)
+				}
+			}
+
+			last, err := db.LastCompacted()
+			if err != nil || time.Since(last) > time.Minute {
+				t.Errorf("Expected the compaction time to be recorded, got %v: %v", last, err)
+			}
+		})
+	}
+}
+
+func TestCompactDatabaseWithoutPurge(t *testing.T) {
+	db := openTestDatabase(t, filepath.Join(t.TempDir(), "whisp.db"), nil)
+	defer db.Close()
+
+	id := insertMessageAt(t, db, "old", "old", time.Now().Add(-365*24*time.Hour))
+	if _, err := db.Exec(`UPDATE messages SET is_deleted = 1 WHERE id = ?`, id); err != nil {
+		t.Fatalf("Failed to delete message: %v", err)
+	}
+	if last, err := db.LastCompacted(); err != nil || !last.IsZero() {
+		t.Errorf("Expected no compaction recorded yet, got %v: %v", last, err)
+	}
+
+	result, err := db.CompactDatabase(time.Time{})
+	if err != nil {
+		t.Fatalf("CompactDatabase failed: %v", err)
+	}
+	if result.PurgedMessages != 0 || countMessages(t, db) != 1 {
+		t.Error("Expected deleted messages to be kept without a purge cutoff")
+	}
+}
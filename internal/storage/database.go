@@ -3,13 +3,15 @@ package storage
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	_ "github.com/mutecomm/go-sqlcipher/v4"
+
+	"github.com/opd-ai/whisp/internal/logging"
 )
 
 // Database wraps the SQLite database connection with encryption support
@@ -17,6 +19,13 @@ type Database struct {
 	db        *sql.DB
 	path      string
 	encrypted bool
+	async     asyncTracker
+
+	connMu sync.RWMutex // Guards db and locked while the database is locked or unlocked
+	locked bool
+
+	compactMu sync.Mutex // Keeps a manual compaction from overlapping a scheduled one
+	blobMu    sync.Mutex // Serializes reference counting of stored files
 }
 
 // SecurityManager interface for database encryption
@@ -31,71 +40,62 @@ func NewDatabase(dbPath string) (*Database, error) {
 }
 
 // NewDatabaseWithEncryption creates a new encrypted database connection
+// using DefaultCipherParams for new databases
 func NewDatabaseWithEncryption(dbPath string, securityManager SecurityManager) (*Database, error) {
+	return NewDatabaseWithCipher(dbPath, securityManager, DefaultCipherParams())
+}
+
+// NewDatabaseWithCipher creates a new database connection, encrypting new
+// databases with the given cipher settings. Existing databases are opened with
+// the settings they were created with; use MigrateCipherParams to change them.
+func NewDatabaseWithCipher(dbPath string, securityManager SecurityManager, params CipherParams) (*Database, error) {
 	// Ensure directory exists
 	if err := ensureDir(filepath.Dir(dbPath)); err != nil {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
+	if err := ApplyPendingRestore(dbPath); err != nil {
+		return nil, err
+	}
 
-	var dsn string
 	encrypted := securityManager != nil
 
 	var db *sql.DB
 	var err error
+	created := false
 
 	if encrypted {
-		// Use SQLCipher for encrypted database - use minimal DSN due to v4 compatibility issues
-		dsn = fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=cipher_compatibility(3)", dbPath)
-		db, err = sql.Open("sqlite3", dsn)
-	} else {
-		// Use regular SQLite for unencrypted database (fallback)
-		dsn = fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)", dbPath)
-		db, err = sql.Open("sqlite3", dsn)
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
-	}
-
-	// Set encryption key if security manager is provided
-	if encrypted {
-		// Try using raw key bytes with SQLCipher v3 compatibility mode
-		keyBytes, err := securityManager.GetDatabaseKeyBytes()
-		if err != nil {
-			db.Close()
-			return nil, fmt.Errorf("failed to get database key bytes: %w", err)
+		if err := params.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid cipher settings: %w", err)
 		}
-		defer func() {
-			// Clear key bytes from memory
-			for i := range keyBytes {
-				keyBytes[i] = 0
+		if databaseExists(dbPath) {
+			recorded, err := LoadCipherParams(dbPath)
+			if err != nil {
+				return nil, err
 			}
-		}()
-
-		// Convert to hex format for SQLCipher PRAGMA key
-		hexKey := fmt.Sprintf("%x", keyBytes)
-
-		// Set the encryption key using PRAGMA key
-		// Note: cipher_compatibility(3) should already be set in DSN
-		if _, err := db.Exec("PRAGMA key = '" + hexKey + "'"); err != nil {
-			db.Close()
-			return nil, fmt.Errorf("failed to set database encryption key: %w", err)
+			if recorded != params {
+				logging.Warnf("Database %s uses cipher settings %+v, not %+v; migrate it to apply the new settings", dbPath, recorded, params)
+			}
+			params = recorded
+		} else {
+			created = true
 		}
 
-		// Test database access by attempting a simple query
-		// This will fail if encryption setup is incorrect
-		var result int
-		err = db.QueryRow("SELECT 1").Scan(&result)
+		hexKey, err := databaseKeyHex(securityManager)
 		if err != nil {
-			// Database might be new, try to create schema first
-			_, createErr := db.Exec("CREATE TABLE IF NOT EXISTS _test_encryption (id INTEGER)")
-			if createErr != nil {
-				db.Close()
-				return nil, fmt.Errorf("failed to verify database encryption: %w", err)
-			}
-			// Clean up test table
-			db.Exec("DROP TABLE IF EXISTS _test_encryption")
+			return nil, err
+		}
+		if db, err = openCipherDB(dbPath, hexKey, params); err != nil {
+			return nil, err
+		}
+	} else {
+		// Use regular SQLite for unencrypted database (fallback)
+		dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)", dbPath)
+		if db, err = sql.Open("sqlite3", dsn); err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
 		}
-	} // Test connection
+	}
+
+	// Test connection
 	if err := db.Ping(); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
@@ -119,22 +119,33 @@ func NewDatabaseWithEncryption(dbPath string, securityManager SecurityManager) (
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	if created {
+		if err := saveCipherParams(dbPath, params); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
 	encryptionStatus := "unencrypted"
 	if encrypted {
 		encryptionStatus = "encrypted"
 	}
-	log.Printf("Database initialized at %s (%s)", dbPath, encryptionStatus)
+	logging.Infof("Database initialized at %s (%s)", dbPath, encryptionStatus)
 	return storage, nil
 }
 
-// Close closes the database connection
+// Close waits for background writes and closes the database connection
 func (d *Database) Close() error {
-	if d.db != nil {
+	if d.db != nil && !d.IsLocked() {
+		if err := d.WaitAsync(DefaultAsyncTimeout); err != nil {
+			logging.Warnf("%v", err)
+		}
+
 		// For WAL mode, ensure all transactions are committed
 		// Only do this for unencrypted databases that use WAL mode
 		if !d.encrypted {
 			if _, err := d.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
-				log.Printf("Warning: Failed to checkpoint WAL: %v", err)
+				logging.Warnf("Failed to checkpoint WAL: %v", err)
 			}
 		}
 
@@ -155,22 +166,22 @@ func (d *Database) GetPath() string {
 
 // Query executes a query that returns rows
 func (d *Database) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return d.db.Query(query, args...)
+	return d.conn().Query(query, args...)
 }
 
 // QueryRow executes a query that returns a single row
 func (d *Database) QueryRow(query string, args ...interface{}) *sql.Row {
-	return d.db.QueryRow(query, args...)
+	return d.conn().QueryRow(query, args...)
 }
 
 // Exec executes a query that doesn't return rows
 func (d *Database) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return d.db.Exec(query, args...)
+	return d.conn().Exec(query, args...)
 }
 
 // Begin starts a transaction
 func (d *Database) Begin() (*sql.Tx, error) {
-	return d.db.Begin()
+	return d.conn().Begin()
 }
 
 // initSchema initializes the database schema
@@ -187,7 +198,17 @@ func (d *Database) initSchema() error {
 		avatar BLOB,
 		status INTEGER NOT NULL DEFAULT 0,
 		is_blocked BOOLEAN NOT NULL DEFAULT 0,
+		is_deleted BOOLEAN NOT NULL DEFAULT 0,
 		is_favorite BOOLEAN NOT NULL DEFAULT 0,
+		local_alias TEXT NOT NULL DEFAULT '',
+		notes TEXT NOT NULL DEFAULT '',
+		is_verified BOOLEAN NOT NULL DEFAULT 0,
+		auto_accept_files BOOLEAN NOT NULL DEFAULT 0,
+		from_request BOOLEAN NOT NULL DEFAULT 0,
+		notify_muted BOOLEAN NOT NULL DEFAULT 0,
+		notify_sound TEXT NOT NULL DEFAULT '',
+		notify_always_preview BOOLEAN NOT NULL DEFAULT 0,
+		notify_override_quiet BOOLEAN NOT NULL DEFAULT 0,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL,
 		last_seen_at DATETIME NOT NULL,
@@ -212,6 +233,10 @@ func (d *Database) initSchema() error {
 		file_type TEXT,
 		is_deleted BOOLEAN NOT NULL DEFAULT 0,
 		reply_to_id INTEGER,
+		reply_to_uuid TEXT,
+		failed_at DATETIME,
+		expires_at DATETIME,
+		is_forwarded BOOLEAN NOT NULL DEFAULT 0,
 		FOREIGN KEY (friend_id) REFERENCES contacts(friend_id),
 		FOREIGN KEY (reply_to_id) REFERENCES messages(id)
 	);
@@ -240,12 +265,108 @@ func (d *Database) initSchema() error {
 		FOREIGN KEY (message_id) REFERENCES messages(id)
 	);
 
+	-- Received files stored once per content, with the number of messages
+	-- and transfers referring to each
+	CREATE TABLE IF NOT EXISTS file_blobs (
+		checksum TEXT PRIMARY KEY,
+		path TEXT UNIQUE NOT NULL,
+		size INTEGER NOT NULL,
+		ref_count INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME NOT NULL
+	);
+
+	-- Unsent message drafts, one per conversation
+	CREATE TABLE IF NOT EXISTS drafts (
+		friend_id INTEGER PRIMARY KEY,
+		content TEXT NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	-- Emoji reactions, keyed by the message UUID shared with the peer
+	CREATE TABLE IF NOT EXISTS message_reactions (
+		message_uuid TEXT NOT NULL,
+		friend_id INTEGER NOT NULL,
+		emoji TEXT NOT NULL,
+		is_outgoing BOOLEAN NOT NULL,
+		timestamp DATETIME NOT NULL,
+		PRIMARY KEY (message_uuid, is_outgoing, emoji)
+	);
+
+	-- Disappearing message timers, agreed with the peer per conversation
+	CREATE TABLE IF NOT EXISTS disappearing_timers (
+		friend_id INTEGER PRIMARY KEY,
+		seconds INTEGER NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	-- Local message retention per conversation, never shared with the peer
+	CREATE TABLE IF NOT EXISTS retention_policies (
+		friend_id INTEGER PRIMARY KEY,
+		seconds INTEGER NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	-- Messages from unknown senders held for review by the sender policy
+	CREATE TABLE IF NOT EXISTS held_messages (
+		uuid TEXT PRIMARY KEY,
+		friend_id INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		message_type INTEGER NOT NULL DEFAULT 0,
+		timestamp DATETIME NOT NULL,
+		reply_to_uuid TEXT,
+		expires_at DATETIME,
+		is_forwarded BOOLEAN NOT NULL DEFAULT 0
+	);
+
+	-- Friends seen running Whisp, who are sent our capabilities when they come online
+	CREATE TABLE IF NOT EXISTS whisp_peers (
+		friend_id INTEGER PRIMARY KEY,
+		seen_at DATETIME NOT NULL
+	);
+
+	-- Group chats we created or joined, keyed by the group ID shared by all members
+	CREATE TABLE IF NOT EXISTS groups (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		is_joined BOOLEAN NOT NULL DEFAULT 1,
+		created_at DATETIME NOT NULL
+	);
+
+	-- Members of each group, including ourselves, by hex public key
+	CREATE TABLE IF NOT EXISTS group_members (
+		group_id TEXT NOT NULL,
+		public_key TEXT NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		has_left BOOLEAN NOT NULL DEFAULT 0,
+		joined_at DATETIME NOT NULL,
+		PRIMARY KEY (group_id, public_key)
+	);
+
+	-- Group chat messages
+	CREATE TABLE IF NOT EXISTS group_messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		uuid TEXT UNIQUE NOT NULL,
+		group_id TEXT NOT NULL,
+		sender_key TEXT NOT NULL,
+		content TEXT NOT NULL,
+		is_outgoing BOOLEAN NOT NULL,
+		timestamp DATETIME NOT NULL
+	);
+
+	-- Incoming friend requests the user has not accepted or rejected yet
+	CREATE TABLE IF NOT EXISTS friend_requests (
+		public_key TEXT PRIMARY KEY,
+		message TEXT NOT NULL,
+		received_at DATETIME NOT NULL
+	);
+
 	-- Create indexes
 	CREATE INDEX IF NOT EXISTS idx_messages_friend_id ON messages(friend_id);
 	CREATE INDEX IF NOT EXISTS idx_messages_timestamp ON messages(timestamp);
 	CREATE INDEX IF NOT EXISTS idx_messages_uuid ON messages(uuid);
 	CREATE INDEX IF NOT EXISTS idx_contacts_friend_id ON contacts(friend_id);
 	CREATE INDEX IF NOT EXISTS idx_file_transfers_friend_id ON file_transfers(friend_id);
+	CREATE INDEX IF NOT EXISTS idx_group_messages_group_id ON group_messages(group_id);
 	`
 
 	_, err := d.db.Exec(schema)
@@ -285,9 +406,7 @@ func (d *Database) runMigrations() error {
 			sql: `
 			-- Create FTS virtual table for message search optimization
 			CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(
-				content,
-				content_row='messages',
-				content_rowid='id'
+				content
 			);
 			
 			-- Populate FTS table with existing messages
@@ -309,6 +428,51 @@ func (d *Database) runMigrations() error {
 			END;
 			`,
 		},
+		{
+			version: "add_reply_to_uuid_to_messages",
+			sql:     `ALTER TABLE messages ADD COLUMN reply_to_uuid TEXT;`,
+		},
+		{
+			version: "add_local_details_to_contacts",
+			sql: `
+			ALTER TABLE contacts ADD COLUMN local_alias TEXT NOT NULL DEFAULT '';
+			ALTER TABLE contacts ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+			ALTER TABLE contacts ADD COLUMN is_verified BOOLEAN NOT NULL DEFAULT 0;
+			`,
+		},
+		{
+			version: "add_failed_at_to_messages",
+			sql:     `ALTER TABLE messages ADD COLUMN failed_at DATETIME;`,
+		},
+		{
+			version: "add_auto_accept_files_to_contacts",
+			sql:     `ALTER TABLE contacts ADD COLUMN auto_accept_files BOOLEAN NOT NULL DEFAULT 0;`,
+		},
+		{
+			version: "add_expires_at_to_messages",
+			sql:     `ALTER TABLE messages ADD COLUMN expires_at DATETIME;`,
+		},
+		{
+			version: "add_notification_overrides_to_contacts",
+			sql: `
+			ALTER TABLE contacts ADD COLUMN notify_muted BOOLEAN NOT NULL DEFAULT 0;
+			ALTER TABLE contacts ADD COLUMN notify_sound TEXT NOT NULL DEFAULT '';
+			ALTER TABLE contacts ADD COLUMN notify_always_preview BOOLEAN NOT NULL DEFAULT 0;
+			ALTER TABLE contacts ADD COLUMN notify_override_quiet BOOLEAN NOT NULL DEFAULT 0;
+			`,
+		},
+		{
+			version: "add_is_deleted_to_contacts",
+			sql:     `ALTER TABLE contacts ADD COLUMN is_deleted BOOLEAN NOT NULL DEFAULT 0;`,
+		},
+		{
+			version: "add_is_forwarded_to_messages",
+			sql:     `ALTER TABLE messages ADD COLUMN is_forwarded BOOLEAN NOT NULL DEFAULT 0;`,
+		},
+		{
+			version: "add_from_request_to_contacts",
+			sql:     `ALTER TABLE contacts ADD COLUMN from_request BOOLEAN NOT NULL DEFAULT 0;`,
+		},
 	}
 
 	// Apply migrations
@@ -333,6 +497,42 @@ func (d *Database) runMigrations() error {
 			if err := d.migrateFTSMessageSearch(); err != nil {
 				return fmt.Errorf("failed to apply FTS migration: %w", err)
 			}
+		} else if migration.version == "add_reply_to_uuid_to_messages" {
+			if err := d.addColumnIfMissing("messages", "reply_to_uuid", "TEXT"); err != nil {
+				return fmt.Errorf("failed to apply reply UUID migration: %w", err)
+			}
+		} else if migration.version == "add_local_details_to_contacts" {
+			if err := d.migrateContactLocalDetails(); err != nil {
+				return fmt.Errorf("failed to apply contact details migration: %w", err)
+			}
+		} else if migration.version == "add_failed_at_to_messages" {
+			if err := d.addColumnIfMissing("messages", "failed_at", "DATETIME"); err != nil {
+				return fmt.Errorf("failed to apply send failure migration: %w", err)
+			}
+		} else if migration.version == "add_auto_accept_files_to_contacts" {
+			if err := d.addColumnIfMissing("contacts", "auto_accept_files", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+				return fmt.Errorf("failed to apply file auto-accept migration: %w", err)
+			}
+		} else if migration.version == "add_expires_at_to_messages" {
+			if err := d.addColumnIfMissing("messages", "expires_at", "DATETIME"); err != nil {
+				return fmt.Errorf("failed to apply disappearing messages migration: %w", err)
+			}
+		} else if migration.version == "add_notification_overrides_to_contacts" {
+			if err := d.migrateContactNotificationOverrides(); err != nil {
+				return fmt.Errorf("failed to apply notification overrides migration: %w", err)
+			}
+		} else if migration.version == "add_is_deleted_to_contacts" {
+			if err := d.migrateContactDeletedFlag(); err != nil {
+				return fmt.Errorf("failed to apply contact deletion migration: %w", err)
+			}
+		} else if migration.version == "add_is_forwarded_to_messages" {
+			if err := d.addColumnIfMissing("messages", "is_forwarded", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+				return fmt.Errorf("failed to apply forwarded messages migration: %w", err)
+			}
+		} else if migration.version == "add_from_request_to_contacts" {
+			if err := d.addColumnIfMissing("contacts", "from_request", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+				return fmt.Errorf("failed to apply friend request origin migration: %w", err)
+			}
 		} else {
 			// Apply regular migration
 			if _, err := d.db.Exec(migration.sql); err != nil {
@@ -346,7 +546,7 @@ func (d *Database) runMigrations() error {
 			return fmt.Errorf("failed to record migration: %w", err)
 		}
 
-		log.Printf("Applied migration: %s", migration.version)
+		logging.Infof("Applied migration: %s", migration.version)
 	}
 
 	return nil
@@ -428,11 +628,112 @@ func (d *Database) migrateAddUUIDToMessages() error {
 	return tx.Commit()
 }
 
+// hasColumn reports whether a table already has the named column
+func (d *Database) hasColumn(table, column string) (bool, error) {
+	rows, err := d.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("failed to get table info: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, dataType string
+		var notNull, primaryKey int
+		var defaultValue sql.NullString
+
+		if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return false, fmt.Errorf("failed to scan column info: %w", err)
+		}
+
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
+
+// addColumnIfMissing adds a column to a table unless it was already created by initSchema
+func (d *Database) addColumnIfMissing(table, column, definition string) error {
+	exists, err := d.hasColumn(table, column)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err := d.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add %s column: %w", column, err)
+	}
+
+	return nil
+}
+
+// migrateContactLocalDetails adds the columns for data the user keeps about a
+// contact that is never sent to the peer
+func (d *Database) migrateContactLocalDetails() error {
+	columns := []struct{ name, definition string }{
+		{"local_alias", "TEXT NOT NULL DEFAULT ''"},
+		{"notes", "TEXT NOT NULL DEFAULT ''"},
+		{"is_verified", "BOOLEAN NOT NULL DEFAULT 0"},
+	}
+
+	for _, column := range columns {
+		if err := d.addColumnIfMissing("contacts", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateContactNotificationOverrides adds the per-contact notification
+// settings to the contacts table if they don't exist
+func (d *Database) migrateContactNotificationOverrides() error {
+	columns := []struct{ name, definition string }{
+		{"notify_muted", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"notify_sound", "TEXT NOT NULL DEFAULT ''"},
+		{"notify_always_preview", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"notify_override_quiet", "BOOLEAN NOT NULL DEFAULT 0"},
+	}
+
+	for _, column := range columns {
+		if err := d.addColumnIfMissing("contacts", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateContactDeletedFlag separates deleted contacts from blocked ones.
+// Deleting used to set is_blocked, so those rows become deleted instead.
+func (d *Database) migrateContactDeletedFlag() error {
+	exists, err := d.hasColumn("contacts", "is_deleted")
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	if err := d.addColumnIfMissing("contacts", "is_deleted", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if _, err := d.db.Exec(`UPDATE contacts SET is_deleted = 1, is_blocked = 0 WHERE is_blocked = 1`); err != nil {
+		return fmt.Errorf("failed to mark deleted contacts: %w", err)
+	}
+
+	return nil
+}
+
 // migrateFTSMessageSearch creates the FTS virtual table and associated triggers for optimized message search
 func (d *Database) migrateFTSMessageSearch() error {
 	// First check if FTS5 is available
 	if !d.isFTS5Available() {
-		log.Printf("FTS5 not available, skipping FTS migration")
+		logging.Infof("FTS5 not available, skipping FTS migration")
 		return nil
 	}
 
@@ -445,9 +746,7 @@ func (d *Database) migrateFTSMessageSearch() error {
 	// Create FTS virtual table for message search optimization
 	ftsSchema := `
 	CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(
-		content,
-		content_row='messages',
-		content_rowid='id'
+		content
 	);`
 
 	if _, err := tx.Exec(ftsSchema); err != nil {
@@ -476,9 +775,7 @@ func (d *Database) migrateFTSMessageSearch() error {
 	WHEN NEW.content IS NOT NULL AND NEW.content != ''
 	BEGIN
 		DELETE FROM messages_fts WHERE rowid = OLD.id;
-		CASE WHEN NEW.is_deleted = 0 THEN
-			INSERT INTO messages_fts(rowid, content) VALUES (NEW.id, NEW.content);
-		END;
+		INSERT INTO messages_fts(rowid, content) SELECT NEW.id, NEW.content WHERE NEW.is_deleted = 0;
 	END;`
 
 	deleteTrigger := `
@@ -492,7 +789,7 @@ func (d *Database) migrateFTSMessageSearch() error {
 		}
 	}
 
-	log.Printf("FTS5 message search index created successfully")
+	logging.Infof("FTS5 message search index created successfully")
 	return tx.Commit()
 }
 
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"crypto/sha256"
 	"os"
 	"path/filepath"
 	"testing"
@@ -24,8 +25,9 @@ func (m *MockSecurityManager) GetDatabaseKeyBytes() ([]byte, error) {
 	if len(m.dbKey) == 0 {
 		return nil, nil
 	}
-	// For testing, just return a fixed 32-byte key
-	return []byte("test-key-32-bytes-for-testing!!!"), nil
+	// For testing, derive a 32-byte key so different keys stay different
+	sum := sha256.Sum256([]byte(m.dbKey))
+	return sum[:], nil
 }
 
 func TestNewDatabase(t *testing.T) {
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/opd-ai/whisp/internal/logging"
+)
+
+// conn returns the current connection. While the database is locked it is
+// closed, so queries fail instead of running without the key.
+func (d *Database) conn() *sql.DB {
+	d.connMu.RLock()
+	defer d.connMu.RUnlock()
+	return d.db
+}
+
+// Lock closes the connection of an encrypted database so SQLCipher no longer
+// holds its key in memory. Queries fail until Unlock reopens it.
+func (d *Database) Lock() error {
+	if !d.encrypted {
+		return fmt.Errorf("only encrypted databases can be locked")
+	}
+	if err := d.WaitAsync(DefaultAsyncTimeout); err != nil {
+		logging.Warnf("%v", err)
+	}
+
+	d.connMu.Lock()
+	defer d.connMu.Unlock()
+
+	if d.locked {
+		return nil
+	}
+	d.locked = true
+	if err := d.db.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+	return nil
+}
+
+// Unlock reopens a locked database with the key derived by the security manager
+func (d *Database) Unlock(securityManager SecurityManager) error {
+	d.connMu.Lock()
+	defer d.connMu.Unlock()
+
+	if !d.locked {
+		return nil
+	}
+
+	params, err := LoadCipherParams(d.path)
+	if err != nil {
+		return err
+	}
+	hexKey, err := databaseKeyHex(securityManager)
+	if err != nil {
+		return err
+	}
+	db, err := openCipherDB(d.path, hexKey, params)
+	if err != nil {
+		return err
+	}
+
+	d.db = db
+	d.locked = false
+	return nil
+}
+
+// IsLocked reports whether the database is locked
+func (d *Database) IsLocked() bool {
+	d.connMu.RLock()
+	defer d.connMu.RUnlock()
+	return d.locked
+}
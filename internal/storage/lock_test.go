@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDatabaseLockUnlock(t *testing.T) {
+	securityManager := &MockSecurityManager{dbKey: "key"}
+	db, err := NewDatabaseWithEncryption(filepath.Join(t.TempDir(), "locked.db"), securityManager)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	insertSetting(t, db, "before lock")
+
+	if err := db.Lock(); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if !db.IsLocked() {
+		t.Fatal("Expected the database to be locked")
+	}
+	if _, err := db.Exec("SELECT 1"); err == nil {
+		t.Error("Expected queries to fail while locked")
+	}
+
+	// A wrong key leaves it locked
+	if err := db.Unlock(&MockSecurityManager{dbKey: "other"}); err == nil || !db.IsLocked() {
+		t.Fatalf("Expected unlocking with the wrong key to fail, got %v", err)
+	}
+	if err := db.Unlock(securityManager); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	assertSetting(t, db, "before lock")
+}
+
+func TestUnencryptedDatabaseCannotLock(t *testing.T) {
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "plain.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Lock(); err == nil || db.IsLocked() {
+		t.Errorf("Expected an unencrypted database to refuse locking, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// stdinReader is shared so input typed ahead of a prompt is not lost
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// StdinIsTerminal reports whether stdin is a terminal that can be prompted,
+// rather than e.g. /dev/null when started from a desktop launcher
+func StdinIsTerminal() bool {
+	return isTerminal(int(os.Stdin.Fd()))
+}
+
+// ReadPassword prints a prompt to stderr and reads a line from stdin without
+// echoing it. Where echo cannot be turned off the input stays visible.
+func ReadPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	restore, err := disableEcho(int(os.Stdin.Fd()))
+	if err != nil {
+		log.Printf("Warning: Cannot hide password input: %v", err)
+	} else {
+		defer func() {
+			restore()
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,16 @@
+//go:build darwin
+
+package common
+
+import "golang.org/x/sys/unix"
+
+// disableEcho turns off terminal echo on fd and returns a function restoring it
+func disableEcho(fd int) (func(), error) {
+	return setEcho(fd, unix.TIOCGETA, unix.TIOCSETA)
+}
+
+// isTerminal reports whether fd is a terminal
+func isTerminal(fd int) bool {
+	_, err := unix.IoctlGetTermios(fd, unix.TIOCGETA)
+	return err == nil
+}
@@ -0,0 +1,16 @@
+//go:build linux
+
+package common
+
+import "golang.org/x/sys/unix"
+
+// disableEcho turns off terminal echo on fd and returns a function restoring it
+func disableEcho(fd int) (func(), error) {
+	return setEcho(fd, unix.TCGETS, unix.TCSETS)
+}
+
+// isTerminal reports whether fd is a terminal
+func isTerminal(fd int) bool {
+	_, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	return err == nil
+}
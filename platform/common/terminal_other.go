@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+
+package common
+
+import "fmt"
+
+// disableEcho is not supported on this platform
+func disableEcho(fd int) (func(), error) {
+	return nil, fmt.Errorf("not supported on this platform")
+}
+
+// isTerminal cannot tell terminals apart on this platform, so it reports
+// none and callers use their graphical prompts
+func isTerminal(fd int) bool {
+	return false
+}
@@ -0,0 +1,20 @@
+//go:build linux || darwin
+
+package common
+
+import "golang.org/x/sys/unix"
+
+// setEcho turns off echo using the platform's get and set termios requests
+func setEcho(fd int, getRequest, setRequest uint) (func(), error) {
+	saved, err := unix.IoctlGetTermios(fd, getRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	silent := *saved
+	silent.Lflag &^= unix.ECHO
+	if err := unix.IoctlSetTermios(fd, setRequest, &silent); err != nil {
+		return nil, err
+	}
+	return func() { unix.IoctlSetTermios(fd, setRequest, saved) }, nil
+}
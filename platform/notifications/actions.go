@@ -0,0 +1,58 @@
+package notifications
+
+import (
+	"errors"
+	"log"
+)
+
+// errActionsUnsupported is returned where notifications cannot show actions
+var errActionsUnsupported = errors.New("notification actions are not supported on this platform")
+
+// actionNotifier shows notifications with action buttons through a platform
+// notification service that supports them. Pressed actions are reported to
+// the callback given when it was created, with the typed text for actions
+// that take input.
+type actionNotifier interface {
+	// Notify shows a notification and returns the ID the platform gave it.
+	// Actions taking input are left out unless the platform can ask for text.
+	Notify(title, body, iconPath, soundFile string, actions []Action) (uint32, error)
+
+	// Close stops listening for actions
+	Close() error
+}
+
+// actionCallback receives an action pressed on a notification shown by an
+// actionNotifier
+type actionCallback func(platformID uint32, actionID, input string)
+
+// actionNotifierLocked returns the notifier for notifications with actions,
+// connecting on first use, or nil if the platform can't show them (requires
+// lock)
+func (m *CrossPlatformManager) actionNotifierLocked() actionNotifier {
+	if !m.actionsTried {
+		m.actionsTried = true
+		notifier, err := newActionNotifier(m.handlePlatformAction)
+		if err != nil {
+			log.Printf("Notification actions unavailable: %v", err)
+		} else {
+			m.actions = notifier
+		}
+	}
+	return m.actions
+}
+
+// handlePlatformAction dispatches an action pressed on a platform
+// notification to the action handler
+func (m *CrossPlatformManager) handlePlatformAction(platformID uint32, actionID, input string) {
+	m.mu.Lock()
+	notificationID, exists := m.platformIDs[platformID]
+	delete(m.platformIDs, platformID)
+	m.mu.Unlock()
+
+	if !exists {
+		return // Not one of ours, or already handled
+	}
+	if err := m.InvokeAction(notificationID, actionID, input); err != nil {
+		log.Printf("Warning: Failed to handle notification action: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+//go:build linux
+
+package notifications
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Freedesktop notification service (Desktop Notifications Specification 1.2)
+const (
+	dbusNotifications     = "org.freedesktop.Notifications"
+	dbusNotificationsPath = dbus.ObjectPath("/org/freedesktop/Notifications")
+)
+
+// dbusInlineReply is the action key of a typed reply. KDE Plasma shows a text
+// field for it and reports the text with the NotificationReplied signal.
+const dbusInlineReply = "inline-reply"
+
+// dbusNotifier shows notifications with actions over D-Bus
+type dbusNotifier struct {
+	conn        *dbus.Conn
+	service     dbus.BusObject
+	inlineReply bool // The server can ask for a typed reply
+	onAction    actionCallback
+	signals     chan *dbus.Signal
+
+	mu   sync.Mutex
+	sent map[uint32]string // Platform ID -> ID of the action taking input, if any
+}
+
+// newActionNotifier connects to the notification service on the session bus
+func newActionNotifier(onAction actionCallback) (actionNotifier, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+
+	service := conn.Object(dbusNotifications, dbusNotificationsPath)
+	var capabilities []string
+	if err := service.Call(dbusNotifications+".GetCapabilities", 0).Store(&capabilities); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to query notification server: %w", err)
+	}
+	if !slices.Contains(capabilities, "actions") {
+		conn.Close()
+		return nil, errActionsUnsupported
+	}
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(dbusNotificationsPath),
+		dbus.WithMatchInterface(dbusNotifications),
+	); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to listen for notification actions: %w", err)
+	}
+
+	n := &dbusNotifier{
+		conn:        conn,
+		service:     service,
+		inlineReply: slices.Contains(capabilities, dbusInlineReply),
+		onAction:    onAction,
+		signals:     make(chan *dbus.Signal, 16),
+		sent:        make(map[uint32]string),
+	}
+	conn.Signal(n.signals)
+	go n.listen()
+	return n, nil
+}
+
+// Notify shows a notification with its actions
+func (n *dbusNotifier) Notify(title, body, iconPath, soundFile string, actions []Action) (uint32, error) {
+	keys, inputAction := dbusActions(actions, n.inlineReply)
+
+	hints := map[string]dbus.Variant{}
+	if soundFile != "" {
+		hints["sound-file"] = dbus.MakeVariant(soundFile)
+	}
+
+	var platformID uint32
+	err := n.service.Call(dbusNotifications+".Notify", 0,
+		"Whisp", uint32(0), iconPath, title, body, keys, hints, int32(-1),
+	).Store(&platformID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	n.mu.Lock()
+	n.sent[platformID] = inputAction
+	n.mu.Unlock()
+	return platformID, nil
+}
+
+// Close disconnects from the session bus, which ends listen
+func (n *dbusNotifier) Close() error {
+	return n.conn.Close()
+}
+
+// listen reports actions on our notifications until the connection closes
+func (n *dbusNotifier) listen() {
+	for signal := range n.signals {
+		if len(signal.Body) < 2 {
+			continue
+		}
+		platformID, ok := signal.Body[0].(uint32)
+		if !ok {
+			continue
+		}
+
+		n.mu.Lock()
+		inputAction, ours := n.sent[platformID]
+		if ours {
+			switch signal.Name {
+			case dbusNotifications + ".ActionInvoked", dbusNotifications + ".NotificationReplied",
+				dbusNotifications + ".NotificationClosed":
+				delete(n.sent, platformID)
+			}
+		}
+		n.mu.Unlock()
+		if !ours {
+			continue // Another application's notification
+		}
+
+		switch signal.Name {
+		case dbusNotifications + ".ActionInvoked":
+			if key, ok := signal.Body[1].(string); ok && key != dbusInlineReply && key != "default" {
+				n.onAction(platformID, key, "")
+			}
+		case dbusNotifications + ".NotificationReplied":
+			if text, ok := signal.Body[1].(string); ok && inputAction != "" {
+				n.onAction(platformID, inputAction, text)
+			}
+		}
+	}
+}
+
+// dbusActions flattens actions into the key, label pairs the Notify method
+// takes. An action taking input becomes the inline reply when the server
+// supports it and is left out otherwise; its ID is returned to map replies
+// back to it.
+func dbusActions(actions []Action, inlineReply bool) (keys []string, inputAction string) {
+	keys = []string{}
+	for _, action := range actions {
+		key := action.ID
+		if action.Input {
+			if !inlineReply || inputAction != "" {
+				continue
+			}
+			key, inputAction = dbusInlineReply, action.ID
+		}
+		keys = append(keys, key, action.Title)
+	}
+	return keys, inputAction
+}
@@ -0,0 +1,45 @@
+//go:build linux
+
+package notifications
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDBusActions(t *testing.T) {
+	actions := []Action{
+		{ID: ActionReply, Title: "Reply", Input: true},
+		{ID: ActionMarkRead, Title: "Mark as Read"},
+	}
+
+	tests := []struct {
+		name        string
+		inlineReply bool
+		wantKeys    []string
+		wantInput   string
+	}{
+		{
+			name:        "inline reply supported",
+			inlineReply: true,
+			wantKeys:    []string{dbusInlineReply, "Reply", ActionMarkRead, "Mark as Read"},
+			wantInput:   ActionReply,
+		},
+		{
+			name:     "inline reply unsupported",
+			wantKeys: []string{ActionMarkRead, "Mark as Read"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, input := dbusActions(actions, tt.inlineReply)
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+			if input != tt.wantInput {
+				t.Errorf("input action = %q, want %q", input, tt.wantInput)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package notifications
+
+// newActionNotifier reports that this platform has no notification service
+// with actions; notifications are shown without them
+func newActionNotifier(onAction actionCallback) (actionNotifier, error) {
+	return nil, errActionsUnsupported
+}
@@ -0,0 +1,76 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeActionNotifier records notifications instead of showing them
+type fakeActionNotifier struct {
+	nextID  uint32
+	actions []Action
+	closed  bool
+}
+
+func (f *fakeActionNotifier) Notify(title, body, iconPath, soundFile string, actions []Action) (uint32, error) {
+	f.nextID++
+	f.actions = actions
+	return f.nextID, nil
+}
+
+func (f *fakeActionNotifier) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestShowWithPlatformActions(t *testing.T) {
+	fake := &fakeActionNotifier{}
+	manager := NewCrossPlatformManager("")
+	manager.actions, manager.actionsTried = fake, true
+
+	var gotAction, gotInput string
+	manager.SetActionHandler(func(n *Notification, actionID, input string) {
+		gotAction, gotInput = actionID, input
+	})
+
+	notification := NewMessageNotificationWithActions("Alice", "hi", 42)
+	if err := manager.Show(context.Background(), notification); err != nil {
+		t.Fatalf("Show failed: %v", err)
+	}
+	if len(fake.actions) != 2 {
+		t.Fatalf("Expected 2 actions passed to the platform, got %d", len(fake.actions))
+	}
+
+	// Actions on other applications' notifications are ignored
+	manager.handlePlatformAction(99, ActionMarkRead, "")
+	if gotAction != "" {
+		t.Errorf("Handler called for an unknown notification: %q", gotAction)
+	}
+
+	manager.handlePlatformAction(fake.nextID, ActionReply, "hello")
+	if gotAction != ActionReply || gotInput != "hello" {
+		t.Errorf("Handler got action=%q input=%q", gotAction, gotInput)
+	}
+
+	if err := manager.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !fake.closed {
+		t.Error("Expected the action notifier to be closed")
+	}
+}
+
+func TestShowWithoutPlatformActions(t *testing.T) {
+	manager := NewCrossPlatformManager("")
+	manager.actionsTried = true // No notifier: the platform can't show actions
+
+	notification := NewMessageNotificationWithActions("Alice", "hi", 42)
+	_ = manager.Show(context.Background(), notification) // No desktop notifier in tests
+
+	if len(notification.Actions) != 0 {
+		t.Errorf("Expected actions to be left out, got %v", notification.Actions)
+	}
+	if err := manager.InvokeAction(notification.ID, ActionReply, "hello"); err == nil {
+		t.Error("Expected error invoking an action that was not shown")
+	}
+}
@@ -0,0 +1,63 @@
+package notifications
+
+import (
+	"image/color"
+	"path/filepath"
+	"strings"
+)
+
+// Appearance is the theme variant notifications are styled for
+type Appearance string
+
+const (
+	// AppearanceLight matches the light app theme
+	AppearanceLight Appearance = "light"
+	// AppearanceDark matches the dark app theme
+	AppearanceDark Appearance = "dark"
+)
+
+// ParseAppearance converts a theme variant name, defaulting to light
+func ParseAppearance(variant string) Appearance {
+	if strings.EqualFold(variant, string(AppearanceDark)) {
+		return AppearanceDark
+	}
+	return AppearanceLight
+}
+
+// AssetSet holds the theme-dependent assets used to render a notification
+type AssetSet struct {
+	IconPath string
+	Accent   color.NRGBA
+}
+
+// Accent colors match the primary color of the app's light and dark themes
+var (
+	lightAccent = color.NRGBA{R: 25, G: 118, B: 210, A: 255}
+	darkAccent  = color.NRGBA{R: 144, G: 202, B: 249, A: 255}
+)
+
+// selectAssets picks the icon variant and accent for an appearance. Dark
+// notifications use an "-dark" sibling of the icon (icon-dark.png) when one
+// exists, falling back to the regular icon.
+func selectAssets(appearance Appearance, iconPath string, exists func(string) bool) AssetSet {
+	if appearance != AppearanceDark {
+		return AssetSet{IconPath: iconPath, Accent: lightAccent}
+	}
+
+	assets := AssetSet{IconPath: iconPath, Accent: darkAccent}
+	if iconPath != "" {
+		ext := filepath.Ext(iconPath)
+		darkIcon := strings.TrimSuffix(iconPath, ext) + "-dark" + ext
+		if exists(darkIcon) {
+			assets.IconPath = darkIcon
+		}
+	}
+
+	return assets
+}
+
+// iconExists reports whether an icon file is present
+func iconExists(path string) bool {
+	ok, _ := fileExists(path)
+	return ok
+}
@@ -0,0 +1,75 @@
+package notifications
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelectAssets(t *testing.T) {
+	present := map[string]bool{"icons/whisp-dark.png": true}
+	exists := func(path string) bool { return present[path] }
+
+	tests := []struct {
+		name       string
+		appearance Appearance
+		iconPath   string
+		wantIcon   string
+		wantAccent bool // true for the dark accent
+	}{
+		{"light uses base icon", AppearanceLight, "icons/whisp.png", "icons/whisp.png", false},
+		{"dark uses dark variant", AppearanceDark, "icons/whisp.png", "icons/whisp-dark.png", true},
+		{"dark falls back to base icon", AppearanceDark, "icons/other.png", "icons/other.png", true},
+		{"dark without icon", AppearanceDark, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assets := selectAssets(tt.appearance, tt.iconPath, exists)
+			if assets.IconPath != tt.wantIcon {
+				t.Errorf("Expected icon %q, got %q", tt.wantIcon, assets.IconPath)
+			}
+			wantAccent := lightAccent
+			if tt.wantAccent {
+				wantAccent = darkAccent
+			}
+			if assets.Accent != wantAccent {
+				t.Errorf("Expected accent %v, got %v", wantAccent, assets.Accent)
+			}
+		})
+	}
+}
+
+func TestParseAppearance(t *testing.T) {
+	for input, want := range map[string]Appearance{"dark": AppearanceDark, "Dark": AppearanceDark, "light": AppearanceLight, "": AppearanceLight} {
+		if got := ParseAppearance(input); got != want {
+			t.Errorf("ParseAppearance(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestCrossPlatformManagerSetAppearance(t *testing.T) {
+	dir := t.TempDir()
+	icon := filepath.Join(dir, "whisp.png")
+	darkIcon := filepath.Join(dir, "whisp-dark.png")
+	for _, path := range []string{icon, darkIcon} {
+		if err := os.WriteFile(path, []byte("png"), 0o644); err != nil {
+			t.Fatalf("Failed to write icon: %v", err)
+		}
+	}
+
+	manager := NewCrossPlatformManager(icon)
+	if got := manager.Assets().IconPath; got != icon {
+		t.Errorf("Expected light icon by default, got %q", got)
+	}
+
+	manager.SetAppearance(AppearanceDark)
+	if got := manager.Assets(); got.IconPath != darkIcon || got.Accent != darkAccent {
+		t.Errorf("Expected dark assets, got %+v", got)
+	}
+
+	manager.SetAppearance(AppearanceLight)
+	if got := manager.Assets().IconPath; got != icon {
+		t.Errorf("Expected light icon after switching back, got %q", got)
+	}
+}
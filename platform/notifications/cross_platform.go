@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"sync"
@@ -19,6 +20,14 @@ type CrossPlatformManager struct {
 	platform     adaptive.Platform
 	activeNotifs map[string]*Notification
 	iconPath     string
+	appearance   Appearance
+	assets       AssetSet
+	onAction     ActionHandler
+	overrides    OverrideLookup
+	actions      actionNotifier    // Shows notifications with actions, if the platform can
+	actionsTried bool              // Whether connecting the action notifier was attempted
+	platformIDs  map[uint32]string // Platform notification ID -> notification ID
+	history      *notificationHistory
 }
 
 // NewCrossPlatformManager creates a new cross-platform notification manager
@@ -32,7 +41,11 @@ func NewCrossPlatformManager(iconPath string) *CrossPlatformManager {
 		},
 		platform:     adaptive.DetectPlatform(),
 		activeNotifs: make(map[string]*Notification),
+		platformIDs:  make(map[uint32]string),
+		history:      newNotificationHistory(HistoryLimit),
 		iconPath:     iconPath,
+		appearance:   AppearanceLight,
+		assets:       selectAssets(AppearanceLight, iconPath, iconExists),
 	}
 }
 
@@ -41,16 +54,6 @@ func (m *CrossPlatformManager) Show(ctx context.Context, notification *Notificat
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Check if notifications are enabled
-	if !m.config.Enabled {
-		return nil // Silently ignore if disabled
-	}
-
-	// Check quiet hours
-	if m.config.QuietHours.IsQuietTime() {
-		return nil // Silently ignore during quiet hours
-	}
-
 	// Validate notification
 	if notification == nil {
 		return errors.New("notification cannot be nil")
@@ -59,27 +62,60 @@ func (m *CrossPlatformManager) Show(ctx context.Context, notification *Notificat
 		return errors.New("notification title cannot be empty")
 	}
 
+	// Apply the global settings and any override for the friend
+	var override ContactOverride
+	if friendID, ok := notification.FriendID(); ok && m.overrides != nil {
+		override, _ = m.overrides(friendID)
+	}
+	d := resolveDelivery(m.config, notification, override, m.config.QuietHours.IsQuietTime())
+	if !d.show {
+		m.history.record(notification, d.reason)
+		return nil // Silently ignore if disabled, muted or quiet
+	}
+
 	// Prepare notification content based on config
 	title := notification.Title
 	body := notification.Body
 
 	// Respect privacy settings
-	if !m.config.ShowPreview {
+	if !d.preview {
 		body = "New message received"
 	}
 	if !m.config.ShowSender && notification.Type == NotificationMessage {
 		title = "New Message"
 	}
 
-	// Choose icon
+	// Choose icon, using the variant for the current theme unless one was given
 	iconPath := notification.Icon
 	if iconPath == "" {
-		iconPath = m.iconPath
+		iconPath = m.assets.IconPath
+	}
+	if notification.Metadata == nil {
+		notification.Metadata = make(map[string]any)
+	}
+	notification.Metadata["accent"] = m.assets.Accent
+	if d.soundFile != "" {
+		notification.Metadata["sound_file"] = d.soundFile
 	}
 
 	// Store active notification
 	m.activeNotifs[notification.ID] = notification
 
+	// Show actions through the platform notification service when it has
+	// them, otherwise leave them out so they can't be invoked
+	if len(notification.Actions) > 0 {
+		if notifier := m.actionNotifierLocked(); notifier != nil {
+			platformID, err := notifier.Notify(title, body, iconPath, d.soundFile, notification.Actions)
+			if err == nil {
+				m.platformIDs[platformID] = notification.ID
+				m.history.record(notification, SuppressedNone)
+				return nil
+			}
+			log.Printf("Warning: Failed to show notification with actions: %v", err)
+		}
+		notification.Actions = nil
+	}
+
 	// Show notification based on platform
 	var err error
 	switch m.platform {
@@ -97,9 +133,11 @@ func (m *CrossPlatformManager) Show(ctx context.Context, notification *Notificat
 
 	if err != nil {
 		delete(m.activeNotifs, notification.ID)
+		m.history.record(notification, SuppressedFailed)
 		return fmt.Errorf("failed to show notification: %w", err)
 	}
 
+	m.history.record(notification, SuppressedNone)
 	return nil
 }
 
@@ -132,6 +170,93 @@ func (m *CrossPlatformManager) GetConfig() NotificationConfig {
 	return m.config
 }
 
+// SetAppearance switches the icon variant and accent to match the app theme
+func (m *CrossPlatformManager) SetAppearance(appearance Appearance) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.appearance = appearance
+	m.assets = selectAssets(appearance, m.iconPath, iconExists)
+}
+
+// Assets returns the asset set used for new notifications
+func (m *CrossPlatformManager) Assets() AssetSet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.assets
+}
+
+// SetActionHandler sets the callback for notification action buttons
+func (m *CrossPlatformManager) SetActionHandler(handler ActionHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onAction = handler
+}
+
+// InvokeAction dispatches an action on a shown notification to the action
+// handler. Platform integrations that render action buttons call this when
+// one is pressed; the notification is dismissed afterwards.
+func (m *CrossPlatformManager) InvokeAction(notificationID, actionID, input string) error {
+	m.mu.Lock()
+	notification, exists := m.activeNotifs[notificationID]
+	if !exists {
+		m.mu.Unlock()
+		return fmt.Errorf("notification %s not found", notificationID)
+	}
+
+	known := false
+	for _, action := range notification.Actions {
+		if action.ID == actionID {
+			known = true
+			break
+		}
+	}
+	if !known {
+		m.mu.Unlock()
+		return fmt.Errorf("notification %s has no action %s", notificationID, actionID)
+	}
+
+	delete(m.activeNotifs, notificationID)
+	handler := m.onAction
+	m.mu.Unlock()
+
+	if handler != nil {
+		handler(notification, actionID, input)
+	}
+	return nil
+}
+
+// RecordSuppressed adds a notification held back before reaching the
+// manager to the history
+func (m *CrossPlatformManager) RecordSuppressed(notification *Notification, reason SuppressReason) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history.record(notification, reason)
+}
+
+// GetHistory returns up to limit recent notifications, newest first, whether
+// or not they were displayed; limit <= 0 returns the whole history
+func (m *CrossPlatformManager) GetHistory(limit int) []HistoryEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.history.latest(limit)
+}
+
+// ClearHistory removes every entry from the history
+func (m *CrossPlatformManager) ClearHistory() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history.clear()
+}
+
+// SetContactOverrides sets the lookup for per-friend overrides of the
+// notification configuration
+func (m *CrossPlatformManager) SetContactOverrides(lookup OverrideLookup) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.overrides = lookup
+}
+
 // IsSupported returns true if notifications are supported on this platform
 func (m *CrossPlatformManager) IsSupported() bool {
 	// beeep supports all major platforms
@@ -157,7 +282,14 @@ func (m *CrossPlatformManager) Close() error {
 
 	// Clear active notifications
 	m.activeNotifs = make(map[string]*Notification)
+	m.platformIDs = make(map[uint32]string)
 
+	if m.actions != nil {
+		if err := m.actions.Close(); err != nil {
+			return fmt.Errorf("failed to close notification actions: %w", err)
+		}
+		m.actions = nil
+	}
 	return nil
 }
 
@@ -1,6 +1,8 @@
 package notifications
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/opd-ai/whisp/ui/adaptive"
@@ -49,6 +51,35 @@ func ConfigFromYAML(yamlConfig any) (NotificationConfig, error) {
 	return config, nil
 }
 
+// quietHoursDays maps the day names accepted in quiet hours schedules
+var quietHoursDays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseQuietHours builds quiet hours from the "HH:MM" times and optional
+// day names ("mon", "tue", ...) used in the configuration file
+func ParseQuietHours(enabled bool, start, end string, days []string) (QuietHours, error) {
+	qh := QuietHours{Enabled: enabled}
+
+	var err error
+	if qh.StartTime, err = time.Parse("15:04", start); err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours start %q: %w", start, err)
+	}
+	if qh.EndTime, err = time.Parse("15:04", end); err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours end %q: %w", end, err)
+	}
+
+	for _, name := range days {
+		day, ok := quietHoursDays[strings.ToLower(name)]
+		if !ok {
+			return QuietHours{}, fmt.Errorf("invalid quiet hours day: %s", name)
+		}
+		qh.Days = append(qh.Days, day)
+	}
+	return qh, nil
+}
+
 // Helper functions for creating common notification types
 
 // NewMessageNotification creates a notification for a new message
@@ -59,6 +90,18 @@ func NewMessageNotification(senderName, messageContent string) *Notification {
 	return notification
 }
 
+// NewMessageNotificationWithActions creates a message notification with
+// "Reply" and "Mark as read" actions for the given friend
+func NewMessageNotificationWithActions(senderName, messageContent string, friendID uint32) *Notification {
+	notification := NewMessageNotification(senderName, messageContent)
+	notification.SetFriendID(friendID)
+	notification.Actions = []Action{
+		{ID: ActionReply, Title: "Reply", Input: true},
+		{ID: ActionMarkRead, Title: "Mark as read"},
+	}
+	return notification
+}
+
 // NewFriendRequestNotification creates a notification for a friend request
 func NewFriendRequestNotification(senderName, message string) *Notification {
 	title := "New Friend Request"
@@ -84,6 +127,20 @@ func NewStatusNotification(friendName, status string) *Notification {
 	return notification
 }
 
+// NewFileOfferNotification creates a notification for a file a friend
+// offered, which was either accepted automatically or waits for the user
+func NewFileOfferNotification(friendName, fileName string, accepted bool) *Notification {
+	body := friendName + " wants to send you " + fileName
+	if accepted {
+		body = "Receiving " + fileName + " from " + friendName
+	}
+
+	notification := NewNotification(NotificationFileTransfer, "Incoming File", body)
+	notification.Sound = true
+	notification.Urgent = false
+	return notification
+}
+
 // NewFileTransferNotification creates a notification for a file transfer
 func NewFileTransferNotification(friendName, fileName string, isIncoming bool) *Notification {
 	var title, body string
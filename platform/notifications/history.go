@@ -0,0 +1,79 @@
+package notifications
+
+import "time"
+
+// HistoryLimit is the number of notifications kept in the history
+const HistoryLimit = 200
+
+// SuppressReason says why a notification was not displayed
+type SuppressReason string
+
+const (
+	SuppressedNone       SuppressReason = ""            // Displayed
+	SuppressedDisabled   SuppressReason = "disabled"    // Notifications turned off
+	SuppressedMuted      SuppressReason = "muted"       // The friend is muted
+	SuppressedQuietHours SuppressReason = "quiet_hours" // Held back by quiet hours
+	SuppressedPaused     SuppressReason = "paused"      // Held back by presentation mode
+	SuppressedFailed     SuppressReason = "failed"      // The platform failed to show it
+)
+
+// HistoryEntry records a notification that was shown or would have been
+type HistoryEntry struct {
+	NotificationID string
+	Type           NotificationType
+	Sender         string // Title of the notification, usually the sender
+	Time           time.Time
+	Displayed      bool
+	Reason         SuppressReason // Why it was not displayed
+}
+
+// notificationHistory keeps the most recent entries in a fixed-size ring
+type notificationHistory struct {
+	entries []HistoryEntry
+	next    int  // Index the next entry is written to
+	full    bool // Whether the ring has wrapped
+}
+
+// newNotificationHistory creates a history holding up to limit entries
+func newNotificationHistory(limit int) *notificationHistory {
+	return &notificationHistory{entries: make([]HistoryEntry, limit)}
+}
+
+// record adds an entry for a notification, overwriting the oldest once full
+func (h *notificationHistory) record(notification *Notification, reason SuppressReason) {
+	h.entries[h.next] = HistoryEntry{
+		NotificationID: notification.ID,
+		Type:           notification.Type,
+		Sender:         notification.Title,
+		Time:           time.Now(),
+		Displayed:      reason == SuppressedNone,
+		Reason:         reason,
+	}
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// latest returns up to limit entries, newest first; limit <= 0 returns all
+func (h *notificationHistory) latest(limit int) []HistoryEntry {
+	count := h.next
+	if h.full {
+		count = len(h.entries)
+	}
+	if limit > 0 && limit < count {
+		count = limit
+	}
+
+	entries := make([]HistoryEntry, count)
+	for i := range entries {
+		entries[i] = h.entries[(h.next-1-i+len(h.entries))%len(h.entries)]
+	}
+	return entries
+}
+
+// clear removes every entry
+func (h *notificationHistory) clear() {
+	clear(h.entries)
+	h.next, h.full = 0, false
+}
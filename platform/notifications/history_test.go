@@ -0,0 +1,89 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNotificationHistoryRing(t *testing.T) {
+	history := newNotificationHistory(3)
+	for _, title := range []string{"one", "two", "three", "four"} {
+		history.record(NewNotification(NotificationMessage, title, ""), SuppressedNone)
+	}
+
+	tests := []struct {
+		limit int
+		want  []string
+	}{
+		{0, []string{"four", "three", "two"}},
+		{2, []string{"four", "three"}},
+		{10, []string{"four", "three", "two"}},
+	}
+	for _, tt := range tests {
+		entries := history.latest(tt.limit)
+		if len(entries) != len(tt.want) {
+			t.Fatalf("latest(%d) returned %d entries, want %d", tt.limit, len(entries), len(tt.want))
+		}
+		for i, entry := range entries {
+			if entry.Sender != tt.want[i] {
+				t.Errorf("latest(%d)[%d] = %q, want %q", tt.limit, i, entry.Sender, tt.want[i])
+			}
+		}
+	}
+
+	history.clear()
+	if entries := history.latest(0); len(entries) != 0 {
+		t.Errorf("Expected empty history after clear, got %d entries", len(entries))
+	}
+}
+
+func TestShowRecordsSuppressedNotifications(t *testing.T) {
+	now := time.Now()
+	allDay := QuietHours{
+		Enabled:   true,
+		StartTime: now.Add(-time.Minute),
+		EndTime:   now.Add(-2 * time.Minute), // Wraps around: quiet all day but this minute
+	}
+
+	tests := []struct {
+		name     string
+		config   NotificationConfig
+		friendID uint32
+		want     SuppressReason
+	}{
+		{"disabled", NotificationConfig{Enabled: false}, 2, SuppressedDisabled},
+		{"muted friend", NotificationConfig{Enabled: true}, 1, SuppressedMuted},
+		{"quiet hours", NotificationConfig{Enabled: true, QuietHours: allDay}, 2, SuppressedQuietHours},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewCrossPlatformManager("")
+			manager.SetConfig(tt.config)
+			manager.SetContactOverrides(func(friendID uint32) (ContactOverride, bool) {
+				return ContactOverride{Muted: friendID == 1}, true
+			})
+
+			notification := NewMessageNotification("Alice", "hello")
+			notification.SetFriendID(tt.friendID)
+			if err := manager.Show(context.Background(), notification); err != nil {
+				t.Fatalf("Show failed: %v", err)
+			}
+
+			history := manager.GetHistory(0)
+			if len(history) != 1 {
+				t.Fatalf("Expected 1 history entry, got %d", len(history))
+			}
+			entry := history[0]
+			if entry.Displayed || entry.Reason != tt.want || entry.Sender != "Alice" || entry.Type != NotificationMessage {
+				t.Errorf("Unexpected history entry %+v", entry)
+			}
+
+			manager.ClearHistory()
+			if len(manager.GetHistory(0)) != 0 {
+				t.Error("Expected empty history after ClearHistory")
+			}
+		})
+	}
+}
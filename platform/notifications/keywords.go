@@ -0,0 +1,42 @@
+package notifications
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Message notification modes
+const (
+	ModeAll      = "all"      // Notify for every incoming message
+	ModeMentions = "mentions" // Notify only for messages matching a keyword
+)
+
+// KeywordFilter matches messages that mention any of a set of keywords.
+// Matching is case-insensitive and only whole words count, so "ann" matches
+// "Ann, are you there?" but not "announcement".
+type KeywordFilter struct {
+	pattern *regexp.Regexp
+}
+
+// NewKeywordFilter creates a filter for the given keywords. Blank keywords are
+// ignored; a filter without keywords matches nothing.
+func NewKeywordFilter(keywords []string) *KeywordFilter {
+	var alternatives []string
+	for _, keyword := range keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			alternatives = append(alternatives, regexp.QuoteMeta(keyword))
+		}
+	}
+	if len(alternatives) == 0 {
+		return &KeywordFilter{}
+	}
+
+	// A keyword must not be preceded or followed by another word character
+	pattern := `(?i)(?:^|[^\pL\pN_])(?:` + strings.Join(alternatives, "|") + `)(?:$|[^\pL\pN_])`
+	return &KeywordFilter{pattern: regexp.MustCompile(pattern)}
+}
+
+// Matches reports whether the message mentions one of the keywords
+func (f *KeywordFilter) Matches(message string) bool {
+	return f.pattern != nil && f.pattern.MatchString(message)
+}
@@ -0,0 +1,33 @@
+package notifications
+
+import "testing"
+
+func TestKeywordFilter(t *testing.T) {
+	filter := NewKeywordFilter([]string{"Ann", " release ", "", "c++"})
+
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"Ann, are you there?", true},
+		{"hey ann", true},
+		{"ANN!", true},
+		{"the announcement is out", false},
+		{"Joanna said hi", false},
+		{"The Release is tomorrow", true},
+		{"prerelease builds", false},
+		{"anyone know c++?", true},
+		{"nothing to see here", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := filter.Matches(tt.message); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+
+	if NewKeywordFilter(nil).Matches("anything") {
+		t.Error("Expected a filter without keywords to match nothing")
+	}
+}
@@ -3,6 +3,7 @@ package notifications
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -53,6 +54,31 @@ type Notification struct {
 type Action struct {
 	ID    string `json:"id"`
 	Title string `json:"title"`
+	Input bool   `json:"input,omitempty"` // Action takes typed text, e.g. a quick reply
+}
+
+// Built-in notification action IDs
+const (
+	ActionReply    = "reply"
+	ActionMarkRead = "mark_read"
+)
+
+// ActionHandler is called when the user invokes a notification action.
+// input holds the typed text for actions that accept it.
+type ActionHandler func(notification *Notification, actionID, input string)
+
+// FriendID returns the friend a notification is about, if it carries one
+func (n *Notification) FriendID() (uint32, bool) {
+	friendID, ok := n.Metadata["friend_id"].(uint32)
+	return friendID, ok
+}
+
+// SetFriendID records the friend a notification is about
+func (n *Notification) SetFriendID(friendID uint32) {
+	if n.Metadata == nil {
+		n.Metadata = make(map[string]any)
+	}
+	n.Metadata["friend_id"] = friendID
 }
 
 // NotificationConfig holds configuration for notifications
@@ -65,28 +91,54 @@ type NotificationConfig struct {
 	PlatformOpts map[string]any
 }
 
-// QuietHours represents quiet hours configuration
+// QuietHours represents quiet hours configuration. Only the time of day of
+// StartTime and EndTime is used; a window whose end is before its start runs
+// overnight, e.g. 22:00-07:00.
 type QuietHours struct {
 	Enabled   bool
 	StartTime time.Time
 	EndTime   time.Time
+	Days      []time.Weekday // Days the window starts on; empty means every day
 }
 
 // IsQuietTime checks if current time is within quiet hours
 func (qh QuietHours) IsQuietTime() bool {
+	return qh.IsInQuietHours(time.Now())
+}
+
+// IsInQuietHours checks if t is within quiet hours. The window includes its
+// start and excludes its end, and is empty when both are the same time. The
+// part of an overnight window after midnight belongs to the day it started.
+func (qh QuietHours) IsInQuietHours(t time.Time) bool {
 	if !qh.Enabled {
 		return false
 	}
 
-	now := time.Now()
-	currentTime := time.Date(0, 1, 1, now.Hour(), now.Minute(), now.Second(), 0, time.UTC)
-
-	// Handle quiet hours that span midnight
-	if qh.StartTime.After(qh.EndTime) {
-		return currentTime.After(qh.StartTime) || currentTime.Before(qh.EndTime)
+	start, end, now := timeOfDay(qh.StartTime), timeOfDay(qh.EndTime), timeOfDay(t)
+	switch {
+	case start < end:
+		return now >= start && now < end && qh.onDay(t.Weekday())
+	case start > end:
+		if now >= start {
+			return qh.onDay(t.Weekday())
+		}
+		if now < end {
+			return qh.onDay((t.Weekday() + 6) % 7) // Started the day before
+		}
 	}
+	return false
+}
 
-	return currentTime.After(qh.StartTime) && currentTime.Before(qh.EndTime)
+// onDay reports whether the window starts on the given weekday
+func (qh QuietHours) onDay(day time.Weekday) bool {
+	return len(qh.Days) == 0 || slices.Contains(qh.Days, day)
+}
+
+// timeOfDay returns the time elapsed since midnight on t's clock
+func timeOfDay(t time.Time) time.Duration {
+	hour, minute, second := t.Clock()
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute +
+		time.Duration(second)*time.Second + time.Duration(t.Nanosecond())
 }
 
 // Manager defines the interface for notification management
@@ -109,6 +161,27 @@ type Manager interface {
 	// RequestPermission requests notification permission (primarily for mobile)
 	RequestPermission(ctx context.Context) error
 
+	// SetAppearance styles notifications for the app's light or dark theme
+	SetAppearance(appearance Appearance)
+
+	// SetActionHandler sets the callback for notification action buttons
+	SetActionHandler(handler ActionHandler)
+
+	// SetContactOverrides sets the lookup for per-friend overrides of the
+	// configuration, applied to notifications that carry a friend ID
+	SetContactOverrides(lookup OverrideLookup)
+
+	// RecordSuppressed adds a notification that was held back before being
+	// shown to the history
+	RecordSuppressed(notification *Notification, reason SuppressReason)
+
+	// GetHistory returns up to limit recent notifications, newest first,
+	// including those that were not displayed
+	GetHistory(limit int) []HistoryEntry
+
+	// ClearHistory removes every entry from the history
+	ClearHistory()
+
 	// Close cleans up resources
 	Close() error
 }
@@ -94,6 +94,76 @@ func TestQuietHours(t *testing.T) {
 	})
 }
 
+func TestIsInQuietHours(t *testing.T) {
+	clock := func(hour, minute int) time.Time {
+		return time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+	overnight := QuietHours{Enabled: true, StartTime: clock(22, 0), EndTime: clock(7, 0)}
+	daytime := QuietHours{Enabled: true, StartTime: clock(9, 0), EndTime: clock(17, 30)}
+	weekdays := overnight
+	weekdays.Days = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+	// 2024-01-05 is a Friday
+	at := func(day, hour, minute, second int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, second, 0, time.Local)
+	}
+
+	tests := []struct {
+		name  string
+		hours QuietHours
+		t     time.Time
+		want  bool
+	}{
+		{"disabled", QuietHours{StartTime: clock(0, 0), EndTime: clock(23, 59)}, at(5, 12, 0, 0), false},
+		{"overnight before midnight", overnight, at(5, 23, 30, 0), true},
+		{"overnight after midnight", overnight, at(6, 3, 0, 0), true},
+		{"overnight daytime", overnight, at(5, 15, 0, 0), false},
+		{"overnight at start", overnight, at(5, 22, 0, 0), true},
+		{"overnight just before start", overnight, at(5, 21, 59, 59), false},
+		{"overnight at end", overnight, at(6, 7, 0, 0), false},
+		{"overnight just before end", overnight, at(6, 6, 59, 59), true},
+		{"overnight at midnight", overnight, at(6, 0, 0, 0), true},
+		{"daytime inside", daytime, at(5, 12, 0, 0), true},
+		{"daytime at start", daytime, at(5, 9, 0, 0), true},
+		{"daytime at end", daytime, at(5, 17, 30, 0), false},
+		{"daytime outside", daytime, at(5, 20, 0, 0), false},
+		{"date is ignored", daytime, time.Date(1999, 7, 14, 10, 0, 0, 0, time.UTC), true},
+		{"empty window", QuietHours{Enabled: true, StartTime: clock(8, 0), EndTime: clock(8, 0)}, at(5, 8, 0, 0), false},
+		{"weekday evening", weekdays, at(5, 23, 0, 0), true},
+		{"night started on a weekday", weekdays, at(6, 3, 0, 0), true},
+		{"weekend evening", weekdays, at(6, 23, 0, 0), false},
+		{"night started on the weekend", weekdays, at(8, 3, 0, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hours.IsInQuietHours(tt.t); got != tt.want {
+				t.Errorf("IsInQuietHours(%s) = %v, want %v", tt.t.Format("Mon 15:04:05"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	qh, err := ParseQuietHours(true, "22:00", "07:30", []string{"Fri", "sat"})
+	if err != nil {
+		t.Fatalf("ParseQuietHours failed: %v", err)
+	}
+	if qh.StartTime.Hour() != 22 || qh.EndTime.Hour() != 7 || qh.EndTime.Minute() != 30 {
+		t.Errorf("Unexpected window %s-%s", qh.StartTime.Format("15:04"), qh.EndTime.Format("15:04"))
+	}
+	if len(qh.Days) != 2 || qh.Days[0] != time.Friday || qh.Days[1] != time.Saturday {
+		t.Errorf("Unexpected days %v", qh.Days)
+	}
+
+	if _, err := ParseQuietHours(true, "25:00", "07:00", nil); err == nil {
+		t.Error("Expected error for an invalid start time")
+	}
+	if _, err := ParseQuietHours(true, "22:00", "07:00", []string{"weekend"}); err == nil {
+		t.Error("Expected error for an invalid day")
+	}
+}
+
 func TestCrossPlatformManager(t *testing.T) {
 	manager := NewCrossPlatformManager("test-icon.png")
 
@@ -287,6 +357,18 @@ func TestNotificationHelpers(t *testing.T) {
 			t.Error("Expected Sound to be false for outgoing files")
 		}
 	})
+
+	t.Run("NewFileOfferNotification", func(t *testing.T) {
+		pending := NewFileOfferNotification("Frank", "notes.txt", false)
+		if pending.Title != "Incoming File" || pending.Body != "Frank wants to send you notes.txt" {
+			t.Errorf("Unexpected pending offer notification %q: %q", pending.Title, pending.Body)
+		}
+
+		accepted := NewFileOfferNotification("Frank", "notes.txt", true)
+		if accepted.Body != "Receiving notes.txt from Frank" || accepted.Type != NotificationFileTransfer {
+			t.Errorf("Unexpected accepted offer notification %q", accepted.Body)
+		}
+	})
 }
 
 func TestGenerateNotificationID(t *testing.T) {
@@ -308,3 +390,35 @@ func TestGenerateNotificationID(t *testing.T) {
 		t.Errorf("Expected ID to start with 'whisp_', got '%s'", id1)
 	}
 }
+
+func TestInvokeActionDispatches(t *testing.T) {
+	manager := NewCrossPlatformManager("")
+	manager.SetConfig(NotificationConfig{Enabled: true})
+
+	var gotFriend uint32
+	var gotAction, gotInput string
+	manager.SetActionHandler(func(n *Notification, actionID, input string) {
+		gotFriend, _ = n.FriendID()
+		gotAction, gotInput = actionID, input
+	})
+
+	// Track the notification directly so the test does not depend on a desktop notifier
+	notification := NewMessageNotificationWithActions("Alice", "hi", 42)
+	manager.activeNotifs[notification.ID] = notification
+
+	if err := manager.InvokeAction(notification.ID, "archive", ""); err == nil {
+		t.Error("Expected error for an action the notification does not offer")
+	}
+
+	if err := manager.InvokeAction(notification.ID, ActionReply, "hello"); err != nil {
+		t.Fatalf("InvokeAction failed: %v", err)
+	}
+	if gotFriend != 42 || gotAction != ActionReply || gotInput != "hello" {
+		t.Errorf("Handler got friend=%d action=%q input=%q", gotFriend, gotAction, gotInput)
+	}
+
+	// The notification is dismissed once an action is taken
+	if err := manager.InvokeAction(notification.ID, ActionMarkRead, ""); err == nil {
+		t.Error("Expected error for a dismissed notification")
+	}
+}
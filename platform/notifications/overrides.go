@@ -0,0 +1,49 @@
+package notifications
+
+// ContactOverride changes how notifications about one friend are shown. The
+// zero value follows the global configuration.
+type ContactOverride struct {
+	Muted              bool   // Never notify
+	Sound              string // Sound file to play instead of the default
+	AlwaysShowPreview  bool   // Show the message even with previews off
+	OverrideQuietHours bool   // Notify during quiet hours
+}
+
+// OverrideLookup returns the override for a friend, if one is set
+type OverrideLookup func(friendID uint32) (ContactOverride, bool)
+
+// delivery is how a notification is shown once the global configuration and
+// any override for its friend are applied
+type delivery struct {
+	show      bool
+	preview   bool
+	sound     bool
+	soundFile string
+	reason    SuppressReason // Why it is not shown
+}
+
+// resolveDelivery decides how a notification is shown. Turning notifications
+// off globally wins over everything, then muting the friend, then quiet
+// hours unless the friend overrides them. Previews and sound follow the
+// global settings, with previews forced on and the sound replaced by the
+// override.
+func resolveDelivery(config NotificationConfig, notification *Notification, override ContactOverride, quietTime bool) delivery {
+	switch {
+	case !config.Enabled:
+		return delivery{reason: SuppressedDisabled}
+	case override.Muted:
+		return delivery{reason: SuppressedMuted}
+	case quietTime && !override.OverrideQuietHours:
+		return delivery{reason: SuppressedQuietHours}
+	}
+
+	d := delivery{
+		show:    true,
+		preview: config.ShowPreview || override.AlwaysShowPreview,
+		sound:   config.PlaySound && notification.Sound,
+	}
+	if d.sound {
+		d.soundFile = override.Sound
+	}
+	return d
+}
@@ -0,0 +1,93 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResolveDelivery(t *testing.T) {
+	global := NotificationConfig{Enabled: true, ShowPreview: false, PlaySound: true}
+
+	tests := []struct {
+		name      string
+		config    NotificationConfig
+		override  ContactOverride
+		quietTime bool
+		want      delivery
+	}{
+		{
+			name:   "global settings without override",
+			config: global,
+			want:   delivery{show: true, sound: true},
+		},
+		{
+			name:     "muted friend",
+			config:   global,
+			override: ContactOverride{Muted: true, OverrideQuietHours: true},
+			want:     delivery{reason: SuppressedMuted},
+		},
+		{
+			name:     "disabled globally wins over the friend",
+			config:   NotificationConfig{Enabled: false},
+			override: ContactOverride{OverrideQuietHours: true, AlwaysShowPreview: true},
+			want:     delivery{reason: SuppressedDisabled},
+		},
+		{
+			name:      "quiet hours apply to friends",
+			config:    global,
+			override:  ContactOverride{AlwaysShowPreview: true},
+			quietTime: true,
+			want:      delivery{reason: SuppressedQuietHours},
+		},
+		{
+			name:      "friend overrides quiet hours",
+			config:    global,
+			override:  ContactOverride{OverrideQuietHours: true},
+			quietTime: true,
+			want:      delivery{show: true, sound: true},
+		},
+		{
+			name:     "friend always shows previews",
+			config:   global,
+			override: ContactOverride{AlwaysShowPreview: true},
+			want:     delivery{show: true, preview: true, sound: true},
+		},
+		{
+			name:     "custom sound",
+			config:   global,
+			override: ContactOverride{Sound: "/sounds/chime.wav"},
+			want:     delivery{show: true, sound: true, soundFile: "/sounds/chime.wav"},
+		},
+		{
+			name:     "custom sound stays off with sounds off",
+			config:   NotificationConfig{Enabled: true, ShowPreview: true},
+			override: ContactOverride{Sound: "/sounds/chime.wav"},
+			want:     delivery{show: true, preview: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := NewMessageNotification("Alice", "hello")
+			got := resolveDelivery(tt.config, notification, tt.override, tt.quietTime)
+			if got != tt.want {
+				t.Errorf("resolveDelivery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShowSkipsMutedFriend(t *testing.T) {
+	manager := NewCrossPlatformManager("")
+	manager.SetContactOverrides(func(friendID uint32) (ContactOverride, bool) {
+		return ContactOverride{Muted: friendID == 1}, true
+	})
+
+	notification := NewMessageNotificationWithActions("Alice", "hello", 1)
+	if err := manager.Show(context.Background(), notification); err != nil {
+		t.Fatalf("Show failed: %v", err)
+	}
+	if _, shown := manager.activeNotifs[notification.ID]; shown {
+		t.Error("Expected no notification for a muted friend")
+	}
+}
@@ -0,0 +1,54 @@
+package adaptive
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/opd-ai/whisp/internal/core/calls"
+	"github.com/opd-ai/whisp/ui/i18n"
+	"github.com/opd-ai/whisp/ui/shared"
+)
+
+// setupCalls creates the call view when calls are enabled and returns it to
+// be shown above the chat, or nil without calls
+func (ui *UI) setupCalls() fyne.CanvasObject {
+	callMgr := ui.coreApp.GetCalls()
+	if callMgr == nil {
+		return nil
+	}
+
+	ui.callView = shared.NewCallView(ui.coreApp, callMgr)
+	ui.callView.SetPresentationMode(ui.presentation)
+	ui.chatView.SetOnCall(func(friendID uint32) {
+		ui.callView.PlaceCall(friendID, false)
+	})
+	ui.coreApp.SetOnCallEvent(func(event *calls.CallEvent) {
+		if event.Type == calls.CallEventIncoming {
+			ui.coreApp.RecordActivity()
+		}
+		ui.callView.HandleEvent(event)
+	})
+	return ui.callView.Container()
+}
+
+// callCurrentFriend calls the friend whose conversation is open
+func (ui *UI) callCurrentFriend(video bool) {
+	if ui.mainWindow == nil || ui.chatView == nil {
+		return
+	}
+	if ui.callView == nil {
+		dialog.ShowInformation(i18n.T("calls.title"), i18n.T("calls.disabled"), ui.mainWindow)
+		return
+	}
+	if video && !ui.coreApp.GetConfigManager().GetConfig().Advanced.Experimental.EnableVideoCalls {
+		dialog.ShowInformation(i18n.T("calls.title"), i18n.T("calls.video_disabled"), ui.mainWindow)
+		return
+	}
+
+	friendID := ui.chatView.CurrentFriend()
+	if friendID == 0 {
+		dialog.ShowInformation(i18n.T("calls.title"), i18n.T("calls.open_conversation"), ui.mainWindow)
+		return
+	}
+	ui.callView.PlaceCall(friendID, video)
+}
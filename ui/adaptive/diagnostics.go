@@ -0,0 +1,145 @@
+package adaptive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// diagnosticsRefresh is how often the open diagnostics panel updates
+const diagnosticsRefresh = 2 * time.Second
+
+// Diagnostics is a snapshot of the app's health. It holds nothing that
+// identifies the user beyond a shortened Tox ID, so it can be shared.
+type Diagnostics struct {
+	Platform          string // OS, architecture and Go version
+	ToxID             string // Redacted
+	ToxConnection     string // "none", "tcp" or "udp"
+	Friends           int
+	FriendsOnline     int
+	ActiveTransfers   int
+	QueuedTransfers   int
+	DatabaseSize      int64
+	DatabaseEncrypted bool
+	Messages          int
+	CacheHits         uint64
+	CacheMisses       uint64
+	MemoryInUse       uint64 // Heap bytes in use
+	MemoryFromOS      uint64 // Bytes obtained from the OS
+	Goroutines        int
+}
+
+// CacheHitRate returns the share of conversation loads served from memory,
+// or -1 before any loads
+func (d Diagnostics) CacheHitRate() float64 {
+	total := d.CacheHits + d.CacheMisses
+	if total == 0 {
+		return -1
+	}
+	return float64(d.CacheHits) / float64(total)
+}
+
+// Text renders the diagnostics as a plain block to paste into a bug report.
+// It is not translated so whoever reads the report can.
+func (d Diagnostics) Text() string {
+	hitRate := "n/a"
+	if rate := d.CacheHitRate(); rate >= 0 {
+		hitRate = fmt.Sprintf("%.1f%% (%d of %d)", rate*100, d.CacheHits, d.CacheHits+d.CacheMisses)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Whisp diagnostics (%s)\n", time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Platform: %s\n", d.Platform)
+	fmt.Fprintf(&b, "Tox ID: %s\n", d.ToxID)
+	fmt.Fprintf(&b, "Tox connection: %s\n", d.ToxConnection)
+	fmt.Fprintf(&b, "Friends online: %d of %d\n", d.FriendsOnline, d.Friends)
+	fmt.Fprintf(&b, "Transfers: %d active, %d queued\n", d.ActiveTransfers, d.QueuedTransfers)
+	fmt.Fprintf(&b, "Database: %s, encrypted: %v\n", formatBytes(d.DatabaseSize), d.DatabaseEncrypted)
+	fmt.Fprintf(&b, "Messages: %d\n", d.Messages)
+	fmt.Fprintf(&b, "Message cache hit rate: %s\n", hitRate)
+	fmt.Fprintf(&b, "Memory: %s in use, %s from OS, %d goroutines\n",
+		formatBytes(int64(d.MemoryInUse)), formatBytes(int64(d.MemoryFromOS)), d.Goroutines)
+	return b.String()
+}
+
+// formatBytes renders a size in the largest unit that keeps it above one
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// showDiagnosticsDialog shows the app's health, refreshing while open, with
+// a button to copy it for a bug report
+func (ui *UI) showDiagnosticsDialog() {
+	if ui.mainWindow == nil {
+		return
+	}
+
+	form := widget.NewForm()
+	values := map[string]*widget.Label{}
+	for _, key := range []string{"connection", "friends", "transfers", "database", "messages", "cache", "memory"} {
+		label := widget.NewLabel("")
+		values[key] = label
+		form.Append(i18n.T("diagnostics."+key), label)
+	}
+
+	render := func(d Diagnostics) {
+		encryption := i18n.T("diagnostics.unencrypted")
+		if d.DatabaseEncrypted {
+			encryption = i18n.T("diagnostics.encrypted")
+		}
+		hitRate := i18n.T("diagnostics.no_loads")
+		if rate := d.CacheHitRate(); rate >= 0 {
+			hitRate = fmt.Sprintf("%.1f%%", rate*100)
+		}
+
+		values["connection"].SetText(i18n.T("diagnostics.connection_" + d.ToxConnection))
+		values["friends"].SetText(i18n.Tf("diagnostics.friends_value", d.FriendsOnline, d.Friends))
+		values["transfers"].SetText(i18n.Tf("diagnostics.transfers_value", d.ActiveTransfers, d.QueuedTransfers))
+		values["database"].SetText(fmt.Sprintf("%s, %s", formatBytes(d.DatabaseSize), encryption))
+		values["messages"].SetText(fmt.Sprintf("%d", d.Messages))
+		values["cache"].SetText(hitRate)
+		values["memory"].SetText(i18n.Tf("diagnostics.memory_value", formatBytes(int64(d.MemoryInUse)), d.Goroutines))
+	}
+	render(ui.coreApp.GetDiagnostics())
+
+	copyBtn := widget.NewButton(i18n.T("diagnostics.copy"), func() {
+		ui.mainWindow.Clipboard().SetContent(ui.coreApp.GetDiagnostics().Text())
+	})
+
+	content := container.NewBorder(nil, copyBtn, nil, nil, form)
+	d := dialog.NewCustom(i18n.T("diagnostics.title"), i18n.T("common.close"), content, ui.mainWindow)
+
+	stop := make(chan struct{})
+	d.SetOnClosed(func() { close(stop) })
+	go func() {
+		ticker := time.NewTicker(diagnosticsRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				render(ui.coreApp.GetDiagnostics())
+			}
+		}
+	}()
+
+	d.Resize(fyne.NewSize(450, 350))
+	d.Show()
+}
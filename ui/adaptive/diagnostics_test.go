@@ -0,0 +1,52 @@
+package adaptive
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiagnosticsText(t *testing.T) {
+	tests := []struct {
+		name  string
+		diag  Diagnostics
+		wants []string
+	}{
+		{
+			name: "no loads yet",
+			diag: Diagnostics{ToxConnection: "none", DatabaseSize: 512},
+			wants: []string{
+				"Tox connection: none",
+				"Database: 512 B, encrypted: false",
+				"Message cache hit rate: n/a",
+			},
+		},
+		{
+			name: "busy app",
+			diag: Diagnostics{
+				ToxID: "ABCDEF01…", ToxConnection: "udp", Friends: 5, FriendsOnline: 2,
+				ActiveTransfers: 1, QueuedTransfers: 3, DatabaseSize: 3 * 1024 * 1024, DatabaseEncrypted: true,
+				Messages: 42, CacheHits: 3, CacheMisses: 1, MemoryInUse: 2048, Goroutines: 12,
+			},
+			wants: []string{
+				"Tox ID: ABCDEF01…",
+				"Friends online: 2 of 5",
+				"Transfers: 1 active, 3 queued",
+				"Database: 3.0 MiB, encrypted: true",
+				"Messages: 42",
+				"Message cache hit rate: 75.0% (3 of 4)",
+				"Memory: 2.0 KiB in use",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := tt.diag.Text()
+			for _, want := range tt.wants {
+				if !strings.Contains(text, want) {
+					t.Errorf("Expected %q in:\n%s", want, text)
+				}
+			}
+		})
+	}
+}
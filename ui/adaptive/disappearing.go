@@ -0,0 +1,75 @@
+package adaptive
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// disappearingChoices are the timers offered for a conversation, in menu order
+var disappearingChoices = []struct {
+	label string // Translation key
+	ttl   time.Duration
+}{
+	{"disappearing.off", 0},
+	{"disappearing.5_minutes", 5 * time.Minute},
+	{"disappearing.1_hour", time.Hour},
+	{"disappearing.1_day", 24 * time.Hour},
+	{"disappearing.1_week", 7 * 24 * time.Hour},
+}
+
+// showDisappearingDialog sets the disappearing message timer of the open conversation
+func (ui *UI) showDisappearingDialog() {
+	if ui.mainWindow == nil || ui.chatView == nil || ui.coreApp.GetMessages() == nil {
+		return
+	}
+
+	friendID := ui.chatView.CurrentFriend()
+	if friendID == 0 {
+		dialog.ShowInformation(i18n.T("disappearing.title"), i18n.T("disappearing.open_conversation"), ui.mainWindow)
+		return
+	}
+	messages := ui.coreApp.GetMessages()
+
+	labels := make([]string, len(disappearingChoices))
+	for i, choice := range disappearingChoices {
+		labels[i] = i18n.T(choice.label)
+	}
+	selectTimer := widget.NewSelect(labels, nil)
+	selectTimer.SetSelected(disappearingLabel(messages.GetDisappearingTimer(friendID)))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("disappearing.delete_after"), selectTimer),
+	}
+	dialog.ShowForm(i18n.T("disappearing.title"), i18n.T("common.save"), i18n.T("common.cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		ttl := disappearingChoices[selectTimer.SelectedIndex()].ttl
+		err := messages.SetDisappearingTimer(friendID, ttl)
+		if errors.Is(err, message.ErrDisappearingDisabled) {
+			dialog.ShowInformation(i18n.T("disappearing.title"), i18n.T("disappearing.disabled"), ui.mainWindow)
+			return
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to set disappearing timer: %w", err), ui.mainWindow)
+		}
+	}, ui.mainWindow)
+}
+
+// disappearingLabel returns the menu label for a timer; timers set by a
+// friend that are not offered here are shown as they are
+func disappearingLabel(ttl time.Duration) string {
+	for _, choice := range disappearingChoices {
+		if choice.ttl == ttl {
+			return i18n.T(choice.label)
+		}
+	}
+	return ttl.String()
+}
@@ -0,0 +1,91 @@
+package adaptive
+
+import (
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// setupFileDrop sends files dropped on the main window to a friend
+func (ui *UI) setupFileDrop() {
+	ui.chatView.SetFileSender(ui.coreApp, ui.mainWindow)
+	ui.mainWindow.SetOnDropped(func(_ fyne.Position, uris []fyne.URI) {
+		ui.sendDroppedFiles(droppedPaths(uris))
+	})
+}
+
+// droppedPaths returns the local file paths among dropped items
+func droppedPaths(uris []fyne.URI) []string {
+	var paths []string
+	for _, uri := range uris {
+		if uri.Scheme() == "file" {
+			paths = append(paths, uri.Path())
+		}
+	}
+	return paths
+}
+
+// sendDroppedFiles sends files to the open conversation, or asks which
+// contact to send them to when no friend's conversation is open
+func (ui *UI) sendDroppedFiles(paths []string) {
+	if len(paths) == 0 || ui.lockedContent != nil || ui.presentation.Enabled() {
+		return
+	}
+	ui.coreApp.RecordActivity()
+
+	if ui.chatView.CurrentFriend() != 0 && ui.chatView.Container().Visible() {
+		ui.chatView.SendFiles(paths)
+		return
+	}
+	ui.pickContact(func(friendID uint32) {
+		ui.openConversation(friendID)
+		ui.chatView.SendFiles(paths)
+	})
+}
+
+// pickContact asks which contact to send dropped files to
+func (ui *UI) pickContact(onPicked func(friendID uint32)) {
+	contacts := sendableContacts(ui.coreApp.GetContacts())
+	if len(contacts) == 0 {
+		dialog.ShowInformation(i18n.T("file_drop.title"), i18n.T("file_drop.no_contacts"), ui.mainWindow)
+		return
+	}
+
+	names := make([]string, len(contacts))
+	for i, c := range contacts {
+		names[i] = c.DisplayName()
+	}
+	picker := widget.NewSelect(names, nil)
+	picker.SetSelectedIndex(0)
+
+	dialog.ShowCustomConfirm(i18n.T("file_drop.choose_contact"), i18n.T("file_drop.send"), i18n.T("common.cancel"),
+		picker, func(confirmed bool) {
+			if confirmed && picker.SelectedIndex() >= 0 {
+				onPicked(contacts[picker.SelectedIndex()].FriendID)
+			}
+		}, ui.mainWindow)
+}
+
+// sendableContacts returns the contacts files can be sent to, by name
+func sendableContacts(contacts *contact.Manager) []*contact.Contact {
+	if contacts == nil {
+		return nil
+	}
+
+	var sendable []*contact.Contact
+	for _, c := range contacts.GetAllContacts() {
+		if !c.IsBlocked {
+			sendable = append(sendable, c)
+		}
+	}
+	sort.Slice(sendable, func(i, j int) bool {
+		return strings.ToLower(sendable[i].DisplayName()) < strings.ToLower(sendable[j].DisplayName())
+	})
+	return sendable
+}
@@ -0,0 +1,47 @@
+package adaptive
+
+import (
+	"github.com/opd-ai/whisp/internal/core/group"
+)
+
+// setupGroups connects group events to the contact list and group chat
+func (ui *UI) setupGroups() {
+	ui.contactList.SetOnGroupSelect(ui.openGroup)
+	ui.groupChat.SetOnLeave(ui.contactList.RefreshGroups)
+
+	groups := ui.coreApp.GetGroups()
+	if groups == nil {
+		return
+	}
+	groups.SetOnMessage(func(msg *group.Message) {
+		ui.groupChat.RefreshGroup(msg.GroupID)
+	})
+	groups.SetOnPeerJoin(func(groupID string, _ group.Member) {
+		ui.groupChat.RefreshGroup(groupID)
+	})
+	groups.SetOnPeerLeave(func(groupID string, _ group.Member) {
+		ui.groupChat.RefreshGroup(groupID)
+	})
+	groups.SetOnInvite(func(*group.Invite) {
+		ui.contactList.RefreshGroups()
+	})
+}
+
+// openGroup shows the conversation of a group in place of the friend chat
+func (ui *UI) openGroup(groupID string) {
+	ui.coreApp.RecordActivity()
+	ui.coreApp.ClearActiveConversation()
+	ui.chatView.Container().Hide()
+	ui.groupChat.Container().Show()
+	ui.groupChat.SetCurrentGroup(groupID)
+
+	if ui.platform.IsMobile() {
+		ui.NavigateToChat()
+	}
+}
+
+// showFriendChat shows the friend chat in place of a group conversation
+func (ui *UI) showFriendChat() {
+	ui.groupChat.Container().Hide()
+	ui.chatView.Container().Show()
+}
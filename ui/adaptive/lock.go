@@ -0,0 +1,76 @@
+package adaptive
+
+import (
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/security"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// lock locks the app from the menu or keyboard shortcut
+func (ui *UI) lock() {
+	if err := ui.coreApp.Lock(); err != nil && ui.mainWindow != nil {
+		dialog.ShowError(fmt.Errorf("failed to lock: %w", err), ui.mainWindow)
+	}
+}
+
+// onLockChanged shows or hides the lock screen as the app locks and unlocks
+func (ui *UI) onLockChanged(locked bool) {
+	if locked {
+		ui.showLockScreen()
+	} else {
+		ui.hideLockScreen()
+	}
+}
+
+// showLockScreen replaces the window content and menu with a password prompt
+func (ui *UI) showLockScreen() {
+	if ui.mainWindow == nil || ui.lockedContent != nil {
+		return
+	}
+	ui.lockedContent = ui.mainWindow.Content()
+
+	status := widget.NewLabel("")
+	status.Alignment = fyne.TextAlignCenter
+	password := widget.NewPasswordEntry()
+	password.SetPlaceHolder(i18n.T("common.password"))
+
+	unlock := func() {
+		err := ui.coreApp.Unlock(password.Text)
+		password.SetText("")
+		switch {
+		case errors.Is(err, security.ErrWrongPassword):
+			status.SetText(i18n.T("lock.wrong_password"))
+		case err != nil:
+			status.SetText(i18n.Tf("lock.failed", err))
+		}
+	}
+	password.OnSubmitted = func(string) { unlock() }
+	unlockBtn := widget.NewButton(i18n.T("lock.unlock"), unlock)
+	unlockBtn.Importance = widget.HighImportance
+
+	title := widget.NewLabelWithStyle(i18n.T("lock.locked"), fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	form := container.NewVBox(title, password, unlockBtn, status)
+
+	ui.mainWindow.SetMainMenu(nil)
+	ui.mainWindow.SetContent(container.NewCenter(container.NewGridWrap(fyne.NewSize(300, form.MinSize().Height), form)))
+	ui.mainWindow.Canvas().Focus(password)
+}
+
+// hideLockScreen restores the window content and menu hidden by the lock screen
+func (ui *UI) hideLockScreen() {
+	if ui.mainWindow == nil || ui.lockedContent == nil {
+		return
+	}
+	ui.mainWindow.SetContent(ui.lockedContent)
+	ui.lockedContent = nil
+	if ui.mainMenu != nil {
+		ui.mainWindow.SetMainMenu(ui.mainMenu)
+	}
+}
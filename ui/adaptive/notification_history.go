@@ -0,0 +1,87 @@
+package adaptive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// notificationHistoryShown is the number of history entries the panel lists
+const notificationHistoryShown = 100
+
+// NotificationRecord is a notification from the history as the panel shows it
+type NotificationRecord struct {
+	Time      time.Time
+	Kind      string // e.g. "message", "friend_request"
+	Sender    string
+	Displayed bool
+	Reason    string // Why it was not displayed, e.g. "quiet_hours"
+}
+
+// notificationReasons are the translation keys describing why a notification
+// was held back
+var notificationReasons = map[string]string{
+	"disabled":    "history.reason_disabled",
+	"muted":       "history.reason_muted",
+	"quiet_hours": "history.reason_quiet_hours",
+	"paused":      "history.reason_paused",
+	"failed":      "history.reason_failed",
+}
+
+// showNotificationHistoryDialog lists recent notifications, including the
+// ones held back, so missed alerts can be caught up on
+func (ui *UI) showNotificationHistoryDialog() {
+	if ui.mainWindow == nil {
+		return
+	}
+
+	list := container.NewVBox()
+	render := func() {
+		list.RemoveAll()
+		records := ui.coreApp.GetNotificationHistory(notificationHistoryShown)
+		if len(records) == 0 {
+			list.Add(widget.NewLabel(i18n.T("history.empty")))
+		}
+		for _, record := range records {
+			list.Add(widget.NewLabel(notificationRecordText(record, ui.presentation.Enabled())))
+		}
+	}
+	render()
+
+	clearBtn := widget.NewButton(i18n.T("history.clear"), func() {
+		ui.coreApp.ClearNotificationHistory()
+		render()
+	})
+
+	content := container.NewBorder(nil, clearBtn, nil, nil, container.NewVScroll(list))
+	d := dialog.NewCustom(i18n.T("history.title"), i18n.T("common.close"), content, ui.mainWindow)
+	d.Resize(fyne.NewSize(500, 400))
+	d.Show()
+}
+
+// notificationRecordText is one line of the history: when, what and from
+// whom, and why it was missed if it was. Senders stay hidden in presentation
+// mode.
+func notificationRecordText(record NotificationRecord, hideSender bool) string {
+	sender := record.Sender
+	if hideSender {
+		sender = i18n.T("history.hidden_sender")
+	}
+	text := fmt.Sprintf("%s  %s  %s", record.Time.Local().Format("2006-01-02 15:04"),
+		strings.ReplaceAll(record.Kind, "_", " "), sender)
+	if !record.Displayed {
+		reason := record.Reason
+		if key, ok := notificationReasons[record.Reason]; ok {
+			reason = i18n.T(key)
+		}
+		text += i18n.Tf("history.missed", reason)
+	}
+	return text
+}
@@ -0,0 +1,43 @@
+package adaptive
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotificationRecordText(t *testing.T) {
+	at := time.Date(2024, 1, 5, 23, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name       string
+		record     NotificationRecord
+		hideSender bool
+		want       string
+	}{
+		{
+			name:   "displayed",
+			record: NotificationRecord{Time: at, Kind: "message", Sender: "Alice", Displayed: true},
+			want:   "2024-01-05 23:30  message  Alice",
+		},
+		{
+			name:   "missed in quiet hours",
+			record: NotificationRecord{Time: at, Kind: "friend_request", Sender: "Bob", Reason: "quiet_hours"},
+			want:   "2024-01-05 23:30  friend request  Bob  (missed: quiet hours)",
+		},
+		{
+			name:       "sender hidden",
+			record:     NotificationRecord{Time: at, Kind: "message", Sender: "Alice", Reason: "paused"},
+			hideSender: true,
+			want:       "2024-01-05 23:30  message  Hidden  (missed: presentation mode)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := notificationRecordText(tt.record, tt.hideSender)
+			if got != tt.want {
+				t.Errorf("notificationRecordText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
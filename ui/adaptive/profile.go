@@ -0,0 +1,147 @@
+package adaptive
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+	"github.com/opd-ai/whisp/ui/shared"
+)
+
+// showExportProfileDialog asks for an optional password and saves the Tox
+// identity to a .tox file
+func (ui *UI) showExportProfileDialog() {
+	if ui.mainWindow == nil {
+		return
+	}
+
+	password := widget.NewPasswordEntry()
+	password.SetPlaceHolder(i18n.T("profile.optional"))
+	confirm := widget.NewPasswordEntry()
+	confirm.SetPlaceHolder(i18n.T("profile.repeat_password"))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("common.password"), password),
+		widget.NewFormItem(i18n.T("common.confirm"), confirm),
+	}
+	dialog.ShowForm(i18n.T("profile.export_title"), i18n.T("profile.export"), i18n.T("common.cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if password.Text != confirm.Text {
+			dialog.ShowInformation(i18n.T("profile.export_title"), i18n.T("profile.passwords_mismatch"), ui.mainWindow)
+			return
+		}
+		ui.saveProfile(password.Text)
+	}, ui.mainWindow)
+}
+
+// saveProfile lets the user pick where to save the profile export
+func (ui *UI) saveProfile(password string) {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, ui.mainWindow)
+			return
+		}
+		if writer == nil {
+			return // Cancelled
+		}
+		path := writer.URI().Path()
+		writer.Close()
+
+		if err := ui.coreApp.ExportProfile(path, password); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to export profile: %w", err), ui.mainWindow)
+			return
+		}
+		dialog.ShowInformation(i18n.T("profile.export_title"), i18n.T("profile.exported"), ui.mainWindow)
+	}, ui.mainWindow)
+	save.SetFileName("whisp.tox")
+	save.Show()
+}
+
+// showImportProfileDialog replaces the Tox identity with one from a .tox file
+func (ui *UI) showImportProfileDialog() {
+	if ui.mainWindow == nil {
+		return
+	}
+
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, ui.mainWindow)
+			return
+		}
+		if reader == nil {
+			return // Cancelled
+		}
+		path := reader.URI().Path()
+		reader.Close()
+
+		password := widget.NewPasswordEntry()
+		password.SetPlaceHolder(i18n.T("profile.password_placeholder"))
+		warning := widget.NewLabel(i18n.T("profile.import_warning"))
+		warning.Wrapping = fyne.TextWrapWord
+
+		items := []*widget.FormItem{
+			widget.NewFormItem("", warning),
+			widget.NewFormItem(i18n.T("common.password"), password),
+		}
+		form := dialog.NewForm(i18n.T("profile.import_title"), i18n.T("profile.import"), i18n.T("common.cancel"), items, func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := ui.coreApp.ImportProfile(path, password.Text); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to import profile: %w", err), ui.mainWindow)
+				return
+			}
+			dialog.ShowInformation(i18n.T("profile.import_title"), i18n.T("profile.imported"), ui.mainWindow)
+		}, ui.mainWindow)
+		form.Resize(fyne.NewSize(420, 0))
+		form.Show()
+	}, ui.mainWindow)
+	open.Show()
+}
+
+// showProfileDialog edits the name, status message and avatar friends see
+func (ui *UI) showProfileDialog() {
+	if ui.mainWindow == nil {
+		return
+	}
+
+	profile := shared.NewProfileDialog(ui.coreApp, ui.mainWindow)
+	profile.SetOnSaved(ui.contactList.RefreshSelfStatus)
+	profile.Show()
+}
+
+// showChangePasswordDialog replaces the password that unlocks Whisp
+func (ui *UI) showChangePasswordDialog() {
+	if ui.mainWindow == nil {
+		return
+	}
+
+	current := widget.NewPasswordEntry()
+	password := widget.NewPasswordEntry()
+	confirm := widget.NewPasswordEntry()
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("password.current"), current),
+		widget.NewFormItem(i18n.T("password.new"), password),
+		widget.NewFormItem(i18n.T("common.confirm"), confirm),
+	}
+	dialog.ShowForm(i18n.T("password.title"), i18n.T("password.change"), i18n.T("common.cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if password.Text != confirm.Text {
+			dialog.ShowInformation(i18n.T("password.title"), i18n.T("password.mismatch"), ui.mainWindow)
+			return
+		}
+		if err := ui.coreApp.ChangePassword(current.Text, password.Text); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to change password: %w", err), ui.mainWindow)
+			return
+		}
+		dialog.ShowInformation(i18n.T("password.title"), i18n.T("password.changed"), ui.mainWindow)
+	}, ui.mainWindow)
+}
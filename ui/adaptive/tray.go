@@ -0,0 +1,105 @@
+package adaptive
+
+import (
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// trayRefreshInterval is how often the tray menu's unread count is updated
+const trayRefreshInterval = 10 * time.Second
+
+// systemTray is the tray icon menu used while minimize to tray is enabled
+type systemTray struct {
+	app      desktop.App
+	menu     *fyne.Menu
+	showItem *fyne.MenuItem
+	unread   int
+}
+
+// setupSystemTray installs the tray menu when minimize to tray is enabled and
+// the platform has a system tray, and reports whether it did
+func (ui *UI) setupSystemTray() bool {
+	if ui.platform.IsMobile() || !ui.coreApp.GetConfigManager().GetConfig().UI.Window.MinimizeToTray {
+		return false
+	}
+	desk, ok := ui.app.(desktop.App)
+	if !ok {
+		return false
+	}
+
+	tray := &systemTray{app: desk}
+	tray.showItem = fyne.NewMenuItem(trayShowLabel(0), ui.showFromTray)
+	tray.menu = fyne.NewMenu("Whisp", tray.showItem, fyne.NewMenuItem(i18n.T("menu.quit"), ui.quit))
+	desk.SetSystemTrayMenu(tray.menu)
+	ui.tray = tray
+
+	ui.updateTrayUnread()
+	go func() {
+		ticker := time.NewTicker(trayRefreshInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ui.updateTrayUnread()
+		}
+	}()
+	return true
+}
+
+// trayShowLabel returns the label of the tray's show item. Fyne cannot set a
+// tray tooltip, so the unread count is shown here instead.
+func trayShowLabel(unread int) string {
+	if unread <= 0 {
+		return i18n.T("tray.show")
+	}
+	return i18n.Tf("tray.show_unread", unread)
+}
+
+// updateTrayUnread refreshes the tray menu when the unread count has changed
+func (ui *UI) updateTrayUnread() {
+	messages := ui.coreApp.GetMessages()
+	if ui.tray == nil || messages == nil {
+		return
+	}
+
+	unread, err := messages.UnreadCount()
+	if err != nil {
+		log.Printf("Warning: Failed to count unread messages: %v", err)
+		return
+	}
+	if unread == ui.tray.unread {
+		return
+	}
+
+	ui.tray.unread = unread
+	ui.tray.showItem.Label = trayShowLabel(unread)
+	ui.tray.app.SetSystemTrayMenu(ui.tray.menu)
+}
+
+// showFromTray brings the main window back after it was closed to the tray
+func (ui *UI) showFromTray() {
+	if ui.mainWindow == nil {
+		return
+	}
+	ui.mainWindow.Show()
+	ui.mainWindow.RequestFocus()
+}
+
+// closeMainWindow hides the window to the tray when there is one and quits otherwise
+func (ui *UI) closeMainWindow() {
+	if ui.tray == nil {
+		ui.quit()
+		return
+	}
+	ui.saveWindowState()
+	ui.mainWindow.Hide()
+}
+
+// quit saves the window state and exits the application
+func (ui *UI) quit() {
+	ui.saveWindowState()
+	ui.app.Quit()
+}
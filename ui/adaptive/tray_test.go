@@ -0,0 +1,133 @@
+package adaptive
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+
+	"github.com/opd-ai/whisp/internal/core/config"
+	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+// trayApp is a test app with a system tray
+type trayApp struct {
+	fyne.App
+	menu     *fyne.Menu
+	setCalls int
+}
+
+func (a *trayApp) SetSystemTrayMenu(menu *fyne.Menu) {
+	a.menu = menu
+	a.setCalls++
+}
+
+func (a *trayApp) SetSystemTrayIcon(icon fyne.Resource) {}
+
+func newTrayTestUI(t *testing.T, app fyne.App, minimizeToTray bool) (*UI, *storage.Database) {
+	t.Helper()
+	configMgr, err := config.NewManager(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to create config manager: %v", err)
+	}
+	cfg := configMgr.GetConfig()
+	cfg.UI.Window.MinimizeToTray = minimizeToTray
+	if err := configMgr.UpdateConfig(cfg); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mockCore := &MockCoreApp{configMgr: configMgr, messages: message.NewManager(db, nil, nil)}
+	ui, err := NewUI(app, mockCore, PlatformLinux)
+	if err != nil {
+		t.Fatalf("NewUI failed: %v", err)
+	}
+	ui.mainWindow = app.NewWindow("Whisp")
+	return ui, db
+}
+
+func TestSystemTrayHidesOnClose(t *testing.T) {
+	app := &trayApp{App: test.NewApp()}
+	defer app.Quit()
+
+	ui, db := newTrayTestUI(t, app, true)
+	if !ui.setupSystemTray() {
+		t.Fatal("Expected the tray to be set up")
+	}
+	if app.menu == nil || len(app.menu.Items) != 2 {
+		t.Fatalf("Expected a tray menu with show and quit items, got %+v", app.menu)
+	}
+	if app.menu.Items[0].Label != "Show Whisp" || app.menu.Items[1].Label != "Quit" {
+		t.Errorf("Unexpected tray items %q and %q", app.menu.Items[0].Label, app.menu.Items[1].Label)
+	}
+
+	ui.mainWindow.Show()
+	ui.closeMainWindow()
+	if len(app.Driver().AllWindows()) == 0 {
+		t.Error("Expected closing to hide the window rather than quit")
+	}
+
+	// An unread message updates the show item
+	msg := ui.coreApp.GetMessages().HandleIncomingMessage(1, "hello", message.MessageTypeNormal)
+	if msg == nil {
+		t.Fatal("Failed to store message")
+	}
+	if err := db.WaitAsync(time.Second); err != nil {
+		t.Fatalf("WaitAsync failed: %v", err)
+	}
+	if _, err := db.Exec("UPDATE messages SET read_at = NULL WHERE id = ?", msg.ID); err != nil {
+		t.Fatalf("Failed to clear read status: %v", err)
+	}
+
+	calls := app.setCalls
+	ui.updateTrayUnread()
+	if got := app.menu.Items[0].Label; got != "Show Whisp (1 unread)" {
+		t.Errorf("Expected the unread count in the tray, got %q", got)
+	}
+	ui.updateTrayUnread()
+	if app.setCalls != calls+1 {
+		t.Errorf("Expected one menu refresh for one change, got %d", app.setCalls-calls)
+	}
+}
+
+func TestSystemTrayDegradesGracefully(t *testing.T) {
+	// The test app has no system tray
+	app := test.NewApp()
+	defer app.Quit()
+
+	ui, _ := newTrayTestUI(t, app, true)
+	if ui.setupSystemTray() || ui.tray != nil {
+		t.Error("Expected no tray without platform support")
+	}
+
+	desk := &trayApp{App: test.NewApp()}
+	defer desk.Quit()
+	ui, _ = newTrayTestUI(t, desk, false)
+	if ui.setupSystemTray() || desk.menu != nil {
+		t.Error("Expected no tray when minimize to tray is disabled")
+	}
+}
+
+func TestTrayShowLabel(t *testing.T) {
+	tests := []struct {
+		unread int
+		want   string
+	}{
+		{0, "Show Whisp"},
+		{3, "Show Whisp (3 unread)"},
+	}
+
+	for _, tt := range tests {
+		if got := trayShowLabel(tt.unread); got != tt.want {
+			t.Errorf("trayShowLabel(%d) = %q, want %q", tt.unread, got, tt.want)
+		}
+	}
+}
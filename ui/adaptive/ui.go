@@ -3,6 +3,9 @@ package adaptive
 import (
 	"context"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -10,10 +13,17 @@ import (
 	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/opd-ai/whisp/internal/core/calls"
 	"github.com/opd-ai/whisp/internal/core/config"
 	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/group"
+	"github.com/opd-ai/whisp/internal/core/linkpreview"
 	"github.com/opd-ai/whisp/internal/core/media"
 	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/internal/core/tox"
+	"github.com/opd-ai/whisp/internal/core/transfer"
+	"github.com/opd-ai/whisp/internal/storage"
+	"github.com/opd-ai/whisp/ui/i18n"
 	"github.com/opd-ai/whisp/ui/shared"
 	"github.com/opd-ai/whisp/ui/theme"
 )
@@ -28,7 +38,15 @@ type UI struct {
 	mainWindow    fyne.Window
 	chatView      *shared.ChatView
 	contactList   *shared.ContactList
+	groupChat     *shared.GroupChatView
+	chatArea      *fyne.Container    // Friend or group chat, whichever is open, below any calls
+	callView      *shared.CallView   // Nil when calls are disabled
 	mobileTabsRef *container.AppTabs // Reference for mobile navigation
+	clipboard     *shared.ClipboardGuard
+	presentation  *shared.PresentationMode
+	tray          *systemTray // Nil unless closing hides to the tray
+	mainMenu      *fyne.MainMenu
+	lockedContent fyne.CanvasObject // Content hidden behind the lock screen
 }
 
 // CoreApp interface for the core application
@@ -37,10 +55,75 @@ type CoreApp interface {
 	GetToxID() string
 	GetContacts() *contact.Manager
 	GetMessages() *message.Manager
+	GetGroups() *group.Manager
 	GetConfigManager() *config.Manager
 	SendMessageFromUI(friendID uint32, content string) error
 	AddContactFromUI(toxID, message string) error
 
+	// Notification focus tracking
+	SetActiveConversation(friendID uint32)
+	ClearActiveConversation()
+	SetNotificationAppearance(variant string)
+	GetNotificationHistory(limit int) []NotificationRecord
+	ClearNotificationHistory()
+	SetPresentationMode(enabled bool)
+
+	// Own presence shown above the contact list
+	GetSelfStatus() contact.Status
+	SetSelfStatus(status contact.Status)
+	IsAutoAway() bool
+	SetOnAutoAwayChanged(callback func(away bool))
+	GetConnectionState() tox.ConnectionState
+	SetOnConnectionChanged(callback func(state tox.ConnectionState))
+
+	// RecordActivity notes user interaction for auto-away
+	RecordActivity()
+
+	// Tox identity import and export
+	ExportProfile(path, password string) error
+	ImportProfile(path, password string) error
+
+	// What friends see of us
+	GetProfile() (name, statusMessage string)
+	UpdateProfile(name, statusMessage string) error
+	GetSelfAvatar() []byte
+	SetSelfAvatar(imagePath string) error
+
+	// Previews of web links in messages
+	LinkPreviewMode(friendID uint32, outgoing bool) linkpreview.Mode
+	FetchLinkPreview(rawURL string) (*linkpreview.Preview, error)
+	CachedLinkPreview(rawURL string) (preview *linkpreview.Preview, done bool)
+
+	// Database backups, restored on the next start
+	BackupDatabase(path string) error
+	RestoreDatabase(path string) error
+	LastBackupTime() time.Time
+	CompactDatabase() (*storage.CompactResult, error)
+
+	// Nodes used to join the Tox network
+	ListBootstrapNodes() []tox.BootstrapNode
+	SaveBootstrapNodes(nodes []tox.BootstrapNode) error
+
+	// ChangePassword replaces the password that unlocks the app
+	ChangePassword(oldPassword, newPassword string) error
+
+	// Locking wipes the key from memory until the password is entered again
+	Lock() error
+	Unlock(password string) error
+	SetOnLockChanged(callback func(locked bool))
+
+	// Calls are nil when disabled in the settings
+	GetCalls() *calls.Manager
+	SetOnCallEvent(callback func(event *calls.CallEvent))
+
+	// Sending files dropped on the window
+	SendFileFromUI(friendID uint32, filePath string) (string, error)
+	CancelFileFromUI(transferID string) error
+	GetTransfers() *transfer.Manager
+
+	// GetDiagnostics reports the app's health for the diagnostics panel
+	GetDiagnostics() Diagnostics
+
 	// Media-related methods
 	GetMediaInfoFromUI(filePath string) (*media.MediaInfo, error)
 	GenerateThumbnailFromUI(filePath string, maxWidth, maxHeight int) (string, error)
@@ -57,6 +140,18 @@ func NewUI(app fyne.App, coreApp CoreApp, platform Platform) (*UI, error) {
 		return nil, fmt.Errorf("failed to initialize theme manager: %w", err)
 	}
 
+	// Scale text to the configured font size and load the strings of the
+	// configured language, which falls back to English
+	if configMgr := coreApp.GetConfigManager(); configMgr != nil {
+		cfg := configMgr.GetConfig()
+		if err := themeManager.SetFontScale(theme.FontScaleFor(cfg.UI.FontSize)); err != nil {
+			log.Printf("Warning: Failed to apply font size: %v", err)
+		}
+		if err := i18n.SetLanguage(cfg.UI.Language); err != nil {
+			log.Printf("Warning: Failed to load language %q, using English: %v", cfg.UI.Language, err)
+		}
+	}
+
 	// Apply theme to app
 	themeManager.ApplyTheme(app)
 
@@ -65,6 +160,7 @@ func NewUI(app fyne.App, coreApp CoreApp, platform Platform) (*UI, error) {
 		coreApp:      coreApp,
 		platform:     platform,
 		themeManager: themeManager,
+		presentation: shared.NewPresentationMode(),
 	}
 
 	return ui, nil
@@ -80,20 +176,112 @@ func (ui *UI) Initialize(ctx context.Context) error {
 	// Create UI components
 	ui.chatView = shared.NewChatView(ui.coreApp)
 	ui.contactList = shared.NewContactList(ui.coreApp)
+	ui.groupChat = shared.NewGroupChatView(ui.coreApp)
+	ui.groupChat.Container().Hide()
+	chats := container.NewStack(ui.chatView.Container(), ui.groupChat.Container())
+	ui.chatArea = container.NewBorder(ui.setupCalls(), nil, nil, nil, chats)
+
+	// Presentation mode masks the UI and holds back notifications
+	ui.chatView.SetPresentationMode(ui.presentation)
+	ui.contactList.SetPresentationMode(ui.presentation)
+	ui.presentation.OnChange(ui.coreApp.SetPresentationMode)
 
 	// Set up contact selection callback with mobile navigation
-	ui.contactList.SetOnContactSelect(func(friendID uint32) {
-		ui.chatView.SetCurrentFriend(friendID)
+	ui.chatView.SetOnActivity(ui.coreApp.RecordActivity)
+	ui.contactList.SetOnContactSelect(ui.openConversation)
+	ui.contactList.SetOnConversationCleared(func(friendID uint32) {
+		ui.chatView.RefreshConversations([]uint32{friendID})
+	})
+	ui.chatView.SetOnSearch(ui.showSearchDialog)
+	ui.chatView.SetOnShowDetails(ui.contactList.ShowContactDetails)
+	ui.chatView.SetOnToxLink(ui.contactList.ShowAddFriendDialogFor)
+	ui.contactList.SetCopyHandler(ui.copySensitive)
+	ui.setupGroups()
+	if contacts := ui.coreApp.GetContacts(); contacts != nil {
+		contacts.SetOnPresenceChanged(ui.contactList.UpdatePresence)
+		contacts.SetOnAvatarChanged(func(friendID uint32) {
+			ui.contactList.UpdateAvatar(friendID)
+			ui.chatView.UpdateAvatar(friendID)
+		})
+	}
+	ui.coreApp.SetOnAutoAwayChanged(func(away bool) {
+		ui.contactList.RefreshSelfStatus()
+	})
+	ui.coreApp.SetOnConnectionChanged(func(state tox.ConnectionState) {
+		ui.contactList.RefreshSelfStatus()
+	})
+	if messages := ui.coreApp.GetMessages(); messages != nil {
+		messages.SetOnFriendTyping(ui.chatView.SetFriendTyping)
+		messages.SetOnMessagesExpired(ui.chatView.RefreshConversations)
+		messages.SetOnMessageChanged(func(friendID uint32, messageUUID string) {
+			ui.chatView.RefreshConversations([]uint32{friendID})
+		})
+		messages.SetOnMessageStatus(func(friendID uint32, messageUUID string) {
+			ui.chatView.RefreshConversations([]uint32{friendID})
+		})
+	}
+
+	// Keep desktop notification styling in step with the app theme
+	ui.syncNotificationAppearance(ui.themeManager.GetThemeType())
+	ui.themeManager.OnThemeChanged(ui.syncNotificationAppearance)
 
-		// On mobile, automatically navigate to chat tab when contact is selected
-		if ui.platform.IsMobile() {
-			ui.NavigateToChat()
+	// Only suppress notifications for the open chat while the app is in front
+	ui.app.Lifecycle().SetOnEnteredForeground(func() {
+		ui.coreApp.RecordActivity()
+		if friendID := ui.chatView.CurrentFriend(); friendID != 0 {
+			ui.coreApp.SetActiveConversation(friendID)
 		}
 	})
+	ui.app.Lifecycle().SetOnExitedForeground(func() {
+		ui.coreApp.ClearActiveConversation()
+	})
 
 	return nil
 }
 
+// openConversation shows the conversation with a friend
+func (ui *UI) openConversation(friendID uint32) {
+	ui.coreApp.RecordActivity()
+	ui.showFriendChat()
+	ui.chatView.SetCurrentFriend(friendID)
+	ui.coreApp.SetActiveConversation(friendID)
+
+	// On mobile, automatically navigate to chat tab when contact is selected
+	if ui.platform.IsMobile() {
+		ui.NavigateToChat()
+	}
+}
+
+// showSearchDialog searches message history, across all conversations when
+// friendID is 0, and opens the result the user picks
+func (ui *UI) showSearchDialog(friendID uint32) {
+	if ui.mainWindow == nil || ui.chatView == nil {
+		return
+	}
+
+	search := shared.NewSearchDialog(ui.coreApp, ui.mainWindow, ui.presentation)
+	search.OnOpen = func(msg *message.Message) {
+		if ui.chatView.CurrentFriend() != msg.FriendID {
+			ui.openConversation(msg.FriendID)
+		}
+		ui.chatView.ScrollToMessage(msg.ID)
+	}
+	search.Show(friendID)
+}
+
+// syncNotificationAppearance passes the effective light/dark variant of a theme to notifications
+func (ui *UI) syncNotificationAppearance(themeType theme.ThemeType) {
+	if themeType == theme.ThemeSystem {
+		themeType = ui.themeManager.DetectSystemTheme()
+	}
+
+	variant := "light"
+	if themeType == theme.ThemeDark || themeType == theme.ThemeAMOLED {
+		variant = "dark"
+	}
+	ui.coreApp.SetNotificationAppearance(variant)
+}
+
 // CreateMainContent creates the main content for the window
 func (ui *UI) CreateMainContent() fyne.CanvasObject {
 	// Create menu bar
@@ -113,9 +301,9 @@ func (ui *UI) createMobileLayout() fyne.CanvasObject {
 
 	// Create mobile-optimized tabs with larger touch targets
 	tabs := container.NewAppTabs(
-		container.NewTabItem("Contacts", contactsWithRefresh),
-		container.NewTabItem("Chat", ui.chatView.Container()),
-		container.NewTabItem("Settings", ui.createMobileSettingsView()),
+		container.NewTabItem(i18n.T("tab.contacts"), contactsWithRefresh),
+		container.NewTabItem(i18n.T("tab.chat"), ui.chatArea),
+		container.NewTabItem(i18n.T("tab.settings"), ui.createMobileSettingsView()),
 	)
 
 	// Configure tab bar for mobile
@@ -130,8 +318,16 @@ func (ui *UI) createMobileLayout() fyne.CanvasObject {
 	return tabs
 }
 
-// ShowMainWindow shows the main application window
+// ShowMainWindow shows the main application window and runs the event loop
+// until the app quits
 func (ui *UI) ShowMainWindow() {
+	ui.OpenMainWindow()
+	ui.app.Run()
+}
+
+// OpenMainWindow shows the main application window, for callers that run
+// the app's event loop themselves
+func (ui *UI) OpenMainWindow() {
 	ui.mainWindow = ui.app.NewWindow("Whisp")
 
 	// Load window state from configuration
@@ -140,13 +336,18 @@ func (ui *UI) ShowMainWindow() {
 	// Set parent window for contact list dialogs
 	if ui.contactList != nil {
 		ui.contactList.SetParentWindow(ui.mainWindow)
+		ui.groupChat.SetParentWindow(ui.mainWindow)
+		if ui.callView != nil {
+			ui.callView.SetParentWindow(ui.mainWindow)
+		}
 		// Initial refresh of contacts
 		ui.contactList.RefreshContacts()
 	}
 
-	// Setup keyboard shortcuts for desktop platforms
+	// Setup keyboard shortcuts and file dropping for desktop platforms
 	if !ui.platform.IsMobile() {
 		ui.setupKeyboardShortcuts()
+		ui.setupFileDrop()
 	}
 
 	// Create layout based on platform
@@ -156,19 +357,20 @@ func (ui *UI) ShowMainWindow() {
 		ui.setupDesktopLayout()
 	}
 
-	// Setup window close handler to save state
-	ui.mainWindow.SetCloseIntercept(func() {
-		ui.saveWindowState()
-		ui.app.Quit()
-	})
+	// Cover the window with the lock screen whenever the app locks
+	ui.coreApp.SetOnLockChanged(ui.onLockChanged)
+
+	// Closing hides to the tray if there is one, and otherwise saves state and quits
+	ui.setupSystemTray()
+	ui.mainWindow.SetCloseIntercept(ui.closeMainWindow)
 
-	ui.mainWindow.ShowAndRun()
+	ui.mainWindow.Show()
 }
 
 // createPullToRefreshContacts creates a pull-to-refresh container for contacts
 func (ui *UI) createPullToRefreshContacts() fyne.CanvasObject {
 	// Create refresh button for mobile
-	refreshBtn := widget.NewButton("Pull to Refresh", func() {
+	refreshBtn := widget.NewButton(i18n.T("mobile.pull_to_refresh"), func() {
 		ui.contactList.RefreshContacts()
 	})
 	refreshBtn.Importance = widget.LowImportance
@@ -183,18 +385,16 @@ func (ui *UI) createPullToRefreshContacts() fyne.CanvasObject {
 // createMobileSettingsView creates a mobile-optimized settings view
 func (ui *UI) createMobileSettingsView() fyne.CanvasObject {
 	// Create mobile settings with larger touch targets
-	toxIDBtn := widget.NewButton("Show Tox ID", func() {
+	toxIDBtn := widget.NewButton(i18n.T("mobile.show_tox_id"), func() {
 		toxID := ui.coreApp.GetToxID()
-		dialog.ShowInformation("Your Tox ID", toxID, ui.mainWindow)
+		dialog.ShowInformation(i18n.T("toxid.yours"), toxID, ui.mainWindow)
 	})
 
-	settingsBtn := widget.NewButton("Application Settings", func() {
-		configMgr := ui.coreApp.GetConfigManager()
-		settingsDialog := shared.NewSettingsDialog(configMgr, ui.mainWindow)
-		settingsDialog.Show()
+	settingsBtn := widget.NewButton(i18n.T("mobile.app_settings"), func() {
+		ui.showSettingsDialog()
 	})
 
-	aboutBtn := widget.NewButton("About Whisp", func() {
+	aboutBtn := widget.NewButton(i18n.T("about.title"), func() {
 		ui.showAboutDialog()
 	})
 
@@ -204,7 +404,7 @@ func (ui *UI) createMobileSettingsView() fyne.CanvasObject {
 	aboutBtn.Resize(fyne.NewSize(300, 60))
 
 	return container.NewVBox(
-		widget.NewCard("", "Quick Actions", container.NewVBox(
+		widget.NewCard("", i18n.T("mobile.quick_actions"), container.NewVBox(
 			toxIDBtn,
 			settingsBtn,
 			aboutBtn,
@@ -257,7 +457,7 @@ func (ui *UI) createDesktopLayout() fyne.CanvasObject {
 	// Create main content
 	content := container.NewHSplit(
 		ui.contactList.Container(),
-		ui.chatView.Container(),
+		ui.chatArea,
 	)
 	content.SetOffset(0.3) // 30% for contacts, 70% for chat
 
@@ -290,45 +490,142 @@ func (ui *UI) setupMobileLayout() {
 	ui.configureMobileWindow()
 }
 
+// showSettingsDialog opens the settings, including database backups and
+// bootstrap nodes
+func (ui *UI) showSettingsDialog() {
+	settingsDialog := shared.NewSettingsDialog(ui.coreApp.GetConfigManager(), ui.themeManager, ui.mainWindow)
+	settingsDialog.SetDatabaseBackup(ui.coreApp)
+	settingsDialog.SetBootstrapNodeStore(ui.coreApp)
+	settingsDialog.Show()
+}
+
 // createMenuBar creates the application menu bar
 func (ui *UI) createMenuBar() *fyne.Container {
 	// File menu
-	settingsItem := fyne.NewMenuItem("Settings", func() {
-		configMgr := ui.coreApp.GetConfigManager()
-		settingsDialog := shared.NewSettingsDialog(configMgr, ui.mainWindow)
-		settingsDialog.Show()
+	settingsItem := fyne.NewMenuItem(i18n.T("menu.settings"), func() {
+		ui.showSettingsDialog()
 	})
 
-	quitItem := fyne.NewMenuItem("Quit", func() {
-		ui.saveWindowState()
-		ui.app.Quit()
+	presentationItem := fyne.NewMenuItem(i18n.T("menu.presentation_mode"), func() {
+		ui.presentation.Toggle()
 	})
+	presentationItem.Checked = ui.presentation.Enabled()
 
-	fileMenu := fyne.NewMenu("File",
+	notificationsItem := fyne.NewMenuItem(i18n.T("menu.notifications"), ui.showNotificationHistoryDialog)
+
+	lockItem := fyne.NewMenuItem(i18n.T("menu.lock"), ui.lock)
+
+	quitItem := fyne.NewMenuItem(i18n.T("menu.quit"), ui.quit)
+
+	fileMenu := fyne.NewMenu(i18n.T("menu.file"),
 		settingsItem,
+		presentationItem,
+		notificationsItem,
+		lockItem,
 		fyne.NewMenuItemSeparator(),
 		quitItem,
 	)
 
 	// Friends menu
-	addFriendItem := fyne.NewMenuItem("Add Friend", func() {
+	addFriendItem := fyne.NewMenuItem(i18n.T("menu.add_friend"), func() {
 		if ui.contactList != nil {
 			ui.contactList.ShowAddFriendDialog()
 		}
 	})
 
-	showToxIDItem := fyne.NewMenuItem("Show My Tox ID", func() {
+	requestsItem := fyne.NewMenuItem(i18n.T("menu.friend_requests"), func() {
+		if ui.contactList != nil {
+			ui.contactList.ShowFriendRequestsDialog()
+		}
+	})
+
+	showToxIDItem := fyne.NewMenuItem(i18n.T("menu.show_tox_id"), func() {
 		ui.showToxIDDialog()
 	})
 
-	friendsMenu := fyne.NewMenu("Friends",
+	detailsItem := fyne.NewMenuItem(i18n.T("menu.contact_details"), func() {
+		ui.showContactDetails()
+	})
+
+	cleanUpItem := fyne.NewMenuItem(i18n.T("menu.clean_up_contacts"), func() {
+		if ui.contactList != nil {
+			ui.contactList.ShowStaleContactsDialog()
+		}
+	})
+
+	markAllReadItem := fyne.NewMenuItem(i18n.T("menu.mark_all_read"), func() {
+		if ui.contactList != nil {
+			ui.contactList.MarkAllRead()
+		}
+	})
+
+	clearHistoryItem := fyne.NewMenuItem(i18n.T("menu.clear_history"), func() {
+		ui.clearCurrentConversation()
+	})
+
+	exportChatItem := fyne.NewMenuItem(i18n.T("menu.export_chat"), func() {
+		ui.showExportChatDialog()
+	})
+
+	disappearingItem := fyne.NewMenuItem(i18n.T("menu.disappearing"), func() {
+		ui.showDisappearingDialog()
+	})
+
+	searchItem := fyne.NewMenuItem(i18n.T("menu.search_messages"), func() {
+		ui.showSearchDialog(0)
+	})
+
+	searchChatItem := fyne.NewMenuItem(i18n.T("menu.search_conversation"), func() {
+		ui.searchCurrentConversation()
+	})
+
+	voiceCallItem := fyne.NewMenuItem(i18n.T("menu.voice_call"), func() {
+		ui.callCurrentFriend(false)
+	})
+
+	videoCallItem := fyne.NewMenuItem(i18n.T("menu.video_call"), func() {
+		ui.callCurrentFriend(true)
+	})
+
+	friendsMenu := fyne.NewMenu(i18n.T("menu.friends"),
 		addFriendItem,
+		requestsItem,
 		showToxIDItem,
+		detailsItem,
+		disappearingItem,
+		cleanUpItem,
+		fyne.NewMenuItemSeparator(),
+		voiceCallItem,
+		videoCallItem,
+		fyne.NewMenuItemSeparator(),
+		searchItem,
+		searchChatItem,
+		exportChatItem,
+		fyne.NewMenuItemSeparator(),
+		markAllReadItem,
+		clearHistoryItem,
+	)
+
+	// Profile menu
+	profileMenu := fyne.NewMenu(i18n.T("menu.profile"),
+		fyne.NewMenuItem(i18n.T("menu.edit_profile"), ui.showProfileDialog),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem(i18n.T("menu.export_profile"), func() {
+			ui.showExportProfileDialog()
+		}),
+		fyne.NewMenuItem(i18n.T("menu.import_profile"), func() {
+			ui.showImportProfileDialog()
+		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem(i18n.T("menu.change_password"), func() {
+			ui.showChangePasswordDialog()
+		}),
 	)
 
 	// Help menu
-	helpMenu := fyne.NewMenu("Help",
-		fyne.NewMenuItem("About", func() {
+	helpMenu := fyne.NewMenu(i18n.T("menu.help"),
+		fyne.NewMenuItem(i18n.T("menu.diagnostics"), ui.showDiagnosticsDialog),
+		fyne.NewMenuItem(i18n.T("menu.about"), func() {
 			ui.showAboutDialog()
 		}),
 	)
@@ -338,10 +635,19 @@ func (ui *UI) createMenuBar() *fyne.Container {
 		settingsItem.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyComma, Modifier: fyne.KeyModifierControl}
 		quitItem.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyQ, Modifier: fyne.KeyModifierControl}
 		addFriendItem.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyN, Modifier: fyne.KeyModifierControl}
+		presentationItem.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyP, Modifier: fyne.KeyModifierControl | fyne.KeyModifierShift}
+		lockItem.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyL, Modifier: fyne.KeyModifierControl}
+		searchItem.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyF, Modifier: fyne.KeyModifierControl}
+		searchChatItem.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyF, Modifier: fyne.KeyModifierControl | fyne.KeyModifierShift}
 	}
 
 	// Create menu bar and set it on the main window if available
-	mainMenu := fyne.NewMainMenu(fileMenu, friendsMenu, helpMenu)
+	mainMenu := fyne.NewMainMenu(fileMenu, friendsMenu, profileMenu, helpMenu)
+	ui.mainMenu = mainMenu
+	ui.presentation.OnChange(func(enabled bool) {
+		presentationItem.Checked = enabled
+		mainMenu.Refresh()
+	})
 	if ui.mainWindow != nil {
 		ui.mainWindow.SetMainMenu(mainMenu)
 	}
@@ -364,8 +670,7 @@ func (ui *UI) setupKeyboardShortcuts() {
 		KeyName:  fyne.KeyQ,
 		Modifier: fyne.KeyModifierControl,
 	}, func(shortcut fyne.Shortcut) {
-		ui.saveWindowState()
-		ui.app.Quit()
+		ui.quit()
 	})
 
 	// Ctrl+N: Add new friend
@@ -383,42 +688,80 @@ func (ui *UI) setupKeyboardShortcuts() {
 		KeyName:  fyne.KeyComma,
 		Modifier: fyne.KeyModifierControl,
 	}, func(shortcut fyne.Shortcut) {
-		configMgr := ui.coreApp.GetConfigManager()
-		settingsDialog := shared.NewSettingsDialog(configMgr, ui.mainWindow)
-		settingsDialog.Show()
+		ui.showSettingsDialog()
+	})
+
+	// Ctrl+Shift+P: Toggle presentation mode
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyP,
+		Modifier: fyne.KeyModifierControl | fyne.KeyModifierShift,
+	}, func(shortcut fyne.Shortcut) {
+		ui.presentation.Toggle()
+	})
+
+	// Ctrl+F: Search all conversations
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyF,
+		Modifier: fyne.KeyModifierControl,
+	}, func(shortcut fyne.Shortcut) {
+		ui.showSearchDialog(0)
+	})
+
+	// Ctrl+Shift+F: Search the open conversation
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyF,
+		Modifier: fyne.KeyModifierControl | fyne.KeyModifierShift,
+	}, func(shortcut fyne.Shortcut) {
+		ui.searchCurrentConversation()
+	})
+
+	// Ctrl+L: Lock the app
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyL,
+		Modifier: fyne.KeyModifierControl,
+	}, func(shortcut fyne.Shortcut) {
+		ui.lock()
+	})
+
+	// Key presses outside text fields count as activity for auto-lock
+	canvas.SetOnTypedKey(func(*fyne.KeyEvent) {
+		ui.coreApp.RecordActivity()
 	})
 
 	// Escape: Close current dialog (handled by Fyne automatically)
 }
 
+// defaultWindowSize is the main window size used until one has been saved
+var defaultWindowSize = fyne.NewSize(1000, 700)
+
+// minSavedWindowSize is the smallest saved size that is restored; anything
+// smaller is treated as corrupt and replaced by the default
+var minSavedWindowSize = fyne.NewSize(320, 240)
+
 // loadWindowState loads window size and position from configuration
 func (ui *UI) loadWindowState() {
 	configMgr := ui.coreApp.GetConfigManager()
 	config := configMgr.GetConfig()
 
-	// Set default size
-	defaultSize := fyne.NewSize(1000, 700)
-	ui.mainWindow.Resize(defaultSize)
-
-	// TODO: Load saved size and position when configuration structure is extended
-	// For now, use defaults based on config flags
+	size := defaultWindowSize
 	if config.UI.Window.RememberSize {
-		// Use saved size when available (future enhancement)
-		ui.mainWindow.Resize(defaultSize)
+		size = savedWindowSize(config.UI.Window.Width, config.UI.Window.Height)
 	}
+	ui.mainWindow.Resize(size)
 
-	if config.UI.Window.RememberPosition {
-		// Center window for now (future enhancement will use saved position)
-		ui.mainWindow.CenterOnScreen()
-	} else {
-		ui.mainWindow.CenterOnScreen()
-	}
+	// Fyne does not expose window positions, so the window is always centered.
+	// This also keeps it visible when the monitor it was last on is gone.
+	ui.mainWindow.CenterOnScreen()
+}
 
-	// Handle minimize to tray setting
-	if config.UI.Window.MinimizeToTray {
-		// TODO: Implement system tray functionality
-		// For now, just set the behavior flag
+// savedWindowSize returns the saved window size, or the default if none was
+// saved or the saved one is too small to be usable
+func savedWindowSize(width, height int) fyne.Size {
+	size := fyne.NewSize(float32(width), float32(height))
+	if size.Width < minSavedWindowSize.Width || size.Height < minSavedWindowSize.Height {
+		return defaultWindowSize
 	}
+	return size
 }
 
 // saveWindowState saves current window size and position to configuration
@@ -429,14 +772,15 @@ func (ui *UI) saveWindowState() {
 
 	configMgr := ui.coreApp.GetConfigManager()
 	config := configMgr.GetConfig()
+	if !config.UI.Window.RememberSize {
+		return
+	}
 
-	// Only save if remember settings are enabled
-	if config.UI.Window.RememberSize || config.UI.Window.RememberPosition {
-		// TODO: Save actual window size and position when configuration structure is extended
-		// For now, just ensure the flags are preserved
-		if err := configMgr.Save(); err != nil {
-			fmt.Printf("Warning: Failed to save window state: %v\n", err)
-		}
+	size := ui.mainWindow.Canvas().Size()
+	config.UI.Window.Width = int(size.Width)
+	config.UI.Window.Height = int(size.Height)
+	if err := configMgr.UpdateConfig(config); err != nil {
+		fmt.Printf("Warning: Failed to save window state: %v\n", err)
 	}
 }
 
@@ -452,19 +796,102 @@ func (ui *UI) showToxIDDialog() {
 	entry.SetText(toxID)
 	entry.Disable()
 
-	copyButton := widget.NewButton("Copy to Clipboard", func() {
-		ui.mainWindow.Clipboard().SetContent(toxID)
+	copyButton := widget.NewButton(i18n.T("toxid.copy"), func() {
+		ui.copySensitive(toxID)
 		// Show brief confirmation
-		dialog.ShowInformation("Copied", "Tox ID copied to clipboard", ui.mainWindow)
+		dialog.ShowInformation(i18n.T("toxid.copied_title"), i18n.T("toxid.copied"), ui.mainWindow)
 	})
 
 	content := container.NewVBox(
-		widget.NewLabel("Your Tox ID:"),
+		widget.NewLabel(i18n.T("toxid.label")),
 		entry,
 		copyButton,
 	)
 
-	dialog.ShowCustom("My Tox ID", "Close", content, ui.mainWindow)
+	dialog.ShowCustom(i18n.T("toxid.title"), i18n.T("common.close"), content, ui.mainWindow)
+}
+
+// copySensitive copies text to the clipboard, clearing it after the configured delay
+func (ui *UI) copySensitive(text string) {
+	if ui.clipboard == nil {
+		ui.clipboard = shared.NewClipboardGuard(ui.mainWindow.Clipboard())
+	}
+
+	seconds := ui.coreApp.GetConfigManager().GetConfig().Privacy.ClipboardClearSeconds
+	ui.clipboard.CopySensitive(text, time.Duration(seconds)*time.Second)
+}
+
+// clearCurrentConversation clears the history of the open conversation
+func (ui *UI) clearCurrentConversation() {
+	if ui.mainWindow == nil || ui.chatView == nil || ui.contactList == nil {
+		return
+	}
+
+	friendID := ui.chatView.CurrentFriend()
+	if friendID == 0 {
+		dialog.ShowInformation(i18n.T("clear_history.title"), i18n.T("clear_history.open_conversation"), ui.mainWindow)
+		return
+	}
+	ui.contactList.ClearConversation(friendID)
+}
+
+// showContactDetails shows the details of the contact whose conversation is open
+func (ui *UI) showContactDetails() {
+	if ui.mainWindow == nil || ui.chatView == nil || ui.contactList == nil {
+		return
+	}
+
+	friendID := ui.chatView.CurrentFriend()
+	if friendID == 0 {
+		dialog.ShowInformation(i18n.T("details.title"), i18n.T("details.open_conversation"), ui.mainWindow)
+		return
+	}
+	ui.contactList.ShowContactDetails(friendID)
+}
+
+// searchCurrentConversation searches the open conversation, or all of them
+// if none is open
+func (ui *UI) searchCurrentConversation() {
+	if ui.chatView == nil {
+		return
+	}
+	ui.showSearchDialog(ui.chatView.CurrentFriend())
+}
+
+// showExportChatDialog saves the open conversation to a file chosen by the user.
+// The file extension picks the format: .json for JSON, anything else for HTML.
+func (ui *UI) showExportChatDialog() {
+	if ui.mainWindow == nil || ui.chatView == nil {
+		return
+	}
+
+	friendID := ui.chatView.CurrentFriend()
+	messages := ui.coreApp.GetMessages()
+	if friendID == 0 || messages == nil {
+		dialog.ShowInformation(i18n.T("export_chat.title"), i18n.T("export_chat.open_conversation"), ui.mainWindow)
+		return
+	}
+
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, ui.mainWindow)
+			return
+		}
+		if writer == nil {
+			return // Cancelled
+		}
+		defer writer.Close()
+
+		format := message.ExportFormatHTML
+		if strings.EqualFold(writer.URI().Extension(), ".json") {
+			format = message.ExportFormatJSON
+		}
+		if err := messages.ExportConversation(friendID, format, writer); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to export chat: %w", err), ui.mainWindow)
+		}
+	}, ui.mainWindow)
+	save.SetFileName(fmt.Sprintf("whisp-chat-%d.html", friendID))
+	save.Show()
 }
 
 // showAboutDialog displays the about dialog
@@ -475,15 +902,15 @@ func (ui *UI) showAboutDialog() {
 
 	content := container.NewVBox(
 		widget.NewLabel("Whisp"),
-		widget.NewLabel("Secure Cross-Platform Messaging"),
+		widget.NewLabel(i18n.T("about.tagline")),
 		widget.NewLabel(""),
-		widget.NewLabel("Built with Go and Fyne"),
-		widget.NewLabel("Uses Tox protocol for P2P messaging"),
+		widget.NewLabel(i18n.T("about.built_with")),
+		widget.NewLabel(i18n.T("about.protocol")),
 		widget.NewLabel(""),
-		widget.NewLabel("Version: 1.0.0-dev"),
+		widget.NewLabel(i18n.Tf("about.version", "1.0.0-dev")),
 	)
 
-	dialog.ShowCustom("About Whisp", "Close", content, ui.mainWindow)
+	dialog.ShowCustom(i18n.T("about.title"), i18n.T("common.close"), content, ui.mainWindow)
 }
 
 // GetThemeManager returns the theme manager
@@ -499,22 +926,27 @@ func (ui *UI) ShowThemeDialog() {
 
 	// Current theme info
 	currentTheme := ui.themeManager.GetThemeType()
-	themeLabel := widget.NewLabel(fmt.Sprintf("Current theme: %v", currentTheme))
+	themeLabel := widget.NewLabel(i18n.Tf("theme_dialog.current", currentTheme))
 
 	// Theme selection buttons
-	lightBtn := widget.NewButton("Light Theme", func() {
+	lightBtn := widget.NewButton(i18n.T("theme_dialog.light"), func() {
 		ui.themeManager.SetTheme(theme.ThemeLight)
-		themeLabel.SetText(fmt.Sprintf("Current theme: %v", theme.ThemeLight))
+		themeLabel.SetText(i18n.Tf("theme_dialog.current", theme.ThemeLight))
 	})
 
-	darkBtn := widget.NewButton("Dark Theme", func() {
+	darkBtn := widget.NewButton(i18n.T("theme_dialog.dark"), func() {
 		ui.themeManager.SetTheme(theme.ThemeDark)
-		themeLabel.SetText(fmt.Sprintf("Current theme: %v", theme.ThemeDark))
+		themeLabel.SetText(i18n.Tf("theme_dialog.current", theme.ThemeDark))
+	})
+
+	amoledBtn := widget.NewButton(i18n.T("theme_dialog.amoled"), func() {
+		ui.themeManager.SetTheme(theme.ThemeAMOLED)
+		themeLabel.SetText(i18n.Tf("theme_dialog.current", theme.ThemeAMOLED))
 	})
 
-	systemBtn := widget.NewButton("System Theme", func() {
+	systemBtn := widget.NewButton(i18n.T("theme_dialog.system"), func() {
 		ui.themeManager.SetTheme(theme.ThemeSystem)
-		themeLabel.SetText(fmt.Sprintf("Current theme: %v", theme.ThemeSystem))
+		themeLabel.SetText(i18n.Tf("theme_dialog.current", theme.ThemeSystem))
 	})
 
 	// Custom themes section
@@ -523,7 +955,7 @@ func (ui *UI) ShowThemeDialog() {
 
 	if len(customThemes) > 0 {
 		customButtons = append(customButtons, widget.NewSeparator())
-		customButtons = append(customButtons, widget.NewLabel("Custom Themes:"))
+		customButtons = append(customButtons, widget.NewLabel(i18n.T("theme_dialog.custom_themes")))
 
 		for _, ct := range customThemes {
 			themeName := ct.Name // Capture for closure
@@ -533,7 +965,7 @@ func (ui *UI) ShowThemeDialog() {
 				prefs.CustomThemeName = themeName
 				prefs.ThemeType = theme.ThemeCustom
 				ui.themeManager.SetPreferences(prefs)
-				themeLabel.SetText(fmt.Sprintf("Current theme: %s (custom)", themeName))
+				themeLabel.SetText(i18n.Tf("theme_dialog.current_custom", themeName))
 			})
 			customButtons = append(customButtons, btn)
 		}
@@ -541,7 +973,7 @@ func (ui *UI) ShowThemeDialog() {
 
 	// Preferences
 	prefs := ui.themeManager.GetPreferences()
-	followSystemCheck := widget.NewCheck("Follow system theme", func(checked bool) {
+	followSystemCheck := widget.NewCheck(i18n.T("theme_dialog.follow_system"), func(checked bool) {
 		ui.themeManager.EnableSystemThemeFollowing(checked)
 	})
 	followSystemCheck.SetChecked(prefs.FollowSystemTheme)
@@ -550,8 +982,8 @@ func (ui *UI) ShowThemeDialog() {
 	content := container.NewVBox(
 		themeLabel,
 		widget.NewSeparator(),
-		widget.NewLabel("Theme Selection:"),
-		container.NewHBox(lightBtn, darkBtn, systemBtn),
+		widget.NewLabel(i18n.T("theme_dialog.selection")),
+		container.NewHBox(lightBtn, darkBtn, amoledBtn, systemBtn),
 	)
 
 	// Add custom theme buttons if any
@@ -559,8 +991,12 @@ func (ui *UI) ShowThemeDialog() {
 		content.Add(btn)
 	}
 
+	content.Add(widget.NewButton(i18n.T("theme_dialog.new_custom"), func() {
+		shared.NewThemeEditor(ui.themeManager, ui.mainWindow).Show()
+	}))
+
 	content.Add(widget.NewSeparator())
 	content.Add(followSystemCheck)
 
-	dialog.ShowCustom("Theme Settings", "Close", content, ui.mainWindow)
+	dialog.ShowCustom(i18n.T("theme_dialog.title"), i18n.T("common.close"), content, ui.mainWindow)
 }
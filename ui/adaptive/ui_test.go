@@ -3,15 +3,24 @@ package adaptive
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/test"
 
+	"github.com/opd-ai/whisp/internal/core/calls"
 	"github.com/opd-ai/whisp/internal/core/config"
 	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/group"
+	"github.com/opd-ai/whisp/internal/core/linkpreview"
 	"github.com/opd-ai/whisp/internal/core/media"
 	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/internal/core/tox"
+	"github.com/opd-ai/whisp/internal/core/transfer"
+	"github.com/opd-ai/whisp/internal/storage"
 )
 
 // MockCoreApp implements CoreApp interface for testing
@@ -42,6 +51,26 @@ func (m *MockCoreApp) GetMessages() *message.Manager {
 	return m.messages
 }
 
+func (m *MockCoreApp) GetSelfStatus() contact.Status {
+	return contact.StatusOnline
+}
+
+func (m *MockCoreApp) SetSelfStatus(status contact.Status) {}
+
+func (m *MockCoreApp) IsAutoAway() bool { return false }
+
+func (m *MockCoreApp) GetConnectionState() tox.ConnectionState { return tox.ConnectionOnline }
+
+func (m *MockCoreApp) SetOnConnectionChanged(callback func(state tox.ConnectionState)) {}
+
+func (m *MockCoreApp) GetDiagnostics() Diagnostics { return Diagnostics{} }
+
+func (m *MockCoreApp) SetOnAutoAwayChanged(callback func(away bool)) {}
+
+func (m *MockCoreApp) GetGroups() *group.Manager {
+	return nil
+}
+
 func (m *MockCoreApp) GetConfigManager() *config.Manager {
 	return m.configMgr
 }
@@ -54,6 +83,67 @@ func (m *MockCoreApp) AddContactFromUI(toxID, message string) error {
 	return nil
 }
 
+func (m *MockCoreApp) SetActiveConversation(friendID uint32) {}
+
+func (m *MockCoreApp) SetNotificationAppearance(variant string) {}
+
+func (m *MockCoreApp) GetNotificationHistory(limit int) []NotificationRecord { return nil }
+
+func (m *MockCoreApp) ClearNotificationHistory() {}
+
+func (m *MockCoreApp) SetPresentationMode(enabled bool) {}
+
+func (m *MockCoreApp) RecordActivity() {}
+
+func (m *MockCoreApp) ExportProfile(path, password string) error { return nil }
+
+func (m *MockCoreApp) ImportProfile(path, password string) error { return nil }
+
+func (m *MockCoreApp) BackupDatabase(path string) error     { return nil }
+func (m *MockCoreApp) GetSelfAvatar() []byte                { return nil }
+func (m *MockCoreApp) SetSelfAvatar(imagePath string) error { return nil }
+func (m *MockCoreApp) GetProfile() (string, string)         { return "", "" }
+func (m *MockCoreApp) LinkPreviewMode(friendID uint32, outgoing bool) linkpreview.Mode {
+	return linkpreview.ModeOff
+}
+func (m *MockCoreApp) FetchLinkPreview(rawURL string) (*linkpreview.Preview, error) { return nil, nil }
+func (m *MockCoreApp) CachedLinkPreview(rawURL string) (*linkpreview.Preview, bool) {
+	return nil, false
+}
+func (m *MockCoreApp) UpdateProfile(name, statusMessage string) error { return nil }
+func (m *MockCoreApp) ListBootstrapNodes() []tox.BootstrapNode {
+	return tox.DefaultBootstrapNodes
+}
+func (m *MockCoreApp) SaveBootstrapNodes(nodes []tox.BootstrapNode) error { return nil }
+
+func (m *MockCoreApp) RestoreDatabase(path string) error { return nil }
+
+func (m *MockCoreApp) LastBackupTime() time.Time { return time.Time{} }
+
+func (m *MockCoreApp) CompactDatabase() (*storage.CompactResult, error) {
+	return &storage.CompactResult{}, nil
+}
+
+func (m *MockCoreApp) SendFileFromUI(friendID uint32, filePath string) (string, error) {
+	return "", nil
+}
+func (m *MockCoreApp) CancelFileFromUI(transferID string) error { return nil }
+func (m *MockCoreApp) GetTransfers() *transfer.Manager          { return nil }
+
+func (m *MockCoreApp) ChangePassword(oldPassword, newPassword string) error { return nil }
+
+func (m *MockCoreApp) Lock() error { return nil }
+
+func (m *MockCoreApp) Unlock(password string) error { return nil }
+
+func (m *MockCoreApp) SetOnLockChanged(callback func(locked bool)) {}
+
+func (m *MockCoreApp) GetCalls() *calls.Manager { return nil }
+
+func (m *MockCoreApp) SetOnCallEvent(callback func(event *calls.CallEvent)) {}
+
+func (m *MockCoreApp) ClearActiveConversation() {}
+
 // Media-related methods required by CoreApp interface
 func (m *MockCoreApp) GetMediaInfoFromUI(filePath string) (*media.MediaInfo, error) {
 	return &media.MediaInfo{
@@ -327,6 +417,62 @@ func TestUI_SaveWindowState(t *testing.T) {
 	ui.saveWindowState()
 }
 
+func TestUI_WindowSizeRoundTrip(t *testing.T) {
+	testApp := test.NewApp()
+	defer testApp.Quit()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configMgr, err := config.NewManager(configPath)
+	if err != nil {
+		t.Fatalf("Failed to create config manager: %v", err)
+	}
+
+	ui, err := NewUI(testApp, &MockCoreApp{configMgr: configMgr}, PlatformLinux)
+	if err != nil {
+		t.Fatalf("NewUI failed: %v", err)
+	}
+	ui.mainWindow = testApp.NewWindow("Test")
+	ui.mainWindow.Resize(fyne.NewSize(800, 600))
+	ui.saveWindowState()
+
+	// The size survives a restart
+	reloaded, err := config.NewManager(configPath)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	window := reloaded.GetConfig().UI.Window
+	if window.Width != 800 || window.Height != 600 {
+		t.Fatalf("Expected 800x600 saved, got %dx%d", window.Width, window.Height)
+	}
+
+	ui, err = NewUI(testApp, &MockCoreApp{configMgr: reloaded}, PlatformLinux)
+	if err != nil {
+		t.Fatalf("NewUI failed: %v", err)
+	}
+	ui.mainWindow = testApp.NewWindow("Test")
+	ui.loadWindowState()
+	if size := ui.mainWindow.Canvas().Size(); size != fyne.NewSize(800, 600) {
+		t.Errorf("Expected the saved size to be restored, got %v", size)
+	}
+}
+
+func TestSavedWindowSize(t *testing.T) {
+	tests := []struct {
+		width, height int
+		want          fyne.Size
+	}{
+		{1280, 900, fyne.NewSize(1280, 900)},
+		{0, 0, defaultWindowSize},     // Never saved
+		{1280, 10, defaultWindowSize}, // Too small to use
+	}
+
+	for _, tt := range tests {
+		if got := savedWindowSize(tt.width, tt.height); got != tt.want {
+			t.Errorf("savedWindowSize(%d, %d) = %v, want %v", tt.width, tt.height, got, tt.want)
+		}
+	}
+}
+
 func TestUI_SetupKeyboardShortcuts(t *testing.T) {
 	// Create test app with test driver
 	testApp := test.NewApp()
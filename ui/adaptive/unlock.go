@@ -0,0 +1,150 @@
+package adaptive
+
+import (
+	"errors"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// ErrPasswordCancelled is returned when the password window is closed
+// without entering a password
+var ErrPasswordCancelled = errors.New("password entry cancelled")
+
+// PasswordWindow asks for the startup password in a window, for when Whisp
+// is not started from a terminal. The app's event loop must be running.
+type PasswordWindow struct {
+	app    fyne.App
+	window fyne.Window
+
+	mu     sync.Mutex
+	result chan string // Receives the password of the current prompt
+}
+
+// NewPasswordWindow creates a password window for app; nothing is shown
+// until Prompt is called
+func NewPasswordWindow(app fyne.App) *PasswordWindow {
+	return &PasswordWindow{app: app}
+}
+
+// Prompt shows the unlock form, or the setup form on first run, and waits
+// for the user. It has the signature of core.PasswordPrompt.
+func (p *PasswordWindow) Prompt(setup bool, attempt int) (string, error) {
+	result := make(chan string, 1)
+	p.mu.Lock()
+	p.result = result
+	p.mu.Unlock()
+
+	if p.window == nil {
+		p.window = p.app.NewWindow("Whisp")
+		p.window.SetCloseIntercept(p.cancel)
+	}
+	form := newPasswordForm(setup, attempt, p.finish)
+	p.window.SetContent(container.NewPadded(form.box))
+	p.window.Resize(fyne.NewSize(360, form.box.MinSize().Height+40))
+	p.window.CenterOnScreen()
+	p.window.Show()
+	p.window.Canvas().Focus(form.password)
+
+	password, ok := <-result
+	// Hidden rather than closed: closing the only window would quit the app
+	p.window.Hide()
+	if !ok {
+		return "", ErrPasswordCancelled
+	}
+	return password, nil
+}
+
+// Close removes the window once startup no longer needs it. Call it after
+// the main window is shown, or the app quits with its last window.
+func (p *PasswordWindow) Close() {
+	if p.window != nil {
+		p.window.SetCloseIntercept(nil)
+		p.window.Close()
+		p.window = nil
+	}
+}
+
+// finish hands the entered password to the waiting prompt
+func (p *PasswordWindow) finish(password string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.result != nil {
+		p.result <- password
+		p.result = nil
+	}
+}
+
+// cancel ends the waiting prompt without a password
+func (p *PasswordWindow) cancel() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.result != nil {
+		close(p.result)
+		p.result = nil
+	}
+}
+
+// passwordForm is the content of the password window
+type passwordForm struct {
+	setup     bool
+	password  *widget.Entry
+	repeat    *widget.Entry // Only used when setting up a password
+	status    *widget.Label
+	submitBtn *widget.Button
+	onDone    func(password string)
+	box       *fyne.Container
+}
+
+// newPasswordForm builds the unlock or setup form. onDone receives the
+// password once it is entered, and confirmed when setting one up.
+func newPasswordForm(setup bool, attempt int, onDone func(password string)) *passwordForm {
+	f := &passwordForm{
+		setup:    setup,
+		password: widget.NewPasswordEntry(),
+		repeat:   widget.NewPasswordEntry(),
+		status:   widget.NewLabel(""),
+		onDone:   onDone,
+	}
+	f.status.Alignment = fyne.TextAlignCenter
+	f.status.Wrapping = fyne.TextWrapWord
+	f.password.OnSubmitted = func(string) { f.submit() }
+	f.repeat.OnSubmitted = func(string) { f.submit() }
+
+	if setup {
+		title := widget.NewLabelWithStyle(i18n.T("unlock.setup_title"), fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+		f.password.SetPlaceHolder(i18n.T("password.new"))
+		f.repeat.SetPlaceHolder(i18n.T("profile.repeat_password"))
+		f.submitBtn = widget.NewButton(i18n.T("unlock.set_password"), f.submit)
+		info := widget.NewLabel(i18n.T("unlock.setup_info"))
+		info.Wrapping = fyne.TextWrapWord
+		f.box = container.NewVBox(title, info, f.password, f.repeat, f.submitBtn, f.status)
+	} else {
+		title := widget.NewLabelWithStyle(i18n.T("lock.locked"), fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+		f.password.SetPlaceHolder(i18n.T("common.password"))
+		f.submitBtn = widget.NewButton(i18n.T("lock.unlock"), f.submit)
+		if attempt > 1 {
+			f.status.SetText(i18n.T("lock.wrong_password"))
+		}
+		f.box = container.NewVBox(title, f.password, f.submitBtn, f.status)
+	}
+	f.submitBtn.Importance = widget.HighImportance
+	return f
+}
+
+// submit checks the entered password and passes it on
+func (f *passwordForm) submit() {
+	switch {
+	case f.password.Text == "":
+		f.status.SetText(i18n.T("unlock.empty"))
+	case f.setup && f.password.Text != f.repeat.Text:
+		f.status.SetText(i18n.T("password.mismatch"))
+		f.repeat.SetText("")
+	default:
+		f.onDone(f.password.Text)
+	}
+}
@@ -0,0 +1,111 @@
+package adaptive
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+func TestPasswordFormSubmit(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      bool
+		password   string
+		repeat     string
+		wantDone   bool
+		wantStatus string
+	}{
+		{"unlock", false, "secret", "", true, ""},
+		{"unlock without password", false, "", "", false, "unlock.empty"},
+		{"setup", true, "secret", "secret", true, ""},
+		{"setup mismatch", true, "secret", "typo", false, "password.mismatch"},
+		{"setup without password", true, "", "", false, "unlock.empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			done := false
+			form := newPasswordForm(tt.setup, 1, func(password string) {
+				got, done = password, true
+			})
+			form.password.SetText(tt.password)
+			form.repeat.SetText(tt.repeat)
+			test.Tap(form.submitBtn)
+
+			if done != tt.wantDone || (done && got != tt.password) {
+				t.Errorf("Expected done=%v with %q, got done=%v with %q", tt.wantDone, tt.password, done, got)
+			}
+			if tt.wantStatus != "" && form.status.Text != i18n.T(tt.wantStatus) {
+				t.Errorf("Expected status %q, got %q", i18n.T(tt.wantStatus), form.status.Text)
+			}
+		})
+	}
+
+	if form := newPasswordForm(false, 2, func(string) {}); form.status.Text != i18n.T("lock.wrong_password") {
+		t.Errorf("Expected a retry to say the password was wrong, got %q", form.status.Text)
+	}
+}
+
+// waitForPrompt waits until the password window waits for input
+func waitForPrompt(t *testing.T, p *PasswordWindow) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		p.mu.Lock()
+		waiting := p.result != nil
+		p.mu.Unlock()
+		if waiting {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the password window to prompt")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPasswordWindowPrompt(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	p := NewPasswordWindow(app)
+
+	tests := []struct {
+		name         string
+		answer       func()
+		wantPassword string
+		wantErr      error
+	}{
+		{"entered", func() { p.finish("secret") }, "secret", nil},
+		{"window closed", p.cancel, "", ErrPasswordCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			type result struct {
+				password string
+				err      error
+			}
+			results := make(chan result, 1)
+			go func() {
+				password, err := p.Prompt(false, 1)
+				results <- result{password, err}
+			}()
+
+			waitForPrompt(t, p)
+			tt.answer()
+
+			select {
+			case got := <-results:
+				if got.password != tt.wantPassword || !errors.Is(got.err, tt.wantErr) {
+					t.Errorf("Expected %q (%v), got %q (%v)", tt.wantPassword, tt.wantErr, got.password, got.err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Expected the prompt to return")
+			}
+		})
+	}
+	p.Close()
+}
@@ -0,0 +1,116 @@
+// Package i18n translates the strings shown in the user interface.
+//
+// Strings are looked up by key in the bundle of the current language, then
+// in the English bundle, and finally the key itself is shown so a missing
+// translation never leaves a blank label.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is used until another language is set and for keys a
+// bundle does not translate
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Bundle maps translation keys to the text shown for them
+type Bundle map[string]string
+
+var (
+	mu       sync.RWMutex
+	language = DefaultLanguage
+	current  Bundle // Bundle of the current language
+	fallback Bundle // English bundle
+	loadErr  error  // Error loading the English bundle, reported by SetLanguage
+)
+
+func init() {
+	fallback, loadErr = LoadBundle(DefaultLanguage)
+	current = fallback
+}
+
+// LoadBundle reads the bundle shipped for a language code such as "es"
+func LoadBundle(lang string) (Bundle, error) {
+	data, err := locales.ReadFile(path.Join("locales", lang+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported language: %s", lang)
+	}
+
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse %s bundle: %w", lang, err)
+	}
+	return bundle, nil
+}
+
+// Languages returns the codes of the languages with a bundle, sorted
+func Languages() []string {
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		return []string{DefaultLanguage}
+	}
+
+	langs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		langs = append(langs, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// SetLanguage switches the strings returned by T to a language. On error the
+// current language is kept.
+func SetLanguage(lang string) error {
+	if loadErr != nil {
+		return loadErr
+	}
+
+	bundle := fallback
+	if lang != DefaultLanguage {
+		var err error
+		if bundle, err = LoadBundle(lang); err != nil {
+			return err
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	language, current = lang, bundle
+	return nil
+}
+
+// Language returns the code of the current language
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T returns the text for a key in the current language, falling back to
+// English and then to the key itself
+func T(key string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if text, ok := current[key]; ok {
+		return text
+	}
+	if text, ok := fallback[key]; ok {
+		return text
+	}
+	return key
+}
+
+// Tf formats the text for a key with fmt.Sprintf
+func Tf(key string, args ...interface{}) string {
+	return fmt.Sprintf(T(key), args...)
+}
@@ -0,0 +1,114 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+)
+
+// useLanguage switches language for a test and restores English afterwards
+func useLanguage(t *testing.T, lang string) {
+	t.Helper()
+	if err := SetLanguage(lang); err != nil {
+		t.Fatalf("Failed to set language %s: %v", lang, err)
+	}
+	t.Cleanup(func() { SetLanguage(DefaultLanguage) })
+}
+
+func TestT(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		key  string
+		want string
+	}{
+		{"english", "en", "menu.quit", "Quit"},
+		{"spanish", "es", "menu.quit", "Salir"},
+		{"unknown key shows the key", "es", "no.such.key", "no.such.key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useLanguage(t, tt.lang)
+			if got := T(tt.key); got != tt.want {
+				t.Errorf("T(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTFallsBackToEnglish(t *testing.T) {
+	useLanguage(t, "es")
+
+	// A bundle missing most keys, as a partial translation would be
+	mu.Lock()
+	current = Bundle{"menu.quit": "Salir"}
+	mu.Unlock()
+
+	if got := T("menu.quit"); got != "Salir" {
+		t.Errorf("Expected the translated text, got %q", got)
+	}
+	if got := T("menu.settings"); got != "Settings" {
+		t.Errorf("Expected English for a missing key, got %q", got)
+	}
+	if got := Tf("tray.show_unread", 3); got != "Show Whisp (3 unread)" {
+		t.Errorf("Expected formatted English for a missing key, got %q", got)
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	useLanguage(t, "es")
+
+	if err := SetLanguage("xx"); err == nil {
+		t.Error("Expected error for a language without a bundle")
+	}
+	if Language() != "es" {
+		t.Errorf("Failed switch should keep the language, got %s", Language())
+	}
+	if T("menu.quit") != "Salir" {
+		t.Error("Failed switch should keep the strings")
+	}
+
+	if err := SetLanguage(DefaultLanguage); err != nil {
+		t.Fatalf("Failed to switch back to English: %v", err)
+	}
+	if T("menu.quit") != "Quit" {
+		t.Errorf("Expected English strings, got %q", T("menu.quit"))
+	}
+}
+
+// formatVerbs matches the fmt verbs a translation must keep
+var formatVerbs = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestBundlesMatchEnglish(t *testing.T) {
+	en, err := LoadBundle(DefaultLanguage)
+	if err != nil {
+		t.Fatalf("Failed to load English bundle: %v", err)
+	}
+
+	for _, lang := range Languages() {
+		bundle, err := LoadBundle(lang)
+		if err != nil {
+			t.Errorf("Failed to load %s bundle: %v", lang, err)
+			continue
+		}
+		for key, text := range bundle {
+			english, ok := en[key]
+			if !ok {
+				t.Errorf("%s: key %q is not in the English bundle", lang, key)
+				continue
+			}
+			want := formatVerbs.FindAllString(english, -1)
+			got := formatVerbs.FindAllString(text, -1)
+			if len(got) != len(want) {
+				t.Errorf("%s: %q has verbs %v, English has %v", lang, key, got, want)
+				continue
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("%s: %q has verbs %v, English has %v", lang, key, got, want)
+					break
+				}
+			}
+		}
+	}
+}
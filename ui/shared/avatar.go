@@ -0,0 +1,199 @@
+package shared
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash/fnv"
+	"image"
+	"image/color"
+	_ "image/jpeg" // Avatars may be JPEG when a PNG would be too large
+	_ "image/png"
+	"strings"
+	"sync"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+)
+
+// Avatar sizes in the contact list and the chat header
+const (
+	contactAvatarSize = 28
+	headerAvatarSize  = 36
+)
+
+// maxCachedAvatars bounds the decoded avatars kept in memory
+const maxCachedAvatars = 256
+
+// avatarColors are the backgrounds of generated avatars
+var avatarColors = []color.NRGBA{
+	{R: 0x3b, G: 0x82, B: 0xf6, A: 0xff},
+	{R: 0x10, G: 0xb9, B: 0x81, A: 0xff},
+	{R: 0xf5, G: 0x9e, B: 0x0b, A: 0xff},
+	{R: 0xef, G: 0x44, B: 0x44, A: 0xff},
+	{R: 0x8b, G: 0x5c, B: 0xf6, A: 0xff},
+	{R: 0xec, G: 0x48, B: 0x99, A: 0xff},
+	{R: 0x06, G: 0xb6, B: 0xd4, A: 0xff},
+	{R: 0x64, G: 0x74, B: 0x8b, A: 0xff},
+}
+
+// avatarImages keeps decoded avatars by the hash of their bytes, so list
+// rows redrawn while scrolling do not decode them again
+var (
+	avatarImagesMu sync.Mutex
+	avatarImages   = make(map[[32]byte]image.Image)
+)
+
+// decodeAvatar returns the decoded avatar, or nil if it is empty or not an image
+func decodeAvatar(data []byte) image.Image {
+	if len(data) == 0 {
+		return nil
+	}
+	key := sha256.Sum256(data)
+
+	avatarImagesMu.Lock()
+	defer avatarImagesMu.Unlock()
+
+	if img, ok := avatarImages[key]; ok {
+		return img
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		img = nil // Remembered too, so a bad avatar is not decoded again
+	}
+	if len(avatarImages) >= maxCachedAvatars {
+		avatarImages = make(map[[32]byte]image.Image)
+	}
+	avatarImages[key] = img
+	return img
+}
+
+// avatarInitials returns up to two initials of a name, or "?" without one
+func avatarInitials(name string) string {
+	var initials []rune
+	for _, word := range strings.Fields(name) {
+		for _, r := range word {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				initials = append(initials, unicode.ToUpper(r))
+				break
+			}
+		}
+		if len(initials) == 2 {
+			break
+		}
+	}
+	if len(initials) == 0 {
+		return "?"
+	}
+	return string(initials)
+}
+
+// avatarColor picks the background of a generated avatar, the same for a name every time
+func avatarColor(name string) color.Color {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return avatarColors[h.Sum32()%uint32(len(avatarColors))]
+}
+
+// avatarWidget shows a contact's avatar, or their initials on a colored
+// circle if they have none
+type avatarWidget struct {
+	widget.BaseWidget
+	size     float32
+	circle   *canvas.Circle
+	initials *canvas.Text
+	image    *canvas.Image
+}
+
+// newAvatarWidget creates an avatar of the given width and height
+func newAvatarWidget(size float32) *avatarWidget {
+	a := &avatarWidget{size: size}
+	a.circle = canvas.NewCircle(avatarColor(""))
+	a.initials = canvas.NewText("?", color.White)
+	a.initials.TextStyle = fyne.TextStyle{Bold: true}
+	a.initials.TextSize = size * 0.4
+	a.image = canvas.NewImageFromImage(nil)
+	a.image.FillMode = canvas.ImageFillContain
+	a.image.Hide()
+	a.ExtendBaseWidget(a)
+	return a
+}
+
+// SetAvatar shows an avatar, falling back to the initials of name when it
+// is empty or cannot be decoded
+func (a *avatarWidget) SetAvatar(data []byte, name string) {
+	if img := decodeAvatar(data); img != nil {
+		a.image.Image = img
+		a.image.Show()
+		a.circle.Hide()
+		a.initials.Hide()
+	} else {
+		a.image.Image = nil
+		a.image.Hide()
+		a.circle.FillColor = avatarColor(name)
+		a.circle.Show()
+		a.initials.Text = avatarInitials(name)
+		a.initials.Show()
+	}
+	a.Refresh()
+}
+
+// showsImage reports whether an avatar image is shown instead of initials
+func (a *avatarWidget) showsImage() bool {
+	return a.image.Visible()
+}
+
+// MinSize keeps the avatar at its size
+func (a *avatarWidget) MinSize() fyne.Size {
+	return fyne.NewSize(a.size, a.size)
+}
+
+// CreateRenderer stacks the image over the generated fallback
+func (a *avatarWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewStack(a.circle, container.NewCenter(a.initials), a.image))
+}
+
+// avatarData returns the avatar shown for a contact, none in presentation
+// mode since it would give them away
+func (cl *ContactList) avatarData(c *contact.Contact) []byte {
+	if cl.presentation.Enabled() {
+		return nil
+	}
+	return c.Avatar
+}
+
+// UpdateAvatar redraws the contact list after a friend's avatar changed
+func (cl *ContactList) UpdateAvatar(friendID uint32) {
+	cl.list.Refresh()
+}
+
+// refreshHeader shows the avatar and name of the open conversation
+func (cv *ChatView) refreshHeader() {
+	if cv.header == nil {
+		return
+	}
+	if cv.currentFriend == 0 {
+		cv.header.Hide()
+		return
+	}
+
+	var avatar []byte
+	if cv.coreApp != nil && cv.coreApp.GetContacts() != nil && !cv.presentation.Enabled() {
+		avatar = cv.coreApp.GetContacts().GetAvatar(cv.currentFriend)
+	}
+	name := cv.friendName(cv.currentFriend)
+	cv.headerAvatar.SetAvatar(avatar, name)
+	cv.headerName.SetText(name)
+	cv.header.Show()
+}
+
+// UpdateAvatar redraws the header after a friend's avatar changed
+func (cv *ChatView) UpdateAvatar(friendID uint32) {
+	if friendID == cv.currentFriend {
+		cv.refreshHeader()
+	}
+}
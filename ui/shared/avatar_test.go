@@ -0,0 +1,95 @@
+package shared
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+)
+
+func TestAvatarInitials(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Alice", "A"},
+		{"alice smith", "AS"},
+		{"Mary Ann Jones", "MA"},
+		{"  (Bob)  ", "B"},
+		{"Émile Zola", "ÉZ"},
+		{"", "?"},
+		{"!!!", "?"},
+	}
+
+	for _, tt := range tests {
+		if got := avatarInitials(tt.name); got != tt.want {
+			t.Errorf("avatarInitials(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if avatarColor("Alice") != avatarColor("Alice") {
+		t.Error("Expected the same color for the same name")
+	}
+}
+
+func TestAvatarWidget(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantImage    bool
+		wantInitials string
+	}{
+		{"no avatar", nil, false, "AS"},
+		{"avatar", buf.Bytes(), true, ""},
+		{"not an image", []byte("garbage"), false, "AS"},
+	}
+
+	avatar := newAvatarWidget(contactAvatarSize)
+	test.WidgetRenderer(avatar)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			avatar.SetAvatar(tt.data, "Alice Smith")
+			if avatar.showsImage() != tt.wantImage {
+				t.Errorf("Expected image shown %v", tt.wantImage)
+			}
+			if !tt.wantImage && (!avatar.initials.Visible() || avatar.initials.Text != tt.wantInitials) {
+				t.Errorf("Expected initials %q, got %q", tt.wantInitials, avatar.initials.Text)
+			}
+		})
+	}
+
+	// Decoded avatars are cached by content
+	if decodeAvatar(buf.Bytes()) != decodeAvatar(append([]byte(nil), buf.Bytes()...)) {
+		t.Error("Expected the decoded avatar to be reused")
+	}
+}
+
+func TestContactAvatarHiddenInPresentationMode(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	cl := NewContactList(&MockCoreApp{})
+	c := &contact.Contact{FriendID: 1, Name: "Alice", Avatar: []byte("avatar")}
+	if got := cl.avatarData(c); !bytes.Equal(got, c.Avatar) {
+		t.Errorf("Expected the avatar, got %q", got)
+	}
+
+	presentation := NewPresentationMode()
+	cl.SetPresentationMode(presentation)
+	presentation.SetEnabled(true)
+	if got := cl.avatarData(c); got != nil {
+		t.Errorf("Expected no avatar in presentation mode, got %q", got)
+	}
+}
@@ -0,0 +1,182 @@
+package shared
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/storage"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// DatabaseBackup saves, restores and compacts the contacts and messages database
+type DatabaseBackup interface {
+	BackupDatabase(path string) error
+	RestoreDatabase(path string) error
+	LastBackupTime() time.Time
+	CompactDatabase() (*storage.CompactResult, error)
+}
+
+// backupIntervals are the schedules offered for automatic backups
+var backupIntervals = []string{"daily", "weekly"}
+
+// compactIntervals are the schedules offered for automatic compaction
+var compactIntervals = []string{"weekly", "monthly"}
+
+// SetDatabaseBackup enables the backup tab of the settings
+func (sd *SettingsDialog) SetDatabaseBackup(backup DatabaseBackup) {
+	sd.backup = backup
+}
+
+// createBackupTab creates the tab for backing up and restoring the database
+func (sd *SettingsDialog) createBackupTab() fyne.CanvasObject {
+	info := widget.NewLabel(i18n.T("backup.info"))
+	info.Wrapping = fyne.TextWrapWord
+
+	backupBtn := widget.NewButton(i18n.T("backup.backup"), sd.saveBackup)
+	restoreBtn := widget.NewButton(i18n.T("backup.restore"), sd.openBackup)
+
+	// Automatic backups, applied with the other settings
+	cfg := sd.configMgr.GetConfig()
+	enabledCheck := widget.NewCheck(i18n.T("backup.scheduled_check"), nil)
+	enabledCheck.SetChecked(cfg.Storage.BackupEnabled)
+
+	intervalSelect := widget.NewSelect(backupIntervals, nil)
+	intervalSelect.SetSelected(cfg.Storage.BackupInterval)
+
+	retentionEntry := widget.NewEntry()
+	retentionEntry.SetText(strconv.Itoa(cfg.Storage.BackupRetention))
+
+	dirEntry := widget.NewEntry()
+	dirEntry.SetPlaceHolder(i18n.T("backup.dir_default"))
+	dirEntry.SetText(cfg.Storage.BackupDir)
+
+	lastBackup := i18n.T("backup.never")
+	if last := sd.backup.LastBackupTime(); !last.IsZero() {
+		lastBackup = last.Format("2006-01-02 15:04")
+	}
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			widget.NewFormItem(i18n.T("backup.scheduled"), enabledCheck),
+			widget.NewFormItem(i18n.T("backup.interval"), intervalSelect),
+			widget.NewFormItem(i18n.T("backup.retention"), retentionEntry),
+			widget.NewFormItem(i18n.T("backup.dir"), dirEntry),
+			widget.NewFormItem(i18n.T("backup.last"), widget.NewLabel(lastBackup)),
+		},
+	}
+
+	sd.storeFormReferences("backup", map[string]interface{}{
+		"enabled":   enabledCheck,
+		"interval":  intervalSelect,
+		"retention": retentionEntry,
+		"dir":       dirEntry,
+	})
+
+	return container.NewScroll(container.NewVBox(info, backupBtn, restoreBtn, widget.NewSeparator(), form,
+		widget.NewSeparator(), sd.createCompactionSection()))
+}
+
+// createCompactionSection creates the controls for shrinking the database
+func (sd *SettingsDialog) createCompactionSection() fyne.CanvasObject {
+	cfg := sd.configMgr.GetConfig()
+
+	info := widget.NewLabel(i18n.T("compact.info"))
+	info.Wrapping = fyne.TextWrapWord
+
+	var optimizeBtn *widget.Button
+	optimizeBtn = widget.NewButton(i18n.T("compact.optimize"), func() {
+		optimizeBtn.Disable()
+		go func() {
+			defer optimizeBtn.Enable()
+			result, err := sd.backup.CompactDatabase()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to optimize storage: %w", err), sd.parentWindow)
+				return
+			}
+			dialog.ShowInformation(i18n.T("compact.title"),
+				fmt.Sprintf(i18n.T("compact.done"), formatBytes(result.BytesReclaimed), result.PurgedMessages), sd.parentWindow)
+		}()
+	})
+
+	enabledCheck := widget.NewCheck(i18n.T("compact.scheduled_check"), nil)
+	enabledCheck.SetChecked(cfg.Storage.CompactEnabled)
+
+	intervalSelect := widget.NewSelect(compactIntervals, nil)
+	intervalSelect.SetSelected(cfg.Storage.CompactInterval)
+
+	retentionEntry := widget.NewEntry()
+	retentionEntry.SetPlaceHolder(i18n.T("compact.keep_forever"))
+	retentionEntry.SetText(strconv.Itoa(cfg.Storage.DeletedRetentionDays))
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			widget.NewFormItem(i18n.T("compact.scheduled"), enabledCheck),
+			widget.NewFormItem(i18n.T("compact.interval"), intervalSelect),
+			widget.NewFormItem(i18n.T("compact.retention"), retentionEntry),
+		},
+	}
+
+	sd.storeFormReferences("compaction", map[string]interface{}{
+		"enabled":   enabledCheck,
+		"interval":  intervalSelect,
+		"retention": retentionEntry,
+	})
+
+	return container.NewVBox(info, optimizeBtn, form)
+}
+
+// saveBackup lets the user pick where to save a backup of the database
+func (sd *SettingsDialog) saveBackup() {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, sd.parentWindow)
+			return
+		}
+		if writer == nil {
+			return // Cancelled
+		}
+		path := writer.URI().Path()
+		writer.Close()
+
+		if err := sd.backup.BackupDatabase(path); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to back up database: %w", err), sd.parentWindow)
+			return
+		}
+		dialog.ShowInformation(i18n.T("backup.title"), i18n.T("backup.saved"), sd.parentWindow)
+	}, sd.parentWindow)
+	save.SetFileName(fmt.Sprintf("whisp-backup-%s.db", time.Now().Format("2006-01-02")))
+	save.Show()
+}
+
+// openBackup restores a backup picked by the user after confirmation
+func (sd *SettingsDialog) openBackup() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, sd.parentWindow)
+			return
+		}
+		if reader == nil {
+			return // Cancelled
+		}
+		path := reader.URI().Path()
+		reader.Close()
+
+		dialog.ShowConfirm(i18n.T("backup.restore_title"), i18n.T("backup.restore_warning"), func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := sd.backup.RestoreDatabase(path); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to restore database: %w", err), sd.parentWindow)
+				return
+			}
+			dialog.ShowInformation(i18n.T("backup.restore_title"), i18n.T("backup.restored"), sd.parentWindow)
+		}, sd.parentWindow)
+	}, sd.parentWindow)
+	open.Show()
+}
@@ -0,0 +1,81 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/tox"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// BootstrapNodeStore lists and saves the nodes used to join the Tox network
+type BootstrapNodeStore interface {
+	ListBootstrapNodes() []tox.BootstrapNode
+	SaveBootstrapNodes(nodes []tox.BootstrapNode) error
+}
+
+// SetBootstrapNodeStore enables the bootstrap node editor of the advanced
+// settings
+func (sd *SettingsDialog) SetBootstrapNodeStore(store BootstrapNodeStore) {
+	sd.bootstrap = store
+}
+
+// newBootstrapNodesEntry creates the editor for the bootstrap nodes, one
+// node per line
+func (sd *SettingsDialog) newBootstrapNodesEntry() *widget.Entry {
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder(i18n.T("settings.bootstrap_nodes_hint"))
+	entry.SetMinRowsVisible(4)
+	entry.SetText(formatBootstrapNodes(sd.bootstrap.ListBootstrapNodes()))
+	return entry
+}
+
+// applyBootstrapNodes saves the edited bootstrap nodes if they changed
+func (sd *SettingsDialog) applyBootstrapNodes(text string) error {
+	nodes, err := parseBootstrapNodes(text)
+	if err != nil {
+		return err
+	}
+	if formatBootstrapNodes(nodes) == formatBootstrapNodes(sd.bootstrap.ListBootstrapNodes()) {
+		return nil
+	}
+	return sd.bootstrap.SaveBootstrapNodes(nodes)
+}
+
+// formatBootstrapNodes writes nodes as "address port public_key" lines
+func formatBootstrapNodes(nodes []tox.BootstrapNode) string {
+	lines := make([]string, len(nodes))
+	for i, node := range nodes {
+		lines[i] = fmt.Sprintf("%s %d %s", node.Address, node.Port, node.PublicKey)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseBootstrapNodes reads "address port public_key" lines, skipping blank
+// ones. No nodes means the defaults.
+func parseBootstrapNodes(text string) ([]tox.BootstrapNode, error) {
+	var nodes []tox.BootstrapNode
+	for i, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, errors.New(i18n.Tf("settings.bootstrap_nodes_invalid", i+1, "expected address, port and public key"))
+		}
+		port, err := strconv.ParseUint(fields[1], 10, 16)
+		if err != nil {
+			return nil, errors.New(i18n.Tf("settings.bootstrap_nodes_invalid", i+1, fmt.Sprintf("invalid port %q", fields[1])))
+		}
+		node := tox.BootstrapNode{Address: fields[0], Port: uint16(port), PublicKey: fields[2]}
+		if err := node.Validate(); err != nil {
+			return nil, errors.New(i18n.Tf("settings.bootstrap_nodes_invalid", i+1, fmt.Sprintf("invalid port %q", fields[1])))
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
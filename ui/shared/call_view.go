@@ -0,0 +1,417 @@
+package shared
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/calls"
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// callRefreshInterval is how often the duration of open calls is updated
+const callRefreshInterval = time.Second
+
+// CallControls places and controls calls. *calls.Manager implements it.
+type CallControls interface {
+	PlaceCall(friendID uint32, callType calls.CallType) error
+	AnswerCall(friendID uint32) error
+	EndCall(friendID uint32) error
+	SetMuted(friendID uint32, muted bool) error
+}
+
+// CallView shows ongoing calls stacked above the chat, and asks the user to
+// answer or reject incoming ones
+type CallView struct {
+	coreApp  CoreApp
+	controls CallControls
+	window   fyne.Window
+
+	mu       sync.Mutex
+	panels   map[uint32]*callPanel           // friendID -> panel
+	incoming map[uint32]*dialog.CustomDialog // friendID -> answer dialog
+	stopTick chan struct{}                   // Closed to stop refreshing durations, nil when idle
+
+	container    *fyne.Container
+	presentation *PresentationMode // nil when presentation mode is not available
+}
+
+// callPanel shows one call with its controls
+type callPanel struct {
+	call    *calls.Call
+	name    string
+	status  *widget.Label
+	muteBtn *widget.Button
+	video   *canvas.Image
+	box     *fyne.Container
+}
+
+// NewCallView creates a call view that controls calls through controls
+func NewCallView(coreApp CoreApp, controls CallControls) *CallView {
+	cv := &CallView{
+		coreApp:   coreApp,
+		controls:  controls,
+		panels:    make(map[uint32]*callPanel),
+		incoming:  make(map[uint32]*dialog.CustomDialog),
+		container: container.NewVBox(),
+	}
+	cv.container.Hide()
+	return cv
+}
+
+// Container returns the view's container, hidden while there are no calls
+func (cv *CallView) Container() *fyne.Container {
+	return cv.container
+}
+
+// SetParentWindow sets the window incoming call dialogs are shown on
+func (cv *CallView) SetParentWindow(window fyne.Window) {
+	cv.window = window
+}
+
+// SetPresentationMode masks caller names while presentation mode is on
+func (cv *CallView) SetPresentationMode(p *PresentationMode) {
+	cv.presentation = p
+}
+
+// PlaceCall calls a friend, with video if requested
+func (cv *CallView) PlaceCall(friendID uint32, video bool) {
+	callType := calls.CallTypeAudio
+	if video {
+		callType = calls.CallTypeVideo
+	}
+	if err := cv.controls.PlaceCall(friendID, callType); err != nil {
+		cv.showError(err)
+	}
+}
+
+// HandleEvent updates the view for a call event
+func (cv *CallView) HandleEvent(event *calls.CallEvent) {
+	if event == nil || event.Call == nil {
+		return
+	}
+	call := event.Call
+
+	switch event.Type {
+	case calls.CallEventIncoming:
+		cv.addPanel(call)
+		cv.showIncoming(call)
+	case calls.CallEventOutgoing:
+		cv.addPanel(call)
+	case calls.CallEventStateChanged:
+		cv.dismissIncoming(call.FriendID)
+		cv.addPanel(call)
+	case calls.CallEventEnded:
+		cv.dismissIncoming(call.FriendID)
+		cv.removePanel(call.FriendID)
+	case calls.CallEventError:
+		log.Printf("Warning: Call with friend %d failed: %v", call.FriendID, event.Error)
+		if call.GetState() == calls.CallStateEnded {
+			cv.dismissIncoming(call.FriendID)
+			cv.removePanel(call.FriendID)
+		}
+	case calls.CallEventVideoFrame:
+		cv.showVideoFrame(call.FriendID, event.VideoFrame)
+	}
+}
+
+// addPanel adds a panel for a call, or refreshes the one already shown
+func (cv *CallView) addPanel(call *calls.Call) {
+	cv.mu.Lock()
+	panel, exists := cv.panels[call.FriendID]
+	if !exists {
+		panel = cv.newCallPanel(call)
+		cv.panels[call.FriendID] = panel
+		cv.container.Add(panel.box)
+		cv.startTickerLocked()
+	}
+	cv.mu.Unlock()
+
+	panel.refresh()
+	cv.container.Show()
+}
+
+// removePanel removes the panel of a finished call
+func (cv *CallView) removePanel(friendID uint32) {
+	cv.mu.Lock()
+	panel, exists := cv.panels[friendID]
+	if exists {
+		delete(cv.panels, friendID)
+		cv.container.Remove(panel.box)
+	}
+	empty := len(cv.panels) == 0
+	if empty && cv.stopTick != nil {
+		close(cv.stopTick)
+		cv.stopTick = nil
+	}
+	cv.mu.Unlock()
+
+	if empty {
+		cv.container.Hide()
+	}
+}
+
+// newCallPanel creates the panel for a call
+func (cv *CallView) newCallPanel(call *calls.Call) *callPanel {
+	friendID := call.FriendID
+	panel := &callPanel{
+		call:   call,
+		name:   cv.friendName(friendID),
+		status: widget.NewLabel(""),
+	}
+
+	panel.muteBtn = widget.NewButton(i18n.T("call.mute"), func() {
+		muted := !panel.call.IsMuted()
+		if err := cv.controls.SetMuted(friendID, muted); err != nil {
+			cv.showError(err)
+			return
+		}
+		panel.refresh()
+	})
+	hangupBtn := widget.NewButton(i18n.T("call.hang_up"), func() {
+		if err := cv.controls.EndCall(friendID); err != nil {
+			log.Printf("Warning: Failed to end call with friend %d: %v", friendID, err)
+			cv.removePanel(friendID) // Already gone on the manager's side
+		}
+	})
+	hangupBtn.Importance = widget.DangerImportance
+
+	nameLabel := widget.NewLabel(panel.name)
+	nameLabel.TextStyle = fyne.TextStyle{Bold: true}
+	nameLabel.Truncation = fyne.TextTruncateEllipsis
+
+	row := container.NewBorder(nil, nil, nameLabel, container.NewHBox(panel.muteBtn, hangupBtn), panel.status)
+	panel.box = container.NewVBox(row)
+
+	if call.Type == calls.CallTypeVideo {
+		panel.video = canvas.NewImageFromImage(nil)
+		panel.video.FillMode = canvas.ImageFillContain
+		panel.video.SetMinSize(fyne.NewSize(320, 240))
+		panel.box.Add(panel.video)
+	}
+	panel.box.Add(widget.NewSeparator())
+	return panel
+}
+
+// refresh updates the state and duration shown for the call
+func (p *callPanel) refresh() {
+	p.status.SetText(callStatusText(p.call.GetState(), p.call.Duration(), p.call.IsMuted()))
+	if p.call.IsMuted() {
+		p.muteBtn.SetText(i18n.T("call.unmute"))
+	} else {
+		p.muteBtn.SetText(i18n.T("call.mute"))
+	}
+	if p.call.GetState() == calls.CallStateActive {
+		p.muteBtn.Enable()
+	} else {
+		p.muteBtn.Disable()
+	}
+}
+
+// startTickerLocked refreshes call durations until no calls are left
+// (requires lock)
+func (cv *CallView) startTickerLocked() {
+	if cv.stopTick != nil {
+		return
+	}
+	stop := make(chan struct{})
+	cv.stopTick = stop
+
+	go func() {
+		ticker := time.NewTicker(callRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				cv.mu.Lock()
+				panels := make([]*callPanel, 0, len(cv.panels))
+				for _, panel := range cv.panels {
+					panels = append(panels, panel)
+				}
+				cv.mu.Unlock()
+				for _, panel := range panels {
+					panel.refresh()
+				}
+			}
+		}
+	}()
+}
+
+// showIncoming asks the user to answer or reject an incoming call
+func (cv *CallView) showIncoming(call *calls.Call) {
+	if cv.window == nil {
+		return
+	}
+	friendID := call.FriendID
+
+	cv.mu.Lock()
+	if _, shown := cv.incoming[friendID]; shown {
+		cv.mu.Unlock()
+		return
+	}
+	var answer *dialog.CustomDialog
+
+	prompt := "call.incoming_voice"
+	if call.Type == calls.CallTypeVideo {
+		prompt = "call.incoming_video"
+	}
+	text := widget.NewLabel(i18n.Tf(prompt, cv.friendName(friendID)))
+
+	answerBtn := widget.NewButton(i18n.T("call.answer"), func() {
+		answer.Hide()
+		if err := cv.controls.AnswerCall(friendID); err != nil {
+			cv.showError(err)
+		}
+	})
+	answerBtn.Importance = widget.HighImportance
+	rejectBtn := widget.NewButton(i18n.T("call.reject"), func() {
+		answer.Hide()
+		if err := cv.controls.EndCall(friendID); err != nil {
+			log.Printf("Warning: Failed to reject call from friend %d: %v", friendID, err)
+		}
+	})
+	rejectBtn.Importance = widget.DangerImportance
+
+	answer = dialog.NewCustomWithoutButtons(i18n.T("call.incoming_title"), text, cv.window)
+	answer.SetButtons([]fyne.CanvasObject{rejectBtn, answerBtn})
+	answer.SetOnClosed(func() {
+		cv.mu.Lock()
+		if cv.incoming[friendID] == answer {
+			delete(cv.incoming, friendID)
+		}
+		cv.mu.Unlock()
+	})
+	cv.incoming[friendID] = answer
+	cv.mu.Unlock()
+
+	answer.Show()
+}
+
+// dismissIncoming closes the answer dialog of a call that was answered or
+// ended elsewhere
+func (cv *CallView) dismissIncoming(friendID uint32) {
+	cv.mu.Lock()
+	answer := cv.incoming[friendID]
+	delete(cv.incoming, friendID)
+	cv.mu.Unlock()
+
+	if answer != nil {
+		answer.Hide()
+	}
+}
+
+// showVideoFrame shows a received video frame in the call's panel
+func (cv *CallView) showVideoFrame(friendID uint32, frame *calls.VideoFrame) {
+	cv.mu.Lock()
+	panel := cv.panels[friendID]
+	cv.mu.Unlock()
+	if panel == nil || panel.video == nil {
+		return
+	}
+
+	img := videoFrameImage(frame)
+	if img == nil {
+		return
+	}
+	panel.video.Image = img
+	panel.video.Refresh()
+}
+
+// videoFrameImage copies the planes of a YUV 4:2:0 frame into an image, or
+// returns nil if the planes are too short for the frame size
+func videoFrameImage(frame *calls.VideoFrame) *image.YCbCr {
+	if frame == nil || frame.Width == 0 || frame.Height == 0 {
+		return nil
+	}
+	width, height := int(frame.Width), int(frame.Height)
+	chromaWidth, chromaHeight := (width+1)/2, (height+1)/2
+
+	planeFits := func(plane []byte, stride, w, h int) bool {
+		return stride >= w && len(plane) >= stride*(h-1)+w
+	}
+	if !planeFits(frame.YPlane, frame.YStride, width, height) ||
+		!planeFits(frame.UPlane, frame.UStride, chromaWidth, chromaHeight) ||
+		!planeFits(frame.VPlane, frame.VStride, chromaWidth, chromaHeight) {
+		return nil
+	}
+
+	img := image.NewYCbCr(image.Rect(0, 0, width, height), image.YCbCrSubsampleRatio420)
+	for y := 0; y < height; y++ {
+		copy(img.Y[y*img.YStride:], frame.YPlane[y*frame.YStride:y*frame.YStride+width])
+	}
+	for y := 0; y < chromaHeight; y++ {
+		copy(img.Cb[y*img.CStride:], frame.UPlane[y*frame.UStride:y*frame.UStride+chromaWidth])
+		copy(img.Cr[y*img.CStride:], frame.VPlane[y*frame.VStride:y*frame.VStride+chromaWidth])
+	}
+	return img
+}
+
+// callStatusText describes the state of a call, with its duration once active
+func callStatusText(state calls.CallState, duration time.Duration, muted bool) string {
+	var text string
+	switch state {
+	case calls.CallStateIncoming:
+		text = i18n.T("call.incoming")
+	case calls.CallStateOutgoing:
+		text = i18n.T("call.calling")
+	case calls.CallStateActive:
+		text = formatCallDuration(duration)
+	case calls.CallStateHolding:
+		text = i18n.Tf("call.on_hold", formatCallDuration(duration))
+	case calls.CallStateEnding, calls.CallStateEnded:
+		text = i18n.T("call.ended")
+	default:
+		text = state.String()
+	}
+	if muted && state == calls.CallStateActive {
+		text += i18n.T("call.muted_suffix")
+	}
+	return text
+}
+
+// formatCallDuration formats a call duration as m:ss, or h:mm:ss for calls of
+// an hour or more
+func formatCallDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	total := int(d / time.Second)
+	hours, minutes, seconds := total/3600, total/60%60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
+
+// friendName returns the name shown for a caller
+func (cv *CallView) friendName(friendID uint32) string {
+	name := i18n.Tf("contacts.fallback_name", friendID)
+	if cv.coreApp != nil && cv.coreApp.GetContacts() != nil {
+		if value, ok := cv.coreApp.GetContacts().GetContact(friendID); ok {
+			if c, ok := value.(*contact.Contact); ok && c.DisplayName() != "" {
+				name = c.DisplayName()
+			}
+		}
+	}
+	return cv.presentation.DisplayName(friendID, name)
+}
+
+// showError shows a call error, or logs it without a window
+func (cv *CallView) showError(err error) {
+	if cv.window == nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	dialog.ShowError(err, cv.window)
+}
@@ -0,0 +1,162 @@
+package shared
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/opd-ai/whisp/internal/core/calls"
+)
+
+// fakeCallControls records the calls the view makes
+type fakeCallControls struct {
+	placed   []calls.CallType
+	answered []uint32
+	ended    []uint32
+	muted    map[uint32]bool
+}
+
+func (f *fakeCallControls) PlaceCall(friendID uint32, callType calls.CallType) error {
+	f.placed = append(f.placed, callType)
+	return nil
+}
+
+func (f *fakeCallControls) AnswerCall(friendID uint32) error {
+	f.answered = append(f.answered, friendID)
+	return nil
+}
+
+func (f *fakeCallControls) EndCall(friendID uint32) error {
+	f.ended = append(f.ended, friendID)
+	return nil
+}
+
+func (f *fakeCallControls) SetMuted(friendID uint32, muted bool) error {
+	if f.muted == nil {
+		f.muted = make(map[uint32]bool)
+	}
+	f.muted[friendID] = muted
+	return nil
+}
+
+func TestCallViewStacksCalls(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	controls := &fakeCallControls{}
+	cv := NewCallView(&MockCoreApp{}, controls)
+	cv.SetParentWindow(app.NewWindow("Test"))
+	if cv.Container().Visible() {
+		t.Fatal("Expected the call view hidden without calls")
+	}
+
+	incoming := calls.NewCall(1, calls.CallTypeAudio, false)
+	incoming.SetState(calls.CallStateIncoming)
+	cv.HandleEvent(calls.NewCallEvent(calls.CallEventIncoming, incoming, ""))
+	if len(cv.incoming) != 1 {
+		t.Errorf("Expected an answer dialog for the incoming call, got %d", len(cv.incoming))
+	}
+
+	outgoing := calls.NewCall(2, calls.CallTypeVideo, true)
+	cv.HandleEvent(calls.NewCallEvent(calls.CallEventOutgoing, outgoing, ""))
+	if len(cv.panels) != 2 || !cv.Container().Visible() {
+		t.Fatalf("Expected both calls stacked, got %d", len(cv.panels))
+	}
+	if cv.panels[2].video == nil || cv.panels[1].video != nil {
+		t.Error("Expected a video image only for the video call")
+	}
+
+	// Answering elsewhere closes the dialog
+	incoming.SetState(calls.CallStateActive)
+	cv.HandleEvent(calls.NewCallEvent(calls.CallEventStateChanged, incoming, ""))
+	if len(cv.incoming) != 0 {
+		t.Error("Expected the answer dialog closed once the call is active")
+	}
+	if cv.panels[1].muteBtn.Disabled() {
+		t.Error("Expected mute available in an active call")
+	}
+
+	incoming.SetState(calls.CallStateEnded)
+	cv.HandleEvent(calls.NewCallEvent(calls.CallEventEnded, incoming, ""))
+	outgoing.SetState(calls.CallStateEnded)
+	cv.HandleEvent(calls.NewCallEvent(calls.CallEventEnded, outgoing, ""))
+	if len(cv.panels) != 0 || cv.Container().Visible() {
+		t.Error("Expected the call view hidden after the calls ended")
+	}
+}
+
+func TestCallViewPlaceCall(t *testing.T) {
+	controls := &fakeCallControls{}
+	cv := NewCallView(&MockCoreApp{}, controls)
+
+	cv.PlaceCall(1, false)
+	cv.PlaceCall(1, true)
+	if len(controls.placed) != 2 || controls.placed[0] != calls.CallTypeAudio || controls.placed[1] != calls.CallTypeVideo {
+		t.Errorf("Placed %v, want an audio then a video call", controls.placed)
+	}
+}
+
+func TestCallStatusText(t *testing.T) {
+	tests := []struct {
+		state    calls.CallState
+		duration time.Duration
+		muted    bool
+		want     string
+	}{
+		{state: calls.CallStateIncoming, want: "Incoming call…"},
+		{state: calls.CallStateOutgoing, muted: true, want: "Calling…"},
+		{state: calls.CallStateActive, duration: 75 * time.Second, want: "1:15"},
+		{state: calls.CallStateActive, duration: 5 * time.Second, muted: true, want: "0:05 · Muted"},
+		{state: calls.CallStateActive, duration: time.Hour + 2*time.Minute + 3*time.Second, want: "1:02:03"},
+		{state: calls.CallStateEnded, want: "Call ended"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := callStatusText(tt.state, tt.duration, tt.muted); got != tt.want {
+				t.Errorf("callStatusText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVideoFrameImage(t *testing.T) {
+	// A 3x3 frame with padded rows; chroma planes are 2x2
+	frame := &calls.VideoFrame{
+		Width: 3, Height: 3,
+		YPlane:  []byte{1, 2, 3, 0, 4, 5, 6, 0, 7, 8, 9},
+		UPlane:  []byte{10, 11, 12, 13},
+		VPlane:  []byte{20, 21, 22, 23},
+		YStride: 4, UStride: 2, VStride: 2,
+	}
+
+	img := videoFrameImage(frame)
+	if img == nil {
+		t.Fatal("Expected an image")
+	}
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			if got, want := img.Y[img.YOffset(x, y)], byte(y*3+x+1); got != want {
+				t.Errorf("Y(%d,%d) = %d, want %d", x, y, got, want)
+			}
+		}
+	}
+	if got := img.Cb[img.COffset(2, 2)]; got != 13 {
+		t.Errorf("Cb(2,2) = %d, want 13", got)
+	}
+	if got := img.Cr[img.COffset(0, 2)]; got != 22 {
+		t.Errorf("Cr(0,2) = %d, want 22", got)
+	}
+
+	short := *frame
+	short.VPlane = short.VPlane[:3]
+	for name, f := range map[string]*calls.VideoFrame{"nil": nil, "short plane": &short, "empty": {}} {
+		t.Run(fmt.Sprint(name), func(t *testing.T) {
+			if videoFrameImage(f) != nil {
+				t.Error("Expected no image for an invalid frame")
+			}
+		})
+	}
+}
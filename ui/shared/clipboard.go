@@ -0,0 +1,65 @@
+package shared
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// ClipboardGuard copies sensitive values (Tox IDs, message text) to the
+// clipboard and clears them again after a delay
+type ClipboardGuard struct {
+	mu         sync.Mutex
+	clipboard  fyne.Clipboard
+	copied     string // Sensitive value we last placed on the clipboard
+	generation uint64 // Invalidates clear timers from earlier copies
+}
+
+// NewClipboardGuard creates a guard for the given clipboard
+func NewClipboardGuard(clipboard fyne.Clipboard) *ClipboardGuard {
+	return &ClipboardGuard{clipboard: clipboard}
+}
+
+// CopySensitive places text on the clipboard and schedules it to be cleared
+// after delay. A delay of zero or less leaves the clipboard alone.
+func (g *ClipboardGuard) CopySensitive(text string, delay time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.clipboard.SetContent(text)
+	g.generation++
+
+	if delay <= 0 {
+		g.copied = ""
+		return
+	}
+
+	g.copied = text
+	generation := g.generation
+	time.AfterFunc(delay, func() {
+		g.clearIfUnchanged(generation)
+	})
+}
+
+// clearIfUnchanged empties the clipboard if it still holds our sensitive value
+func (g *ClipboardGuard) clearIfUnchanged(generation uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// A later copy owns the clipboard now and has its own timer
+	if generation != g.generation {
+		return
+	}
+
+	if shouldClearClipboard(g.clipboard.Content(), g.copied) {
+		g.clipboard.SetContent("")
+	}
+	g.copied = ""
+}
+
+// shouldClearClipboard reports whether the clipboard still contains the value
+// we copied, so something the user copied afterwards is never clobbered
+func shouldClearClipboard(current, copied string) bool {
+	return copied != "" && current == copied
+}
@@ -0,0 +1,94 @@
+package shared
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClipboard is a goroutine-safe clipboard for exercising clear timers
+type fakeClipboard struct {
+	mu      sync.Mutex
+	content string
+}
+
+func (c *fakeClipboard) Content() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.content
+}
+
+func (c *fakeClipboard) SetContent(content string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.content = content
+}
+
+func TestShouldClearClipboard(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		copied   string
+		expected bool
+	}{
+		{"still our value", "toxid", "toxid", true},
+		{"user copied something else", "shopping list", "toxid", false},
+		{"clipboard already empty", "", "toxid", false},
+		{"nothing sensitive copied", "", "", false},
+		{"nothing sensitive copied with content", "notes", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldClearClipboard(tt.current, tt.copied); got != tt.expected {
+				t.Errorf("shouldClearClipboard(%q, %q) = %v, expected %v", tt.current, tt.copied, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClipboardGuard(t *testing.T) {
+	clipboard := &fakeClipboard{}
+	guard := NewClipboardGuard(clipboard)
+
+	t.Run("clears unchanged value", func(t *testing.T) {
+		guard.CopySensitive("toxid", 20*time.Millisecond)
+		if clipboard.Content() != "toxid" {
+			t.Fatalf("Expected clipboard to hold copied value, got %q", clipboard.Content())
+		}
+
+		time.Sleep(60 * time.Millisecond)
+		if clipboard.Content() != "" {
+			t.Errorf("Expected clipboard to be cleared, got %q", clipboard.Content())
+		}
+	})
+
+	t.Run("keeps later user copy", func(t *testing.T) {
+		guard.CopySensitive("toxid", 20*time.Millisecond)
+		clipboard.SetContent("copied by user")
+
+		time.Sleep(60 * time.Millisecond)
+		if clipboard.Content() != "copied by user" {
+			t.Errorf("Expected user content to survive, got %q", clipboard.Content())
+		}
+	})
+
+	t.Run("later sensitive copy restarts timer", func(t *testing.T) {
+		guard.CopySensitive("first", 20*time.Millisecond)
+		guard.CopySensitive("second", time.Hour)
+
+		time.Sleep(60 * time.Millisecond)
+		if clipboard.Content() != "second" {
+			t.Errorf("Expected second copy to remain, got %q", clipboard.Content())
+		}
+	})
+
+	t.Run("zero delay never clears", func(t *testing.T) {
+		guard.CopySensitive("toxid", 0)
+
+		time.Sleep(20 * time.Millisecond)
+		if clipboard.Content() != "toxid" {
+			t.Errorf("Expected clipboard to be left alone, got %q", clipboard.Content())
+		}
+	})
+}
@@ -3,15 +3,29 @@ package shared
 import (
 	"fmt"
 	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/opd-ai/whisp/internal/core/config"
 	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/group"
+	"github.com/opd-ai/whisp/internal/core/linkpreview"
 	"github.com/opd-ai/whisp/internal/core/media"
 	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/internal/core/tox"
+	"github.com/opd-ai/whisp/ui/i18n"
 )
 
 // CoreApp interface for core application access
@@ -21,30 +35,87 @@ type CoreApp interface {
 	GetToxID() string
 	GetMessages() *message.Manager
 	GetContacts() *contact.Manager
+	GetGroups() *group.Manager
 	GetConfigManager() *config.Manager
 
+	// Own presence shown above the contact list
+	GetSelfStatus() contact.Status
+	SetSelfStatus(status contact.Status)
+	IsAutoAway() bool
+	GetConnectionState() tox.ConnectionState
+	GetSelfAvatar() []byte
+	GetProfile() (name, statusMessage string)
+
 	// Media-related methods
 	GetMediaInfoFromUI(filePath string) (*media.MediaInfo, error)
 	GenerateThumbnailFromUI(filePath string, maxWidth, maxHeight int) (string, error)
 	IsMediaFileFromUI(filePath string) bool
 	GetThumbnailPathFromUI(filePath string, maxWidth, maxHeight int) (string, bool)
+
+	// Previews of web links in messages
+	LinkPreviewMode(friendID uint32, outgoing bool) linkpreview.Mode
+	FetchLinkPreview(rawURL string) (*linkpreview.Preview, error)
+	CachedLinkPreview(rawURL string) (preview *linkpreview.Preview, done bool)
 }
 
 // ChatView represents the chat interface
 type ChatView struct {
 	container     *fyne.Container
 	messages      *widget.List
-	input         *widget.Entry
+	input         *pasteEntry
 	sendBtn       *widget.Button
 	coreApp       CoreApp
 	currentFriend uint32
 	messageData   []*message.Message
+
+	historyMu      sync.Mutex
+	history        *MessageWindow // nil until a conversation is loaded
+	loadingHistory bool
+
+	drafts *message.DraftAutosaver
+	unsent map[uint32]string // Drafts of conversations that are not open, by friend
+
+	typing      *message.TypingNotifier
+	typingLabel *widget.Label // "Friend is typing…", shown below the messages
+
+	replyTo    *message.Message // Message the next send replies to, if any
+	replyBar   *fyne.Container
+	replyLabel *widget.Label
+
+	spellMu      sync.Mutex
+	spellChecker SpellChecker
+	spellHint    *widget.Label
+	spellTimer   *time.Timer
+
+	translateMu  sync.Mutex
+	translations *TranslationCache // nil until a translator is set
+
+	onActivity func()       // Called when the user types or sends
+	onSearch   func(uint32) // Called to search the current conversation
+	callBtn    *widget.Button
+	onCall     func(uint32) // Called to call the current friend
+	detailsBtn *widget.Button
+	onDetails  func(uint32) // Called to show the current friend's details
+	onToxLink  func(string) // Called with the Tox ID of a tapped tox: link
+
+	presentation *PresentationMode // nil when presentation mode is not available
+
+	header       *fyne.Container // Avatar and name of the open conversation
+	headerAvatar *avatarWidget
+	headerName   *widget.Label
+
+	fileSender   FileSender  // nil until files can be sent from the chat
+	parentWindow fyne.Window // Shows dialogs about dropped files
+	transferMu   sync.Mutex
+	transferRows map[uint32][]fyne.CanvasObject // Progress of files being sent, by friend
+	transfers    *fyne.Container                // Rows of the open conversation
 }
 
 // NewChatView creates a new chat view
 func NewChatView(coreApp CoreApp) *ChatView {
 	cv := &ChatView{
-		coreApp: coreApp,
+		coreApp:      coreApp,
+		spellChecker: NoopSpellChecker{},
 	}
 	cv.initializeComponents()
 	return cv
@@ -68,47 +139,113 @@ func (cv *ChatView) initializeComponents() {
 				container.Objects = nil
 
 				// Create message content based on type
-				cv.createMessageContent(container, msg)
+				var prev *message.Message
+				if i > 0 {
+					prev = cv.messageData[i-1]
+				}
+				cv.createMessageContent(container, msg, prev)
 
 				container.Refresh()
+				// Rows differ in height, e.g. with a date separator or a preview
+				cv.messages.SetItemHeight(i, container.MinSize().Height)
+				cv.maybeLoadMoreHistory(i)
 			}
 		},
 	)
 
 	// Input field
-	cv.input = widget.NewEntry()
-	cv.input.SetPlaceHolder("Type a message...")
+	cv.input = newPasteEntry(cv.pasteImage)
+	cv.input.SetPlaceHolder(i18n.T("chat.type_message"))
 	cv.input.Wrapping = fyne.TextWrapWord
 	cv.input.OnSubmitted = func(text string) {
 		cv.sendMessage()
 	}
+	cv.input.OnChanged = cv.onInputChanged
+
+	// Spelling hint, shown only while the input has misspellings
+	cv.spellHint = widget.NewLabel("")
+	cv.spellHint.TextStyle = fyne.TextStyle{Italic: true}
+	cv.spellHint.Wrapping = fyne.TextWrapWord
+	cv.spellHint.Hide()
+
+	// Typing line for the open conversation
+	cv.typingLabel = widget.NewLabel("")
+	cv.typingLabel.TextStyle = fyne.TextStyle{Italic: true}
+	cv.typingLabel.Hide()
 
 	// Send button
-	cv.sendBtn = widget.NewButton("Send", func() {
+	cv.sendBtn = widget.NewButton(i18n.T("chat.send"), func() {
 		cv.sendMessage()
 	})
 
+	// Reply bar, shown while composing a reply
+	cv.replyLabel = widget.NewLabel("")
+	cv.replyLabel.TextStyle = fyne.TextStyle{Italic: true}
+	cv.replyLabel.Truncation = fyne.TextTruncateEllipsis
+	cancelReplyBtn := widget.NewButton("✕", cv.cancelReply)
+	cancelReplyBtn.Importance = widget.LowImportance
+	cv.replyBar = container.NewBorder(nil, nil, nil, cancelReplyBtn, cv.replyLabel)
+	cv.replyBar.Hide()
+
+	// Search button, searches the current conversation
+	searchBtn := widget.NewButton("🔍", func() {
+		if cv.onSearch != nil && cv.currentFriend != 0 {
+			cv.onSearch(cv.currentFriend)
+		}
+	})
+	searchBtn.Importance = widget.LowImportance
+
+	// Call button, shown once calls are available
+	cv.callBtn = widget.NewButton("📞", func() {
+		if cv.onCall != nil && cv.currentFriend != 0 {
+			cv.onCall(cv.currentFriend)
+		}
+	})
+	cv.callBtn.Importance = widget.LowImportance
+	cv.callBtn.Hide()
+
 	// Input container
 	inputContainer := container.NewBorder(
-		nil, nil, nil, cv.sendBtn,
+		container.NewVBox(cv.replyBar, cv.spellHint), nil, nil, container.NewHBox(cv.callBtn, searchBtn, cv.sendBtn),
 		cv.input,
 	)
 
+	// Files being sent in the open conversation
+	cv.transfers = container.NewVBox()
+
+	// Header with the friend's avatar and name
+	cv.headerAvatar = newAvatarWidget(headerAvatarSize)
+	cv.headerName = widget.NewLabel("")
+	cv.headerName.TextStyle = fyne.TextStyle{Bold: true}
+	cv.detailsBtn = widget.NewButtonWithIcon("", theme.InfoIcon(), func() {
+		if cv.onDetails != nil && cv.currentFriend != 0 {
+			cv.onDetails(cv.currentFriend)
+		}
+	})
+	cv.detailsBtn.Hide()
+	cv.header = container.NewBorder(nil, widget.NewSeparator(), cv.headerAvatar, cv.detailsBtn, cv.headerName)
+	cv.header.Hide()
+
 	// Main container
 	cv.container = container.NewBorder(
-		nil, inputContainer, nil, nil,
+		cv.header, container.NewVBox(cv.transfers, cv.typingLabel, inputContainer), nil, nil,
 		cv.messages,
 	)
 }
 
-// createMessageContent creates the appropriate content for a message based on its type
-func (cv *ChatView) createMessageContent(container *fyne.Container, msg *message.Message) {
-	// Create sender prefix
-	var sender string
-	if msg.IsOutgoing {
-		sender = "You: "
-	} else {
-		sender = "Friend: "
+// createMessageContent creates the appropriate content for a message based on
+// its type, below a date separator and the sender unless prev already shows them
+func (cv *ChatView) createMessageContent(container *fyne.Container, msg, prev *message.Message) {
+	cv.createMessageHeader(container, msg, prev)
+
+	if msg.IsForwarded {
+		forwardedLabel := widget.NewLabel(i18n.T("chat.forwarded"))
+		forwardedLabel.TextStyle = fyne.TextStyle{Italic: true}
+		container.Add(forwardedLabel)
+	}
+
+	if msg.ReplyToUUID != "" {
+		cv.createReplyReference(container, msg)
 	}
 
 	// Handle different message types
@@ -116,28 +253,210 @@ func (cv *ChatView) createMessageContent(container *fyne.Container, msg *message
 	case message.MessageTypeFile, message.MessageTypeImage, message.MessageTypeVideo:
 		// For file messages, show both text and media preview
 		if msg.FilePath != "" {
-			cv.createFileMessageContent(container, msg, sender)
+			cv.createFileMessageContent(container, msg)
 		} else {
-			cv.createTextMessageContent(container, msg, sender)
+			cv.createTextMessageContent(container, msg)
 		}
 	case message.MessageTypeVoice:
-		cv.createVoiceMessageContent(container, msg, sender)
+		cv.createVoiceMessageContent(container, msg)
 	default:
-		cv.createTextMessageContent(container, msg, sender)
+		cv.createTextMessageContent(container, msg)
+		if !msg.IsOutgoing {
+			cv.createTranslation(container, msg)
+		}
+		cv.createLinkPreview(container, msg)
+	}
+
+	cv.createDeliveryStatus(container, msg)
+
+	if actions := cv.reactionRow(msg); actions != nil {
+		replyBtn := widget.NewButton("↩", func() {
+			cv.startReply(msg)
+		})
+		replyBtn.Importance = widget.LowImportance
+		actions.Add(replyBtn)
+		actions.Add(cv.messageMenuButton(msg))
+		container.Add(actions)
+	}
+
+	// Sends that exhausted their retry budget
+	if msg.FailedAt != nil {
+		failedLabel := widget.NewLabel(i18n.T("chat.not_delivered"))
+		failedLabel.TextStyle = fyne.TextStyle{Italic: true}
+		resendBtn := widget.NewButton(i18n.T("chat.resend"), func() {
+			cv.resendAsNew(msg.ID)
+		})
+		resendBtn.Importance = widget.LowImportance
+		container.Add(failedLabel)
+		container.Add(resendBtn)
+	}
+}
+
+// resendAsNew replaces a failed message with a fresh copy and reloads the conversation
+func (cv *ChatView) resendAsNew(messageID int64) {
+	if cv.coreApp == nil || cv.coreApp.GetMessages() == nil {
+		return
+	}
+	if _, err := cv.coreApp.GetMessages().ResendAsNew(messageID); err != nil {
+		log.Printf("Failed to resend message: %v", err)
+	}
+	if err := cv.loadConversation(cv.currentFriend); err != nil {
+		log.Printf("Failed to reload messages: %v", err)
+	}
+	cv.messages.Refresh()
+}
+
+// createTranslation shows a received message's translation, or a button to request one
+func (cv *ChatView) createTranslation(container *fyne.Container, msg *message.Message) {
+	translations, targetLang := cv.translationTarget()
+	if translations == nil {
+		return
+	}
+
+	if translated, ok := translations.Cached(msg.Content, targetLang); ok {
+		label := widget.NewLabel("🌐 " + translated)
+		label.TextStyle = fyne.TextStyle{Italic: true}
+		label.Wrapping = fyne.TextWrapWord
+		container.Add(label)
+		return
+	}
+
+	var translateBtn *widget.Button
+	translateBtn = widget.NewButton(i18n.T("chat.translate"), func() {
+		translateBtn.Disable()
+		go func() {
+			if _, err := translations.Translate(msg.Content, targetLang); err != nil {
+				log.Printf("Warning: %v", err)
+				translateBtn.Enable()
+				return
+			}
+			cv.messages.Refresh()
+		}()
+	})
+	translateBtn.Importance = widget.LowImportance
+	container.Add(translateBtn)
+}
+
+// replySnippetLength is how many characters of a message are quoted above replies
+const replySnippetLength = 60
+
+// createReplyReference quotes the message a reply refers to. Tapping the quote
+// jumps to the original.
+func (cv *ChatView) createReplyReference(container *fyne.Container, msg *message.Message) {
+	original, text := cv.replyParent(msg)
+	if original == nil {
+		reference := widget.NewLabel(text)
+		reference.TextStyle = fyne.TextStyle{Italic: true}
+		reference.Truncation = fyne.TextTruncateEllipsis
+		container.Add(reference)
+		return
+	}
+
+	originalID := original.ID
+	link := widget.NewHyperlink(text, nil)
+	link.OnTapped = func() {
+		cv.jumpToMessage(originalID)
+	}
+	container.Add(link)
+}
+
+// replyParent returns the message a reply refers to and the quote shown for it.
+// The message is nil when the original is missing or deleted.
+func (cv *ChatView) replyParent(msg *message.Message) (*message.Message, string) {
+	if msg.IsReplyUnavailable() {
+		return nil, i18n.T("chat.reply_unavailable")
+	}
+
+	for _, original := range cv.messageData {
+		if original.ID == *msg.ReplyToID {
+			return original, "↪ " + replySnippet(cv.presentation.Preview(original.Content))
+		}
+	}
+
+	// The original is outside the loaded window
+	if cv.coreApp == nil || cv.coreApp.GetMessages() == nil {
+		return nil, i18n.T("chat.reply_earlier")
 	}
+	original, err := cv.coreApp.GetMessages().GetMessage(*msg.ReplyToID)
+	if err != nil {
+		return nil, i18n.T("chat.reply_unavailable")
+	}
+	if original.IsDeleted {
+		return nil, i18n.T("chat.reply_deleted")
+	}
+	return original, "↪ " + replySnippet(cv.presentation.Preview(original.Content))
+}
+
+// replySnippet shortens a message to its first line, within replySnippetLength characters
+func replySnippet(content string) string {
+	if line, _, found := strings.Cut(content, "\n"); found {
+		content = line + " …"
+	}
+	if runes := []rune(content); len(runes) > replySnippetLength {
+		content = strings.TrimSpace(string(runes[:replySnippetLength])) + "…"
+	}
+	return content
+}
+
+// jumpToMessage scrolls to a message and selects it so it stands out,
+// loading older history until it is in the window
+func (cv *ChatView) jumpToMessage(messageID int64) {
+	cv.historyMu.Lock()
+	row := cv.rowOf(messageID)
+	for row < 0 && cv.history != nil && !cv.loadingHistory && cv.history.HasOlder() {
+		if _, err := cv.shiftWindow(cv.history, true); err != nil {
+			log.Printf("Failed to load message history: %v", err)
+			break
+		}
+		row = cv.rowOf(messageID)
+	}
+	cv.historyMu.Unlock()
+
+	if row < 0 {
+		return
+	}
+	cv.messages.Refresh()
+	cv.messages.Select(row)
+}
+
+// ScrollToMessage shows a message of the current conversation, loading
+// older history if needed
+func (cv *ChatView) ScrollToMessage(messageID int64) {
+	cv.jumpToMessage(messageID)
+}
+
+// rowOf returns the list row showing a message, or -1 if it is not loaded
+func (cv *ChatView) rowOf(messageID int64) int {
+	for i, msg := range cv.messageData {
+		if msg.ID == messageID {
+			return i
+		}
+	}
+	return -1
+}
+
+// startReply makes the next message sent a reply to msg
+func (cv *ChatView) startReply(msg *message.Message) {
+	cv.replyTo = msg
+	cv.replyLabel.SetText(i18n.T("chat.replying_to") + replySnippet(cv.presentation.Preview(msg.Content)))
+	cv.replyBar.Show()
+}
+
+// cancelReply goes back to sending ordinary messages
+func (cv *ChatView) cancelReply() {
+	cv.replyTo = nil
+	cv.replyBar.Hide()
 }
 
 // createTextMessageContent creates content for text messages
-func (cv *ChatView) createTextMessageContent(container *fyne.Container, msg *message.Message, sender string) {
-	label := widget.NewLabel(sender + msg.Content)
-	label.Wrapping = fyne.TextWrapWord
-	container.Add(label)
+func (cv *ChatView) createTextMessageContent(container *fyne.Container, msg *message.Message) {
+	container.Add(cv.newMessageText(msg.Content))
 }
 
 // createFileMessageContent creates content for file messages with media preview
-func (cv *ChatView) createFileMessageContent(container *fyne.Container, msg *message.Message, sender string) {
+func (cv *ChatView) createFileMessageContent(container *fyne.Container, msg *message.Message) {
 	// Add text content
-	textLabel := widget.NewLabel(sender + msg.Content)
+	textLabel := widget.NewLabel(msg.Content)
 	textLabel.Wrapping = fyne.TextWrapWord
 	container.Add(textLabel)
 
@@ -147,21 +466,21 @@ func (cv *ChatView) createFileMessageContent(container *fyne.Container, msg *mes
 		container.Add(mediaPreview.Container())
 	} else {
 		// Show file info for non-media files
-		fileInfo := widget.NewLabel(fmt.Sprintf("📎 File: %s", msg.FilePath))
+		fileInfo := widget.NewLabel(i18n.Tf("chat.file", msg.FilePath))
 		fileInfo.TextStyle = fyne.TextStyle{Italic: true}
 		container.Add(fileInfo)
 	}
 }
 
 // createVoiceMessageContent creates content for voice messages
-func (cv *ChatView) createVoiceMessageContent(container *fyne.Container, msg *message.Message, sender string) {
+func (cv *ChatView) createVoiceMessageContent(container *fyne.Container, msg *message.Message) {
 	// Add text content
-	textLabel := widget.NewLabel(sender + msg.Content)
+	textLabel := widget.NewLabel(msg.Content)
 	textLabel.Wrapping = fyne.TextWrapWord
 	container.Add(textLabel)
 
 	// Add voice message indicator
-	voiceLabel := widget.NewLabel("🎵 Voice Message")
+	voiceLabel := widget.NewLabel(i18n.T("chat.voice_message"))
 	voiceLabel.TextStyle = fyne.TextStyle{Italic: true}
 	container.Add(voiceLabel)
 }
@@ -169,23 +488,38 @@ func (cv *ChatView) createVoiceMessageContent(container *fyne.Container, msg *me
 // sendMessage handles sending a message
 func (cv *ChatView) sendMessage() {
 	text := cv.input.Text
-	if text == "" {
+	if text == "" || cv.presentation.Enabled() {
 		return
 	}
+	if cv.onActivity != nil {
+		cv.onActivity()
+	}
 
 	if cv.coreApp != nil && cv.currentFriend != 0 {
-		if err := cv.coreApp.SendMessageFromUI(cv.currentFriend, text); err != nil {
+		var err error
+		if cv.replyTo != nil && cv.coreApp.GetMessages() != nil {
+			_, err = cv.coreApp.GetMessages().SendReply(cv.currentFriend, text, message.MessageTypeNormal, cv.replyTo.ID)
+		} else {
+			err = cv.coreApp.SendMessageFromUI(cv.currentFriend, text)
+		}
+		if err != nil {
 			log.Printf("Failed to send message: %v", err)
 			return
 		}
+		cv.cancelReply()
+
+		if drafts := cv.draftAutosaver(); drafts != nil {
+			drafts.Discard(cv.currentFriend)
+		}
+		if typing := cv.typingNotifier(); typing != nil {
+			typing.Stop(cv.currentFriend)
+		}
 
 		// Reload messages from database to get the actual sent message
 		if cv.coreApp.GetMessages() != nil {
-			messages, err := cv.coreApp.GetMessages().GetMessages(cv.currentFriend, 50, 0)
-			if err != nil {
+			if err := cv.loadConversation(cv.currentFriend); err != nil {
 				log.Printf("Failed to reload messages: %v", err)
 			} else {
-				cv.messageData = messages
 				cv.messages.Refresh()
 			}
 		}
@@ -196,22 +530,257 @@ func (cv *ChatView) sendMessage() {
 
 // SetCurrentFriend sets the current friend for chat
 func (cv *ChatView) SetCurrentFriend(friendID uint32) {
+	drafts := cv.draftAutosaver()
+	if drafts != nil {
+		drafts.Flush()
+	}
+	typing := cv.typingNotifier()
+	if typing != nil {
+		typing.Stop(cv.currentFriend)
+	}
+
+	cv.keepDraft(cv.currentFriend, cv.input.Text)
 	cv.currentFriend = friendID
+	cv.cancelReply()
+	cv.refreshTyping()
+	cv.refreshHeader()
+	cv.showTransferRows()
 
 	// Load message history for this friend
 	if cv.coreApp != nil && cv.coreApp.GetMessages() != nil {
-		messages, err := cv.coreApp.GetMessages().GetMessages(friendID, 50, 0) // Load last 50 messages
-		if err != nil {
+		if err := cv.loadConversation(friendID); err != nil {
 			log.Printf("Failed to load message history: %v", err)
 			cv.messageData = []*message.Message{} // Clear on error
-		} else {
-			cv.messageData = messages
 		}
 	} else {
+		cv.historyMu.Lock()
+		cv.history = nil
+		cv.historyMu.Unlock()
 		cv.messageData = []*message.Message{} // Clear if no core app
 	}
 
 	cv.messages.Refresh()
+
+	// Restore any draft saved for this conversation, including after a crash
+	draft := cv.savedDraft(friendID)
+	if typing != nil {
+		typing.SetText(friendID, draft)
+	}
+	cv.input.SetText(draft)
+}
+
+// loadConversation replaces the history window with the newest messages of a conversation
+func (cv *ChatView) loadConversation(friendID uint32) error {
+	messages := cv.coreApp.GetMessages()
+	window := NewMessageWindow(func(limit, offset int) ([]*message.Message, error) {
+		return messages.GetMessages(friendID, limit, offset)
+	}, cv.messageWindowSize(), messagePageSize)
+
+	cv.historyMu.Lock()
+	defer cv.historyMu.Unlock()
+
+	cv.history = window
+	if err := window.Reset(); err != nil {
+		return err
+	}
+	cv.messageData = window.Messages()
+	return nil
+}
+
+// messageWindowSize returns the configured number of messages kept in memory
+func (cv *ChatView) messageWindowSize() int {
+	if cv.coreApp.GetConfigManager() == nil {
+		return DefaultMessageWindowSize
+	}
+	return cv.coreApp.GetConfigManager().GetConfig().UI.MessageWindowSize
+}
+
+// maybeLoadMoreHistory loads the next page when the list reaches either edge
+// of the window. Messages are shown newest first, so the last row leads to
+// older history and the first row to newer messages dropped from memory.
+func (cv *ChatView) maybeLoadMoreHistory(row int) {
+	cv.historyMu.Lock()
+	defer cv.historyMu.Unlock()
+
+	if cv.history == nil || cv.loadingHistory {
+		return
+	}
+
+	var older bool
+	switch {
+	case row == len(cv.messageData)-1 && cv.history.HasOlder():
+		older = true
+	case row == 0 && cv.history.HasNewer():
+		older = false
+	default:
+		return
+	}
+
+	cv.loadingHistory = true
+	go cv.loadMoreHistory(cv.history, older, row)
+}
+
+// loadMoreHistory moves the window and keeps the row the user was looking at in view
+func (cv *ChatView) loadMoreHistory(window *MessageWindow, older bool, row int) {
+	// The list reads messageData while refreshing, so no other load may start
+	// until the refresh is done
+	defer func() {
+		cv.historyMu.Lock()
+		cv.loadingHistory = false
+		cv.historyMu.Unlock()
+	}()
+
+	cv.historyMu.Lock()
+	shift, err := cv.shiftWindow(window, older)
+	cv.historyMu.Unlock()
+
+	if err != nil {
+		log.Printf("Failed to load message history: %v", err)
+		return
+	}
+
+	// Refreshing renders rows, which call maybeLoadMoreHistory, so it runs unlocked
+	cv.messages.Refresh()
+	if shift != 0 {
+		cv.messages.ScrollTo(row + shift)
+	}
+}
+
+// shiftWindow loads a page in one direction and returns how far held rows moved.
+// The caller must hold historyMu.
+func (cv *ChatView) shiftWindow(window *MessageWindow, older bool) (int, error) {
+	// The conversation may have changed while this was scheduled
+	if window != cv.history {
+		return 0, nil
+	}
+
+	before := window.Offset()
+	var err error
+	if older {
+		_, err = window.LoadOlder()
+	} else {
+		_, err = window.LoadNewer()
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	cv.messageData = window.Messages()
+	return before - window.Offset(), nil
+}
+
+// RefreshConversations reloads the open conversation if it is one of the given ones
+func (cv *ChatView) RefreshConversations(friendIDs []uint32) {
+	for _, friendID := range friendIDs {
+		if friendID != cv.currentFriend || cv.coreApp == nil || cv.coreApp.GetMessages() == nil {
+			continue
+		}
+		if err := cv.loadConversation(friendID); err != nil {
+			log.Printf("Failed to reload messages: %v", err)
+			return
+		}
+		cv.messages.Refresh()
+		return
+	}
+}
+
+// CurrentFriend returns the friend whose conversation is shown, or 0 if none
+func (cv *ChatView) CurrentFriend() uint32 {
+	return cv.currentFriend
+}
+
+// SetOnActivity sets the callback invoked when the user types or sends a message
+func (cv *ChatView) SetOnActivity(callback func()) {
+	cv.onActivity = callback
+}
+
+// onInputChanged autosaves the draft, reports typing and schedules a spellcheck
+func (cv *ChatView) onInputChanged(text string) {
+	if cv.onActivity != nil {
+		cv.onActivity()
+	}
+	if drafts := cv.draftAutosaver(); drafts != nil && cv.currentFriend != 0 {
+		drafts.Update(cv.currentFriend, text)
+	}
+	if typing := cv.typingNotifier(); typing != nil && cv.currentFriend != 0 {
+		typing.Update(cv.currentFriend, text)
+	}
+	cv.scheduleSpellcheck(text)
+}
+
+// SetOnSearch sets the callback invoked to search the current conversation
+func (cv *ChatView) SetOnSearch(callback func(friendID uint32)) {
+	cv.onSearch = callback
+}
+
+// SetOnCall sets the callback invoked to call the current friend and shows
+// the call button
+func (cv *ChatView) SetOnCall(callback func(friendID uint32)) {
+	cv.onCall = callback
+	cv.callBtn.Show()
+}
+
+// SetOnShowDetails sets the callback invoked to show the current friend's
+// details and shows the details button
+func (cv *ChatView) SetOnShowDetails(callback func(friendID uint32)) {
+	cv.onDetails = callback
+	cv.detailsBtn.Show()
+}
+
+// GetDraft returns the unsent text of a conversation
+func (cv *ChatView) GetDraft(friendID uint32) string {
+	if friendID == cv.currentFriend {
+		return cv.input.Text
+	}
+	return cv.savedDraft(friendID)
+}
+
+// savedDraft returns the draft kept for a conversation since it was last
+// open, or the one stored from an earlier run
+func (cv *ChatView) savedDraft(friendID uint32) string {
+	if draft, ok := cv.unsent[friendID]; ok {
+		return draft
+	}
+	if cv.draftAutosaver() == nil {
+		return ""
+	}
+	draft, err := cv.coreApp.GetMessages().LoadDraft(friendID)
+	if err != nil {
+		log.Printf("Failed to load draft: %v", err)
+	}
+	return draft
+}
+
+// SetDraft replaces the unsent text of a conversation, showing it in the
+// input if the conversation is open
+func (cv *ChatView) SetDraft(friendID uint32, text string) {
+	if friendID == cv.currentFriend {
+		cv.input.SetText(text)
+		return
+	}
+	cv.keepDraft(friendID, text)
+	if drafts := cv.draftAutosaver(); drafts != nil {
+		drafts.Update(friendID, text)
+	}
+}
+
+// keepDraft remembers the unsent text of a conversation while another is open
+func (cv *ChatView) keepDraft(friendID uint32, text string) {
+	if friendID == 0 {
+		return
+	}
+	if cv.unsent == nil {
+		cv.unsent = make(map[uint32]string)
+	}
+	cv.unsent[friendID] = text
+}
+
+// draftAutosaver returns the draft autosaver, or nil if messages are unavailable
+func (cv *ChatView) draftAutosaver() *message.DraftAutosaver {
+	if cv.drafts == nil && cv.coreApp != nil && cv.coreApp.GetMessages() != nil {
+		cv.drafts = message.NewDraftAutosaver(cv.coreApp.GetMessages(), message.DefaultAutosaveDelay)
+	}
+	return cv.drafts
 }
 
 // Container returns the chat view container
@@ -219,14 +788,132 @@ func (cv *ChatView) Container() *fyne.Container {
 	return cv.container
 }
 
+// SetPresentationMode makes the chat read-only and hides reply previews while
+// presentation mode is on
+func (cv *ChatView) SetPresentationMode(presentation *PresentationMode) {
+	cv.presentation = presentation
+	presentation.OnChange(cv.applyPresentation)
+	cv.applyPresentation(presentation.Enabled())
+}
+
+// applyPresentation updates the chat for presentation mode turning on or off
+func (cv *ChatView) applyPresentation(enabled bool) {
+	if enabled {
+		cv.input.Disable()
+		cv.sendBtn.Disable()
+	} else {
+		cv.input.Enable()
+		cv.sendBtn.Enable()
+	}
+	cv.refreshHeader()
+	cv.messages.Refresh()
+}
+
+// SetSpellChecker sets the spellchecker used for the message input.
+// Passing nil disables spellchecking.
+func (cv *ChatView) SetSpellChecker(checker SpellChecker) {
+	if checker == nil {
+		checker = NoopSpellChecker{}
+	}
+
+	cv.spellMu.Lock()
+	cv.spellChecker = checker
+	cv.spellMu.Unlock()
+
+	cv.scheduleSpellcheck(cv.input.Text)
+}
+
+// SetTranslator sets the translator used for on-demand translation of received
+// messages. Translation is only offered while privacy.enable_translation is on,
+// since text is sent to the translator. Passing nil disables translation.
+func (cv *ChatView) SetTranslator(translator Translator) {
+	cv.translateMu.Lock()
+	if translator == nil {
+		cv.translations = nil
+	} else {
+		cv.translations = NewTranslationCache(translator)
+	}
+	cv.translateMu.Unlock()
+
+	cv.messages.Refresh()
+}
+
+// translationTarget returns the translation cache and target language,
+// or a nil cache if the user has not opted in
+func (cv *ChatView) translationTarget() (*TranslationCache, string) {
+	cv.translateMu.Lock()
+	translations := cv.translations
+	cv.translateMu.Unlock()
+
+	if translations == nil {
+		return nil, ""
+	}
+
+	cfg := cv.coreApp.GetConfigManager().GetConfig()
+	if !cfg.Privacy.EnableTranslation {
+		return nil, ""
+	}
+
+	return translations, cfg.UI.Language
+}
+
+// scheduleSpellcheck checks the input once typing pauses, off the UI thread
+func (cv *ChatView) scheduleSpellcheck(text string) {
+	cv.spellMu.Lock()
+	defer cv.spellMu.Unlock()
+
+	if cv.spellTimer != nil {
+		cv.spellTimer.Stop()
+	}
+
+	if text == "" {
+		cv.spellHint.SetText("")
+		cv.spellHint.Hide()
+		return
+	}
+
+	checker := cv.spellChecker
+	cv.spellTimer = time.AfterFunc(spellcheckDelay, func() {
+		hint := formatMisspellings(FindMisspellings(checker, text))
+
+		// Drop results for text the user has since changed
+		if cv.input.Text != text {
+			return
+		}
+
+		cv.spellHint.SetText(hint)
+		if hint == "" {
+			cv.spellHint.Hide()
+		} else {
+			cv.spellHint.Show()
+		}
+	})
+}
+
 // ContactList represents the contact list interface
 type ContactList struct {
 	container    *fyne.Container
 	list         *widget.List
+	search       *widget.Entry
+	showBlocked  *widget.Check  // Lists blocked contacts too
+	sortSelect   *widget.Select // Order of the contact list
+	requestsBtn  *widget.Button // Opens the friend request inbox, hidden when empty
+	selfStatus   *widget.Select // Our own status
+	selfDot      *canvas.Text
+	selfIdle     *widget.Label // Shown while auto-away set the status
+	selfNetwork  *widget.Label // Shown while we are not on the Tox network
+	selfAvatar   *avatarWidget
+	selfName     *widget.Label // Our name, or a hint to set one
 	coreApp      CoreApp
-	contactData  []*contact.Contact
-	onSelect     func(uint32) // Callback when contact is selected
-	parentWindow fyne.Window  // Reference to parent window for dialogs
+	groups       *groupSection
+	allContacts  []*contact.Contact // Every contact, before filtering
+	contactData  []*contact.Contact // Contacts matching the search box
+	unread       map[uint32]int     // Unread message count by friend ID
+	onSelect     func(uint32)       // Callback when contact is selected
+	onCleared    func(uint32)       // Callback when a conversation was cleared
+	copyText     func(string)       // Copies sensitive text, such as Tox IDs
+	parentWindow fyne.Window        // Reference to parent window for dialogs
+	presentation *PresentationMode
 }
 
 // NewContactList creates a new contact list
@@ -235,6 +922,11 @@ func NewContactList(coreApp CoreApp) *ContactList {
 		coreApp: coreApp,
 	}
 	cl.initializeComponents()
+	if coreApp != nil && coreApp.GetContacts() != nil {
+		coreApp.GetContacts().SetOnRequestsChanged(cl.refreshRequests)
+	}
+	cl.refreshRequests()
+	cl.RefreshGroups()
 	return cl
 }
 
@@ -249,41 +941,144 @@ func (cl *ContactList) initializeComponents() {
 	cl.list = widget.NewList(
 		func() int { return len(cl.contactData) },
 		func() fyne.CanvasObject {
-			return widget.NewButton("Contact", nil)
+			star := widget.NewButton(favoriteStar(false), nil)
+			star.Importance = widget.LowImportance
+			left := container.NewHBox(newPresenceDot(contact.StatusOffline), newAvatarWidget(contactAvatarSize))
+			return container.NewBorder(nil, nil, left, star, newContactButton())
 		},
 		func(i widget.ListItemID, o fyne.CanvasObject) {
 			if i < len(cl.contactData) {
 				contact := cl.contactData[i]
-				button := o.(*widget.Button)
-				displayName := contact.Name
-				if displayName == "" || displayName == "Unknown" {
-					displayName = fmt.Sprintf("Friend %d", contact.FriendID)
-				}
-				button.SetText(displayName)
+				row := o.(*fyne.Container)
+				button := row.Objects[0].(*contactButton)
+				left := row.Objects[1].(*fyne.Container)
+				setPresenceDot(left.Objects[0].(*canvas.Text), contact.Status)
+				left.Objects[1].(*avatarWidget).SetAvatar(cl.avatarData(contact), cl.displayName(contact))
+				star := row.Objects[2].(*widget.Button)
+				star.SetText(favoriteStar(contact.IsFavorite))
+				star.OnTapped = func() { cl.toggleFavorite(contact) }
+				button.SetText(cl.rowLabel(contact))
 				button.OnTapped = func() {
+					cl.markRead(contact.FriendID)
 					if cl.onSelect != nil {
 						cl.onSelect(contact.FriendID)
 					}
 				}
+				button.onSecondary = func(pos fyne.Position) {
+					cl.showContactMenu(contact.FriendID, pos)
+				}
 			}
 		},
 	)
 
 	// Add friend button
-	addFriendBtn := widget.NewButton("Add Friend", func() {
-		cl.showAddFriendDialog()
+	addFriendBtn := widget.NewButton(i18n.T("add_friend.title"), func() {
+		cl.showAddFriendDialog("")
 	})
 
+	// Search box filters the list as the user types
+	cl.search = widget.NewEntry()
+	cl.search.SetPlaceHolder(i18n.T("contacts.search"))
+	cl.search.OnChanged = func(string) {
+		cl.applyFilter()
+	}
+
+	// Blocked contacts are hidden unless asked for
+	cl.showBlocked = widget.NewCheck(i18n.T("contacts.show_blocked"), func(bool) {
+		cl.applyFilter()
+	})
+	cl.sortSelect = cl.newSortSelect()
+
+	// Friend request inbox, shown while requests are waiting
+	cl.requestsBtn = widget.NewButton(i18n.T("contacts.friend_requests"), cl.ShowFriendRequestsDialog)
+	cl.requestsBtn.Importance = widget.HighImportance
+	cl.requestsBtn.Hide()
+
+	// Group chats below the friends
+	cl.groups = cl.newGroupSection()
+
 	// Main container
 	cl.container = container.NewVBox(
-		widget.NewLabel("Contacts"),
+		cl.newSelfStatusHeader(),
 		addFriendBtn,
+		cl.requestsBtn,
+		cl.search,
+		container.NewBorder(nil, nil, cl.showBlocked, nil, cl.sortSelect),
 		cl.list,
+		cl.groups.container,
 	)
 }
 
-// showAddFriendDialog shows the add friend dialog
-func (cl *ContactList) showAddFriendDialog() {
+// rowLabel returns a contact's name as shown in the list, with its unread count
+func (cl *ContactList) rowLabel(c *contact.Contact) string {
+	label := cl.displayName(c)
+	if c.StatusMessage != "" && !cl.presentation.Enabled() {
+		label = fmt.Sprintf("%s — %s", label, c.StatusMessage)
+	}
+	if unread := cl.unread[c.FriendID]; unread > 0 {
+		label = fmt.Sprintf("%s (%d)", label, unread)
+	}
+	if c.IsBlocked {
+		label += i18n.T("contacts.blocked_suffix")
+	}
+	return label
+}
+
+// displayName returns a contact's name, masked in presentation mode
+func (cl *ContactList) displayName(c *contact.Contact) string {
+	displayName := c.DisplayName()
+	if displayName == "" || displayName == "Unknown" {
+		displayName = i18n.Tf("contacts.fallback_name", c.FriendID)
+	}
+	return cl.presentation.DisplayName(c.FriendID, displayName)
+}
+
+// applyFilter shows the contacts matching the search box, leaving out
+// blocked contacts unless they were asked for
+func (cl *ContactList) applyFilter() {
+	contacts := cl.allContacts
+	if !cl.showBlocked.Checked {
+		contacts = withoutBlocked(contacts)
+	}
+	cl.contactData = filterContacts(contacts, cl.search.Text)
+	cl.list.Refresh()
+}
+
+// withoutBlocked returns the contacts that are not blocked
+func withoutBlocked(contacts []*contact.Contact) []*contact.Contact {
+	unblocked := make([]*contact.Contact, 0, len(contacts))
+	for _, c := range contacts {
+		if !c.IsBlocked {
+			unblocked = append(unblocked, c)
+		}
+	}
+	return unblocked
+}
+
+// filterContacts returns the contacts whose name, alias, status message or
+// friend ID contains query, ignoring case. An empty query matches everyone.
+func filterContacts(contacts []*contact.Contact, query string) []*contact.Contact {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return contacts
+	}
+
+	matches := []*contact.Contact{}
+	for _, c := range contacts {
+		fields := []string{c.Name, c.Alias, c.StatusMessage, strconv.FormatUint(uint64(c.FriendID), 10)}
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), query) {
+				matches = append(matches, c)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// showAddFriendDialog shows the add friend dialog, filled in with toxID
+// if it is set
+func (cl *ContactList) showAddFriendDialog(toxID string) {
 	if cl.parentWindow == nil {
 		log.Println("No parent window available for add friend dialog")
 		return
@@ -291,53 +1086,102 @@ func (cl *ContactList) showAddFriendDialog() {
 
 	// Create input fields
 	toxIDEntry := widget.NewEntry()
-	toxIDEntry.SetPlaceHolder("Enter Tox ID...")
+	toxIDEntry.SetPlaceHolder(i18n.T("add_friend.tox_id_placeholder"))
 	toxIDEntry.Wrapping = fyne.TextWrapWord
+	toxIDEntry.SetText(toxID)
 
 	messageEntry := widget.NewEntry()
-	messageEntry.SetText("Hello! I'd like to add you as a friend.")
-	messageEntry.SetPlaceHolder("Friend request message...")
+	messageEntry.SetText(i18n.T("add_friend.default_message"))
+	messageEntry.SetPlaceHolder(i18n.T("add_friend.message_placeholder"))
 	messageEntry.Wrapping = fyne.TextWrapWord
 
+	// Fingerprint of the entered ID, for comparing with the friend out-of-band
+	fingerprintLabel := widget.NewLabel("")
+	fingerprintLabel.Wrapping = fyne.TextWrapWord
+	toxIDEntry.OnChanged = func(text string) {
+		if fingerprint, err := contact.Fingerprint(text); err == nil {
+			fingerprintLabel.SetText(i18n.T("add_friend.fingerprint") + fingerprint)
+		} else {
+			fingerprintLabel.SetText("")
+		}
+	}
+	verifiedCheck := widget.NewCheck(i18n.T("add_friend.verified"), nil)
+
 	// Create buttons
-	var dialog *widget.PopUp
+	var popup *widget.PopUp
 
-	addButton := widget.NewButton("Add Friend", func() {
+	addContact := func(toxID, message string, verified bool) {
+		if err := cl.coreApp.AddContactFromUI(toxID, message); err != nil {
+			log.Printf("Failed to add contact: %v", err)
+			// Show error dialog
+			cl.showErrorDialog(i18n.Tf("add_friend.failed", err))
+			return
+		}
+		log.Println("Friend request sent successfully")
+
+		if verified {
+			if added, ok := cl.coreApp.GetContacts().FindByToxID(toxID); ok {
+				if err := cl.coreApp.GetContacts().SetVerified(added.FriendID, true); err != nil {
+					log.Printf("Failed to mark contact as verified: %v", err)
+				}
+			}
+		}
+
+		// Refresh contact list
+		cl.RefreshContacts()
+		popup.Hide()
+	}
+
+	addButton := widget.NewButton(i18n.T("add_friend.title"), func() {
 		toxID := toxIDEntry.Text
 		message := messageEntry.Text
 
 		if toxID == "" {
 			// Show error - invalid Tox ID
-			cl.showErrorDialog("Please enter a valid Tox ID")
+			cl.showErrorDialog(i18n.T("add_friend.invalid_id"))
 			return
 		}
 
 		// Try to add the contact
-		if cl.coreApp != nil {
-			if err := cl.coreApp.AddContactFromUI(toxID, message); err != nil {
-				log.Printf("Failed to add contact: %v", err)
-				// Show error dialog
-				cl.showErrorDialog(fmt.Sprintf("Failed to add contact: %v", err))
-			} else {
-				log.Println("Friend request sent successfully")
-				// Refresh contact list
-				cl.RefreshContacts()
-				dialog.Hide()
-			}
+		if cl.coreApp == nil {
+			return
 		}
+
+		verified := verifiedCheck.Checked
+		if !contact.NeedsAddConfirmation(cl.confirmUnverifiedAdds(), verified) {
+			addContact(toxID, message, verified)
+			return
+		}
+
+		fingerprint, err := contact.Fingerprint(toxID)
+		if err != nil {
+			cl.showErrorDialog(i18n.Tf("add_friend.failed", err))
+			return
+		}
+		warning := widget.NewLabel(i18n.T("add_friend.unverified") + "\n\n" + fingerprint)
+		warning.Wrapping = fyne.TextWrapWord
+		confirm := dialog.NewCustomConfirm(i18n.T("add_friend.unverified_title"), i18n.T("add_friend.add_anyway"), i18n.T("common.cancel"), warning, func(ok bool) {
+			if ok {
+				addContact(toxID, message, false)
+			}
+		}, cl.parentWindow)
+		confirm.Resize(fyne.NewSize(400, 250))
+		confirm.Show()
 	})
 
-	cancelButton := widget.NewButton("Cancel", func() {
-		dialog.Hide()
+	cancelButton := widget.NewButton(i18n.T("common.cancel"), func() {
+		popup.Hide()
 	})
 
 	// Create dialog content
 	content := container.NewVBox(
-		widget.NewLabel("Add Friend"),
+		widget.NewLabel(i18n.T("add_friend.title")),
 		widget.NewSeparator(),
-		widget.NewLabel("Tox ID:"),
+		widget.NewLabel(i18n.T("add_friend.tox_id")),
 		toxIDEntry,
-		widget.NewLabel("Message:"),
+		fingerprintLabel,
+		verifiedCheck,
+		widget.NewLabel(i18n.T("add_friend.message")),
 		messageEntry,
 		widget.NewSeparator(),
 		container.NewHBox(
@@ -347,19 +1191,60 @@ func (cl *ContactList) showAddFriendDialog() {
 	)
 
 	// Create and show dialog
-	dialog = widget.NewModalPopUp(content, cl.parentWindow.Canvas())
-	dialog.Resize(fyne.NewSize(400, 300))
-	dialog.Show()
+	popup = widget.NewModalPopUp(content, cl.parentWindow.Canvas())
+	popup.Resize(fyne.NewSize(400, 300))
+	popup.Show()
+}
+
+// confirmUnverifiedAdds reports whether adding an unverified contact needs confirmation
+func (cl *ContactList) confirmUnverifiedAdds() bool {
+	if cl.coreApp.GetConfigManager() == nil {
+		return false
+	}
+	return cl.coreApp.GetConfigManager().GetConfig().Privacy.ConfirmUnverifiedContacts
 }
 
 // RefreshContacts refreshes the contact list
 func (cl *ContactList) RefreshContacts() {
 	if cl.coreApp != nil && cl.coreApp.GetContacts() != nil {
-		contacts := cl.coreApp.GetContacts().GetAllContacts()
-		cl.contactData = contacts
+		cl.allContacts = cl.sortedContacts()
 	} else {
-		cl.contactData = []*contact.Contact{} // Clear if no core app
+		cl.allContacts = []*contact.Contact{} // Clear if no core app
 	}
+
+	cl.unread = nil
+	if cl.coreApp != nil && cl.coreApp.GetMessages() != nil {
+		counts, err := cl.coreApp.GetMessages().GetUnreadCounts()
+		if err != nil {
+			log.Printf("Failed to load unread counts: %v", err)
+		}
+		cl.unread = counts
+	}
+	cl.applyFilter()
+	cl.RefreshSelfStatus()
+	cl.RefreshGroups()
+}
+
+// markRead marks a conversation read when it is opened and clears its badge
+func (cl *ContactList) markRead(friendID uint32) {
+	if cl.unread[friendID] == 0 || cl.coreApp.GetMessages() == nil {
+		return
+	}
+	if err := cl.coreApp.GetMessages().MarkAsRead(friendID); err != nil {
+		log.Printf("Failed to mark messages as read: %v", err)
+		return
+	}
+	delete(cl.unread, friendID)
+	cl.list.Refresh()
+}
+
+// SetPresentationMode masks contact names while presentation mode is on
+func (cl *ContactList) SetPresentationMode(presentation *PresentationMode) {
+	cl.presentation = presentation
+	presentation.OnChange(func(bool) {
+		cl.list.Refresh()
+		cl.RefreshSelfStatus()
+	})
 	cl.list.Refresh()
 }
 
@@ -368,9 +1253,225 @@ func (cl *ContactList) SetOnContactSelect(callback func(uint32)) {
 	cl.onSelect = callback
 }
 
+// ShowStaleContactsDialog lists contacts inactive for the configured period
+// and removes them once the user confirms
+func (cl *ContactList) ShowStaleContactsDialog() {
+	if cl.coreApp == nil || cl.coreApp.GetContacts() == nil || cl.parentWindow == nil {
+		return
+	}
+
+	days := config.DefaultStaleContactDays
+	if configMgr := cl.coreApp.GetConfigManager(); configMgr != nil {
+		if configured := configMgr.GetConfig().Storage.StaleContactDays; configured > 0 {
+			days = configured
+		}
+	}
+
+	contacts := cl.coreApp.GetContacts()
+	stale, err := contacts.FindStaleContacts(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		cl.showErrorDialog(i18n.Tf("clean_up.failed", err))
+		return
+	}
+	if len(stale) == 0 {
+		dialog.ShowInformation(i18n.T("clean_up.title"), i18n.Tf("clean_up.none", days), cl.parentWindow)
+		return
+	}
+
+	names := make([]string, len(stale))
+	friendIDs := make([]uint32, len(stale))
+	for i, c := range stale {
+		names[i] = cl.presentation.DisplayName(c.FriendID, c.DisplayName())
+		friendIDs[i] = c.FriendID
+	}
+
+	summary := widget.NewLabel(i18n.Tf("clean_up.summary", days) + "\n\n" + strings.Join(names, "\n"))
+	summary.Wrapping = fyne.TextWrapWord
+	confirm := dialog.NewCustomConfirm(i18n.T("clean_up.title"), i18n.T("clean_up.remove"), i18n.T("clean_up.keep"), container.NewVScroll(summary), func(ok bool) {
+		if !ok {
+			return
+		}
+		if removed, err := contacts.RemoveContacts(friendIDs); err != nil {
+			cl.showErrorDialog(i18n.Tf("clean_up.partial", removed, len(friendIDs), err))
+		}
+		cl.RefreshContacts()
+	}, cl.parentWindow)
+	confirm.Resize(fyne.NewSize(400, 300))
+	confirm.Show()
+}
+
+// ShowContactDetails shows a contact's own name and Tox ID next to the
+// settings the user keeps for it: the local nickname, whether its files are
+// accepted automatically and how its notifications are shown. None of them
+// is ever sent to the contact.
+func (cl *ContactList) ShowContactDetails(friendID uint32) {
+	if cl.coreApp == nil || cl.coreApp.GetContacts() == nil || cl.parentWindow == nil {
+		return
+	}
+
+	contacts := cl.coreApp.GetContacts()
+	found, ok := contacts.GetContact(friendID)
+	if !ok {
+		cl.showErrorDialog(i18n.Tf("details.not_found", friendID))
+		return
+	}
+	c := found.(*contact.Contact)
+
+	alias := widget.NewEntry()
+	alias.SetText(c.Alias)
+	alias.SetPlaceHolder(i18n.T("details.nickname_placeholder"))
+
+	name := c.Name
+	if name == "" {
+		name = i18n.T("details.not_set")
+	}
+	toxID := widget.NewLabel(c.ToxID)
+	toxID.Wrapping = fyne.TextWrapBreak
+	copyToxID := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() { cl.copy(c.ToxID) })
+	toxIDRow := container.NewBorder(nil, nil, nil, copyToxID, toxID)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("details.nickname"), alias),
+		widget.NewFormItem(i18n.T("details.name"), widget.NewLabel(cl.presentation.DisplayName(c.FriendID, name))),
+		widget.NewFormItem(i18n.T("details.presence"), widget.NewLabel(cl.presenceText(c))),
+		widget.NewFormItem(i18n.T("details.status"), widget.NewLabel(cl.presentation.Preview(c.StatusMessage))),
+		widget.NewFormItem(i18n.T("details.tox_id"), toxIDRow),
+	}
+
+	favorite := widget.NewCheck(i18n.T("details.favorite"), nil)
+	favorite.SetChecked(c.IsFavorite)
+	items = append(items, widget.NewFormItem(i18n.T("details.favorites"), favorite))
+
+	autoAccept := widget.NewCheck(i18n.T("details.auto_accept"), nil)
+	autoAccept.SetChecked(c.AutoAcceptFiles)
+	items = append(items, widget.NewFormItem(i18n.T("details.files"), autoAccept))
+
+	blocked := widget.NewCheck(i18n.T("details.block"), nil)
+	blocked.SetChecked(c.IsBlocked)
+	items = append(items, widget.NewFormItem(i18n.T("details.blocking"), blocked))
+
+	muted := widget.NewCheck(i18n.T("details.mute"), nil)
+	muted.SetChecked(c.Notifications.Muted)
+	alwaysPreview := widget.NewCheck(i18n.T("details.always_preview"), nil)
+	alwaysPreview.SetChecked(c.Notifications.AlwaysShowPreview)
+	overrideQuiet := widget.NewCheck(i18n.T("details.override_quiet"), nil)
+	overrideQuiet.SetChecked(c.Notifications.OverrideQuietHours)
+	sound := widget.NewEntry()
+	sound.SetText(c.Notifications.Sound)
+	sound.SetPlaceHolder(i18n.T("details.default_sound"))
+	items = append(items,
+		widget.NewFormItem(i18n.T("details.notifications"), container.NewVBox(muted, alwaysPreview, overrideQuiet)),
+		widget.NewFormItem(i18n.T("details.sound"), sound),
+	)
+
+	// Retention is local, so it stays editable while presenting
+	messages := cl.coreApp.GetMessages()
+	var retention *widget.Select
+	var keep time.Duration
+	if messages != nil {
+		labels := make([]string, len(retentionChoices))
+		for i, choice := range retentionChoices {
+			labels[i] = i18n.T(choice.label)
+		}
+		keep = messages.GetFriendRetention(friendID)
+		retention = widget.NewSelect(labels, nil)
+		retention.SetSelected(retentionLabel(keep))
+		items = append(items, widget.NewFormItem(i18n.T("details.retention"), retention))
+
+		clearBtn := widget.NewButton(i18n.T("details.clear_history"), func() { cl.ClearConversation(friendID) })
+		items = append(items, widget.NewFormItem(i18n.T("details.history"), container.NewHBox(clearBtn)))
+	}
+
+	// Shared files would give away what was said, so they stay hidden while presenting
+	var gallery *mediaGallery
+	if messages != nil && !cl.presentation.Enabled() {
+		gallery = newMediaGallery(cl.coreApp, friendID)
+		items = append(items, widget.NewFormItem(i18n.T("details.media"), gallery.container))
+	}
+
+	if cl.presentation.Enabled() {
+		alias.Disable()
+		autoAccept.Disable()
+		blocked.Disable()
+		favorite.Disable()
+		muted.Disable()
+		alwaysPreview.Disable()
+		overrideQuiet.Disable()
+		sound.Disable()
+		toxIDRow.Hide()
+	}
+
+	form := dialog.NewForm(i18n.T("details.title"), i18n.T("common.save"), i18n.T("common.cancel"), items, func(save bool) {
+		if !save {
+			return
+		}
+		if retention != nil && retention.SelectedIndex() >= 0 {
+			if chosen := retentionChoices[retention.SelectedIndex()].keep; chosen != keep {
+				if err := messages.SetFriendRetention(friendID, chosen); err != nil {
+					cl.showErrorDialog(i18n.Tf("details.retention_failed", err))
+					return
+				}
+			}
+		}
+		if alias.Disabled() {
+			return
+		}
+		if err := contacts.SetAlias(friendID, alias.Text); err != nil {
+			cl.showErrorDialog(i18n.Tf("details.nickname_failed", err))
+			return
+		}
+		if autoAccept.Checked != c.AutoAcceptFiles {
+			if err := contacts.SetAutoAcceptFiles(friendID, autoAccept.Checked); err != nil {
+				cl.showErrorDialog(i18n.Tf("details.files_failed", err))
+				return
+			}
+		}
+		if favorite.Checked != c.IsFavorite {
+			if err := contacts.SetFavorite(friendID, favorite.Checked); err != nil {
+				cl.showErrorDialog(i18n.Tf("contacts.favorite_failed", err))
+				return
+			}
+		}
+		if blocked.Checked != c.IsBlocked {
+			setBlocked := contacts.Unblock
+			if blocked.Checked {
+				setBlocked = contacts.Block
+			}
+			if err := setBlocked(friendID); err != nil {
+				cl.showErrorDialog(i18n.Tf("details.block_failed", err))
+				return
+			}
+		}
+		notify := contact.NotificationOverride{
+			Muted:              muted.Checked,
+			Sound:              strings.TrimSpace(sound.Text),
+			AlwaysShowPreview:  alwaysPreview.Checked,
+			OverrideQuietHours: overrideQuiet.Checked,
+		}
+		if notify != c.Notifications {
+			if err := contacts.SetNotificationOverride(friendID, notify); err != nil {
+				cl.showErrorDialog(i18n.Tf("details.notifications_failed", err))
+				return
+			}
+		}
+		cl.RefreshContacts()
+	}, cl.parentWindow)
+	if gallery != nil && gallery.total > 0 {
+		form.Resize(fyne.NewSize(560, 640))
+	} else {
+		form.Resize(fyne.NewSize(450, 420))
+	}
+	form.Show()
+}
+
 // ShowAddFriendDialog shows the add friend dialog (public method)
 func (cl *ContactList) ShowAddFriendDialog() {
-	cl.showAddFriendDialog()
+	cl.showAddFriendDialog("")
+}
+
+// ShowAddFriendDialogFor shows the add friend dialog filled in with a Tox ID
+func (cl *ContactList) ShowAddFriendDialogFor(toxID string) {
+	cl.showAddFriendDialog(toxID)
 }
 
 // Container returns the contact list container
@@ -390,7 +1491,7 @@ func (cl *ContactList) showErrorDialog(message string) {
 	errorPopup = widget.NewModalPopUp(
 		container.NewVBox(
 			errorLabel,
-			widget.NewButton("OK", func() {
+			widget.NewButton(i18n.T("common.ok"), func() {
 				errorPopup.Hide()
 			}),
 		),
@@ -407,12 +1508,19 @@ type MediaPreview struct {
 	mediaInfo     *media.MediaInfo
 	thumbnailPath string
 	coreApp       CoreApp
+
+	filePath  string
+	maxWidth  int
+	maxHeight int
 }
 
 // NewMediaPreview creates a new media preview for a file
 func NewMediaPreview(coreApp CoreApp, filePath string, maxWidth, maxHeight int) *MediaPreview {
 	mp := &MediaPreview{
-		coreApp: coreApp,
+		coreApp:   coreApp,
+		filePath:  filePath,
+		maxWidth:  maxWidth,
+		maxHeight: maxHeight,
 	}
 
 	mp.initializePreview(filePath, maxWidth, maxHeight)
@@ -466,26 +1574,72 @@ func (mp *MediaPreview) initializePreview(filePath string, maxWidth, maxHeight i
 // createImagePreview creates a preview for image files
 func (mp *MediaPreview) createImagePreview(filePath string) {
 	// Create image card with thumbnail
-	title := fmt.Sprintf("Image (%dx%d)", mp.mediaInfo.Width, mp.mediaInfo.Height)
+	title := i18n.Tf("media.image", mp.mediaInfo.Width, mp.mediaInfo.Height)
 	subtitle := mp.formatFileSize(mp.mediaInfo.Size)
 
 	mp.image = widget.NewCard(title, subtitle, nil)
 
-	// TODO: In a full implementation, load the actual thumbnail image
-	// For now, show a placeholder with image info
-	content := widget.NewLabel(fmt.Sprintf("📷 %s", title))
-	content.Alignment = fyne.TextAlignCenter
+	var content fyne.CanvasObject
+	if err := mp.ensureThumbnail(); err != nil {
+		log.Printf("Failed to load thumbnail for %s: %v", filePath, err)
+		label := widget.NewLabel(fmt.Sprintf("📷 %s", title))
+		label.Alignment = fyne.TextAlignCenter
+		content = label
+	} else {
+		thumbnail := canvas.NewImageFromFile(mp.thumbnailPath)
+		thumbnail.FillMode = canvas.ImageFillContain
+		thumbnail.SetMinSize(fitSize(mp.mediaInfo.Width, mp.mediaInfo.Height, mp.maxWidth, mp.maxHeight))
+		content = newTappableImage(thumbnail, func() {
+			mp.showFullImage(filePath)
+		})
+	}
 
 	mp.image.SetContent(content)
 	mp.container = container.NewVBox(mp.image)
 }
 
+// ensureThumbnail regenerates the thumbnail if its file has been removed,
+// for example by cache pruning, since the path was looked up
+func (mp *MediaPreview) ensureThumbnail() error {
+	if mp.thumbnailPath != "" {
+		if _, err := os.Stat(mp.thumbnailPath); err == nil {
+			return nil
+		}
+	}
+
+	thumbnailPath, err := mp.coreApp.GenerateThumbnailFromUI(mp.filePath, mp.maxWidth, mp.maxHeight)
+	if err != nil {
+		return fmt.Errorf("failed to regenerate thumbnail: %w", err)
+	}
+	if _, err := os.Stat(thumbnailPath); err != nil {
+		return fmt.Errorf("thumbnail missing after regeneration: %w", err)
+	}
+	mp.thumbnailPath = thumbnailPath
+	return nil
+}
+
+// showFullImage opens the original image in its own window
+func (mp *MediaPreview) showFullImage(filePath string) {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return
+	}
+
+	full := canvas.NewImageFromFile(filePath)
+	full.FillMode = canvas.ImageFillContain
+
+	window := app.NewWindow(filepath.Base(filePath))
+	window.SetContent(full)
+	window.Resize(fitSize(mp.mediaInfo.Width, mp.mediaInfo.Height, fullImageMaxWidth, fullImageMaxHeight))
+	window.Show()
+}
+
 // createVideoPreview creates a preview for video files
 func (mp *MediaPreview) createVideoPreview(filePath string) {
 	// Create video card with thumbnail
-	title := "Video"
+	title := i18n.T("media.video")
 	if mp.mediaInfo.Duration > 0 {
-		title = fmt.Sprintf("Video (%s)", mp.formatDuration(mp.mediaInfo.Duration))
+		title = i18n.Tf("media.video_duration", mp.formatDuration(mp.mediaInfo.Duration))
 	}
 	subtitle := mp.formatFileSize(mp.mediaInfo.Size)
 
@@ -501,7 +1655,7 @@ func (mp *MediaPreview) createVideoPreview(filePath string) {
 
 // createGenericMediaPreview creates a preview for other media types
 func (mp *MediaPreview) createGenericMediaPreview(filePath string) {
-	title := fmt.Sprintf("%s File", mp.mediaInfo.Type.String())
+	title := i18n.Tf("media.typed_file", mp.mediaInfo.Type.String())
 	subtitle := mp.formatFileSize(mp.mediaInfo.Size)
 
 	card := widget.NewCard(title, subtitle, nil)
@@ -524,8 +1678,8 @@ func (mp *MediaPreview) createGenericMediaPreview(filePath string) {
 
 // createNonMediaPreview creates a preview for non-media files
 func (mp *MediaPreview) createNonMediaPreview(filePath string) {
-	title := "File"
-	subtitle := "Non-media file"
+	title := i18n.T("media.file")
+	subtitle := i18n.T("media.non_media")
 
 	card := widget.NewCard(title, subtitle, nil)
 
@@ -538,8 +1692,8 @@ func (mp *MediaPreview) createNonMediaPreview(filePath string) {
 
 // createErrorPreview creates a preview when there's an error
 func (mp *MediaPreview) createErrorPreview(filePath string, err error) {
-	title := "Error"
-	subtitle := "Failed to load preview"
+	title := i18n.T("media.error")
+	subtitle := i18n.T("media.load_failed")
 
 	card := widget.NewCard(title, subtitle, nil)
 
@@ -550,8 +1704,59 @@ func (mp *MediaPreview) createErrorPreview(filePath string, err error) {
 	mp.container = container.NewVBox(card)
 }
 
+// Largest initial size of the full image window
+const (
+	fullImageMaxWidth  = 1024
+	fullImageMaxHeight = 768
+)
+
+// fitSize scales width x height to fit within maxWidth x maxHeight, keeping
+// the aspect ratio. Images are never enlarged; unknown sizes use the maximum.
+func fitSize(width, height, maxWidth, maxHeight int) fyne.Size {
+	if width <= 0 || height <= 0 {
+		return fyne.NewSize(float32(maxWidth), float32(maxHeight))
+	}
+
+	scale := math.Min(float64(maxWidth)/float64(width), float64(maxHeight)/float64(height))
+	if scale > 1 {
+		scale = 1
+	}
+	return fyne.NewSize(float32(math.Round(float64(width)*scale)), float32(math.Round(float64(height)*scale)))
+}
+
+// tappableImage is an image that runs a callback when tapped
+type tappableImage struct {
+	widget.BaseWidget
+	image    *canvas.Image
+	onTapped func()
+}
+
+// newTappableImage wraps an image so it can be tapped
+func newTappableImage(image *canvas.Image, onTapped func()) *tappableImage {
+	t := &tappableImage{image: image, onTapped: onTapped}
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+// CreateRenderer draws the wrapped image
+func (t *tappableImage) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(t.image)
+}
+
+// Tapped runs the tap callback
+func (t *tappableImage) Tapped(*fyne.PointEvent) {
+	if t.onTapped != nil {
+		t.onTapped()
+	}
+}
+
 // formatFileSize formats file size in human-readable format
 func (mp *MediaPreview) formatFileSize(size int64) string {
+	return formatBytes(size)
+}
+
+// formatBytes formats a byte count in human-readable format
+func formatBytes(size int64) string {
 	const unit = 1024
 	if size < unit {
 		return fmt.Sprintf("%d B", size)
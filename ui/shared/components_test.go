@@ -1,13 +1,19 @@
 package shared
 
 import (
+	"path/filepath"
 	"testing"
 
 	"fyne.io/fyne/v2/test"
+	"github.com/opd-ai/toxcore"
 	"github.com/opd-ai/whisp/internal/core/config"
 	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/group"
+	"github.com/opd-ai/whisp/internal/core/linkpreview"
 	"github.com/opd-ai/whisp/internal/core/media"
 	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/internal/core/tox"
+	"github.com/opd-ai/whisp/internal/storage"
 )
 
 // MockCoreApp implements the CoreApp interface for testing
@@ -36,6 +42,29 @@ func (m *MockCoreApp) GetContacts() *contact.Manager {
 	return nil // Simple mock
 }
 
+func (m *MockCoreApp) GetSelfStatus() contact.Status {
+	return contact.StatusOnline
+}
+
+func (m *MockCoreApp) SetSelfStatus(status contact.Status) {}
+
+func (m *MockCoreApp) IsAutoAway() bool { return false }
+
+func (m *MockCoreApp) GetConnectionState() tox.ConnectionState { return tox.ConnectionOnline }
+func (m *MockCoreApp) GetSelfAvatar() []byte                   { return nil }
+func (m *MockCoreApp) GetProfile() (string, string)            { return "", "" }
+func (m *MockCoreApp) LinkPreviewMode(friendID uint32, outgoing bool) linkpreview.Mode {
+	return linkpreview.ModeOff
+}
+func (m *MockCoreApp) FetchLinkPreview(rawURL string) (*linkpreview.Preview, error) { return nil, nil }
+func (m *MockCoreApp) CachedLinkPreview(rawURL string) (*linkpreview.Preview, bool) {
+	return nil, false
+}
+
+func (m *MockCoreApp) GetGroups() *group.Manager {
+	return nil // Simple mock
+}
+
 func (m *MockCoreApp) GetConfigManager() *config.Manager {
 	return nil // Simple mock
 }
@@ -112,3 +141,295 @@ func TestChatViewSetCurrentFriend(t *testing.T) {
 		t.Errorf("Expected current friend to be 123, got %d", chatView.currentFriend)
 	}
 }
+
+func TestContactListSearch(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	cl := NewContactList(&MockCoreApp{})
+	cl.allContacts = []*contact.Contact{
+		{FriendID: 1, Name: "Alice", StatusMessage: "At the beach"},
+		{FriendID: 2, Name: "Bob", Alias: "Bobby Tables"},
+		{FriendID: 12, Name: "Carol", StatusMessage: "busy"},
+	}
+	cl.applyFilter()
+
+	tests := []struct {
+		query string
+		want  []uint32
+	}{
+		{"", []uint32{1, 2, 12}},
+		{"ALI", []uint32{1}},
+		{"tables", []uint32{2}}, // Alias
+		{"beach", []uint32{1}},  // Status message
+		{"2", []uint32{2, 12}},  // Friend ID
+		{"zelda", []uint32{}},
+		{"  bob ", []uint32{2}},
+	}
+
+	for _, tt := range tests {
+		cl.search.SetText(tt.query)
+
+		var got []uint32
+		for _, c := range cl.contactData {
+			got = append(got, c.FriendID)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("Query %q: expected %v, got %v", tt.query, tt.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Query %q: expected %v, got %v", tt.query, tt.want, got)
+				break
+			}
+		}
+		if cl.list.Length() != len(tt.want) {
+			t.Errorf("Query %q: list shows %d rows, expected %d", tt.query, cl.list.Length(), len(tt.want))
+		}
+	}
+
+	// Typing filters as the user goes, and clearing restores everyone
+	cl.search.SetText("")
+	test.Type(cl.search, "car")
+	if len(cl.contactData) != 1 || cl.contactData[0].FriendID != 12 {
+		t.Errorf("Expected typing to filter to Carol, got %d contacts", len(cl.contactData))
+	}
+	cl.search.SetText("")
+	if len(cl.contactData) != len(cl.allContacts) {
+		t.Errorf("Expected clearing the search to show all contacts, got %d", len(cl.contactData))
+	}
+}
+
+func TestContactListHidesBlocked(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	cl := NewContactList(&MockCoreApp{})
+	cl.allContacts = []*contact.Contact{
+		{FriendID: 1, Name: "Alice"},
+		{FriendID: 2, Name: "Mallory", IsBlocked: true},
+	}
+	cl.applyFilter()
+
+	if len(cl.contactData) != 1 || cl.contactData[0].FriendID != 1 {
+		t.Errorf("Expected blocked contacts to be hidden, got %d contacts", len(cl.contactData))
+	}
+
+	cl.showBlocked.SetChecked(true)
+	if len(cl.contactData) != 2 {
+		t.Errorf("Expected blocked contacts when asked for, got %d contacts", len(cl.contactData))
+	}
+	if got := cl.rowLabel(cl.allContacts[1]); got != "Mallory (blocked)" {
+		t.Errorf("Expected blocked contacts to be marked, got %q", got)
+	}
+}
+
+func TestContactListUnreadBadge(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	cl := NewContactList(&MockCoreApp{})
+	alice := &contact.Contact{FriendID: 1, Name: "Alice"}
+	unnamed := &contact.Contact{FriendID: 2}
+	cl.unread = map[uint32]int{1: 3}
+
+	if got := cl.rowLabel(alice); got != "Alice (3)" {
+		t.Errorf("Expected unread badge, got %q", got)
+	}
+	if got := cl.rowLabel(unnamed); got != "Friend 2" {
+		t.Errorf("Expected no badge without unread messages, got %q", got)
+	}
+
+	// Refreshing without a message manager drops stale counts
+	cl.RefreshContacts()
+	if got := cl.rowLabel(alice); got != "Alice" {
+		t.Errorf("Expected badge to clear, got %q", got)
+	}
+}
+
+func TestContactListPrefersAlias(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	cl := NewContactList(&MockCoreApp{})
+	aliased := &contact.Contact{FriendID: 1, Name: "Alex", Alias: "Alex (work)"}
+	if got := cl.rowLabel(aliased); got != "Alex (work)" {
+		t.Errorf("Expected the alias, got %q", got)
+	}
+
+	aliased.Alias = ""
+	if got := cl.rowLabel(aliased); got != "Alex" {
+		t.Errorf("Expected the name without an alias, got %q", got)
+	}
+}
+
+// messagesCoreApp is a MockCoreApp backed by a real message manager
+type messagesCoreApp struct {
+	MockCoreApp
+	manager *message.Manager
+}
+
+func (m *messagesCoreApp) GetMessages() *message.Manager {
+	return m.manager
+}
+
+// nullTox accepts every message without sending it anywhere
+type nullTox struct{}
+
+func (nullTox) SendMessage(friendID uint32, message string, messageType toxcore.MessageType) error {
+	return nil
+}
+
+func newMessagesCoreApp(t *testing.T) *messagesCoreApp {
+	t.Helper()
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &messagesCoreApp{manager: message.NewManager(db, nullTox{}, nil)}
+}
+
+func TestChatViewReply(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	core := newMessagesCoreApp(t)
+	original := core.manager.HandleIncomingMessage(1, "lunch tomorrow?\nsay noon", message.MessageTypeNormal)
+	if original == nil {
+		t.Fatal("Failed to store message")
+	}
+
+	cv := NewChatView(core)
+	cv.SetCurrentFriend(1)
+	cv.startReply(original)
+	if !cv.replyBar.Visible() || cv.replyLabel.Text != "Replying to: lunch tomorrow? …" {
+		t.Errorf("Expected the reply bar to quote the original, got %q", cv.replyLabel.Text)
+	}
+
+	cv.input.SetText("sure")
+	cv.sendMessage()
+	if cv.replyTo != nil || cv.replyBar.Visible() {
+		t.Error("Expected the reply to be finished after sending")
+	}
+
+	reply := cv.messageData[0]
+	if reply.Content != "sure" || reply.ReplyToID == nil || *reply.ReplyToID != original.ID {
+		t.Fatalf("Expected the sent message to reply to %d, got %+v", original.ID, reply)
+	}
+	if parent, text := cv.replyParent(reply); parent == nil || text != "↪ lunch tomorrow? …" {
+		t.Errorf("Expected a quote of the original, got %q", text)
+	}
+
+	// A deleted original can no longer be jumped to
+	if err := core.manager.DeleteMessage(original.ID); err != nil {
+		t.Fatalf("DeleteMessage failed: %v", err)
+	}
+	cv.SetCurrentFriend(1)
+	if parent, text := cv.replyParent(reply); parent != nil || text != "↪ Original message was deleted" {
+		t.Errorf("Expected the deleted original to be reported, got %q", text)
+	}
+}
+
+func TestChatViewJumpToMessage(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	cv := NewChatView(&MockCoreApp{})
+	history := &fakeHistory{total: 300}
+	cv.history = NewMessageWindow(history.load, 100, 50)
+	if err := cv.history.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	cv.messageData = cv.history.Messages()
+
+	// Older pages load until the message is in the window
+	cv.jumpToMessage(20)
+	if cv.rowOf(20) < 0 {
+		t.Fatalf("Expected message 20 to be loaded, window holds %d..%d",
+			cv.messageData[len(cv.messageData)-1].ID, cv.messageData[0].ID)
+	}
+
+	// Unknown messages stop at the start of the conversation
+	cv.jumpToMessage(9999)
+	if cv.history.HasOlder() {
+		t.Error("Expected the whole conversation to have been searched")
+	}
+}
+
+func TestReplySnippet(t *testing.T) {
+	long := "0123456789012345678901234567890123456789012345678901234567890123456789"
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"short", "short"},
+		{"first line\nsecond line", "first line …"},
+		{long, long[:replySnippetLength] + "…"},
+	}
+
+	for _, tt := range tests {
+		if got := replySnippet(tt.content); got != tt.want {
+			t.Errorf("replySnippet(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestFormatPublicKey(t *testing.T) {
+	var key [32]byte
+	key[0], key[31] = 0xab, 0x01
+
+	got := formatPublicKey(key)
+	want := "AB000000 00000000 00000000 00000000 00000000 00000000 00000000 00000001"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestChatViewKeepsDraftPerFriend(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	tests := []struct {
+		name string
+		core CoreApp
+	}{
+		{"in memory", &MockCoreApp{}},
+		{"stored", newMessagesCoreApp(t)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cv := NewChatView(tt.core)
+			cv.SetCurrentFriend(1)
+			cv.input.SetText("half a thought")
+			cv.SetCurrentFriend(2)
+			if cv.input.Text != "" {
+				t.Errorf("Expected an empty input for friend 2, got %q", cv.input.Text)
+			}
+			cv.input.SetText("for two")
+			cv.SetDraft(3, "set for three")
+
+			cv.SetCurrentFriend(1)
+			if cv.input.Text != "half a thought" {
+				t.Errorf("Expected friend 1's draft restored, got %q", cv.input.Text)
+			}
+			for friendID, want := range map[uint32]string{1: "half a thought", 2: "for two", 3: "set for three", 4: ""} {
+				if got := cv.GetDraft(friendID); got != want {
+					t.Errorf("Friend %d: expected draft %q, got %q", friendID, want, got)
+				}
+			}
+
+			// Sending clears only the sent conversation's draft
+			cv.sendMessage()
+			cv.SetCurrentFriend(2)
+			if got := cv.GetDraft(1); got != "" {
+				t.Errorf("Expected the sent draft to be gone, got %q", got)
+			}
+			if cv.input.Text != "for two" {
+				t.Errorf("Expected friend 2's draft restored, got %q", cv.input.Text)
+			}
+		})
+	}
+}
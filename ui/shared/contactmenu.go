@@ -0,0 +1,113 @@
+package shared
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// contactButton is a contact list row that opens a menu on right-click
+type contactButton struct {
+	widget.Button
+	onSecondary func(pos fyne.Position)
+}
+
+// newContactButton creates an empty contact list row
+func newContactButton() *contactButton {
+	button := &contactButton{}
+	button.ExtendBaseWidget(button)
+	return button
+}
+
+// TappedSecondary opens the contact menu where the row was clicked
+func (b *contactButton) TappedSecondary(event *fyne.PointEvent) {
+	if b.onSecondary != nil {
+		b.onSecondary(event.AbsolutePosition)
+	}
+}
+
+// showContactMenu pops up the actions for a contact at pos
+func (cl *ContactList) showContactMenu(friendID uint32, pos fyne.Position) {
+	if cl.parentWindow == nil {
+		return
+	}
+
+	menu := fyne.NewMenu("",
+		fyne.NewMenuItem(i18n.T("contact_menu.mark_read"), func() { cl.markRead(friendID) }),
+		fyne.NewMenuItem(i18n.T("contact_menu.clear_history"), func() { cl.ClearConversation(friendID) }),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem(i18n.T("contact_menu.details"), func() { cl.ShowContactDetails(friendID) }),
+	)
+	widget.ShowPopUpMenuAtPosition(menu, cl.parentWindow.Canvas(), pos)
+}
+
+// SetOnConversationCleared sets the callback invoked after a friend's
+// history was cleared
+func (cl *ContactList) SetOnConversationCleared(callback func(friendID uint32)) {
+	cl.onCleared = callback
+}
+
+// ClearConversation asks for confirmation, then hides every message
+// exchanged with a friend
+func (cl *ContactList) ClearConversation(friendID uint32) {
+	if cl.coreApp == nil || cl.coreApp.GetMessages() == nil || cl.parentWindow == nil {
+		return
+	}
+
+	dialog.ShowConfirm(i18n.T("clear_history.title"), i18n.Tf("clear_history.confirm", cl.contactName(friendID)), func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if _, err := cl.coreApp.GetMessages().ClearConversation(friendID, false); err != nil {
+			cl.showErrorDialog(i18n.Tf("clear_history.failed", err))
+			return
+		}
+		cl.RefreshContacts()
+		if cl.onCleared != nil {
+			cl.onCleared(friendID)
+		}
+	}, cl.parentWindow)
+}
+
+// SetCopyHandler sets how sensitive text such as Tox IDs is copied, so it
+// can be cleared from the clipboard later
+func (cl *ContactList) SetCopyHandler(copyText func(text string)) {
+	cl.copyText = copyText
+}
+
+// copy places text on the clipboard
+func (cl *ContactList) copy(text string) {
+	if cl.copyText != nil {
+		cl.copyText(text)
+		return
+	}
+	if cl.parentWindow != nil {
+		cl.parentWindow.Clipboard().SetContent(text)
+	}
+}
+
+// MarkAllRead marks every conversation read and clears the unread badges
+func (cl *ContactList) MarkAllRead() {
+	if cl.coreApp == nil || cl.coreApp.GetMessages() == nil {
+		return
+	}
+	if _, err := cl.coreApp.GetMessages().MarkAllAsRead(); err != nil {
+		log.Printf("Failed to mark messages as read: %v", err)
+		return
+	}
+	cl.RefreshContacts()
+}
+
+// contactName returns the name a friend is shown under in the list
+func (cl *ContactList) contactName(friendID uint32) string {
+	for _, c := range cl.allContacts {
+		if c.FriendID == friendID {
+			return cl.displayName(c)
+		}
+	}
+	return i18n.Tf("contacts.fallback_name", friendID)
+}
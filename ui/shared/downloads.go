@@ -0,0 +1,73 @@
+package shared
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/config"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// downloadMediaTypes are the file types that can have their own download folder
+var downloadMediaTypes = []string{"image", "video", "audio", "document"}
+
+// createDownloadDirItems creates the form rows for the download folder and
+// the folders for each file type
+func (sd *SettingsDialog) createDownloadDirItems(cfg config.Config) []*widget.FormItem {
+	defaultEntry := widget.NewEntry()
+	defaultEntry.SetText(cfg.Storage.DownloadDir)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("settings.download_dir"), sd.folderPicker(defaultEntry)),
+	}
+	refs := map[string]interface{}{"default": defaultEntry}
+
+	for _, mediaType := range downloadMediaTypes {
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder(i18n.T("settings.download_dir_same"))
+		entry.SetText(cfg.Storage.DownloadDirs[mediaType])
+		items = append(items, widget.NewFormItem(i18n.T("settings.download_dir_"+mediaType), sd.folderPicker(entry)))
+		refs[mediaType] = entry
+	}
+
+	sd.storeFormReferences("downloads", refs)
+	return items
+}
+
+// folderPicker puts a button next to entry that fills it with a chosen folder
+func (sd *SettingsDialog) folderPicker(entry *widget.Entry) fyne.CanvasObject {
+	browse := widget.NewButton(i18n.T("settings.browse"), func() {
+		dialog.ShowFolderOpen(func(folder fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, sd.parentWindow)
+				return
+			}
+			if folder != nil {
+				entry.SetText(folder.Path())
+			}
+		}, sd.parentWindow)
+	})
+	return container.NewBorder(nil, nil, nil, browse, entry)
+}
+
+// applyDownloadDirs copies the download folders from the form to cfg
+func applyDownloadDirs(cfg *config.Config, refs map[string]interface{}) {
+	if entry, ok := refs["default"].(*widget.Entry); ok {
+		cfg.Storage.DownloadDir = strings.TrimSpace(entry.Text)
+	}
+
+	// A new map, so the configuration in use is not changed before saving
+	dirs := make(map[string]string)
+	for _, mediaType := range downloadMediaTypes {
+		if entry, ok := refs[mediaType].(*widget.Entry); ok {
+			if dir := strings.TrimSpace(entry.Text); dir != "" {
+				dirs[mediaType] = dir
+			}
+		}
+	}
+	cfg.Storage.DownloadDirs = dirs
+}
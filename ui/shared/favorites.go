@@ -0,0 +1,100 @@
+package shared
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// contactSortModes are the contact list orders offered, with the translation
+// keys of their names
+var contactSortModes = []struct {
+	mode contact.SortMode
+	key  string
+}{
+	{contact.SortFavoritesFirst, "contacts.sort_favorites"},
+	{contact.SortRecent, "contacts.sort_recent"},
+	{contact.SortAlphabetical, "contacts.sort_alphabetical"},
+}
+
+// newSortSelect creates the picker for the contact list order, starting at
+// the saved order
+func (cl *ContactList) newSortSelect() *widget.Select {
+	mode := cl.sortMode()
+	labels := make([]string, len(contactSortModes))
+	selected := i18n.T(contactSortModes[0].key) // Favorites first when unset
+	for i, sm := range contactSortModes {
+		labels[i] = i18n.T(sm.key)
+		if sm.mode == mode {
+			selected = labels[i]
+		}
+	}
+
+	// Set the selection before the callback so opening does not save
+	sortSelect := widget.NewSelect(labels, nil)
+	sortSelect.SetSelected(selected)
+	sortSelect.OnChanged = func(label string) {
+		for i, sm := range contactSortModes {
+			if labels[i] == label {
+				cl.setSortMode(sm.mode)
+				return
+			}
+		}
+	}
+	return sortSelect
+}
+
+// sortMode returns the saved contact list order
+func (cl *ContactList) sortMode() contact.SortMode {
+	if cl.coreApp == nil || cl.coreApp.GetConfigManager() == nil {
+		return contact.SortFavoritesFirst
+	}
+	return contact.SortMode(cl.coreApp.GetConfigManager().GetConfig().UI.ContactSort)
+}
+
+// setSortMode saves a new contact list order and re-sorts the list
+func (cl *ContactList) setSortMode(mode contact.SortMode) {
+	if cl.coreApp != nil && cl.coreApp.GetConfigManager() != nil {
+		configMgr := cl.coreApp.GetConfigManager()
+		cfg := configMgr.GetConfig()
+		cfg.UI.ContactSort = string(mode)
+		if err := configMgr.UpdateConfig(cfg); err != nil {
+			log.Printf("Warning: Failed to save contact sort order: %v", err)
+		}
+	}
+	cl.RefreshContacts()
+}
+
+// sortedContacts returns every contact in the saved order
+func (cl *ContactList) sortedContacts() []*contact.Contact {
+	contacts := cl.coreApp.GetContacts()
+	sorted, err := contacts.GetContactsSorted(cl.sortMode())
+	if err != nil {
+		log.Printf("Warning: Failed to sort contacts: %v", err)
+		return contacts.GetAllContacts()
+	}
+	return sorted
+}
+
+// favoriteStar returns the label of a contact's favorite toggle
+func favoriteStar(favorite bool) string {
+	if favorite {
+		return "★"
+	}
+	return "☆"
+}
+
+// toggleFavorite pins or unpins a contact and re-sorts the list
+func (cl *ContactList) toggleFavorite(c *contact.Contact) {
+	if cl.coreApp == nil || cl.coreApp.GetContacts() == nil {
+		return
+	}
+	if err := cl.coreApp.GetContacts().SetFavorite(c.FriendID, !c.IsFavorite); err != nil {
+		cl.showErrorDialog(i18n.Tf("contacts.favorite_failed", err))
+		return
+	}
+	cl.RefreshContacts()
+}
@@ -0,0 +1,196 @@
+package shared
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/transfer"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// largeDropSize is the total size of dropped files above which the user is
+// asked before they are sent
+const largeDropSize = 100 * 1024 * 1024
+
+// FileSender sends files to friends
+type FileSender interface {
+	SendFileFromUI(friendID uint32, filePath string) (string, error)
+	CancelFileFromUI(transferID string) error
+	GetTransfers() *transfer.Manager
+}
+
+// droppedFile is a file to send from the chat
+type droppedFile struct {
+	path string
+	size int64
+}
+
+// checkDroppedFiles returns the dropped paths that can be sent and the names
+// of files over maxSize. Folders and paths that cannot be read are skipped.
+func checkDroppedFiles(paths []string, maxSize uint64) ([]droppedFile, []string) {
+	var files []droppedFile
+	var tooLarge []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			log.Printf("Warning: Skipping dropped file %s: %v", path, err)
+			continue
+		}
+		if info.IsDir() {
+			continue
+		}
+		if uint64(info.Size()) > maxSize {
+			tooLarge = append(tooLarge, filepath.Base(path))
+			continue
+		}
+		files = append(files, droppedFile{path: path, size: info.Size()})
+	}
+	return files, tooLarge
+}
+
+// totalSize returns the combined size of files
+func totalSize(files []droppedFile) int64 {
+	var total int64
+	for _, file := range files {
+		total += file.size
+	}
+	return total
+}
+
+// SetFileSender lets files be sent from the chat through sender, with
+// dialogs shown over parent
+func (cv *ChatView) SetFileSender(sender FileSender, parent fyne.Window) {
+	cv.fileSender = sender
+	cv.parentWindow = parent
+}
+
+// SendFiles sends files to the friend whose conversation is open, asking
+// first when together they are large
+func (cv *ChatView) SendFiles(paths []string) {
+	friendID := cv.currentFriend
+	if cv.fileSender == nil || friendID == 0 || cv.presentation.Enabled() {
+		return
+	}
+
+	maxSize := cv.fileSender.GetTransfers().GetMaxFileSize()
+	files, tooLarge := checkDroppedFiles(paths, maxSize)
+	if len(tooLarge) > 0 {
+		dialog.ShowInformation(i18n.T("file_drop.title"),
+			i18n.Tf("file_drop.too_large", strings.Join(tooLarge, ", "), formatBytes(int64(maxSize))), cv.parentWindow)
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	if total := totalSize(files); total > largeDropSize {
+		dialog.ShowConfirm(i18n.T("file_drop.title"), i18n.Tf("file_drop.confirm_large", len(files), formatBytes(total)),
+			func(confirmed bool) {
+				if confirmed {
+					go cv.sendFiles(friendID, files)
+				}
+			}, cv.parentWindow)
+		return
+	}
+	go cv.sendFiles(friendID, files)
+}
+
+// sendFiles starts a transfer of each file and shows its progress
+func (cv *ChatView) sendFiles(friendID uint32, files []droppedFile) {
+	var failed []string
+	for _, file := range files {
+		name := filepath.Base(file.path)
+		transferID, err := cv.fileSender.SendFileFromUI(friendID, file.path)
+		if err != nil {
+			log.Printf("Failed to send %s: %v", file.path, err)
+			failed = append(failed, name)
+			continue
+		}
+		cv.addTransferRow(friendID, transferID, name)
+	}
+
+	if len(failed) > 0 {
+		dialog.ShowError(errors.New(i18n.Tf("file_drop.send_failed", strings.Join(failed, ", "))), cv.parentWindow)
+	}
+}
+
+// addTransferRow shows the progress of a file being sent to a friend until
+// the transfer ends or is cancelled
+func (cv *ChatView) addTransferRow(friendID uint32, transferID, name string) {
+	label := widget.NewLabel(i18n.Tf("file_drop.sending", name))
+	label.Truncation = fyne.TextTruncateEllipsis
+	progress := widget.NewProgressBar()
+
+	var row *fyne.Container
+	cancelBtn := widget.NewButton("✕", func() {
+		if err := cv.fileSender.CancelFileFromUI(transferID); err != nil {
+			log.Printf("Failed to cancel transfer %s: %v", transferID, err)
+		}
+		cv.removeTransferRow(friendID, row)
+	})
+	cancelBtn.Importance = widget.LowImportance
+	row = container.NewBorder(label, nil, nil, cancelBtn, progress)
+
+	transfers := cv.fileSender.GetTransfers()
+	if err := transfers.SetProgressCallback(transferID, func(t *transfer.Transfer) {
+		label.SetText(i18n.Tf("file_drop.sending", name))
+		progress.SetValue(t.Progress())
+	}); err != nil {
+		log.Printf("Warning: Failed to follow transfer %s: %v", transferID, err)
+		return
+	}
+	if t, ok := transfers.GetTransfer(transferID); ok && t.IsQueued() {
+		label.SetText(i18n.Tf("file_drop.queued", name))
+	}
+	transfers.SetCompletionCallback(transferID, func(t *transfer.Transfer, err error) {
+		if err != nil {
+			log.Printf("Transfer of %s failed: %v", name, err)
+			dialog.ShowError(errors.New(i18n.Tf("file_drop.send_failed", name)), cv.parentWindow)
+		}
+		cv.removeTransferRow(friendID, row)
+	})
+
+	cv.transferMu.Lock()
+	if cv.transferRows == nil {
+		cv.transferRows = make(map[uint32][]fyne.CanvasObject)
+	}
+	cv.transferRows[friendID] = append(cv.transferRows[friendID], row)
+	cv.transferMu.Unlock()
+
+	cv.showTransferRows()
+}
+
+// removeTransferRow stops showing the progress row of a friend's transfer
+func (cv *ChatView) removeTransferRow(friendID uint32, row fyne.CanvasObject) {
+	cv.transferMu.Lock()
+	rows := cv.transferRows[friendID]
+	for i, r := range rows {
+		if r == row {
+			cv.transferRows[friendID] = append(rows[:i:i], rows[i+1:]...)
+			break
+		}
+	}
+	if len(cv.transferRows[friendID]) == 0 {
+		delete(cv.transferRows, friendID)
+	}
+	cv.transferMu.Unlock()
+
+	cv.showTransferRows()
+}
+
+// showTransferRows shows the files being sent in the open conversation
+func (cv *ChatView) showTransferRows() {
+	cv.transferMu.Lock()
+	rows := append([]fyne.CanvasObject(nil), cv.transferRows[cv.currentFriend]...)
+	cv.transferMu.Unlock()
+
+	cv.transfers.Objects = rows
+	cv.transfers.Refresh()
+}
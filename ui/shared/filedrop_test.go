@@ -0,0 +1,107 @@
+package shared
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/opd-ai/whisp/internal/core/transfer"
+)
+
+// mockFileSender records the files it is asked to send
+type mockFileSender struct {
+	transfers *transfer.Manager
+	sent      chan string
+}
+
+func (s *mockFileSender) SendFileFromUI(friendID uint32, filePath string) (string, error) {
+	s.sent <- filepath.Base(filePath)
+	return "", nil
+}
+
+func (s *mockFileSender) CancelFileFromUI(transferID string) error { return nil }
+
+func (s *mockFileSender) GetTransfers() *transfer.Manager { return s.transfers }
+
+// writeDroppedFile creates a file of size bytes in dir and returns its path
+func writeDroppedFile(t *testing.T, dir, name string, size int) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	return path
+}
+
+func TestCheckDroppedFiles(t *testing.T) {
+	dir := t.TempDir()
+	small := writeDroppedFile(t, dir, "small.txt", 10)
+	big := writeDroppedFile(t, dir, "big.iso", 100)
+	folder := filepath.Join(dir, "folder")
+	if err := os.Mkdir(folder, 0o700); err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		paths        []string
+		wantFiles    []droppedFile
+		wantTooLarge []string
+	}{
+		{"within the limit", []string{small}, []droppedFile{{small, 10}}, nil},
+		{"over the limit", []string{small, big}, []droppedFile{{small, 10}}, []string{"big.iso"}},
+		{"folders and missing files skipped", []string{folder, filepath.Join(dir, "gone.txt")}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, tooLarge := checkDroppedFiles(tt.paths, 50)
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("Expected files %v, got %v", tt.wantFiles, files)
+			}
+			if !reflect.DeepEqual(tooLarge, tt.wantTooLarge) {
+				t.Errorf("Expected too large %v, got %v", tt.wantTooLarge, tooLarge)
+			}
+		})
+	}
+}
+
+func TestSendFilesToOpenConversation(t *testing.T) {
+	test.NewApp()
+	dir := t.TempDir()
+	transfers, err := transfer.NewManager(dir)
+	if err != nil {
+		t.Fatalf("Failed to create transfer manager: %v", err)
+	}
+	sender := &mockFileSender{transfers: transfers, sent: make(chan string, 2)}
+
+	cv := NewChatView(&MockCoreApp{})
+	cv.SetFileSender(sender, test.NewWindow(nil))
+	paths := []string{writeDroppedFile(t, dir, "a.txt", 1), writeDroppedFile(t, dir, "b.txt", 1)}
+
+	// Nothing is sent without an open conversation
+	cv.SendFiles(paths)
+	select {
+	case name := <-sender.sent:
+		t.Fatalf("Expected no file sent without a conversation, got %s", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cv.currentFriend = 7
+	cv.SendFiles(paths)
+	for _, want := range []string{"a.txt", "b.txt"} {
+		select {
+		case name := <-sender.sent:
+			if name != want {
+				t.Errorf("Expected %s to be sent, got %s", want, name)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %s to be sent", want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package shared
+
+import (
+	"errors"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// messageMenuButton returns the button that opens the actions for a message
+func (cv *ChatView) messageMenuButton(msg *message.Message) *widget.Button {
+	var menuBtn *widget.Button
+	menuBtn = widget.NewButton("⋯", func() {
+		cv.showMessageMenu(menuBtn, msg)
+	})
+	menuBtn.Importance = widget.LowImportance
+	return menuBtn
+}
+
+// showMessageMenu pops up the actions for a message below a button
+func (cv *ChatView) showMessageMenu(anchor fyne.CanvasObject, msg *message.Message) {
+	driver := fyne.CurrentApp().Driver()
+	canvas := driver.CanvasForObject(anchor)
+	if canvas == nil {
+		return
+	}
+
+	forwardItem := fyne.NewMenuItem(i18n.T("chat.forward"), func() {
+		cv.showForwardPicker(msg)
+	})
+	// Disappearing messages must not outlive their conversation
+	forwardItem.Disabled = msg.ExpiresAt != nil
+
+	position := driver.AbsolutePositionForObject(anchor).Add(fyne.NewPos(0, anchor.Size().Height))
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", forwardItem), canvas, position)
+}
+
+// forwardCandidates returns the contacts a message from friendID can be
+// forwarded to: everyone else who is not blocked
+func forwardCandidates(contacts []*contact.Contact, friendID uint32) []*contact.Contact {
+	var candidates []*contact.Contact
+	for _, c := range withoutBlocked(contacts) {
+		if c.FriendID != friendID {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
+
+// showForwardPicker asks which contact to forward a message to, then sends it
+func (cv *ChatView) showForwardPicker(msg *message.Message) {
+	if cv.parentWindow == nil || cv.coreApp == nil || cv.coreApp.GetContacts() == nil {
+		return
+	}
+
+	candidates := forwardCandidates(cv.coreApp.GetContacts().GetAllContacts(), msg.FriendID)
+	shown := candidates
+
+	var picker dialog.Dialog
+	list := widget.NewList(
+		func() int { return len(shown) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			c := shown[id]
+			name := c.DisplayName()
+			if name == "" || name == "Unknown" {
+				name = i18n.Tf("contacts.fallback_name", c.FriendID)
+			}
+			obj.(*widget.Label).SetText(cv.presentation.DisplayName(c.FriendID, name))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		friendID := shown[id].FriendID
+		picker.Hide()
+		cv.forwardMessage(msg, friendID)
+	}
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder(i18n.T("contacts.search"))
+	search.OnChanged = func(query string) {
+		shown = filterContacts(candidates, query)
+		list.UnselectAll()
+		list.Refresh()
+	}
+
+	var content fyne.CanvasObject = container.NewBorder(search, nil, nil, nil, list)
+	if len(candidates) == 0 {
+		content = widget.NewLabel(i18n.T("forward.no_contacts"))
+	}
+
+	picker = dialog.NewCustom(i18n.T("forward.title"), i18n.T("common.cancel"), content, cv.parentWindow)
+	picker.Resize(fyne.NewSize(360, 420))
+	picker.Show()
+}
+
+// forwardMessage sends a copy of a message to a friend
+func (cv *ChatView) forwardMessage(msg *message.Message, friendID uint32) {
+	if cv.coreApp.GetMessages() == nil {
+		return
+	}
+	if _, err := cv.coreApp.GetMessages().ForwardMessage(msg.ID, friendID); err != nil {
+		dialog.ShowError(errors.New(i18n.Tf("forward.failed", err)), cv.parentWindow)
+	}
+}
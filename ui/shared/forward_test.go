@@ -0,0 +1,39 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+)
+
+func TestForwardCandidates(t *testing.T) {
+	contacts := []*contact.Contact{
+		{FriendID: 1, Name: "Alice"},
+		{FriendID: 2, Name: "Bob"},
+		{FriendID: 3, Name: "Mallory", IsBlocked: true},
+		{FriendID: 4, Name: "Carol"},
+	}
+
+	tests := []struct {
+		name     string
+		friendID uint32
+		want     []uint32
+	}{
+		{"leaves out the source conversation", 1, []uint32{2, 4}},
+		{"leaves out blocked contacts", 2, []uint32{1, 4}},
+		{"unknown source", 9, []uint32{1, 2, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := forwardCandidates(contacts, tt.friendID)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d candidates, got %d", len(tt.want), len(got))
+			}
+			for i, c := range got {
+				if c.FriendID != tt.want[i] {
+					t.Errorf("Candidate %d: expected friend %d, got %d", i, tt.want[i], c.FriendID)
+				}
+			}
+		})
+	}
+}
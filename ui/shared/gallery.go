@@ -0,0 +1,107 @@
+package shared
+
+import (
+	"log"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// Gallery layout; thumbnails are generated a page at a time
+const (
+	galleryPageSize  = 12
+	galleryColumns   = 4
+	galleryThumbSize = 96
+)
+
+// mediaGallery pages through the files exchanged with a friend
+type mediaGallery struct {
+	coreApp  CoreApp
+	friendID uint32
+	page     int
+	total    int
+
+	grid      *fyne.Container
+	pageLabel *widget.Label
+	prevBtn   *widget.Button
+	nextBtn   *widget.Button
+	container *fyne.Container
+}
+
+// newMediaGallery creates a gallery showing the newest files first
+func newMediaGallery(coreApp CoreApp, friendID uint32) *mediaGallery {
+	g := &mediaGallery{coreApp: coreApp, friendID: friendID}
+
+	g.grid = container.NewGridWithColumns(galleryColumns)
+	g.pageLabel = widget.NewLabel("")
+	g.prevBtn = widget.NewButton(i18n.T("gallery.previous"), func() { g.showPage(g.page - 1) })
+	g.nextBtn = widget.NewButton(i18n.T("gallery.next"), func() { g.showPage(g.page + 1) })
+	g.container = container.NewBorder(nil, container.NewHBox(g.prevBtn, g.pageLabel, g.nextBtn), nil, nil, g.grid)
+
+	total, err := coreApp.GetMessages().CountSharedMedia(friendID)
+	if err != nil {
+		log.Printf("Failed to count shared media: %v", err)
+	}
+	g.total = total
+	g.showPage(0)
+	return g
+}
+
+// pages returns how many pages the gallery has
+func (g *mediaGallery) pages() int {
+	return (g.total + galleryPageSize - 1) / galleryPageSize
+}
+
+// showPage loads and shows one page of files
+func (g *mediaGallery) showPage(page int) {
+	if page < 0 || (page > 0 && page >= g.pages()) {
+		return
+	}
+	g.page = page
+
+	g.grid.Objects = nil
+	if g.total == 0 {
+		g.pageLabel.SetText(i18n.T("gallery.empty"))
+		g.prevBtn.Hide()
+		g.nextBtn.Hide()
+		g.grid.Refresh()
+		return
+	}
+
+	files, err := g.coreApp.GetMessages().GetSharedMedia(g.friendID, galleryPageSize, page*galleryPageSize)
+	if err != nil {
+		log.Printf("Failed to load shared media: %v", err)
+	}
+	for _, msg := range files {
+		g.grid.Add(g.thumbnail(msg.FilePath))
+	}
+	g.grid.Refresh()
+
+	g.pageLabel.SetText(i18n.Tf("gallery.page", page+1, g.pages()))
+	g.prevBtn.Show()
+	g.nextBtn.Show()
+	if page == 0 {
+		g.prevBtn.Disable()
+	} else {
+		g.prevBtn.Enable()
+	}
+	if page >= g.pages()-1 {
+		g.nextBtn.Disable()
+	} else {
+		g.nextBtn.Enable()
+	}
+}
+
+// thumbnail previews an image or video, or names any other file
+func (g *mediaGallery) thumbnail(filePath string) fyne.CanvasObject {
+	if g.coreApp.IsMediaFileFromUI(filePath) {
+		return NewMediaPreview(g.coreApp, filePath, galleryThumbSize, galleryThumbSize).Container()
+	}
+	name := widget.NewLabel(filepath.Base(filePath))
+	name.Truncation = fyne.TextTruncateEllipsis
+	return name
+}
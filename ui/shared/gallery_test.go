@@ -0,0 +1,56 @@
+package shared
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/internal/storage"
+)
+
+func TestMediaGalleryPages(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	core := &messagesCoreApp{manager: message.NewManager(db, nullTox{}, nil)}
+
+	// Attach a file to more messages than fit on one page
+	for i := 0; i < galleryPageSize+3; i++ {
+		msg := core.manager.HandleIncomingMessage(3, fmt.Sprintf("file %d", i), message.MessageTypeFile)
+		if msg == nil {
+			t.Fatal("Failed to store message")
+		}
+		if _, err := db.Exec(`UPDATE messages SET file_path = ? WHERE id = ?`, fmt.Sprintf("/tmp/file%d.pdf", i), msg.ID); err != nil {
+			t.Fatalf("Failed to attach file: %v", err)
+		}
+	}
+
+	g := newMediaGallery(core, 3)
+	if len(g.grid.Objects) != galleryPageSize {
+		t.Errorf("Expected a full first page, got %d files", len(g.grid.Objects))
+	}
+	if !g.prevBtn.Disabled() || g.nextBtn.Disabled() {
+		t.Error("Expected only the next button enabled on the first page")
+	}
+
+	test.Tap(g.nextBtn)
+	if len(g.grid.Objects) != 3 {
+		t.Errorf("Expected 3 files on the last page, got %d", len(g.grid.Objects))
+	}
+	if g.prevBtn.Disabled() || !g.nextBtn.Disabled() {
+		t.Error("Expected only the previous button enabled on the last page")
+	}
+
+	empty := newMediaGallery(core, 4)
+	if len(empty.grid.Objects) != 0 || empty.nextBtn.Visible() {
+		t.Error("Expected an empty gallery without paging")
+	}
+}
@@ -0,0 +1,292 @@
+package shared
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/group"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// groupHistoryLimit is how many recent messages a group chat shows
+const groupHistoryLimit = 200
+
+// GroupChatView shows a group conversation. It follows ChatView but only
+// handles text, since group events carry no files or reactions.
+type GroupChatView struct {
+	container    *fyne.Container
+	messages     *widget.List
+	input        *widget.Entry
+	sendBtn      *widget.Button
+	inviteBtn    *widget.Button
+	leaveBtn     *widget.Button
+	title        *widget.Label
+	membersLabel *widget.Label
+	coreApp      CoreApp
+	currentGroup string
+	messageData  []*group.Message
+	names        map[string]string // Display names by member public key
+
+	parentWindow fyne.Window
+	onLeave      func()
+}
+
+// NewGroupChatView creates a new group chat view
+func NewGroupChatView(coreApp CoreApp) *GroupChatView {
+	gv := &GroupChatView{coreApp: coreApp}
+	gv.initializeComponents()
+	return gv
+}
+
+// initializeComponents initializes the group chat view components
+func (gv *GroupChatView) initializeComponents() {
+	gv.messages = widget.NewList(
+		func() int { return len(gv.messageData) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("Template")
+			label.Wrapping = fyne.TextWrapWord
+			return label
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			if i < len(gv.messageData) {
+				o.(*widget.Label).SetText(gv.formatMessage(gv.messageData[i]))
+			}
+		},
+	)
+
+	gv.title = widget.NewLabel("")
+	gv.title.TextStyle = fyne.TextStyle{Bold: true}
+	gv.membersLabel = widget.NewLabel("")
+	gv.membersLabel.Wrapping = fyne.TextWrapWord
+
+	gv.inviteBtn = widget.NewButton(i18n.T("group.invite"), gv.showInviteDialog)
+	gv.leaveBtn = widget.NewButton(i18n.T("group.leave"), gv.confirmLeave)
+	gv.leaveBtn.Importance = widget.LowImportance
+
+	gv.input = widget.NewEntry()
+	gv.input.SetPlaceHolder(i18n.T("group.message_placeholder"))
+	gv.input.OnSubmitted = func(string) {
+		gv.sendMessage()
+	}
+	gv.sendBtn = widget.NewButton(i18n.T("chat.send"), gv.sendMessage)
+
+	header := container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(gv.inviteBtn, gv.leaveBtn), gv.title),
+		gv.membersLabel,
+	)
+	inputContainer := container.NewBorder(nil, nil, nil, gv.sendBtn, gv.input)
+
+	gv.container = container.NewBorder(header, inputContainer, nil, nil, gv.messages)
+}
+
+// groupManager returns the group manager, or nil if groups are unavailable
+func (gv *GroupChatView) groupManager() *group.Manager {
+	if gv.coreApp == nil {
+		return nil
+	}
+	return gv.coreApp.GetGroups()
+}
+
+// SetCurrentGroup shows a group's conversation
+func (gv *GroupChatView) SetCurrentGroup(groupID string) {
+	gv.currentGroup = groupID
+	gv.input.SetText("")
+	gv.reload()
+}
+
+// CurrentGroup returns the group whose conversation is shown, or "" if none
+func (gv *GroupChatView) CurrentGroup() string {
+	return gv.currentGroup
+}
+
+// RefreshGroup reloads the open conversation if it is the given group
+func (gv *GroupChatView) RefreshGroup(groupID string) {
+	if groupID == gv.currentGroup {
+		gv.reload()
+	}
+}
+
+// reload loads the members and recent messages of the open group
+func (gv *GroupChatView) reload() {
+	groups := gv.groupManager()
+	if groups == nil || gv.currentGroup == "" {
+		return
+	}
+
+	g, err := groups.GetGroup(gv.currentGroup)
+	if err != nil {
+		log.Printf("Failed to load group: %v", err)
+		return
+	}
+	gv.title.SetText(g.Name)
+	if g.IsJoined {
+		gv.input.Enable()
+		gv.sendBtn.Enable()
+		gv.inviteBtn.Enable()
+		gv.leaveBtn.Enable()
+	} else {
+		gv.input.Disable()
+		gv.sendBtn.Disable()
+		gv.inviteBtn.Disable()
+		gv.leaveBtn.Disable()
+	}
+
+	names, err := groups.DisplayNames(gv.currentGroup)
+	if err != nil {
+		log.Printf("Failed to load group members: %v", err)
+	}
+	gv.names = names
+	gv.membersLabel.SetText(gv.memberSummary())
+
+	messages, err := groups.GetMessages(gv.currentGroup, groupHistoryLimit, 0)
+	if err != nil {
+		log.Printf("Failed to load group messages: %v", err)
+		return
+	}
+	// Stored newest first, shown oldest first
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	gv.messageData = messages
+	gv.messages.Refresh()
+	if len(messages) > 0 {
+		gv.messages.ScrollToBottom()
+	}
+}
+
+// memberSummary lists the active members of the open group
+func (gv *GroupChatView) memberSummary() string {
+	members, err := gv.groupManager().GetMembers(gv.currentGroup)
+	if err != nil {
+		return ""
+	}
+	var active []string
+	for _, member := range members {
+		if !member.HasLeft {
+			active = append(active, gv.names[member.PublicKey])
+		}
+	}
+	return i18n.Tf("group.members", strings.Join(active, ", "))
+}
+
+// formatMessage renders a group message with its sender and time
+func (gv *GroupChatView) formatMessage(msg *group.Message) string {
+	sender := i18n.T("group.you")
+	if !msg.IsOutgoing {
+		sender = gv.names[msg.SenderKey]
+		if sender == "" {
+			sender = strings.ToUpper(msg.SenderKey[:min(8, len(msg.SenderKey))])
+		}
+	}
+	return fmt.Sprintf("[%s] %s: %s", msg.Timestamp.Local().Format("15:04"), sender, msg.Content)
+}
+
+// sendMessage sends the input to the open group
+func (gv *GroupChatView) sendMessage() {
+	groups := gv.groupManager()
+	text := strings.TrimSpace(gv.input.Text)
+	if groups == nil || gv.currentGroup == "" || text == "" {
+		return
+	}
+
+	if _, err := groups.SendGroupMessage(gv.currentGroup, text); err != nil {
+		gv.showError(fmt.Errorf("failed to send group message: %w", err))
+		return
+	}
+	gv.input.SetText("")
+	gv.reload()
+}
+
+// showInviteDialog picks a friend to invite to the open group
+func (gv *GroupChatView) showInviteDialog() {
+	groups := gv.groupManager()
+	if gv.parentWindow == nil || groups == nil || gv.currentGroup == "" || gv.coreApp.GetContacts() == nil {
+		return
+	}
+
+	contacts := gv.coreApp.GetContacts().GetAllContacts()
+	if len(contacts) == 0 {
+		dialog.ShowInformation(i18n.T("group.invite_title"), i18n.T("group.add_friend_first"), gv.parentWindow)
+		return
+	}
+	labels := make([]string, len(contacts))
+	for i, c := range contacts {
+		labels[i] = inviteeLabel(c)
+	}
+	selectFriend := widget.NewSelect(labels, nil)
+
+	items := []*widget.FormItem{widget.NewFormItem(i18n.T("group.friend"), selectFriend)}
+	groupID := gv.currentGroup
+	dialog.ShowForm(i18n.T("group.invite_title"), i18n.T("group.invite"), i18n.T("common.cancel"), items, func(ok bool) {
+		index := selectFriend.SelectedIndex()
+		if !ok || index < 0 {
+			return
+		}
+		if err := groups.InviteFriend(groupID, contacts[index].FriendID); err != nil {
+			gv.showError(fmt.Errorf("failed to invite friend: %w", err))
+		}
+	}, gv.parentWindow)
+}
+
+// inviteeLabel names a friend in the invite picker
+func inviteeLabel(c *contact.Contact) string {
+	name := c.DisplayName()
+	if name == "" || name == "Unknown" {
+		name = i18n.Tf("contacts.fallback_name", c.FriendID)
+	}
+	return name
+}
+
+// confirmLeave leaves the open group after confirmation
+func (gv *GroupChatView) confirmLeave() {
+	groups := gv.groupManager()
+	if gv.parentWindow == nil || groups == nil || gv.currentGroup == "" {
+		return
+	}
+
+	groupID := gv.currentGroup
+	dialog.ShowConfirm(i18n.T("group.leave_title"), i18n.T("group.leave_confirm"), func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := groups.LeaveGroup(groupID); err != nil {
+			gv.showError(fmt.Errorf("failed to leave group: %w", err))
+			return
+		}
+		gv.reload()
+		if gv.onLeave != nil {
+			gv.onLeave()
+		}
+	}, gv.parentWindow)
+}
+
+// SetParentWindow sets the parent window for dialogs
+func (gv *GroupChatView) SetParentWindow(window fyne.Window) {
+	gv.parentWindow = window
+}
+
+// SetOnLeave sets the callback invoked after the user leaves a group
+func (gv *GroupChatView) SetOnLeave(callback func()) {
+	gv.onLeave = callback
+}
+
+// Container returns the group chat view container
+func (gv *GroupChatView) Container() *fyne.Container {
+	return gv.container
+}
+
+// showError shows an error dialog, or logs it without a window
+func (gv *GroupChatView) showError(err error) {
+	if gv.parentWindow == nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	dialog.ShowError(err, gv.parentWindow)
+}
@@ -0,0 +1,196 @@
+package shared
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/group"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// groupSection is the Groups part of the contact list
+type groupSection struct {
+	container  *fyne.Container
+	list       *widget.List
+	invitesBtn *widget.Button // Opens pending group invites, hidden when empty
+	data       []*group.Group
+	onSelect   func(groupID string)
+}
+
+// newGroupSection creates the Groups part of the contact list
+func (cl *ContactList) newGroupSection() *groupSection {
+	gs := &groupSection{}
+	gs.list = widget.NewList(
+		func() int { return len(gs.data) },
+		func() fyne.CanvasObject {
+			return widget.NewButton("Group", nil)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			if i >= len(gs.data) {
+				return
+			}
+			g := gs.data[i]
+			button := o.(*widget.Button)
+			label := g.Name
+			if !g.IsJoined {
+				label += i18n.T("groups.left_suffix")
+			}
+			button.SetText(label)
+			button.OnTapped = func() {
+				if gs.onSelect != nil {
+					gs.onSelect(g.ID)
+				}
+			}
+		},
+	)
+
+	newGroupBtn := widget.NewButton(i18n.T("groups.new"), cl.ShowNewGroupDialog)
+
+	gs.invitesBtn = widget.NewButton(i18n.T("groups.invites"), cl.ShowGroupInvitesDialog)
+	gs.invitesBtn.Importance = widget.HighImportance
+	gs.invitesBtn.Hide()
+
+	gs.container = container.NewVBox(
+		widget.NewLabel(i18n.T("groups.title")),
+		newGroupBtn,
+		gs.invitesBtn,
+		gs.list,
+	)
+	return gs
+}
+
+// groupManager returns the group manager, or nil if groups are unavailable
+func (cl *ContactList) groupManager() *group.Manager {
+	if cl.coreApp == nil {
+		return nil
+	}
+	return cl.coreApp.GetGroups()
+}
+
+// RefreshGroups reloads the groups and the number of waiting invites
+func (cl *ContactList) RefreshGroups() {
+	groups := cl.groupManager()
+	if groups == nil {
+		cl.groups.container.Hide()
+		return
+	}
+	cl.groups.container.Show()
+
+	data, err := groups.GetGroups()
+	if err != nil {
+		log.Printf("Failed to load groups: %v", err)
+	}
+	cl.groups.data = data
+	cl.groups.list.Refresh()
+
+	if count := len(groups.GetInvites()); count > 0 {
+		cl.groups.invitesBtn.SetText(i18n.Tf("groups.invites_count", count))
+		cl.groups.invitesBtn.Show()
+	} else {
+		cl.groups.invitesBtn.Hide()
+	}
+}
+
+// SetOnGroupSelect sets the callback for group selection
+func (cl *ContactList) SetOnGroupSelect(callback func(groupID string)) {
+	cl.groups.onSelect = callback
+}
+
+// ShowNewGroupDialog asks for a name and creates a group
+func (cl *ContactList) ShowNewGroupDialog() {
+	groups := cl.groupManager()
+	if cl.parentWindow == nil || groups == nil {
+		return
+	}
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder(i18n.T("groups.name_placeholder"))
+	items := []*widget.FormItem{widget.NewFormItem(i18n.T("details.name"), nameEntry)}
+
+	dialog.ShowForm(i18n.T("groups.new"), i18n.T("groups.create"), i18n.T("common.cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		created, err := groups.CreateGroup(nameEntry.Text)
+		if err != nil {
+			cl.showErrorDialog(i18n.Tf("groups.create_failed", err))
+			return
+		}
+		cl.RefreshGroups()
+		if cl.groups.onSelect != nil {
+			cl.groups.onSelect(created.ID)
+		}
+	}, cl.parentWindow)
+}
+
+// ShowGroupInvitesDialog lists the waiting group invites with buttons to
+// join or decline each one
+func (cl *ContactList) ShowGroupInvitesDialog() {
+	groups := cl.groupManager()
+	if cl.parentWindow == nil || groups == nil {
+		return
+	}
+
+	list := container.NewVBox()
+	var render func()
+	render = func() {
+		list.RemoveAll()
+		invites := groups.GetInvites()
+		if len(invites) == 0 {
+			list.Add(widget.NewLabel(i18n.T("groups.no_invites")))
+		}
+		for _, invite := range invites {
+			list.Add(cl.inviteRow(invite, render))
+			list.Add(widget.NewSeparator())
+		}
+		cl.RefreshGroups()
+	}
+	render()
+
+	d := dialog.NewCustom(i18n.T("groups.invites"), i18n.T("common.close"), container.NewVScroll(list), cl.parentWindow)
+	d.Resize(fyne.NewSize(450, 350))
+	d.Show()
+}
+
+// inviteRow shows one group invite; done is called once it was answered
+func (cl *ContactList) inviteRow(invite *group.Invite, done func()) fyne.CanvasObject {
+	groups := cl.groupManager()
+	groupID := invite.GroupID
+
+	inviter := i18n.Tf("contacts.fallback_name", invite.FriendID)
+	if contacts := cl.coreApp.GetContacts(); contacts != nil {
+		if value, ok := contacts.GetContact(invite.FriendID); ok {
+			if c, ok := value.(*contact.Contact); ok && c.DisplayName() != "" {
+				inviter = c.DisplayName()
+			}
+		}
+	}
+	inviter = cl.presentation.DisplayName(invite.FriendID, inviter)
+	title := widget.NewLabel(invite.Name)
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	details := widget.NewLabel(i18n.Tf("groups.invited_by", inviter, len(invite.Members)))
+
+	joinBtn := widget.NewButton(i18n.T("groups.join"), func() {
+		if _, err := groups.JoinGroupByID(groupID); err != nil {
+			cl.showErrorDialog(i18n.Tf("groups.join_failed", err))
+			return
+		}
+		done()
+	})
+	joinBtn.Importance = widget.HighImportance
+
+	declineBtn := widget.NewButton(i18n.T("groups.decline"), func() {
+		groups.DeclineInvite(groupID)
+		done()
+	})
+
+	return container.NewVBox(
+		title,
+		container.NewBorder(nil, nil, details, container.NewHBox(declineBtn, joinBtn)),
+	)
+}
@@ -0,0 +1,135 @@
+package shared
+
+import (
+	"github.com/opd-ai/whisp/internal/core/message"
+)
+
+// DefaultMessageWindowSize is the number of messages kept in memory per conversation
+const DefaultMessageWindowSize = 200
+
+// messagePageSize is how many messages are loaded from the database at a time
+const messagePageSize = 50
+
+// MessageLoader loads up to limit messages, newest first, skipping the newest offset
+type MessageLoader func(limit, offset int) ([]*message.Message, error)
+
+// MessageWindow keeps a bounded slice of a conversation's history in memory.
+// Messages are held newest first, as returned by message.Manager.GetMessages.
+// Loading older messages drops the newest ones from memory once the window is
+// full, and loading newer messages drops the oldest; both stay in the database.
+type MessageWindow struct {
+	load     MessageLoader
+	maxSize  int
+	pageSize int
+
+	messages []*message.Message
+	offset   int  // Newer messages not held in memory
+	atOldest bool // The oldest message of the conversation is held
+}
+
+// NewMessageWindow creates a window holding at most maxSize messages.
+// Non-positive sizes fall back to the defaults.
+func NewMessageWindow(load MessageLoader, maxSize, pageSize int) *MessageWindow {
+	if pageSize <= 0 {
+		pageSize = messagePageSize
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMessageWindowSize
+	}
+	if maxSize < pageSize {
+		maxSize = pageSize
+	}
+
+	return &MessageWindow{
+		load:     load,
+		maxSize:  maxSize,
+		pageSize: pageSize,
+	}
+}
+
+// Reset loads the newest page of the conversation, discarding the current window
+func (w *MessageWindow) Reset() error {
+	w.messages = nil
+	w.offset = 0
+	w.atOldest = false
+
+	page, err := w.load(w.pageSize, 0)
+	if err != nil {
+		return err
+	}
+	w.messages = page
+	w.atOldest = len(page) < w.pageSize
+	return nil
+}
+
+// Messages returns the messages currently held, newest first
+func (w *MessageWindow) Messages() []*message.Message {
+	return w.messages
+}
+
+// Offset returns how many newer messages are not held in memory. A message's
+// position in the conversation is its index in Messages plus Offset.
+func (w *MessageWindow) Offset() int {
+	return w.offset
+}
+
+// HasOlder reports whether older messages can still be loaded
+func (w *MessageWindow) HasOlder() bool {
+	return !w.atOldest
+}
+
+// HasNewer reports whether newer messages were dropped and can be reloaded
+func (w *MessageWindow) HasNewer() bool {
+	return w.offset > 0
+}
+
+// LoadOlder appends the next page of older messages, dropping the newest held
+// messages if the window overflows. It returns the number of messages loaded.
+func (w *MessageWindow) LoadOlder() (int, error) {
+	if w.atOldest {
+		return 0, nil
+	}
+
+	page, err := w.load(w.pageSize, w.offset+len(w.messages))
+	if err != nil {
+		return 0, err
+	}
+	if len(page) < w.pageSize {
+		w.atOldest = true
+	}
+
+	w.messages = append(w.messages, page...)
+	if overflow := len(w.messages) - w.maxSize; overflow > 0 {
+		w.messages = append([]*message.Message(nil), w.messages[overflow:]...)
+		w.offset += overflow
+	}
+
+	return len(page), nil
+}
+
+// LoadNewer prepends the page of newer messages that were dropped, discarding
+// the oldest held messages if the window overflows. It returns the number of
+// messages loaded.
+func (w *MessageWindow) LoadNewer() (int, error) {
+	if w.offset == 0 {
+		return 0, nil
+	}
+
+	limit := w.pageSize
+	if limit > w.offset {
+		limit = w.offset
+	}
+	page, err := w.load(limit, w.offset-limit)
+	if err != nil {
+		return 0, err
+	}
+
+	w.messages = append(page, w.messages...)
+	w.offset -= len(page)
+	if len(w.messages) > w.maxSize {
+		w.messages = append([]*message.Message(nil), w.messages[:w.maxSize]...)
+		w.atOldest = false
+	}
+
+	return len(page), nil
+}
@@ -0,0 +1,260 @@
+package shared
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/opd-ai/whisp/internal/core/message"
+)
+
+// fakeHistory serves a conversation of total messages the way GetMessages does:
+// newest first, with message IDs counting up from the oldest
+type fakeHistory struct {
+	total int
+	loads int
+}
+
+func (h *fakeHistory) load(limit, offset int) ([]*message.Message, error) {
+	h.loads++
+	var page []*message.Message
+	for i := offset; i < offset+limit && i < h.total; i++ {
+		page = append(page, &message.Message{ID: int64(h.total - i)})
+	}
+	return page, nil
+}
+
+// ids returns the IDs of the held messages
+func ids(messages []*message.Message) []int64 {
+	out := make([]int64, len(messages))
+	for i, msg := range messages {
+		out[i] = msg.ID
+	}
+	return out
+}
+
+// assertWindow checks the held messages are the contiguous run newest..newest-count+1
+func assertWindow(t *testing.T, w *MessageWindow, newest int64, count int) {
+	t.Helper()
+
+	got := ids(w.Messages())
+	if len(got) != count {
+		t.Fatalf("Expected %d messages held, got %d: %v", count, len(got), got)
+	}
+	for i, id := range got {
+		if want := newest - int64(i); id != want {
+			t.Fatalf("Expected message %d at index %d, got %v", want, i, got)
+		}
+	}
+}
+
+func TestMessageWindowCapsSize(t *testing.T) {
+	history := &fakeHistory{total: 500}
+	w := NewMessageWindow(history.load, 100, 25)
+
+	if err := w.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	assertWindow(t, w, 500, 25)
+
+	for i := 0; i < 10; i++ {
+		if _, err := w.LoadOlder(); err != nil {
+			t.Fatalf("LoadOlder failed: %v", err)
+		}
+		if n := len(w.Messages()); n > 100 {
+			t.Fatalf("Window grew past its cap: %d", n)
+		}
+	}
+
+	// 11 pages of 25 were loaded; the newest 175 were dropped from memory
+	assertWindow(t, w, 325, 100)
+	if w.Offset() != 175 || !w.HasNewer() {
+		t.Errorf("Expected offset 175 with newer messages, got %d", w.Offset())
+	}
+}
+
+func TestMessageWindowReloadsDroppedMessages(t *testing.T) {
+	history := &fakeHistory{total: 300}
+	w := NewMessageWindow(history.load, 60, 20)
+	if err := w.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+
+	// Scroll back until the newest messages fall out of the window
+	for i := 0; i < 8; i++ {
+		if _, err := w.LoadOlder(); err != nil {
+			t.Fatalf("LoadOlder failed: %v", err)
+		}
+	}
+	assertWindow(t, w, 180, 60)
+	if w.Offset() != 120 {
+		t.Errorf("Expected offset 120, got %d", w.Offset())
+	}
+
+	// Scrolling towards the present reloads them and drops the oldest instead
+	tests := []struct {
+		newest int64
+		offset int
+	}{
+		{200, 100},
+		{220, 80},
+		{240, 60},
+		{260, 40},
+		{280, 20},
+		{300, 0},
+	}
+	for _, tt := range tests {
+		n, err := w.LoadNewer()
+		if err != nil {
+			t.Fatalf("LoadNewer failed: %v", err)
+		}
+		if n != 20 {
+			t.Errorf("Expected 20 messages loaded, got %d", n)
+		}
+		assertWindow(t, w, tt.newest, 60)
+		if w.Offset() != tt.offset {
+			t.Errorf("Expected offset %d, got %d", tt.offset, w.Offset())
+		}
+	}
+
+	if w.HasNewer() {
+		t.Error("Expected no newer messages at the end of the conversation")
+	}
+	if n, _ := w.LoadNewer(); n != 0 {
+		t.Errorf("Expected nothing to load past the newest message, got %d", n)
+	}
+
+	// The oldest messages were dropped, so they can be loaded again
+	if !w.HasOlder() {
+		t.Error("Expected older messages to be reloadable")
+	}
+	if _, err := w.LoadOlder(); err != nil {
+		t.Fatalf("LoadOlder failed: %v", err)
+	}
+	assertWindow(t, w, 280, 60)
+}
+
+func TestMessageWindowReachesOldest(t *testing.T) {
+	history := &fakeHistory{total: 45}
+	w := NewMessageWindow(history.load, 100, 20)
+	if err := w.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+
+	for w.HasOlder() {
+		if _, err := w.LoadOlder(); err != nil {
+			t.Fatalf("LoadOlder failed: %v", err)
+		}
+	}
+	assertWindow(t, w, 45, 45)
+
+	loads := history.loads
+	if n, _ := w.LoadOlder(); n != 0 || history.loads != loads {
+		t.Error("Expected no database load once the oldest message is held")
+	}
+}
+
+func TestMessageWindowDefaults(t *testing.T) {
+	tests := []struct {
+		maxSize, pageSize         int
+		wantMaxSize, wantPageSize int
+	}{
+		{0, 0, DefaultMessageWindowSize, messagePageSize},
+		{-5, 10, DefaultMessageWindowSize, 10},
+		{10, 50, 50, 50}, // The window always fits a page
+	}
+
+	for _, tt := range tests {
+		w := NewMessageWindow((&fakeHistory{}).load, tt.maxSize, tt.pageSize)
+		if w.maxSize != tt.wantMaxSize || w.pageSize != tt.wantPageSize {
+			t.Errorf("NewMessageWindow(%d, %d) = (%d, %d), want (%d, %d)",
+				tt.maxSize, tt.pageSize, w.maxSize, w.pageSize, tt.wantMaxSize, tt.wantPageSize)
+		}
+	}
+}
+
+func TestMessageWindowLoadError(t *testing.T) {
+	history := &fakeHistory{total: 100}
+	fail := false
+	w := NewMessageWindow(func(limit, offset int) ([]*message.Message, error) {
+		if fail {
+			return nil, fmt.Errorf("database closed")
+		}
+		return history.load(limit, offset)
+	}, 40, 20)
+
+	if err := w.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+
+	fail = true
+	if _, err := w.LoadOlder(); err == nil {
+		t.Error("Expected load error")
+	}
+	assertWindow(t, w, 100, 20)
+}
+
+// waitForHistoryLoad waits until no history page is being loaded
+func waitForHistoryLoad(t *testing.T, cv *ChatView) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		cv.historyMu.Lock()
+		loading := cv.loadingHistory
+		cv.historyMu.Unlock()
+		if !loading {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for history to load")
+}
+
+func TestChatViewScrollLoadsOlderHistory(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	cv := NewChatView(&MockCoreApp{})
+	history := &fakeHistory{total: 120}
+	cv.history = NewMessageWindow(history.load, 200, 50)
+	if err := cv.history.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	cv.messageData = cv.history.Messages()
+
+	// Rows other than the oldest loaded one do not load anything
+	cv.maybeLoadMoreHistory(10)
+	waitForHistoryLoad(t, cv)
+	if history.loads != 1 {
+		t.Fatalf("Expected no load for a middle row, got %d loads", history.loads)
+	}
+
+	// A load already in progress is not repeated
+	cv.loadingHistory = true
+	cv.maybeLoadMoreHistory(len(cv.messageData) - 1)
+	cv.loadingHistory = false
+	if history.loads != 1 {
+		t.Fatalf("Expected no load while another is running, got %d loads", history.loads)
+	}
+
+	for _, want := range []int{100, 120} {
+		cv.maybeLoadMoreHistory(len(cv.messageData) - 1)
+		waitForHistoryLoad(t, cv)
+		if len(cv.messageData) != want {
+			t.Fatalf("Expected %d messages after scrolling, got %d", want, len(cv.messageData))
+		}
+	}
+	if got := cv.messageData[len(cv.messageData)-1].ID; got != 1 {
+		t.Errorf("Expected the oldest message last, got ID %d", got)
+	}
+
+	// Once the oldest message is shown, scrolling stops loading
+	loads := history.loads
+	cv.maybeLoadMoreHistory(len(cv.messageData) - 1)
+	waitForHistoryLoad(t, cv)
+	if history.loads != loads {
+		t.Errorf("Expected no further loads at the start of the conversation, got %d", history.loads-loads)
+	}
+}
@@ -0,0 +1,103 @@
+package shared
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/linkpreview"
+	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// linkPreviewThumbSize is the size of the image on a preview card
+const linkPreviewThumbSize = 64
+
+// createLinkPreview adds a preview card for the first web link in a
+// message, or a button to load one when previews are fetched on request
+func (cv *ChatView) createLinkPreview(row *fyne.Container, msg *message.Message) {
+	if cv.presentation.Enabled() {
+		return
+	}
+	rawURL := firstWebLink(msg.Content)
+	if rawURL == "" {
+		return
+	}
+	mode := cv.coreApp.LinkPreviewMode(msg.FriendID, msg.IsOutgoing)
+	if mode == linkpreview.ModeOff {
+		return
+	}
+
+	if preview, done := cv.coreApp.CachedLinkPreview(rawURL); done {
+		if preview != nil {
+			row.Add(newLinkPreviewCard(preview))
+		}
+		return
+	}
+	if mode == linkpreview.ModeAuto {
+		cv.fetchLinkPreview(rawURL)
+		return
+	}
+
+	load := widget.NewButton(i18n.T("chat.load_preview"), func() { cv.fetchLinkPreview(rawURL) })
+	load.Importance = widget.LowImportance
+	row.Add(container.NewHBox(load))
+}
+
+// fetchLinkPreview fetches a preview off the UI thread and redraws the
+// messages once it is in
+func (cv *ChatView) fetchLinkPreview(rawURL string) {
+	go func() {
+		if _, err := cv.coreApp.FetchLinkPreview(rawURL); err != nil {
+			log.Printf("No link preview for %s: %v", rawURL, err)
+		}
+		cv.messages.Refresh()
+	}()
+}
+
+// firstWebLink returns the first http or https link in text
+func firstWebLink(text string) string {
+	for _, link := range findLinks(text) {
+		if link.url.Scheme == "http" || link.url.Scheme == "https" {
+			return text[link.start:link.end]
+		}
+	}
+	return ""
+}
+
+// newLinkPreviewCard shows the site, title, description and image of a page
+func newLinkPreviewCard(preview *linkpreview.Preview) fyne.CanvasObject {
+	text := container.NewVBox()
+	if preview.SiteName != "" {
+		site := widget.NewLabel(preview.SiteName)
+		site.Importance = widget.LowImportance
+		site.Truncation = fyne.TextTruncateEllipsis
+		text.Add(site)
+	}
+	if preview.Title != "" {
+		title := widget.NewLabel(preview.Title)
+		title.TextStyle = fyne.TextStyle{Bold: true}
+		title.Truncation = fyne.TextTruncateEllipsis
+		text.Add(title)
+	}
+	if preview.Description != "" {
+		description := widget.NewLabel(preview.Description)
+		description.Wrapping = fyne.TextWrapWord
+		text.Add(description)
+	}
+
+	var thumbnail fyne.CanvasObject
+	if len(preview.Image) > 0 {
+		image := canvas.NewImageFromResource(fyne.NewStaticResource(preview.ImageURL, preview.Image))
+		image.FillMode = canvas.ImageFillContain
+		image.SetMinSize(fyne.NewSize(linkPreviewThumbSize, linkPreviewThumbSize))
+		thumbnail = image
+	}
+
+	background := canvas.NewRectangle(theme.InputBackgroundColor())
+	return container.NewStack(background, container.NewPadded(container.NewBorder(nil, nil, thumbnail, nil, text)))
+}
@@ -0,0 +1,100 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/linkpreview"
+	"github.com/opd-ai/whisp/internal/core/message"
+)
+
+// previewCoreApp is a MockCoreApp with canned link previews
+type previewCoreApp struct {
+	MockCoreApp
+	mode     linkpreview.Mode
+	previews map[string]*linkpreview.Preview
+}
+
+func (p *previewCoreApp) LinkPreviewMode(friendID uint32, outgoing bool) linkpreview.Mode {
+	return p.mode
+}
+
+func (p *previewCoreApp) CachedLinkPreview(rawURL string) (*linkpreview.Preview, bool) {
+	preview, ok := p.previews[rawURL]
+	return preview, ok
+}
+
+func TestFirstWebLink(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"no links", ""},
+		{"see https://a.example/x, then https://b.example", "https://a.example/x"},
+		{"tox:" + strings.Repeat("C3", 38) + " or http://c.example.", "http://c.example"},
+	}
+
+	for _, tt := range tests {
+		if got := firstWebLink(tt.text); got != tt.want {
+			t.Errorf("firstWebLink(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCreateLinkPreview(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	core := &previewCoreApp{previews: map[string]*linkpreview.Preview{
+		"https://cached.example/": {URL: "https://cached.example/", Title: "Cached page", SiteName: "cached.example"},
+	}}
+	cv := NewChatView(core)
+
+	tests := []struct {
+		name       string
+		mode       linkpreview.Mode
+		content    string
+		wantCard   bool
+		wantButton bool
+	}{
+		{"previews off", linkpreview.ModeOff, "https://cached.example/", false, false},
+		{"no link", linkpreview.ModeAuto, "hello", false, false},
+		{"cached preview", linkpreview.ModeManual, "look: https://cached.example/", true, false},
+		{"fetched on request", linkpreview.ModeManual, "https://new.example/", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core.mode = tt.mode
+			row := &fyne.Container{}
+			cv.createLinkPreview(row, &message.Message{FriendID: 1, Content: tt.content})
+
+			var card, button bool
+			for _, obj := range row.Objects {
+				if box, ok := obj.(*fyne.Container); ok && len(box.Objects) == 1 {
+					_, button = box.Objects[0].(*widget.Button)
+				} else if obj != nil {
+					card = true
+				}
+			}
+			if card != tt.wantCard || button != tt.wantButton {
+				t.Errorf("Expected card %v and button %v, got %v and %v", tt.wantCard, tt.wantButton, card, button)
+			}
+		})
+	}
+
+	// Presenting hides previews
+	core.mode = linkpreview.ModeAuto
+	presentation := NewPresentationMode()
+	cv.SetPresentationMode(presentation)
+	presentation.SetEnabled(true)
+	row := &fyne.Container{}
+	cv.createLinkPreview(row, &message.Message{FriendID: 1, Content: "https://cached.example/"})
+	if len(row.Objects) != 0 {
+		t.Error("Expected no preview while presenting")
+	}
+}
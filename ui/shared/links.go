@@ -0,0 +1,109 @@
+package shared
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// linkPattern finds web links and tox: IDs; trimLink then drops trailing
+// punctuation that belongs to the sentence
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+|\btox:[0-9a-f]{76}\b`)
+
+// textLink is a link found in message text, by byte offsets
+type textLink struct {
+	start, end int
+	url        *url.URL
+}
+
+// findLinks returns the links in text, in order
+func findLinks(text string) []textLink {
+	var links []textLink
+	for _, match := range linkPattern.FindAllStringIndex(text, -1) {
+		start, end := match[0], match[0]+len(trimLink(text[match[0]:match[1]]))
+		u, err := url.Parse(text[start:end])
+		if err != nil || (u.Scheme != "tox" && u.Host == "") {
+			continue
+		}
+		links = append(links, textLink{start: start, end: end, url: u})
+	}
+	return links
+}
+
+// trimLink drops punctuation ending a sentence after a link, and closing
+// brackets that the link did not open
+func trimLink(link string) string {
+	for link != "" {
+		last := link[len(link)-1]
+		switch last {
+		case '.', ',', ';', ':', '!', '?', '\'', '*':
+			link = link[:len(link)-1]
+			continue
+		case ')', ']', '}':
+			open := map[byte]string{')': "(", ']': "[", '}': "{"}[last]
+			if strings.Count(link, open) < strings.Count(link, string(last)) {
+				link = link[:len(link)-1]
+				continue
+			}
+		}
+		return link
+	}
+	return link
+}
+
+// linkSegments splits text into plain runs and tappable links. tox: links
+// go to onToxLink; web links open in the browser. With clickable false the
+// text stays plain.
+func linkSegments(text string, clickable bool, onToxLink func(toxID string)) []widget.RichTextSegment {
+	links := findLinks(text)
+	if !clickable || len(links) == 0 {
+		return []widget.RichTextSegment{&widget.TextSegment{Text: text, Style: widget.RichTextStyleInline}}
+	}
+
+	var segments []widget.RichTextSegment
+	pos := 0
+	for _, link := range links {
+		if link.start > pos {
+			segments = append(segments, &widget.TextSegment{Text: text[pos:link.start], Style: widget.RichTextStyleInline})
+		}
+		segment := &widget.HyperlinkSegment{Text: text[link.start:link.end], URL: link.url}
+		if link.url.Scheme == "tox" {
+			toxID := link.url.Opaque
+			segment.OnTapped = func() {
+				if onToxLink != nil {
+					onToxLink(toxID)
+				}
+			}
+		}
+		segments = append(segments, segment)
+		pos = link.end
+	}
+	if pos < len(text) {
+		segments = append(segments, &widget.TextSegment{Text: text[pos:], Style: widget.RichTextStyleInline})
+	}
+	return segments
+}
+
+// newMessageText shows message text with its links made tappable
+func (cv *ChatView) newMessageText(text string) *widget.RichText {
+	richText := widget.NewRichText(linkSegments(text, cv.linksClickable(), cv.onToxLink)...)
+	richText.Wrapping = fyne.TextWrapWord
+	return richText
+}
+
+// linksClickable reports whether links in messages may be opened
+func (cv *ChatView) linksClickable() bool {
+	if cv.coreApp == nil || cv.coreApp.GetConfigManager() == nil {
+		return true
+	}
+	return cv.coreApp.GetConfigManager().GetConfig().Privacy.OpenLinks
+}
+
+// SetOnToxLink sets the callback invoked when a tox: link in a message is
+// tapped
+func (cv *ChatView) SetOnToxLink(callback func(toxID string)) {
+	cv.onToxLink = callback
+}
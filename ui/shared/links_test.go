@@ -0,0 +1,79 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestFindLinks(t *testing.T) {
+	toxID := strings.Repeat("A1", 38)
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"plain text", "no links here", nil},
+		{"single link", "see https://example.com/page", []string{"https://example.com/page"}},
+		{"trailing period", "Go to http://example.com.", []string{"http://example.com"}},
+		{"trailing punctuation", "really? https://example.com/a?b=1!?", []string{"https://example.com/a?b=1"}},
+		{"parenthesized", "(docs at https://example.com/docs)", []string{"https://example.com/docs"}},
+		{"balanced parentheses", "https://en.wikipedia.org/wiki/Go_(language)", []string{"https://en.wikipedia.org/wiki/Go_(language)"}},
+		{"parenthesized with balanced", "(https://en.wikipedia.org/wiki/Go_(language)).", []string{"https://en.wikipedia.org/wiki/Go_(language)"}},
+		{"multiple links", "https://a.example, and https://b.example; done", []string{"https://a.example", "https://b.example"}},
+		{"quoted", `"https://example.com/x"`, []string{"https://example.com/x"}},
+		{"uppercase scheme", "HTTPS://EXAMPLE.COM", []string{"HTTPS://EXAMPLE.COM"}},
+		{"no host", "https://. is not a link", nil},
+		{"other scheme", "ftp://example.com and javascript:alert(1)", nil},
+		{"inside a word", "xhttps://example.com", nil},
+		{"tox id", "add me: tox:" + toxID + ".", []string{"tox:" + toxID}},
+		{"short tox id", "tox:ABCDEF", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, link := range findLinks(tt.text) {
+				got = append(got, tt.text[link.start:link.end])
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("findLinks(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkSegments(t *testing.T) {
+	toxID := strings.Repeat("B2", 38)
+	text := "see https://example.com, or tox:" + toxID + " later"
+
+	segments := linkSegments(text, true, nil)
+	if len(segments) != 5 {
+		t.Fatalf("Expected 5 segments, got %d", len(segments))
+	}
+	if link, ok := segments[1].(*widget.HyperlinkSegment); !ok || link.URL.String() != "https://example.com" || link.OnTapped != nil {
+		t.Errorf("Expected a web link opened by the browser, got %#v", segments[1])
+	}
+
+	var tapped string
+	segments = linkSegments(text, true, func(id string) { tapped = id })
+	segments[3].(*widget.HyperlinkSegment).OnTapped()
+	if tapped != toxID {
+		t.Errorf("Expected the tox: link to pass on its ID, got %q", tapped)
+	}
+
+	var joined string
+	for _, segment := range segments {
+		joined += segment.Textual()
+	}
+	if joined != text {
+		t.Errorf("Expected the segments to keep the text, got %q", joined)
+	}
+
+	segments = linkSegments(text, false, nil)
+	if len(segments) != 1 {
+		t.Errorf("Expected plain text with links turned off, got %d segments", len(segments))
+	}
+}
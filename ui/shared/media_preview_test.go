@@ -0,0 +1,78 @@
+package shared
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+)
+
+func TestFitSize(t *testing.T) {
+	tests := []struct {
+		width, height       int
+		maxWidth, maxHeight int
+		want                fyne.Size
+	}{
+		{400, 200, 200, 200, fyne.NewSize(200, 100)}, // Wide
+		{200, 400, 200, 200, fyne.NewSize(100, 200)}, // Tall
+		{100, 50, 200, 200, fyne.NewSize(100, 50)},   // Never enlarged
+		{0, 0, 200, 150, fyne.NewSize(200, 150)},     // Unknown size
+		{300, 300, 200, 100, fyne.NewSize(100, 100)},
+	}
+
+	for _, tt := range tests {
+		if got := fitSize(tt.width, tt.height, tt.maxWidth, tt.maxHeight); got != tt.want {
+			t.Errorf("fitSize(%d, %d, %d, %d) = %v, want %v",
+				tt.width, tt.height, tt.maxWidth, tt.maxHeight, got, tt.want)
+		}
+	}
+}
+
+// regeneratingCoreApp writes a thumbnail file whenever one is generated
+type regeneratingCoreApp struct {
+	MockCoreApp
+	thumbnailPath string
+	generated     int
+}
+
+func (m *regeneratingCoreApp) GenerateThumbnailFromUI(filePath string, maxWidth, maxHeight int) (string, error) {
+	m.generated++
+	return m.thumbnailPath, os.WriteFile(m.thumbnailPath, []byte("thumbnail"), 0o644)
+}
+
+func (m *regeneratingCoreApp) GetThumbnailPathFromUI(filePath string, maxWidth, maxHeight int) (string, bool) {
+	_, err := os.Stat(m.thumbnailPath)
+	return m.thumbnailPath, err == nil
+}
+
+func TestMediaPreviewRegeneratesDeletedThumbnail(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	core := &regeneratingCoreApp{thumbnailPath: filepath.Join(t.TempDir(), "thumb.jpg")}
+	preview := NewMediaPreview(core, "photo.jpg", 200, 150)
+	if core.generated != 1 {
+		t.Fatalf("Expected the thumbnail to be generated once, got %d", core.generated)
+	}
+	if _, ok := preview.image.Content.(*tappableImage); !ok {
+		t.Errorf("Expected the thumbnail image in the card, got %T", preview.image.Content)
+	}
+
+	// The cache was pruned after the path was looked up
+	if err := os.Remove(core.thumbnailPath); err != nil {
+		t.Fatalf("Failed to remove thumbnail: %v", err)
+	}
+	if err := preview.ensureThumbnail(); err != nil {
+		t.Fatalf("ensureThumbnail failed: %v", err)
+	}
+	if core.generated != 2 {
+		t.Errorf("Expected the deleted thumbnail to be regenerated, got %d generations", core.generated)
+	}
+
+	// An existing thumbnail is reused
+	if err := preview.ensureThumbnail(); err != nil || core.generated != 2 {
+		t.Errorf("Expected the existing thumbnail to be reused, got %d generations (%v)", core.generated, err)
+	}
+}
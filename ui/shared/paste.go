@@ -0,0 +1,152 @@
+package shared
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // Decode pasted GIF images
+	_ "image/jpeg" // Decode pasted JPEG images
+	"image/png"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// maxPastedPathLength is the longest clipboard text tried as a file path
+const maxPastedPathLength = 4096
+
+// pasteEntry is the message input. Pasting an image offers to send it
+// instead of inserting text.
+type pasteEntry struct {
+	widget.Entry
+	onPaste func(text string) bool // Reports whether it handled the paste
+}
+
+// newPasteEntry creates a message input that passes pasted text to onPaste first
+func newPasteEntry(onPaste func(text string) bool) *pasteEntry {
+	entry := &pasteEntry{onPaste: onPaste}
+	entry.ExtendBaseWidget(entry)
+	return entry
+}
+
+// TypedShortcut lets onPaste handle a paste before the entry inserts text
+func (e *pasteEntry) TypedShortcut(shortcut fyne.Shortcut) {
+	if paste, ok := shortcut.(*fyne.ShortcutPaste); ok && paste.Clipboard != nil && e.onPaste != nil {
+		if e.onPaste(paste.Clipboard.Content()) {
+			return
+		}
+	}
+	e.Entry.TypedShortcut(shortcut)
+}
+
+// pastedImage decodes the image on the clipboard: a data URI, the path or
+// file URI of an image, or when the clipboard has no text, the fallback file
+// a screenshot tool saves to
+func pastedImage(text, fallback string) (image.Image, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		if fallback == "" {
+			return nil, false
+		}
+		text = fallback
+	}
+
+	if strings.HasPrefix(text, "data:image/") {
+		_, data, found := strings.Cut(text, ";base64,")
+		if !found {
+			return nil, false
+		}
+		img, _, err := image.Decode(base64.NewDecoder(base64.StdEncoding, strings.NewReader(data)))
+		return img, err == nil
+	}
+
+	if len(text) > maxPastedPathLength || strings.ContainsAny(text, "\r\n") {
+		return nil, false
+	}
+	if strings.HasPrefix(text, "file://") {
+		uri, err := url.Parse(text)
+		if err != nil {
+			return nil, false
+		}
+		text = uri.Path
+	}
+	if !filepath.IsAbs(text) {
+		return nil, false
+	}
+
+	file, err := os.Open(text)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	return img, err == nil
+}
+
+// savePastedImage writes img to dir as a PNG named after the time it was pasted
+func savePastedImage(img image.Image, dir string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create paste directory: %w", err)
+	}
+
+	path := filepath.Join(dir, "pasted-image-"+now.Format("20060102-150405")+".png")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create pasted image: %w", err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", fmt.Errorf("failed to encode pasted image: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to save pasted image: %w", err)
+	}
+	return path, nil
+}
+
+// pasteImage offers to send an image pasted into the open conversation and
+// reports whether the clipboard held one
+func (cv *ChatView) pasteImage(text string) bool {
+	friendID := cv.currentFriend
+	if cv.fileSender == nil || friendID == 0 || cv.presentation.Enabled() {
+		return false
+	}
+
+	fallback := ""
+	if cv.coreApp != nil && cv.coreApp.GetConfigManager() != nil {
+		fallback = cv.coreApp.GetConfigManager().GetConfig().UI.PasteImageFile
+	}
+	img, ok := pastedImage(text, fallback)
+	if !ok {
+		return false
+	}
+
+	path, err := savePastedImage(img, filepath.Join(os.TempDir(), "whisp-paste"), time.Now())
+	if err != nil {
+		log.Printf("Failed to save pasted image: %v", err)
+		dialog.ShowError(errors.New(i18n.T("paste.save_failed")), cv.parentWindow)
+		return true
+	}
+
+	preview := NewMediaPreview(cv.coreApp, path, 300, 200)
+	dialog.ShowCustomConfirm(i18n.T("paste.title"), i18n.T("paste.send"), i18n.T("common.cancel"), preview.Container(),
+		func(confirmed bool) {
+			if !confirmed || cv.currentFriend != friendID {
+				os.Remove(path)
+				return
+			}
+			cv.SendFiles([]string{path})
+		}, cv.parentWindow)
+	return true
+}
@@ -0,0 +1,92 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testPNG returns a 2x3 PNG image
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestPastedImage(t *testing.T) {
+	dir := t.TempDir()
+	data := testPNG(t)
+	imagePath := filepath.Join(dir, "shot.png")
+	if err := os.WriteFile(imagePath, data, 0o600); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+	textPath := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(textPath, []byte("not an image"), 0o600); err != nil {
+		t.Fatalf("Failed to write text: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		text      string
+		fallback  string
+		wantImage bool
+	}{
+		{"plain text", "hello there", imagePath, false},
+		{"data URI", "data:image/png;base64," + base64.StdEncoding.EncodeToString(data), "", true},
+		{"image path", imagePath, "", true},
+		{"file URI", "file://" + imagePath, "", true},
+		{"path of a non-image", textPath, "", false},
+		{"relative path", "shot.png", "", false},
+		{"empty clipboard with fallback", "", imagePath, true},
+		{"empty clipboard without fallback", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, ok := pastedImage(tt.text, tt.fallback)
+			if ok != tt.wantImage {
+				t.Fatalf("Expected image=%v, got %v", tt.wantImage, ok)
+			}
+			if ok && img.Bounds().Dx() != 2 {
+				t.Errorf("Expected a 2 pixel wide image, got %d", img.Bounds().Dx())
+			}
+		})
+	}
+}
+
+func TestSavePastedImage(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(testPNG(t)))
+	if err != nil {
+		t.Fatalf("Failed to decode image: %v", err)
+	}
+	dir := filepath.Join(t.TempDir(), "paste")
+	now := time.Date(2026, 10, 16, 14, 30, 5, 0, time.UTC)
+
+	path, err := savePastedImage(img, dir, now)
+	if err != nil {
+		t.Fatalf("savePastedImage failed: %v", err)
+	}
+	if want := filepath.Join(dir, "pasted-image-20261016-143005.png"); path != want {
+		t.Errorf("Expected %s, got %s", want, path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open saved image: %v", err)
+	}
+	defer file.Close()
+	if _, err := png.Decode(file); err != nil {
+		t.Errorf("Expected a PNG, got error %v", err)
+	}
+}
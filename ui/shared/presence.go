@@ -0,0 +1,163 @@
+package shared
+
+import (
+	"image/color"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/tox"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// selfStatuses are the statuses the user can pick, in menu order
+var selfStatuses = []contact.Status{contact.StatusOnline, contact.StatusAway, contact.StatusBusy}
+
+// presenceColor returns the dot color for a status
+func presenceColor(status contact.Status) color.Color {
+	switch status {
+	case contact.StatusOnline:
+		return color.NRGBA{R: 0x2e, G: 0xb8, B: 0x4b, A: 0xff}
+	case contact.StatusAway:
+		return color.NRGBA{R: 0xf0, G: 0xb4, B: 0x29, A: 0xff}
+	case contact.StatusBusy:
+		return color.NRGBA{R: 0xd9, G: 0x3b, B: 0x3b, A: 0xff}
+	default:
+		return color.NRGBA{R: 0x8a, G: 0x8a, B: 0x8a, A: 0xff}
+	}
+}
+
+// newPresenceDot creates the colored dot shown next to a name
+func newPresenceDot(status contact.Status) *canvas.Text {
+	dot := canvas.NewText("●", presenceColor(status))
+	dot.TextStyle = fyne.TextStyle{Bold: true}
+	return dot
+}
+
+// setPresenceDot recolors a presence dot
+func setPresenceDot(dot *canvas.Text, status contact.Status) {
+	dot.Color = presenceColor(status)
+	dot.Refresh()
+}
+
+// newSelfStatusHeader creates the contact list header with our own status
+func (cl *ContactList) newSelfStatusHeader() fyne.CanvasObject {
+	labels := make([]string, len(selfStatuses))
+	for i, status := range selfStatuses {
+		labels[i] = statusText(status)
+	}
+
+	cl.selfDot = newPresenceDot(contact.StatusOffline)
+	cl.selfStatus = widget.NewSelect(labels, func(selected string) {
+		if cl.coreApp == nil {
+			return
+		}
+		for _, status := range selfStatuses {
+			if statusText(status) == selected && status != cl.coreApp.GetSelfStatus() {
+				cl.coreApp.SetSelfStatus(status)
+			}
+		}
+		cl.RefreshSelfStatus()
+	})
+
+	cl.selfIdle = widget.NewLabel(i18n.T("presence.idle"))
+	cl.selfIdle.Importance = widget.LowImportance
+	cl.selfIdle.Hide()
+
+	cl.selfNetwork = widget.NewLabel("")
+	cl.selfNetwork.Importance = widget.WarningImportance
+	cl.selfNetwork.Hide()
+
+	cl.selfAvatar = newAvatarWidget(contactAvatarSize)
+	cl.selfName = widget.NewLabel(i18n.T("tab.contacts"))
+	cl.selfName.TextStyle = fyne.TextStyle{Bold: true}
+	cl.selfName.Truncation = fyne.TextTruncateEllipsis
+
+	return container.NewBorder(nil, nil, cl.selfAvatar,
+		container.NewHBox(cl.selfNetwork, cl.selfIdle, cl.selfDot, cl.selfStatus), cl.selfName)
+}
+
+// RefreshSelfStatus shows our current status, which auto-away may have changed
+func (cl *ContactList) RefreshSelfStatus() {
+	if cl.coreApp == nil {
+		cl.selfStatus.Disable()
+		return
+	}
+
+	status := cl.coreApp.GetSelfStatus()
+	setPresenceDot(cl.selfDot, status)
+	var avatar []byte
+	if !cl.presentation.Enabled() {
+		avatar = cl.coreApp.GetSelfAvatar()
+	}
+	name, _ := cl.coreApp.GetProfile()
+	cl.selfAvatar.SetAvatar(avatar, selfAvatarName(name))
+	if name == "" {
+		cl.selfName.SetText(i18n.T("profile.unnamed"))
+		cl.selfName.Importance = widget.LowImportance
+	} else {
+		cl.selfName.SetText(name)
+		cl.selfName.Importance = widget.MediumImportance
+	}
+	cl.selfName.Refresh()
+	if cl.coreApp.IsAutoAway() {
+		cl.selfIdle.Show()
+	} else {
+		cl.selfIdle.Hide()
+	}
+	switch cl.coreApp.GetConnectionState() {
+	case tox.ConnectionOnline:
+		cl.selfNetwork.Hide()
+	case tox.ConnectionConnecting:
+		cl.selfNetwork.SetText(i18n.T("presence.connecting"))
+		cl.selfNetwork.Show()
+	default:
+		cl.selfNetwork.SetText(i18n.T("presence.network_offline"))
+		cl.selfNetwork.Show()
+	}
+	if status == contact.StatusOffline {
+		// Appear offline is a privacy setting, not a status to pick here
+		cl.selfStatus.PlaceHolder = i18n.T("presence.appearing_offline")
+		cl.selfStatus.ClearSelected()
+		cl.selfStatus.Disable()
+		return
+	}
+	cl.selfStatus.Enable()
+	if cl.selfStatus.Selected != statusText(status) {
+		cl.selfStatus.SetSelected(statusText(status))
+	}
+}
+
+// UpdatePresence redraws the contact list after a friend's status changed
+func (cl *ContactList) UpdatePresence(friendID uint32, status contact.Status) {
+	cl.list.Refresh()
+}
+
+// presenceText describes a contact's status for the details dialog, with when
+// an offline friend was last seen if the user wants that shown
+func (cl *ContactList) presenceText(c *contact.Contact) string {
+	if c.Status != contact.StatusOffline {
+		return statusText(c.Status)
+	}
+	if c.LastSeenAt.IsZero() || !cl.showLastSeen() {
+		return statusText(c.Status)
+	}
+	return i18n.Tf("presence.last_seen", c.LastSeenAt.Local().Format("2006-01-02 15:04"))
+}
+
+// statusText returns the translated name of a status
+func statusText(status contact.Status) string {
+	return i18n.T("status." + strings.ToLower(status.String()))
+}
+
+// showLastSeen reports whether last-seen times are shown
+func (cl *ContactList) showLastSeen() bool {
+	if cl.coreApp == nil || cl.coreApp.GetConfigManager() == nil {
+		return true
+	}
+	return cl.coreApp.GetConfigManager().GetConfig().Privacy.ShowLastSeen
+}
@@ -0,0 +1,62 @@
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+)
+
+func TestContactListShowsStatusMessage(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	cl := NewContactList(&MockCoreApp{})
+	busy := &contact.Contact{FriendID: 1, Name: "Alice", StatusMessage: "In a meeting", Status: contact.StatusBusy}
+	if got := cl.rowLabel(busy); got != "Alice — In a meeting" {
+		t.Errorf("Expected the status message, got %q", got)
+	}
+
+	presentation := NewPresentationMode()
+	cl.SetPresentationMode(presentation)
+	presentation.SetEnabled(true)
+	if got := cl.rowLabel(busy); got != "Contact 1" {
+		t.Errorf("Expected the status message hidden in presentation mode, got %q", got)
+	}
+}
+
+func TestContactPresenceText(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	cl := NewContactList(&MockCoreApp{})
+	lastSeen := time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		contact *contact.Contact
+		want    string
+	}{
+		{&contact.Contact{Status: contact.StatusOnline}, "Online"},
+		{&contact.Contact{Status: contact.StatusAway, LastSeenAt: lastSeen}, "Away"},
+		{&contact.Contact{Status: contact.StatusOffline}, "Offline"},
+		{&contact.Contact{Status: contact.StatusOffline, LastSeenAt: lastSeen}, "Offline, last seen 2024-03-01 09:30"},
+	}
+	for _, tt := range tests {
+		if got := cl.presenceText(tt.contact); got != tt.want {
+			t.Errorf("presenceText() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestSelfStatusHeader(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	cl := NewContactList(&MockCoreApp{})
+	cl.RefreshContacts()
+	if cl.selfStatus.Selected != "Online" {
+		t.Errorf("Expected own status Online, got %q", cl.selfStatus.Selected)
+	}
+}
@@ -0,0 +1,80 @@
+package shared
+
+import (
+	"sync"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// PresentationMode hides personal details for screen sharing and demos:
+// contact names are masked, message previews are hidden and conversations are
+// read-only. It only changes what is displayed; stored data is untouched.
+// A nil PresentationMode is always off.
+type PresentationMode struct {
+	mu       sync.RWMutex
+	enabled  bool
+	onChange []func(enabled bool)
+}
+
+// NewPresentationMode creates a presentation mode that starts off
+func NewPresentationMode() *PresentationMode {
+	return &PresentationMode{}
+}
+
+// Enabled reports whether presentation mode is on
+func (p *PresentationMode) Enabled() bool {
+	if p == nil {
+		return false
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.enabled
+}
+
+// SetEnabled turns presentation mode on or off and notifies the listeners
+func (p *PresentationMode) SetEnabled(enabled bool) {
+	p.mu.Lock()
+	if p.enabled == enabled {
+		p.mu.Unlock()
+		return
+	}
+	p.enabled = enabled
+	listeners := append([]func(bool){}, p.onChange...)
+	p.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(enabled)
+	}
+}
+
+// Toggle flips presentation mode and returns the new state
+func (p *PresentationMode) Toggle() bool {
+	enabled := !p.Enabled()
+	p.SetEnabled(enabled)
+	return enabled
+}
+
+// OnChange registers a callback run whenever presentation mode is toggled
+func (p *PresentationMode) OnChange(callback func(enabled bool)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onChange = append(p.onChange, callback)
+}
+
+// DisplayName returns the name to show for a contact, replaced by a neutral
+// label while presentation mode is on
+func (p *PresentationMode) DisplayName(friendID uint32, name string) string {
+	if !p.Enabled() {
+		return name
+	}
+	return i18n.Tf("presentation.contact", friendID)
+}
+
+// Preview returns a message preview to show, hidden while presentation mode is on
+func (p *PresentationMode) Preview(text string) string {
+	if !p.Enabled() || text == "" {
+		return text
+	}
+	return i18n.T("presentation.hidden_preview")
+}
@@ -0,0 +1,65 @@
+package shared
+
+import "testing"
+
+func TestPresentationModeMasking(t *testing.T) {
+	p := NewPresentationMode()
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		wantName    string
+		wantPreview string
+	}{
+		{"off passes through", false, "Alice", "see you at 5"},
+		{"on masks", true, "Contact 7", "Message hidden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.SetEnabled(tt.enabled)
+			if got := p.DisplayName(7, "Alice"); got != tt.wantName {
+				t.Errorf("DisplayName = %q, want %q", got, tt.wantName)
+			}
+			if got := p.Preview("see you at 5"); got != tt.wantPreview {
+				t.Errorf("Preview = %q, want %q", got, tt.wantPreview)
+			}
+		})
+	}
+
+	// Nothing to hide in an empty preview
+	if got := p.Preview(""); got != "" {
+		t.Errorf("Expected empty preview to stay empty, got %q", got)
+	}
+}
+
+func TestPresentationModeNilIsOff(t *testing.T) {
+	var p *PresentationMode
+	if p.Enabled() {
+		t.Error("Expected nil presentation mode to be off")
+	}
+	if got := p.DisplayName(1, "Bob"); got != "Bob" {
+		t.Errorf("Expected name to pass through, got %q", got)
+	}
+	if got := p.Preview("hello"); got != "hello" {
+		t.Errorf("Expected preview to pass through, got %q", got)
+	}
+}
+
+func TestPresentationModeOnChange(t *testing.T) {
+	p := NewPresentationMode()
+	var changes []bool
+	p.OnChange(func(enabled bool) { changes = append(changes, enabled) })
+
+	if !p.Toggle() {
+		t.Error("Expected Toggle to turn presentation mode on")
+	}
+	p.SetEnabled(true) // No change, no callback
+	if p.Toggle() {
+		t.Error("Expected Toggle to turn presentation mode off")
+	}
+
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("Expected callbacks for on then off, got %v", changes)
+	}
+}
@@ -0,0 +1,160 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/tox"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// ProfileEditor reads and changes what friends see of us
+type ProfileEditor interface {
+	GetProfile() (name, statusMessage string)
+	UpdateProfile(name, statusMessage string) error
+	GetSelfAvatar() []byte
+	SetSelfAvatar(imagePath string) error
+}
+
+// ProfileDialog edits our name, status message and avatar
+type ProfileDialog struct {
+	editor       ProfileEditor
+	parentWindow fyne.Window
+	onSaved      func()
+
+	name          *widget.Entry
+	statusMessage *widget.Entry
+	avatar        *avatarWidget
+	avatarPath    string // Image picked as the new avatar, if any
+	removeAvatar  bool   // Whether the avatar is removed on save
+}
+
+// NewProfileDialog creates a profile editor over the parent window
+func NewProfileDialog(editor ProfileEditor, parentWindow fyne.Window) *ProfileDialog {
+	return &ProfileDialog{editor: editor, parentWindow: parentWindow}
+}
+
+// SetOnSaved sets the callback invoked after the profile was saved
+func (pd *ProfileDialog) SetOnSaved(callback func()) {
+	pd.onSaved = callback
+}
+
+// Show opens the profile editor
+func (pd *ProfileDialog) Show() {
+	name, statusMessage := pd.editor.GetProfile()
+
+	pd.name = widget.NewEntry()
+	pd.name.SetPlaceHolder(i18n.T("profile.name_placeholder"))
+	pd.name.SetText(name)
+	pd.name.Validator = func(text string) error {
+		return profileLengthError(text, tox.MaxNameLength)
+	}
+
+	pd.statusMessage = widget.NewMultiLineEntry()
+	pd.statusMessage.Wrapping = fyne.TextWrapWord
+	pd.statusMessage.SetPlaceHolder(i18n.T("profile.status_placeholder"))
+	pd.statusMessage.SetText(statusMessage)
+	pd.statusMessage.Validator = func(text string) error {
+		return profileLengthError(text, tox.MaxStatusMessageLength)
+	}
+
+	pd.avatar = newAvatarWidget(2 * headerAvatarSize)
+	pd.avatar.SetAvatar(pd.editor.GetSelfAvatar(), pd.initialsName())
+	pd.name.OnChanged = func(string) { pd.refreshAvatar() }
+
+	chooseBtn := widget.NewButton(i18n.T("profile.choose_avatar"), pd.chooseAvatar)
+	removeBtn := widget.NewButton(i18n.T("profile.remove_avatar"), func() {
+		pd.avatarPath = ""
+		pd.removeAvatar = true
+		pd.refreshAvatar()
+	})
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("profile.avatar"), container.NewHBox(pd.avatar, container.NewVBox(chooseBtn, removeBtn))),
+		widget.NewFormItem(i18n.T("profile.name"), pd.name),
+		widget.NewFormItem(i18n.T("profile.status_message"), pd.statusMessage),
+	}
+	form := dialog.NewForm(i18n.T("profile.edit_title"), i18n.T("common.save"), i18n.T("common.cancel"), items, func(ok bool) {
+		if ok {
+			pd.save()
+		}
+	}, pd.parentWindow)
+	form.Resize(fyne.NewSize(460, 0))
+	form.Show()
+}
+
+// save applies the edited profile
+func (pd *ProfileDialog) save() {
+	if err := pd.editor.UpdateProfile(pd.name.Text, pd.statusMessage.Text); err != nil {
+		dialog.ShowError(fmt.Errorf("failed to save profile: %w", err), pd.parentWindow)
+		return
+	}
+	if pd.avatarPath != "" || pd.removeAvatar {
+		if err := pd.editor.SetSelfAvatar(pd.avatarPath); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to set avatar: %w", err), pd.parentWindow)
+		}
+	}
+	if pd.onSaved != nil {
+		pd.onSaved()
+	}
+}
+
+// chooseAvatar lets the user pick an image as the new avatar
+func (pd *ProfileDialog) chooseAvatar() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, pd.parentWindow)
+			return
+		}
+		if reader == nil {
+			return // Cancelled
+		}
+		pd.avatarPath = reader.URI().Path()
+		pd.removeAvatar = false
+		reader.Close()
+		pd.refreshAvatar()
+	}, pd.parentWindow)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpg", ".jpeg"}))
+	open.Show()
+}
+
+// refreshAvatar previews the avatar the profile will have once saved
+func (pd *ProfileDialog) refreshAvatar() {
+	var data []byte
+	switch {
+	case pd.avatarPath != "":
+		data, _ = os.ReadFile(pd.avatarPath) // Shown scaled; resized on save
+	case !pd.removeAvatar:
+		data = pd.editor.GetSelfAvatar()
+	}
+	pd.avatar.SetAvatar(data, pd.initialsName())
+}
+
+// initialsName returns the name generated avatars take their initials from
+func (pd *ProfileDialog) initialsName() string {
+	return selfAvatarName(pd.name.Text)
+}
+
+// selfAvatarName returns the name our generated avatar uses, which needs
+// something to show while we have no name
+func selfAvatarName(name string) string {
+	if name == "" {
+		return i18n.T("avatar.self")
+	}
+	return name
+}
+
+// profileLengthError reports text longer than the Tox limit in bytes
+func profileLengthError(text string, limit int) error {
+	if len(text) > limit {
+		return errors.New(i18n.Tf("profile.too_long", len(text)-limit))
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/opd-ai/whisp/internal/core/tox"
+)
+
+// fakeProfileEditor records the profile changes a dialog makes
+type fakeProfileEditor struct {
+	name, statusMessage string
+	avatarCalls         []string
+}
+
+func (f *fakeProfileEditor) GetProfile() (string, string) { return f.name, f.statusMessage }
+func (f *fakeProfileEditor) GetSelfAvatar() []byte        { return nil }
+
+func (f *fakeProfileEditor) UpdateProfile(name, statusMessage string) error {
+	f.name, f.statusMessage = name, statusMessage
+	return nil
+}
+
+func (f *fakeProfileEditor) SetSelfAvatar(imagePath string) error {
+	f.avatarCalls = append(f.avatarCalls, imagePath)
+	return nil
+}
+
+func TestProfileLengthError(t *testing.T) {
+	tests := []struct {
+		text    string
+		limit   int
+		wantErr bool
+	}{
+		{"", tox.MaxNameLength, false},
+		{strings.Repeat("a", tox.MaxNameLength), tox.MaxNameLength, false},
+		{strings.Repeat("a", tox.MaxNameLength+1), tox.MaxNameLength, true},
+		{strings.Repeat("é", tox.MaxNameLength/2+1), tox.MaxNameLength, true}, // Two bytes each
+	}
+
+	for _, tt := range tests {
+		if err := profileLengthError(tt.text, tt.limit); (err != nil) != tt.wantErr {
+			t.Errorf("profileLengthError(%d bytes, %d) = %v, wantErr %v", len(tt.text), tt.limit, err, tt.wantErr)
+		}
+	}
+
+	if selfAvatarName("") == "" {
+		t.Error("Expected a fallback name for generated avatars")
+	}
+	if got := selfAvatarName("Alice"); got != "Alice" {
+		t.Errorf("Expected our name to be used, got %q", got)
+	}
+}
+
+func TestProfileDialogSave(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	window := app.NewWindow("test")
+
+	editor := &fakeProfileEditor{name: "Alice", statusMessage: "Around"}
+	pd := NewProfileDialog(editor, window)
+	saved := 0
+	pd.SetOnSaved(func() { saved++ })
+	pd.Show()
+
+	if pd.name.Text != "Alice" || pd.statusMessage.Text != "Around" {
+		t.Fatalf("Expected the current profile, got %q and %q", pd.name.Text, pd.statusMessage.Text)
+	}
+
+	// Untouched avatars are left alone
+	pd.name.SetText("Bob")
+	pd.statusMessage.SetText("")
+	pd.save()
+	if editor.name != "Bob" || editor.statusMessage != "" {
+		t.Errorf("Expected the edited profile saved, got %q and %q", editor.name, editor.statusMessage)
+	}
+	if len(editor.avatarCalls) != 0 {
+		t.Errorf("Expected the avatar unchanged, got %v", editor.avatarCalls)
+	}
+	if saved != 1 {
+		t.Errorf("Expected the saved callback once, got %d", saved)
+	}
+
+	pd.removeAvatar = true
+	pd.save()
+	if len(editor.avatarCalls) != 1 || editor.avatarCalls[0] != "" {
+		t.Errorf("Expected the avatar removed, got %v", editor.avatarCalls)
+	}
+}
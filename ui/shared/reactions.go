@@ -0,0 +1,119 @@
+package shared
+
+import (
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/config"
+	"github.com/opd-ai/whisp/internal/core/message"
+)
+
+// reactionChip is one emoji shown under a message
+type reactionChip struct {
+	Emoji string
+	Count int  // Reactions with this emoji
+	Mine  bool // We reacted with it
+}
+
+// label returns the chip's button text
+func (c reactionChip) label() string {
+	if c.Count > 1 {
+		return fmt.Sprintf("%s %d", c.Emoji, c.Count)
+	}
+	return c.Emoji
+}
+
+// summarizeReactions groups reactions by emoji, in the order each was first used
+func summarizeReactions(reactions []message.Reaction) []reactionChip {
+	var chips []reactionChip
+	index := make(map[string]int)
+	for _, r := range reactions {
+		i, ok := index[r.Emoji]
+		if !ok {
+			i = len(chips)
+			index[r.Emoji] = i
+			chips = append(chips, reactionChip{Emoji: r.Emoji})
+		}
+		chips[i].Count++
+		chips[i].Mine = chips[i].Mine || r.IsOutgoing
+	}
+	return chips
+}
+
+// reactionRow returns the reaction chips shown under a message, or nil if the
+// message cannot be reacted to. Tapping a chip toggles our reaction.
+func (cv *ChatView) reactionRow(msg *message.Message) *fyne.Container {
+	if cv.coreApp == nil || cv.coreApp.GetMessages() == nil || msg.UUID == "" || msg.IsDeleted {
+		return nil
+	}
+
+	reactions, err := cv.coreApp.GetMessages().GetReactions(msg.UUID)
+	if err != nil {
+		log.Printf("Failed to load reactions: %v", err)
+		return nil
+	}
+
+	row := container.NewHBox()
+	for _, chip := range summarizeReactions(reactions) {
+		emoji := chip.Emoji
+		chipBtn := widget.NewButton(chip.label(), func() {
+			cv.toggleReaction(msg, emoji)
+		})
+		chipBtn.Importance = widget.LowImportance
+		if chip.Mine {
+			chipBtn.Importance = widget.HighImportance
+		}
+		row.Add(chipBtn)
+	}
+
+	var addBtn *widget.Button
+	addBtn = widget.NewButton("+", func() {
+		cv.showReactionPicker(addBtn, msg)
+	})
+	addBtn.Importance = widget.LowImportance
+	row.Add(addBtn)
+
+	return row
+}
+
+// toggleReaction adds or withdraws our reaction and redraws the conversation
+func (cv *ChatView) toggleReaction(msg *message.Message, emoji string) {
+	if _, err := cv.coreApp.GetMessages().ToggleReaction(msg.FriendID, msg.UUID, emoji); err != nil {
+		log.Printf("Failed to update reaction: %v", err)
+		return
+	}
+	cv.messages.Refresh()
+}
+
+// showReactionPicker pops up the quick reaction bar below a button
+func (cv *ChatView) showReactionPicker(anchor fyne.CanvasObject, msg *message.Message) {
+	driver := fyne.CurrentApp().Driver()
+	canvas := driver.CanvasForObject(anchor)
+	if canvas == nil {
+		return
+	}
+
+	var items []*fyne.MenuItem
+	for _, emoji := range cv.quickReactions() {
+		emoji := emoji
+		items = append(items, fyne.NewMenuItem(emoji, func() {
+			cv.toggleReaction(msg, emoji)
+		}))
+	}
+
+	position := driver.AbsolutePositionForObject(anchor).Add(fyne.NewPos(0, anchor.Size().Height))
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), canvas, position)
+}
+
+// quickReactions returns the emoji offered by the reaction picker
+func (cv *ChatView) quickReactions() []string {
+	if cv.coreApp.GetConfigManager() == nil {
+		return append([]string(nil), config.DefaultQuickReactions...)
+	}
+	cfg := cv.coreApp.GetConfigManager().GetConfig()
+	return cfg.GetQuickReactions()
+}
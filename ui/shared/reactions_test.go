@@ -0,0 +1,39 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/opd-ai/whisp/internal/core/message"
+)
+
+func TestSummarizeReactions(t *testing.T) {
+	reactions := []message.Reaction{
+		{Emoji: "😂"},
+		{Emoji: "👍", IsOutgoing: true},
+		{Emoji: "😂", IsOutgoing: true},
+		{Emoji: "❤️"},
+	}
+
+	want := []struct {
+		label string
+		mine  bool
+	}{
+		{"😂 2", true},
+		{"👍", true},
+		{"❤️", false},
+	}
+
+	chips := summarizeReactions(reactions)
+	if len(chips) != len(want) {
+		t.Fatalf("Expected %d chips, got %+v", len(want), chips)
+	}
+	for i, w := range want {
+		if chips[i].label() != w.label || chips[i].Mine != w.mine {
+			t.Errorf("Chip %d: expected %q (mine=%v), got %q (mine=%v)", i, w.label, w.mine, chips[i].label(), chips[i].Mine)
+		}
+	}
+
+	if chips := summarizeReactions(nil); len(chips) != 0 {
+		t.Errorf("Expected no chips without reactions, got %+v", chips)
+	}
+}
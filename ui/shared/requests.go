@@ -0,0 +1,114 @@
+package shared
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// pendingRequests returns the friend requests waiting for an answer
+func (cl *ContactList) pendingRequests() []contact.PendingRequest {
+	if cl.coreApp == nil || cl.coreApp.GetContacts() == nil {
+		return nil
+	}
+	return cl.coreApp.GetContacts().GetPendingRequests()
+}
+
+// refreshRequests shows the number of waiting friend requests on the inbox button
+func (cl *ContactList) refreshRequests() {
+	count := len(cl.pendingRequests())
+	if count == 0 {
+		cl.requestsBtn.Hide()
+		return
+	}
+	cl.requestsBtn.SetText(i18n.Tf("requests.count", count))
+	cl.requestsBtn.Show()
+}
+
+// ShowFriendRequestsDialog lists the waiting friend requests with buttons to
+// accept or reject each one
+func (cl *ContactList) ShowFriendRequestsDialog() {
+	if cl.parentWindow == nil || cl.coreApp == nil || cl.coreApp.GetContacts() == nil {
+		return
+	}
+
+	list := container.NewVBox()
+	var render func()
+	render = func() {
+		list.RemoveAll()
+		requests := cl.pendingRequests()
+		if len(requests) == 0 {
+			list.Add(widget.NewLabel(i18n.T("requests.none")))
+		}
+		for _, request := range requests {
+			list.Add(cl.requestRow(request, render))
+			list.Add(widget.NewSeparator())
+		}
+	}
+	render()
+
+	d := dialog.NewCustom(i18n.T("contacts.friend_requests"), i18n.T("common.close"), container.NewVScroll(list), cl.parentWindow)
+	d.Resize(fyne.NewSize(500, 400))
+	d.Show()
+}
+
+// requestRow shows one friend request; done is called once it was answered
+func (cl *ContactList) requestRow(request contact.PendingRequest, done func()) fyne.CanvasObject {
+	contacts := cl.coreApp.GetContacts()
+	publicKey := request.PublicKey
+
+	key := widget.NewLabel(formatPublicKey(publicKey))
+	key.TextStyle = fyne.TextStyle{Monospace: true}
+	key.Wrapping = fyne.TextWrapBreak
+
+	greeting := request.Message
+	if strings.TrimSpace(greeting) == "" {
+		greeting = i18n.T("requests.no_message")
+	}
+	messageLabel := widget.NewLabel(cl.presentation.Preview(greeting))
+	messageLabel.Wrapping = fyne.TextWrapWord
+
+	received := widget.NewLabel(i18n.T("requests.received") + request.Timestamp.Local().Format("2006-01-02 15:04"))
+	received.TextStyle = fyne.TextStyle{Italic: true}
+
+	acceptBtn := widget.NewButton(i18n.T("requests.accept"), func() {
+		if _, err := contacts.AcceptFriendRequest(publicKey); err != nil {
+			cl.showErrorDialog(i18n.Tf("requests.accept_failed", err))
+			return
+		}
+		cl.RefreshContacts()
+		done()
+	})
+	acceptBtn.Importance = widget.HighImportance
+
+	rejectBtn := widget.NewButton(i18n.T("call.reject"), func() {
+		if err := contacts.RejectFriendRequest(publicKey); err != nil {
+			cl.showErrorDialog(i18n.Tf("requests.reject_failed", err))
+			return
+		}
+		done()
+	})
+
+	return container.NewVBox(
+		key,
+		messageLabel,
+		container.NewBorder(nil, nil, received, container.NewHBox(rejectBtn, acceptBtn)),
+	)
+}
+
+// formatPublicKey shows a public key as upper-case hex in groups of eight
+func formatPublicKey(publicKey [32]byte) string {
+	encoded := strings.ToUpper(hex.EncodeToString(publicKey[:]))
+	groups := make([]string, 0, len(encoded)/8)
+	for i := 0; i < len(encoded); i += 8 {
+		groups = append(groups, encoded[i:i+8])
+	}
+	return strings.Join(groups, " ")
+}
@@ -0,0 +1,32 @@
+package shared
+
+import (
+	"time"
+
+	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// retentionChoices are the retentions offered for a conversation, in menu order
+var retentionChoices = []struct {
+	label string // Translation key
+	keep  time.Duration
+}{
+	{"retention.default", message.RetentionDefault},
+	{"retention.forever", 0},
+	{"retention.7_days", 7 * 24 * time.Hour},
+	{"retention.30_days", 30 * 24 * time.Hour},
+	{"retention.90_days", 90 * 24 * time.Hour},
+	{"retention.1_year", 365 * 24 * time.Hour},
+}
+
+// retentionLabel returns the menu label for a retention; ones not offered
+// here are shown in days
+func retentionLabel(keep time.Duration) string {
+	for _, choice := range retentionChoices {
+		if choice.keep == keep {
+			return i18n.T(choice.label)
+		}
+	}
+	return i18n.Tf("retention.days", int(keep/(24*time.Hour)))
+}
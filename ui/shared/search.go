@@ -0,0 +1,216 @@
+package shared
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// searchResultLimit is the most results a search shows
+const searchResultLimit = 100
+
+// searchSnippetRadius is how many characters around a match a result shows
+const searchSnippetRadius = 40
+
+// searchGroup holds the search results from one conversation
+type searchGroup struct {
+	FriendID uint32
+	Messages []*message.Message
+}
+
+// groupSearchResults groups results by conversation, in the order each
+// conversation first appears
+func groupSearchResults(results []*message.Message) []searchGroup {
+	var groups []searchGroup
+	index := make(map[uint32]int)
+	for _, msg := range results {
+		i, ok := index[msg.FriendID]
+		if !ok {
+			i = len(groups)
+			index[msg.FriendID] = i
+			groups = append(groups, searchGroup{FriendID: msg.FriendID})
+		}
+		groups[i].Messages = append(groups[i].Messages, msg)
+	}
+	return groups
+}
+
+// searchSnippet returns the text around the first case-insensitive match of
+// query in content, with the match's rune offsets in the snippet. Without a
+// match the start of the content is returned with offsets of -1.
+func searchSnippet(content, query string, radius int) (string, int, int) {
+	text := []rune(strings.Join(strings.Fields(content), " "))
+	needle := []rune(strings.ToLower(query))
+	lower := []rune(strings.ToLower(string(text)))
+
+	match := -1
+	if len(needle) > 0 && len(lower) == len(text) {
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				match = i
+				break
+			}
+		}
+	}
+	if match < 0 {
+		if len(text) > 2*radius {
+			return string(text[:2*radius]) + "…", -1, -1
+		}
+		return string(text), -1, -1
+	}
+
+	from, to := match-radius, match+len(needle)+radius
+	prefix, suffix := "…", "…"
+	if from <= 0 {
+		from, prefix = 0, ""
+	}
+	if to >= len(text) {
+		to, suffix = len(text), ""
+	}
+
+	start := match - from + len([]rune(prefix))
+	return prefix + string(text[from:to]) + suffix, start, start + len(needle)
+}
+
+// highlightMatch returns rich text showing a snippet with its match in bold
+func highlightMatch(snippet string, start, end int) []widget.RichTextSegment {
+	runes := []rune(snippet)
+	if start < 0 || end > len(runes) || start >= end {
+		return []widget.RichTextSegment{&widget.TextSegment{Text: snippet, Style: widget.RichTextStyleInline}}
+	}
+
+	bold := widget.RichTextStyleStrong
+	bold.Inline = true
+	return []widget.RichTextSegment{
+		&widget.TextSegment{Text: string(runes[:start]), Style: widget.RichTextStyleInline},
+		&widget.TextSegment{Text: string(runes[start:end]), Style: bold},
+		&widget.TextSegment{Text: string(runes[end:]), Style: widget.RichTextStyleInline},
+	}
+}
+
+// SearchDialog searches message history and opens the result the user picks
+type SearchDialog struct {
+	coreApp      CoreApp
+	parentWindow fyne.Window
+	presentation *PresentationMode
+	dialog       dialog.Dialog
+	results      *fyne.Container
+
+	// OnOpen is called with the message the user picked
+	OnOpen func(msg *message.Message)
+}
+
+// NewSearchDialog creates a search dialog. Names and snippets are masked while
+// presentation mode is on.
+func NewSearchDialog(coreApp CoreApp, parentWindow fyne.Window, presentation *PresentationMode) *SearchDialog {
+	return &SearchDialog{
+		coreApp:      coreApp,
+		parentWindow: parentWindow,
+		presentation: presentation,
+	}
+}
+
+// Show opens the dialog. A non-zero friendID limits the search to that conversation.
+func (sd *SearchDialog) Show(friendID uint32) {
+	title := i18n.T("search.title")
+	if friendID != 0 {
+		title = i18n.Tf("search.in", sd.contactName(friendID))
+	}
+
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(i18n.T("search.placeholder"))
+	sd.results = container.NewVBox()
+	entry.OnSubmitted = func(query string) {
+		sd.search(query, friendID)
+	}
+	searchBtn := widget.NewButton(i18n.T("search.search"), func() {
+		sd.search(entry.Text, friendID)
+	})
+
+	content := container.NewBorder(
+		container.NewBorder(nil, nil, nil, searchBtn, entry), nil, nil, nil,
+		container.NewVScroll(sd.results),
+	)
+	sd.dialog = dialog.NewCustom(title, i18n.T("common.close"), content, sd.parentWindow)
+	sd.dialog.Resize(fyne.NewSize(600, 500))
+	sd.dialog.Show()
+	sd.parentWindow.Canvas().Focus(entry)
+}
+
+// search runs a query and lists the results grouped by conversation
+func (sd *SearchDialog) search(query string, friendID uint32) {
+	sd.results.RemoveAll()
+	query = strings.TrimSpace(query)
+	if query == "" || sd.coreApp.GetMessages() == nil {
+		return
+	}
+
+	opts := message.SearchOptions{Query: query, Limit: searchResultLimit}
+	if friendID != 0 {
+		opts.FriendID = &friendID
+	}
+	results, err := sd.coreApp.GetMessages().SearchMessagesFiltered(opts)
+	if err != nil {
+		log.Printf("Failed to search messages: %v", err)
+		sd.results.Add(widget.NewLabel(i18n.T("search.failed")))
+		return
+	}
+	if len(results) == 0 {
+		sd.results.Add(widget.NewLabel(i18n.T("search.none")))
+		return
+	}
+
+	for _, group := range groupSearchResults(results) {
+		header := widget.NewLabel(fmt.Sprintf("%s (%d)", sd.contactName(group.FriendID), len(group.Messages)))
+		header.TextStyle = fyne.TextStyle{Bold: true}
+		sd.results.Add(header)
+
+		for _, msg := range group.Messages {
+			sd.results.Add(sd.resultRow(msg, query))
+		}
+	}
+}
+
+// resultRow shows one search result with the matched text highlighted
+func (sd *SearchDialog) resultRow(msg *message.Message, query string) fyne.CanvasObject {
+	snippet, start, end := searchSnippet(msg.Content, query, searchSnippetRadius)
+	if sd.presentation.Enabled() {
+		snippet, start, end = sd.presentation.Preview(msg.Content), -1, -1
+	}
+
+	text := widget.NewRichText(highlightMatch(snippet, start, end)...)
+	text.Wrapping = fyne.TextWrapWord
+	when := widget.NewLabel(msg.Timestamp.Local().Format("2006-01-02 15:04"))
+
+	openBtn := widget.NewButton(i18n.T("search.open"), func() {
+		sd.dialog.Hide()
+		if sd.OnOpen != nil {
+			sd.OnOpen(msg)
+		}
+	})
+	openBtn.Importance = widget.LowImportance
+
+	return container.NewBorder(nil, nil, when, openBtn, text)
+}
+
+// contactName returns the name shown for a conversation
+func (sd *SearchDialog) contactName(friendID uint32) string {
+	name := i18n.Tf("contacts.fallback_name", friendID)
+	if contacts := sd.coreApp.GetContacts(); contacts != nil {
+		if c, ok := contacts.GetContact(friendID); ok {
+			if named, ok := c.(*contact.Contact); ok && named.DisplayName() != "" {
+				name = named.DisplayName()
+			}
+		}
+	}
+	return sd.presentation.DisplayName(friendID, name)
+}
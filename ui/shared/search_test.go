@@ -0,0 +1,80 @@
+package shared
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/message"
+)
+
+func TestGroupSearchResults(t *testing.T) {
+	results := []*message.Message{
+		{ID: 1, FriendID: 2},
+		{ID: 2, FriendID: 1},
+		{ID: 3, FriendID: 2},
+	}
+
+	groups := groupSearchResults(results)
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(groups))
+	}
+	if groups[0].FriendID != 2 || len(groups[0].Messages) != 2 || groups[0].Messages[1].ID != 3 {
+		t.Errorf("Unexpected first group %+v", groups[0])
+	}
+	if groups[1].FriendID != 1 || len(groups[1].Messages) != 1 {
+		t.Errorf("Unexpected second group %+v", groups[1])
+	}
+	if groups := groupSearchResults(nil); len(groups) != 0 {
+		t.Errorf("Expected no groups, got %+v", groups)
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		query   string
+		radius  int
+		want    string
+		match   string
+	}{
+		{"short", "Lunch at noon?", "noon", 40, "Lunch at noon?", "noon"},
+		{"case insensitive", "See you at the PARK", "park", 40, "See you at the PARK", "PARK"},
+		{"trimmed", "one two three four five", "three", 4, "…two three fou…", "three"},
+		{"whitespace collapsed", "a\n\nb  c", "b c", 10, "a b c", "b c"},
+		{"unicode", "héllo wörld", "WÖR", 2, "…o wörld", "wör"},
+		{"no match", "abcdefghij", "xyz", 3, "abcdef…", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, start, end := searchSnippet(tt.content, tt.query, tt.radius)
+			if got != tt.want {
+				t.Errorf("Expected snippet %q, got %q", tt.want, got)
+			}
+			match := ""
+			if start >= 0 {
+				match = string([]rune(got)[start:end])
+			}
+			if match != tt.match {
+				t.Errorf("Expected match %q, got %q", tt.match, match)
+			}
+		})
+	}
+}
+
+func TestHighlightMatch(t *testing.T) {
+	segments := highlightMatch("say hello there", 4, 9)
+	if len(segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %d", len(segments))
+	}
+	bold := segments[1].(*widget.TextSegment)
+	if bold.Text != "hello" || !bold.Style.TextStyle.Bold {
+		t.Errorf("Expected the match in bold, got %+v", bold)
+	}
+
+	if segments := highlightMatch("no match", -1, -1); len(segments) != 1 {
+		t.Errorf("Expected plain text without a match, got %d segments", len(segments))
+	}
+}
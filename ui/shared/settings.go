@@ -2,7 +2,10 @@ package shared
 
 import (
 	"fmt"
+	"log"
 	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -10,7 +13,10 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/opd-ai/whisp/internal/core/audio"
 	"github.com/opd-ai/whisp/internal/core/config"
+	"github.com/opd-ai/whisp/ui/i18n"
+	"github.com/opd-ai/whisp/ui/theme"
 )
 
 // SettingsDialog represents the settings configuration interface
@@ -18,7 +24,12 @@ import (
 type SettingsDialog struct {
 	dialog       *dialog.CustomDialog
 	configMgr    *config.Manager
+	themeManager theme.ThemeManager // Nil disables the live theme preview
 	parentWindow fyne.Window
+	savedTheme   theme.ThemeType    // Theme to restore when closing without saving
+	themeChanged bool               // Whether a theme was picked since opening
+	backup       DatabaseBackup     // Nil hides the backup tab
+	bootstrap    BootstrapNodeStore // Nil hides the bootstrap node editor
 
 	// UI bindings for real-time updates
 	themeBinding    binding.String
@@ -27,12 +38,16 @@ type SettingsDialog struct {
 }
 
 // NewSettingsDialog creates a new settings dialog
-// Takes config manager and parent window for modal behavior
-func NewSettingsDialog(configMgr *config.Manager, parentWindow fyne.Window) *SettingsDialog {
+// Takes config manager, theme manager for previews and parent window for modal behavior
+func NewSettingsDialog(configMgr *config.Manager, themeManager theme.ThemeManager, parentWindow fyne.Window) *SettingsDialog {
 	sd := &SettingsDialog{
 		configMgr:    configMgr,
+		themeManager: themeManager,
 		parentWindow: parentWindow,
 	}
+	if themeManager != nil {
+		sd.savedTheme = themeManager.GetThemeType()
+	}
 
 	// Create data bindings for real-time UI updates
 	sd.themeBinding = binding.NewString()
@@ -54,7 +69,8 @@ func (sd *SettingsDialog) Show() {
 	content := sd.createContent()
 
 	// Create dialog with save and cancel buttons
-	sd.dialog = dialog.NewCustom("Settings", "Close", content, sd.parentWindow)
+	sd.dialog = dialog.NewCustom(i18n.T("settings.title"), i18n.T("common.close"), content, sd.parentWindow)
+	sd.dialog.SetOnClosed(sd.revertThemePreview)
 	sd.dialog.Resize(fyne.NewSize(600, 500))
 	sd.dialog.Show()
 }
@@ -63,14 +79,21 @@ func (sd *SettingsDialog) Show() {
 // Organizes settings into logical groups for usability
 func (sd *SettingsDialog) createContent() fyne.CanvasObject {
 	tabs := container.NewAppTabs(
-		container.NewTabItem("General", sd.createGeneralTab()),
-		container.NewTabItem("Privacy", sd.createPrivacyTab()),
-		container.NewTabItem("Notifications", sd.createNotificationsTab()),
-		container.NewTabItem("Advanced", sd.createAdvancedTab()),
+		container.NewTabItem(i18n.T("settings.general"), sd.createGeneralTab()),
+		container.NewTabItem(i18n.T("settings.privacy"), sd.createPrivacyTab()),
+		container.NewTabItem(i18n.T("settings.notifications"), sd.createNotificationsTab()),
+		container.NewTabItem(i18n.T("settings.advanced"), sd.createAdvancedTab()),
 	)
+	if sd.backup != nil {
+		tabs.Append(container.NewTabItem(i18n.T("backup.title"), sd.createBackupTab()))
+	} else {
+		// Left by an earlier dialog
+		delete(formReferences, "backup")
+		delete(formReferences, "compaction")
+	}
 
 	// Add save/apply buttons at the bottom
-	saveBtn := widget.NewButton("Save", func() {
+	saveBtn := widget.NewButton(i18n.T("common.save"), func() {
 		if err := sd.applySettings(); err != nil {
 			dialog.ShowError(err, sd.parentWindow)
 			return
@@ -79,12 +102,12 @@ func (sd *SettingsDialog) createContent() fyne.CanvasObject {
 	})
 	saveBtn.Importance = widget.HighImportance
 
-	applyBtn := widget.NewButton("Apply", func() {
+	applyBtn := widget.NewButton(i18n.T("settings.apply"), func() {
 		if err := sd.applySettings(); err != nil {
 			dialog.ShowError(err, sd.parentWindow)
 		}
 	})
-	resetBtn := widget.NewButton("Reset to Defaults", sd.resetToDefaults)
+	resetBtn := widget.NewButton(i18n.T("settings.reset"), sd.resetToDefaults)
 
 	buttonContainer := container.NewHBox(
 		widget.NewLabel(""), // Spacer
@@ -107,14 +130,23 @@ func (sd *SettingsDialog) createContent() fyne.CanvasObject {
 func (sd *SettingsDialog) createGeneralTab() fyne.CanvasObject {
 	cfg := sd.configMgr.GetConfig()
 
-	// Theme selection
-	themeSelect := widget.NewSelect(
-		[]string{"system", "light", "dark", "amoled"},
-		func(value string) {
-			sd.themeBinding.Set(value)
-		},
-	)
+	// Theme selection, previewed live until saved
+	themeSelect := widget.NewSelect([]string{"system", "light", "dark", "amoled"}, nil)
 	themeSelect.SetSelected(cfg.UI.Theme)
+	themeSelect.OnChanged = func(value string) {
+		sd.themeBinding.Set(value)
+		sd.themeChanged = true
+		sd.previewTheme(value)
+	}
+
+	// Custom themes are made in the theme editor
+	themeRow := fyne.CanvasObject(themeSelect)
+	if sd.themeManager != nil {
+		customizeBtn := widget.NewButton(i18n.T("settings.customize"), func() {
+			NewThemeEditor(sd.themeManager, sd.parentWindow).Show()
+		})
+		themeRow = container.NewBorder(nil, nil, nil, customizeBtn, themeSelect)
+	}
 
 	// Font size selection
 	fontSizeSelect := widget.NewSelect(
@@ -135,32 +167,47 @@ func (sd *SettingsDialog) createGeneralTab() fyne.CanvasObject {
 	languageSelect.SetSelected(cfg.UI.Language)
 
 	// Storage settings
-	encryptionCheck := widget.NewCheck("Enable database encryption", nil)
+	encryptionCheck := widget.NewCheck(i18n.T("settings.encryption_check"), nil)
 	encryptionCheck.SetChecked(cfg.Storage.EnableEncryption)
 
-	animationsCheck := widget.NewCheck("Enable animations", nil)
+	animationsCheck := widget.NewCheck(i18n.T("settings.animations_check"), nil)
 	animationsCheck.SetChecked(cfg.UI.EnableAnimations)
 
-	soundCheck := widget.NewCheck("Enable sound effects", nil)
+	soundCheck := widget.NewCheck(i18n.T("settings.sound_check"), nil)
 	soundCheck.SetChecked(cfg.UI.EnableSoundEffects)
 
 	// File size limit
 	maxFileSizeEntry := widget.NewEntry()
 	maxFileSizeEntry.SetText(fmt.Sprintf("%.0f", float64(cfg.Storage.MaxFileSize)/(1024*1024*1024))) // Convert to GB
 
+	// Voice message format, with the one this build actually saves
+	voiceFormatNote := widget.NewLabel("")
+	voiceFormatNote.Wrapping = fyne.TextWrapWord
+	voiceFormatSelect := widget.NewSelect([]string{"wav", "opus"}, func(value string) {
+		voiceFormatNote.SetText(voiceFormatNoteText(value))
+	})
+	voiceFormat := cfg.Storage.VoiceFormat
+	if voiceFormat == "" {
+		voiceFormat = audio.DefaultVoiceFormat().Codec // Configs from before the setting
+	}
+	voiceFormatSelect.SetSelected(voiceFormat)
+
 	form := &widget.Form{
 		Items: []*widget.FormItem{
-			widget.NewFormItem("Theme", themeSelect),
-			widget.NewFormItem("Font Size", fontSizeSelect),
-			widget.NewFormItem("Language", languageSelect),
+			widget.NewFormItem(i18n.T("settings.theme"), themeRow),
+			widget.NewFormItem(i18n.T("settings.font_size"), fontSizeSelect),
+			widget.NewFormItem(i18n.T("settings.language"), languageSelect),
+			widget.NewFormItem("", widget.NewSeparator()),
+			widget.NewFormItem(i18n.T("settings.encryption"), encryptionCheck),
+			widget.NewFormItem(i18n.T("settings.animations"), animationsCheck),
+			widget.NewFormItem(i18n.T("settings.sound_effects"), soundCheck),
 			widget.NewFormItem("", widget.NewSeparator()),
-			widget.NewFormItem("Database Encryption", encryptionCheck),
-			widget.NewFormItem("Animations", animationsCheck),
-			widget.NewFormItem("Sound Effects", soundCheck),
+			widget.NewFormItem(i18n.T("settings.max_file_size"), maxFileSizeEntry),
+			widget.NewFormItem(i18n.T("settings.voice_format"), container.NewVBox(voiceFormatSelect, voiceFormatNote)),
 			widget.NewFormItem("", widget.NewSeparator()),
-			widget.NewFormItem("Max File Size (GB)", maxFileSizeEntry),
 		},
 	}
+	form.Items = append(form.Items, sd.createDownloadDirItems(cfg)...)
 
 	// Store references for saving
 	sd.storeFormReferences("general", map[string]interface{}{
@@ -171,69 +218,151 @@ func (sd *SettingsDialog) createGeneralTab() fyne.CanvasObject {
 		"animations":  animationsCheck,
 		"sound":       soundCheck,
 		"maxFileSize": maxFileSizeEntry,
+		"voiceFormat": voiceFormatSelect,
 	})
 
 	return container.NewScroll(form)
 }
 
+// voiceFormatNoteText explains when voice messages are saved in another
+// format than the one chosen
+func voiceFormatNoteText(codec string) string {
+	if effective := audio.VoiceCodec(codec); effective != codec {
+		return fmt.Sprintf(i18n.T("settings.voice_format_fallback"), strings.ToUpper(effective))
+	}
+	return ""
+}
+
 // createPrivacyTab creates the privacy settings tab
 // Includes message history, typing indicators, and security options
 func (sd *SettingsDialog) createPrivacyTab() fyne.CanvasObject {
 	cfg := sd.configMgr.GetConfig()
 
 	// Message privacy
-	saveHistoryCheck := widget.NewCheck("Save message history", nil)
+	saveHistoryCheck := widget.NewCheck(i18n.T("settings.save_history_check"), nil)
 	saveHistoryCheck.SetChecked(cfg.Privacy.SaveMessageHistory)
 
-	disappearingCheck := widget.NewCheck("Enable disappearing messages", nil)
+	disappearingCheck := widget.NewCheck(i18n.T("settings.disappearing_check"), nil)
 	disappearingCheck.SetChecked(cfg.Privacy.EnableDisappearingMessages)
 
 	// Typing indicators
-	showTypingCheck := widget.NewCheck("Show typing indicators", nil)
+	showTypingCheck := widget.NewCheck(i18n.T("settings.show_typing_check"), nil)
 	showTypingCheck.SetChecked(cfg.Privacy.ShowTypingIndicators)
 
-	sendTypingCheck := widget.NewCheck("Send typing indicators", nil)
+	sendTypingCheck := widget.NewCheck(i18n.T("settings.send_typing_check"), nil)
 	sendTypingCheck.SetChecked(cfg.Privacy.SendTypingIndicators)
 
 	// Read receipts
-	showReceiptsCheck := widget.NewCheck("Show read receipts", nil)
+	showReceiptsCheck := widget.NewCheck(i18n.T("settings.show_receipts_check"), nil)
 	showReceiptsCheck.SetChecked(cfg.Privacy.ShowReadReceipts)
 
-	sendReceiptsCheck := widget.NewCheck("Send read receipts", nil)
+	sendReceiptsCheck := widget.NewCheck(i18n.T("settings.send_receipts_check"), nil)
 	sendReceiptsCheck.SetChecked(cfg.Privacy.SendReadReceipts)
 
+	// Presence
+	appearOfflineCheck := widget.NewCheck(i18n.T("settings.appear_offline_check"), nil)
+	appearOfflineCheck.SetChecked(cfg.Privacy.AppearOffline)
+
+	autoAwayCheck := widget.NewCheck(i18n.T("settings.auto_away_check"), nil)
+	autoAwayCheck.SetChecked(cfg.Privacy.AutoAway)
+
+	autoAwayAfterEntry := widget.NewEntry()
+	autoAwayAfterEntry.SetText(strconv.Itoa(int(cfg.Privacy.AutoAwayAfter / time.Minute)))
+
+	// Auto-lock needs a password; 0 never locks
+	lockTimeoutEntry := widget.NewEntry()
+	lockTimeoutEntry.SetText(strconv.Itoa(int(cfg.Privacy.LockTimeout / time.Minute)))
+
+	// Translation
+	translationCheck := widget.NewCheck(i18n.T("settings.translation_check"), nil)
+	translationCheck.SetChecked(cfg.Privacy.EnableTranslation)
+
 	// File sharing
-	autoAcceptCheck := widget.NewCheck("Auto-accept files from friends", nil)
+	autoAcceptCheck := widget.NewCheck(i18n.T("settings.auto_accept_check"), nil)
 	autoAcceptCheck.SetChecked(cfg.Privacy.AutoAcceptFiles)
 
 	autoDownloadEntry := widget.NewEntry()
 	autoDownloadEntry.SetText(fmt.Sprintf("%.0f", float64(cfg.Privacy.AutoDownloadLimit)/(1024*1024))) // Convert to MB
 
+	// Adding contacts
+	confirmUnverifiedCheck := widget.NewCheck(i18n.T("settings.confirm_unverified_check"), nil)
+	confirmUnverifiedCheck.SetChecked(cfg.Privacy.ConfirmUnverifiedContacts)
+
+	// Messages from non-contacts
+	senderPolicySelect := widget.NewSelect(
+		[]string{"accept", "hold", "reject"},
+		nil,
+	)
+	senderPolicySelect.SetSelected(cfg.Privacy.UnknownSenderPolicy)
+
+	// Local message retention
+	retentionEntry := widget.NewEntry()
+	retentionEntry.SetText(strconv.Itoa(cfg.Privacy.RetentionDays))
+	retentionEntry.SetPlaceHolder(i18n.T("settings.retention_placeholder"))
+	retentionHardDeleteCheck := widget.NewCheck(i18n.T("settings.retention_hard_delete"), nil)
+	retentionHardDeleteCheck.SetChecked(cfg.Privacy.RetentionHardDelete)
+
+	// Deletions by friends
+	remoteDeleteCheck := widget.NewCheck(i18n.T("settings.remote_delete_check"), nil)
+	remoteDeleteCheck.SetChecked(cfg.Privacy.HonorRemoteDeletions)
+
+	// Links in messages
+	openLinksCheck := widget.NewCheck(i18n.T("settings.open_links_check"), nil)
+	openLinksCheck.SetChecked(cfg.Privacy.OpenLinks)
+	linkPreviewsCheck := widget.NewCheck(i18n.T("settings.link_previews_check"), nil)
+	linkPreviewsCheck.SetChecked(cfg.Privacy.LinkPreviews)
+
 	form := &widget.Form{
 		Items: []*widget.FormItem{
-			widget.NewFormItem("Message History", saveHistoryCheck),
-			widget.NewFormItem("Disappearing Messages", disappearingCheck),
+			widget.NewFormItem(i18n.T("settings.message_history"), saveHistoryCheck),
+			widget.NewFormItem(i18n.T("settings.disappearing"), disappearingCheck),
+			widget.NewFormItem(i18n.T("settings.retention"), retentionEntry),
+			widget.NewFormItem("", retentionHardDeleteCheck),
 			widget.NewFormItem("", widget.NewSeparator()),
-			widget.NewFormItem("Show Typing Status", showTypingCheck),
-			widget.NewFormItem("Send Typing Status", sendTypingCheck),
+			widget.NewFormItem(i18n.T("settings.show_typing"), showTypingCheck),
+			widget.NewFormItem(i18n.T("settings.send_typing"), sendTypingCheck),
 			widget.NewFormItem("", widget.NewSeparator()),
-			widget.NewFormItem("Show Read Receipts", showReceiptsCheck),
-			widget.NewFormItem("Send Read Receipts", sendReceiptsCheck),
+			widget.NewFormItem(i18n.T("settings.show_receipts"), showReceiptsCheck),
+			widget.NewFormItem(i18n.T("settings.send_receipts"), sendReceiptsCheck),
 			widget.NewFormItem("", widget.NewSeparator()),
-			widget.NewFormItem("Auto-Accept Files", autoAcceptCheck),
-			widget.NewFormItem("Auto-Download Limit (MB)", autoDownloadEntry),
+			widget.NewFormItem(i18n.T("settings.appear_offline"), appearOfflineCheck),
+			widget.NewFormItem(i18n.T("settings.auto_away"), autoAwayCheck),
+			widget.NewFormItem(i18n.T("settings.auto_away_after"), autoAwayAfterEntry),
+			widget.NewFormItem(i18n.T("settings.auto_lock"), lockTimeoutEntry),
+			widget.NewFormItem(i18n.T("settings.translation"), translationCheck),
+			widget.NewFormItem("", widget.NewSeparator()),
+			widget.NewFormItem(i18n.T("settings.auto_accept"), autoAcceptCheck),
+			widget.NewFormItem(i18n.T("settings.auto_download"), autoDownloadEntry),
+			widget.NewFormItem("", widget.NewSeparator()),
+			widget.NewFormItem(i18n.T("settings.unverified_contacts"), confirmUnverifiedCheck),
+			widget.NewFormItem(i18n.T("settings.sender_policy"), senderPolicySelect),
+			widget.NewFormItem(i18n.T("settings.remote_deletions"), remoteDeleteCheck),
+			widget.NewFormItem(i18n.T("settings.open_links"), openLinksCheck),
+			widget.NewFormItem("", linkPreviewsCheck),
 		},
 	}
 
 	sd.storeFormReferences("privacy", map[string]interface{}{
-		"saveHistory":  saveHistoryCheck,
-		"disappearing": disappearingCheck,
-		"showTyping":   showTypingCheck,
-		"sendTyping":   sendTypingCheck,
-		"showReceipts": showReceiptsCheck,
-		"sendReceipts": sendReceiptsCheck,
-		"autoAccept":   autoAcceptCheck,
-		"autoDownload": autoDownloadEntry,
+		"saveHistory":   saveHistoryCheck,
+		"disappearing":  disappearingCheck,
+		"showTyping":    showTypingCheck,
+		"sendTyping":    sendTypingCheck,
+		"showReceipts":  showReceiptsCheck,
+		"sendReceipts":  sendReceiptsCheck,
+		"appearOffline": appearOfflineCheck,
+		"autoAway":      autoAwayCheck,
+		"autoAwayAfter": autoAwayAfterEntry,
+		"lockTimeout":   lockTimeoutEntry,
+		"translation":   translationCheck,
+		"autoAccept":    autoAcceptCheck,
+		"autoDownload":  autoDownloadEntry,
+		"senderPolicy":  senderPolicySelect,
+		"confirmAdd":    confirmUnverifiedCheck,
+		"remoteDelete":  remoteDeleteCheck,
+		"openLinks":     openLinksCheck,
+		"linkPreviews":  linkPreviewsCheck,
+		"retention":     retentionEntry,
+		"hardDelete":    retentionHardDeleteCheck,
 	})
 
 	return container.NewScroll(form)
@@ -245,36 +374,36 @@ func (sd *SettingsDialog) createNotificationsTab() fyne.CanvasObject {
 	cfg := sd.configMgr.GetConfig()
 
 	// Global notifications
-	enabledCheck := widget.NewCheck("Enable notifications", nil)
+	enabledCheck := widget.NewCheck(i18n.T("settings.enable_notifications_check"), nil)
 	enabledCheck.SetChecked(cfg.Notifications.Enabled)
 
 	// Desktop notifications
-	desktopPreviewCheck := widget.NewCheck("Show message preview", nil)
+	desktopPreviewCheck := widget.NewCheck(i18n.T("settings.show_preview_check"), nil)
 	desktopPreviewCheck.SetChecked(cfg.Notifications.Desktop.ShowPreview)
 
-	desktopSoundCheck := widget.NewCheck("Play notification sound", nil)
+	desktopSoundCheck := widget.NewCheck(i18n.T("settings.play_sound_check"), nil)
 	desktopSoundCheck.SetChecked(cfg.Notifications.Desktop.PlaySound)
 
-	desktopSenderCheck := widget.NewCheck("Show sender name", nil)
+	desktopSenderCheck := widget.NewCheck(i18n.T("settings.show_sender_check"), nil)
 	desktopSenderCheck.SetChecked(cfg.Notifications.Desktop.ShowSender)
 
 	// Mobile notifications
-	mobileVibrateCheck := widget.NewCheck("Vibrate on message", nil)
+	mobileVibrateCheck := widget.NewCheck(i18n.T("settings.vibrate_check"), nil)
 	mobileVibrateCheck.SetChecked(cfg.Notifications.Mobile.Vibrate)
 
-	mobileLockScreenCheck := widget.NewCheck("Show on lock screen", nil)
+	mobileLockScreenCheck := widget.NewCheck(i18n.T("settings.lock_screen_check"), nil)
 	mobileLockScreenCheck.SetChecked(cfg.Notifications.Mobile.ShowOnLockScreen)
 
 	form := &widget.Form{
 		Items: []*widget.FormItem{
-			widget.NewFormItem("Enable Notifications", enabledCheck),
+			widget.NewFormItem(i18n.T("settings.enable_notifications"), enabledCheck),
 			widget.NewFormItem("", widget.NewSeparator()),
-			widget.NewFormItem("Desktop: Show Preview", desktopPreviewCheck),
-			widget.NewFormItem("Desktop: Play Sound", desktopSoundCheck),
-			widget.NewFormItem("Desktop: Show Sender", desktopSenderCheck),
+			widget.NewFormItem(i18n.T("settings.desktop_preview"), desktopPreviewCheck),
+			widget.NewFormItem(i18n.T("settings.desktop_sound"), desktopSoundCheck),
+			widget.NewFormItem(i18n.T("settings.desktop_sender"), desktopSenderCheck),
 			widget.NewFormItem("", widget.NewSeparator()),
-			widget.NewFormItem("Mobile: Vibrate", mobileVibrateCheck),
-			widget.NewFormItem("Mobile: Lock Screen", mobileLockScreenCheck),
+			widget.NewFormItem(i18n.T("settings.mobile_vibrate"), mobileVibrateCheck),
+			widget.NewFormItem(i18n.T("settings.mobile_lock_screen"), mobileLockScreenCheck),
 		},
 	}
 
@@ -302,10 +431,10 @@ func (sd *SettingsDialog) createAdvancedTab() fyne.CanvasObject {
 	)
 	logLevelSelect.SetSelected(cfg.Advanced.LogLevel)
 
-	logToFileCheck := widget.NewCheck("Log to file", nil)
+	logToFileCheck := widget.NewCheck(i18n.T("settings.log_to_file_check"), nil)
 	logToFileCheck.SetChecked(cfg.Advanced.LogToFile)
 
-	debugModeCheck := widget.NewCheck("Enable debug mode", nil)
+	debugModeCheck := widget.NewCheck(i18n.T("settings.debug_mode_check"), nil)
 	debugModeCheck.SetChecked(cfg.Advanced.EnableDebugMode)
 
 	// Performance
@@ -318,18 +447,46 @@ func (sd *SettingsDialog) createAdvancedTab() fyne.CanvasObject {
 	cacheSizeEntry := widget.NewEntry()
 	cacheSizeEntry.SetText(strconv.Itoa(cfg.Advanced.MessageCacheSize))
 
+	rateLimitEntry := widget.NewEntry()
+	rateLimitEntry.SetPlaceHolder(i18n.T("settings.unlimited"))
+	rateLimitEntry.SetText(strconv.Itoa(cfg.Advanced.TransferRateLimit / 1024)) // Convert to KB/s
+
+	// Experimental features, applied on restart
+	voiceCallsCheck := widget.NewCheck(i18n.T("settings.voice_calls_check"), nil)
+	voiceCallsCheck.SetChecked(cfg.Advanced.Experimental.EnableVoiceCalls)
+
+	videoCallsCheck := widget.NewCheck(i18n.T("settings.video_calls_check"), nil)
+	videoCallsCheck.SetChecked(cfg.Advanced.Experimental.EnableVideoCalls)
+
 	form := &widget.Form{
 		Items: []*widget.FormItem{
-			widget.NewFormItem("Log Level", logLevelSelect),
-			widget.NewFormItem("Log to File", logToFileCheck),
-			widget.NewFormItem("Debug Mode", debugModeCheck),
+			widget.NewFormItem(i18n.T("settings.log_level"), logLevelSelect),
+			widget.NewFormItem(i18n.T("settings.log_to_file"), logToFileCheck),
+			widget.NewFormItem(i18n.T("settings.debug_mode"), debugModeCheck),
+			widget.NewFormItem("", widget.NewSeparator()),
+			widget.NewFormItem(i18n.T("settings.max_downloads"), maxDownloadsEntry),
+			widget.NewFormItem(i18n.T("settings.max_uploads"), maxUploadsEntry),
+			widget.NewFormItem(i18n.T("settings.cache_size"), cacheSizeEntry),
+			widget.NewFormItem(i18n.T("settings.upload_limit"), rateLimitEntry),
 			widget.NewFormItem("", widget.NewSeparator()),
-			widget.NewFormItem("Max Concurrent Downloads", maxDownloadsEntry),
-			widget.NewFormItem("Max Concurrent Uploads", maxUploadsEntry),
-			widget.NewFormItem("Message Cache Size", cacheSizeEntry),
+			widget.NewFormItem(i18n.T("settings.voice_calls"), voiceCallsCheck),
+			widget.NewFormItem(i18n.T("settings.video_calls"), videoCallsCheck),
 		},
 	}
 
+	// Bootstrap nodes, for networks that cannot reach the default ones
+	if sd.bootstrap != nil {
+		nodesEntry := sd.newBootstrapNodesEntry()
+		form.Append("", widget.NewSeparator())
+		form.Append(i18n.T("settings.bootstrap_nodes"), nodesEntry)
+		sd.storeFormReferences("bootstrap", map[string]interface{}{
+			"nodes": nodesEntry,
+		})
+	} else {
+		// Left by an earlier dialog
+		delete(formReferences, "bootstrap")
+	}
+
 	sd.storeFormReferences("advanced", map[string]interface{}{
 		"logLevel":     logLevelSelect,
 		"logToFile":    logToFileCheck,
@@ -337,6 +494,9 @@ func (sd *SettingsDialog) createAdvancedTab() fyne.CanvasObject {
 		"maxDownloads": maxDownloadsEntry,
 		"maxUploads":   maxUploadsEntry,
 		"cacheSize":    cacheSizeEntry,
+		"rateLimit":    rateLimitEntry,
+		"voiceCalls":   voiceCallsCheck,
+		"videoCalls":   videoCallsCheck,
 	})
 
 	return container.NewScroll(form)
@@ -353,6 +513,17 @@ func (sd *SettingsDialog) storeFormReferences(section string, refs map[string]in
 // applySettings applies the current form values to configuration
 func (sd *SettingsDialog) applySettings() error {
 	cfg := sd.configMgr.GetConfig()
+	language := cfg.UI.Language
+
+	// Bootstrap nodes are saved apart from the config, first so a bad line
+	// leaves everything unsaved
+	if bootstrap, ok := formReferences["bootstrap"]; ok && sd.bootstrap != nil {
+		if nodes, ok := bootstrap["nodes"].(*widget.Entry); ok {
+			if err := sd.applyBootstrapNodes(nodes.Text); err != nil {
+				return err
+			}
+		}
+	}
 
 	// Apply general settings
 	if general, ok := formReferences["general"]; ok {
@@ -379,6 +550,13 @@ func (sd *SettingsDialog) applySettings() error {
 				cfg.Storage.MaxFileSize = int64(size * 1024 * 1024 * 1024) // Convert GB to bytes
 			}
 		}
+		if voiceFormat, ok := general["voiceFormat"].(*widget.Select); ok {
+			cfg.Storage.VoiceFormat = voiceFormat.Selected
+		}
+	}
+
+	if downloads, ok := formReferences["downloads"]; ok {
+		applyDownloadDirs(&cfg, downloads)
 	}
 
 	// Apply privacy settings
@@ -401,6 +579,25 @@ func (sd *SettingsDialog) applySettings() error {
 		if sendReceipts, ok := privacy["sendReceipts"].(*widget.Check); ok {
 			cfg.Privacy.SendReadReceipts = sendReceipts.Checked
 		}
+		if appearOffline, ok := privacy["appearOffline"].(*widget.Check); ok {
+			cfg.Privacy.AppearOffline = appearOffline.Checked
+		}
+		if autoAway, ok := privacy["autoAway"].(*widget.Check); ok {
+			cfg.Privacy.AutoAway = autoAway.Checked
+		}
+		if autoAwayAfter, ok := privacy["autoAwayAfter"].(*widget.Entry); ok {
+			if minutes, err := strconv.Atoi(autoAwayAfter.Text); err == nil && minutes > 0 {
+				cfg.Privacy.AutoAwayAfter = time.Duration(minutes) * time.Minute
+			}
+		}
+		if lockTimeout, ok := privacy["lockTimeout"].(*widget.Entry); ok {
+			if minutes, err := strconv.Atoi(lockTimeout.Text); err == nil && minutes >= 0 {
+				cfg.Privacy.LockTimeout = time.Duration(minutes) * time.Minute
+			}
+		}
+		if translation, ok := privacy["translation"].(*widget.Check); ok {
+			cfg.Privacy.EnableTranslation = translation.Checked
+		}
 		if autoAccept, ok := privacy["autoAccept"].(*widget.Check); ok {
 			cfg.Privacy.AutoAcceptFiles = autoAccept.Checked
 		}
@@ -409,6 +606,29 @@ func (sd *SettingsDialog) applySettings() error {
 				cfg.Privacy.AutoDownloadLimit = int64(size * 1024 * 1024) // Convert MB to bytes
 			}
 		}
+		if senderPolicy, ok := privacy["senderPolicy"].(*widget.Select); ok {
+			cfg.Privacy.UnknownSenderPolicy = senderPolicy.Selected
+		}
+		if confirmAdd, ok := privacy["confirmAdd"].(*widget.Check); ok {
+			cfg.Privacy.ConfirmUnverifiedContacts = confirmAdd.Checked
+		}
+		if remoteDelete, ok := privacy["remoteDelete"].(*widget.Check); ok {
+			cfg.Privacy.HonorRemoteDeletions = remoteDelete.Checked
+		}
+		if openLinks, ok := privacy["openLinks"].(*widget.Check); ok {
+			cfg.Privacy.OpenLinks = openLinks.Checked
+		}
+		if linkPreviews, ok := privacy["linkPreviews"].(*widget.Check); ok {
+			cfg.Privacy.LinkPreviews = linkPreviews.Checked
+		}
+		if retention, ok := privacy["retention"].(*widget.Entry); ok {
+			if days, err := strconv.Atoi(retention.Text); err == nil && days >= 0 {
+				cfg.Privacy.RetentionDays = days
+			}
+		}
+		if hardDelete, ok := privacy["hardDelete"].(*widget.Check); ok {
+			cfg.Privacy.RetentionHardDelete = hardDelete.Checked
+		}
 	}
 
 	// Apply notification settings
@@ -444,6 +664,12 @@ func (sd *SettingsDialog) applySettings() error {
 		if debugMode, ok := advanced["debugMode"].(*widget.Check); ok {
 			cfg.Advanced.EnableDebugMode = debugMode.Checked
 		}
+		if voiceCalls, ok := advanced["voiceCalls"].(*widget.Check); ok {
+			cfg.Advanced.Experimental.EnableVoiceCalls = voiceCalls.Checked
+		}
+		if videoCalls, ok := advanced["videoCalls"].(*widget.Check); ok {
+			cfg.Advanced.Experimental.EnableVideoCalls = videoCalls.Checked
+		}
 		if maxDownloads, ok := advanced["maxDownloads"].(*widget.Entry); ok {
 			if count, err := strconv.Atoi(maxDownloads.Text); err == nil {
 				cfg.Advanced.MaxConcurrentDownloads = count
@@ -455,21 +681,114 @@ func (sd *SettingsDialog) applySettings() error {
 			}
 		}
 		if cacheSize, ok := advanced["cacheSize"].(*widget.Entry); ok {
-			if size, err := strconv.Atoi(cacheSize.Text); err == nil {
+			if size, err := strconv.Atoi(cacheSize.Text); err == nil && size >= 0 {
 				cfg.Advanced.MessageCacheSize = size
 			}
 		}
+		if rateLimit, ok := advanced["rateLimit"].(*widget.Entry); ok {
+			if limit, err := strconv.Atoi(rateLimit.Text); err == nil && limit >= 0 {
+				cfg.Advanced.TransferRateLimit = limit * 1024 // Convert KB/s to bytes per second
+			}
+		}
+	}
+
+	// Apply automatic backup settings
+	if backup, ok := formReferences["backup"]; ok {
+		if enabled, ok := backup["enabled"].(*widget.Check); ok {
+			cfg.Storage.BackupEnabled = enabled.Checked
+		}
+		if interval, ok := backup["interval"].(*widget.Select); ok {
+			cfg.Storage.BackupInterval = interval.Selected
+		}
+		if retention, ok := backup["retention"].(*widget.Entry); ok {
+			if count, err := strconv.Atoi(retention.Text); err == nil && count > 0 {
+				cfg.Storage.BackupRetention = count
+			}
+		}
+		if dir, ok := backup["dir"].(*widget.Entry); ok {
+			cfg.Storage.BackupDir = strings.TrimSpace(dir.Text)
+		}
+	}
+
+	// Apply compaction settings
+	if compaction, ok := formReferences["compaction"]; ok {
+		if enabled, ok := compaction["enabled"].(*widget.Check); ok {
+			cfg.Storage.CompactEnabled = enabled.Checked
+		}
+		if interval, ok := compaction["interval"].(*widget.Select); ok {
+			cfg.Storage.CompactInterval = interval.Selected
+		}
+		if retention, ok := compaction["retention"].(*widget.Entry); ok {
+			if days, err := strconv.Atoi(retention.Text); err == nil && days >= 0 {
+				cfg.Storage.DeletedRetentionDays = days
+			}
+		}
 	}
 
 	// Save configuration
-	return sd.configMgr.UpdateConfig(cfg)
+	if err := sd.configMgr.UpdateConfig(cfg); err != nil {
+		return err
+	}
+	sd.applyFontSize(cfg.UI.FontSize)
+	if cfg.UI.Language != language {
+		// Strings are looked up as widgets are built, so only a restart
+		// shows the whole UI in the new language
+		dialog.ShowInformation(i18n.T("settings.language"), i18n.T("settings.language_restart"), sd.parentWindow)
+	}
+	if !sd.themeChanged {
+		return nil // Keep a theme chosen elsewhere, e.g. a custom theme
+	}
+	return sd.saveTheme(cfg.UI.Theme)
+}
+
+// previewTheme shows a theme across the app without saving it
+func (sd *SettingsDialog) previewTheme(name string) {
+	if sd.themeManager == nil {
+		return
+	}
+	if err := sd.themeManager.PreviewTheme(theme.ParseThemeType(name)); err != nil {
+		dialog.ShowError(err, sd.parentWindow)
+	}
+}
+
+// saveTheme keeps the theme chosen in the dialog
+func (sd *SettingsDialog) saveTheme(name string) error {
+	if sd.themeManager == nil {
+		return nil
+	}
+	themeType := theme.ParseThemeType(name)
+	if err := sd.themeManager.SetTheme(themeType); err != nil {
+		return fmt.Errorf("failed to save theme: %w", err)
+	}
+	sd.savedTheme = themeType
+	return nil
+}
+
+// applyFontSize scales the theme's text to the font size setting
+func (sd *SettingsDialog) applyFontSize(fontSize string) {
+	if sd.themeManager == nil {
+		return
+	}
+	if err := sd.themeManager.SetFontScale(theme.FontScaleFor(fontSize)); err != nil {
+		log.Printf("Warning: Failed to apply font size: %v", err)
+	}
+}
+
+// revertThemePreview restores the saved theme if another one is previewed
+func (sd *SettingsDialog) revertThemePreview() {
+	if sd.themeManager == nil || sd.themeManager.GetThemeType() == sd.savedTheme {
+		return
+	}
+	if err := sd.themeManager.PreviewTheme(sd.savedTheme); err != nil {
+		log.Printf("Warning: Failed to restore theme: %v", err)
+	}
 }
 
 // resetToDefaults resets all settings to default values
 func (sd *SettingsDialog) resetToDefaults() {
 	dialog.ShowConfirm(
-		"Reset Settings",
-		"Are you sure you want to reset all settings to their default values? This action cannot be undone.",
+		i18n.T("settings.reset_title"),
+		i18n.T("settings.reset_confirm"),
 		func(confirmed bool) {
 			if confirmed {
 				// Create new config manager to get defaults
@@ -479,9 +798,16 @@ func (sd *SettingsDialog) resetToDefaults() {
 						dialog.ShowError(fmt.Errorf("failed to reset settings: %w", err), sd.parentWindow)
 						return
 					}
+					sd.applyFontSize(defaultCfg.UI.FontSize)
+					if err := sd.saveTheme(defaultCfg.UI.Theme); err != nil {
+						dialog.ShowError(err, sd.parentWindow)
+					}
 					// Close and reopen dialog to refresh values
 					sd.dialog.Hide()
-					NewSettingsDialog(sd.configMgr, sd.parentWindow).Show()
+					reopened := NewSettingsDialog(sd.configMgr, sd.themeManager, sd.parentWindow)
+					reopened.SetDatabaseBackup(sd.backup)
+					reopened.SetBootstrapNodeStore(sd.bootstrap)
+					reopened.Show()
 				}
 			}
 		},
@@ -0,0 +1,140 @@
+package shared
+
+import (
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/audio"
+	"github.com/opd-ai/whisp/internal/core/config"
+	"github.com/opd-ai/whisp/internal/core/tox"
+	"github.com/opd-ai/whisp/ui/theme"
+)
+
+func TestSettingsThemePreview(t *testing.T) {
+	dir := t.TempDir()
+	configMgr, err := config.NewManager(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to create config manager: %v", err)
+	}
+	themeManager := theme.NewDefaultThemeManager(dir)
+	if err := themeManager.Initialize(test.NewApp()); err != nil {
+		t.Fatalf("Failed to initialize theme manager: %v", err)
+	}
+	if err := themeManager.SetTheme(theme.ThemeLight); err != nil {
+		t.Fatalf("Failed to set theme: %v", err)
+	}
+
+	sd := NewSettingsDialog(configMgr, themeManager, test.NewWindow(nil))
+
+	// Previewing changes the theme until the dialog closes without saving
+	sd.previewTheme("dark")
+	if themeManager.GetThemeType() != theme.ThemeDark {
+		t.Errorf("Expected dark theme previewed, got %v", themeManager.GetThemeType())
+	}
+	sd.revertThemePreview()
+	if themeManager.GetThemeType() != theme.ThemeLight {
+		t.Errorf("Expected light theme restored, got %v", themeManager.GetThemeType())
+	}
+
+	// Saving keeps the previewed theme
+	sd.previewTheme("amoled")
+	if err := sd.saveTheme("amoled"); err != nil {
+		t.Fatalf("saveTheme failed: %v", err)
+	}
+	sd.revertThemePreview()
+	if themeManager.GetThemeType() != theme.ThemeAMOLED {
+		t.Errorf("Expected saved AMOLED theme kept, got %v", themeManager.GetThemeType())
+	}
+	if themeManager.GetPreferences().ThemeType != theme.ThemeAMOLED {
+		t.Error("Expected AMOLED theme saved as the preference")
+	}
+}
+
+func TestSettingsWithoutThemeManager(t *testing.T) {
+	configMgr, err := config.NewManager(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to create config manager: %v", err)
+	}
+
+	sd := NewSettingsDialog(configMgr, nil, test.NewWindow(nil))
+	sd.previewTheme("dark")
+	sd.revertThemePreview()
+	if err := sd.saveTheme("dark"); err != nil {
+		t.Errorf("saveTheme without theme manager failed: %v", err)
+	}
+}
+
+func TestSettingsVoiceFormat(t *testing.T) {
+	configMgr, err := config.NewManager(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to create config manager: %v", err)
+	}
+
+	sd := NewSettingsDialog(configMgr, nil, test.NewWindow(nil))
+	sd.createGeneralTab()
+	voiceFormat, ok := formReferences["general"]["voiceFormat"].(*widget.Select)
+	if !ok {
+		t.Fatal("Expected a voice format select")
+	}
+	if voiceFormat.Selected != "wav" {
+		t.Errorf("Expected WAV by default, got %q", voiceFormat.Selected)
+	}
+
+	// Builds without an Opus encoder say so instead of saving WAV silently
+	if note := voiceFormatNoteText("opus"); (note == "") != (audio.VoiceCodec("opus") == "opus") {
+		t.Errorf("Unexpected note for Opus: %q", note)
+	}
+	if note := voiceFormatNoteText("wav"); note != "" {
+		t.Errorf("Expected no note for WAV, got %q", note)
+	}
+
+	voiceFormat.SetSelected("opus")
+	if err := sd.applySettings(); err != nil {
+		t.Fatalf("applySettings failed: %v", err)
+	}
+	if got := configMgr.GetConfig().Storage.VoiceFormat; got != "opus" {
+		t.Errorf("Expected opus saved, got %q", got)
+	}
+}
+
+func TestParseBootstrapNodes(t *testing.T) {
+	key := "F404ABAA1C99A9D37D61AB54898F56793E1DEF8BD46B1038B9D822E8460FAB67"
+
+	tests := []struct {
+		name    string
+		text    string
+		want    []tox.BootstrapNode
+		wantErr bool
+	}{
+		{"empty", "  \n", nil, false},
+		{"nodes", "tox.example.org 443 " + key + "\n\n1.2.3.4  33445\t" + key, []tox.BootstrapNode{{Address: "tox.example.org", Port: 443, PublicKey: key}, {Address: "1.2.3.4", Port: 33445, PublicKey: key}}, false},
+		{"missing key", "tox.example.org 443", nil, true},
+		{"port out of range", "tox.example.org 70000 " + key, nil, true},
+		{"bad key", "tox.example.org 443 ABCD", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := parseBootstrapNodes(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBootstrapNodes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(nodes) != len(tt.want) {
+				t.Fatalf("Expected %d nodes, got %v", len(tt.want), nodes)
+			}
+			for i := range tt.want {
+				if nodes[i] != tt.want[i] {
+					t.Errorf("Node %d: expected %+v, got %+v", i, tt.want[i], nodes[i])
+				}
+			}
+			if !tt.wantErr {
+				if again, _ := parseBootstrapNodes(formatBootstrapNodes(nodes)); len(again) != len(nodes) {
+					t.Errorf("Expected formatted nodes to parse back, got %v", again)
+				}
+			}
+		})
+	}
+}
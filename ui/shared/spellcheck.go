@@ -0,0 +1,138 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// spellcheckDelay is how long typing must pause before the input is checked
+const spellcheckDelay = 400 * time.Millisecond
+
+// maxSuggestions limits how many corrections are shown per word
+const maxSuggestions = 3
+
+// SpellChecker checks individual words. Implementations may wrap a system
+// dictionary or a third-party library; ChatView only depends on this interface.
+type SpellChecker interface {
+	Check(word string) (ok bool, suggestions []string)
+}
+
+// NoopSpellChecker accepts every word
+type NoopSpellChecker struct{}
+
+// Check always reports the word as correct
+func (NoopSpellChecker) Check(word string) (bool, []string) {
+	return true, nil
+}
+
+// Misspelling is a word in the input that the spellchecker rejected
+type Misspelling struct {
+	Word        string
+	Start       int // Byte offset of the word in the input
+	End         int
+	Suggestions []string
+}
+
+// FindMisspellings splits text into words and returns those the checker rejects.
+// Words containing digits or symbols, URLs, and @mentions are skipped.
+func FindMisspellings(checker SpellChecker, text string) []Misspelling {
+	if checker == nil {
+		return nil
+	}
+
+	var misspellings []Misspelling
+	for _, token := range tokenizeWords(text) {
+		ok, suggestions := checker.Check(token.Word)
+		if ok {
+			continue
+		}
+		token.Suggestions = suggestions
+		misspellings = append(misspellings, token)
+	}
+
+	return misspellings
+}
+
+// tokenizeWords returns the checkable words in text with their positions
+func tokenizeWords(text string) []Misspelling {
+	var words []Misspelling
+
+	for _, field := range fieldsWithOffsets(text) {
+		if strings.Contains(field.Word, "://") || strings.HasPrefix(field.Word, "@") || strings.HasPrefix(field.Word, "www.") ||
+			strings.ContainsFunc(field.Word, unicode.IsDigit) {
+			continue
+		}
+
+		// Trim surrounding punctuation but keep inner apostrophes ("don't")
+		start := strings.IndexFunc(field.Word, unicode.IsLetter)
+		end := strings.LastIndexFunc(field.Word, unicode.IsLetter)
+		if start < 0 {
+			continue
+		}
+		_, lastSize := utf8.DecodeRuneInString(field.Word[end:])
+		word := field.Word[start : end+lastSize]
+
+		if strings.ContainsFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && r != '\'' && r != '’' && r != '-' }) {
+			continue
+		}
+
+		words = append(words, Misspelling{
+			Word:  word,
+			Start: field.Start + start,
+			End:   field.Start + start + len(word),
+		})
+	}
+
+	return words
+}
+
+// fieldsWithOffsets splits text on whitespace, recording each field's byte offset
+func fieldsWithOffsets(text string) []Misspelling {
+	var fields []Misspelling
+	start := -1
+
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				fields = append(fields, Misspelling{Word: text[start:i], Start: start, End: i})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, Misspelling{Word: text[start:], Start: start, End: len(text)})
+	}
+
+	return fields
+}
+
+// formatMisspellings builds the hint shown below the message input
+func formatMisspellings(misspellings []Misspelling) string {
+	if len(misspellings) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(misspellings))
+	for _, m := range misspellings {
+		suggestions := m.Suggestions
+		if len(suggestions) > maxSuggestions {
+			suggestions = suggestions[:maxSuggestions]
+		}
+		if len(suggestions) == 0 {
+			parts = append(parts, m.Word)
+		} else {
+			parts = append(parts, fmt.Sprintf("%s → %s", m.Word, strings.Join(suggestions, ", ")))
+		}
+	}
+
+	return i18n.T("spelling.prefix") + strings.Join(parts, "; ")
+}
@@ -0,0 +1,122 @@
+package shared
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+)
+
+// fakeSpellChecker rejects words found in its corrections map
+type fakeSpellChecker struct {
+	corrections map[string][]string
+}
+
+func (f *fakeSpellChecker) Check(word string) (bool, []string) {
+	suggestions, misspelled := f.corrections[strings.ToLower(word)]
+	return !misspelled, suggestions
+}
+
+func newFakeSpellChecker() *fakeSpellChecker {
+	return &fakeSpellChecker{corrections: map[string][]string{
+		"helo":  {"hello", "help", "hero", "halo"},
+		"wrold": {"world"},
+		"teh":   nil,
+	}}
+}
+
+func TestFindMisspellings(t *testing.T) {
+	checker := newFakeSpellChecker()
+
+	tests := []struct {
+		name     string
+		text     string
+		expected []Misspelling
+	}{
+		{"empty", "", nil},
+		{"all correct", "hello world", nil},
+		{
+			name: "flagged with positions",
+			text: "Helo, wrold!",
+			expected: []Misspelling{
+				{Word: "Helo", Start: 0, End: 4, Suggestions: []string{"hello", "help", "hero", "halo"}},
+				{Word: "wrold", Start: 6, End: 11, Suggestions: []string{"world"}},
+			},
+		},
+		{
+			name:     "no suggestions",
+			text:     "  teh  end",
+			expected: []Misspelling{{Word: "teh", Start: 2, End: 5}},
+		},
+		{"skips urls, mentions and numbers", "https://helo.example @helo helo2", nil},
+		{
+			name:     "multibyte text keeps byte offsets",
+			text:     "café helo",
+			expected: []Misspelling{{Word: "helo", Start: 6, End: 10, Suggestions: []string{"hello", "help", "hero", "halo"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindMisspellings(checker, tt.text)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+			for _, m := range got {
+				if tt.text[m.Start:m.End] != m.Word {
+					t.Errorf("Offsets %d:%d do not match word %q", m.Start, m.End, m.Word)
+				}
+			}
+		})
+	}
+
+	if got := FindMisspellings(NoopSpellChecker{}, "helo wrold"); got != nil {
+		t.Errorf("Expected no-op checker to accept everything, got %+v", got)
+	}
+}
+
+func TestFormatMisspellings(t *testing.T) {
+	misspellings := FindMisspellings(newFakeSpellChecker(), "helo teh")
+
+	expected := "Spelling: helo → hello, help, hero; teh"
+	if got := formatMisspellings(misspellings); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if got := formatMisspellings(nil); got != "" {
+		t.Errorf("Expected empty hint, got %q", got)
+	}
+}
+
+func TestChatViewSpellcheck(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	chatView := NewChatView(&MockCoreApp{})
+	chatView.SetSpellChecker(newFakeSpellChecker())
+
+	chatView.input.SetText("helo")
+	chatView.input.SetText("helo wrold")
+
+	// Nothing is shown until typing pauses
+	if chatView.spellHint.Visible() {
+		t.Error("Expected spellcheck to be debounced")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !chatView.spellHint.Visible() && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if !strings.Contains(chatView.spellHint.Text, "wrold → world") {
+		t.Errorf("Expected hint for latest input, got %q", chatView.spellHint.Text)
+	}
+
+	// Clearing the input hides the hint immediately
+	chatView.input.SetText("")
+	if chatView.spellHint.Visible() {
+		t.Error("Expected hint to be hidden for empty input")
+	}
+}
@@ -0,0 +1,291 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/ui/i18n"
+	"github.com/opd-ai/whisp/ui/theme"
+)
+
+// builtinSchemes are the starting points offered besides the custom themes
+var builtinSchemes = []struct {
+	name   string
+	scheme theme.ColorScheme
+}{
+	{"Light", theme.LightColorScheme},
+	{"Dark", theme.DarkColorScheme},
+	{"AMOLED", theme.AMOLEDColorScheme},
+}
+
+// ThemeEditor creates and edits custom themes with a color picker for each
+// color, a live sample and readability warnings
+type ThemeEditor struct {
+	themeManager theme.ThemeManager
+	parentWindow fyne.Window
+	dialog       *dialog.CustomDialog
+
+	scheme     theme.ColorScheme // Colors being edited
+	nameEntry  *widget.Entry
+	descEntry  *widget.Entry
+	swatches   []*canvas.Rectangle // One per scheme field, in Fields order
+	sample     *fyne.Container
+	warnings   *widget.Label
+	applyCheck *widget.Check
+}
+
+// NewThemeEditor creates a theme editor starting from the light scheme
+func NewThemeEditor(themeManager theme.ThemeManager, parentWindow fyne.Window) *ThemeEditor {
+	return &ThemeEditor{
+		themeManager: themeManager,
+		parentWindow: parentWindow,
+		scheme:       theme.LightColorScheme,
+	}
+}
+
+// Show displays the editor
+func (te *ThemeEditor) Show() {
+	te.nameEntry = widget.NewEntry()
+	te.nameEntry.SetPlaceHolder(i18n.T("theme_editor.name_placeholder"))
+	te.descEntry = widget.NewEntry()
+	te.descEntry.SetPlaceHolder(i18n.T("theme_editor.description_placeholder"))
+
+	startSelect := widget.NewSelect(te.startingPoints(), func(name string) {
+		te.loadStartingPoint(name)
+	})
+	startSelect.PlaceHolder = "Light"
+
+	te.warnings = widget.NewLabel("")
+	te.warnings.Wrapping = fyne.TextWrapWord
+	te.warnings.Importance = widget.WarningImportance
+
+	te.applyCheck = widget.NewCheck(i18n.T("theme_editor.apply"), nil)
+	te.applyCheck.SetChecked(true)
+
+	form := widget.NewForm(
+		widget.NewFormItem(i18n.T("details.name"), te.nameEntry),
+		widget.NewFormItem(i18n.T("theme_editor.description"), te.descEntry),
+		widget.NewFormItem(i18n.T("theme_editor.start_from"), startSelect),
+	)
+
+	te.sample = container.NewStack()
+	pickers := te.createColorRows()
+	te.refresh()
+
+	saveBtn := widget.NewButton(i18n.T("common.save"), te.save)
+	saveBtn.Importance = widget.HighImportance
+
+	right := container.NewBorder(widget.NewLabel(i18n.T("theme_editor.preview")), te.warnings, nil, nil, te.sample)
+	split := container.NewHSplit(container.NewVScroll(pickers), right)
+	split.SetOffset(0.5)
+
+	content := container.NewBorder(
+		form,
+		container.NewHBox(te.applyCheck, layout.NewSpacer(), saveBtn),
+		nil, nil,
+		split,
+	)
+
+	te.dialog = dialog.NewCustom(i18n.T("theme_editor.title"), i18n.T("common.close"), content, te.parentWindow)
+	te.dialog.Resize(fyne.NewSize(760, 560))
+	te.dialog.Show()
+}
+
+// startingPoints lists the built-in schemes and the custom themes by name
+func (te *ThemeEditor) startingPoints() []string {
+	names := make([]string, 0, len(builtinSchemes))
+	for _, builtin := range builtinSchemes {
+		names = append(names, builtin.name)
+	}
+
+	var custom []string
+	for _, ct := range te.themeManager.ListCustomThemes() {
+		custom = append(custom, ct.Name)
+	}
+	sort.Strings(custom)
+	return append(names, custom...)
+}
+
+// loadStartingPoint copies the colors of a built-in scheme or custom theme.
+// Starting from a custom theme keeps its name and description, so saving
+// updates it unless it is renamed.
+func (te *ThemeEditor) loadStartingPoint(name string) {
+	for _, builtin := range builtinSchemes {
+		if builtin.name == name {
+			te.scheme = builtin.scheme
+			te.refresh()
+			return
+		}
+	}
+
+	ct, err := te.themeManager.GetCustomTheme(name)
+	if err != nil {
+		dialog.ShowError(err, te.parentWindow)
+		return
+	}
+	te.scheme = ct.ColorScheme
+	te.nameEntry.SetText(ct.Name)
+	te.descEntry.SetText(ct.Description)
+	te.refresh()
+}
+
+// createColorRows creates a swatch and color picker button for each color
+func (te *ThemeEditor) createColorRows() fyne.CanvasObject {
+	rows := container.NewVBox()
+	te.swatches = nil
+	for i, field := range te.scheme.Fields() {
+		index := i
+		name := field.Name
+
+		swatch := canvas.NewRectangle(*field.Color)
+		swatch.SetMinSize(fyne.NewSize(32, 20))
+		swatch.StrokeWidth = 1
+		swatch.StrokeColor = color.Gray{Y: 128}
+		te.swatches = append(te.swatches, swatch)
+
+		pick := widget.NewButton(i18n.T("theme_editor.choose"), func() {
+			picker := dialog.NewColorPicker(name, i18n.Tf("theme_editor.pick", strings.ToLower(name)), func(c color.Color) {
+				*te.scheme.Fields()[index].Color = theme.NewSerializableColor(c)
+				te.refresh()
+			}, te.parentWindow)
+			picker.Advanced = true
+			picker.SetColor(*te.scheme.Fields()[index].Color)
+			picker.Show()
+		})
+
+		rows.Add(container.NewBorder(nil, nil, swatch, pick, widget.NewLabel(name)))
+	}
+	return rows
+}
+
+// refresh updates the swatches, sample and warnings from the edited colors
+func (te *ThemeEditor) refresh() {
+	for i, field := range te.scheme.Fields() {
+		if i < len(te.swatches) {
+			te.swatches[i].FillColor = *field.Color
+			te.swatches[i].Refresh()
+		}
+	}
+
+	if te.sample != nil {
+		te.sample.Objects = []fyne.CanvasObject{themeSample(te.scheme)}
+		te.sample.Refresh()
+	}
+	if te.warnings != nil {
+		te.warnings.SetText(contrastWarningText(te.scheme.CheckContrast()))
+	}
+}
+
+// save stores the theme, asking first if some colors are hard to read
+func (te *ThemeEditor) save() {
+	name := strings.TrimSpace(te.nameEntry.Text)
+	if name == "" {
+		dialog.ShowError(errors.New(i18n.T("theme_editor.name_required")), te.parentWindow)
+		return
+	}
+
+	if warnings := te.scheme.CheckContrast(); len(warnings) > 0 {
+		dialog.ShowConfirm(i18n.T("theme_editor.poor_readability"),
+			contrastWarningText(warnings)+"\n\n"+i18n.T("theme_editor.save_anyway"),
+			func(ok bool) {
+				if ok {
+					te.store(name)
+				}
+			}, te.parentWindow)
+		return
+	}
+	te.store(name)
+}
+
+// store creates or updates the custom theme and optionally switches to it
+func (te *ThemeEditor) store(name string) {
+	ct := theme.CustomTheme{
+		Name:        name,
+		Description: strings.TrimSpace(te.descEntry.Text),
+		ColorScheme: te.scheme,
+	}
+
+	saveTheme := te.themeManager.CreateCustomTheme
+	if _, err := te.themeManager.GetCustomTheme(name); err == nil {
+		saveTheme = te.themeManager.UpdateCustomTheme
+	}
+	if err := saveTheme(ct); err != nil {
+		dialog.ShowError(fmt.Errorf("failed to save theme: %w", err), te.parentWindow)
+		return
+	}
+
+	if te.applyCheck.Checked {
+		prefs := te.themeManager.GetPreferences()
+		prefs.ThemeType = theme.ThemeCustom
+		prefs.CustomThemeName = name
+		if err := te.themeManager.SetPreferences(prefs); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to use theme: %w", err), te.parentWindow)
+			return
+		}
+	}
+	te.dialog.Hide()
+}
+
+// themeSample draws a conversation and buttons in a color scheme
+func themeSample(scheme theme.ColorScheme) fyne.CanvasObject {
+	bubble := func(text string, fill, fg color.Color, sent bool) fyne.CanvasObject {
+		label := canvas.NewText(text, fg)
+		timeLabel := canvas.NewText("12:34", scheme.MessageTime)
+		timeLabel.TextSize = 10
+		bg := canvas.NewRectangle(fill)
+		bg.CornerRadius = 8
+		b := container.NewStack(bg, container.NewPadded(container.NewVBox(label, timeLabel)))
+		if sent {
+			return container.NewHBox(layout.NewSpacer(), b)
+		}
+		return container.NewHBox(b, layout.NewSpacer())
+	}
+
+	button := func(text string, fill, fg color.Color) fyne.CanvasObject {
+		bg := canvas.NewRectangle(fill)
+		bg.CornerRadius = 4
+		return container.NewStack(bg, container.NewPadded(canvas.NewText(text, fg)))
+	}
+
+	online := canvas.NewCircle(scheme.OnlineIndicator)
+	header := container.NewBorder(nil, nil,
+		container.NewGridWrap(fyne.NewSize(10, 10), online), nil,
+		canvas.NewText("Alice", scheme.OnSurface))
+	headerBg := canvas.NewRectangle(scheme.Surface)
+	divider := canvas.NewRectangle(scheme.Divider)
+	divider.SetMinSize(fyne.NewSize(0, 1))
+
+	body := container.NewVBox(
+		container.NewStack(headerBg, container.NewPadded(header)),
+		divider,
+		bubble(i18n.T("theme_editor.sample_received"), scheme.MessageReceived, scheme.MessageText, false),
+		bubble(i18n.T("theme_editor.sample_sent"), scheme.MessageSent, scheme.MessageText, true),
+		canvas.NewText(i18n.T("theme_editor.sample_body"), scheme.OnBackground),
+		container.NewHBox(
+			button(i18n.T("chat.send"), scheme.Primary, scheme.OnPrimary),
+			button(i18n.T("theme_editor.sample_call"), scheme.Secondary, scheme.OnSecondary),
+			button(i18n.T("theme_editor.sample_delete"), scheme.Error, scheme.OnError),
+		),
+	)
+	return container.NewStack(canvas.NewRectangle(scheme.Background), container.NewPadded(body))
+}
+
+// contrastWarningText describes hard-to-read color pairs
+func contrastWarningText(warnings []theme.ContrastWarning) string {
+	lines := make([]string, len(warnings))
+	for i, w := range warnings {
+		lines[i] = i18n.Tf("theme_editor.contrast_warning",
+			w.Foreground, w.Background, w.Ratio, w.Minimum)
+	}
+	return strings.Join(lines, "\n")
+}
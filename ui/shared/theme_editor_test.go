@@ -0,0 +1,74 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/opd-ai/whisp/ui/theme"
+)
+
+func TestThemeEditorSave(t *testing.T) {
+	themeManager := theme.NewDefaultThemeManager(t.TempDir())
+	if err := themeManager.Initialize(test.NewApp()); err != nil {
+		t.Fatalf("Failed to initialize theme manager: %v", err)
+	}
+
+	editor := NewThemeEditor(themeManager, test.NewWindow(nil))
+	editor.Show()
+
+	// Start from the dark scheme and change one color
+	editor.loadStartingPoint("Dark")
+	editor.scheme.Primary = theme.NewSerializableColorFromRGBA(255, 128, 0, 255)
+	editor.refresh()
+	editor.nameEntry.SetText("Sunset")
+	editor.store("Sunset")
+
+	saved, err := themeManager.GetCustomTheme("Sunset")
+	if err != nil {
+		t.Fatalf("Theme not saved: %v", err)
+	}
+	if saved.ColorScheme.Background != theme.DarkColorScheme.Background ||
+		saved.ColorScheme.Primary != theme.NewSerializableColorFromRGBA(255, 128, 0, 255) {
+		t.Error("Saved theme should be the dark scheme with the new primary color")
+	}
+	prefs := themeManager.GetPreferences()
+	if prefs.ThemeType != theme.ThemeCustom || prefs.CustomThemeName != "Sunset" {
+		t.Errorf("Expected the saved theme in use, got %+v", prefs)
+	}
+
+	// Duplicating an existing theme keeps its name so saving updates it
+	editor = NewThemeEditor(themeManager, test.NewWindow(nil))
+	editor.Show()
+	editor.loadStartingPoint("Sunset")
+	if editor.nameEntry.Text != "Sunset" || editor.scheme.Primary != saved.ColorScheme.Primary {
+		t.Fatal("Expected the existing theme loaded")
+	}
+	editor.scheme.Secondary = theme.NewSerializableColorFromRGBA(0, 0, 255, 255)
+	editor.store("Sunset")
+
+	if themes := themeManager.ListCustomThemes(); len(themes) != 1 {
+		t.Fatalf("Expected the theme updated in place, got %d themes", len(themes))
+	}
+	updated, _ := themeManager.GetCustomTheme("Sunset")
+	if updated.ColorScheme.Secondary != theme.NewSerializableColorFromRGBA(0, 0, 255, 255) {
+		t.Error("Expected the update saved")
+	}
+}
+
+func TestThemeEditorWarnings(t *testing.T) {
+	themeManager := theme.NewDefaultThemeManager(t.TempDir())
+	editor := NewThemeEditor(themeManager, test.NewWindow(nil))
+	editor.Show()
+
+	if editor.warnings.Text != "" {
+		t.Errorf("Expected no warnings for the light scheme, got %q", editor.warnings.Text)
+	}
+
+	editor.scheme.OnBackground = editor.scheme.Background
+	editor.refresh()
+	if !strings.Contains(editor.warnings.Text, "On Background on Background is hard to read") {
+		t.Errorf("Expected a readability warning, got %q", editor.warnings.Text)
+	}
+}
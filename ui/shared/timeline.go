@@ -0,0 +1,111 @@
+package shared
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// groupWindow is how soon after the previous message one from the same
+// sender is shown without repeating the name
+const groupWindow = 5 * time.Minute
+
+// Glyphs for the delivery status of outgoing messages
+const (
+	statusPending   = "🕓"
+	statusDelivered = "✓"
+	statusRead      = "✓✓"
+)
+
+// createMessageHeader adds a date separator when msg starts a new day and the
+// sender and time when it does not continue the previous sender's messages
+func (cv *ChatView) createMessageHeader(content *fyne.Container, msg, prev *message.Message) {
+	if startsNewDay(prev, msg) {
+		day := widget.NewLabelWithStyle(dayLabel(msg.Timestamp, time.Now()), fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+		content.Add(day)
+	}
+	if continuesGroup(prev, msg) {
+		return
+	}
+
+	sender := i18n.T("chat.you")
+	if !msg.IsOutgoing {
+		sender = cv.friendName(msg.FriendID)
+	}
+	name := widget.NewLabelWithStyle(sender, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	sent := widget.NewLabelWithStyle(messageTime(msg.Timestamp), fyne.TextAlignTrailing, fyne.TextStyle{Italic: true})
+	content.Add(container.NewBorder(nil, nil, name, sent))
+}
+
+// createDeliveryStatus adds the delivery status of an outgoing message
+func (cv *ChatView) createDeliveryStatus(content *fyne.Container, msg *message.Message) {
+	showRead := true
+	if cv.coreApp != nil && cv.coreApp.GetConfigManager() != nil {
+		showRead = cv.coreApp.GetConfigManager().GetConfig().Privacy.ShowReadReceipts
+	}
+	if glyph := deliveryStatus(msg, showRead); glyph != "" {
+		content.Add(widget.NewLabelWithStyle(glyph, fyne.TextAlignTrailing, fyne.TextStyle{}))
+	}
+}
+
+// deliveryStatus returns the status glyph of an outgoing message, or "" for
+// received and failed messages. Read is only shown while read receipts are.
+func deliveryStatus(msg *message.Message, showRead bool) string {
+	switch {
+	case !msg.IsOutgoing || msg.FailedAt != nil:
+		return ""
+	case msg.ReadAt != nil && showRead:
+		return statusRead
+	case msg.DeliveredAt != nil || msg.ReadAt != nil:
+		return statusDelivered
+	default:
+		return statusPending
+	}
+}
+
+// startsNewDay reports whether msg was sent on a different day than prev
+func startsNewDay(prev, msg *message.Message) bool {
+	return prev == nil || !sameDay(prev.Timestamp, msg.Timestamp)
+}
+
+// continuesGroup reports whether msg follows prev from the same sender
+// closely enough to be shown under the same name
+func continuesGroup(prev, msg *message.Message) bool {
+	if prev == nil || prev.IsOutgoing != msg.IsOutgoing || startsNewDay(prev, msg) {
+		return false
+	}
+	gap := msg.Timestamp.Sub(prev.Timestamp)
+	return gap >= 0 && gap < groupWindow
+}
+
+// sameDay reports whether a and b fall on the same local calendar day
+func sameDay(a, b time.Time) bool {
+	a, b = a.Local(), b.Local()
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// dayLabel names the day of t for a date separator: today, yesterday, the
+// date in the current year, or the full date
+func dayLabel(t, now time.Time) string {
+	t, now = t.Local(), now.Local()
+	switch {
+	case sameDay(t, now):
+		return i18n.T("chat.today")
+	case sameDay(t, now.AddDate(0, 0, -1)):
+		return i18n.T("chat.yesterday")
+	case t.Year() == now.Year():
+		return t.Format("January 2")
+	default:
+		return t.Format("January 2, 2006")
+	}
+}
+
+// messageTime formats the time a message was sent
+func messageTime(t time.Time) string {
+	return t.Local().Format("15:04")
+}
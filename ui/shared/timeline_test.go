@@ -0,0 +1,86 @@
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opd-ai/whisp/internal/core/message"
+)
+
+func TestMessageGrouping(t *testing.T) {
+	base := time.Date(2026, 3, 3, 10, 0, 0, 0, time.Local)
+	msg := func(outgoing bool, offset time.Duration) *message.Message {
+		return &message.Message{IsOutgoing: outgoing, Timestamp: base.Add(offset)}
+	}
+
+	tests := []struct {
+		name         string
+		prev         *message.Message
+		msg          *message.Message
+		wantNewDay   bool
+		wantGrouping bool
+	}{
+		{"first message", nil, msg(false, 0), true, false},
+		{"same sender soon after", msg(false, 0), msg(false, 2*time.Minute), false, true},
+		{"same sender much later", msg(false, 0), msg(false, time.Hour), false, false},
+		{"other sender", msg(false, 0), msg(true, time.Minute), false, false},
+		{"next day", msg(true, 13*time.Hour+58*time.Minute), msg(true, 14*time.Hour+time.Minute), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := startsNewDay(tt.prev, tt.msg); got != tt.wantNewDay {
+				t.Errorf("Expected new day %v, got %v", tt.wantNewDay, got)
+			}
+			if got := continuesGroup(tt.prev, tt.msg); got != tt.wantGrouping {
+				t.Errorf("Expected grouped %v, got %v", tt.wantGrouping, got)
+			}
+		})
+	}
+}
+
+func TestDayLabel(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		when time.Time
+		want string
+	}{
+		{now.Add(-time.Hour), "Today"},
+		{time.Date(2026, 10, 15, 23, 30, 0, 0, time.Local), "Yesterday"},
+		{time.Date(2026, 3, 3, 12, 0, 0, 0, time.Local), "March 3"},
+		{time.Date(2025, 12, 31, 12, 0, 0, 0, time.Local), "December 31, 2025"},
+	}
+
+	for _, tt := range tests {
+		if got := dayLabel(tt.when, now); got != tt.want {
+			t.Errorf("dayLabel(%v) = %q, want %q", tt.when, got, tt.want)
+		}
+	}
+}
+
+func TestDeliveryStatus(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		msg      *message.Message
+		showRead bool
+		want     string
+	}{
+		{"received", &message.Message{ReadAt: &now}, true, ""},
+		{"pending", &message.Message{IsOutgoing: true}, true, statusPending},
+		{"delivered", &message.Message{IsOutgoing: true, DeliveredAt: &now}, true, statusDelivered},
+		{"read", &message.Message{IsOutgoing: true, DeliveredAt: &now, ReadAt: &now}, true, statusRead},
+		{"read with receipts hidden", &message.Message{IsOutgoing: true, DeliveredAt: &now, ReadAt: &now}, false, statusDelivered},
+		{"failed", &message.Message{IsOutgoing: true, FailedAt: &now}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deliveryStatus(tt.msg, tt.showRead); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
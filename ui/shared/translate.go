@@ -0,0 +1,80 @@
+package shared
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Translator translates message text into another language. Implementations
+// usually call an external service, so ChatView only uses one after the user
+// has opted in to translations.
+type Translator interface {
+	Translate(text, targetLang string) (string, error)
+}
+
+// NoopTranslator returns text unchanged
+type NoopTranslator struct{}
+
+// Translate returns the input text
+func (NoopTranslator) Translate(text, targetLang string) (string, error) {
+	return text, nil
+}
+
+// translationKey identifies a cached translation
+type translationKey struct {
+	text       string
+	targetLang string
+}
+
+// TranslationCache wraps a Translator and remembers its results so repeat
+// requests for the same text do not leave the device again
+type TranslationCache struct {
+	mu         sync.Mutex
+	translator Translator
+	cache      map[translationKey]string
+}
+
+// NewTranslationCache creates a cache in front of translator
+func NewTranslationCache(translator Translator) *TranslationCache {
+	if translator == nil {
+		translator = NoopTranslator{}
+	}
+	return &TranslationCache{
+		translator: translator,
+		cache:      make(map[translationKey]string),
+	}
+}
+
+// Translate returns the cached translation of text, calling the translator on a miss.
+// Failed translations are not cached so they can be retried.
+func (tc *TranslationCache) Translate(text, targetLang string) (string, error) {
+	key := translationKey{text: text, targetLang: targetLang}
+
+	tc.mu.Lock()
+	translated, ok := tc.cache[key]
+	translator := tc.translator
+	tc.mu.Unlock()
+	if ok {
+		return translated, nil
+	}
+
+	translated, err := translator.Translate(text, targetLang)
+	if err != nil {
+		return "", fmt.Errorf("failed to translate message: %w", err)
+	}
+
+	tc.mu.Lock()
+	tc.cache[key] = translated
+	tc.mu.Unlock()
+
+	return translated, nil
+}
+
+// Cached returns a previously stored translation without calling the translator
+func (tc *TranslationCache) Cached(text, targetLang string) (string, bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	translated, ok := tc.cache[translationKey{text: text, targetLang: targetLang}]
+	return translated, ok
+}
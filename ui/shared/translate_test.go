@@ -0,0 +1,83 @@
+package shared
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeTranslator tags text with the target language and counts calls
+type fakeTranslator struct {
+	calls int
+	fail  bool
+}
+
+func (f *fakeTranslator) Translate(text, targetLang string) (string, error) {
+	f.calls++
+	if f.fail {
+		return "", errors.New("service unavailable")
+	}
+	return "[" + targetLang + "] " + text, nil
+}
+
+func TestTranslationCache(t *testing.T) {
+	translator := &fakeTranslator{}
+	cache := NewTranslationCache(translator)
+
+	translated, err := cache.Translate("hola", "en")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if translated != "[en] hola" {
+		t.Errorf("Expected translated text, got %q", translated)
+	}
+
+	// Repeat requests are served from the cache
+	for i := 0; i < 3; i++ {
+		if again, _ := cache.Translate("hola", "en"); again != translated {
+			t.Errorf("Expected cached translation %q, got %q", translated, again)
+		}
+	}
+	if translator.calls != 1 {
+		t.Errorf("Expected translator to be called once, got %d", translator.calls)
+	}
+
+	// A different target language is a separate entry
+	if de, _ := cache.Translate("hola", "de"); de != "[de] hola" {
+		t.Errorf("Expected German translation, got %q", de)
+	}
+	if translator.calls != 2 {
+		t.Errorf("Expected 2 translator calls, got %d", translator.calls)
+	}
+
+	if cached, ok := cache.Cached("hola", "en"); !ok || cached != translated {
+		t.Errorf("Expected Cached to return %q, got %q (%v)", translated, cached, ok)
+	}
+	if _, ok := cache.Cached("adios", "en"); ok {
+		t.Error("Expected no cached entry for untranslated text")
+	}
+}
+
+func TestTranslationCacheErrorsNotCached(t *testing.T) {
+	translator := &fakeTranslator{fail: true}
+	cache := NewTranslationCache(translator)
+
+	if _, err := cache.Translate("hola", "en"); err == nil {
+		t.Fatal("Expected translation error")
+	}
+
+	translator.fail = false
+	translated, err := cache.Translate("hola", "en")
+	if err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if translated != "[en] hola" || translator.calls != 2 {
+		t.Errorf("Expected retry to call translator again, got %q after %d calls", translated, translator.calls)
+	}
+}
+
+func TestNoopTranslator(t *testing.T) {
+	cache := NewTranslationCache(nil)
+	if translated, err := cache.Translate("unchanged", "fr"); err != nil || translated != "unchanged" {
+		t.Errorf("Expected text unchanged, got %q (%v)", translated, err)
+	}
+}
@@ -0,0 +1,51 @@
+package shared
+
+import (
+	"github.com/opd-ai/whisp/internal/core/contact"
+	"github.com/opd-ai/whisp/internal/core/message"
+	"github.com/opd-ai/whisp/ui/i18n"
+)
+
+// typingNotifier returns the typing notifier, or nil if messages are unavailable
+func (cv *ChatView) typingNotifier() *message.TypingNotifier {
+	if cv.typing == nil && cv.coreApp != nil && cv.coreApp.GetMessages() != nil {
+		cv.typing = message.NewTypingNotifier(cv.coreApp.GetMessages().SendTyping, message.DefaultTypingTimeout)
+	}
+	return cv.typing
+}
+
+// SetFriendTyping shows or hides the typing line for a friend. Only the open
+// conversation shows it.
+func (cv *ChatView) SetFriendTyping(friendID uint32, typing bool) {
+	if friendID != cv.currentFriend {
+		return
+	}
+	if !typing {
+		cv.typingLabel.Hide()
+		return
+	}
+	cv.typingLabel.SetText(i18n.Tf("chat.typing", cv.friendName(friendID)))
+	cv.typingLabel.Show()
+}
+
+// refreshTyping shows the typing line if the open conversation's friend is typing
+func (cv *ChatView) refreshTyping() {
+	typing := false
+	if cv.coreApp != nil && cv.coreApp.GetMessages() != nil && cv.currentFriend != 0 {
+		typing = cv.coreApp.GetMessages().IsFriendTyping(cv.currentFriend)
+	}
+	cv.SetFriendTyping(cv.currentFriend, typing)
+}
+
+// friendName returns the name shown for a friend
+func (cv *ChatView) friendName(friendID uint32) string {
+	name := i18n.Tf("contacts.fallback_name", friendID)
+	if cv.coreApp != nil && cv.coreApp.GetContacts() != nil {
+		if value, ok := cv.coreApp.GetContacts().GetContact(friendID); ok {
+			if c, ok := value.(*contact.Contact); ok && c.DisplayName() != "" {
+				name = c.DisplayName()
+			}
+		}
+	}
+	return cv.presentation.DisplayName(friendID, name)
+}
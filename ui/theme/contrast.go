@@ -0,0 +1,108 @@
+package theme
+
+import (
+	"image/color"
+	"math"
+)
+
+// WCAG AA contrast ratios: body text needs more contrast than button labels,
+// which count as user interface components
+const (
+	MinContrastRatio       = 4.5
+	MinButtonContrastRatio = 3.0
+)
+
+// ColorField is one editable color of a color scheme
+type ColorField struct {
+	Name  string
+	Color *SerializableColor
+}
+
+// Fields returns the colors of the scheme in display order, pointing into it
+// so editors can change them in place
+func (s *ColorScheme) Fields() []ColorField {
+	return []ColorField{
+		{"Primary", &s.Primary},
+		{"Secondary", &s.Secondary},
+		{"Success", &s.Success},
+		{"Warning", &s.Warning},
+		{"Error", &s.Error},
+		{"Info", &s.Info},
+		{"Background", &s.Background},
+		{"Surface", &s.Surface},
+		{"Surface Variant", &s.SurfaceVariant},
+		{"On Primary", &s.OnPrimary},
+		{"On Secondary", &s.OnSecondary},
+		{"On Background", &s.OnBackground},
+		{"On Surface", &s.OnSurface},
+		{"On Error", &s.OnError},
+		{"Message Sent", &s.MessageSent},
+		{"Message Received", &s.MessageReceived},
+		{"Message Text", &s.MessageText},
+		{"Message Time", &s.MessageTime},
+		{"Online Indicator", &s.OnlineIndicator},
+		{"Offline Indicator", &s.OfflineIndicator},
+		{"Border", &s.Border},
+		{"Divider", &s.Divider},
+		{"Highlight", &s.Highlight},
+		{"Disabled", &s.Disabled},
+		{"Shadow", &s.Shadow},
+	}
+}
+
+// ContrastWarning reports a text color that is hard to read on its background
+type ContrastWarning struct {
+	Foreground string
+	Background string
+	Ratio      float64
+	Minimum    float64 // Ratio the pair should reach
+}
+
+// CheckContrast returns the text and background pairs of the scheme that
+// fall short of their minimum contrast
+func (s ColorScheme) CheckContrast() []ContrastWarning {
+	pairs := []struct {
+		foreground, background string
+		fg, bg                 SerializableColor
+		minimum                float64
+	}{
+		{"On Primary", "Primary", s.OnPrimary, s.Primary, MinButtonContrastRatio},
+		{"On Secondary", "Secondary", s.OnSecondary, s.Secondary, MinButtonContrastRatio},
+		{"On Error", "Error", s.OnError, s.Error, MinButtonContrastRatio},
+		{"On Background", "Background", s.OnBackground, s.Background, MinContrastRatio},
+		{"On Surface", "Surface", s.OnSurface, s.Surface, MinContrastRatio},
+		{"Message Text", "Message Sent", s.MessageText, s.MessageSent, MinContrastRatio},
+		{"Message Text", "Message Received", s.MessageText, s.MessageReceived, MinContrastRatio},
+	}
+
+	var warnings []ContrastWarning
+	for _, pair := range pairs {
+		if ratio := ContrastRatio(pair.fg, pair.bg); ratio < pair.minimum {
+			warnings = append(warnings, ContrastWarning{pair.foreground, pair.background, ratio, pair.minimum})
+		}
+	}
+	return warnings
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two colors, from 1 for
+// identical colors to 21 for black on white. Alpha is ignored.
+func ContrastRatio(a, b color.Color) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// relativeLuminance is the WCAG relative luminance of a color
+func relativeLuminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	linear := func(v uint32) float64 {
+		s := float64(v) / 0xffff
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
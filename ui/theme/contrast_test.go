@@ -0,0 +1,79 @@
+package theme
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b color.Color
+		want float64
+	}{
+		{"black on white", color.Black, color.White, 21},
+		{"white on black", color.White, color.Black, 21},
+		{"same color", color.Gray{Y: 120}, color.Gray{Y: 120}, 1},
+		{"gray on white", color.Gray{Y: 118}, color.White, 4.54},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContrastRatio(tt.a, tt.b); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("ContrastRatio() = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckContrast(t *testing.T) {
+	for name, scheme := range map[string]ColorScheme{
+		"light": LightColorScheme, "dark": DarkColorScheme, "amoled": AMOLEDColorScheme,
+	} {
+		if warnings := scheme.CheckContrast(); len(warnings) != 0 {
+			t.Errorf("Built-in %s scheme has readability warnings: %+v", name, warnings)
+		}
+	}
+
+	scheme := LightColorScheme
+	scheme.OnPrimary = scheme.Primary
+	scheme.MessageText = NewSerializableColorFromRGBA(200, 200, 200, 255)
+	warnings := scheme.CheckContrast()
+
+	want := map[string]bool{
+		"On Primary/Primary":            true,
+		"Message Text/Message Sent":     true,
+		"Message Text/Message Received": true,
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("Expected %d warnings, got %+v", len(want), warnings)
+	}
+	for _, w := range warnings {
+		if !want[w.Foreground+"/"+w.Background] || w.Ratio >= w.Minimum {
+			t.Errorf("Unexpected warning %+v", w)
+		}
+	}
+}
+
+func TestColorSchemeFields(t *testing.T) {
+	scheme := LightColorScheme
+	fields := scheme.Fields()
+	if len(fields) != 25 {
+		t.Fatalf("Expected 25 fields, got %d", len(fields))
+	}
+
+	// Fields point into the scheme
+	*fields[0].Color = NewSerializableColorFromRGBA(1, 2, 3, 255)
+	if scheme.Primary != NewSerializableColorFromRGBA(1, 2, 3, 255) {
+		t.Error("Editing a field should change the scheme")
+	}
+
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		if seen[field.Name] {
+			t.Errorf("Duplicate field %s", field.Name)
+		}
+		seen[field.Name] = true
+	}
+}
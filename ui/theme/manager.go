@@ -23,6 +23,7 @@ type DefaultThemeManager struct {
 	configDir        string
 	changeCallbacks  []func(ThemeType)
 	autoSwitchTimer  *time.Timer
+	fontScale        float32 // Applied to every theme; set from the config
 }
 
 // NewDefaultThemeManager creates a new default theme manager
@@ -37,6 +38,7 @@ func NewDefaultThemeManager(configDir string) *DefaultThemeManager {
 		customThemes:   make(map[string]CustomTheme),
 		systemDetector: NewSystemThemeDetector(),
 		configDir:      configDir,
+		fontScale:      1,
 	}
 }
 
@@ -116,6 +118,56 @@ func (tm *DefaultThemeManager) SetTheme(themeType ThemeType) error {
 	return nil
 }
 
+// PreviewTheme shows a theme without changing the saved preference. Setting
+// the theme keeps it; previewing the preferred theme again reverts.
+func (tm *DefaultThemeManager) PreviewTheme(themeType ThemeType) error {
+	tm.mu.Lock()
+
+	oldTheme, oldCurrent := tm.currentThemeType, tm.currentTheme
+	tm.currentThemeType = themeType
+	if err := tm.updateCurrentTheme(); err != nil {
+		tm.currentThemeType, tm.currentTheme = oldTheme, oldCurrent
+		tm.mu.Unlock()
+		return fmt.Errorf("failed to preview theme: %w", err)
+	}
+
+	if tm.app != nil {
+		tm.app.Settings().SetTheme(tm.currentTheme)
+	}
+
+	callbacks := make([]func(ThemeType), len(tm.changeCallbacks))
+	copy(callbacks, tm.changeCallbacks)
+	tm.mu.Unlock()
+
+	tm.notifyThemeChangeWithCallbacks(callbacks, themeType)
+	return nil
+}
+
+// SetFontScale scales the text of every theme, e.g. by FontScaleFor the
+// font_size setting
+func (tm *DefaultThemeManager) SetFontScale(scale float32) error {
+	if scale <= 0 {
+		return fmt.Errorf("invalid font scale: %v", scale)
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.fontScale = scale
+	tm.currentTheme = tm.currentTheme.WithFontScale(scale)
+	if tm.app != nil {
+		tm.app.Settings().SetTheme(tm.currentTheme)
+	}
+	return nil
+}
+
+// GetFontScale returns the factor text is scaled by
+func (tm *DefaultThemeManager) GetFontScale() float32 {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.fontScale
+}
+
 // GetThemeType returns the current theme type
 func (tm *DefaultThemeManager) GetThemeType() ThemeType {
 	tm.mu.RLock()
@@ -422,6 +474,8 @@ func (tm *DefaultThemeManager) updateCurrentTheme() error {
 		tm.currentTheme = NewLightTheme()
 	case ThemeDark:
 		tm.currentTheme = NewDarkTheme()
+	case ThemeAMOLED:
+		tm.currentTheme = NewAMOLEDTheme()
 	case ThemeSystem:
 		systemTheme := tm.systemDetector.DetectSystemTheme()
 		if systemTheme == ThemeDark {
@@ -442,6 +496,7 @@ func (tm *DefaultThemeManager) updateCurrentTheme() error {
 		return fmt.Errorf("unknown theme type: %v", tm.currentThemeType)
 	}
 
+	tm.currentTheme = tm.currentTheme.WithFontScale(tm.fontScale)
 	return nil
 }
 
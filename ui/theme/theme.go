@@ -10,9 +10,10 @@ import (
 
 // WhispTheme implements fyne.Theme interface for Whisp
 type WhispTheme struct {
-	scheme  ColorScheme
-	isDark  bool
-	variant fyne.ThemeVariant
+	scheme    ColorScheme
+	isDark    bool
+	variant   fyne.ThemeVariant
+	fontScale float32 // Multiplies text sizes, 1 for Fyne's defaults
 }
 
 // NewWhispTheme creates a new Whisp theme with the given color scheme
@@ -23,12 +24,30 @@ func NewWhispTheme(scheme ColorScheme, isDark bool) *WhispTheme {
 	}
 
 	return &WhispTheme{
-		scheme:  scheme,
-		isDark:  isDark,
-		variant: variant,
+		scheme:    scheme,
+		isDark:    isDark,
+		variant:   variant,
+		fontScale: 1,
 	}
 }
 
+// Font scales for the font_size setting
+var fontScales = map[string]float32{
+	"small":       0.85,
+	"medium":      1,
+	"large":       1.15,
+	"extra_large": 1.3,
+}
+
+// FontScaleFor returns the text scale for a font_size setting, 1 for
+// unknown values
+func FontScaleFor(fontSize string) float32 {
+	if scale, ok := fontScales[fontSize]; ok {
+		return scale
+	}
+	return 1
+}
+
 // NewLightTheme creates a new light theme
 func NewLightTheme() *WhispTheme {
 	return NewWhispTheme(LightColorScheme, false)
@@ -39,6 +58,11 @@ func NewDarkTheme() *WhispTheme {
 	return NewWhispTheme(DarkColorScheme, true)
 }
 
+// NewAMOLEDTheme creates a new dark theme with a true black background
+func NewAMOLEDTheme() *WhispTheme {
+	return NewWhispTheme(AMOLEDColorScheme, true)
+}
+
 // Color returns the theme's color for the specified ColorName
 func (t *WhispTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
 	// Handle variant-specific colors
@@ -145,13 +169,34 @@ func (t *WhispTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
 	return theme.DefaultTheme().Icon(name)
 }
 
-// Size returns the theme's size for the specified SizeName
+// Size returns the theme's size for the specified SizeName. Text and the
+// icons inline with it follow the font scale; padding and other spacing keep
+// Fyne's defaults so layouts hold at large sizes.
 func (t *WhispTheme) Size(name fyne.ThemeSizeName) float32 {
 	// Use Fyne's default sizes
+	size := theme.DefaultTheme().Size(name)
 	if t.isDark {
-		return theme.DarkTheme().Size(name)
+		size = theme.DarkTheme().Size(name)
 	}
-	return theme.DefaultTheme().Size(name)
+
+	switch name {
+	case theme.SizeNameText, theme.SizeNameCaptionText, theme.SizeNameHeadingText,
+		theme.SizeNameSubHeadingText, theme.SizeNameInlineIcon:
+		return size * t.fontScale
+	}
+	return size
+}
+
+// WithFontScale returns a copy of the theme with text scaled by scale
+func (t *WhispTheme) WithFontScale(scale float32) *WhispTheme {
+	scaled := *t
+	scaled.fontScale = scale
+	return &scaled
+}
+
+// FontScale returns the factor text sizes are multiplied by
+func (t *WhispTheme) FontScale() float32 {
+	return t.fontScale
 }
 
 // GetColorScheme returns the theme's color scheme
@@ -22,13 +22,17 @@ func TestThemeTypes(t *testing.T) {
 		{"Dark theme", ThemeDark, "dark"},
 		{"System theme", ThemeSystem, "system"},
 		{"Custom theme", ThemeCustom, "custom"},
+		{"AMOLED theme", ThemeAMOLED, "amoled"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Note: ThemeType is an iota, so we can't directly convert to string
-			// This test just verifies the constants exist
-			_ = tt.themeType
+			if got := tt.themeType.String(); got != tt.expected {
+				t.Errorf("String() = %q, want %q", got, tt.expected)
+			}
+			if got := ParseThemeType(tt.expected); got != tt.themeType {
+				t.Errorf("ParseThemeType(%q) = %v, want %v", tt.expected, got, tt.themeType)
+			}
 		})
 	}
 }
@@ -63,6 +67,23 @@ func TestColorSchemes(t *testing.T) {
 			t.Error("OnBackground color should not be zero value")
 		}
 	})
+
+	t.Run("AMOLEDColorScheme", func(t *testing.T) {
+		scheme := AMOLEDColorScheme
+
+		if scheme.Background != NewSerializableColorFromRGBA(0, 0, 0, 255) {
+			t.Errorf("Background should be pure black, got %+v", scheme.Background)
+		}
+		if scheme.Surface == scheme.Background {
+			t.Error("Surfaces should stand out from the black background")
+		}
+		if scheme.OnBackground != DarkColorScheme.OnBackground || scheme.Primary != DarkColorScheme.Primary {
+			t.Error("Text and accent colors should follow the dark scheme")
+		}
+		if !NewAMOLEDTheme().IsDark() {
+			t.Error("AMOLED theme should be dark")
+		}
+	})
 }
 
 func TestWhispTheme(t *testing.T) {
@@ -227,6 +248,74 @@ func TestThemeManager(t *testing.T) {
 		}
 	})
 
+	t.Run("PreviewTheme", func(t *testing.T) {
+		manager := NewDefaultThemeManager(tempDir)
+		app := test.NewApp()
+		manager.Initialize(app)
+		if err := manager.SetTheme(ThemeLight); err != nil {
+			t.Fatalf("Failed to set light theme: %v", err)
+		}
+
+		if err := manager.PreviewTheme(ThemeAMOLED); err != nil {
+			t.Fatalf("Failed to preview AMOLED theme: %v", err)
+		}
+		if manager.GetThemeType() != ThemeAMOLED {
+			t.Error("Theme type should be AMOLED while previewing")
+		}
+		if bg := app.Settings().Theme().Color(theme.ColorNameBackground, theme.VariantDark); bg != color.Color(AMOLEDColorScheme.Background) {
+			t.Errorf("Expected the black AMOLED background applied, got %v", bg)
+		}
+
+		// A preview is not saved
+		if manager.GetPreferences().ThemeType != ThemeLight {
+			t.Error("Preview should not change the preferred theme")
+		}
+		reloaded := NewDefaultThemeManager(tempDir)
+		reloaded.Initialize(test.NewApp())
+		if reloaded.GetThemeType() != ThemeLight {
+			t.Errorf("Preview should not be persisted, got %v", reloaded.GetThemeType())
+		}
+
+		if err := manager.PreviewTheme(ThemeCustom); err == nil {
+			t.Error("Expected error previewing a custom theme that is not set")
+		}
+		if manager.GetThemeType() != ThemeAMOLED {
+			t.Error("Failed preview should keep the previous theme")
+		}
+	})
+
+	t.Run("FontScale", func(t *testing.T) {
+		manager := NewDefaultThemeManager(tempDir)
+		app := test.NewApp()
+		manager.Initialize(app)
+		if manager.GetFontScale() != 1 {
+			t.Errorf("Expected default font scale 1, got %v", manager.GetFontScale())
+		}
+
+		base := manager.GetCurrentTheme().Size(theme.SizeNameText)
+		if err := manager.SetFontScale(FontScaleFor("large")); err != nil {
+			t.Fatalf("Failed to set font scale: %v", err)
+		}
+		if got := app.Settings().Theme().Size(theme.SizeNameText); got <= base {
+			t.Errorf("Expected larger text applied to the app, got %v (was %v)", got, base)
+		}
+
+		// The scale survives switching themes
+		if err := manager.SetTheme(ThemeDark); err != nil {
+			t.Fatalf("Failed to set dark theme: %v", err)
+		}
+		if manager.GetFontScale() != FontScaleFor("large") {
+			t.Errorf("Expected font scale kept, got %v", manager.GetFontScale())
+		}
+		if got := manager.GetCurrentTheme().Size(theme.SizeNameText); got <= base {
+			t.Errorf("Expected scaled text after switching themes, got %v", got)
+		}
+
+		if err := manager.SetFontScale(0); err == nil {
+			t.Error("Expected error for a zero font scale")
+		}
+	})
+
 	t.Run("CustomThemes", func(t *testing.T) {
 		manager := NewDefaultThemeManager(tempDir)
 		app := test.NewApp()
@@ -431,6 +520,17 @@ func TestThemeManager(t *testing.T) {
 		if len(themes) != 1 || themes[0].Name != "Persistent Theme" {
 			t.Error("Custom theme should be persisted")
 		}
+
+		// The AMOLED theme round-trips through the preferences file
+		manager2.SetTheme(ThemeAMOLED)
+		manager3 := NewDefaultThemeManager(testTempDir)
+		manager3.Initialize(app)
+		if manager3.GetThemeType() != ThemeAMOLED {
+			t.Errorf("AMOLED theme preference should be persisted, got %v", manager3.GetThemeType())
+		}
+		if !manager3.GetCurrentTheme().(*WhispTheme).IsDark() {
+			t.Error("Loaded AMOLED theme should be dark")
+		}
 	})
 }
 
@@ -548,3 +648,30 @@ func colorsApproximatelyEqual(c1, c2 color.Color) bool {
 		abs(b1-b2) <= tolerance &&
 		abs(a1-a2) <= tolerance
 }
+
+func TestFontSizeScalesText(t *testing.T) {
+	sizes := []string{"small", "medium", "large", "extra_large"}
+	seen := make(map[float32]string)
+	var previous float32
+	for _, size := range sizes {
+		text := NewLightTheme().WithFontScale(FontScaleFor(size)).Size(theme.SizeNameText)
+		if other, ok := seen[text]; ok {
+			t.Errorf("Font sizes %s and %s both give text size %v", other, size, text)
+		}
+		if text <= previous {
+			t.Errorf("Expected %s text larger than %v, got %v", size, previous, text)
+		}
+		seen[text] = size
+		previous = text
+	}
+
+	// Spacing is not scaled
+	padding := NewLightTheme().Size(theme.SizeNamePadding)
+	if got := NewLightTheme().WithFontScale(FontScaleFor("extra_large")).Size(theme.SizeNamePadding); got != padding {
+		t.Errorf("Expected padding %v unscaled, got %v", padding, got)
+	}
+
+	if FontScaleFor("unknown") != 1 {
+		t.Error("Expected unknown font sizes to leave text unscaled")
+	}
+}
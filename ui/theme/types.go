@@ -87,6 +87,7 @@ const (
 	ThemeDark
 	ThemeSystem // Follows system preference
 	ThemeCustom // User-defined custom theme
+	ThemeAMOLED // Dark theme on pure black for OLED screens
 )
 
 // String returns string representation of theme type
@@ -100,6 +101,8 @@ func (t ThemeType) String() string {
 		return "system"
 	case ThemeCustom:
 		return "custom"
+	case ThemeAMOLED:
+		return "amoled"
 	default:
 		return "unknown"
 	}
@@ -116,6 +119,8 @@ func ParseThemeType(s string) ThemeType {
 		return ThemeSystem
 	case "custom":
 		return ThemeCustom
+	case "amoled":
+		return ThemeAMOLED
 	default:
 		return ThemeSystem // Default to system
 	}
@@ -188,6 +193,11 @@ type ThemeManager interface {
 	GetCurrentTheme() fyne.Theme
 	SetTheme(themeType ThemeType) error
 	GetThemeType() ThemeType
+	PreviewTheme(themeType ThemeType) error // Shows a theme without saving it
+
+	// Text size
+	SetFontScale(scale float32) error
+	GetFontScale() float32
 
 	// Custom themes
 	CreateCustomTheme(theme CustomTheme) error
@@ -290,4 +300,20 @@ var (
 		Disabled:  NewSerializableColorFromRGBA(97, 97, 97, 255),   // Dark Medium Gray
 		Shadow:    NewSerializableColorFromRGBA(0, 0, 0, 80),       // Darker Shadow
 	}
+
+	// AMOLED theme color scheme: the dark scheme on true black, so unlit
+	// pixels stay off on OLED screens
+	AMOLEDColorScheme = amoledScheme(DarkColorScheme)
 )
+
+// amoledScheme puts a dark scheme on true black, keeping dark surfaces so
+// buttons and menus stay visible
+func amoledScheme(dark ColorScheme) ColorScheme {
+	scheme := dark
+	scheme.Background = NewSerializableColorFromRGBA(0, 0, 0, 255)         // Black
+	scheme.Surface = NewSerializableColorFromRGBA(16, 16, 16, 255)         // Near Black
+	scheme.SurfaceVariant = NewSerializableColorFromRGBA(28, 28, 28, 255)  // Very Dark Gray
+	scheme.MessageReceived = NewSerializableColorFromRGBA(28, 28, 28, 255) // Very Dark Gray
+	scheme.Divider = NewSerializableColorFromRGBA(32, 32, 32, 255)         // Dark Divider
+	return scheme
+}